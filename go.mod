@@ -3,20 +3,37 @@ module github.com/open-telemetry/opentelemetry-log-collection
 go 1.15
 
 require (
+	github.com/Azure/azure-amqp-common-go/v3 v3.0.1
+	github.com/Azure/azure-event-hubs-go/v3 v3.3.12
+	github.com/Shopify/sarama v1.29.1
+	github.com/alicebob/miniredis/v2 v2.23.0
 	github.com/antonmedv/expr v1.8.9
+	github.com/aws/aws-sdk-go v1.38.3
 	github.com/bmatcuk/doublestar/v3 v3.0.0
+	github.com/eclipse/paho.golang v0.10.0
+	github.com/eclipse/paho.mqtt.golang v1.4.2
+	github.com/fsnotify/fsnotify v1.4.9
+	github.com/go-redis/redis/v8 v8.11.0
+	github.com/gosnmp/gosnmp v1.34.0
 	github.com/jpillora/backoff v1.0.0
 	github.com/json-iterator/go v1.1.11
 	github.com/mitchellh/mapstructure v1.4.1
+	github.com/nats-io/nats-server/v2 v2.3.0
+	github.com/nats-io/nats.go v1.11.0
 	github.com/observiq/ctimefmt v1.0.0
 	github.com/observiq/go-syslog/v3 v3.0.2
 	github.com/observiq/nanojack v0.0.0-20201106172433-343928847ebc
 	github.com/stretchr/testify v1.7.0
+	github.com/tinylib/msgp v1.1.6
+	github.com/xdg-go/scram v1.0.2
 	go.opentelemetry.io/collector v0.27.0
 	go.uber.org/zap v1.16.0
-	golang.org/x/sys v0.0.0-20210423082822-04245dca01da
+	golang.org/x/net v0.0.0-20210614182718-04defd469f4e
+	golang.org/x/oauth2 v0.0.0-20210323180902-22b0adad7558
+	golang.org/x/sys v0.0.0-20210615035016-665e8c7367d1
 	golang.org/x/text v0.3.6
 	gonum.org/v1/gonum v0.9.1
+	google.golang.org/protobuf v1.26.0
 	gopkg.in/yaml.v2 v2.4.0
 	k8s.io/api v0.21.1
 	k8s.io/apimachinery v0.21.1
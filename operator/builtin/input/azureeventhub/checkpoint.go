@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureeventhub
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-event-hubs-go/v3/persist"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+)
+
+// checkpointsKey is the key under which checkpoints are persisted
+const checkpointsKey = "checkpoints"
+
+// checkpointStore holds the checkpoints of the partitions of an event hub.
+// It implements the checkpoint persister interface of the event hub client,
+// which reads the checkpoint of a partition when a receiver is created, and
+// writes it after each event is handled.
+type checkpointStore struct {
+	sync.Mutex
+	checkpoints      map[string]persist.Checkpoint
+	startAtBeginning bool
+	startTime        time.Time
+	dirty            bool
+}
+
+func newCheckpointStore(startAtBeginning bool) *checkpointStore {
+	return &checkpointStore{
+		checkpoints:      make(map[string]persist.Checkpoint),
+		startAtBeginning: startAtBeginning,
+		startTime:        time.Now(),
+	}
+}
+
+// Read returns the checkpoint of a partition. If the partition has no checkpoint,
+// events are received from the beginning of the partition, or from the time the
+// operator first started.
+func (s *checkpointStore) Read(namespace, name, consumerGroup, partitionID string) (persist.Checkpoint, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if cp, ok := s.checkpoints[checkpointKey(namespace, name, consumerGroup, partitionID)]; ok {
+		return cp, nil
+	}
+	if s.startAtBeginning {
+		return persist.NewCheckpointFromStartOfStream(), nil
+	}
+	return persist.NewCheckpoint("", 0, s.startTime), nil
+}
+
+// Write sets the checkpoint of a partition
+func (s *checkpointStore) Write(namespace, name, consumerGroup, partitionID string, checkpoint persist.Checkpoint) error {
+	s.Lock()
+	defer s.Unlock()
+	s.checkpoints[checkpointKey(namespace, name, consumerGroup, partitionID)] = checkpoint
+	s.dirty = true
+	return nil
+}
+
+// load loads the checkpoints from the persister
+func (s *checkpointStore) load(ctx context.Context, persister operator.Persister) error {
+	data, err := persister.Get(ctx, checkpointsKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	return json.Unmarshal(data, &s.checkpoints)
+}
+
+// persist saves the checkpoints to the persister, if they have changed
+func (s *checkpointStore) persist(ctx context.Context, persister operator.Persister) error {
+	s.Lock()
+	defer s.Unlock()
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(s.checkpoints)
+	if err != nil {
+		return err
+	}
+	if err := persister.Set(ctx, checkpointsKey, data); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// checkpointKey identifies a partition, so that a change of
+// event hub or consumer group does not reuse checkpoints
+func checkpointKey(namespace, name, consumerGroup, partitionID string) string {
+	return path.Join(namespace, name, consumerGroup, partitionID)
+}
@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureeventhub
+
+import (
+	"context"
+	"encoding/json"
+
+	eventhub "github.com/Azure/azure-event-hubs-go/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	eventHubAttribute  = "azure.eventhub.name"
+	partitionAttribute = "azure.eventhub.partition"
+)
+
+// recordsEnvelope is the envelope in which Azure services,
+// such as diagnostic settings, send a batch of log records
+type recordsEnvelope struct {
+	Records []map[string]interface{} `json:"records"`
+}
+
+// eventBodies returns the bodies of the entries created from an event. Each
+// record of an Azure records envelope becomes an entry. Any other event becomes
+// a single entry, with the event data as its body.
+func eventBodies(data []byte) []interface{} {
+	var envelope recordsEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Records == nil {
+		return []interface{}{string(data)}
+	}
+
+	bodies := make([]interface{}, 0, len(envelope.Records))
+	for _, record := range envelope.Records {
+		bodies = append(bodies, record)
+	}
+	return bodies
+}
+
+// handler returns the handler of the events received from a partition
+func (e *EventHubInput) handler(ctx context.Context, partitionID string) eventhub.Handler {
+	return func(_ context.Context, event *eventhub.Event) error {
+		// Events received while stopping are released without a checkpoint
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		for _, body := range eventBodies(event.Data) {
+			entry, err := e.NewEntry(body)
+			if err != nil {
+				e.Errorw("Failed to create entry", zap.String("partition", partitionID), zap.Error(err))
+				continue
+			}
+
+			if event.SystemProperties != nil && event.SystemProperties.EnqueuedTime != nil {
+				entry.Timestamp = *event.SystemProperties.EnqueuedTime
+			}
+			entry.AddAttribute(eventHubAttribute, e.eventHub)
+			entry.AddAttribute(partitionAttribute, partitionID)
+			e.Write(ctx, entry)
+		}
+
+		// An event is only checkpointed once its entries have been written
+		return ctx.Err()
+	}
+}
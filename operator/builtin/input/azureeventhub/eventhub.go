@@ -0,0 +1,302 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureeventhub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-amqp-common-go/v3/aad"
+	"github.com/Azure/azure-amqp-common-go/v3/conn"
+	eventhub "github.com/Azure/azure-event-hubs-go/v3"
+	"github.com/jpillora/backoff"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	startAtBeginning = "beginning"
+	startAtEnd       = "end"
+
+	// persistInterval is how often checkpoints are persisted
+	persistInterval = time.Second
+
+	// closeTimeout is how long to wait for the receivers to close
+	closeTimeout = 10 * time.Second
+)
+
+func init() {
+	operator.Register("azure_event_hub_input", func() operator.Builder { return NewEventHubInputConfig("") })
+}
+
+// NewEventHubInputConfig creates a new Azure Event Hub input config with default values
+func NewEventHubInputConfig(operatorID string) *EventHubInputConfig {
+	return &EventHubInputConfig{
+		InputConfig:   helper.NewInputConfig(operatorID, "azure_event_hub_input"),
+		ConsumerGroup: eventhub.DefaultConsumerGroup,
+		StartAt:       startAtEnd,
+	}
+}
+
+// EventHubInputConfig is the configuration of an Azure Event Hub input operator.
+type EventHubInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	ConnectionString string `mapstructure:"connection_string,omitempty" json:"connection_string,omitempty" yaml:"connection_string,omitempty"`
+	Namespace        string `mapstructure:"namespace,omitempty"         json:"namespace,omitempty"         yaml:"namespace,omitempty"`
+	EventHub         string `mapstructure:"event_hub,omitempty"         json:"event_hub,omitempty"         yaml:"event_hub,omitempty"`
+	TenantID         string `mapstructure:"tenant_id,omitempty"         json:"tenant_id,omitempty"         yaml:"tenant_id,omitempty"`
+	ClientID         string `mapstructure:"client_id,omitempty"         json:"client_id,omitempty"         yaml:"client_id,omitempty"`
+	ClientSecret     string `mapstructure:"client_secret,omitempty"     json:"client_secret,omitempty"     yaml:"client_secret,omitempty"`
+	ConsumerGroup    string `mapstructure:"consumer_group,omitempty"    json:"consumer_group,omitempty"    yaml:"consumer_group,omitempty"`
+	StartAt          string `mapstructure:"start_at,omitempty"          json:"start_at,omitempty"          yaml:"start_at,omitempty"`
+}
+
+// Build will build an Azure Event Hub input operator.
+func (c EventHubInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	eventHubInput := &EventHubInput{
+		InputOperator:    inputOperator,
+		namespace:        c.Namespace,
+		eventHub:         c.EventHub,
+		consumerGroup:    c.ConsumerGroup,
+		startAtBeginning: c.StartAt == startAtBeginning,
+	}
+
+	hasServicePrincipal := c.TenantID != "" || c.ClientID != "" || c.ClientSecret != ""
+	switch {
+	case c.ConnectionString != "":
+		if c.Namespace != "" {
+			return nil, fmt.Errorf("`namespace` can not be used with `connection_string`")
+		}
+		if hasServicePrincipal {
+			return nil, fmt.Errorf("`tenant_id`, `client_id` and `client_secret` can not be used with `connection_string`")
+		}
+
+		parsed, err := conn.ParsedConnectionFromStr(c.ConnectionString)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connection_string: %s", err)
+		}
+		switch {
+		case parsed.HubName == "" && c.EventHub == "":
+			return nil, fmt.Errorf("missing required parameter 'event_hub'")
+		case parsed.HubName == "":
+			eventHubInput.connectionString = c.ConnectionString + ";EntityPath=" + c.EventHub
+		case c.EventHub != "" && c.EventHub != parsed.HubName:
+			return nil, fmt.Errorf("`event_hub` does not match the EntityPath of `connection_string`")
+		default:
+			eventHubInput.connectionString = c.ConnectionString
+			eventHubInput.eventHub = parsed.HubName
+		}
+		eventHubInput.namespace = parsed.Namespace
+	case c.Namespace != "":
+		if c.EventHub == "" {
+			return nil, fmt.Errorf("missing required parameter 'event_hub'")
+		}
+		if hasServicePrincipal && (c.TenantID == "" || c.ClientID == "" || c.ClientSecret == "") {
+			return nil, fmt.Errorf("`tenant_id`, `client_id` and `client_secret` must be set together")
+		}
+		eventHubInput.tenantID = c.TenantID
+		eventHubInput.clientID = c.ClientID
+		eventHubInput.clientSecret = c.ClientSecret
+	default:
+		return nil, fmt.Errorf("one of 'connection_string' or 'namespace' is required")
+	}
+
+	if c.ConsumerGroup == "" {
+		return nil, fmt.Errorf("missing required parameter 'consumer_group'")
+	}
+
+	if c.StartAt != startAtBeginning && c.StartAt != startAtEnd {
+		return nil, fmt.Errorf("invalid start_at location '%s'", c.StartAt)
+	}
+
+	return []operator.Operator{eventHubInput}, nil
+}
+
+// EventHubInput is an operator that receives events from an Azure Event Hub.
+type EventHubInput struct {
+	helper.InputOperator
+	connectionString string
+	namespace        string
+	eventHub         string
+	tenantID         string
+	clientID         string
+	clientSecret     string
+	consumerGroup    string
+	startAtBeginning bool
+
+	hub         *eventhub.Hub
+	checkpoints *checkpointStore
+	persister   operator.Persister
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+// Start will start receiving events from each partition of the event hub.
+func (e *EventHubInput) Start(persister operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.persister = persister
+
+	e.checkpoints = newCheckpointStore(e.startAtBeginning)
+	if err := e.checkpoints.load(ctx, persister); err != nil {
+		return fmt.Errorf("failed to load checkpoints: %s", err)
+	}
+
+	hub, err := e.newHub()
+	if err != nil {
+		return fmt.Errorf("failed to create event hub client: %s", err)
+	}
+	e.hub = hub
+
+	e.goReceivePartitions(ctx)
+	e.goPersistCheckpoints(ctx)
+	return nil
+}
+
+// Stop will stop receiving events.
+func (e *EventHubInput) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.wg.Wait()
+	if e.hub != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		defer cancel()
+		if err := e.hub.Close(ctx); err != nil {
+			e.Errorw("Failed to close event hub client", zap.Error(err))
+		}
+	}
+	if e.checkpoints == nil {
+		return nil
+	}
+	return e.checkpoints.persist(context.Background(), e.persister)
+}
+
+// newHub creates an event hub client, which authenticates with the connection
+// string if one is configured, and with Azure Active Directory otherwise
+func (e *EventHubInput) newHub() (*eventhub.Hub, error) {
+	persistence := eventhub.HubWithOffsetPersistence(e.checkpoints)
+	if e.connectionString != "" {
+		return eventhub.NewHubFromConnectionString(e.connectionString, persistence)
+	}
+
+	// Without a service principal, the credentials are found in the
+	// environment, or a managed identity is used
+	tokenProvider, err := aad.NewJWTProvider(aad.JWTProviderWithEnvironmentVars(), func(config *aad.TokenProviderConfiguration) error {
+		if e.clientSecret != "" {
+			config.TenantID = e.tenantID
+			config.ClientID = e.clientID
+			config.ClientSecret = e.clientSecret
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return eventhub.NewHub(e.namespace, e.eventHub, tokenProvider, persistence)
+}
+
+// goReceivePartitions will find the partitions of the event hub, and receive from each of them.
+func (e *EventHubInput) goReceivePartitions(ctx context.Context) {
+	e.wg.Add(1)
+
+	go func() {
+		defer e.wg.Done()
+
+		b := backoff.Backoff{Max: time.Minute}
+		for {
+			info, err := e.hub.GetRuntimeInformation(ctx)
+			if err == nil {
+				for _, partitionID := range info.PartitionIDs {
+					e.goReceive(ctx, partitionID)
+				}
+				return
+			}
+			e.Errorw("Failed to get event hub partitions", zap.Error(err))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(b.Duration()):
+			}
+		}
+	}()
+}
+
+// goReceive will receive events from a partition, and restart
+// the receiver if it stops with an error.
+func (e *EventHubInput) goReceive(ctx context.Context, partitionID string) {
+	e.wg.Add(1)
+
+	go func() {
+		defer e.wg.Done()
+
+		b := backoff.Backoff{Max: time.Minute}
+		for {
+			handle, err := e.hub.Receive(ctx, partitionID, e.handler(ctx, partitionID), eventhub.ReceiveWithConsumerGroup(e.consumerGroup))
+			if err == nil {
+				b.Reset()
+				select {
+				case <-ctx.Done():
+					return
+				case <-handle.Done():
+					err = handle.Err()
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			e.Errorw("Failed to receive from partition", zap.String("partition", partitionID), zap.Error(err))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(b.Duration()):
+			}
+		}
+	}()
+}
+
+// goPersistCheckpoints periodically persists the checkpoints of the partitions.
+func (e *EventHubInput) goPersistCheckpoints(ctx context.Context) {
+	e.wg.Add(1)
+
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(persistInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := e.checkpoints.persist(ctx, e.persister); err != nil {
+					e.Errorw("Failed to persist checkpoints", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
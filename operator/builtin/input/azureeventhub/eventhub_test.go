@@ -0,0 +1,291 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureeventhub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	eventhub "github.com/Azure/azure-event-hubs-go/v3"
+	"github.com/Azure/azure-event-hubs-go/v3/persist"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+const testConnectionString = "Endpoint=sb://test.servicebus.windows.net/;SharedAccessKeyName=listen;SharedAccessKey=c2VjcmV0"
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*EventHubInputConfig)
+		expectErr bool
+	}{
+		{
+			"ConnectionString",
+			func(cfg *EventHubInputConfig) {
+				cfg.ConnectionString = testConnectionString
+				cfg.EventHub = "logs"
+			},
+			false,
+		},
+		{
+			"ConnectionStringWithEntityPath",
+			func(cfg *EventHubInputConfig) {
+				cfg.ConnectionString = testConnectionString + ";EntityPath=logs"
+			},
+			false,
+		},
+		{
+			"ConnectionStringMismatchedEventHub",
+			func(cfg *EventHubInputConfig) {
+				cfg.ConnectionString = testConnectionString + ";EntityPath=logs"
+				cfg.EventHub = "metrics"
+			},
+			true,
+		},
+		{
+			"ConnectionStringMissingEventHub",
+			func(cfg *EventHubInputConfig) {
+				cfg.ConnectionString = testConnectionString
+			},
+			true,
+		},
+		{
+			"InvalidConnectionString",
+			func(cfg *EventHubInputConfig) {
+				cfg.ConnectionString = "Endpoint=sb://test.servicebus.windows.net/"
+				cfg.EventHub = "logs"
+			},
+			true,
+		},
+		{
+			"ConnectionStringWithServicePrincipal",
+			func(cfg *EventHubInputConfig) {
+				cfg.ConnectionString = testConnectionString
+				cfg.EventHub = "logs"
+				cfg.ClientID = "client"
+			},
+			true,
+		},
+		{
+			"ConnectionStringWithNamespace",
+			func(cfg *EventHubInputConfig) {
+				cfg.ConnectionString = testConnectionString
+				cfg.Namespace = "test"
+				cfg.EventHub = "logs"
+			},
+			true,
+		},
+		{
+			"Namespace",
+			func(cfg *EventHubInputConfig) {
+				cfg.Namespace = "test"
+				cfg.EventHub = "logs"
+			},
+			false,
+		},
+		{
+			"NamespaceWithServicePrincipal",
+			func(cfg *EventHubInputConfig) {
+				cfg.Namespace = "test"
+				cfg.EventHub = "logs"
+				cfg.TenantID = "tenant"
+				cfg.ClientID = "client"
+				cfg.ClientSecret = "secret"
+			},
+			false,
+		},
+		{
+			"NamespacePartialServicePrincipal",
+			func(cfg *EventHubInputConfig) {
+				cfg.Namespace = "test"
+				cfg.EventHub = "logs"
+				cfg.ClientID = "client"
+			},
+			true,
+		},
+		{
+			"NamespaceMissingEventHub",
+			func(cfg *EventHubInputConfig) {
+				cfg.Namespace = "test"
+			},
+			true,
+		},
+		{
+			"MissingConnection",
+			func(cfg *EventHubInputConfig) {
+				cfg.EventHub = "logs"
+			},
+			true,
+		},
+		{
+			"MissingConsumerGroup",
+			func(cfg *EventHubInputConfig) {
+				cfg.Namespace = "test"
+				cfg.EventHub = "logs"
+				cfg.ConsumerGroup = ""
+			},
+			true,
+		},
+		{
+			"InvalidStartAt",
+			func(cfg *EventHubInputConfig) {
+				cfg.Namespace = "test"
+				cfg.EventHub = "logs"
+				cfg.StartAt = "middle"
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewEventHubInputConfig("test_id")
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEventBodies(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		expected []interface{}
+	}{
+		{
+			"Records",
+			`{"records":[{"category":"AuditEvent","level":"Informational"},{"category":"AuditEvent","level":"Error"}]}`,
+			[]interface{}{
+				map[string]interface{}{"category": "AuditEvent", "level": "Informational"},
+				map[string]interface{}{"category": "AuditEvent", "level": "Error"},
+			},
+		},
+		{
+			"EmptyRecords",
+			`{"records":[]}`,
+			[]interface{}{},
+		},
+		{
+			"ObjectWithoutRecords",
+			`{"message":"test"}`,
+			[]interface{}{`{"message":"test"}`},
+		},
+		{
+			"InvalidRecords",
+			`{"records":"test"}`,
+			[]interface{}{`{"records":"test"}`},
+		},
+		{
+			"Text",
+			"test message",
+			[]interface{}{"test message"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, eventBodies([]byte(tc.data)))
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	cfg := NewEventHubInputConfig("test_id")
+	cfg.ConnectionString = testConnectionString + ";EntityPath=logs"
+	cfg.OutputIDs = []string{"fake"}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	eventHubInput := ops[0].(*EventHubInput)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, eventHubInput.SetOutputs([]operator.Operator{fakeOutput}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := eventHubInput.handler(ctx, "3")
+
+	enqueued := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	event := &eventhub.Event{
+		Data:             []byte(`{"records":[{"operationName":"read"},{"operationName":"write"}]}`),
+		SystemProperties: &eventhub.SystemProperties{EnqueuedTime: &enqueued},
+	}
+	require.NoError(t, handler(context.Background(), event))
+
+	for _, operationName := range []string{"read", "write"} {
+		select {
+		case e := <-fakeOutput.Received:
+			require.Equal(t, map[string]interface{}{"operationName": operationName}, e.Body)
+			require.Equal(t, enqueued, e.Timestamp)
+			require.Equal(t, map[string]string{
+				eventHubAttribute:  "logs",
+				partitionAttribute: "3",
+			}, e.Attributes)
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for entry")
+		}
+	}
+
+	// Events are released without being written once the operator stops
+	cancel()
+	require.Error(t, handler(context.Background(), &eventhub.Event{Data: []byte("test")}))
+	expectNoEntry(t, fakeOutput)
+}
+
+func TestCheckpointStore(t *testing.T) {
+	persister := testutil.NewMockPersister("test")
+
+	store := newCheckpointStore(false)
+	cp, err := store.Read("test", "logs", "$Default", "0")
+	require.NoError(t, err)
+	require.Equal(t, persist.NewCheckpoint("", 0, store.startTime), cp)
+
+	written := persist.NewCheckpoint("4294967296", 12, time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, store.Write("test", "logs", "$Default", "0", written))
+	require.NoError(t, store.persist(context.Background(), persister))
+
+	loaded := newCheckpointStore(true)
+	require.NoError(t, loaded.load(context.Background(), persister))
+
+	cp, err = loaded.Read("test", "logs", "$Default", "0")
+	require.NoError(t, err)
+	require.Equal(t, written, cp)
+
+	// Other partitions and consumer groups have their own checkpoints
+	cp, err = loaded.Read("test", "logs", "$Default", "1")
+	require.NoError(t, err)
+	require.Equal(t, persist.NewCheckpointFromStartOfStream(), cp)
+
+	cp, err = loaded.Read("test", "logs", "archive", "0")
+	require.NoError(t, err)
+	require.Equal(t, persist.NewCheckpointFromStartOfStream(), cp)
+}
+
+func expectNoEntry(t *testing.T, fakeOutput *testutil.FakeOutput) {
+	select {
+	case e := <-fakeOutput.Received:
+		require.FailNow(t, "Unexpected entry", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
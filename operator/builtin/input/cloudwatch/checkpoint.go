@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudwatch
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+)
+
+// checkpointsKey is the key under which checkpoints are persisted
+const checkpointsKey = "checkpoints"
+
+// groupCheckpoint is the position reached in a log group. In the filter mode,
+// it is the timestamp of the latest event read, and the IDs of the events read
+// with that timestamp. In the get mode, it is the next token of each log stream.
+type groupCheckpoint struct {
+	Timestamp int64             `json:"timestamp,omitempty"`
+	EventIDs  []string          `json:"event_ids,omitempty"`
+	Streams   map[string]string `json:"streams,omitempty"`
+}
+
+// checkpointStore holds the checkpoints of the log groups
+type checkpointStore struct {
+	sync.Mutex
+	groups map[string]*groupCheckpoint
+	dirty  bool
+}
+
+func newCheckpointStore() *checkpointStore {
+	return &checkpointStore{
+		groups: make(map[string]*groupCheckpoint),
+	}
+}
+
+// get returns a copy of the checkpoint of a log group
+func (s *checkpointStore) get(logGroup string) groupCheckpoint {
+	s.Lock()
+	defer s.Unlock()
+
+	cp, ok := s.groups[logGroup]
+	if !ok {
+		return groupCheckpoint{}
+	}
+
+	copied := groupCheckpoint{
+		Timestamp: cp.Timestamp,
+		EventIDs:  append([]string(nil), cp.EventIDs...),
+	}
+	if cp.Streams != nil {
+		copied.Streams = make(map[string]string, len(cp.Streams))
+		for stream, token := range cp.Streams {
+			copied.Streams[stream] = token
+		}
+	}
+	return copied
+}
+
+func (s *checkpointStore) set(logGroup string, cp groupCheckpoint) {
+	s.Lock()
+	defer s.Unlock()
+	s.groups[logGroup] = &cp
+	s.dirty = true
+}
+
+// load loads the checkpoints from the persister
+func (s *checkpointStore) load(ctx context.Context, persister operator.Persister) error {
+	data, err := persister.Get(ctx, checkpointsKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	return json.Unmarshal(data, &s.groups)
+}
+
+// persist saves the checkpoints to the persister, if they have changed
+func (s *checkpointStore) persist(ctx context.Context, persister operator.Persister) error {
+	s.Lock()
+	defer s.Unlock()
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(s.groups)
+	if err != nil {
+		return err
+	}
+	if err := persister.Set(ctx, checkpointsKey, data); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
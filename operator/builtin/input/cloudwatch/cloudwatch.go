@@ -0,0 +1,275 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudwatch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	startAtBeginning = "beginning"
+	startAtEnd       = "end"
+
+	modeFilter = "filter"
+	modeGet    = "get"
+
+	logGroupAttribute  = "cloudwatch.log_group"
+	logStreamAttribute = "cloudwatch.log_stream"
+)
+
+func init() {
+	operator.Register("cloudwatch_input", func() operator.Builder { return NewCloudWatchInputConfig("") })
+}
+
+// NewCloudWatchInputConfig creates a new CloudWatch input config with default values
+func NewCloudWatchInputConfig(operatorID string) *CloudWatchInputConfig {
+	return &CloudWatchInputConfig{
+		InputConfig:  helper.NewInputConfig(operatorID, "cloudwatch_input"),
+		Mode:         modeFilter,
+		PollInterval: helper.NewDuration(time.Minute),
+		StartAt:      startAtEnd,
+	}
+}
+
+// CloudWatchInputConfig is the configuration of a CloudWatch input operator.
+type CloudWatchInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+	helper.AWSConfig   `mapstructure:",squash" yaml:",inline"`
+
+	Endpoint        string          `mapstructure:"endpoint,omitempty"          json:"endpoint,omitempty"          yaml:"endpoint,omitempty"`
+	LogGroups       []string        `mapstructure:"log_groups,omitempty"        json:"log_groups,omitempty"        yaml:"log_groups,omitempty"`
+	LogGroupPrefix  string          `mapstructure:"log_group_prefix,omitempty"  json:"log_group_prefix,omitempty"  yaml:"log_group_prefix,omitempty"`
+	LogStreamPrefix string          `mapstructure:"log_stream_prefix,omitempty" json:"log_stream_prefix,omitempty" yaml:"log_stream_prefix,omitempty"`
+	FilterPattern   string          `mapstructure:"filter_pattern,omitempty"    json:"filter_pattern,omitempty"    yaml:"filter_pattern,omitempty"`
+	Mode            string          `mapstructure:"mode,omitempty"              json:"mode,omitempty"              yaml:"mode,omitempty"`
+	PollInterval    helper.Duration `mapstructure:"poll_interval,omitempty"     json:"poll_interval,omitempty"     yaml:"poll_interval,omitempty"`
+	StartAt         string          `mapstructure:"start_at,omitempty"          json:"start_at,omitempty"          yaml:"start_at,omitempty"`
+}
+
+// Build will build a CloudWatch input operator.
+func (c CloudWatchInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.LogGroups) == 0 && c.LogGroupPrefix == "" {
+		return nil, fmt.Errorf("one of 'log_groups' or 'log_group_prefix' is required")
+	}
+
+	switch c.Mode {
+	case modeFilter:
+	case modeGet:
+		if c.FilterPattern != "" {
+			return nil, fmt.Errorf("`filter_pattern` can only be used with the `filter` mode")
+		}
+	default:
+		return nil, fmt.Errorf("invalid mode '%s'", c.Mode)
+	}
+
+	if c.PollInterval.Raw() <= 0 {
+		return nil, fmt.Errorf("`poll_interval` must be positive")
+	}
+
+	if c.StartAt != startAtBeginning && c.StartAt != startAtEnd {
+		return nil, fmt.Errorf("invalid start_at location '%s'", c.StartAt)
+	}
+
+	if err := c.AWSConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	cloudWatchInput := &CloudWatchInput{
+		InputOperator:    inputOperator,
+		logGroups:        c.LogGroups,
+		logGroupPrefix:   c.LogGroupPrefix,
+		logStreamPrefix:  c.LogStreamPrefix,
+		filterPattern:    c.FilterPattern,
+		mode:             c.Mode,
+		pollInterval:     c.PollInterval.Raw(),
+		startAtBeginning: c.StartAt == startAtBeginning,
+		newClient:        c.newClient,
+	}
+
+	return []operator.Operator{cloudWatchInput}, nil
+}
+
+// newClient creates a CloudWatch Logs client
+func (c CloudWatchInputConfig) newClient() (cloudwatchlogsiface.CloudWatchLogsAPI, error) {
+	sess, err := c.AWSConfig.NewSession()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Endpoint != "" {
+		return cloudwatchlogs.New(sess, aws.NewConfig().WithEndpoint(c.Endpoint)), nil
+	}
+	return cloudwatchlogs.New(sess), nil
+}
+
+// CloudWatchInput is an operator that reads log events from CloudWatch Logs.
+type CloudWatchInput struct {
+	helper.InputOperator
+	logGroups        []string
+	logGroupPrefix   string
+	logStreamPrefix  string
+	filterPattern    string
+	mode             string
+	pollInterval     time.Duration
+	startAtBeginning bool
+	newClient        func() (cloudwatchlogsiface.CloudWatchLogsAPI, error)
+
+	client      cloudwatchlogsiface.CloudWatchLogsAPI
+	checkpoints *checkpointStore
+	startTime   int64
+	persister   operator.Persister
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+// Start will start polling CloudWatch Logs.
+func (c *CloudWatchInput) Start(persister operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.persister = persister
+
+	client, err := c.newClient()
+	if err != nil {
+		return fmt.Errorf("failed to create cloudwatch logs client: %s", err)
+	}
+	c.client = client
+
+	c.checkpoints = newCheckpointStore()
+	if err := c.checkpoints.load(ctx, persister); err != nil {
+		return fmt.Errorf("failed to load checkpoints: %s", err)
+	}
+
+	// Events are read from the time the operator first starts,
+	// unless they are read from the beginning
+	if !c.startAtBeginning {
+		c.startTime = toMillis(time.Now())
+	}
+
+	c.goPoll(ctx)
+	return nil
+}
+
+// Stop will stop polling CloudWatch Logs.
+func (c *CloudWatchInput) Stop() error {
+	c.cancel()
+	c.wg.Wait()
+	if c.checkpoints == nil {
+		return nil
+	}
+	return c.checkpoints.persist(context.Background(), c.persister)
+}
+
+// goPoll will poll the log groups for new events.
+func (c *CloudWatchInput) goPoll(ctx context.Context) {
+	c.wg.Add(1)
+
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(c.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			c.poll(ctx)
+			if err := c.checkpoints.persist(ctx, c.persister); err != nil {
+				c.Errorw("Failed to persist checkpoints", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// poll reads the new events of each log group
+func (c *CloudWatchInput) poll(ctx context.Context) {
+	logGroups, err := c.listLogGroups(ctx)
+	if err != nil {
+		c.Errorw("Failed to list log groups", zap.Error(err))
+		return
+	}
+
+	for _, logGroup := range logGroups {
+		if ctx.Err() != nil {
+			return
+		}
+
+		var err error
+		switch c.mode {
+		case modeFilter:
+			err = c.filterEvents(ctx, logGroup)
+		case modeGet:
+			err = c.getEvents(ctx, logGroup)
+		}
+		if err != nil {
+			c.Errorw("Failed to read log group", zap.String("log_group", logGroup), zap.Error(err))
+		}
+	}
+}
+
+// listLogGroups returns the configured log groups, and those which match the prefix
+func (c *CloudWatchInput) listLogGroups(ctx context.Context) ([]string, error) {
+	logGroups := append([]string{}, c.logGroups...)
+	if c.logGroupPrefix == "" {
+		return logGroups, nil
+	}
+
+	listed := make(map[string]bool, len(logGroups))
+	for _, logGroup := range logGroups {
+		listed[logGroup] = true
+	}
+
+	input := &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(c.logGroupPrefix),
+	}
+	err := c.client.DescribeLogGroupsPagesWithContext(ctx, input, func(page *cloudwatchlogs.DescribeLogGroupsOutput, _ bool) bool {
+		for _, group := range page.LogGroups {
+			name := aws.StringValue(group.LogGroupName)
+			if !listed[name] {
+				listed[name] = true
+				logGroups = append(logGroups, name)
+			}
+		}
+		return true
+	})
+	return logGroups, err
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
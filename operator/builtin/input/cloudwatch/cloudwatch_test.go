@@ -0,0 +1,434 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudwatch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+type fakeEvent struct {
+	id        string
+	stream    string
+	message   string
+	timestamp int64
+}
+
+// fakeClient serves the events of log groups. Its pages hold a single event, and
+// the tokens of GetLogEvents are the index of the next event of the stream.
+type fakeClient struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+
+	sync.Mutex
+	groups       map[string][]fakeEvent
+	filterInputs []cloudwatchlogs.FilterLogEventsInput
+	getErr       error
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{groups: make(map[string][]fakeEvent)}
+}
+
+func (f *fakeClient) addEvents(logGroup string, events ...fakeEvent) {
+	f.Lock()
+	defer f.Unlock()
+	f.groups[logGroup] = append(f.groups[logGroup], events...)
+}
+
+func (f *fakeClient) DescribeLogGroupsPagesWithContext(_ aws.Context, input *cloudwatchlogs.DescribeLogGroupsInput, fn func(*cloudwatchlogs.DescribeLogGroupsOutput, bool) bool, _ ...request.Option) error {
+	f.Lock()
+	defer f.Unlock()
+
+	page := &cloudwatchlogs.DescribeLogGroupsOutput{}
+	for name := range f.groups {
+		if len(name) >= len(*input.LogGroupNamePrefix) && name[:len(*input.LogGroupNamePrefix)] == *input.LogGroupNamePrefix {
+			page.LogGroups = append(page.LogGroups, &cloudwatchlogs.LogGroup{LogGroupName: aws.String(name)})
+		}
+	}
+	fn(page, true)
+	return nil
+}
+
+func (f *fakeClient) FilterLogEventsPagesWithContext(_ aws.Context, input *cloudwatchlogs.FilterLogEventsInput, fn func(*cloudwatchlogs.FilterLogEventsOutput, bool) bool, _ ...request.Option) error {
+	f.Lock()
+	f.filterInputs = append(f.filterInputs, *input)
+	var events []fakeEvent
+	for _, event := range f.groups[*input.LogGroupName] {
+		if event.timestamp >= aws.Int64Value(input.StartTime) {
+			events = append(events, event)
+		}
+	}
+	f.Unlock()
+
+	for i, event := range events {
+		page := &cloudwatchlogs.FilterLogEventsOutput{
+			Events: []*cloudwatchlogs.FilteredLogEvent{{
+				EventId:       aws.String(event.id),
+				LogStreamName: aws.String(event.stream),
+				Message:       aws.String(event.message),
+				Timestamp:     aws.Int64(event.timestamp),
+			}},
+		}
+		if !fn(page, i == len(events)-1) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (f *fakeClient) DescribeLogStreamsPagesWithContext(_ aws.Context, input *cloudwatchlogs.DescribeLogStreamsInput, fn func(*cloudwatchlogs.DescribeLogStreamsOutput, bool) bool, _ ...request.Option) error {
+	f.Lock()
+	defer f.Unlock()
+
+	page := &cloudwatchlogs.DescribeLogStreamsOutput{}
+	listed := make(map[string]bool)
+	for _, event := range f.groups[*input.LogGroupName] {
+		if !listed[event.stream] {
+			listed[event.stream] = true
+			page.LogStreams = append(page.LogStreams, &cloudwatchlogs.LogStream{LogStreamName: aws.String(event.stream)})
+		}
+	}
+	fn(page, true)
+	return nil
+}
+
+func (f *fakeClient) GetLogEventsWithContext(_ aws.Context, input *cloudwatchlogs.GetLogEventsInput, _ ...request.Option) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+
+	var events []fakeEvent
+	for _, event := range f.groups[*input.LogGroupName] {
+		if event.stream == *input.LogStreamName {
+			events = append(events, event)
+		}
+	}
+
+	next := 0
+	if input.NextToken != nil {
+		_, err := fmt.Sscanf(*input.NextToken, "f/%d", &next)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		for next < len(events) && events[next].timestamp < aws.Int64Value(input.StartTime) {
+			next++
+		}
+	}
+
+	output := &cloudwatchlogs.GetLogEventsOutput{}
+	if next < len(events) {
+		event := events[next]
+		output.Events = []*cloudwatchlogs.OutputLogEvent{{
+			Message:   aws.String(event.message),
+			Timestamp: aws.Int64(event.timestamp),
+		}}
+		next++
+	}
+	output.NextForwardToken = aws.String(fmt.Sprintf("f/%d", next))
+	return output, nil
+}
+
+func newTestCloudWatchInput(t *testing.T, client *fakeClient, cfgMod func(*CloudWatchInputConfig)) (*CloudWatchInput, *testutil.FakeOutput) {
+	cfg := NewCloudWatchInputConfig("test_id")
+	cfg.LogGroups = []string{"/aws/lambda/test"}
+	cfg.StartAt = startAtBeginning
+	cfg.OutputIDs = []string{"fake"}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	cloudWatchInput := ops[0].(*CloudWatchInput)
+	cloudWatchInput.newClient = func() (cloudwatchlogsiface.CloudWatchLogsAPI, error) {
+		return client, nil
+	}
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, cloudWatchInput.SetOutputs([]operator.Operator{fakeOutput}))
+	return cloudWatchInput, fakeOutput
+}
+
+// startPolling starts the operator, which polls immediately. The poll
+// interval is long, so that each test controls further polls.
+func startPolling(t *testing.T, input *CloudWatchInput, persister operator.Persister) {
+	input.pollInterval = time.Hour
+	require.NoError(t, input.Start(persister))
+}
+
+func expectMessages(t *testing.T, output *testutil.FakeOutput, expected ...string) {
+	for _, message := range expected {
+		select {
+		case e := <-output.Received:
+			require.Equal(t, message, e.Body)
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for entry", "Body: %s", message)
+		}
+	}
+}
+
+func expectNoEntry(t *testing.T, output *testutil.FakeOutput) {
+	select {
+	case e := <-output.Received:
+		require.FailNow(t, "Unexpected entry", "Body: %v", e.Body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*CloudWatchInputConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *CloudWatchInputConfig) {},
+			false,
+		},
+		{
+			"LogGroupPrefix",
+			func(cfg *CloudWatchInputConfig) {
+				cfg.LogGroups = nil
+				cfg.LogGroupPrefix = "/aws/lambda/"
+			},
+			false,
+		},
+		{
+			"MissingLogGroups",
+			func(cfg *CloudWatchInputConfig) {
+				cfg.LogGroups = nil
+			},
+			true,
+		},
+		{
+			"GetMode",
+			func(cfg *CloudWatchInputConfig) {
+				cfg.Mode = modeGet
+			},
+			false,
+		},
+		{
+			"GetModeWithFilterPattern",
+			func(cfg *CloudWatchInputConfig) {
+				cfg.Mode = modeGet
+				cfg.FilterPattern = "ERROR"
+			},
+			true,
+		},
+		{
+			"InvalidMode",
+			func(cfg *CloudWatchInputConfig) {
+				cfg.Mode = "tail"
+			},
+			true,
+		},
+		{
+			"ZeroPollInterval",
+			func(cfg *CloudWatchInputConfig) {
+				cfg.PollInterval.Duration = 0
+			},
+			true,
+		},
+		{
+			"InvalidStartAt",
+			func(cfg *CloudWatchInputConfig) {
+				cfg.StartAt = "middle"
+			},
+			true,
+		},
+		{
+			"ExternalIDWithoutRole",
+			func(cfg *CloudWatchInputConfig) {
+				cfg.ExternalID = "secret"
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewCloudWatchInputConfig("test_id")
+			cfg.LogGroups = []string{"/aws/lambda/test"}
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewClientRegion(t *testing.T) {
+	cfg := NewCloudWatchInputConfig("test_id")
+	cfg.Region = "us-east-1"
+	_, err := cfg.newClient()
+	require.NoError(t, err)
+}
+
+func TestFilterEvents(t *testing.T) {
+	client := newFakeClient()
+	client.addEvents("/aws/lambda/test",
+		fakeEvent{"1", "a", "first", 1000},
+		fakeEvent{"2", "b", "second", 2000},
+	)
+	cloudWatchInput, fakeOutput := newTestCloudWatchInput(t, client, nil)
+	startPolling(t, cloudWatchInput, testutil.NewMockPersister("test"))
+	defer func() { require.NoError(t, cloudWatchInput.Stop()) }()
+
+	select {
+	case e := <-fakeOutput.Received:
+		require.Equal(t, "first", e.Body)
+		require.Equal(t, time.Unix(1, 0), e.Timestamp)
+		require.Equal(t, map[string]string{
+			logGroupAttribute:  "/aws/lambda/test",
+			logStreamAttribute: "a",
+		}, e.Attributes)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+	expectMessages(t, fakeOutput, "second")
+	expectNoEntry(t, fakeOutput)
+
+	// events with the latest timestamp which were already read are skipped
+	client.addEvents("/aws/lambda/test",
+		fakeEvent{"3", "a", "third", 2000},
+		fakeEvent{"4", "a", "fourth", 3000},
+	)
+	cloudWatchInput.poll(context.Background())
+	expectMessages(t, fakeOutput, "third", "fourth")
+	expectNoEntry(t, fakeOutput)
+
+	client.Lock()
+	defer client.Unlock()
+	require.Equal(t, int64(2000), aws.Int64Value(client.filterInputs[1].StartTime))
+}
+
+func TestFilterEventsStartAtEnd(t *testing.T) {
+	client := newFakeClient()
+	client.addEvents("/aws/lambda/test", fakeEvent{"1", "a", "old", 1000})
+	cloudWatchInput, fakeOutput := newTestCloudWatchInput(t, client, func(cfg *CloudWatchInputConfig) {
+		cfg.StartAt = startAtEnd
+		cfg.FilterPattern = "ERROR"
+		cfg.LogStreamPrefix = "2021/"
+	})
+	startPolling(t, cloudWatchInput, testutil.NewMockPersister("test"))
+	defer func() { require.NoError(t, cloudWatchInput.Stop()) }()
+	expectNoEntry(t, fakeOutput)
+
+	client.addEvents("/aws/lambda/test", fakeEvent{"2", "a", "new", toMillis(time.Now())})
+	cloudWatchInput.poll(context.Background())
+	expectMessages(t, fakeOutput, "new")
+
+	client.Lock()
+	defer client.Unlock()
+	require.Equal(t, "ERROR", aws.StringValue(client.filterInputs[0].FilterPattern))
+	require.Equal(t, "2021/", aws.StringValue(client.filterInputs[0].LogStreamNamePrefix))
+}
+
+func TestGetEvents(t *testing.T) {
+	client := newFakeClient()
+	client.addEvents("/aws/lambda/test",
+		fakeEvent{"1", "a", "a1", 1000},
+		fakeEvent{"2", "b", "b1", 1000},
+		fakeEvent{"3", "a", "a2", 2000},
+	)
+	cloudWatchInput, fakeOutput := newTestCloudWatchInput(t, client, func(cfg *CloudWatchInputConfig) {
+		cfg.Mode = modeGet
+	})
+	startPolling(t, cloudWatchInput, testutil.NewMockPersister("test"))
+	defer func() { require.NoError(t, cloudWatchInput.Stop()) }()
+
+	expectMessages(t, fakeOutput, "a1", "a2", "b1")
+	expectNoEntry(t, fakeOutput)
+
+	client.addEvents("/aws/lambda/test", fakeEvent{"4", "b", "b2", 3000})
+	cloudWatchInput.poll(context.Background())
+	expectMessages(t, fakeOutput, "b2")
+	expectNoEntry(t, fakeOutput)
+
+	require.Equal(t, map[string]string{"a": "f/2", "b": "f/2"}, cloudWatchInput.checkpoints.get("/aws/lambda/test").Streams)
+}
+
+func TestGetEventsError(t *testing.T) {
+	client := newFakeClient()
+	client.addEvents("/aws/lambda/test", fakeEvent{"1", "a", "a1", 1000})
+	cloudWatchInput, fakeOutput := newTestCloudWatchInput(t, client, func(cfg *CloudWatchInputConfig) {
+		cfg.Mode = modeGet
+	})
+	startPolling(t, cloudWatchInput, testutil.NewMockPersister("test"))
+	defer func() { require.NoError(t, cloudWatchInput.Stop()) }()
+	expectMessages(t, fakeOutput, "a1")
+
+	// the tokens are kept when the events can not be read
+	client.Lock()
+	client.getErr = fmt.Errorf("throttled")
+	client.Unlock()
+	cloudWatchInput.poll(context.Background())
+	require.Equal(t, map[string]string{"a": "f/1"}, cloudWatchInput.checkpoints.get("/aws/lambda/test").Streams)
+}
+
+func TestLogGroupPrefix(t *testing.T) {
+	client := newFakeClient()
+	client.addEvents("/aws/lambda/one", fakeEvent{"1", "a", "one", 1000})
+	client.addEvents("/aws/lambda/two", fakeEvent{"1", "a", "two", 1000})
+	client.addEvents("/aws/rds/db", fakeEvent{"1", "a", "db", 1000})
+	cloudWatchInput, _ := newTestCloudWatchInput(t, client, func(cfg *CloudWatchInputConfig) {
+		cfg.LogGroups = []string{"/aws/lambda/one", "/aws/vpc/flow"}
+		cfg.LogGroupPrefix = "/aws/lambda/"
+	})
+	cloudWatchInput.client = client
+
+	logGroups, err := cloudWatchInput.listLogGroups(context.Background())
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"/aws/lambda/one", "/aws/vpc/flow", "/aws/lambda/two"}, logGroups)
+}
+
+func TestCheckpointsPersisted(t *testing.T) {
+	client := newFakeClient()
+	client.addEvents("/aws/lambda/test", fakeEvent{"1", "a", "first", 1000})
+	persister := testutil.NewMockPersister("test")
+
+	cloudWatchInput, fakeOutput := newTestCloudWatchInput(t, client, nil)
+	startPolling(t, cloudWatchInput, persister)
+	expectMessages(t, fakeOutput, "first")
+	require.NoError(t, cloudWatchInput.Stop())
+
+	// events already read are not read again when the operator restarts
+	client.addEvents("/aws/lambda/test", fakeEvent{"2", "a", "second", 2000})
+	cloudWatchInput, fakeOutput = newTestCloudWatchInput(t, client, nil)
+	startPolling(t, cloudWatchInput, persister)
+	defer func() { require.NoError(t, cloudWatchInput.Stop()) }()
+	expectMessages(t, fakeOutput, "second")
+	expectNoEntry(t, fakeOutput)
+}
@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudwatch
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"go.uber.org/zap"
+)
+
+// filterEvents reads the events of a log group with FilterLogEvents, starting
+// from the timestamp of the latest event already read. Events with that
+// timestamp are read again, so the IDs of those already read are skipped.
+func (c *CloudWatchInput) filterEvents(ctx context.Context, logGroup string) error {
+	cp := c.checkpoints.get(logGroup)
+	seen := make(map[string]bool, len(cp.EventIDs))
+	for _, id := range cp.EventIDs {
+		seen[id] = true
+	}
+
+	input := &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName: aws.String(logGroup),
+	}
+	if c.filterPattern != "" {
+		input.FilterPattern = aws.String(c.filterPattern)
+	}
+	if c.logStreamPrefix != "" {
+		input.LogStreamNamePrefix = aws.String(c.logStreamPrefix)
+	}
+	if start := cp.Timestamp; start > 0 {
+		input.StartTime = aws.Int64(start)
+	} else if c.startTime > 0 {
+		input.StartTime = aws.Int64(c.startTime)
+	}
+
+	var changed bool
+	err := c.client.FilterLogEventsPagesWithContext(ctx, input, func(page *cloudwatchlogs.FilterLogEventsOutput, _ bool) bool {
+		for _, event := range page.Events {
+			timestamp := aws.Int64Value(event.Timestamp)
+			id := aws.StringValue(event.EventId)
+			if timestamp == cp.Timestamp && seen[id] {
+				continue
+			}
+
+			c.emit(ctx, logGroup, aws.StringValue(event.LogStreamName), aws.StringValue(event.Message), timestamp)
+			changed = true
+
+			if timestamp > cp.Timestamp {
+				cp.Timestamp = timestamp
+				cp.EventIDs = nil
+				seen = make(map[string]bool)
+			}
+			if timestamp == cp.Timestamp {
+				cp.EventIDs = append(cp.EventIDs, id)
+				seen[id] = true
+			}
+		}
+		return ctx.Err() == nil
+	})
+
+	if changed {
+		c.checkpoints.set(logGroup, cp)
+	}
+	return err
+}
+
+// getEvents reads the events of each log stream of a log group with
+// GetLogEvents, starting from the next token returned by the last read
+func (c *CloudWatchInput) getEvents(ctx context.Context, logGroup string) error {
+	streams, err := c.listLogStreams(ctx, logGroup)
+	if err != nil {
+		return err
+	}
+
+	cp := c.checkpoints.get(logGroup)
+	tokens := make(map[string]string, len(streams))
+	defer func() {
+		cp.Streams = tokens
+		c.checkpoints.set(logGroup, cp)
+	}()
+
+	for i, stream := range streams {
+		token, err := c.getStreamEvents(ctx, logGroup, stream, cp.Streams[stream])
+		if token != "" {
+			tokens[stream] = token
+		}
+		if err != nil {
+			// the tokens of the streams which have not been read are kept
+			for _, unread := range streams[i+1:] {
+				if token, ok := cp.Streams[unread]; ok {
+					tokens[unread] = token
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// getStreamEvents reads the events of a log stream until the end of the
+// stream, which is reached when the same token is returned again. It
+// returns the token from which the next events of the stream are read.
+func (c *CloudWatchInput) getStreamEvents(ctx context.Context, logGroup, stream, token string) (string, error) {
+	input := &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String(logGroup),
+		LogStreamName: aws.String(stream),
+		StartFromHead: aws.Bool(true),
+	}
+	if token != "" {
+		input.NextToken = aws.String(token)
+	} else if c.startTime > 0 {
+		input.StartTime = aws.Int64(c.startTime)
+	}
+
+	for ctx.Err() == nil {
+		output, err := c.client.GetLogEventsWithContext(ctx, input)
+		if err != nil {
+			return token, err
+		}
+
+		for _, event := range output.Events {
+			c.emit(ctx, logGroup, stream, aws.StringValue(event.Message), aws.Int64Value(event.Timestamp))
+		}
+
+		next := aws.StringValue(output.NextForwardToken)
+		if next == "" || next == token {
+			break
+		}
+		token = next
+		input.NextToken = aws.String(next)
+		input.StartTime = nil
+	}
+	return token, nil
+}
+
+// listLogStreams returns the names of the log streams of
+// a log group which match the configured prefix
+func (c *CloudWatchInput) listLogStreams(ctx context.Context, logGroup string) ([]string, error) {
+	input := &cloudwatchlogs.DescribeLogStreamsInput{
+		LogGroupName: aws.String(logGroup),
+	}
+	if c.logStreamPrefix != "" {
+		input.LogStreamNamePrefix = aws.String(c.logStreamPrefix)
+	}
+
+	var streams []string
+	err := c.client.DescribeLogStreamsPagesWithContext(ctx, input, func(page *cloudwatchlogs.DescribeLogStreamsOutput, _ bool) bool {
+		for _, stream := range page.LogStreams {
+			streams = append(streams, aws.StringValue(stream.LogStreamName))
+		}
+		return true
+	})
+	return streams, err
+}
+
+func (c *CloudWatchInput) emit(ctx context.Context, logGroup, stream, message string, timestamp int64) {
+	entry, err := c.NewEntry(message)
+	if err != nil {
+		c.Errorw("Failed to create entry", zap.Error(err))
+		return
+	}
+	entry.Timestamp = fromMillis(timestamp)
+	entry.AddAttribute(logGroupAttribute, logGroup)
+	entry.AddAttribute(logStreamAttribute, stream)
+	c.Write(ctx, entry)
+}
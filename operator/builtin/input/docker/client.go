@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// container is the subset of a docker container's details used by the operator
+type container struct {
+	ID      string `json:"Id"`
+	Name    string `json:"Name"`
+	LogPath string `json:"LogPath"`
+	Config  struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	HostConfig struct {
+		LogConfig struct {
+			Type string `json:"Type"`
+		} `json:"LogConfig"`
+	} `json:"HostConfig"`
+}
+
+// dockerClient is a minimal client of the docker engine API
+type dockerClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+// newDockerClient creates a client of the docker engine at the host, which
+// is either a unix socket of the form unix:///path, or a tcp address of
+// the form tcp://host:port
+func newDockerClient(host string, apiVersion string) (*dockerClient, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{}
+	var baseURL string
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+		baseURL = "http://docker"
+	case "tcp", "http":
+		if u.Host == "" {
+			return nil, fmt.Errorf("missing address")
+		}
+		baseURL = "http://" + u.Host
+	default:
+		return nil, fmt.Errorf("unsupported scheme '%s'", u.Scheme)
+	}
+
+	if apiVersion != "" {
+		baseURL += "/v" + strings.TrimPrefix(apiVersion, "v")
+	}
+
+	return &dockerClient{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   10 * time.Second,
+		},
+		baseURL: baseURL,
+	}, nil
+}
+
+// containerSummary is the subset of a listed container used by the operator
+type containerSummary struct {
+	ID    string `json:"Id"`
+	State string `json:"State"`
+}
+
+// listContainers lists all containers, including those which are not running
+func (c *dockerClient) listContainers(ctx context.Context) ([]containerSummary, error) {
+	var containers []containerSummary
+	if err := c.get(ctx, "/containers/json?all=1", &containers); err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+// inspectContainer gets the details of a container
+func (c *dockerClient) inspectContainer(ctx context.Context, id string) (*container, error) {
+	details := &container{}
+	if err := c.get(ctx, "/containers/"+url.PathEscape(id)+"/json", details); err != nil {
+		return nil, err
+	}
+	details.Name = strings.TrimPrefix(details.Name, "/")
+	return details, nil
+}
+
+func (c *dockerClient) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request to %s failed with status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
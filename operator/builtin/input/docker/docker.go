@@ -0,0 +1,395 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	startAtBeginning = "beginning"
+	startAtEnd       = "end"
+
+	// jsonFileDriver is the only log driver whose logs can be read
+	jsonFileDriver = "json-file"
+
+	// offsetsKey is the key under which the offsets of container logs are persisted
+	offsetsKey = "offsets"
+
+	// persistInterval is how often the offsets of container logs are persisted
+	persistInterval = time.Second
+
+	defaultMaxLogSize = 1024 * 1024
+)
+
+func init() {
+	operator.Register("docker_input", func() operator.Builder { return NewDockerInputConfig("") })
+}
+
+// NewDockerInputConfig creates a new docker input config with default values
+func NewDockerInputConfig(operatorID string) *DockerInputConfig {
+	return &DockerInputConfig{
+		InputConfig:       helper.NewInputConfig(operatorID, "docker_input"),
+		DockerHost:        "unix:///var/run/docker.sock",
+		APIVersion:        "1.24",
+		PollInterval:      helper.NewDuration(200 * time.Millisecond),
+		DiscoveryInterval: helper.NewDuration(10 * time.Second),
+		StartAt:           startAtEnd,
+		MaxLogSize:        defaultMaxLogSize,
+	}
+}
+
+// DockerInputConfig is the configuration of a docker input operator.
+type DockerInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	DockerHost        string          `mapstructure:"docker_host,omitempty"        json:"docker_host,omitempty"        yaml:"docker_host,omitempty"`
+	APIVersion        string          `mapstructure:"api_version,omitempty"        json:"api_version,omitempty"        yaml:"api_version,omitempty"`
+	PollInterval      helper.Duration `mapstructure:"poll_interval,omitempty"      json:"poll_interval,omitempty"      yaml:"poll_interval,omitempty"`
+	DiscoveryInterval helper.Duration `mapstructure:"discovery_interval,omitempty" json:"discovery_interval,omitempty" yaml:"discovery_interval,omitempty"`
+	IncludeContainers []string        `mapstructure:"include_containers,omitempty" json:"include_containers,omitempty" yaml:"include_containers,omitempty"`
+	ExcludeContainers []string        `mapstructure:"exclude_containers,omitempty" json:"exclude_containers,omitempty" yaml:"exclude_containers,omitempty"`
+	StartAt           string          `mapstructure:"start_at,omitempty"           json:"start_at,omitempty"           yaml:"start_at,omitempty"`
+	MaxLogSize        helper.ByteSize `mapstructure:"max_log_size,omitempty"       json:"max_log_size,omitempty"       yaml:"max_log_size,omitempty"`
+}
+
+// Build will build a docker input operator.
+func (c DockerInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.DockerHost == "" {
+		return nil, fmt.Errorf("missing required parameter 'docker_host'")
+	}
+
+	client, err := newDockerClient(c.DockerHost, c.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker_host '%s': %s", c.DockerHost, err)
+	}
+
+	if c.PollInterval.Raw() <= 0 {
+		return nil, fmt.Errorf("`poll_interval` must be positive")
+	}
+	if c.DiscoveryInterval.Raw() <= 0 {
+		return nil, fmt.Errorf("`discovery_interval` must be positive")
+	}
+	if c.MaxLogSize <= 0 {
+		return nil, fmt.Errorf("`max_log_size` must be positive")
+	}
+
+	for _, pattern := range append(c.IncludeContainers, c.ExcludeContainers...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid container pattern '%s': %s", pattern, err)
+		}
+	}
+
+	if c.StartAt != startAtBeginning && c.StartAt != startAtEnd {
+		return nil, fmt.Errorf("invalid start_at location '%s'", c.StartAt)
+	}
+
+	dockerInput := &DockerInput{
+		InputOperator:     inputOperator,
+		client:            client,
+		pollInterval:      c.PollInterval.Raw(),
+		discoveryInterval: c.DiscoveryInterval.Raw(),
+		include:           c.IncludeContainers,
+		exclude:           c.ExcludeContainers,
+		startAtBeginning:  c.StartAt == startAtBeginning,
+		maxLogSize:        int(c.MaxLogSize),
+	}
+
+	return []operator.Operator{dockerInput}, nil
+}
+
+// DockerInput is an operator that reads the logs of docker containers.
+type DockerInput struct {
+	helper.InputOperator
+	client            *dockerClient
+	pollInterval      time.Duration
+	discoveryInterval time.Duration
+	include           []string
+	exclude           []string
+	startAtBeginning  bool
+	maxLogSize        int
+
+	// readers holds the readers of the running containers, and ignored
+	// holds the containers whose logs are not read
+	readers map[string]*containerReader
+	ignored map[string]bool
+
+	offsets   map[string]int64
+	dirty     bool
+	persister operator.Persister
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// Start will start reading the logs of docker containers.
+func (d *DockerInput) Start(persister operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.persister = persister
+	d.readers = make(map[string]*containerReader)
+	d.ignored = make(map[string]bool)
+	d.offsets = make(map[string]int64)
+
+	if err := d.loadOffsets(ctx); err != nil {
+		return fmt.Errorf("failed to load offsets: %s", err)
+	}
+
+	// containers which are already running when the operator first
+	// starts are read from start_at, and any later ones from the beginning
+	if err := d.discover(ctx, !d.startAtBeginning && len(d.offsets) == 0); err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	d.goPoll(ctx)
+	return nil
+}
+
+// Stop will stop reading the logs of docker containers.
+func (d *DockerInput) Stop() error {
+	d.cancel()
+	d.wg.Wait()
+
+	for id, reader := range d.readers {
+		d.setOffset(id, reader.committed)
+		reader.Close()
+	}
+	d.readers = nil
+	return d.persistOffsets(context.Background())
+}
+
+// goPoll will periodically read the logs of containers, and discover
+// containers which have been started or removed.
+func (d *DockerInput) goPoll(ctx context.Context) {
+	d.wg.Add(1)
+
+	go func() {
+		defer d.wg.Done()
+
+		pollTicker := time.NewTicker(d.pollInterval)
+		defer pollTicker.Stop()
+		discoveryTicker := time.NewTicker(d.discoveryInterval)
+		defer discoveryTicker.Stop()
+		persistTicker := time.NewTicker(persistInterval)
+		defer persistTicker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-pollTicker.C:
+				d.poll(ctx)
+			case <-discoveryTicker.C:
+				if err := d.discover(ctx, false); err != nil {
+					d.Errorw("Failed to list containers", zap.Error(err))
+				}
+			case <-persistTicker.C:
+				if err := d.persistOffsets(ctx); err != nil {
+					d.Errorw("Failed to persist offsets", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// poll reads the logs of all running containers
+func (d *DockerInput) poll(ctx context.Context) {
+	for id, reader := range d.readers {
+		d.read(ctx, reader)
+		d.setOffset(id, reader.committed)
+	}
+}
+
+func (d *DockerInput) read(ctx context.Context, reader *containerReader) {
+	emit := func(stream, log string, ts time.Time) {
+		entry, err := d.NewEntry(log)
+		if err != nil {
+			d.Errorw("Failed to create entry", zap.Error(err))
+			return
+		}
+		entry.Timestamp = ts
+		for key, value := range reader.attributes {
+			entry.AddAttribute(key, value)
+		}
+		entry.AddAttribute("log.iostream", stream)
+		d.Write(ctx, entry)
+	}
+
+	if err := reader.readLines(emit, d.maxLogSize); err != nil {
+		d.Errorw("Failed to read container log", zap.String("container_id", reader.id), zap.Error(err))
+	}
+}
+
+// discover lists the containers, starts reading the logs of those which have
+// started, and stops reading the logs of those which have stopped
+func (d *DockerInput) discover(ctx context.Context, skipExisting bool) error {
+	containers, err := d.client.listContainers(ctx)
+	if err != nil {
+		return err
+	}
+
+	listed := make(map[string]bool, len(containers))
+	for _, summary := range containers {
+		listed[summary.ID] = true
+		reader, reading := d.readers[summary.ID]
+
+		if summary.State != "running" {
+			if reading {
+				// the offset is kept in case the container is restarted
+				d.read(ctx, reader)
+				d.setOffset(summary.ID, reader.committed)
+				reader.Close()
+				delete(d.readers, summary.ID)
+			}
+			continue
+		}
+
+		if reading || d.ignored[summary.ID] {
+			continue
+		}
+
+		if err := d.startReading(ctx, summary.ID, skipExisting); err != nil {
+			d.Errorw("Failed to read container log", zap.String("container_id", summary.ID), zap.Error(err))
+		}
+	}
+
+	for id, reader := range d.readers {
+		if !listed[id] {
+			d.read(ctx, reader)
+			reader.Close()
+			delete(d.readers, id)
+		}
+	}
+	for id := range d.offsets {
+		if !listed[id] {
+			delete(d.offsets, id)
+			d.dirty = true
+		}
+	}
+	for id := range d.ignored {
+		if !listed[id] {
+			delete(d.ignored, id)
+		}
+	}
+	return nil
+}
+
+func (d *DockerInput) startReading(ctx context.Context, id string, skipExisting bool) error {
+	details, err := d.client.inspectContainer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !d.matches(details.Name) {
+		d.ignored[id] = true
+		return nil
+	}
+	if details.HostConfig.LogConfig.Type != jsonFileDriver || details.LogPath == "" {
+		d.Debugw("Ignoring container which does not use the json-file log driver",
+			zap.String("container_name", details.Name),
+			zap.String("log_driver", details.HostConfig.LogConfig.Type))
+		d.ignored[id] = true
+		return nil
+	}
+
+	offset, ok := d.offsets[id]
+	reader, err := newContainerReader(details, offset)
+	if err != nil {
+		return err
+	}
+
+	if !ok && skipExisting {
+		info, err := reader.file.Stat()
+		if err != nil {
+			reader.Close()
+			return err
+		}
+		reader.offset = info.Size()
+		reader.committed = info.Size()
+	}
+
+	d.Debugw("Started reading container log", zap.String("container_name", details.Name))
+	d.readers[id] = reader
+	d.setOffset(id, reader.committed)
+	return nil
+}
+
+// matches returns whether the logs of the container with the name are read
+func (d *DockerInput) matches(name string) bool {
+	for _, pattern := range d.exclude {
+		if ok, _ := path.Match(pattern, name); ok {
+			return false
+		}
+	}
+	if len(d.include) == 0 {
+		return true
+	}
+	for _, pattern := range d.include {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *DockerInput) setOffset(id string, offset int64) {
+	if current, ok := d.offsets[id]; !ok || current != offset {
+		d.offsets[id] = offset
+		d.dirty = true
+	}
+}
+
+// loadOffsets loads the offsets of container logs from the persister
+func (d *DockerInput) loadOffsets(ctx context.Context) error {
+	data, err := d.persister.Get(ctx, offsetsKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &d.offsets)
+}
+
+// persistOffsets saves the offsets of container logs to the persister, if they have changed
+func (d *DockerInput) persistOffsets(ctx context.Context) error {
+	if !d.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(d.offsets)
+	if err != nil {
+		return err
+	}
+	if err := d.persister.Set(ctx, offsetsKey, data); err != nil {
+		return err
+	}
+	d.dirty = false
+	return nil
+}
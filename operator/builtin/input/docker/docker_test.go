@@ -0,0 +1,340 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// fakeDocker serves the parts of the docker engine API used by the operator
+type fakeDocker struct {
+	sync.Mutex
+	containers map[string]*container
+	states     map[string]string
+	dir        string
+}
+
+func newFakeDocker(t *testing.T) (*fakeDocker, string) {
+	docker := &fakeDocker{
+		containers: make(map[string]*container),
+		states:     make(map[string]string),
+		dir:        t.TempDir(),
+	}
+	server := httptest.NewServer(docker)
+	t.Cleanup(server.Close)
+	return docker, strings.Replace(server.URL, "http://", "tcp://", 1)
+}
+
+func (f *fakeDocker) addContainer(t *testing.T, id, name, driver string) string {
+	f.Lock()
+	defer f.Unlock()
+
+	details := &container{ID: id, Name: "/" + name}
+	details.Config.Image = "image-" + name
+	details.HostConfig.LogConfig.Type = driver
+	if driver == jsonFileDriver {
+		details.LogPath = filepath.Join(f.dir, id+"-json.log")
+		require.NoError(t, ioutil.WriteFile(details.LogPath, nil, 0600))
+	}
+	f.containers[id] = details
+	f.states[id] = "running"
+	return details.LogPath
+}
+
+func (f *fakeDocker) setState(id, state string) {
+	f.Lock()
+	defer f.Unlock()
+	f.states[id] = state
+}
+
+func (f *fakeDocker) removeContainer(id string) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.containers, id)
+	delete(f.states, id)
+}
+
+func (f *fakeDocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1.24")
+	if path == "/containers/json" {
+		summaries := make([]containerSummary, 0, len(f.containers))
+		for id := range f.containers {
+			summaries = append(summaries, containerSummary{ID: id, State: f.states[id]})
+		}
+		_ = json.NewEncoder(w).Encode(summaries)
+		return
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(path, "/containers/"), "/json")
+	details, ok := f.containers[id]
+	if !ok {
+		http.Error(w, `{"message":"No such container"}`, http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(details)
+}
+
+func newTestDockerInput(t *testing.T, host string, cfgMod func(*DockerInputConfig)) (*DockerInput, *testutil.FakeOutput) {
+	cfg := NewDockerInputConfig("test_id")
+	cfg.DockerHost = host
+	cfg.OutputIDs = []string{"fake"}
+	cfg.PollInterval.Duration = 10 * time.Millisecond
+	cfg.DiscoveryInterval.Duration = 20 * time.Millisecond
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	dockerInput := ops[0].(*DockerInput)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, dockerInput.SetOutputs([]operator.Operator{fakeOutput}))
+	return dockerInput, fakeOutput
+}
+
+func expectEntry(t *testing.T, output *testutil.FakeOutput, body string, attributes map[string]string) {
+	select {
+	case e := <-output.Received:
+		require.Equal(t, body, e.Body)
+		for key, value := range attributes {
+			require.Equal(t, value, e.Attributes[key], key)
+		}
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry", "Body: %s", body)
+	}
+}
+
+func expectNoEntry(t *testing.T, output *testutil.FakeOutput) {
+	select {
+	case e := <-output.Received:
+		require.FailNow(t, "Unexpected entry", "Body: %v", e.Body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*DockerInputConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *DockerInputConfig) {},
+			false,
+		},
+		{
+			"TCPHost",
+			func(cfg *DockerInputConfig) {
+				cfg.DockerHost = "tcp://127.0.0.1:2375"
+			},
+			false,
+		},
+		{
+			"MissingHost",
+			func(cfg *DockerInputConfig) {
+				cfg.DockerHost = ""
+			},
+			true,
+		},
+		{
+			"UnsupportedHostScheme",
+			func(cfg *DockerInputConfig) {
+				cfg.DockerHost = "npipe:////./pipe/docker_engine"
+			},
+			true,
+		},
+		{
+			"ZeroPollInterval",
+			func(cfg *DockerInputConfig) {
+				cfg.PollInterval.Duration = 0
+			},
+			true,
+		},
+		{
+			"ZeroDiscoveryInterval",
+			func(cfg *DockerInputConfig) {
+				cfg.DiscoveryInterval.Duration = 0
+			},
+			true,
+		},
+		{
+			"ZeroMaxLogSize",
+			func(cfg *DockerInputConfig) {
+				cfg.MaxLogSize = 0
+			},
+			true,
+		},
+		{
+			"InvalidContainerPattern",
+			func(cfg *DockerInputConfig) {
+				cfg.ExcludeContainers = []string{"[web"}
+			},
+			true,
+		},
+		{
+			"InvalidStartAt",
+			func(cfg *DockerInputConfig) {
+				cfg.StartAt = "middle"
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewDockerInputConfig("test_id")
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDockerInput(t *testing.T) {
+	docker, host := newFakeDocker(t)
+	webLog := docker.addContainer(t, "web-id", "web", jsonFileDriver)
+	ts := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	appendLog(t, webLog, logLine{Log: "before start\n", Stream: "stdout", Time: ts})
+
+	dockerInput, fakeOutput := newTestDockerInput(t, host, nil)
+	require.NoError(t, dockerInput.Start(testutil.NewMockPersister("test")))
+	defer func() { require.NoError(t, dockerInput.Stop()) }()
+
+	// logs written before the operator started are skipped
+	appendLog(t, webLog, logLine{Log: "after start\n", Stream: "stdout", Time: ts})
+	expectEntry(t, fakeOutput, "after start", map[string]string{
+		"container.id":         "web-id",
+		"container.name":       "web",
+		"container.image.name": "image-web",
+		"log.iostream":         "stdout",
+	})
+
+	// logs of containers started later are read from the beginning
+	dbLog := docker.addContainer(t, "db-id", "db", jsonFileDriver)
+	appendLog(t, dbLog,
+		logLine{Log: "partial ", Stream: "stderr", Time: ts},
+		logLine{Log: "line\n", Stream: "stderr", Time: ts},
+	)
+	expectEntry(t, fakeOutput, "partial line", map[string]string{
+		"container.name": "db",
+		"log.iostream":   "stderr",
+	})
+	expectNoEntry(t, fakeOutput)
+}
+
+func TestDockerInputStartAtBeginning(t *testing.T) {
+	docker, host := newFakeDocker(t)
+	webLog := docker.addContainer(t, "web-id", "web", jsonFileDriver)
+	appendLog(t, webLog, logLine{Log: "before start\n", Stream: "stdout", Time: time.Now()})
+
+	dockerInput, fakeOutput := newTestDockerInput(t, host, func(cfg *DockerInputConfig) {
+		cfg.StartAt = startAtBeginning
+	})
+	require.NoError(t, dockerInput.Start(testutil.NewMockPersister("test")))
+	defer func() { require.NoError(t, dockerInput.Stop()) }()
+
+	expectEntry(t, fakeOutput, "before start", nil)
+}
+
+func TestDockerInputIgnoredContainers(t *testing.T) {
+	docker, host := newFakeDocker(t)
+	docker.addContainer(t, "syslog-id", "syslog", "syslog")
+	collectorLog := docker.addContainer(t, "collector-id", "collector", jsonFileDriver)
+	webLog := docker.addContainer(t, "web-id", "web", jsonFileDriver)
+
+	dockerInput, fakeOutput := newTestDockerInput(t, host, func(cfg *DockerInputConfig) {
+		cfg.ExcludeContainers = []string{"coll*"}
+	})
+	require.NoError(t, dockerInput.Start(testutil.NewMockPersister("test")))
+	defer func() { require.NoError(t, dockerInput.Stop()) }()
+
+	appendLog(t, collectorLog, logLine{Log: "excluded\n", Stream: "stdout", Time: time.Now()})
+	appendLog(t, webLog, logLine{Log: "included\n", Stream: "stdout", Time: time.Now()})
+	expectEntry(t, fakeOutput, "included", nil)
+	expectNoEntry(t, fakeOutput)
+}
+
+func TestDockerInputStoppedContainer(t *testing.T) {
+	docker, host := newFakeDocker(t)
+	webLog := docker.addContainer(t, "web-id", "web", jsonFileDriver)
+
+	dockerInput, fakeOutput := newTestDockerInput(t, host, nil)
+	require.NoError(t, dockerInput.Start(testutil.NewMockPersister("test")))
+	defer func() { require.NoError(t, dockerInput.Stop()) }()
+
+	appendLog(t, webLog, logLine{Log: "first\n", Stream: "stdout", Time: time.Now()})
+	expectEntry(t, fakeOutput, "first", nil)
+
+	// the log is read from the same offset when the container is restarted
+	docker.setState("web-id", "exited")
+	time.Sleep(100 * time.Millisecond)
+	appendLog(t, webLog, logLine{Log: "second\n", Stream: "stdout", Time: time.Now()})
+	expectNoEntry(t, fakeOutput)
+
+	docker.setState("web-id", "running")
+	expectEntry(t, fakeOutput, "second", nil)
+}
+
+func TestDockerInputOffsets(t *testing.T) {
+	docker, host := newFakeDocker(t)
+	webLog := docker.addContainer(t, "web-id", "web", jsonFileDriver)
+	persister := testutil.NewMockPersister("test")
+
+	dockerInput, fakeOutput := newTestDockerInput(t, host, nil)
+	require.NoError(t, dockerInput.Start(persister))
+	appendLog(t, webLog, logLine{Log: "first\n", Stream: "stdout", Time: time.Now()})
+	expectEntry(t, fakeOutput, "first", nil)
+	require.NoError(t, dockerInput.Stop())
+
+	// logs written while the operator was stopped are read when it restarts
+	appendLog(t, webLog, logLine{Log: "second\n", Stream: "stdout", Time: time.Now()})
+	dockerInput, fakeOutput = newTestDockerInput(t, host, nil)
+	require.NoError(t, dockerInput.Start(persister))
+	expectEntry(t, fakeOutput, "second", nil)
+	expectNoEntry(t, fakeOutput)
+
+	// the offset is forgotten when the container is removed
+	docker.removeContainer("web-id")
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, dockerInput.Stop())
+
+	data, err := persister.Get(context.Background(), offsetsKey)
+	require.NoError(t, err)
+	require.JSONEq(t, `{}`, string(data))
+}
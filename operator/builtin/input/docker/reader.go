@@ -0,0 +1,181 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// logLine is a line of a json-file log
+type logLine struct {
+	Log    string    `json:"log"`
+	Stream string    `json:"stream"`
+	Time   time.Time `json:"time"`
+}
+
+// partialLine holds the parts of a line which docker split
+// into several log lines because of its length
+type partialLine struct {
+	builder strings.Builder
+	time    time.Time
+}
+
+// containerReader reads the json-file log of a container
+type containerReader struct {
+	id         string
+	path       string
+	attributes map[string]string
+
+	file   *os.File
+	offset int64
+
+	// committed is the offset after the last complete line,
+	// from which reading resumes when the operator restarts
+	committed int64
+	partial   map[string]*partialLine
+}
+
+func newContainerReader(details *container, offset int64) (*containerReader, error) {
+	file, err := os.Open(details.LogPath)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := map[string]string{
+		"container.id":         details.ID,
+		"container.name":       details.Name,
+		"container.image.name": details.Config.Image,
+	}
+	for key, value := range details.Config.Labels {
+		attributes["container.label."+key] = value
+	}
+
+	return &containerReader{
+		id:         details.ID,
+		path:       details.LogPath,
+		attributes: attributes,
+		file:       file,
+		offset:     offset,
+		committed:  offset,
+		partial:    make(map[string]*partialLine),
+	}, nil
+}
+
+// readLines reads the complete lines written since the last read. When
+// the log has been rotated, the rest of the old log is read before the
+// new log is opened.
+func (r *containerReader) readLines(emit func(stream, log string, ts time.Time), maxLogSize int) error {
+	if err := r.readToEnd(emit, maxLogSize); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	current, err := r.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !os.SameFile(current, info):
+		file, err := os.Open(r.path)
+		if err != nil {
+			return err
+		}
+		r.file.Close()
+		r.file = file
+	case info.Size() < r.offset:
+		// the log was truncated
+	default:
+		return nil
+	}
+
+	r.offset = 0
+	r.committed = 0
+	r.partial = make(map[string]*partialLine)
+	return r.readToEnd(emit, maxLogSize)
+}
+
+func (r *containerReader) readToEnd(emit func(stream, log string, ts time.Time), maxLogSize int) error {
+	if _, err := r.file.Seek(r.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(r.file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// an incomplete line is read again once it is complete
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		r.offset += int64(len(line))
+
+		var parsed logLine
+		if err := json.Unmarshal(line, &parsed); err != nil {
+			continue
+		}
+		r.handleLine(parsed, emit, maxLogSize)
+	}
+}
+
+// handleLine emits a complete line, joining it with any preceding parts.
+// A line which does not end with a newline is the part of a longer line.
+func (r *containerReader) handleLine(line logLine, emit func(stream, log string, ts time.Time), maxLogSize int) {
+	complete := strings.HasSuffix(line.Log, "\n")
+	text := strings.TrimSuffix(line.Log, "\n")
+
+	partial, ok := r.partial[line.Stream]
+	if !ok {
+		if complete {
+			emit(line.Stream, text, line.Time)
+			r.commit()
+			return
+		}
+		partial = &partialLine{time: line.Time}
+		r.partial[line.Stream] = partial
+	}
+
+	partial.builder.WriteString(text)
+	if complete || partial.builder.Len() >= maxLogSize {
+		delete(r.partial, line.Stream)
+		emit(line.Stream, partial.builder.String(), partial.time)
+		r.commit()
+	}
+}
+
+// commit records the offset as safe to resume from, if no lines are partially read
+func (r *containerReader) commit() {
+	if len(r.partial) == 0 {
+		r.committed = r.offset
+	}
+}
+
+func (r *containerReader) Close() error {
+	return r.file.Close()
+}
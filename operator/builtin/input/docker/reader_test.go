@@ -0,0 +1,192 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type emitted struct {
+	stream string
+	log    string
+	ts     time.Time
+}
+
+func newTestReader(t *testing.T) (*containerReader, string) {
+	logPath := filepath.Join(t.TempDir(), "container-json.log")
+	require.NoError(t, ioutil.WriteFile(logPath, nil, 0600))
+
+	details := &container{ID: "abc", Name: "web", LogPath: logPath}
+	details.Config.Image = "nginx:latest"
+	details.Config.Labels = map[string]string{"app": "web"}
+
+	reader, err := newContainerReader(details, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { reader.Close() })
+	return reader, logPath
+}
+
+func appendLog(t *testing.T, logPath string, lines ...logLine) {
+	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	defer file.Close()
+
+	for _, line := range lines {
+		data, err := json.Marshal(line)
+		require.NoError(t, err)
+		_, err = file.Write(append(data, '\n'))
+		require.NoError(t, err)
+	}
+}
+
+func readAll(t *testing.T, reader *containerReader, maxLogSize int) []emitted {
+	var lines []emitted
+	err := reader.readLines(func(stream, log string, ts time.Time) {
+		lines = append(lines, emitted{stream, log, ts})
+	}, maxLogSize)
+	require.NoError(t, err)
+	return lines
+}
+
+func TestContainerReaderAttributes(t *testing.T) {
+	reader, _ := newTestReader(t)
+	require.Equal(t, map[string]string{
+		"container.id":         "abc",
+		"container.name":       "web",
+		"container.image.name": "nginx:latest",
+		"container.label.app":  "web",
+	}, reader.attributes)
+}
+
+func TestContainerReaderLines(t *testing.T) {
+	reader, logPath := newTestReader(t)
+	ts := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	appendLog(t, logPath,
+		logLine{Log: "first\n", Stream: "stdout", Time: ts},
+		logLine{Log: "second\n", Stream: "stderr", Time: ts},
+	)
+	require.Equal(t, []emitted{
+		{"stdout", "first", ts},
+		{"stderr", "second", ts},
+	}, readAll(t, reader, defaultMaxLogSize))
+	require.Equal(t, reader.offset, reader.committed)
+
+	require.Empty(t, readAll(t, reader, defaultMaxLogSize))
+}
+
+func TestContainerReaderIncompleteLine(t *testing.T) {
+	reader, logPath := newTestReader(t)
+
+	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	defer file.Close()
+	_, err = file.WriteString(`{"log":"first\n","stream":"stdout",`)
+	require.NoError(t, err)
+
+	require.Empty(t, readAll(t, reader, defaultMaxLogSize))
+	require.Equal(t, int64(0), reader.offset)
+
+	_, err = file.WriteString(`"time":"2021-06-01T12:00:00Z"}` + "\n")
+	require.NoError(t, err)
+
+	lines := readAll(t, reader, defaultMaxLogSize)
+	require.Len(t, lines, 1)
+	require.Equal(t, "first", lines[0].log)
+}
+
+func TestContainerReaderPartialLines(t *testing.T) {
+	reader, logPath := newTestReader(t)
+	first := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(time.Millisecond)
+
+	appendLog(t, logPath,
+		logLine{Log: "long ", Stream: "stdout", Time: first},
+		logLine{Log: "error\n", Stream: "stderr", Time: first},
+		logLine{Log: "line", Stream: "stdout", Time: second},
+	)
+	require.Equal(t, []emitted{
+		{"stderr", "error", first},
+	}, readAll(t, reader, defaultMaxLogSize))
+
+	// the offset is not committed while a line is partially read
+	require.Equal(t, int64(0), reader.committed)
+
+	appendLog(t, logPath, logLine{Log: "\n", Stream: "stdout", Time: second})
+	require.Equal(t, []emitted{
+		{"stdout", "long line", first},
+	}, readAll(t, reader, defaultMaxLogSize))
+	require.Equal(t, reader.offset, reader.committed)
+}
+
+func TestContainerReaderMaxLogSize(t *testing.T) {
+	reader, logPath := newTestReader(t)
+	ts := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	appendLog(t, logPath,
+		logLine{Log: "aaaa", Stream: "stdout", Time: ts},
+		logLine{Log: "bbbb", Stream: "stdout", Time: ts},
+		logLine{Log: "cc\n", Stream: "stdout", Time: ts},
+	)
+	require.Equal(t, []emitted{
+		{"stdout", "aaaabbbb", ts},
+		{"stdout", "cc", ts},
+	}, readAll(t, reader, 8))
+}
+
+func TestContainerReaderRotation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows does not allow renaming open files")
+	}
+	reader, logPath := newTestReader(t)
+	ts := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	appendLog(t, logPath, logLine{Log: "first\n", Stream: "stdout", Time: ts})
+	require.Len(t, readAll(t, reader, defaultMaxLogSize), 1)
+
+	// lines written before the rotation are read from the old log
+	appendLog(t, logPath, logLine{Log: "second\n", Stream: "stdout", Time: ts})
+	require.NoError(t, os.Rename(logPath, logPath+".1"))
+	require.NoError(t, ioutil.WriteFile(logPath, nil, 0600))
+	appendLog(t, logPath, logLine{Log: "third\n", Stream: "stdout", Time: ts})
+
+	require.Equal(t, []emitted{
+		{"stdout", "second", ts},
+		{"stdout", "third", ts},
+	}, readAll(t, reader, defaultMaxLogSize))
+}
+
+func TestContainerReaderTruncation(t *testing.T) {
+	reader, logPath := newTestReader(t)
+	ts := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	appendLog(t, logPath, logLine{Log: "first line\n", Stream: "stdout", Time: ts})
+	require.Len(t, readAll(t, reader, defaultMaxLogSize), 1)
+
+	require.NoError(t, os.Truncate(logPath, 0))
+	appendLog(t, logPath, logLine{Log: "x\n", Stream: "stdout", Time: ts})
+
+	require.Equal(t, []emitted{
+		{"stdout", "x", ts},
+	}, readAll(t, reader, defaultMaxLogSize))
+}
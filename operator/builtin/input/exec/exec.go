@@ -0,0 +1,378 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	osexec "os/exec"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	modeOnce     = "once"
+	modeInterval = "interval"
+	modeStream   = "stream"
+
+	restartNever     = "never"
+	restartOnFailure = "on_failure"
+	restartAlways    = "always"
+
+	streamAttribute = "log.iostream"
+
+	defaultMaxLogSize = 1024 * 1024
+)
+
+func init() {
+	operator.Register("exec_input", func() operator.Builder { return NewExecInputConfig("") })
+}
+
+// NewExecInputConfig creates a new exec input config with default values
+func NewExecInputConfig(operatorID string) *ExecInputConfig {
+	return &ExecInputConfig{
+		InputConfig:   helper.NewInputConfig(operatorID, "exec_input"),
+		Mode:          modeStream,
+		Interval:      helper.NewDuration(time.Minute),
+		InheritEnv:    true,
+		RestartPolicy: restartAlways,
+		RestartDelay:  helper.NewDuration(time.Second),
+		MaxLogSize:    defaultMaxLogSize,
+		Multiline:     helper.NewMultilineConfig(),
+		Encoding:      helper.NewEncodingConfig(),
+	}
+}
+
+// ExecInputConfig is the configuration of an exec input operator
+type ExecInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	Command       string                 `mapstructure:"command,omitempty"        json:"command,omitempty"        yaml:"command,omitempty"`
+	Args          []string               `mapstructure:"args,omitempty"           json:"args,omitempty"           yaml:"args,omitempty"`
+	Mode          string                 `mapstructure:"mode,omitempty"           json:"mode,omitempty"           yaml:"mode,omitempty"`
+	Interval      helper.Duration        `mapstructure:"interval,omitempty"       json:"interval,omitempty"       yaml:"interval,omitempty"`
+	Timeout       helper.Duration        `mapstructure:"timeout,omitempty"        json:"timeout,omitempty"        yaml:"timeout,omitempty"`
+	Env           map[string]string      `mapstructure:"env,omitempty"            json:"env,omitempty"            yaml:"env,omitempty"`
+	InheritEnv    bool                   `mapstructure:"inherit_env"              json:"inherit_env"              yaml:"inherit_env"`
+	WorkingDir    string                 `mapstructure:"working_dir,omitempty"    json:"working_dir,omitempty"    yaml:"working_dir,omitempty"`
+	RestartPolicy string                 `mapstructure:"restart_policy,omitempty" json:"restart_policy,omitempty" yaml:"restart_policy,omitempty"`
+	RestartDelay  helper.Duration        `mapstructure:"restart_delay,omitempty"  json:"restart_delay,omitempty"  yaml:"restart_delay,omitempty"`
+	CaptureStderr bool                   `mapstructure:"capture_stderr,omitempty" json:"capture_stderr,omitempty" yaml:"capture_stderr,omitempty"`
+	MaxLogSize    helper.ByteSize        `mapstructure:"max_log_size,omitempty"   json:"max_log_size,omitempty"   yaml:"max_log_size,omitempty"`
+	Multiline     helper.MultilineConfig `mapstructure:"multiline,omitempty"      json:"multiline,omitempty"      yaml:"multiline,omitempty"`
+	Encoding      helper.EncodingConfig  `mapstructure:",squash,omitempty"        json:",inline,omitempty"        yaml:",inline,omitempty"`
+}
+
+// Build will build an exec input operator
+func (c ExecInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Command == "" {
+		return nil, fmt.Errorf("missing required parameter 'command'")
+	}
+
+	switch c.Mode {
+	case modeOnce, modeStream:
+	case modeInterval:
+		if c.Interval.Raw() <= 0 {
+			return nil, fmt.Errorf("`interval` must be positive")
+		}
+	default:
+		return nil, fmt.Errorf("invalid mode '%s'", c.Mode)
+	}
+
+	if c.Timeout.Raw() < 0 {
+		return nil, fmt.Errorf("`timeout` must not be negative")
+	}
+
+	switch c.RestartPolicy {
+	case restartNever, restartOnFailure, restartAlways:
+	default:
+		return nil, fmt.Errorf("invalid restart_policy '%s'", c.RestartPolicy)
+	}
+
+	if c.RestartDelay.Raw() < 0 {
+		return nil, fmt.Errorf("`restart_delay` must not be negative")
+	}
+
+	if c.MaxLogSize <= 0 {
+		return nil, fmt.Errorf("`max_log_size` must be positive")
+	}
+
+	encoding, err := c.Encoding.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the multiline config, since a split func
+	// is built for each output stream of each run
+	if _, err := c.Multiline.Build(context, encoding.Encoding, true); err != nil {
+		return nil, err
+	}
+
+	var env []string
+	if c.InheritEnv {
+		env = os.Environ()
+	}
+	for key, value := range c.Env {
+		env = append(env, key+"="+value)
+	}
+
+	execInput := &ExecInput{
+		InputOperator: inputOperator,
+		command:       c.Command,
+		args:          c.Args,
+		mode:          c.Mode,
+		interval:      c.Interval.Raw(),
+		timeout:       c.Timeout.Raw(),
+		env:           env,
+		workingDir:    c.WorkingDir,
+		restartPolicy: c.RestartPolicy,
+		restartDelay:  c.RestartDelay.Raw(),
+		captureStderr: c.CaptureStderr,
+		maxLogSize:    int(c.MaxLogSize),
+		encoding:      encoding,
+		newSplitFunc: func() (bufio.SplitFunc, error) {
+			return c.Multiline.Build(context, encoding.Encoding, true)
+		},
+	}
+	return []operator.Operator{execInput}, nil
+}
+
+// ExecInput is an operator that runs a command and reads its output as log entries
+type ExecInput struct {
+	helper.InputOperator
+	command       string
+	args          []string
+	mode          string
+	interval      time.Duration
+	timeout       time.Duration
+	env           []string
+	workingDir    string
+	restartPolicy string
+	restartDelay  time.Duration
+	captureStderr bool
+	maxLogSize    int
+	encoding      helper.Encoding
+	newSplitFunc  func() (bufio.SplitFunc, error)
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Start will start running the command
+func (e *ExecInput) Start(_ operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	switch e.mode {
+	case modeOnce:
+		e.goRunOnce(ctx)
+	case modeInterval:
+		e.goRunInterval(ctx)
+	case modeStream:
+		e.goRunStream(ctx)
+	}
+	return nil
+}
+
+// Stop will stop running the command, killing it if it is running
+func (e *ExecInput) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.wg.Wait()
+	return nil
+}
+
+// goRunOnce will run the command a single time
+func (e *ExecInput) goRunOnce(ctx context.Context) {
+	e.wg.Add(1)
+
+	go func() {
+		defer e.wg.Done()
+		e.runAndLog(ctx)
+	}()
+}
+
+// goRunInterval will run the command immediately, and then on each interval.
+// A run which has not finished by the next interval delays the next run.
+func (e *ExecInput) goRunInterval(ctx context.Context) {
+	e.wg.Add(1)
+
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			e.runAndLog(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// goRunStream will run the command, and restart it when it exits according to the restart policy
+func (e *ExecInput) goRunStream(ctx context.Context) {
+	e.wg.Add(1)
+
+	go func() {
+		defer e.wg.Done()
+
+		for {
+			err := e.runAndLog(ctx)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case e.restartPolicy == restartNever:
+				return
+			case e.restartPolicy == restartOnFailure && err == nil:
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(e.restartDelay):
+			}
+			e.Debugw("Restarting command", zap.String("command", e.command))
+		}
+	}()
+}
+
+// runAndLog runs the command, and logs an error if it fails
+func (e *ExecInput) runAndLog(ctx context.Context) error {
+	err := e.run(ctx)
+	if err != nil && ctx.Err() == nil {
+		e.Errorw("Command failed", zap.String("command", e.command), zap.Error(err))
+	}
+	return err
+}
+
+// run runs the command, and creates entries from its output until it exits
+func (e *ExecInput) run(ctx context.Context) error {
+	cmdCtx := ctx
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		cmdCtx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	cmd := osexec.CommandContext(cmdCtx, e.command, e.args...)
+	cmd.Env = e.env
+	cmd.Dir = e.workingDir
+
+	outputs := map[string]io.ReadCloser{}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	outputs["stdout"] = stdout
+	if e.captureStderr {
+		stderr, err := cmd.StderrPipe()
+		if err != nil {
+			return err
+		}
+		outputs["stderr"] = stderr
+	}
+
+	splitFuncs := make(map[string]bufio.SplitFunc, len(outputs))
+	for stream := range outputs {
+		if splitFuncs[stream], err = e.newSplitFunc(); err != nil {
+			return err
+		}
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for stream, output := range outputs {
+		wg.Add(1)
+		go func(stream string, output io.Reader) {
+			defer wg.Done()
+			e.readOutput(ctx, stream, output, splitFuncs[stream])
+		}(stream, output)
+	}
+
+	// Processes started by the command may hold its output open after
+	// it is killed, so the output is closed to stop reading from it
+	readDone := make(chan struct{})
+	go func() {
+		select {
+		case <-cmdCtx.Done():
+			for _, output := range outputs {
+				output.Close()
+			}
+		case <-readDone:
+		}
+	}()
+
+	// The output must be read before waiting for the command
+	wg.Wait()
+	close(readDone)
+	err = cmd.Wait()
+	if cmdCtx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", e.timeout)
+	}
+	return err
+}
+
+// readOutput creates an entry from each message of an output stream of the command
+func (e *ExecInput) readOutput(ctx context.Context, stream string, output io.Reader, splitFunc bufio.SplitFunc) {
+	scanner := bufio.NewScanner(output)
+	scanner.Buffer(make([]byte, 0, 4096), e.maxLogSize)
+	scanner.Split(splitFunc)
+
+	for scanner.Scan() {
+		decoded, err := e.encoding.Decode(scanner.Bytes())
+		if err != nil {
+			e.Errorw("Failed to decode data", zap.Error(err))
+			continue
+		}
+
+		entry, err := e.NewEntry(decoded)
+		if err != nil {
+			e.Errorw("Failed to create entry", zap.Error(err))
+			continue
+		}
+		entry.AddAttribute(streamAttribute, stream)
+		e.Write(ctx, entry)
+	}
+
+	// The output is closed if the command is killed
+	if err := scanner.Err(); err != nil && !errors.Is(err, os.ErrClosed) {
+		e.Errorw("Failed to read command output", zap.String("stream", stream), zap.Error(err))
+	}
+}
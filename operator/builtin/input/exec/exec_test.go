@@ -0,0 +1,310 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestExecInput(t *testing.T, script string, cfgMod func(*ExecInputConfig)) (*ExecInput, *testutil.FakeOutput) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Test scripts require a unix shell")
+	}
+
+	cfg := NewExecInputConfig("test_id")
+	cfg.Command = "sh"
+	cfg.Args = []string{"-c", script}
+	cfg.OutputIDs = []string{"fake"}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	execInput := ops[0].(*ExecInput)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, execInput.SetOutputs([]operator.Operator{fakeOutput}))
+	require.NoError(t, execInput.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() { require.NoError(t, execInput.Stop()) })
+	return execInput, fakeOutput
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*ExecInputConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *ExecInputConfig) {},
+			false,
+		},
+		{
+			"MissingCommand",
+			func(cfg *ExecInputConfig) {
+				cfg.Command = ""
+			},
+			true,
+		},
+		{
+			"Once",
+			func(cfg *ExecInputConfig) {
+				cfg.Mode = modeOnce
+			},
+			false,
+		},
+		{
+			"Interval",
+			func(cfg *ExecInputConfig) {
+				cfg.Mode = modeInterval
+				cfg.Interval = helper.NewDuration(10 * time.Second)
+			},
+			false,
+		},
+		{
+			"ZeroInterval",
+			func(cfg *ExecInputConfig) {
+				cfg.Mode = modeInterval
+				cfg.Interval = helper.NewDuration(0)
+			},
+			true,
+		},
+		{
+			"InvalidMode",
+			func(cfg *ExecInputConfig) {
+				cfg.Mode = "daemon"
+			},
+			true,
+		},
+		{
+			"NegativeTimeout",
+			func(cfg *ExecInputConfig) {
+				cfg.Timeout = helper.NewDuration(-time.Second)
+			},
+			true,
+		},
+		{
+			"InvalidRestartPolicy",
+			func(cfg *ExecInputConfig) {
+				cfg.RestartPolicy = "sometimes"
+			},
+			true,
+		},
+		{
+			"NegativeRestartDelay",
+			func(cfg *ExecInputConfig) {
+				cfg.RestartDelay = helper.NewDuration(-time.Second)
+			},
+			true,
+		},
+		{
+			"ZeroMaxLogSize",
+			func(cfg *ExecInputConfig) {
+				cfg.MaxLogSize = 0
+			},
+			true,
+		},
+		{
+			"InvalidMultiline",
+			func(cfg *ExecInputConfig) {
+				cfg.Multiline.LineStartPattern = "("
+			},
+			true,
+		},
+		{
+			"InvalidEncoding",
+			func(cfg *ExecInputConfig) {
+				cfg.Encoding.Encoding = "no-such-encoding"
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewExecInputConfig("test_id")
+			cfg.Command = "dmesg"
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestExecInputOnce(t *testing.T) {
+	_, fakeOutput := newTestExecInput(t, "echo first; echo second >&2; echo third", func(cfg *ExecInputConfig) {
+		cfg.Mode = modeOnce
+	})
+
+	for _, expected := range []string{"first", "third"} {
+		e := expectEntry(t, fakeOutput)
+		require.Equal(t, expected, e.Body)
+		require.Equal(t, map[string]string{streamAttribute: "stdout"}, e.Attributes)
+	}
+	expectNoEntry(t, fakeOutput)
+}
+
+func TestExecInputCaptureStderr(t *testing.T) {
+	_, fakeOutput := newTestExecInput(t, "echo first; echo second >&2", func(cfg *ExecInputConfig) {
+		cfg.Mode = modeOnce
+		cfg.CaptureStderr = true
+	})
+
+	received := map[string]interface{}{}
+	for i := 0; i < 2; i++ {
+		e := expectEntry(t, fakeOutput)
+		received[e.Attributes[streamAttribute]] = e.Body
+	}
+	require.Equal(t, map[string]interface{}{"stdout": "first", "stderr": "second"}, received)
+}
+
+func TestExecInputEnv(t *testing.T) {
+	require.NoError(t, os.Setenv("EXEC_INPUT_INHERITED", "inherited"))
+	defer os.Unsetenv("EXEC_INPUT_INHERITED")
+
+	t.Run("Inherited", func(t *testing.T) {
+		_, fakeOutput := newTestExecInput(t, `echo "$EXEC_INPUT_TEST" "${EXEC_INPUT_INHERITED:-none}"`, func(cfg *ExecInputConfig) {
+			cfg.Mode = modeOnce
+			cfg.Env = map[string]string{"EXEC_INPUT_TEST": "value"}
+		})
+		fakeOutput.ExpectBody(t, "value inherited")
+	})
+
+	t.Run("NotInherited", func(t *testing.T) {
+		_, fakeOutput := newTestExecInput(t, `echo "$EXEC_INPUT_TEST" "${EXEC_INPUT_INHERITED:-none}"`, func(cfg *ExecInputConfig) {
+			cfg.Mode = modeOnce
+			cfg.Env = map[string]string{"EXEC_INPUT_TEST": "value"}
+			cfg.InheritEnv = false
+		})
+		fakeOutput.ExpectBody(t, "value none")
+	})
+}
+
+func TestExecInputWorkingDir(t *testing.T) {
+	dir := t.TempDir()
+	_, fakeOutput := newTestExecInput(t, "pwd -P", func(cfg *ExecInputConfig) {
+		cfg.Mode = modeOnce
+		cfg.WorkingDir = dir
+	})
+
+	e := expectEntry(t, fakeOutput)
+	require.Contains(t, e.Body, "/")
+	require.Contains(t, dir, e.Body)
+}
+
+func TestExecInputInterval(t *testing.T) {
+	_, fakeOutput := newTestExecInput(t, "echo tick", func(cfg *ExecInputConfig) {
+		cfg.Mode = modeInterval
+		cfg.Interval = helper.NewDuration(50 * time.Millisecond)
+	})
+
+	for i := 0; i < 3; i++ {
+		fakeOutput.ExpectBody(t, "tick")
+	}
+}
+
+func TestExecInputRestartPolicy(t *testing.T) {
+	cases := []struct {
+		name          string
+		script        string
+		restartPolicy string
+		expectRestart bool
+	}{
+		{"AlwaysSuccess", "echo run", restartAlways, true},
+		{"AlwaysFailure", "echo run; exit 1", restartAlways, true},
+		{"OnFailureSuccess", "echo run", restartOnFailure, false},
+		{"OnFailureFailure", "echo run; exit 1", restartOnFailure, true},
+		{"NeverFailure", "echo run; exit 1", restartNever, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, fakeOutput := newTestExecInput(t, tc.script, func(cfg *ExecInputConfig) {
+				cfg.RestartPolicy = tc.restartPolicy
+				cfg.RestartDelay = helper.NewDuration(10 * time.Millisecond)
+			})
+
+			fakeOutput.ExpectBody(t, "run")
+			if tc.expectRestart {
+				fakeOutput.ExpectBody(t, "run")
+				return
+			}
+			expectNoEntry(t, fakeOutput)
+		})
+	}
+}
+
+func TestExecInputTimeout(t *testing.T) {
+	_, fakeOutput := newTestExecInput(t, "echo start; sleep 10; echo end", func(cfg *ExecInputConfig) {
+		cfg.Timeout = helper.NewDuration(100 * time.Millisecond)
+		cfg.RestartDelay = helper.NewDuration(10 * time.Millisecond)
+	})
+
+	// The command is killed at the timeout, and restarted
+	fakeOutput.ExpectBody(t, "start")
+	fakeOutput.ExpectBody(t, "start")
+}
+
+func TestExecInputStop(t *testing.T) {
+	execInput, fakeOutput := newTestExecInput(t, "echo start; sleep 10", nil)
+	fakeOutput.ExpectBody(t, "start")
+
+	done := make(chan struct{})
+	go func() {
+		require.NoError(t, execInput.Stop())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "Timed out waiting for the command to be stopped")
+	}
+}
+
+func expectEntry(t *testing.T, fakeOutput *testutil.FakeOutput) *entry.Entry {
+	select {
+	case e := <-fakeOutput.Received:
+		return e
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+	return nil
+}
+
+func expectNoEntry(t *testing.T, fakeOutput *testutil.FakeOutput) {
+	select {
+	case e := <-fakeOutput.Received:
+		require.FailNow(t, "Unexpected entry", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
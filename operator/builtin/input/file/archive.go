@@ -0,0 +1,147 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"go.uber.org/zap"
+)
+
+const (
+	archiveNameAttribute   = "archive.name"
+	archiveMemberAttribute = "archive.member"
+)
+
+// readArchive reads the members of a zip archive in turn, each from its own offset
+func (f *Reader) readArchive(ctx context.Context) {
+	members, err := zipMembers(f.file)
+	if err != nil {
+		// The archive may still be being written
+		f.Debugw("Failed to read zip archive. Will retry during the next poll", zap.Error(err))
+		return
+	}
+
+	if f.MemberOffsets == nil {
+		f.MemberOffsets = make(map[string]int64, len(members))
+	}
+
+	complete := true
+	for _, member := range members {
+		if !f.readMember(ctx, member) {
+			complete = false
+			break
+		}
+	}
+	f.Offset = f.archiveOffset()
+	if complete {
+		f.recordEnd()
+	}
+
+	if complete && (f.fileInput.deleteAfterRead || f.fileInput.onComplete != nil) {
+		f.finished = f.isFinished()
+	}
+}
+
+// readMember reads a member of a zip archive from its offset. It returns false
+// if reading stopped before the end of the member.
+func (f *Reader) readMember(ctx context.Context, member *zip.File) bool {
+	offset := f.MemberOffsets[member.Name]
+	if uint64(offset) >= member.UncompressedSize64 {
+		return true
+	}
+
+	rc, err := member.Open()
+	if err != nil {
+		f.Errorw("Failed to open archive member", "member", member.Name, zap.Error(err))
+		return true
+	}
+	defer rc.Close()
+
+	if _, err := io.CopyN(ioutil.Discard, rc, offset); err != nil {
+		f.Errorw("Failed to seek archive member", "member", member.Name, zap.Error(err))
+		return true
+	}
+
+	attributes := map[string]string{
+		archiveNameAttribute:   filepath.Base(f.Path),
+		archiveMemberAttribute: member.Name,
+	}
+
+	// Members are complete, so the last entry is flushed even if it is not terminated
+	scanner := NewPositionalScanner(rc, f.fileInput.MaxLogSize, offset, flushAtEOFSplitFunc(f.splitFunc))
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return false
+		default:
+		}
+
+		if !f.limiter.allow(len(scanner.Bytes())) {
+			return false
+		}
+
+		if err := f.emit(ctx, scanner.Bytes(), attributes); err != nil {
+			f.Error("Failed to emit entry", zap.Error(err))
+		}
+		f.MemberOffsets[member.Name] = scanner.Pos()
+	}
+
+	if err := getScannerError(scanner); err != nil {
+		f.Errorw("Failed during scan", "member", member.Name, zap.Error(err))
+	}
+	return true
+}
+
+// skipArchive sets the offset of each member of a zip archive to its end
+func (f *Reader) skipArchive() error {
+	members, err := zipMembers(f.file)
+	if err != nil {
+		return err
+	}
+
+	f.MemberOffsets = make(map[string]int64, len(members))
+	for _, member := range members {
+		f.MemberOffsets[member.Name] = int64(member.UncompressedSize64)
+	}
+	f.Offset = f.archiveOffset()
+	f.recordEnd()
+	return nil
+}
+
+// archiveOffset returns the total number of bytes read from the members of a zip archive
+func (f *Reader) archiveOffset() int64 {
+	var offset int64
+	for _, memberOffset := range f.MemberOffsets {
+		offset += memberOffset
+	}
+	return offset
+}
+
+// flushAtEOFSplitFunc wraps a split func, returning any remaining data as a token at EOF
+func flushAtEOFSplitFunc(splitFunc bufio.SplitFunc) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		advance, token, err = splitFunc(data, atEOF)
+		if err == nil && token == nil && atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return advance, token, err
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+	"time"
+)
+
+// updateBackoff doubles the interval at which an idle file is read, up to the
+// maximum poll interval, and resets it to the poll interval when the file has
+// new content. It records the size and modification time of the file, so that
+// changes are noticed without opening the file.
+func (f *Reader) updateBackoff(startOffset int64) {
+	if f.fileInput.maxPollInterval == 0 {
+		return
+	}
+
+	if f.Offset != startOffset {
+		f.idleInterval = 0
+	} else if f.idleInterval == 0 {
+		f.idleInterval = 2 * f.fileInput.PollInterval
+	} else {
+		f.idleInterval *= 2
+	}
+	if f.idleInterval > f.fileInput.maxPollInterval {
+		f.idleInterval = f.fileInput.maxPollInterval
+	}
+	f.nextRead = time.Now().Add(f.idleInterval)
+
+	if info, err := f.file.Stat(); err == nil {
+		f.lastSize = info.Size()
+		f.lastModTime = info.ModTime()
+	}
+}
+
+// isIdle returns true if the file at the path should not be read during this
+// poll, because it has not been modified since it was last read and its
+// backoff interval has not elapsed
+func (f *InputOperator) isIdle(path string) bool {
+	if f.maxPollInterval == 0 {
+		return false
+	}
+
+	var reader *Reader
+	for i := len(f.knownFiles) - 1; i >= 0; i-- {
+		if f.knownFiles[i].Path == path {
+			reader = f.knownFiles[i]
+			break
+		}
+	}
+	if reader == nil || !time.Now().Before(reader.nextRead) {
+		return false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return info.Size() == reader.lastSize && info.ModTime().Equal(reader.lastModTime)
+}
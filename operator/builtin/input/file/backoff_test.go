@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestBackoffIdleFile(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.PollInterval = helper.NewDuration(time.Second)
+		cfg.MaxPollInterval = helper.NewDuration(3 * time.Second)
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+	require.Equal(t, time.Duration(0), operator.knownFiles[len(operator.knownFiles)-1].idleInterval)
+
+	require.False(t, operator.isIdle(temp.Name()))
+
+	// Each time the file is read without new content,
+	// the interval is doubled up to the maximum
+	expected := []time.Duration{2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for _, interval := range expected {
+		operator.knownFiles[len(operator.knownFiles)-1].nextRead = time.Time{}
+		operator.poll(context.Background())
+		require.Equal(t, interval, operator.knownFiles[len(operator.knownFiles)-1].idleInterval)
+	}
+	expectNoMessages(t, logReceived)
+
+	// The file has not changed since it was read, and was read
+	// recently, so it is not opened during the next poll
+	require.True(t, operator.isIdle(temp.Name()))
+}
+
+// BackoffResumesOnWrite tests that an idle file is read as soon as
+// it is written to, and that its backoff is reset
+func TestBackoffResumesOnWrite(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.MaxPollInterval = helper.NewDuration(time.Hour)
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+	operator.poll(context.Background())
+	require.True(t, operator.isIdle(temp.Name()))
+
+	writeString(t, temp, "testlog2\n")
+	require.False(t, operator.isIdle(temp.Name()))
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog2")
+	require.Equal(t, time.Duration(0), operator.knownFiles[len(operator.knownFiles)-1].idleInterval)
+}
+
+// BackoffIdleFileRetained tests that the offset of a file which
+// is skipped for many polls is not forgotten
+func TestBackoffIdleFileRetained(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.MaxPollInterval = helper.NewDuration(time.Hour)
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+	for i := 0; i < 10; i++ {
+		operator.poll(context.Background())
+	}
+
+	// Modify the file without changing its size
+	now := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(temp.Name(), now, now))
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+}
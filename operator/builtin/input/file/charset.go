@@ -0,0 +1,133 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// encodingAuto is the encoding which is detected separately for each file
+const encodingAuto = "auto"
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// detectEncoding returns the name of the encoding of the content. A byte order mark
+// is used if present. Otherwise, content in which every other byte is zero is assumed
+// to be UTF-16, and content which is not valid UTF-8 is assumed to be Windows-1252,
+// a superset of Latin-1.
+func detectEncoding(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, bomUTF8):
+		return "utf-8"
+	case bytes.HasPrefix(content, bomUTF16LE):
+		return "utf-16le"
+	case bytes.HasPrefix(content, bomUTF16BE):
+		return "utf-16be"
+	}
+
+	// Count the zero bytes at even and odd positions. ASCII characters
+	// encoded as UTF-16 have a zero high byte.
+	var evenZeros, oddZeros int
+	for i, b := range content {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	pairs := len(content) / 2
+	switch {
+	case pairs > 0 && oddZeros > pairs/2 && evenZeros <= pairs/10:
+		return "utf-16le"
+	case pairs > 0 && evenZeros > pairs/2 && oddZeros <= pairs/10:
+		return "utf-16be"
+	}
+
+	// A multi-byte character may have been cut off at the end of the content
+	valid := content
+	for i := len(content) - 1; i >= 0 && i >= len(content)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(content[i]) {
+			if !utf8.FullRune(content[i:]) {
+				valid = content[:i]
+			}
+			break
+		}
+	}
+	if utf8.Valid(valid) {
+		return "utf-8"
+	}
+	return "windows-1252"
+}
+
+// detectEncoding detects the encoding of the file from the bytes used for its
+// fingerprint, and configures the reader to use it. The encoding is not detected until the file
+// has content.
+func (f *Reader) detectEncoding() error {
+	if !f.fileInput.autoEncoding || f.Encoding != "" {
+		return nil
+	}
+
+	src, err := f.openAt(0)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, f.fileInput.fingerprintSize)
+	n, err := io.ReadFull(src, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+
+	name := detectEncoding(buf[:n])
+	if err := f.setEncoding(name); err != nil {
+		return err
+	}
+	f.Debugw("Detected file encoding", "encoding", name)
+	return nil
+}
+
+// setEncoding configures the reader to decode and split the file with the named encoding
+func (f *Reader) setEncoding(name string) error {
+	enc, err := helper.EncodingConfig{Encoding: name}.Build(operator.BuildContext{})
+	if err != nil {
+		return err
+	}
+
+	splitFunc, err := f.fileInput.splitFuncFor(enc.Encoding)
+	if err != nil {
+		return fmt.Errorf("build split func for %s: %s", name, err)
+	}
+
+	f.Encoding = name
+	f.decoder = enc.Encoding.NewDecoder()
+	f.splitFunc = splitFunc
+	return nil
+}
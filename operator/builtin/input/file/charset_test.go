@@ -0,0 +1,118 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  []byte
+		expected string
+	}{
+		{"ASCII", []byte("foo\nbar\n"), "utf-8"},
+		{"UTF8", []byte("折\n"), "utf-8"},
+		{"UTF8Truncated", []byte{'a', 230, 138}, "utf-8"},
+		{"UTF8BOM", []byte{0xEF, 0xBB, 0xBF, 'f', 'o', 'o'}, "utf-8"},
+		{"UTF16LEBOM", []byte{0xFF, 0xFE, 'f', 0, 'o', 0}, "utf-16le"},
+		{"UTF16BEBOM", []byte{0xFE, 0xFF, 0, 'f', 0, 'o'}, "utf-16be"},
+		{"UTF16LE", []byte{'f', 0, 'o', 0, 'o', 0, '\n', 0}, "utf-16le"},
+		{"UTF16BE", []byte{0, 'f', 0, 'o', 0, 'o', 0, '\n'}, "utf-16be"},
+		{"Latin1", []byte{'c', 'a', 'f', 0xE9, '\n'}, "windows-1252"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, detectEncoding(tc.content))
+		})
+	}
+}
+
+func TestAutoEncoding(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  []byte
+		encoding string
+		expected []string
+	}{
+		{
+			"UTF16LEWithBOM",
+			[]byte{0xFF, 0xFE, 'f', 0, 'o', 0, 'o', 0, '\n', 0, 'b', 0, 'a', 0, 'r', 0, '\n', 0},
+			"utf-16le",
+			[]string{"foo", "bar"},
+		},
+		{
+			"UTF8WithBOM",
+			[]byte{0xEF, 0xBB, 0xBF, 'f', 'o', 'o', '\n'},
+			"utf-8",
+			[]string{"foo"},
+		},
+		{
+			"Latin1",
+			[]byte{'c', 'a', 'f', 0xE9, '\n'},
+			"windows-1252",
+			[]string{"café"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+				cfg.Encoding.Encoding = "auto"
+			}, nil)
+
+			temp := openTemp(t, tempDir)
+			_, err := temp.Write(tc.content)
+			require.NoError(t, err)
+
+			require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+			defer operator.Stop()
+
+			for _, expected := range tc.expected {
+				e := waitForOne(t, logReceived)
+				require.Equal(t, expected, e.Body)
+				require.Equal(t, tc.encoding, e.Attributes["file_encoding"])
+			}
+		})
+	}
+}
+
+func TestAutoEncodingEmptyFile(t *testing.T) {
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Encoding.Encoding = "auto"
+	}, nil)
+
+	temp := openTemp(t, tempDir)
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	// The encoding is detected once the file has content
+	expectNoMessages(t, logReceived)
+	_, err := temp.Write([]byte{0xFF, 0xFE, 'f', 0, 'o', 0, 'o', 0, '\n', 0})
+	require.NoError(t, err)
+
+	e := waitForOne(t, logReceived)
+	require.Equal(t, "foo", e.Body)
+	require.Equal(t, "utf-16le", e.Attributes["file_encoding"])
+}
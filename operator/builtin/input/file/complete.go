@@ -0,0 +1,205 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	onCompleteMove    = "move"
+	onCompleteArchive = "archive"
+)
+
+// OnCompleteConfig is the configuration of the action taken on files which have been read to completion
+type OnCompleteConfig struct {
+	Action      string `mapstructure:"action"      json:"action"      yaml:"action"`
+	Destination string `mapstructure:"destination" json:"destination" yaml:"destination"`
+}
+
+func (c OnCompleteConfig) validate() error {
+	switch c.Action {
+	case onCompleteMove, onCompleteArchive:
+	default:
+		return fmt.Errorf("invalid on_complete action '%s'", c.Action)
+	}
+
+	if c.Destination == "" {
+		return fmt.Errorf("missing required field `on_complete.destination`")
+	}
+	return nil
+}
+
+// completeFinishedFiles moves or archives the files of all readers that have been
+// read to completion. Files which are already in the destination are not moved again.
+func (f *InputOperator) completeFinishedFiles(readers []*Reader) {
+	for _, reader := range readers {
+		if !reader.finished || f.inDestination(reader.Path) {
+			continue
+		}
+
+		destination := f.completeDestination
+		if !filepath.IsAbs(destination) {
+			destination = filepath.Join(filepath.Dir(reader.Path), destination)
+		}
+
+		if err := os.MkdirAll(destination, 0750); err != nil {
+			reader.Errorw("Failed to create on_complete destination", zap.Error(err))
+			continue
+		}
+
+		var err error
+		var dst string
+		if f.onComplete.Action == onCompleteArchive {
+			dst, err = archiveFile(reader.Path, destination)
+		} else {
+			dst, err = moveFile(reader.Path, destination)
+		}
+		if err != nil {
+			reader.Errorw("Failed to complete file", "action", f.onComplete.Action, zap.Error(err))
+			continue
+		}
+
+		reader.Debugw("Completed file", "action", f.onComplete.Action, "destination", dst)
+		delete(f.SeenPaths, reader.Path)
+	}
+}
+
+// filterCompleted removes the paths of files which are in the on_complete destination,
+// so that files which have been moved are not read again by a recursive include
+func (f *InputOperator) filterCompleted(paths []string) []string {
+	filtered := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !f.inDestination(path) {
+			filtered = append(filtered, path)
+		}
+	}
+	return filtered
+}
+
+// inDestination returns true if the file is in the on_complete destination or any
+// directory below it. A relative destination is relative to the directory of each
+// file, so the file is in a destination if it is below that of any of its parents.
+func (f *InputOperator) inDestination(path string) bool {
+	if filepath.IsAbs(f.completeDestination) {
+		return isWithin(path, f.completeDestination)
+	}
+
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if isWithin(path, filepath.Join(dir, f.completeDestination)) {
+			return true
+		}
+		if dir == filepath.Dir(dir) {
+			return false
+		}
+	}
+}
+
+// isWithin returns true if the path is below the directory
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// moveFile moves the file into the directory, and returns its new path
+func moveFile(path, dir string) (string, error) {
+	dst := availablePath(filepath.Join(dir, filepath.Base(path)))
+	if err := os.Rename(path, dst); err == nil || renamed(path, dst) {
+		return dst, nil
+	}
+
+	// The destination may be on a different filesystem, in which case
+	// the file must be copied before the original is removed
+	if err := copyFile(path, dst, false); err != nil {
+		return "", err
+	}
+	return dst, os.Remove(path)
+}
+
+// renamed returns true if a rename which reported an error has nonetheless
+// taken place. A network filesystem client may retry a rename whose reply was
+// lost, and receive an error because the source has already been moved.
+func renamed(src, dst string) bool {
+	if _, err := os.Lstat(src); !os.IsNotExist(err) {
+		return false
+	}
+	_, err := os.Lstat(dst)
+	return err == nil
+}
+
+// archiveFile compresses the file into the directory with gzip,
+// removes the original, and returns the path of the archive
+func archiveFile(path, dir string) (string, error) {
+	dst := availablePath(filepath.Join(dir, filepath.Base(path)+".gz"))
+	if err := copyFile(path, dst, true); err != nil {
+		return "", err
+	}
+	return dst, os.Remove(path)
+}
+
+func copyFile(src, dst string, compress bool) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+
+	var w io.Writer = out
+	if compress {
+		gz := gzip.NewWriter(out)
+		defer func() {
+			if closeErr := gz.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		w = gz
+	}
+
+	_, err = io.Copy(w, in)
+	return err
+}
+
+// availablePath returns the path, or the path with a numeric
+// suffix if a file already exists at the path
+func availablePath(path string) string {
+	dst := path
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(dst); os.IsNotExist(err) {
+			return dst
+		}
+		dst = path + "." + strconv.Itoa(i)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestOnCompleteMove(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.OnComplete = &OnCompleteConfig{Action: "move", Destination: "processed"}
+	}, nil)
+
+	path := filepath.Join(tempDir, "batch.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("testlog1\ntestlog2\n"), 0600))
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	waitForMessage(t, logReceived, "testlog1")
+	waitForMessage(t, logReceived, "testlog2")
+
+	moved := filepath.Join(tempDir, "processed", "batch.log")
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(moved)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	_, err := os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+
+	contents, err := ioutil.ReadFile(moved)
+	require.NoError(t, err)
+	require.Equal(t, "testlog1\ntestlog2\n", string(contents))
+}
+
+// OnCompleteMoveRecursive tests that files which have been moved into
+// a destination matched by a recursive include are not read or moved again
+func TestOnCompleteMoveRecursive(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.OnComplete = &OnCompleteConfig{Action: "move", Destination: "processed"}
+	}, nil)
+	operator.Include = []string{filepath.Join(tempDir, "**", "*.log")}
+
+	dir := filepath.Join(tempDir, "app")
+	require.NoError(t, os.MkdirAll(dir, 0750))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "batch.log"), []byte("testlog1\n"), 0600))
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	waitForMessage(t, logReceived, "testlog1")
+
+	moved := filepath.Join(dir, "processed", "batch.log")
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(moved)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	expectNoMessagesUntil(t, logReceived, 5*operator.PollInterval)
+	_, err := os.Stat(moved)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "processed", "processed"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestInDestination(t *testing.T) {
+	cases := []struct {
+		name        string
+		destination string
+		path        string
+		expected    bool
+	}{
+		{"RelativeSibling", "processed", "/data/app.log", false},
+		{"RelativeBelow", "processed", "/data/processed/app.log", true},
+		{"RelativeSubtree", "processed", "/data/processed/old/app.log", true},
+		{"RelativeNested", "done/processed", "/data/done/processed/app.log", true},
+		{"RelativeSimilarName", "processed", "/data/processed.old/app.log", false},
+		{"RelativeParent", "../processed", "/processed/app.log", true},
+		{"RelativePath", "processed", "data/processed/app.log", true},
+		{"AbsoluteOutside", "/archive", "/data/app.log", false},
+		{"AbsoluteBelow", "/archive", "/archive/app.log", true},
+		{"AbsoluteSubtree", "/archive", "/archive/data/app.log", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &InputOperator{completeDestination: filepath.FromSlash(tc.destination)}
+			require.Equal(t, tc.expected, f.inDestination(filepath.FromSlash(tc.path)))
+		})
+	}
+}
+
+func TestOnCompleteArchive(t *testing.T) {
+	t.Parallel()
+	archiveDir := testutil.NewTempDir(t)
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.OnComplete = &OnCompleteConfig{Action: "archive", Destination: archiveDir}
+	}, nil)
+
+	path := filepath.Join(tempDir, "batch.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("testlog1\n"), 0600))
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	waitForMessage(t, logReceived, "testlog1")
+
+	archive := filepath.Join(archiveDir, "batch.log.gz")
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return os.IsNotExist(err)
+	}, time.Second, 10*time.Millisecond)
+
+	file, err := os.Open(archive)
+	require.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	contents, err := ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	require.Equal(t, "testlog1\n", string(contents))
+}
+
+// OnCompleteMoveExistingDestination tests that a file is not
+// overwritten when a file with the same name was already moved
+func TestOnCompleteMoveExistingDestination(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	dir := filepath.Join(tempDir, "processed")
+	require.NoError(t, os.MkdirAll(dir, 0750))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "batch.log"), []byte("old"), 0600))
+
+	path := filepath.Join(tempDir, "batch.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("new"), 0600))
+
+	dst, err := moveFile(path, dir)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "batch.log.1"), dst)
+
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "batch.log"))
+	require.NoError(t, err)
+	require.Equal(t, "old", string(contents))
+}
+
+// Renamed tests that a rename which reported an error is detected
+// as having succeeded if the file is already at its destination
+func TestRenamed(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	src := filepath.Join(tempDir, "batch.log")
+	dst := filepath.Join(tempDir, "processed.log")
+	require.NoError(t, ioutil.WriteFile(src, []byte("log"), 0600))
+	require.False(t, renamed(src, dst))
+
+	require.NoError(t, os.Rename(src, dst))
+	require.True(t, renamed(src, dst))
+}
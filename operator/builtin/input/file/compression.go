@@ -0,0 +1,153 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	compressionNone  = ""
+	compressionGzip  = "gzip"
+	compressionBzip2 = "bzip2"
+	compressionZip   = "zip"
+	compressionAuto  = "auto"
+)
+
+const maxFileSize = 1<<63 - 1
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	zipMagic   = []byte("PK\x03\x04")
+)
+
+// compressionExtensions are the file extensions detected in auto mode
+var compressionExtensions = map[string]string{
+	".gz":  compressionGzip,
+	".bz2": compressionBzip2,
+	".zip": compressionZip,
+}
+
+// compressionOf returns the compression with which the file should be read.
+// In auto mode, files are detected by their extension or by their magic bytes.
+func (f *InputOperator) compressionOf(file *os.File) string {
+	if f.compression != compressionAuto {
+		return f.compression
+	}
+
+	if compression, ok := compressionExtensions[filepath.Ext(file.Name())]; ok {
+		return compression
+	}
+
+	buf := make([]byte, len(zipMagic))
+	n, _ := file.ReadAt(buf, 0)
+	switch {
+	case bytes.HasPrefix(buf[:n], gzipMagic):
+		return compressionGzip
+	case bytes.HasPrefix(buf[:n], bzip2Magic):
+		return compressionBzip2
+	case bytes.HasPrefix(buf[:n], zipMagic):
+		return compressionZip
+	default:
+		return compressionNone
+	}
+}
+
+// newDecompressor returns a reader over the decompressed content of the file,
+// starting from the beginning of the file regardless of its current position.
+// An empty file results in an empty reader. The content of a zip archive
+// is the content of each of its members in turn.
+func newDecompressor(file *os.File, compression string) (io.Reader, error) {
+	src := io.NewSectionReader(file, 0, maxFileSize)
+	switch compression {
+	case compressionGzip:
+		gz, err := gzip.NewReader(src)
+		if err == io.EOF {
+			return bytes.NewReader(nil), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return gz, nil
+	case compressionBzip2:
+		return bzip2.NewReader(src), nil
+	case compressionZip:
+		members, err := zipMembers(file)
+		if err != nil {
+			return nil, err
+		}
+		readers := make([]io.Reader, 0, len(members))
+		for _, member := range members {
+			readers = append(readers, &lazyMemberReader{member: member})
+		}
+		return io.MultiReader(readers...), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression '%s'", compression)
+	}
+}
+
+// zipMembers returns the regular files in the zip archive
+func zipMembers(file *os.File) ([]*zip.File, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat: %s", err)
+	}
+	if info.Size() == 0 {
+		return nil, nil
+	}
+
+	archive, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("open zip archive: %s", err)
+	}
+
+	members := make([]*zip.File, 0, len(archive.File))
+	for _, member := range archive.File {
+		if member.Mode().IsRegular() {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+// lazyMemberReader opens a zip archive member when it is first read
+type lazyMemberReader struct {
+	member *zip.File
+	rc     io.ReadCloser
+}
+
+func (r *lazyMemberReader) Read(p []byte) (int, error) {
+	if r.rc == nil {
+		rc, err := r.member.Open()
+		if err != nil {
+			return 0, err
+		}
+		r.rc = rc
+	}
+
+	n, err := r.rc.Read(p)
+	if err == io.EOF {
+		r.rc.Close()
+	}
+	return n, err
+}
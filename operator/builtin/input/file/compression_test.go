@@ -0,0 +1,274 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func writeGzipFile(t testing.TB, path string, s string) {
+	writeGzipFileLevel(t, path, s, gzip.DefaultCompression)
+}
+
+func writeGzipFileLevel(t testing.TB, path string, s string, level int) {
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	gz, err := gzip.NewWriterLevel(file, level)
+	require.NoError(t, err)
+	_, err = gz.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+}
+
+// bzip2Testlogs is "testlog1\ntestlog2\n" compressed with bzip2
+var bzip2Testlogs = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x37, 0x04, 0xa3, 0xbc, 0x00, 0x00,
+	0x07, 0x49, 0x80, 0x00, 0x10, 0x30, 0x00, 0x02, 0x84, 0x8c, 0x00, 0x20, 0x00, 0x21, 0x28, 0x06,
+	0x42, 0x0c, 0x98, 0x8a, 0xac, 0x18, 0x89, 0x10, 0x84, 0x33, 0xc5, 0xdc, 0x91, 0x4e, 0x14, 0x24,
+	0x0d, 0xc1, 0x28, 0xef, 0x00,
+}
+
+func writeZipFile(t testing.TB, path string, members map[string]string, order []string) {
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	w := zip.NewWriter(file)
+	for _, name := range order {
+		member, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = member.Write([]byte(members[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+}
+
+func TestReadGzipFile(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = "gzip"
+	}, nil)
+
+	writeGzipFile(t, filepath.Join(tempDir, "app.log.1"), "testlog1\ntestlog2\n")
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	waitForMessage(t, logReceived, "testlog1")
+	waitForMessage(t, logReceived, "testlog2")
+	expectNoMessages(t, logReceived)
+}
+
+func TestReadGzipFileAutoDetect(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = "auto"
+	}, nil)
+
+	writeGzipFile(t, filepath.Join(tempDir, "app.log.1.gz"), "testlog1\n")
+	writeGzipFile(t, filepath.Join(tempDir, "app.log.2"), "testlog2\n")
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog3\n")
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	waitForMessages(t, logReceived, []string{"testlog1", "testlog2", "testlog3"})
+}
+
+// GzipRotatedFileNotReread tests that a file which is compressed after
+// rotation is recognized by its decompressed fingerprint and not read again
+func TestGzipRotatedFileNotReread(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = "auto"
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\ntestlog2\n")
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+	waitForMessage(t, logReceived, "testlog2")
+
+	require.NoError(t, temp.Close())
+	require.NoError(t, os.Remove(temp.Name()))
+	writeGzipFile(t, temp.Name()+".1.gz", "testlog1\ntestlog2\n")
+
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+}
+
+func TestReadGzipFileStartAtEnd(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = "gzip"
+		cfg.StartAt = "end"
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	writeGzipFile(t, filepath.Join(tempDir, "app.log.1"), "testlog1\ntestlog2\n")
+
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+}
+
+// GzipFileUnchangedNotDecompressed tests that a compressed file which was read to
+// the end is not decompressed again until its size or modification time changes
+func TestGzipFileUnchangedNotDecompressed(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = "gzip"
+		cfg.FingerprintSize = helper.ByteSize(16)
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	// The content is stored without compression, so that both versions have the same size
+	path := filepath.Join(tempDir, "app.log.1")
+	writeGzipFileLevel(t, path, "testlog1\ntestlogxx", gzip.NoCompression)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+	expectNoMessages(t, logReceived)
+
+	// The file has the same size and modification time, so it is not read
+	writeGzipFileLevel(t, path, "testlog1\ntestlog2\n", gzip.NoCompression)
+	require.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+
+	modTime := info.ModTime().Add(time.Second)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog2")
+}
+
+func TestReadBzip2File(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = "bzip2"
+	}, nil)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tempDir, "app.log.1"), bzip2Testlogs, 0600))
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	waitForMessages(t, logReceived, []string{"testlog1", "testlog2"})
+	expectNoMessages(t, logReceived)
+}
+
+func TestReadArchivesAutoDetect(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = "auto"
+	}, nil)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tempDir, "app.log.1.bz2"), bzip2Testlogs, 0600))
+	writeZipFile(t, filepath.Join(tempDir, "app.log.2"), map[string]string{"a.log": "testlog3\n"}, []string{"a.log"})
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	waitForMessages(t, logReceived, []string{"testlog1", "testlog2", "testlog3"})
+	expectNoMessages(t, logReceived)
+}
+
+func TestReadZipFile(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = "auto"
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	path := filepath.Join(tempDir, "logs.zip")
+	writeZipFile(t, path, map[string]string{
+		"app.log":     "testlog1\ntestlog2",
+		"dir/":        "",
+		"dir/web.log": "testlog3\n",
+	}, []string{"app.log", "dir/", "dir/web.log"})
+
+	operator.poll(context.Background())
+	for _, expected := range []struct{ body, member string }{
+		{"testlog1", "app.log"},
+		{"testlog2", "app.log"},
+		{"testlog3", "dir/web.log"},
+	} {
+		e := waitForOne(t, logReceived)
+		require.Equal(t, expected.body, e.Body)
+		require.Equal(t, "logs.zip", e.Attributes["archive.name"])
+		require.Equal(t, expected.member, e.Attributes["archive.member"])
+	}
+	expectNoMessages(t, logReceived)
+
+	// The offsets of the members are kept, so the archive is not read again
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+	require.Equal(t, map[string]int64{"app.log": 17, "dir/web.log": 9}, operator.knownFiles[0].MemberOffsets)
+}
+
+// ZipMemberAdded tests that when an archive is rewritten with an additional
+// member, only the new member is read
+func TestZipMemberAdded(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = "zip"
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	path := filepath.Join(tempDir, "logs.zip")
+	writeZipFile(t, path, map[string]string{"a.log": "testlog1\n"}, []string{"a.log"})
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+
+	writeZipFile(t, path, map[string]string{"a.log": "testlog1\n", "b.log": "testlog2\n"}, []string{"a.log", "b.log"})
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog2")
+	expectNoMessages(t, logReceived)
+}
+
+func TestReadZipFileStartAtEnd(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = "zip"
+		cfg.StartAt = "end"
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	writeZipFile(t, filepath.Join(tempDir, "logs.zip"), map[string]string{"a.log": "testlog1\n"}, []string{"a.log"})
+
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+}
@@ -15,10 +15,15 @@
 package file
 
 import (
+	"bufio"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/bmatcuk/doublestar/v3"
+	"golang.org/x/text/encoding"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator"
@@ -37,14 +42,19 @@ const (
 // NewInputConfig creates a new input config with default values
 func NewInputConfig(operatorID string) *InputConfig {
 	return &InputConfig{
-		InputConfig:        helper.NewInputConfig(operatorID, "file_input"),
-		PollInterval:       helper.Duration{Duration: 200 * time.Millisecond},
-		IncludeFileName:    true,
-		IncludeFilePath:    false,
-		StartAt:            "end",
-		MaxLogSize:         defaultMaxLogSize,
-		MaxConcurrentFiles: defaultMaxConcurrentFiles,
-		Encoding:           helper.NewEncodingConfig(),
+		InputConfig:         helper.NewInputConfig(operatorID, "file_input"),
+		PollInterval:        helper.Duration{Duration: 200 * time.Millisecond},
+		IncludeFileName:     true,
+		IncludeFilePath:     false,
+		StartAt:             "end",
+		MaxLogSize:          defaultMaxLogSize,
+		MaxConcurrentFiles:  defaultMaxConcurrentFiles,
+		LongLineMode:        longLineModeError,
+		Encoding:            helper.NewEncodingConfig(),
+		DeleteMode:          deleteModeDelete,
+		FingerprintStrategy: fingerprintStrategyFirstBytes,
+		WatchMode:           watchModePoll,
+		FollowSymlinks:      followSymlinksAll,
 	}
 }
 
@@ -55,15 +65,41 @@ type InputConfig struct {
 	Include []string `mapstructure:"include,omitempty" json:"include,omitempty" yaml:"include,omitempty"`
 	Exclude []string `mapstructure:"exclude,omitempty" json:"exclude,omitempty" yaml:"exclude,omitempty"`
 
-	PollInterval       helper.Duration        `mapstructure:"poll_interval,omitempty"         json:"poll_interval,omitempty"        yaml:"poll_interval,omitempty"`
-	Multiline          helper.MultilineConfig `mapstructure:"multiline,omitempty"             json:"multiline,omitempty"            yaml:"multiline,omitempty"`
-	IncludeFileName    bool                   `mapstructure:"include_file_name,omitempty"     json:"include_file_name,omitempty"    yaml:"include_file_name,omitempty"`
-	IncludeFilePath    bool                   `mapstructure:"include_file_path,omitempty"     json:"include_file_path,omitempty"    yaml:"include_file_path,omitempty"`
-	StartAt            string                 `mapstructure:"start_at,omitempty"              json:"start_at,omitempty"             yaml:"start_at,omitempty"`
-	FingerprintSize    helper.ByteSize        `mapstructure:"fingerprint_size,omitempty"      json:"fingerprint_size,omitempty"     yaml:"fingerprint_size,omitempty"`
-	MaxLogSize         helper.ByteSize        `mapstructure:"max_log_size,omitempty"          json:"max_log_size,omitempty"         yaml:"max_log_size,omitempty"`
-	MaxConcurrentFiles int                    `mapstructure:"max_concurrent_files,omitempty"  json:"max_concurrent_files,omitempty" yaml:"max_concurrent_files,omitempty"`
-	Encoding           helper.EncodingConfig  `mapstructure:",squash,omitempty"               json:",inline,omitempty"              yaml:",inline,omitempty"`
+	ExcludeOlderThan helper.Duration `mapstructure:"exclude_older_than,omitempty" json:"exclude_older_than,omitempty" yaml:"exclude_older_than,omitempty"`
+	FollowSymlinks   string          `mapstructure:"follow_symlinks,omitempty"    json:"follow_symlinks,omitempty"    yaml:"follow_symlinks,omitempty"`
+
+	IncludeFileContentRegex string `mapstructure:"include_file_content_regex,omitempty" json:"include_file_content_regex,omitempty" yaml:"include_file_content_regex,omitempty"`
+	ExcludeFileContentRegex string `mapstructure:"exclude_file_content_regex,omitempty" json:"exclude_file_content_regex,omitempty" yaml:"exclude_file_content_regex,omitempty"`
+
+	PollInterval        helper.Duration        `mapstructure:"poll_interval,omitempty"         json:"poll_interval,omitempty"        yaml:"poll_interval,omitempty"`
+	MaxPollInterval     helper.Duration        `mapstructure:"max_poll_interval,omitempty"     json:"max_poll_interval,omitempty"    yaml:"max_poll_interval,omitempty"`
+	WatchMode           string                 `mapstructure:"watch_mode,omitempty"            json:"watch_mode,omitempty"           yaml:"watch_mode,omitempty"`
+	Multiline           helper.MultilineConfig `mapstructure:"multiline,omitempty"             json:"multiline,omitempty"            yaml:"multiline,omitempty"`
+	Format              string                 `mapstructure:"format,omitempty"                json:"format,omitempty"               yaml:"format,omitempty"`
+	Header              *HeaderConfig          `mapstructure:"header,omitempty"                json:"header,omitempty"               yaml:"header,omitempty"`
+	IncludeFileName     bool                   `mapstructure:"include_file_name,omitempty"     json:"include_file_name,omitempty"    yaml:"include_file_name,omitempty"`
+	IncludeFilePath     bool                   `mapstructure:"include_file_path,omitempty"     json:"include_file_path,omitempty"    yaml:"include_file_path,omitempty"`
+	IncludeFileMetadata bool                   `mapstructure:"include_file_metadata,omitempty" json:"include_file_metadata,omitempty" yaml:"include_file_metadata,omitempty"`
+	StartAt             string                 `mapstructure:"start_at,omitempty"              json:"start_at,omitempty"             yaml:"start_at,omitempty"`
+	StartAtTime         *StartAtTimeConfig     `mapstructure:"start_at_time,omitempty"         json:"start_at_time,omitempty"        yaml:"start_at_time,omitempty"`
+	FingerprintSize     helper.ByteSize        `mapstructure:"fingerprint_size,omitempty"      json:"fingerprint_size,omitempty"     yaml:"fingerprint_size,omitempty"`
+	FingerprintStrategy string                 `mapstructure:"fingerprint_strategy,omitempty"  json:"fingerprint_strategy,omitempty" yaml:"fingerprint_strategy,omitempty"`
+	FingerprintOffset   helper.ByteSize        `mapstructure:"fingerprint_offset,omitempty"    json:"fingerprint_offset,omitempty"   yaml:"fingerprint_offset,omitempty"`
+	MaxLogSize          helper.ByteSize        `mapstructure:"max_log_size,omitempty"          json:"max_log_size,omitempty"         yaml:"max_log_size,omitempty"`
+	MaxConcurrentFiles  int                    `mapstructure:"max_concurrent_files,omitempty"  json:"max_concurrent_files,omitempty" yaml:"max_concurrent_files,omitempty"`
+	LongLineMode        string                 `mapstructure:"long_line_mode,omitempty"        json:"long_line_mode,omitempty"       yaml:"long_line_mode,omitempty"`
+	MaxBytesPerSec      helper.ByteSize        `mapstructure:"max_bytes_per_sec,omitempty"     json:"max_bytes_per_sec,omitempty"    yaml:"max_bytes_per_sec,omitempty"`
+	MaxLinesPerSec      int                    `mapstructure:"max_lines_per_sec,omitempty"     json:"max_lines_per_sec,omitempty"    yaml:"max_lines_per_sec,omitempty"`
+	Ordering            string                 `mapstructure:"ordering,omitempty"              json:"ordering,omitempty"             yaml:"ordering,omitempty"`
+	Compression         string                 `mapstructure:"compression,omitempty"           json:"compression,omitempty"          yaml:"compression,omitempty"`
+	DeleteAfterRead     bool                   `mapstructure:"delete_after_read,omitempty"     json:"delete_after_read,omitempty"    yaml:"delete_after_read,omitempty"`
+	DeleteMode          string                 `mapstructure:"delete_mode,omitempty"           json:"delete_mode,omitempty"          yaml:"delete_mode,omitempty"`
+	DeleteMinIdle       helper.Duration        `mapstructure:"delete_min_idle,omitempty"       json:"delete_min_idle,omitempty"      yaml:"delete_min_idle,omitempty"`
+	OnComplete          *OnCompleteConfig      `mapstructure:"on_complete,omitempty"           json:"on_complete,omitempty"          yaml:"on_complete,omitempty"`
+	CheckpointNamespace string                 `mapstructure:"checkpoint_namespace,omitempty"  json:"checkpoint_namespace,omitempty" yaml:"checkpoint_namespace,omitempty"`
+	ShareDelete         bool                   `mapstructure:"share_delete,omitempty"          json:"share_delete,omitempty"         yaml:"share_delete,omitempty"`
+	NetworkFSMode       bool                   `mapstructure:"network_fs_mode,omitempty"       json:"network_fs_mode,omitempty"      yaml:"network_fs_mode,omitempty"`
+	Encoding            helper.EncodingConfig  `mapstructure:",squash,omitempty"               json:",inline,omitempty"              yaml:",inline,omitempty"`
 }
 
 // Build will build a file input operator from the supplied configuration
@@ -93,6 +129,28 @@ func (c InputConfig) Build(context operator.BuildContext) ([]operator.Operator,
 		}
 	}
 
+	if c.ExcludeOlderThan.Raw() < 0 {
+		return nil, fmt.Errorf("`exclude_older_than` must not be negative")
+	}
+
+	var includeContent, excludeContent *regexp.Regexp
+	if c.IncludeFileContentRegex != "" {
+		includeContent, err = regexp.Compile(c.IncludeFileContentRegex)
+		if err != nil {
+			return nil, fmt.Errorf("compiling include_file_content_regex: %s", err)
+		}
+	}
+	if c.ExcludeFileContentRegex != "" {
+		excludeContent, err = regexp.Compile(c.ExcludeFileContentRegex)
+		if err != nil {
+			return nil, fmt.Errorf("compiling exclude_file_content_regex: %s", err)
+		}
+	}
+
+	if c.MaxPollInterval.Raw() != 0 && c.MaxPollInterval.Raw() < c.PollInterval.Raw() {
+		return nil, fmt.Errorf("`max_poll_interval` must not be less than `poll_interval`")
+	}
+
 	if c.MaxLogSize <= 0 {
 		return nil, fmt.Errorf("`max_log_size` must be positive")
 	}
@@ -101,30 +159,160 @@ func (c InputConfig) Build(context operator.BuildContext) ([]operator.Operator,
 		return nil, fmt.Errorf("`max_concurrent_files` must be positive")
 	}
 
+	if c.MaxBytesPerSec < 0 {
+		return nil, fmt.Errorf("`max_bytes_per_sec` must not be negative")
+	}
+
+	if c.MaxLinesPerSec < 0 {
+		return nil, fmt.Errorf("`max_lines_per_sec` must not be negative")
+	}
+
 	if c.FingerprintSize == 0 {
 		c.FingerprintSize = defaultFingerprintSize
 	} else if c.FingerprintSize < minFingerprintSize {
 		return nil, fmt.Errorf("`fingerprint_size` must be at least %d bytes", minFingerprintSize)
 	}
 
-	encoding, err := c.Encoding.Build(context)
+	switch c.FingerprintStrategy {
+	case fingerprintStrategyFirstBytes, fingerprintStrategyInode:
+		if c.FingerprintOffset != 0 {
+			return nil, fmt.Errorf("`fingerprint_offset` can only be used with the `hash` fingerprint strategy")
+		}
+	case fingerprintStrategyHash:
+		if c.FingerprintOffset < 0 {
+			return nil, fmt.Errorf("`fingerprint_offset` must not be negative")
+		}
+	default:
+		return nil, fmt.Errorf("invalid fingerprint_strategy '%s'", c.FingerprintStrategy)
+	}
+
+	// With automatic detection, files are read as UTF-8 until
+	// their encoding has been detected
+	encodingConfig := c.Encoding
+	autoEncoding := strings.EqualFold(encodingConfig.Encoding, encodingAuto)
+	if autoEncoding {
+		encodingConfig.Encoding = "utf-8"
+	}
+
+	splitFuncFor := func(enc encoding.Encoding) (bufio.SplitFunc, error) {
+		return c.Multiline.Build(context, enc, false)
+	}
+
+	encoding, err := encodingConfig.Build(context)
 	if err != nil {
 		return nil, err
 	}
 
-	splitFunc, err := c.Multiline.Build(context, encoding.Encoding, false)
+	splitFunc, err := splitFuncFor(encoding.Encoding)
 	if err != nil {
 		return nil, err
 	}
 
 	var startAtBeginning bool
+	var start *startPosition
 	switch c.StartAt {
 	case "beginning":
 		startAtBeginning = true
 	case "end":
 		startAtBeginning = false
 	default:
-		return nil, fmt.Errorf("invalid start_at location '%s'", c.StartAt)
+		start, err = parseStartPosition(c.StartAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var startAtTime *timestampFinder
+	if start != nil && !start.time.IsZero() {
+		if c.StartAtTime == nil {
+			return nil, fmt.Errorf("`start_at_time` is required when `start_at` is a timestamp")
+		}
+		startAtTime, err = c.StartAtTime.Build(context)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch c.WatchMode {
+	case watchModePoll, watchModeNotify:
+	default:
+		return nil, fmt.Errorf("invalid watch_mode '%s'", c.WatchMode)
+	}
+
+	var header *headerParser
+	if c.Header != nil {
+		header, err = c.Header.Build()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch c.FollowSymlinks {
+	case followSymlinksNever, followSymlinksFiles, followSymlinksDirectories, followSymlinksAll:
+	default:
+		return nil, fmt.Errorf("invalid follow_symlinks '%s'", c.FollowSymlinks)
+	}
+
+	switch c.Ordering {
+	case orderingNone, orderingMtime, orderingName, orderingNumericSuffix:
+	default:
+		return nil, fmt.Errorf("invalid ordering '%s'", c.Ordering)
+	}
+
+	switch c.Compression {
+	case compressionNone, compressionGzip, compressionBzip2, compressionZip, compressionAuto:
+	default:
+		return nil, fmt.Errorf("invalid compression '%s'", c.Compression)
+	}
+
+	switch c.Format {
+	case formatNone:
+	case formatCRI:
+		if c.Multiline.LineStartPattern != "" || c.Multiline.LineEndPattern != "" {
+			return nil, fmt.Errorf("`multiline` cannot be used with `format: cri`")
+		}
+	default:
+		return nil, fmt.Errorf("invalid format '%s'", c.Format)
+	}
+
+	switch c.LongLineMode {
+	case longLineModeError, longLineModeSplit:
+	default:
+		return nil, fmt.Errorf("invalid long_line_mode '%s'", c.LongLineMode)
+	}
+
+	switch c.DeleteMode {
+	case deleteModeDelete, deleteModeTruncate:
+	default:
+		return nil, fmt.Errorf("invalid delete_mode '%s'", c.DeleteMode)
+	}
+
+	if c.DeleteAfterRead && c.StartAt == "end" {
+		return nil, fmt.Errorf("`delete_after_read` cannot be used with `start_at: end`")
+	}
+
+	if c.DeleteMinIdle.Raw() < 0 {
+		return nil, fmt.Errorf("`delete_min_idle` must not be negative")
+	}
+
+	// Files must not have been modified for at least one poll interval before they are deleted
+	minIdle := c.PollInterval.Raw()
+	if c.DeleteAfterRead && c.DeleteMinIdle.Raw() > minIdle {
+		minIdle = c.DeleteMinIdle.Raw()
+	}
+
+	var completeDestination string
+	if c.OnComplete != nil {
+		if err := c.OnComplete.validate(); err != nil {
+			return nil, err
+		}
+		completeDestination = filepath.Clean(c.OnComplete.Destination)
+		if c.DeleteAfterRead {
+			return nil, fmt.Errorf("`on_complete` cannot be used with `delete_after_read`")
+		}
+		if c.StartAt == "end" {
+			return nil, fmt.Errorf("`on_complete` cannot be used with `start_at: end`")
+		}
 	}
 
 	fileNameField := entry.NewNilField()
@@ -138,23 +326,53 @@ func (c InputConfig) Build(context operator.BuildContext) ([]operator.Operator,
 	}
 
 	op := &InputOperator{
-		InputOperator:      inputOperator,
-		Include:            c.Include,
-		Exclude:            c.Exclude,
-		SplitFunc:          splitFunc,
-		PollInterval:       c.PollInterval.Raw(),
-		FilePathField:      filePathField,
-		FileNameField:      fileNameField,
-		startAtBeginning:   startAtBeginning,
-		queuedMatches:      make([]string, 0),
-		encoding:           encoding,
-		firstCheck:         true,
-		cancel:             func() {},
-		knownFiles:         make([]*Reader, 0, 10),
-		fingerprintSize:    int(c.FingerprintSize),
-		MaxLogSize:         int(c.MaxLogSize),
-		MaxConcurrentFiles: c.MaxConcurrentFiles,
-		SeenPaths:          make(map[string]struct{}, 100),
+		InputOperator:       inputOperator,
+		Include:             c.Include,
+		Exclude:             c.Exclude,
+		excludeOlderThan:    c.ExcludeOlderThan.Raw(),
+		includeContent:      includeContent,
+		excludeContent:      excludeContent,
+		followSymlinks:      c.FollowSymlinks,
+		SplitFunc:           splitFunc,
+		splitFuncFor:        splitFuncFor,
+		forceFlushPeriod:    c.Multiline.ForceFlushPeriod.Raw(),
+		PollInterval:        c.PollInterval.Raw(),
+		maxPollInterval:     c.MaxPollInterval.Raw(),
+		watchMode:           c.WatchMode,
+		ordering:            c.Ordering,
+		header:              header,
+		scheduler:           newScheduler(c.Include),
+		FilePathField:       filePathField,
+		FileNameField:       fileNameField,
+		includeFileMetadata: c.IncludeFileMetadata,
+		startAtBeginning:    startAtBeginning,
+		startPosition:       start,
+		startAtTime:         startAtTime,
+		compression:         c.Compression,
+		deleteAfterRead:     c.DeleteAfterRead,
+		deleteMode:          c.DeleteMode,
+		minIdle:             minIdle,
+		onComplete:          c.OnComplete,
+		completeDestination: completeDestination,
+		checkpointNamespace: c.CheckpointNamespace,
+		shareDelete:         c.ShareDelete,
+		networkFS:           c.NetworkFSMode,
+		queuedMatches:       make([]string, 0),
+		encoding:            encoding,
+		autoEncoding:        autoEncoding,
+		firstCheck:          true,
+		cancel:              func() {},
+		knownFiles:          make([]*Reader, 0, 10),
+		fingerprintSize:     int(c.FingerprintSize),
+		fingerprintStrategy: c.FingerprintStrategy,
+		fingerprintOffset:   int64(c.FingerprintOffset),
+		MaxLogSize:          int(c.MaxLogSize),
+		splitLongLines:      c.LongLineMode == longLineModeSplit,
+		format:              c.Format,
+		MaxConcurrentFiles:  c.MaxConcurrentFiles,
+		maxBytesPerSec:      int(c.MaxBytesPerSec),
+		maxLinesPerSec:      c.MaxLinesPerSec,
+		SeenPaths:           make(map[string]struct{}, 100),
 	}
 
 	return []operator.Operator{op}, nil
@@ -503,6 +503,231 @@ func TestConfig(t *testing.T) {
 				return cfg
 			}(),
 		},
+		{
+			Name:      "long_line_mode_split",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.LongLineMode = "split"
+				return cfg
+			}(),
+		},
+		{
+			Name:      "encoding_auto",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.Encoding = helper.EncodingConfig{Encoding: "auto"}
+				return cfg
+			}(),
+		},
+		{
+			Name:      "delete_after_read",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.StartAt = "beginning"
+				cfg.DeleteAfterRead = true
+				cfg.DeleteMode = "truncate"
+				cfg.DeleteMinIdle = helper.NewDuration(time.Minute)
+				return cfg
+			}(),
+		},
+		{
+			Name:      "fingerprint_strategy_inode",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.FingerprintStrategy = "inode"
+				return cfg
+			}(),
+		},
+		{
+			Name:      "fingerprint_strategy_hash",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.FingerprintStrategy = "hash"
+				cfg.FingerprintOffset = helper.ByteSize(4096)
+				cfg.FingerprintSize = helper.ByteSize(64)
+				return cfg
+			}(),
+		},
+		{
+			Name:      "include_file_metadata",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.IncludeFileMetadata = true
+				return cfg
+			}(),
+		},
+		{
+			Name:      "watch_mode_notify",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.WatchMode = "notify"
+				return cfg
+			}(),
+		},
+		{
+			Name:      "ordering_numeric_suffix",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.Ordering = "numeric_suffix"
+				return cfg
+			}(),
+		},
+		{
+			Name:      "exclude_older_than",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.ExcludeOlderThan = helper.NewDuration(24 * time.Hour)
+				return cfg
+			}(),
+		},
+		{
+			Name:      "include_file_content_regex",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.IncludeFileContentRegex = `^{"schema":"v2"`
+				return cfg
+			}(),
+		},
+		{
+			Name:      "follow_symlinks_never",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.FollowSymlinks = "never"
+				return cfg
+			}(),
+		},
+		{
+			Name:      "max_per_sec",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.MaxBytesPerSec = 1024 * 1024
+				cfg.MaxLinesPerSec = 1000
+				return cfg
+			}(),
+		},
+		{
+			Name:      "start_at_offset",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.StartAt = "1024"
+				return cfg
+			}(),
+		},
+		{
+			Name:      "start_at_timestamp",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.StartAt = "2021-06-01T10:00:00Z"
+				cfg.StartAtTime = &StartAtTimeConfig{
+					Regex:  `^(\S+ \S+)`,
+					Layout: "%Y-%m-%d %H:%M:%S",
+				}
+				return cfg
+			}(),
+		},
+		{
+			Name:      "checkpoint_namespace",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.CheckpointNamespace = "app_logs"
+				return cfg
+			}(),
+		},
+		{
+			Name:      "share_delete",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.ShareDelete = true
+				return cfg
+			}(),
+		},
+		{
+			Name:      "on_complete_move",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.StartAt = "beginning"
+				cfg.OnComplete = &OnCompleteConfig{
+					Action:      "move",
+					Destination: "processed",
+				}
+				return cfg
+			}(),
+		},
+		{
+			Name:      "max_poll_interval",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.MaxPollInterval = helper.NewDuration(30 * time.Second)
+				return cfg
+			}(),
+		},
+		{
+			Name:      "header",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.Header = &HeaderConfig{
+					Pattern:    "^#",
+					ParseRegex: "^#(?P<key>[^:]+): (?P<value>.*)$",
+					Target:     "resource",
+				}
+				return cfg
+			}(),
+		},
+		{
+			Name:      "compression_gzip",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.Compression = "gzip"
+				return cfg
+			}(),
+		},
+		{
+			Name:      "compression_zip",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.Compression = "zip"
+				return cfg
+			}(),
+		},
+		{
+			Name:      "format_cri",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.Format = "cri"
+				return cfg
+			}(),
+		},
+		{
+			Name:      "network_fs_mode",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.NetworkFSMode = true
+				return cfg
+			}(),
+		},
 	}
 
 	for _, tc := range cases {
@@ -553,6 +778,11 @@ func TestMapStructureDecodeConfigWithHook(t *testing.T) {
 		"max_log_size":         "1mib",
 		"max_concurrent_files": 1024,
 		"encoding":             "utf16",
+		"delete_mode":          "delete",
+		"long_line_mode":       "error",
+		"fingerprint_strategy": "first_bytes",
+		"watch_mode":           "poll",
+		"follow_symlinks":      "all",
 	}
 
 	var actual InputConfig
@@ -589,6 +819,11 @@ func TestMapStructureDecodeConfig(t *testing.T) {
 		"max_log_size":         1024 * 1024,
 		"max_concurrent_files": 1024,
 		"encoding":             "utf16",
+		"delete_mode":          "delete",
+		"long_line_mode":       "error",
+		"fingerprint_strategy": "first_bytes",
+		"watch_mode":           "poll",
+		"follow_symlinks":      "all",
 	}
 
 	var actual InputConfig
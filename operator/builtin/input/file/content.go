@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// filterContent excludes the files whose first line does not satisfy the
+// configured content regexes. Files whose first line is not yet complete
+// are excluded until it is, so that they can be matched against the whole line.
+func (f *InputOperator) filterContent(files []*os.File, fps []*Fingerprint) ([]*os.File, []*Fingerprint) {
+	filteredFiles := files[:0]
+	filteredFps := fps[:0]
+	for i, file := range files {
+		line, complete, err := f.firstLine(file, fps[i])
+		if err != nil {
+			f.Errorw("Failed to read first line", "path", file.Name(), zap.Error(err))
+			file.Close()
+			continue
+		}
+		if !complete || !f.matchesContent(line) {
+			file.Close()
+			continue
+		}
+		filteredFiles = append(filteredFiles, file)
+		filteredFps = append(filteredFps, fps[i])
+	}
+	return filteredFiles, filteredFps
+}
+
+// firstLine returns the first line of the file, up to the fingerprint size.
+// It returns false if the first line may not yet have been completely written.
+func (f *InputOperator) firstLine(file *os.File, fp *Fingerprint) ([]byte, bool, error) {
+	content := fp.FirstBytes
+	if f.fingerprintStrategy == fingerprintStrategyHash {
+		buf := make([]byte, f.fingerprintSize)
+		n, err := f.readFingerprintBytes(file, buf, 0)
+		if err != nil && err != io.EOF {
+			return nil, false, err
+		}
+		content = buf[:n]
+	}
+
+	if i := bytes.IndexByte(content, '\n'); i >= 0 {
+		return bytes.TrimSuffix(content[:i], []byte("\r")), true, nil
+	}
+	return content, len(content) >= f.fingerprintSize, nil
+}
+
+// matchesContent returns true if the line matches the include_file_content_regex,
+// if any, and does not match the exclude_file_content_regex, if any
+func (f *InputOperator) matchesContent(line []byte) bool {
+	if f.includeContent != nil && !f.includeContent.Match(line) {
+		return false
+	}
+	if f.excludeContent != nil && f.excludeContent.Match(line) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// FileContentRegex tests that only files whose first line
+// matches the content regexes are read
+func TestFileContentRegex(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.IncludeFileContentRegex = `^{"schema":"v2"`
+		cfg.ExcludeFileContentRegex = `"test":true`
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	v1 := openTemp(t, tempDir)
+	writeString(t, v1, "{\"schema\":\"v1\"}\nv1 entry\n")
+	v2 := openTemp(t, tempDir)
+	writeString(t, v2, "{\"schema\":\"v2\"}\nv2 entry\n")
+	test := openTemp(t, tempDir)
+	writeString(t, test, "{\"schema\":\"v2\",\"test\":true}\ntest entry\n")
+
+	operator.poll(context.Background())
+	waitForMessages(t, logReceived, []string{"{\"schema\":\"v2\"}", "v2 entry"})
+	expectNoMessages(t, logReceived)
+}
+
+// FileContentRegexIncompleteLine tests that a file is not matched
+// against the content regexes until its first line is complete
+func TestFileContentRegexIncompleteLine(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.ExcludeFileContentRegex = `"test":true`
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "{\"schema\":\"v2\"")
+	operator.poll(context.Background())
+	require.Empty(t, operator.knownFiles)
+
+	writeString(t, temp, ",\"test\":true}\ntest entry\n")
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+}
+
+func TestFirstLine(t *testing.T) {
+	t.Parallel()
+	operator, _, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.FingerprintSize = helper.ByteSize(minFingerprintSize)
+	}, nil)
+
+	cases := []struct {
+		name         string
+		contents     string
+		expected     string
+		expectedDone bool
+	}{
+		{"Complete", "first\r\nsecond\n", "first", true},
+		{"Incomplete", "first", "first", false},
+		{"LongerThanFingerprint", "a very long first line\n", "a very long firs", true},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			temp := openTemp(t, tempDir)
+			writeString(t, temp, tc.contents)
+			fp, err := operator.NewFingerprint(temp)
+			require.NoError(t, err)
+
+			line, done, err := operator.firstLine(temp, fp)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(line))
+			require.Equal(t, tc.expectedDone, done)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bytes"
+	"context"
+	"time"
+)
+
+const (
+	formatNone = ""
+	formatCRI  = "cri"
+)
+
+const (
+	criPartialTag      = "P"
+	criStreamAttribute = "log.iostream"
+)
+
+// CRIPartial holds the parts of a line which a container runtime split into
+// several lines of a CRI log, until the line's final part is read
+type CRIPartial struct {
+	Message   []byte
+	Timestamp time.Time
+}
+
+// emitCRI emits the line of a CRI log, which has the format
+// `<timestamp> <stream> <tag> <message>`. Lines whose tag is P are partial,
+// and are joined with the following lines of the same stream, up to the
+// line whose tag is F. Lines which are not in the CRI format are emitted as is.
+func (f *Reader) emitCRI(ctx context.Context, line []byte, attributes map[string]string) error {
+	fields := bytes.SplitN(line, []byte(" "), 4)
+	if len(fields) < 3 {
+		return f.emitEntry(ctx, line, attributes, time.Time{})
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, string(fields[0]))
+	if err != nil {
+		return f.emitEntry(ctx, line, attributes, time.Time{})
+	}
+
+	var message []byte
+	if len(fields) == 4 {
+		message = fields[3]
+	}
+	stream := string(fields[1])
+
+	// The tag may be followed by other tags separated by colons
+	tag := string(bytes.SplitN(fields[2], []byte(":"), 2)[0])
+
+	partial, ok := f.CRIPartials[stream]
+	if ok {
+		partial.Message = append(partial.Message, message...)
+		timestamp = partial.Timestamp
+	} else {
+		partial = &CRIPartial{Message: append([]byte(nil), message...), Timestamp: timestamp}
+	}
+
+	if tag == criPartialTag && len(partial.Message) < f.fileInput.MaxLogSize {
+		if f.CRIPartials == nil {
+			f.CRIPartials = make(map[string]*CRIPartial)
+		}
+		f.CRIPartials[stream] = partial
+		return nil
+	}
+	delete(f.CRIPartials, stream)
+
+	criAttributes := make(map[string]string, len(attributes)+1)
+	for key, value := range attributes {
+		criAttributes[key] = value
+	}
+	criAttributes[criStreamAttribute] = stream
+	return f.emitEntry(ctx, partial.Message, criAttributes, timestamp)
+}
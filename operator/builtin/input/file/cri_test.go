@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// CRIFormat tests that CRI lines are parsed, and that
+// partial lines are joined with their final part
+func TestCRIFormat(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Format = formatCRI
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "2021-06-01T12:00:00.000000001Z stdout F complete line\n")
+	writeString(t, temp, "2021-06-01T12:00:01.000000001Z stdout P first part, \n")
+	writeString(t, temp, "2021-06-01T12:00:02.000000001Z stderr F error line\n")
+	writeString(t, temp, "2021-06-01T12:00:03.000000001Z stdout F second part\n")
+	writeString(t, temp, "not a cri line\n")
+
+	operator.poll(context.Background())
+
+	e := waitForOne(t, logReceived)
+	require.Equal(t, "complete line", e.Body)
+	require.Equal(t, "stdout", e.Attributes["log.iostream"])
+	require.Equal(t, time.Date(2021, 6, 1, 12, 0, 0, 1, time.UTC), e.Timestamp.UTC())
+
+	e = waitForOne(t, logReceived)
+	require.Equal(t, "error line", e.Body)
+	require.Equal(t, "stderr", e.Attributes["log.iostream"])
+
+	e = waitForOne(t, logReceived)
+	require.Equal(t, "first part, second part", e.Body)
+	require.Equal(t, "stdout", e.Attributes["log.iostream"])
+	require.Equal(t, time.Date(2021, 6, 1, 12, 0, 1, 1, time.UTC), e.Timestamp.UTC())
+
+	e = waitForOne(t, logReceived)
+	require.Equal(t, "not a cri line", e.Body)
+	require.NotContains(t, e.Attributes, "log.iostream")
+
+	expectNoMessages(t, logReceived)
+}
+
+// CRIFormatPartialAcrossPolls tests that a partial line is
+// joined with its final part when it is written later
+func TestCRIFormatPartialAcrossPolls(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Format = formatCRI
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "2021-06-01T12:00:00Z stdout P first\n")
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+
+	writeString(t, temp, "2021-06-01T12:00:01Z stdout P :second\n")
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+
+	writeString(t, temp, "2021-06-01T12:00:02Z stdout F :third\n")
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "first:second:third")
+}
+
+// CRIFormatMaxLogSize tests that partial lines are emitted
+// once they are joined up to max_log_size
+func TestCRIFormatMaxLogSize(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Format = formatCRI
+		cfg.MaxLogSize = 64
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "2021-06-01T12:00:00Z stdout P aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa\n")
+	writeString(t, temp, "2021-06-01T12:00:00Z stdout P bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb\n")
+	writeString(t, temp, "2021-06-01T12:00:00Z stdout F cc\n")
+
+	operator.poll(context.Background())
+	waitForMessages(t, logReceived, []string{
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaabbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+		"cc",
+	})
+}
+
+func TestCRIPartialsCopy(t *testing.T) {
+	t.Parallel()
+	operator, _, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Format = formatCRI
+	}, nil)
+
+	temp := openTemp(t, tempDir)
+	reader, err := operator.NewReader(temp.Name(), temp, &Fingerprint{})
+	require.NoError(t, err)
+	reader.CRIPartials = map[string]*CRIPartial{
+		"stdout": {Message: []byte("first"), Timestamp: time.Unix(1, 0)},
+	}
+
+	copied, err := reader.Copy(temp)
+	require.NoError(t, err)
+	copied.CRIPartials["stdout"].Message[0] = 'F'
+	require.Equal(t, "first", string(reader.CRIPartials["stdout"].Message))
+}
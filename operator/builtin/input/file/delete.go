@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+
+	"go.uber.org/zap"
+)
+
+const (
+	deleteModeDelete   = "delete"
+	deleteModeTruncate = "truncate"
+)
+
+// deleteFinishedFiles deletes or truncates the files of all readers that have
+// been read to completion, and returns true if any files were removed. The
+// fingerprints of removed files are forgotten so that a new file with the same
+// content will be read from the beginning.
+func (f *InputOperator) deleteFinishedFiles(readers []*Reader) bool {
+	removed := false
+	for _, reader := range readers {
+		if !reader.finished {
+			continue
+		}
+
+		var err error
+		if f.deleteMode == deleteModeTruncate {
+			err = os.Truncate(reader.Path, 0)
+		} else {
+			err = os.Remove(reader.Path)
+		}
+		if err != nil {
+			reader.Errorw("Failed to delete file after read", zap.Error(err))
+			continue
+		}
+
+		reader.Debugw("Deleted file after read", "delete_mode", f.deleteMode)
+		f.forgetFingerprint(reader.Fingerprint)
+		delete(f.SeenPaths, reader.Path)
+		removed = true
+	}
+	return removed
+}
+
+// forgetFingerprint removes all known files that match the fingerprint
+func (f *InputOperator) forgetFingerprint(fp *Fingerprint) {
+	knownFiles := make([]*Reader, 0, len(f.knownFiles))
+	for _, reader := range f.knownFiles {
+		if !fp.StartsWith(reader.Fingerprint) {
+			knownFiles = append(knownFiles, reader)
+		}
+	}
+	f.knownFiles = knownFiles
+}
@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestDeleteAfterRead(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.DeleteAfterRead = true
+	}, nil)
+
+	path := filepath.Join(tempDir, "batch.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("testlog1\ntestlog2\n"), 0600))
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	waitForMessage(t, logReceived, "testlog1")
+	waitForMessage(t, logReceived, "testlog2")
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return os.IsNotExist(err)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTruncateAfterRead(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.DeleteAfterRead = true
+		cfg.DeleteMode = "truncate"
+	}, nil)
+
+	path := filepath.Join(tempDir, "batch.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("testlog1\n"), 0600))
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	waitForMessage(t, logReceived, "testlog1")
+
+	require.Eventually(t, func() bool {
+		info, err := os.Stat(path)
+		return err == nil && info.Size() == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// Content written after truncation is read as a new file
+	require.NoError(t, ioutil.WriteFile(path, []byte("testlog1\n"), 0600))
+	waitForMessage(t, logReceived, "testlog1")
+}
+
+// DeleteAfterReadActiveFile tests that a file which was recently
+// modified is not deleted, even if it has been read to the end
+func TestDeleteAfterReadActiveFile(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.DeleteAfterRead = true
+		cfg.PollInterval.Duration = time.Hour
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+
+	_, err := os.Stat(temp.Name())
+	require.NoError(t, err)
+}
+
+// DeleteAfterReadPartialLine tests that a file is not deleted
+// while it contains content that has not yet been emitted
+func TestDeleteAfterReadPartialLine(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.DeleteAfterRead = true
+		cfg.PollInterval.Duration = time.Nanosecond
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\ntestlog2")
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+
+	_, err := os.Stat(temp.Name())
+	require.NoError(t, err)
+}
+
+// DeleteAfterReadMinIdle tests that a file is not deleted until
+// it has not been modified for the minimum idle time
+func TestDeleteAfterReadMinIdle(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.DeleteAfterRead = true
+		cfg.PollInterval.Duration = time.Nanosecond
+		cfg.DeleteMinIdle.Duration = time.Hour
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+
+	_, err := os.Stat(temp.Name())
+	require.NoError(t, err)
+}
+
+// failingPersister fails to save any values
+type failingPersister struct {
+	operator.Persister
+}
+
+func (p failingPersister) Set(context.Context, string, []byte) error {
+	return errors.New("database is unavailable")
+}
+
+// DeleteAfterReadSyncFailure tests that a file is not deleted
+// until its offset has been saved
+func TestDeleteAfterReadSyncFailure(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.DeleteAfterRead = true
+		cfg.PollInterval.Duration = time.Nanosecond
+	}, nil)
+	operator.persister = failingPersister{testutil.NewMockPersister("test")}
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+	time.Sleep(time.Millisecond)
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+
+	_, err := os.Stat(temp.Name())
+	require.NoError(t, err)
+
+	// The file is deleted once its offset can be saved
+	operator.persister = testutil.NewMockPersister("test")
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+	_, err = os.Stat(temp.Name())
+	require.True(t, os.IsNotExist(err))
+}
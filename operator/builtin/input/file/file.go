@@ -21,12 +21,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
+	"regexp"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bmatcuk/doublestar/v3"
 	"go.uber.org/zap"
+	"golang.org/x/text/encoding"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator"
@@ -52,15 +54,48 @@ type InputOperator struct {
 	knownFiles    []*Reader
 	queuedMatches []string
 
-	startAtBeginning bool
-
-	fingerprintSize int
-
-	encoding helper.Encoding
+	startAtBeginning    bool
+	startPosition       *startPosition
+	startAtTime         *timestampFinder
+	includeFileMetadata bool
+	watchMode           string
+	ordering            string
+	excludeOlderThan    time.Duration
+	includeContent      *regexp.Regexp
+	excludeContent      *regexp.Regexp
+	maxPollInterval     time.Duration
+	forceFlushPeriod    time.Duration
+	followSymlinks      string
+	maxBytesPerSec      int
+	splitLongLines      bool
+	format              string
+	networkFS           bool
+	maxLinesPerSec      int
+	header              *headerParser
+	scheduler           *scheduler
+	compression         string
+	deleteAfterRead     bool
+	deleteMode          string
+	minIdle             time.Duration
+	onComplete          *OnCompleteConfig
+	completeDestination string
+	checkpointNamespace string
+	shareDelete         bool
+
+	fingerprintSize     int
+	fingerprintStrategy string
+	fingerprintOffset   int64
+
+	encoding     helper.Encoding
+	autoEncoding bool
+	splitFuncFor func(encoding.Encoding) (bufio.SplitFunc, error)
 
 	wg         sync.WaitGroup
 	firstCheck bool
 	cancel     context.CancelFunc
+
+	truncated uint64
+	replaced  uint64
 }
 
 // Start will start the file monitoring process
@@ -70,11 +105,23 @@ func (f *InputOperator) Start(persister operator.Persister) error {
 	f.firstCheck = true
 
 	f.persister = persister
+	if f.checkpointNamespace != "" {
+		f.persister = operator.NewScopedPersister(checkpointScopePrefix+f.checkpointNamespace, operator.UnscopedPersister(persister))
+	}
+
 	// Load offsets from disk
-	if err := f.loadLastPollFiles(ctx); err != nil {
+	if err := f.loadLastPollFiles(ctx, f.persister); err != nil {
 		return fmt.Errorf("read known files from database: %s", err)
 	}
 
+	// Offsets which were saved before a checkpoint namespace was
+	// configured are stored in the scope of the operator ID
+	if f.checkpointNamespace != "" && len(f.knownFiles) == 0 {
+		if err := f.loadLastPollFiles(ctx, persister); err != nil {
+			return fmt.Errorf("read known files from database: %s", err)
+		}
+	}
+
 	// Start polling goroutine
 	f.startPoller(ctx)
 
@@ -90,9 +137,31 @@ func (f *InputOperator) Stop() error {
 	return nil
 }
 
+// Truncated returns the number of times a file was truncated below the offset which had been read,
+// and was read again from the beginning.
+func (f *InputOperator) Truncated() uint64 {
+	return atomic.LoadUint64(&f.truncated)
+}
+
+// Replaced returns the number of times the content of a file was replaced by different content which
+// is shorter than the offset which had been read, and was read as a new file.
+func (f *InputOperator) Replaced() uint64 {
+	return atomic.LoadUint64(&f.replaced)
+}
+
 // startPoller kicks off a goroutine that will poll the filesystem periodically,
-// checking if there are new files or new logs in the watched files
+// checking if there are new files or new logs in the watched files. In notify
+// watch mode, the filesystem is also polled whenever a watched file changes.
 func (f *InputOperator) startPoller(ctx context.Context) {
+	var notify <-chan struct{}
+	if f.watchMode == watchModeNotify {
+		var err error
+		notify, err = f.startWatcher(ctx)
+		if err != nil {
+			f.Warnw("Failed to start file watcher. Falling back to polling", zap.Error(err))
+		}
+	}
+
 	f.wg.Add(1)
 	go func() {
 		defer f.wg.Done()
@@ -104,6 +173,12 @@ func (f *InputOperator) startPoller(ctx context.Context) {
 			case <-ctx.Done():
 				return
 			case <-globTicker.C:
+			case <-notify:
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(notifyDebounce):
+				}
 			}
 
 			f.poll(ctx)
@@ -116,9 +191,11 @@ func (f *InputOperator) poll(ctx context.Context) {
 	var matches []string
 	if len(f.queuedMatches) > f.MaxConcurrentFiles {
 		matches, f.queuedMatches = f.queuedMatches[:f.MaxConcurrentFiles], f.queuedMatches[f.MaxConcurrentFiles:]
+		f.scheduler.setQueued(f.queuedMatches)
 	} else {
 		if len(f.queuedMatches) > 0 {
 			matches, f.queuedMatches = f.queuedMatches, make([]string, 0)
+			f.scheduler.setQueued(f.queuedMatches)
 		} else {
 			// Increment the generation on all known readers
 			// This is done here because the next generation is about to start
@@ -127,46 +204,89 @@ func (f *InputOperator) poll(ctx context.Context) {
 			}
 
 			// Get the list of paths on disk
-			matches = getMatches(f.Include, f.Exclude)
+			matches = getMatches(f.Include, f.Exclude, f.followSymlinks)
 			if f.firstCheck && len(matches) == 0 {
 				f.Warnw("no files match the configured include patterns", "include", f.Include)
-			} else if len(matches) > f.MaxConcurrentFiles {
+			}
+			if f.excludeOlderThan > 0 {
+				matches = filterOlderThan(matches, time.Now().Add(-f.excludeOlderThan))
+			}
+			if f.networkFS {
+				matches = filterSillyRenames(matches)
+			}
+			if f.onComplete != nil {
+				matches = f.filterCompleted(matches)
+			}
+			f.scheduler.update(matches, time.Now())
+			sortMatches(matches, f.ordering)
+			if len(matches) > f.MaxConcurrentFiles {
+				// Without an explicit ordering, the files which were read least
+				// recently are read first, so that no file is starved
+				if f.ordering == orderingNone {
+					f.scheduler.sortLeastRecentlyRead(matches)
+				}
 				matches, f.queuedMatches = matches[:f.MaxConcurrentFiles], matches[f.MaxConcurrentFiles:]
 			}
+			f.scheduler.setQueued(f.queuedMatches)
+			f.logStats()
 		}
 	}
 
 	readers := f.makeReaders(matches)
 	f.firstCheck = false
 
-	var wg sync.WaitGroup
-	for _, reader := range readers {
-		wg.Add(1)
-		go func(r *Reader) {
-			defer wg.Done()
-			r.ReadToEnd(ctx)
-		}(reader)
-	}
+	if f.ordering == orderingNone {
+		var wg sync.WaitGroup
+		for _, reader := range readers {
+			wg.Add(1)
+			go func(r *Reader) {
+				defer wg.Done()
+				r.ReadToEnd(ctx)
+			}(reader)
+		}
 
-	// Wait until all the reader goroutines are finished
-	wg.Wait()
+		// Wait until all the reader goroutines are finished
+		wg.Wait()
+	} else {
+		// Read the files one at a time, so that each file is read
+		// to the end before moving on to the next one
+		for _, reader := range readers {
+			reader.ReadToEnd(ctx)
+		}
+	}
 
 	// Close all files
 	for _, reader := range readers {
 		reader.Close()
 	}
+	f.scheduler.markRead(readers, time.Now())
 
 	f.saveCurrent(readers)
-	f.syncLastPollFiles(ctx)
+	if err := f.syncLastPollFiles(ctx); err != nil {
+		f.Errorw("Failed to sync to database", zap.Error(err))
+		return
+	}
+
+	// Files are only deleted or moved once their offsets have been saved, so that
+	// their content is not read again if the operator is restarted
+	if f.deleteAfterRead && f.deleteFinishedFiles(readers) {
+		// The fingerprints of deleted files are forgotten, so that a new file with the
+		// same content is read from the beginning, even after the operator is restarted
+		if err := f.syncLastPollFiles(ctx); err != nil {
+			f.Errorw("Failed to sync to database", zap.Error(err))
+		}
+	}
+	if f.onComplete != nil {
+		f.completeFinishedFiles(readers)
+	}
 }
 
 // getMatches gets a list of paths given an array of glob patterns to include and exclude
-func getMatches(includes, excludes []string) []string {
+func getMatches(includes, excludes []string, followSymlinks string) []string {
 	all := make([]string, 0, len(includes))
 	for _, include := range includes {
-		matches, _ := filepath.Glob(include) // compile error checked in build
 	INCLUDE:
-		for _, match := range matches {
+		for _, match := range globMatches(include, excludes, followSymlinks) {
 			for _, exclude := range excludes {
 				if itMatches, _ := doublestar.PathMatch(exclude, match); itMatches {
 					continue INCLUDE
@@ -183,7 +303,20 @@ func getMatches(includes, excludes []string) []string {
 		}
 	}
 
-	return all
+	return dedupSymlinkTargets(all)
+}
+
+// filterOlderThan returns the paths of files which were modified at or after the cutoff
+func filterOlderThan(paths []string, cutoff time.Time) []string {
+	recent := make([]string, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || info.ModTime().Before(cutoff) {
+			continue
+		}
+		recent = append(recent, path)
+	}
+	return recent
 }
 
 // makeReaders takes a list of paths, then creates readers from each of those paths,
@@ -196,13 +329,30 @@ func (f *InputOperator) makeReaders(filesPaths []string) []*Reader {
 		if _, ok := f.SeenPaths[path]; !ok {
 			if f.startAtBeginning {
 				f.Infow("Started watching file", "path", path)
+			} else if f.startPosition != nil {
+				f.Infow("Started watching file from the configured start_at position", "path", path)
 			} else {
 				f.Infow("Started watching file from end. To read preexisting logs, configure the argument 'start_at' to 'beginning'", "path", path)
 			}
 			f.SeenPaths[path] = struct{}{}
 		}
-		file, err := os.Open(path)
+		if f.isIdle(path) {
+			f.retainKnownReaders(path)
+			continue
+		}
+
+		file, err := openForReading(path, f.shareDelete)
 		if err != nil {
+			if isLocked(err) {
+				f.Debugw("File is locked by another process. Will retry during the next poll", "path", path)
+				f.retainKnownReaders(path)
+				continue
+			}
+			if f.networkFS && isStaleHandle(err) {
+				f.Debugw("Stale file handle. Will retry during the next poll", "path", path)
+				f.retainKnownReaders(path)
+				continue
+			}
 			f.Errorw("Failed to open file", zap.Error(err))
 			continue
 		}
@@ -211,20 +361,33 @@ func (f *InputOperator) makeReaders(filesPaths []string) []*Reader {
 
 	// Get fingerprints for each file
 	fps := make([]*Fingerprint, 0, len(files))
+	fpFiles := make([]*os.File, 0, len(files))
 	for _, file := range files {
 		fp, err := f.NewFingerprint(file)
 		if err != nil {
+			file.Close()
+			if f.networkFS && isStaleHandle(err) {
+				f.Debugw("Stale file handle. Will retry during the next poll", "path", file.Name())
+				f.retainKnownReaders(file.Name())
+				continue
+			}
 			f.Errorw("Failed creating fingerprint", zap.Error(err))
 			continue
 		}
 		fps = append(fps, fp)
+		fpFiles = append(fpFiles, file)
+	}
+	files = fpFiles
+
+	if f.includeContent != nil || f.excludeContent != nil {
+		files, fps = f.filterContent(files, fps)
 	}
 
 	// Exclude any empty fingerprints or duplicate fingerprints to avoid doubling up on copy-truncate files
 OUTER:
 	for i := 0; i < len(fps); {
 		fp := fps[i]
-		if len(fp.FirstBytes) == 0 {
+		if len(fp.FirstBytes) == 0 && len(fp.FileID) == 0 {
 			files[i].Close()
 			// Empty file, don't read it until we can compare its fingerprint
 			fps = append(fps[:i], fps[i+1:]...)
@@ -269,14 +432,23 @@ func (f *InputOperator) saveCurrent(readers []*Reader) {
 	// Add readers from the current, completed poll interval to the list of known files
 	f.knownFiles = append(f.knownFiles, readers...)
 
-	// Clear out old readers. They are sorted such that they are oldest first,
-	// so we can just find the first reader whose generation is less than our
-	// max, and keep every reader after that
-	for i := 0; i < len(f.knownFiles); i++ {
-		reader := f.knownFiles[i]
+	// Clear out old readers. Readers of locked files may be retained
+	// longer than others, so every reader's generation is checked
+	knownFiles := make([]*Reader, 0, len(f.knownFiles))
+	for _, reader := range f.knownFiles {
 		if reader.generation <= 3 {
-			f.knownFiles = f.knownFiles[i:]
-			break
+			knownFiles = append(knownFiles, reader)
+		}
+	}
+	f.knownFiles = knownFiles
+}
+
+// retainKnownReaders keeps the known readers of a file which is not read during
+// this poll, such as a file which is locked, so that its offset is not forgotten
+func (f *InputOperator) retainKnownReaders(path string) {
+	for _, reader := range f.knownFiles {
+		if reader.Path == path {
+			reader.generation = 0
 		}
 	}
 }
@@ -289,6 +461,7 @@ func (f *InputOperator) newReader(file *os.File, fp *Fingerprint, firstCheck boo
 			return nil, err
 		}
 		newReader.Path = file.Name()
+		newReader.Fingerprint.FileID = fp.FileID
 		return newReader, nil
 	}
 
@@ -312,37 +485,58 @@ func (f *InputOperator) findFingerprintMatch(fp *Fingerprint) (*Reader, bool) {
 			return oldReader, true
 		}
 	}
+
+	if f.networkFS {
+		if oldReader, ok := f.findPartialFingerprintMatch(fp); ok {
+			return oldReader, true
+		}
+	}
+
+	// A file which was copied during rotation has a new file ID, so
+	// it is matched by its content, as long as there is enough content
+	// to distinguish it from other files
+	if len(fp.FileID) == 0 || len(fp.FirstBytes) < minFingerprintSize {
+		return nil, false
+	}
+	content := &Fingerprint{FirstBytes: fp.FirstBytes}
+	for i := len(f.knownFiles) - 1; i >= 0; i-- {
+		oldReader := f.knownFiles[i]
+		if content.StartsWith(&Fingerprint{FirstBytes: oldReader.Fingerprint.FirstBytes}) {
+			return oldReader, true
+		}
+	}
 	return nil, false
 }
 
 const knownFilesKey = "knownFiles"
 
+// checkpointScopePrefix is prepended to the checkpoint namespace, so that
+// namespaced checkpoints do not collide with the scopes of operator IDs
+const checkpointScopePrefix = "$file_input."
+
 // syncLastPollFiles syncs the most recent set of files to the database
-func (f *InputOperator) syncLastPollFiles(ctx context.Context) {
+func (f *InputOperator) syncLastPollFiles(ctx context.Context) error {
 	var buf bytes.Buffer
 	enc := json.NewEncoder(&buf)
 
 	// Encode the number of known files
 	if err := enc.Encode(len(f.knownFiles)); err != nil {
-		f.Errorw("Failed to encode known files", zap.Error(err))
-		return
+		return fmt.Errorf("encode known files: %s", err)
 	}
 
 	// Encode each known file
 	for _, fileReader := range f.knownFiles {
 		if err := enc.Encode(fileReader); err != nil {
-			f.Errorw("Failed to encode known files", zap.Error(err))
+			return fmt.Errorf("encode known files: %s", err)
 		}
 	}
 
-	if err := f.persister.Set(ctx, knownFilesKey, buf.Bytes()); err != nil {
-		f.Errorw("Failed to sync to database", zap.Error(err))
-	}
+	return f.persister.Set(ctx, knownFilesKey, buf.Bytes())
 }
 
-// syncLastPollFiles loads the most recent set of files to the database
-func (f *InputOperator) loadLastPollFiles(ctx context.Context) error {
-	encoded, err := f.persister.Get(ctx, knownFilesKey)
+// loadLastPollFiles loads the most recent set of files from the database
+func (f *InputOperator) loadLastPollFiles(ctx context.Context, persister operator.Persister) error {
+	encoded, err := persister.Get(ctx, knownFilesKey)
 	if err != nil {
 		return err
 	}
@@ -169,8 +169,8 @@ func TestBuild(t *testing.T) {
 					LineStartPattern: "Exists",
 				}
 			},
-			require.Error,
-			nil,
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {},
 		},
 		{
 			"MultilineConfiguredStartPattern",
@@ -208,9 +208,44 @@ func TestBuild(t *testing.T) {
 					LineEndPattern:   ".*",
 				}
 			},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {},
+		},
+		{
+			"InvalidLineStartAndEndRegex",
+			func(f *InputConfig) {
+				f.Multiline = helper.MultilineConfig{
+					LineStartPattern: "START",
+					LineEndPattern:   "(",
+				}
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"MultilineNegativeMaxLines",
+			func(f *InputConfig) {
+				f.Multiline = helper.MultilineConfig{
+					LineStartPattern: "START",
+					MaxLines:         -1,
+				}
+			},
 			require.Error,
 			nil,
 		},
+		{
+			"MultilineForceFlushPeriod",
+			func(f *InputConfig) {
+				f.Multiline = helper.MultilineConfig{
+					LineStartPattern: "START",
+					ForceFlushPeriod: helper.NewDuration(time.Second),
+				}
+			},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {
+				require.Equal(t, time.Second, f.forceFlushPeriod)
+			},
+		},
 		{
 			"NoLineStartOrEnd",
 			func(f *InputConfig) {
@@ -239,6 +274,295 @@ func TestBuild(t *testing.T) {
 			require.Error,
 			nil,
 		},
+		{
+			"GzipCompression",
+			func(f *InputConfig) {
+				f.Compression = "gzip"
+			},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {
+				require.Equal(t, "gzip", f.compression)
+			},
+		},
+		{
+			"DeleteAfterReadStartAtEnd",
+			func(f *InputConfig) {
+				f.DeleteAfterRead = true
+				f.StartAt = "end"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"InvalidDeleteMode",
+			func(f *InputConfig) {
+				f.DeleteMode = "shred"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"HashFingerprintStrategy",
+			func(f *InputConfig) {
+				f.FingerprintStrategy = "hash"
+				f.FingerprintOffset = 4096
+			},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {
+				require.Equal(t, "hash", f.fingerprintStrategy)
+				require.Equal(t, int64(4096), f.fingerprintOffset)
+			},
+		},
+		{
+			"InodeFingerprintStrategy",
+			func(f *InputConfig) {
+				f.FingerprintStrategy = "inode"
+			},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {
+				require.Equal(t, "inode", f.fingerprintStrategy)
+			},
+		},
+		{
+			"InodeFingerprintStrategyWithOffset",
+			func(f *InputConfig) {
+				f.FingerprintStrategy = "inode"
+				f.FingerprintOffset = 4096
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"FingerprintOffsetWithoutHash",
+			func(f *InputConfig) {
+				f.FingerprintOffset = 4096
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"InvalidFingerprintStrategy",
+			func(f *InputConfig) {
+				f.FingerprintStrategy = "md5"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"SplitLongLines",
+			func(f *InputConfig) {
+				f.LongLineMode = "split"
+			},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {
+				require.True(t, f.splitLongLines)
+			},
+		},
+		{
+			"InvalidLongLineMode",
+			func(f *InputConfig) {
+				f.LongLineMode = "truncate"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"InvalidIncludeFileContentRegex",
+			func(f *InputConfig) {
+				f.IncludeFileContentRegex = "^{\"schema\":\"v2\"("
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"FileContentRegex",
+			func(f *InputConfig) {
+				f.IncludeFileContentRegex = "^{\"schema\":\"v2\""
+				f.ExcludeFileContentRegex = "\"test\":true"
+			},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {
+				require.Equal(t, "^{\"schema\":\"v2\"", f.includeContent.String())
+				require.Equal(t, "\"test\":true", f.excludeContent.String())
+			},
+		},
+		{
+			"InvalidFormat",
+			func(f *InputConfig) {
+				f.Format = "docker"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"CRIFormatWithMultiline",
+			func(f *InputConfig) {
+				f.Format = "cri"
+				f.Multiline.LineStartPattern = "^\\d"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"InvalidWatchMode",
+			func(f *InputConfig) {
+				f.WatchMode = "inotify"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"InvalidOrdering",
+			func(f *InputConfig) {
+				f.Ordering = "size"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"NegativeExcludeOlderThan",
+			func(f *InputConfig) {
+				f.ExcludeOlderThan = helper.NewDuration(-time.Hour)
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"InvalidFollowSymlinks",
+			func(f *InputConfig) {
+				f.FollowSymlinks = "sometimes"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"NegativeMaxBytesPerSec",
+			func(f *InputConfig) {
+				f.MaxBytesPerSec = -1
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"NegativeMaxLinesPerSec",
+			func(f *InputConfig) {
+				f.MaxLinesPerSec = -1
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"StartAtOffset",
+			func(f *InputConfig) {
+				f.StartAt = "1024"
+			},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {
+				require.Equal(t, int64(1024), f.startPosition.offset)
+			},
+		},
+		{
+			"StartAtNegativeOffset",
+			func(f *InputConfig) {
+				f.StartAt = "-1"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"StartAtTimestampMissingTimeConfig",
+			func(f *InputConfig) {
+				f.StartAt = "2021-06-01T10:00:00Z"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"StartAtTimestampMissingLayout",
+			func(f *InputConfig) {
+				f.StartAt = "2021-06-01T10:00:00Z"
+				f.StartAtTime = &StartAtTimeConfig{}
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"OnCompleteInvalidAction",
+			func(f *InputConfig) {
+				f.StartAt = "beginning"
+				f.OnComplete = &OnCompleteConfig{Action: "copy", Destination: "processed"}
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"OnCompleteMissingDestination",
+			func(f *InputConfig) {
+				f.StartAt = "beginning"
+				f.OnComplete = &OnCompleteConfig{Action: "move"}
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"OnCompleteWithDeleteAfterRead",
+			func(f *InputConfig) {
+				f.StartAt = "beginning"
+				f.DeleteAfterRead = true
+				f.OnComplete = &OnCompleteConfig{Action: "move", Destination: "processed"}
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"OnCompleteStartAtEnd",
+			func(f *InputConfig) {
+				f.StartAt = "end"
+				f.OnComplete = &OnCompleteConfig{Action: "move", Destination: "processed"}
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"MaxPollIntervalLessThanPollInterval",
+			func(f *InputConfig) {
+				f.MaxPollInterval = helper.NewDuration(time.Millisecond)
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"HeaderMissingPattern",
+			func(f *InputConfig) {
+				f.Header = &HeaderConfig{ParseRegex: "^#(?P<key>[^:]+): (?P<value>.*)$"}
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"HeaderNoNamedGroups",
+			func(f *InputConfig) {
+				f.Header = &HeaderConfig{Pattern: "^#", ParseRegex: "^#(.*)$"}
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"HeaderInvalidTarget",
+			func(f *InputConfig) {
+				f.Header = &HeaderConfig{Pattern: "^#", Target: "body"}
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"InvalidCompression",
+			func(f *InputConfig) {
+				f.Compression = "zstd"
+			},
+			require.Error,
+			nil,
+		},
 	}
 
 	for _, tc := range cases {
@@ -298,6 +622,32 @@ func TestAddFileFields(t *testing.T) {
 	require.Equal(t, temp.Name(), e.Attributes["file_path"])
 }
 
+// ExcludeOlderThan tests that files which have not been modified
+// recently are skipped, and are read once they are modified again
+func TestExcludeOlderThan(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.ExcludeOlderThan = helper.NewDuration(time.Hour)
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	oldFile := openTemp(t, tempDir)
+	writeString(t, oldFile, "testlog1\n")
+	oldTime := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(oldFile.Name(), oldTime, oldTime))
+
+	newFile := openTemp(t, tempDir)
+	writeString(t, newFile, "testlog2\n")
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog2")
+	expectNoMessages(t, logReceived)
+
+	writeString(t, oldFile, "testlog3\n")
+	operator.poll(context.Background())
+	waitForMessages(t, logReceived, []string{"testlog1", "testlog3"})
+}
+
 // ReadExistingLogs tests that, when starting from beginning, we
 // read all the lines that are already there
 func TestReadExistingLogs(t *testing.T) {
@@ -450,6 +800,29 @@ func TestSplitWrite(t *testing.T) {
 	waitForMessage(t, logReceived, "testlog1testlog2")
 }
 
+// ForceFlush tests that a partial multiline entry at the end of
+// the file is emitted once no new content has been written for
+// the force flush period
+func TestForceFlush(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Multiline = helper.MultilineConfig{
+			LineStartPattern: "^START",
+			ForceFlushPeriod: helper.NewDuration(200 * time.Millisecond),
+		}
+	}, nil)
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "START 1\nstack\nSTART 2\nstack\n")
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	waitForMessage(t, logReceived, "START 1\nstack\n")
+	expectNoMessagesUntil(t, logReceived, 100*time.Millisecond)
+	waitForMessage(t, logReceived, "START 2\nstack\n")
+}
+
 func TestDecodeBufferIsResized(t *testing.T) {
 	t.Parallel()
 	operator, logReceived, tempDir := newTestFileOperator(t, nil, nil)
@@ -904,6 +1277,220 @@ func TestTruncateThenWrite(t *testing.T) {
 	expectNoMessages(t, logReceived)
 }
 
+// TruncateThenWriteSamePrefix tests that when a file is truncated and then
+// rewritten with content that matches the original fingerprint, the new
+// content is read from the beginning of the file
+func TestTruncateThenWriteSamePrefix(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.FingerprintSize = helper.ByteSize(minFingerprintSize)
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp1 := openTemp(t, tempDir)
+	writeString(t, temp1, "header 0123456789 testlog1\nheader 0123456789 testlog2\n")
+
+	operator.poll(context.Background())
+	defer operator.Stop()
+
+	waitForMessage(t, logReceived, "header 0123456789 testlog1")
+	waitForMessage(t, logReceived, "header 0123456789 testlog2")
+
+	require.NoError(t, temp1.Truncate(0))
+	temp1.Seek(0, 0)
+
+	writeString(t, temp1, "header 0123456789 testlog3\n")
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "header 0123456789 testlog3")
+	expectNoMessages(t, logReceived)
+	require.Equal(t, uint64(1), operator.Truncated())
+	require.Equal(t, uint64(0), operator.Replaced())
+}
+
+// CheckTruncation tests that a file which is smaller than its offset is read from
+// the beginning, and is given a new identity only if its content was replaced
+func TestCheckTruncation(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name              string
+		content           string
+		expectedFirst     string
+		expectedTruncated uint64
+		expectedReplaced  uint64
+	}{
+		{"Truncated", "testlog1\n", "testlog1\n", 1, 0},
+		{"Replaced", "other\n", "other\n", 0, 1},
+		{"Empty", "", "testlog1\ntestlog2\n", 1, 0},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			operator, _, tempDir := newTestFileOperator(t, nil, nil)
+			temp := openTemp(t, tempDir)
+			writeString(t, temp, "testlog1\ntestlog2\n")
+
+			fp, err := operator.NewFingerprint(temp)
+			require.NoError(t, err)
+			reader, err := operator.NewReader(temp.Name(), temp, fp)
+			require.NoError(t, err)
+			reader.Offset = 18
+			reader.HeaderComplete = true
+
+			require.NoError(t, temp.Truncate(0))
+			_, err = temp.WriteAt([]byte(tc.content), 0)
+			require.NoError(t, err)
+
+			require.NoError(t, reader.checkTruncation())
+			require.Equal(t, int64(0), reader.Offset)
+			require.False(t, reader.HeaderComplete)
+			require.Equal(t, tc.expectedFirst, string(reader.Fingerprint.FirstBytes))
+			require.Equal(t, tc.expectedTruncated, operator.Truncated())
+			require.Equal(t, tc.expectedReplaced, operator.Replaced())
+		})
+	}
+}
+
+// HashFingerprintIdenticalHeaders tests that files which begin with
+// the same header are read separately when using the hash strategy
+func TestHashFingerprintIdenticalHeaders(t *testing.T) {
+	t.Parallel()
+	header := stringWithLength(100)
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.FingerprintSize = helper.ByteSize(minFingerprintSize)
+		cfg.FingerprintStrategy = "hash"
+		cfg.FingerprintOffset = helper.ByteSize(len(header) + 1)
+	}, nil)
+
+	temp1 := openTemp(t, tempDir)
+	writeString(t, temp1, header+"\ntestlog1 after the header\n")
+	temp2 := openTemp(t, tempDir)
+	writeString(t, temp2, header+"\ntestlog2 after the header\n")
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	waitForMessages(t, logReceived, []string{
+		header, "testlog1 after the header",
+		header, "testlog2 after the header",
+	})
+}
+
+// HashFingerprintGrowingFile tests that a file which is shorter than the hashed
+// range is read, and continues from its offset once it grows past the range
+func TestHashFingerprintGrowingFile(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.FingerprintSize = helper.ByteSize(minFingerprintSize)
+		cfg.FingerprintStrategy = "hash"
+		cfg.FingerprintOffset = helper.ByteSize(4)
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+
+	writeString(t, temp, "testlog2 grows past the hashed range\n")
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog2 grows past the hashed range")
+	expectNoMessages(t, logReceived)
+
+	writeString(t, temp, "testlog3\n")
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog3")
+	expectNoMessages(t, logReceived)
+	require.False(t, operator.knownFiles[len(operator.knownFiles)-1].Fingerprint.Partial)
+}
+
+// InodeFingerprintIdenticalContent tests that short files with
+// identical content are read separately when using the inode strategy
+func TestInodeFingerprintIdenticalContent(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.FingerprintStrategy = "inode"
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp1 := openTemp(t, tempDir)
+	writeString(t, temp1, "ok\n")
+	temp2 := openTemp(t, tempDir)
+	writeString(t, temp2, "ok\n")
+
+	operator.poll(context.Background())
+	defer operator.Stop()
+	waitForMessages(t, logReceived, []string{"ok", "ok"})
+
+	// Each file continues from its own offset
+	writeString(t, temp1, "testlog1\n")
+	writeString(t, temp2, "testlog2\n")
+	operator.poll(context.Background())
+	waitForMessages(t, logReceived, []string{"testlog1", "testlog2"})
+	expectNoMessages(t, logReceived)
+}
+
+// InodeFingerprintReplacedContent tests that a file whose content is
+// replaced is read from the beginning when using the inode strategy
+func TestInodeFingerprintReplacedContent(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.FingerprintStrategy = "inode"
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "heartbeat 1\n")
+
+	operator.poll(context.Background())
+	defer operator.Stop()
+	waitForMessage(t, logReceived, "heartbeat 1")
+
+	require.NoError(t, temp.Truncate(0))
+	_, err := temp.WriteAt([]byte("heartbeat 22\n"), 0)
+	require.NoError(t, err)
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "heartbeat 22")
+}
+
+// InodeFingerprintCopyTruncate tests that a copy of a file made
+// during rotation continues from the offset of the original file
+// when using the inode strategy
+func TestInodeFingerprintCopyTruncate(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.FingerprintStrategy = "inode"
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp1 := openTemp(t, tempDir)
+	writeString(t, temp1, "testlog1\ntestlog2\n")
+
+	operator.poll(context.Background())
+	defer operator.Stop()
+	waitForMessages(t, logReceived, []string{"testlog1", "testlog2"})
+	operator.wg.Wait()
+
+	temp2 := openTemp(t, tempDir)
+	_, err := temp1.Seek(0, 0)
+	require.NoError(t, err)
+	_, err = io.Copy(temp2, temp1)
+	require.NoError(t, err)
+	require.NoError(t, temp1.Truncate(0))
+	_, err = temp1.Seek(0, 0)
+	require.NoError(t, err)
+
+	writeString(t, temp2, "testlog3\n")
+	writeString(t, temp1, "testlog4\n")
+
+	operator.poll(context.Background())
+	waitForMessages(t, logReceived, []string{"testlog3", "testlog4"})
+	expectNoMessages(t, logReceived)
+}
+
 // CopyTruncateWriteBoth tests that when a file is copied
 // with unread logs on the end, then the original is truncated,
 // we get the unread logs on the copy as well as any new logs
@@ -966,6 +1553,58 @@ func TestOffsetsAfterRestart(t *testing.T) {
 	waitForMessage(t, logReceived, "testlog2")
 }
 
+// OffsetsAfterRename tests that offsets stored in a checkpoint
+// namespace are found after the operator's ID changes
+func TestOffsetsAfterRename(t *testing.T) {
+	t.Parallel()
+	database := testutil.NewUnscopedMockPersister()
+	oldPersister := operator.NewScopedPersister("old_id", database)
+	newPersister := operator.NewScopedPersister("new_id", database)
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.CheckpointNamespace = "app_logs"
+	}, nil)
+
+	temp1 := openTemp(t, tempDir)
+	writeString(t, temp1, "testlog1\n")
+
+	require.NoError(t, operator.Start(oldPersister))
+	defer operator.Stop()
+	waitForMessage(t, logReceived, "testlog1")
+
+	// Restart the operator with a different ID
+	require.NoError(t, operator.Stop())
+	require.NoError(t, operator.Start(newPersister))
+
+	// Write a new log and expect only that log
+	writeString(t, temp1, "testlog2\n")
+	waitForMessage(t, logReceived, "testlog2")
+	expectNoMessages(t, logReceived)
+}
+
+// OffsetsAfterAddingNamespace tests that offsets stored under the operator ID
+// are used when a checkpoint namespace is configured for the first time
+func TestOffsetsAfterAddingNamespace(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, nil, nil)
+	persister := testutil.NewMockPersister("test")
+
+	temp1 := openTemp(t, tempDir)
+	writeString(t, temp1, "testlog1\n")
+
+	require.NoError(t, operator.Start(persister))
+	defer operator.Stop()
+	waitForMessage(t, logReceived, "testlog1")
+
+	// Restart the operator with a checkpoint namespace
+	require.NoError(t, operator.Stop())
+	operator.checkpointNamespace = "app_logs"
+	require.NoError(t, operator.Start(persister))
+
+	writeString(t, temp1, "testlog2\n")
+	waitForMessage(t, logReceived, "testlog2")
+	expectNoMessages(t, logReceived)
+}
+
 func TestOffsetsAfterRestart_BigFiles(t *testing.T) {
 	t.Parallel()
 	operator, logReceived, tempDir := newTestFileOperator(t, nil, nil)
@@ -1378,7 +2017,7 @@ func TestExclude(t *testing.T) {
 	includes := []string{filepath.Join(tempDir, "*")}
 	excludes := []string{filepath.Join(tempDir, "*exclude.log")}
 
-	matches := getMatches(includes, excludes)
+	matches := getMatches(includes, excludes, followSymlinksAll)
 	require.ElementsMatch(t, matches, paths[:1])
 }
 func TestExcludeEmpty(t *testing.T) {
@@ -1388,7 +2027,7 @@ func TestExcludeEmpty(t *testing.T) {
 	includes := []string{filepath.Join(tempDir, "*")}
 	excludes := []string{}
 
-	matches := getMatches(includes, excludes)
+	matches := getMatches(includes, excludes, followSymlinksAll)
 	require.ElementsMatch(t, matches, paths)
 }
 func TestExcludeMany(t *testing.T) {
@@ -1398,7 +2037,7 @@ func TestExcludeMany(t *testing.T) {
 	includes := []string{filepath.Join(tempDir, "*")}
 	excludes := []string{filepath.Join(tempDir, "a*.log"), filepath.Join(tempDir, "*2.log")}
 
-	matches := getMatches(includes, excludes)
+	matches := getMatches(includes, excludes, followSymlinksAll)
 	require.ElementsMatch(t, matches, paths[2:3])
 }
 func TestExcludeDuplicates(t *testing.T) {
@@ -1408,10 +2047,62 @@ func TestExcludeDuplicates(t *testing.T) {
 	includes := []string{filepath.Join(tempDir, "*1*"), filepath.Join(tempDir, "a*")}
 	excludes := []string{filepath.Join(tempDir, "a*.log"), filepath.Join(tempDir, "*2.log")}
 
-	matches := getMatches(includes, excludes)
+	matches := getMatches(includes, excludes, followSymlinksAll)
 	require.ElementsMatch(t, matches, paths[2:3])
 }
 
+func TestRecursiveGlob(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "a", "b", "c"), 0755))
+	paths := writeTempFiles(tempDir, []string{
+		"root.log",
+		filepath.Join("a", "a.log"),
+		filepath.Join("a", "b", "c", "c.log"),
+		filepath.Join("a", "b", "c", "c.txt"),
+	})
+
+	includes := []string{filepath.Join(tempDir, "**", "*.log")}
+	excludes := []string{}
+
+	matches := getMatches(includes, excludes, followSymlinksAll)
+	require.ElementsMatch(t, matches, paths[:3])
+}
+
+func TestRecursiveGlobExcludeSubtree(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "a", "b", "c"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "d"), 0755))
+	paths := writeTempFiles(tempDir, []string{
+		filepath.Join("a", "a.log"),
+		filepath.Join("a", "b", "b.log"),
+		filepath.Join("a", "b", "c", "c.log"),
+		filepath.Join("d", "d.log"),
+	})
+
+	includes := []string{filepath.Join(tempDir, "**", "*.log")}
+	excludes := []string{filepath.Join(tempDir, "a", "b", "**"), filepath.Join(tempDir, "d", "*.log")}
+
+	matches := getMatches(includes, excludes, followSymlinksAll)
+	require.ElementsMatch(t, matches, paths[:1])
+}
+
+func TestRecursiveGlobMiddle(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "team1", "service1"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "team2", "service2", "logs"), 0755))
+	paths := writeTempFiles(tempDir, []string{
+		filepath.Join("team1", "service1", "app.log"),
+		filepath.Join("team2", "service2", "logs", "app.log"),
+		filepath.Join("team2", "other.log"),
+	})
+
+	includes := []string{filepath.Join(tempDir, "**", "app.log")}
+	excludes := []string{}
+
+	matches := getMatches(includes, excludes, followSymlinksAll)
+	require.ElementsMatch(t, matches, paths[:2])
+}
+
 // writes file with the specified file names and returns their full paths in order
 func writeTempFiles(tempDir string, names []string) []string {
 	result := make([]string, 0, len(names))
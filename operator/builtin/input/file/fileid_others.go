@@ -0,0 +1,45 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package file
+
+import (
+	"encoding/binary"
+	"os"
+	"syscall"
+)
+
+// deviceIDSize is the number of leading bytes of a file ID which
+// identify the device rather than the file itself
+const deviceIDSize = 8
+
+// fileID returns the device and inode of the file. It returns
+// false if the platform does not provide them.
+func fileID(file *os.File) ([]byte, bool) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil, false
+	}
+
+	id := make([]byte, 16)
+	binary.BigEndian.PutUint64(id[:8], uint64(stat.Dev))
+	binary.BigEndian.PutUint64(id[8:], uint64(stat.Ino))
+	return id, true
+}
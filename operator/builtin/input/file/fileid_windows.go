@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build windows
+
+package file
+
+import (
+	"encoding/binary"
+	"os"
+	"syscall"
+)
+
+// deviceIDSize is the number of leading bytes of a file ID which
+// identify the volume serial number rather than the file itself
+const deviceIDSize = 4
+
+// fileID returns the volume serial number and file index of the file,
+// which are the Windows equivalent of a device and inode
+func fileID(file *os.File) ([]byte, bool) {
+	var data syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(syscall.Handle(file.Fd()), &data); err != nil {
+		return nil, false
+	}
+
+	id := make([]byte, 12)
+	binary.BigEndian.PutUint32(id[:4], data.VolumeSerialNumber)
+	binary.BigEndian.PutUint32(id[4:8], data.FileIndexHigh)
+	binary.BigEndian.PutUint32(id[8:], data.FileIndexLow)
+	return id, true
+}
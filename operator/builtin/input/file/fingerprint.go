@@ -16,44 +16,130 @@ package file
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 )
 
 const defaultFingerprintSize = 1000 // bytes
 const minFingerprintSize = 16       // bytes
 
+const (
+	fingerprintStrategyFirstBytes = "first_bytes"
+	fingerprintStrategyHash       = "hash"
+	fingerprintStrategyInode      = "inode"
+)
+
 // Fingerprint is used to identify a file
 // A file's fingerprint is the first N bytes of the file,
-// where N is the fingerprintSize on the file_input operator
+// where N is the fingerprintSize on the file_input operator.
+// When using the hash strategy, the fingerprint is instead a hash
+// of the N bytes which follow the configured fingerprint offset. Until
+// the file contains all of those bytes, the fingerprint is partial, and
+// is the bytes of the file up to the end of the hashed range.
+// When using the inode strategy, the fingerprint also includes the
+// device and inode of the file, if the platform supports them.
 type Fingerprint struct {
 	FirstBytes []byte
+	FileID     []byte `json:",omitempty"`
+	Partial    bool   `json:",omitempty"`
+
+	// raw is the content from which a hash fingerprint was created, so that it
+	// can be compared to partial fingerprints created before the file was complete
+	raw []byte
 }
 
-// NewFingerprint creates a new fingerprint from an open file
+// NewFingerprint creates a new fingerprint from an open file.
+// Compressed files are fingerprinted using their decompressed content.
 func (f *InputOperator) NewFingerprint(file *os.File) (*Fingerprint, error) {
-	buf := make([]byte, f.fingerprintSize)
+	if f.fingerprintStrategy == fingerprintStrategyHash {
+		return f.newHashFingerprint(file)
+	}
 
-	n, err := file.ReadAt(buf, 0)
+	buf := make([]byte, f.fingerprintSize)
+	n, err := f.readFingerprintBytes(file, buf, 0)
 	if err != nil && err != io.EOF {
-		return nil, fmt.Errorf("reading fingerprint bytes: %s", err)
+		return nil, fmt.Errorf("reading fingerprint bytes: %w", err)
 	}
 
 	fp := &Fingerprint{
 		FirstBytes: buf[:n],
 	}
 
+	// Platforms without file IDs fall back to the first bytes of the file
+	if f.fingerprintStrategy == fingerprintStrategyInode {
+		if id, ok := fileID(file); ok {
+			// Device numbers are not stable across remounts of a network filesystem
+			if f.networkFS {
+				id = id[deviceIDSize:]
+			}
+			fp.FileID = id
+		}
+	}
+
 	return fp, nil
 }
 
+// newHashFingerprint creates a fingerprint from a hash of the fingerprintSize
+// bytes which follow the fingerprintOffset. Files which are not yet large enough
+// to contain the entire range have a partial fingerprint of the bytes they contain.
+func (f *InputOperator) newHashFingerprint(file *os.File) (*Fingerprint, error) {
+	buf := make([]byte, f.fingerprintOffset+int64(f.fingerprintSize))
+	n, err := f.readFingerprintBytes(file, buf, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("reading fingerprint bytes: %w", err)
+	}
+
+	if n < len(buf) {
+		return &Fingerprint{FirstBytes: buf[:n], Partial: true}, nil
+	}
+
+	sum := sha256.Sum256(buf[f.fingerprintOffset:])
+	return &Fingerprint{FirstBytes: sum[:], raw: buf}, nil
+}
+
+// readFingerprintBytes reads into buf starting at the given offset, without
+// modifying the current position of the file
+func (f *InputOperator) readFingerprintBytes(file *os.File, buf []byte, offset int64) (int, error) {
+	compression := f.compressionOf(file)
+	if compression == compressionNone {
+		return file.ReadAt(buf, offset)
+	}
+
+	r, err := newDecompressor(file, compression)
+	if err != nil && compression == compressionZip {
+		// A zip archive can not be opened until it has been completely written,
+		// so it is treated as empty until then
+		return 0, io.EOF
+	}
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.CopyN(ioutil.Discard, r, offset); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(r, buf)
+	if err == io.ErrUnexpectedEOF {
+		return n, io.EOF
+	}
+	return n, err
+}
+
 // Copy creates a new copy of the fingerprint
 func (f Fingerprint) Copy() *Fingerprint {
 	buf := make([]byte, len(f.FirstBytes), cap(f.FirstBytes))
 	n := copy(buf, f.FirstBytes)
-	return &Fingerprint{
+	fp := &Fingerprint{
 		FirstBytes: buf[:n],
 	}
+	if f.FileID != nil {
+		fp.FileID = append([]byte{}, f.FileID...)
+	}
+	fp.Partial = f.Partial
+	fp.raw = f.raw
+	return fp
 }
 
 // StartsWith returns true if the fingerprints are the same
@@ -61,15 +147,34 @@ func (f Fingerprint) Copy() *Fingerprint {
 // This is important functionality for tracking new files,
 // since their initial size is typically less than that of
 // a fingerprint. As the file grows, its fingerprint is updated
-// until it reaches a maximum size, as configured on the operator
+// until it reaches a maximum size, as configured on the operator.
+// Fingerprints with file IDs must have the same ID, and content where
+// either starts with the other, since the ID may be reused by a new file.
+// A partial hash fingerprint is compared to the content of a complete one.
 func (f Fingerprint) StartsWith(old *Fingerprint) bool {
+	if len(f.FileID) > 0 && len(old.FileID) > 0 {
+		if !bytes.Equal(f.FileID, old.FileID) {
+			return false
+		}
+		return bytes.HasPrefix(f.FirstBytes, old.FirstBytes) || bytes.HasPrefix(old.FirstBytes, f.FirstBytes)
+	}
+
+	content := f.FirstBytes
+	if old.Partial != f.Partial {
+		// A partial fingerprint can not start with a complete one, since the file would have shrunk
+		if f.Partial {
+			return false
+		}
+		content = f.raw
+	}
+
 	l0 := len(old.FirstBytes)
 	if l0 == 0 {
 		return false
 	}
-	l1 := len(f.FirstBytes)
+	l1 := len(content)
 	if l0 > l1 {
 		return false
 	}
-	return bytes.Equal(old.FirstBytes[:l0], f.FirstBytes[:l0])
+	return bytes.Equal(old.FirstBytes[:l0], content[:l0])
 }
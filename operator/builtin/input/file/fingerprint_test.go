@@ -15,6 +15,7 @@
 package file
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
@@ -22,6 +23,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 )
 
 func TestNewFingerprintDoesNotModifyOffset(t *testing.T) {
@@ -141,6 +144,123 @@ func TestNewFingerprint(t *testing.T) {
 	}
 }
 
+func TestNewHashFingerprint(t *testing.T) {
+	cases := []struct {
+		name        string
+		offset      int64
+		size        int
+		fileSize    int
+		expectedLen int
+		partial     bool
+	}{
+		{
+			name:        "fileSmallerThanOffset",
+			offset:      100,
+			size:        minFingerprintSize,
+			fileSize:    50,
+			expectedLen: 50,
+			partial:     true,
+		},
+		{
+			name:        "fileSmallerThanRange",
+			offset:      100,
+			size:        minFingerprintSize,
+			fileSize:    100 + minFingerprintSize - 1,
+			expectedLen: 100 + minFingerprintSize - 1,
+			partial:     true,
+		},
+		{
+			name:        "fileExactRange",
+			offset:      100,
+			size:        minFingerprintSize,
+			fileSize:    100 + minFingerprintSize,
+			expectedLen: sha256.Size,
+		},
+		{
+			name:        "noOffset",
+			offset:      0,
+			size:        defaultFingerprintSize,
+			fileSize:    defaultFingerprintSize * 2,
+			expectedLen: sha256.Size,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			f, _, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+				cfg.FingerprintStrategy = "hash"
+				cfg.FingerprintOffset = helper.ByteSize(tc.offset)
+				cfg.FingerprintSize = helper.ByteSize(tc.size)
+			}, nil)
+
+			temp := openTemp(t, tempDir)
+			writeString(t, temp, stringWithLength(tc.fileSize))
+
+			fp, err := f.NewFingerprint(temp)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedLen, len(fp.FirstBytes))
+			require.Equal(t, tc.partial, fp.Partial)
+		})
+	}
+}
+
+func TestHashFingerprintPartialStartsWith(t *testing.T) {
+	t.Parallel()
+	f, _, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.FingerprintStrategy = "hash"
+		cfg.FingerprintOffset = helper.ByteSize(10)
+		cfg.FingerprintSize = helper.ByteSize(minFingerprintSize)
+	}, nil)
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "0123456789abc")
+	partial, err := f.NewFingerprint(temp)
+	require.NoError(t, err)
+	require.True(t, partial.Partial)
+
+	writeString(t, temp, "defghijklmnopqrstuvwxyz")
+	complete, err := f.NewFingerprint(temp)
+	require.NoError(t, err)
+	require.False(t, complete.Partial)
+
+	// The complete fingerprint is compared to the content of the partial one
+	require.True(t, complete.StartsWith(partial))
+	require.True(t, complete.Copy().StartsWith(partial))
+	require.False(t, partial.StartsWith(complete))
+
+	other := openTemp(t, tempDir)
+	writeString(t, other, "0123456789xyz")
+	otherPartial, err := f.NewFingerprint(other)
+	require.NoError(t, err)
+	require.False(t, complete.StartsWith(otherPartial))
+}
+
+func TestNewHashFingerprintIdenticalHeaders(t *testing.T) {
+	t.Parallel()
+	header := stringWithLength(2000)
+
+	f, _, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.FingerprintStrategy = "hash"
+		cfg.FingerprintOffset = helper.ByteSize(len(header))
+		cfg.FingerprintSize = helper.ByteSize(minFingerprintSize)
+	}, nil)
+
+	temp1 := openTemp(t, tempDir)
+	writeString(t, temp1, header+stringWithLength(minFingerprintSize))
+	temp2 := openTemp(t, tempDir)
+	writeString(t, temp2, header+stringWithLength(minFingerprintSize))
+
+	fp1, err := f.NewFingerprint(temp1)
+	require.NoError(t, err)
+	fp2, err := f.NewFingerprint(temp2)
+	require.NoError(t, err)
+
+	require.False(t, fp1.StartsWith(fp2))
+	require.False(t, fp2.StartsWith(fp1))
+}
+
 func TestFingerprintCopy(t *testing.T) {
 	t.Parallel()
 	cases := []string{
@@ -221,6 +341,85 @@ func TestFingerprintStartsWith(t *testing.T) {
 // The static file can be thought of as the present state of
 // the file, while each iteration of the growing file represents
 // a possible state of the same file at a previous time.
+func TestFingerprintStartsWithFileID(t *testing.T) {
+	cases := []struct {
+		name     string
+		a        *Fingerprint
+		b        *Fingerprint
+		expected bool
+	}{
+		{
+			name:     "sameIDSameContent",
+			a:        &Fingerprint{FileID: []byte{1}, FirstBytes: []byte("hello")},
+			b:        &Fingerprint{FileID: []byte{1}, FirstBytes: []byte("hello")},
+			expected: true,
+		},
+		{
+			name:     "sameIDEmpty",
+			a:        &Fingerprint{FileID: []byte{1}, FirstBytes: []byte{}},
+			b:        &Fingerprint{FileID: []byte{1}, FirstBytes: []byte{}},
+			expected: true,
+		},
+		{
+			name:     "sameIDGrowingContent",
+			a:        &Fingerprint{FileID: []byte{1}, FirstBytes: []byte("hello")},
+			b:        &Fingerprint{FileID: []byte{1}, FirstBytes: []byte("helloworld")},
+			expected: true,
+		},
+		{
+			name:     "sameIDDifferentContent",
+			a:        &Fingerprint{FileID: []byte{1}, FirstBytes: []byte("hello")},
+			b:        &Fingerprint{FileID: []byte{1}, FirstBytes: []byte("world")},
+			expected: false,
+		},
+		{
+			name:     "differentIDSameContent",
+			a:        &Fingerprint{FileID: []byte{1}, FirstBytes: []byte("hello")},
+			b:        &Fingerprint{FileID: []byte{2}, FirstBytes: []byte("hello")},
+			expected: false,
+		},
+		{
+			name:     "oneWithoutID",
+			a:        &Fingerprint{FirstBytes: []byte("hello")},
+			b:        &Fingerprint{FileID: []byte{2}, FirstBytes: []byte("hello")},
+			expected: true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.expected, tc.a.StartsWith(tc.b))
+			require.Equal(t, tc.expected, tc.b.StartsWith(tc.a))
+		})
+	}
+}
+
+func TestNewInodeFingerprint(t *testing.T) {
+	t.Parallel()
+	f, _, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.FingerprintStrategy = "inode"
+	}, nil)
+
+	temp1 := openTemp(t, tempDir)
+	temp2 := openTemp(t, tempDir)
+
+	fp1, err := f.NewFingerprint(temp1)
+	require.NoError(t, err)
+	fp2, err := f.NewFingerprint(temp2)
+	require.NoError(t, err)
+	require.NotEmpty(t, fp1.FileID)
+	require.NotEqual(t, fp1.FileID, fp2.FileID)
+
+	// The same file has the same ID
+	reopened := openFile(t, temp1.Name())
+	fp3, err := f.NewFingerprint(reopened)
+	require.NoError(t, err)
+	require.Equal(t, fp1.FileID, fp3.FileID)
+	require.Equal(t, fp1.FileID, fp1.Copy().FileID)
+}
+
 func TestFingerprintStartsWith_FromFile(t *testing.T) {
 	r := rand.New(rand.NewSource(112358))
 
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v3"
+)
+
+// globMatches returns the paths of the files which match the pattern.
+// Patterns which contain `**` are matched by walking the directory tree
+// below the static part of the pattern, skipping excluded directories.
+// Symlinked directories are only walked if allowed by the symlink policy.
+func globMatches(include string, excludes []string, followSymlinks string) []string {
+	if !strings.Contains(include, "**") {
+		matches, _ := filepath.Glob(include) // compile error checked in build
+		return filterSymlinks(include, matches, followSymlinks)
+	}
+
+	pattern := filepath.Clean(include)
+	root, _ := watchRoot(pattern)
+
+	w := &globWalker{
+		pattern:    pattern,
+		excludes:   excludes,
+		followDirs: followsSymlinkedDirs(followSymlinks),
+		visited:    make(map[string]struct{}),
+		matches:    make([]string, 0),
+	}
+	w.walk(root)
+	return filterSymlinks(include, w.matches, followSymlinks)
+}
+
+// globWalker finds the files below a directory which match a pattern
+type globWalker struct {
+	pattern    string
+	excludes   []string
+	followDirs bool
+	visited    map[string]struct{}
+	matches    []string
+}
+
+func (w *globWalker) walk(dir string) {
+	// Avoid walking a directory more than once when symlinks form a loop
+	if target, err := filepath.EvalSymlinks(dir); err == nil {
+		if _, ok := w.visited[target]; ok {
+			return
+		}
+		w.visited[target] = struct{}{}
+	}
+
+	// Skip directories that can not be read
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, info := range infos {
+		path := filepath.Join(dir, info.Name())
+		if info.Mode()&os.ModeSymlink != 0 {
+			if info, err = os.Stat(path); err != nil {
+				continue
+			}
+			if info.IsDir() && !w.followDirs {
+				continue
+			}
+		}
+
+		if info.IsDir() {
+			if !isExcludedDir(path, w.excludes) {
+				w.walk(path)
+			}
+			continue
+		}
+
+		if ok, _ := doublestar.PathMatch(w.pattern, path); ok {
+			w.matches = append(w.matches, path)
+		}
+	}
+}
+
+// isExcludedDir returns true if every file in the directory would be excluded
+func isExcludedDir(dir string, excludes []string) bool {
+	for _, exclude := range excludes {
+		for _, suffix := range []string{"/**", string(filepath.Separator) + "**"} {
+			if !strings.HasSuffix(exclude, suffix) {
+				continue
+			}
+			if ok, _ := doublestar.PathMatch(strings.TrimSuffix(exclude, suffix), dir); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+)
+
+const (
+	headerTargetAttributes = "attributes"
+	headerTargetResource   = "resource"
+)
+
+// defaultHeaderKey is the key used for header lines when no parse_regex is configured
+const defaultHeaderKey = "header"
+
+// HeaderConfig is the configuration of the header lines at the beginning of a file
+type HeaderConfig struct {
+	Pattern    string `mapstructure:"pattern"               json:"pattern"               yaml:"pattern"`
+	ParseRegex string `mapstructure:"parse_regex,omitempty" json:"parse_regex,omitempty" yaml:"parse_regex,omitempty"`
+	Target     string `mapstructure:"target,omitempty"      json:"target,omitempty"      yaml:"target,omitempty"`
+	LineCount  int    `mapstructure:"line_count,omitempty"  json:"line_count,omitempty"  yaml:"line_count,omitempty"`
+}
+
+// Build will build a header parser from the supplied configuration
+func (c HeaderConfig) Build() (*headerParser, error) {
+	if c.LineCount < 0 {
+		return nil, fmt.Errorf("invalid value for `header.line_count`, must not be negative")
+	}
+
+	if c.Pattern == "" && c.LineCount == 0 {
+		return nil, fmt.Errorf("missing required field `header.pattern` or `header.line_count`")
+	}
+
+	h := &headerParser{lineCount: c.LineCount}
+
+	var err error
+	if c.Pattern != "" {
+		h.pattern, err = regexp.Compile(c.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compiling header pattern: %s", err)
+		}
+	}
+
+	if c.ParseRegex != "" {
+		h.parseRegex, err = regexp.Compile(c.ParseRegex)
+		if err != nil {
+			return nil, fmt.Errorf("compiling header parse_regex: %s", err)
+		}
+
+		namedCaptureGroups := 0
+		for _, groupName := range h.parseRegex.SubexpNames() {
+			if groupName != "" {
+				namedCaptureGroups++
+			}
+		}
+		if namedCaptureGroups == 0 {
+			return nil, fmt.Errorf("no named capture groups in header parse_regex")
+		}
+	}
+
+	switch c.Target {
+	case "", headerTargetAttributes:
+	case headerTargetResource:
+		h.toResource = true
+	default:
+		return nil, fmt.Errorf("invalid header target '%s'", c.Target)
+	}
+
+	return h, nil
+}
+
+// headerParser identifies and parses the header lines at the beginning of a file
+type headerParser struct {
+	pattern    *regexp.Regexp
+	parseRegex *regexp.Regexp
+	toResource bool
+	lineCount  int
+}
+
+// isHeader returns true if the line is part of the header, given the number of header lines before it
+func (h *headerParser) isHeader(line string, index int) bool {
+	if h.lineCount > 0 && index >= h.lineCount {
+		return false
+	}
+	return h.pattern == nil || h.pattern.MatchString(line)
+}
+
+// parse adds the values parsed from a header line to values. If the parse regex
+// contains `key` and `value` capture groups, they are used as a key value pair.
+// Otherwise, each named capture group is used as a key.
+func (h *headerParser) parse(line string, values map[string]string) {
+	if h.parseRegex == nil {
+		if existing, ok := values[defaultHeaderKey]; ok {
+			values[defaultHeaderKey] = existing + "\n" + line
+		} else {
+			values[defaultHeaderKey] = line
+		}
+		return
+	}
+
+	matches := h.parseRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return
+	}
+
+	groups := make(map[string]string, len(matches))
+	for i, groupName := range h.parseRegex.SubexpNames() {
+		if i == 0 || groupName == "" {
+			continue
+		}
+		groups[groupName] = matches[i]
+	}
+
+	key, hasKey := groups["key"]
+	value, hasValue := groups["value"]
+	if hasKey && hasValue {
+		values[key] = value
+		return
+	}
+
+	for groupName, value := range groups {
+		values[groupName] = value
+	}
+}
+
+// apply adds the header values to the entry
+func (h *headerParser) apply(e *entry.Entry, values map[string]string) {
+	for key, value := range values {
+		if h.toResource {
+			e.AddResourceKey(key, value)
+		} else {
+			e.AddAttribute(key, value)
+		}
+	}
+}
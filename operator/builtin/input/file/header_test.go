@@ -0,0 +1,200 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestHeaderParser(t *testing.T) {
+	cases := []struct {
+		name     string
+		cfg      HeaderConfig
+		lines    []string
+		expected map[string]string
+	}{
+		{
+			"NoParseRegex",
+			HeaderConfig{Pattern: "^#"},
+			[]string{"#Version: 1.0", "#Fields: date time"},
+			map[string]string{"header": "#Version: 1.0\n#Fields: date time"},
+		},
+		{
+			"KeyValue",
+			HeaderConfig{Pattern: "^#", ParseRegex: "^#(?P<key>[^:]+): (?P<value>.*)$"},
+			[]string{"#Version: 1.0", "#Fields: date time"},
+			map[string]string{"Version": "1.0", "Fields": "date time"},
+		},
+		{
+			"NamedGroups",
+			HeaderConfig{Pattern: "^#", ParseRegex: "^#host=(?P<host>\\S+) app=(?P<app>\\S+)$"},
+			[]string{"#host=web1 app=nginx"},
+			map[string]string{"host": "web1", "app": "nginx"},
+		},
+		{
+			"LineCount",
+			HeaderConfig{LineCount: 1},
+			[]string{"id,severity,message"},
+			map[string]string{"header": "id,severity,message"},
+		},
+		{
+			"NoMatch",
+			HeaderConfig{Pattern: "^#", ParseRegex: "^#(?P<key>[^:]+): (?P<value>.*)$"},
+			[]string{"# comment"},
+			map[string]string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := tc.cfg.Build()
+			require.NoError(t, err)
+
+			values := map[string]string{}
+			for i, line := range tc.lines {
+				require.True(t, h.isHeader(line, i))
+				h.parse(line, values)
+			}
+			require.Equal(t, tc.expected, values)
+		})
+	}
+}
+
+func TestHeaderAttributes(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Header = &HeaderConfig{
+			Pattern:    "^#",
+			ParseRegex: "^#(?P<key>[^:]+): (?P<value>.*)$",
+		}
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "#Version: 1.0\n#Fields: date time\ntestlog1\n")
+
+	operator.poll(context.Background())
+	e := waitForOne(t, logReceived)
+	require.Equal(t, "testlog1", e.Body)
+	require.Equal(t, "1.0", e.Attributes["Version"])
+	require.Equal(t, "date time", e.Attributes["Fields"])
+
+	// Header values are attached to entries read in later polls
+	writeString(t, temp, "testlog2\n")
+	operator.poll(context.Background())
+	e = waitForOne(t, logReceived)
+	require.Equal(t, "testlog2", e.Body)
+	require.Equal(t, "1.0", e.Attributes["Version"])
+	expectNoMessages(t, logReceived)
+}
+
+func TestHeaderResource(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Header = &HeaderConfig{Pattern: "^#", Target: "resource"}
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "#Version: 1.0\ntestlog1\n")
+
+	operator.poll(context.Background())
+	e := waitForOne(t, logReceived)
+	require.Equal(t, "testlog1", e.Body)
+	require.Equal(t, "#Version: 1.0", e.Resource["header"])
+}
+
+// HeaderLinesAfterHeader tests that lines matching the header pattern
+// are emitted as entries once the header has ended
+func TestHeaderLinesAfterHeader(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Header = &HeaderConfig{Pattern: "^#"}
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "#header\ntestlog1\n#testlog2\n")
+
+	operator.poll(context.Background())
+	waitForMessages(t, logReceived, []string{"testlog1", "#testlog2"})
+}
+
+func TestHeaderStartAtEnd(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.StartAt = "end"
+		cfg.Header = &HeaderConfig{Pattern: "^#"}
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "#header\ntestlog1\n")
+
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+
+	writeString(t, temp, "testlog2\n")
+	operator.poll(context.Background())
+	e := waitForOne(t, logReceived)
+	require.Equal(t, "testlog2", e.Body)
+	require.Equal(t, "#header", e.Attributes["header"])
+}
+
+func TestHeaderConfigBuild(t *testing.T) {
+	_, err := HeaderConfig{}.Build()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing required field `header.pattern` or `header.line_count`")
+
+	_, err = HeaderConfig{LineCount: -1}.Build()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid value for `header.line_count`")
+
+	h, err := HeaderConfig{Pattern: "^#", LineCount: 2}.Build()
+	require.NoError(t, err)
+	require.True(t, h.isHeader("#first", 0))
+	require.False(t, h.isHeader("second", 1))
+	require.False(t, h.isHeader("#third", 2))
+}
+
+// HeaderLineCount tests that the first lines of a file are the header when
+// line_count is configured, such as the column names of a CSV file
+func TestHeaderLineCount(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Header = &HeaderConfig{LineCount: 1}
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "id,severity,message\n1,info,started\n")
+
+	operator.poll(context.Background())
+	e := waitForOne(t, logReceived)
+	require.Equal(t, "1,info,started", e.Body)
+	require.Equal(t, "id,severity,message", e.Attributes["header"])
+
+	writeString(t, temp, "2,error,failed\n")
+	operator.poll(context.Background())
+	e = waitForOne(t, logReceived)
+	require.Equal(t, "2,error,failed", e.Body)
+	require.Equal(t, "id,severity,message", e.Attributes["header"])
+	expectNoMessages(t, logReceived)
+}
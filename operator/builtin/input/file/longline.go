@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bufio"
+	"context"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+const (
+	longLineModeError = "error"
+	longLineModeSplit = "split"
+)
+
+const (
+	chunkIndexAttribute = "log.chunk.index"
+	chunkTotalAttribute = "log.chunk.total"
+)
+
+// readLongEntry emits the entry at the offset, which is longer than max_log_size, as
+// a series of chunks. The file is read twice, first to count the chunks so that each
+// chunk can be labeled with the total, and then to emit them, so that the entry is
+// never held in memory. It returns false if the entry could not be read completely.
+func (f *Reader) readLongEntry(ctx context.Context) bool {
+	total, ok, err := f.countChunks()
+	if err != nil {
+		f.Errorw("Failed to read long entry", zap.Error(err))
+		return false
+	}
+	if !ok {
+		// The rest of the entry has not been written yet
+		return false
+	}
+
+	src, err := f.openAt(f.Offset)
+	if err != nil {
+		f.Errorw("Failed to seek", zap.Error(err))
+		return false
+	}
+
+	var chunked bool
+	splitFunc := chunkSplitFunc(f.splitFunc, f.fileInput.MaxLogSize, &chunked)
+	scanner := NewPositionalScanner(src, f.fileInput.MaxLogSize, f.Offset, splitFunc)
+	for i := 0; i < total && scanner.Scan(); i++ {
+		attributes := map[string]string{
+			chunkIndexAttribute: strconv.Itoa(i),
+			chunkTotalAttribute: strconv.Itoa(total),
+		}
+		if err := f.emit(ctx, scanner.Bytes(), attributes); err != nil {
+			f.Error("Failed to emit entry", zap.Error(err))
+		}
+		f.Offset = scanner.Pos()
+	}
+	if err := getScannerError(scanner); err != nil {
+		f.Errorw("Failed during scan", zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// countChunks returns the number of chunks in the entry at the offset.
+// It returns false if the end of the entry has not been written yet.
+func (f *Reader) countChunks() (int, bool, error) {
+	src, err := f.openAt(f.Offset)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var chunked bool
+	splitFunc := chunkSplitFunc(f.splitFunc, f.fileInput.MaxLogSize, &chunked)
+	scanner := NewPositionalScanner(src, f.fileInput.MaxLogSize, f.Offset, splitFunc)
+	total := 0
+	for scanner.Scan() {
+		if !chunked {
+			// The last part of the entry is empty when the entry
+			// ends at the end of a chunk
+			if len(scanner.Bytes()) > 0 {
+				total++
+			}
+			return total, true, nil
+		}
+		total++
+	}
+	return 0, false, getScannerError(scanner)
+}
+
+// chunkSplitFunc wraps a split func, returning the first size bytes of the data as a
+// token when the data fills the buffer without a complete token. chunked is set to
+// true when the returned token is a chunk of a longer token.
+func chunkSplitFunc(splitFunc bufio.SplitFunc, size int, chunked *bool) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		*chunked = false
+		advance, token, err = splitFunc(data, atEOF)
+		if err == nil && token == nil && len(data) >= size {
+			*chunked = true
+			return size, data[:size], nil
+		}
+		return advance, token, err
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+const testChunkSize = 16384
+
+func newLongLineOperator(t *testing.T) (*InputOperator, chan *entry.Entry, string) {
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.MaxLogSize = helper.ByteSize(testChunkSize)
+		cfg.LongLineMode = "split"
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+	return operator, logReceived, tempDir
+}
+
+func requireChunk(t *testing.T, e *entry.Entry, body string, index, total string) {
+	require.Equal(t, body, e.Body)
+	require.Equal(t, index, e.Attributes["log.chunk.index"])
+	require.Equal(t, total, e.Attributes["log.chunk.total"])
+}
+
+func TestSplitLongLine(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newLongLineOperator(t)
+	defer operator.Stop()
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n"+strings.Repeat("a", 2*testChunkSize+100)+"\ntestlog2\n")
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+	requireChunk(t, waitForOne(t, logReceived), strings.Repeat("a", testChunkSize), "0", "3")
+	requireChunk(t, waitForOne(t, logReceived), strings.Repeat("a", testChunkSize), "1", "3")
+	requireChunk(t, waitForOne(t, logReceived), strings.Repeat("a", 100), "2", "3")
+
+	e := waitForOne(t, logReceived)
+	require.Equal(t, "testlog2", e.Body)
+	require.NotContains(t, e.Attributes, "log.chunk.index")
+	expectNoMessages(t, logReceived)
+}
+
+func TestSplitLongLineEndsAtChunk(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newLongLineOperator(t)
+	defer operator.Stop()
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, strings.Repeat("a", testChunkSize)+"\ntestlog1\n")
+
+	operator.poll(context.Background())
+	requireChunk(t, waitForOne(t, logReceived), strings.Repeat("a", testChunkSize), "0", "1")
+	waitForMessage(t, logReceived, "testlog1")
+	expectNoMessages(t, logReceived)
+}
+
+func TestSplitLongLineIncomplete(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newLongLineOperator(t)
+	defer operator.Stop()
+
+	// The chunks are not emitted until the end of the line is written
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, strings.Repeat("a", testChunkSize+100))
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+
+	writeString(t, temp, "bc\n")
+	operator.poll(context.Background())
+	requireChunk(t, waitForOne(t, logReceived), strings.Repeat("a", testChunkSize), "0", "2")
+	requireChunk(t, waitForOne(t, logReceived), strings.Repeat("a", 100)+"bc", "1", "2")
+	expectNoMessages(t, logReceived)
+}
+
+func TestLongLineError(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.MaxLogSize = helper.ByteSize(testChunkSize)
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, strings.Repeat("a", testChunkSize+100)+"\n")
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+}
@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// fileMetadata returns the attributes which describe the current state of a file
+func fileMetadata(file *os.File) (map[string]string, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat: %s", err)
+	}
+
+	metadata := map[string]string{
+		"file_size":  strconv.FormatInt(info.Size(), 10),
+		"file_mtime": info.ModTime().UTC().Format(time.RFC3339Nano),
+	}
+	if err := addPlatformMetadata(file, info, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
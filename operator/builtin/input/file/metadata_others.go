@@ -0,0 +1,36 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package file
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// addPlatformMetadata adds the owner and inode of the file
+func addPlatformMetadata(_ *os.File, info os.FileInfo, metadata map[string]string) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	metadata["file_uid"] = strconv.FormatUint(uint64(stat.Uid), 10)
+	metadata["file_gid"] = strconv.FormatUint(uint64(stat.Gid), 10)
+	metadata["file_inode"] = strconv.FormatUint(uint64(stat.Ino), 10)
+	return nil
+}
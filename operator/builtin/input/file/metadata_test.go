@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// AddFileMetadata tests that file metadata attributes are included
+// when IncludeFileMetadata is set to true
+func TestAddFileMetadata(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.IncludeFileMetadata = true
+	}, nil)
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog\n")
+	info, err := temp.Stat()
+	require.NoError(t, err)
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	e := waitForOne(t, logReceived)
+	require.Equal(t, "8", e.Attributes["file_size"])
+	require.Equal(t, info.ModTime().UTC().Format(time.RFC3339Nano), e.Attributes["file_mtime"])
+	require.NotEmpty(t, e.Attributes["file_inode"])
+
+	if runtime.GOOS != "windows" {
+		require.Equal(t, strconv.Itoa(os.Getuid()), e.Attributes["file_uid"])
+		require.Equal(t, strconv.Itoa(os.Getgid()), e.Attributes["file_gid"])
+	}
+}
+
+func TestNoFileMetadataByDefault(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, nil, nil)
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog\n")
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	e := waitForOne(t, logReceived)
+	require.NotContains(t, e.Attributes, "file_size")
+	require.NotContains(t, e.Attributes, "file_inode")
+}
@@ -0,0 +1,37 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build windows
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// addPlatformMetadata adds the file index of the file, which is the Windows
+// equivalent of an inode. Windows files do not have an owner uid or gid.
+func addPlatformMetadata(file *os.File, _ os.FileInfo, metadata map[string]string) error {
+	var data syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(syscall.Handle(file.Fd()), &data); err != nil {
+		return fmt.Errorf("get file information: %s", err)
+	}
+
+	index := uint64(data.FileIndexHigh)<<32 | uint64(data.FileIndexLow)
+	metadata["file_inode"] = strconv.FormatUint(index, 10)
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// sillyRenamePrefix is the prefix of the temporary names given by NFS
+// clients to files which are deleted while they are still open
+const sillyRenamePrefix = ".nfs"
+
+// isStaleHandle returns true if the error was caused by a file handle
+// which is no longer valid, such as after a network filesystem fails over
+func isStaleHandle(err error) bool {
+	return errors.Is(err, syscall.ESTALE)
+}
+
+// filterSillyRenames removes the files which were deleted while open on an
+// NFS mount, since they are not new files, and will disappear once closed
+func filterSillyRenames(paths []string) []string {
+	filtered := paths[:0]
+	for _, path := range paths {
+		if !strings.HasPrefix(filepath.Base(path), sillyRenamePrefix) {
+			filtered = append(filtered, path)
+		}
+	}
+	return filtered
+}
+
+// hasStaleAttributes returns true if the file appears to be smaller than the
+// offset that has already been read, without having been modified since it was
+// last read. Network filesystem clients cache file attributes, so this is
+// assumed to be a stale view of the file rather than a truncation.
+func (f *Reader) hasStaleAttributes() bool {
+	info, err := f.file.Stat()
+	if err != nil {
+		return false
+	}
+
+	if info.Size() < f.Offset && !info.ModTime().After(f.readModTime) {
+		return true
+	}
+	f.readModTime = info.ModTime()
+	return false
+}
+
+// findPartialFingerprintMatch returns the known reader whose fingerprint starts
+// with the given fingerprint. A network filesystem may briefly return less content
+// than was previously read, which would otherwise make the file appear to be new.
+func (f *InputOperator) findPartialFingerprintMatch(fp *Fingerprint) (*Reader, bool) {
+	if len(fp.FirstBytes) < minFingerprintSize {
+		return nil, false
+	}
+	for i := len(f.knownFiles) - 1; i >= 0; i-- {
+		oldReader := f.knownFiles[i]
+		if oldReader.Fingerprint.StartsWith(fp) {
+			return oldReader, true
+		}
+	}
+	return nil, false
+}
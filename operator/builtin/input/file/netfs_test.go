@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestFilterSillyRenames(t *testing.T) {
+	paths := []string{
+		filepath.Join("logs", "a.log"),
+		filepath.Join("logs", ".nfs000000000123456700000001"),
+		filepath.Join("logs", "b.log"),
+	}
+	require.Equal(t, []string{
+		filepath.Join("logs", "a.log"),
+		filepath.Join("logs", "b.log"),
+	}, filterSillyRenames(paths))
+}
+
+func TestNetworkFSPartialFingerprintMatch(t *testing.T) {
+	t.Parallel()
+	operator, _, _ := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.NetworkFSMode = true
+	}, nil)
+
+	known := &Reader{Fingerprint: &Fingerprint{FirstBytes: []byte("0123456789abcdefghijklmnop")}}
+	operator.knownFiles = []*Reader{known}
+
+	reader, ok := operator.findFingerprintMatch(&Fingerprint{FirstBytes: []byte("0123456789abcdefgh")})
+	require.True(t, ok)
+	require.Equal(t, known, reader)
+
+	// Too little content to distinguish files
+	_, ok = operator.findFingerprintMatch(&Fingerprint{FirstBytes: []byte("0123456789")})
+	require.False(t, ok)
+
+	operator.networkFS = false
+	_, ok = operator.findFingerprintMatch(&Fingerprint{FirstBytes: []byte("0123456789abcdefgh")})
+	require.False(t, ok)
+}
+
+func TestNetworkFSInodeFingerprint(t *testing.T) {
+	t.Parallel()
+	operator, _, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.FingerprintStrategy = "inode"
+	}, nil)
+
+	temp := openTemp(t, tempDir)
+	fp, err := operator.NewFingerprint(temp)
+	require.NoError(t, err)
+
+	operator.networkFS = true
+	networkFP, err := operator.NewFingerprint(temp)
+	require.NoError(t, err)
+	require.Equal(t, fp.FileID[deviceIDSize:], networkFP.FileID)
+}
+
+// NetworkFSStaleAttributes tests that a file which appears to shrink without
+// being modified is not treated as truncated and read again from the start
+func TestNetworkFSStaleAttributes(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.NetworkFSMode = true
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "this is the first log entry\n")
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "this is the first log entry")
+
+	info, err := temp.Stat()
+	require.NoError(t, err)
+	require.NoError(t, temp.Truncate(20))
+	require.NoError(t, os.Chtimes(temp.Name(), info.ModTime(), info.ModTime()))
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+
+	_, err = temp.WriteAt([]byte("this is the first log entry\nsecond entry\n"), 0)
+	require.NoError(t, err)
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "second entry")
+	expectNoMessages(t, logReceived)
+}
@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	orderingNone          = ""
+	orderingMtime         = "mtime"
+	orderingName          = "name"
+	orderingNumericSuffix = "numeric_suffix"
+)
+
+var numericSuffixRegex = regexp.MustCompile(`(\d+)\D*$`)
+
+// sortMatches sorts the paths in the order in which they should be read
+func sortMatches(paths []string, ordering string) {
+	switch ordering {
+	case orderingMtime:
+		modTimes := make(map[string]time.Time, len(paths))
+		for _, path := range paths {
+			if info, err := os.Stat(path); err == nil {
+				modTimes[path] = info.ModTime()
+			}
+		}
+		sort.SliceStable(paths, func(i, j int) bool {
+			return modTimes[paths[i]].Before(modTimes[paths[j]])
+		})
+	case orderingName:
+		sort.Strings(paths)
+	case orderingNumericSuffix:
+		sort.Strings(paths)
+		sort.SliceStable(paths, func(i, j int) bool {
+			return numericSuffix(paths[i]) > numericSuffix(paths[j])
+		})
+	}
+}
+
+// numericSuffix returns the last number in the file name, or -1 if there is none.
+// Rotated files are typically numbered such that the highest number is the oldest.
+func numericSuffix(path string) int64 {
+	match := numericSuffixRegex.FindStringSubmatch(filepath.Base(path))
+	if match == nil {
+		return -1
+	}
+	n, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
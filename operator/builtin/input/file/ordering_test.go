@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestSortMatches(t *testing.T) {
+	cases := []struct {
+		name     string
+		ordering string
+		paths    []string
+		expected []string
+	}{
+		{
+			"Name",
+			orderingName,
+			[]string{"b.log", "c.log", "a.log"},
+			[]string{"a.log", "b.log", "c.log"},
+		},
+		{
+			"NumericSuffix",
+			orderingNumericSuffix,
+			[]string{"app.log", "app.log.1", "app.log.10", "app.log.2"},
+			[]string{"app.log.10", "app.log.2", "app.log.1", "app.log"},
+		},
+		{
+			"NumericSuffixWithExtension",
+			orderingNumericSuffix,
+			[]string{"app.log", "app.log.1.gz", "app.log.2.gz"},
+			[]string{"app.log.2.gz", "app.log.1.gz", "app.log"},
+		},
+		{
+			"None",
+			orderingNone,
+			[]string{"b.log", "c.log", "a.log"},
+			[]string{"b.log", "c.log", "a.log"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			sortMatches(tc.paths, tc.ordering)
+			require.Equal(t, tc.expected, tc.paths)
+		})
+	}
+}
+
+func TestReadOrderedByName(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Ordering = "name"
+	}, nil)
+
+	for _, name := range []string{"b", "c", "a"} {
+		content := []byte(name + "1\n" + name + "2\n")
+		require.NoError(t, ioutil.WriteFile(filepath.Join(tempDir, name+".log"), content, 0600))
+	}
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	for _, expected := range []string{"a1", "a2", "b1", "b2", "c1", "c2"} {
+		waitForMessage(t, logReceived, expected)
+	}
+}
+
+func TestReadOrderedByMtime(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Ordering = "mtime"
+	}, nil)
+
+	now := time.Now()
+	for i, name := range []string{"a", "b", "c"} {
+		path := filepath.Join(tempDir, name+".log")
+		content := []byte(name + "1\n" + name + "2\n")
+		require.NoError(t, ioutil.WriteFile(path, content, 0600))
+
+		// a is the newest file, and c is the oldest
+		modTime := now.Add(-time.Duration(i) * time.Hour)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	for _, expected := range []string{"c1", "c2", "b1", "b2", "a1", "a2"} {
+		waitForMessage(t, logReceived, expected)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"time"
+)
+
+// tokenBucket allows up to rate tokens per second to be taken. Tokens are
+// added continuously, and up to one second's worth of tokens may accumulate.
+type tokenBucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate int) *tokenBucket {
+	return &tokenBucket{
+		rate:   float64(rate),
+		tokens: float64(rate),
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+}
+
+// available returns true if n tokens can be taken. A request for more tokens than
+// the bucket can hold is allowed once the bucket is full, so that it is not
+// blocked forever.
+func (b *tokenBucket) available(n float64) bool {
+	return b.tokens >= n || b.tokens >= b.rate
+}
+
+// readLimiter limits the number of bytes and lines read from a file per second
+type readLimiter struct {
+	bytes *tokenBucket
+	lines *tokenBucket
+}
+
+// newReadLimiter creates a limiter for the configured rates, or
+// returns nil if the rate of reading is not limited
+func newReadLimiter(maxBytesPerSec, maxLinesPerSec int) *readLimiter {
+	if maxBytesPerSec <= 0 && maxLinesPerSec <= 0 {
+		return nil
+	}
+
+	l := &readLimiter{}
+	if maxBytesPerSec > 0 {
+		l.bytes = newTokenBucket(maxBytesPerSec)
+	}
+	if maxLinesPerSec > 0 {
+		l.lines = newTokenBucket(maxLinesPerSec)
+	}
+	return l
+}
+
+// allow returns true if a token of the given size may be read now. If it may not,
+// no tokens are consumed, and the token should be read during a later poll.
+func (l *readLimiter) allow(size int) bool {
+	if l == nil {
+		return true
+	}
+
+	now := time.Now()
+	if l.bytes != nil {
+		l.bytes.refill(now)
+		if !l.bytes.available(float64(size)) {
+			return false
+		}
+	}
+	if l.lines != nil {
+		l.lines.refill(now)
+		if !l.lines.available(1) {
+			return false
+		}
+	}
+
+	if l.bytes != nil {
+		l.bytes.tokens -= float64(size)
+	}
+	if l.lines != nil {
+		l.lines.tokens--
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestReadLimiterUnlimited(t *testing.T) {
+	l := newReadLimiter(0, 0)
+	require.Nil(t, l)
+	for i := 0; i < 1000; i++ {
+		require.True(t, l.allow(1024))
+	}
+}
+
+func TestReadLimiterLines(t *testing.T) {
+	l := newReadLimiter(0, 3)
+	require.True(t, l.allow(10))
+	require.True(t, l.allow(10))
+	require.True(t, l.allow(10))
+	require.False(t, l.allow(10))
+
+	l.lines.last = l.lines.last.Add(-time.Second)
+	require.True(t, l.allow(10))
+}
+
+func TestReadLimiterBytes(t *testing.T) {
+	l := newReadLimiter(20, 0)
+	require.True(t, l.allow(15))
+	require.False(t, l.allow(15))
+	require.True(t, l.allow(5))
+}
+
+// ReadLimiterOversizedToken tests that a token larger than the
+// byte rate is allowed once the bucket is full
+func TestReadLimiterOversizedToken(t *testing.T) {
+	l := newReadLimiter(10, 0)
+	require.True(t, l.allow(100))
+	require.False(t, l.allow(1))
+
+	l.bytes.last = l.bytes.last.Add(-100 * time.Second)
+	require.True(t, l.allow(1))
+}
+
+func TestReadLimiterBoth(t *testing.T) {
+	l := newReadLimiter(100, 1)
+	require.True(t, l.allow(10))
+
+	// Rejected by the line limit, so no bytes are consumed
+	require.False(t, l.allow(10))
+	require.InDelta(t, 90, l.bytes.tokens, 1)
+}
+
+func TestMaxLinesPerSec(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.MaxLinesPerSec = 2
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\ntestlog2\ntestlog3\ntestlog4\n")
+
+	operator.poll(context.Background())
+	waitForMessages(t, logReceived, []string{"testlog1", "testlog2"})
+	expectNoMessages(t, logReceived)
+
+	// The rest of the file is read once the limit allows it
+	time.Sleep(time.Second)
+	operator.poll(context.Background())
+	waitForMessages(t, logReceived, []string{"testlog3", "testlog4"})
+}
+
+// RateLimitFairness tests that a file which exceeds its rate
+// limit does not prevent other files from being read
+func TestRateLimitFairness(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.MaxLinesPerSec = 1
+		cfg.Ordering = "name"
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	hot := openTempWithPattern(t, tempDir, "a*.log")
+	writeString(t, hot, "hot1\nhot2\nhot3\n")
+	quiet := openTempWithPattern(t, tempDir, "b*.log")
+	writeString(t, quiet, "quiet1\n")
+
+	operator.poll(context.Background())
+	waitForMessages(t, logReceived, []string{"hot1", "quiet1"})
+	expectNoMessages(t, logReceived)
+}
@@ -19,28 +19,57 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 	"golang.org/x/text/encoding"
 	"golang.org/x/text/transform"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/errors"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 )
 
 // Reader manages a single file
 type Reader struct {
-	Fingerprint *Fingerprint
-	Offset      int64
-	Path        string
+	Fingerprint    *Fingerprint
+	Offset         int64
+	Path           string
+	HeaderValues   map[string]string      `json:",omitempty"`
+	HeaderComplete bool                   `json:",omitempty"`
+	HeaderLines    int                    `json:",omitempty"`
+	Encoding       string                 `json:",omitempty"`
+	MemberOffsets  map[string]int64       `json:",omitempty"`
+	CRIPartials    map[string]*CRIPartial `json:",omitempty"`
 
-	generation int
-	fileInput  *InputOperator
-	file       *os.File
+	generation  int
+	fileInput   *InputOperator
+	file        *os.File
+	compression string
+	finished    bool
+	metadata    map[string]string
+	limiter     *readLimiter
+	flusher     *helper.Flusher
+
+	idleInterval time.Duration
+	nextRead     time.Time
+	lastSize     int64
+	lastModTime  time.Time
+	readModTime  time.Time
+
+	// The size and modification time of a compressed file when its content
+	// was last read to the end, so that it is not decompressed again until
+	// it changes
+	endSize    int64
+	endModTime time.Time
 
 	decoder      *encoding.Decoder
 	decodeBuffer []byte
+	splitFunc    bufio.SplitFunc
 
 	*zap.SugaredLogger `json:"-"`
 }
@@ -55,6 +84,14 @@ func (f *InputOperator) NewReader(path string, file *os.File, fp *Fingerprint) (
 		SugaredLogger: f.SugaredLogger.With("path", path),
 		decoder:       f.encoding.Encoding.NewDecoder(),
 		decodeBuffer:  make([]byte, 1<<12),
+		splitFunc:     f.SplitFunc,
+		limiter:       newReadLimiter(f.maxBytesPerSec, f.maxLinesPerSec),
+	}
+	if f.forceFlushPeriod > 0 {
+		r.flusher = helper.NewFlusher(f.forceFlushPeriod)
+	}
+	if file != nil {
+		r.compression = f.compressionOf(file)
 	}
 	return r, nil
 }
@@ -66,65 +103,402 @@ func (f *Reader) Copy(file *os.File) (*Reader, error) {
 		return nil, err
 	}
 	reader.Offset = f.Offset
+	reader.limiter = f.limiter
+	reader.flusher = f.flusher
+	reader.idleInterval = f.idleInterval
+	reader.nextRead = f.nextRead
+	reader.lastSize = f.lastSize
+	reader.lastModTime = f.lastModTime
+	reader.readModTime = f.readModTime
+	reader.endSize = f.endSize
+	reader.endModTime = f.endModTime
+	reader.HeaderComplete = f.HeaderComplete
+	reader.HeaderLines = f.HeaderLines
+	if f.fileInput.autoEncoding && f.Encoding != "" {
+		if err := reader.setEncoding(f.Encoding); err != nil {
+			return nil, err
+		}
+	}
+	if f.MemberOffsets != nil {
+		reader.MemberOffsets = make(map[string]int64, len(f.MemberOffsets))
+		for name, offset := range f.MemberOffsets {
+			reader.MemberOffsets[name] = offset
+		}
+	}
+	if f.CRIPartials != nil {
+		reader.CRIPartials = make(map[string]*CRIPartial, len(f.CRIPartials))
+		for stream, partial := range f.CRIPartials {
+			reader.CRIPartials[stream] = &CRIPartial{
+				Message:   append([]byte(nil), partial.Message...),
+				Timestamp: partial.Timestamp,
+			}
+		}
+	}
+	if f.HeaderValues != nil {
+		reader.HeaderValues = make(map[string]string, len(f.HeaderValues))
+		for key, value := range f.HeaderValues {
+			reader.HeaderValues[key] = value
+		}
+	}
 	return reader, nil
 }
 
 // InitializeOffset sets the starting offset
 func (f *Reader) InitializeOffset(startAtBeginning bool) error {
-	if !startAtBeginning {
-		info, err := f.file.Stat()
-		if err != nil {
-			return fmt.Errorf("stat: %s", err)
+	if err := f.detectEncoding(); err != nil {
+		return fmt.Errorf("detect encoding: %s", err)
+	}
+
+	if startAtBeginning {
+		return nil
+	}
+
+	// The members of zip archives are read separately, so only the end of each member can be found
+	if f.compression == compressionZip {
+		return f.skipArchive()
+	}
+
+	// The header applies to all entries in the file, so it is read even
+	// though the content before the end of the file will be skipped
+	if f.fileInput.header != nil {
+		if err := f.readHeader(); err != nil {
+			return fmt.Errorf("read header: %s", err)
 		}
-		f.Offset = info.Size()
 	}
 
+	if start := f.fileInput.startPosition; start != nil {
+		return f.seekStartPosition(start)
+	}
+
+	size, err := f.contentSize()
+	if err != nil {
+		return err
+	}
+	f.Offset = size
+	f.recordEnd()
 	return nil
 }
 
+// contentSize returns the size of the file's content, which
+// is the decompressed size if the file is compressed
+func (f *Reader) contentSize() (int64, error) {
+	if f.compression != compressionNone {
+		r, err := newDecompressor(f.file, f.compression)
+		if err != nil {
+			return 0, fmt.Errorf("open %s stream: %s", f.compression, err)
+		}
+		size, err := io.Copy(ioutil.Discard, r)
+		if err != nil {
+			return 0, fmt.Errorf("decompress: %s", err)
+		}
+		return size, nil
+	}
+
+	info, err := f.file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("stat: %s", err)
+	}
+	return info.Size(), nil
+}
+
+// recordEnd records the size and modification time of a compressed
+// file after its content has been read to the end
+func (f *Reader) recordEnd() {
+	if f.compression == compressionNone {
+		return
+	}
+	if info, err := f.file.Stat(); err == nil {
+		f.endSize = info.Size()
+		f.endModTime = info.ModTime()
+	}
+}
+
+// unchangedSinceEnd returns true if the file is compressed and has not changed
+// since its content was read to the end. Compressed files can only be read
+// from the beginning, so they are not decompressed again to find no new content.
+func (f *Reader) unchangedSinceEnd() bool {
+	if f.compression == compressionNone || f.endModTime.IsZero() {
+		return false
+	}
+	info, err := f.file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Size() == f.endSize && info.ModTime().Equal(f.endModTime)
+}
+
 // ReadToEnd will read until the end of the file
 func (f *Reader) ReadToEnd(ctx context.Context) {
 	defer f.file.Close()
+	defer f.updateBackoff(f.Offset)
+	defer f.updateHashFingerprint()
 
-	if _, err := f.file.Seek(f.Offset, 0); err != nil {
-		f.Errorw("Failed to seek", zap.Error(err))
+	if f.fileInput.networkFS && f.hasStaleAttributes() {
+		f.Debugw("File appears to be truncated without being modified. Will retry during the next poll")
+		return
+	}
+
+	if f.unchangedSinceEnd() {
+		return
+	}
+
+	if err := f.checkTruncation(); err != nil {
+		f.Errorw("Failed to check for truncation", zap.Error(err))
+		return
+	}
+
+	if err := f.detectEncoding(); err != nil {
+		f.Errorw("Failed to detect encoding", zap.Error(err))
+		return
+	}
+
+	if f.fileInput.includeFileMetadata {
+		metadata, err := fileMetadata(f.file)
+		if err != nil {
+			f.Errorw("Failed to read file metadata", zap.Error(err))
+			return
+		}
+		f.metadata = metadata
+	}
+
+	if f.compression == compressionZip {
+		f.readArchive(ctx)
 		return
 	}
 
-	fr := NewFingerprintUpdatingReader(f.file, f.Offset, f.Fingerprint, f.fileInput.fingerprintSize)
-	scanner := NewPositionalScanner(fr, f.fileInput.MaxLogSize, f.Offset, f.fileInput.SplitFunc)
+	for f.readEntries(ctx) {
+		// The entry at the offset is longer than max_log_size, so it is
+		// emitted in chunks before the rest of the file is read
+		if !f.readLongEntry(ctx) {
+			return
+		}
+	}
+}
+
+// readEntries reads the entries from the offset to the end of the file. It returns
+// true if it stopped at an entry which is longer than max_log_size and should be split.
+func (f *Reader) readEntries(ctx context.Context) bool {
+	src, err := f.openAt(f.Offset)
+	if err != nil {
+		f.Errorw("Failed to seek", zap.Error(err))
+		return false
+	}
+
+	// Hash fingerprints are updated by updateHashFingerprint instead, since the bytes read are not hashed
+	fr := src
+	if f.fileInput.fingerprintStrategy != fingerprintStrategyHash {
+		fr = NewFingerprintUpdatingReader(src, f.Offset, f.Fingerprint, f.fileInput.fingerprintSize)
+	}
+	// The flusher only applies while reading new content, since it
+	// depends on how long the content at the end of the file has waited
+	splitFunc := f.splitFunc
+	if f.flusher != nil {
+		splitFunc = f.flusher.SplitFunc(splitFunc)
+	}
+	scanner := NewPositionalScanner(fr, f.fileInput.MaxLogSize, f.Offset, splitFunc)
 
 	// Iterate over the tokenized file, emitting entries as we go
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			return false
 		default:
 		}
 
 		ok := scanner.Scan()
 		if !ok {
+			if f.fileInput.splitLongLines && scanner.Err() == bufio.ErrTooLong {
+				return true
+			}
 			if err := getScannerError(scanner); err != nil {
 				f.Errorw("Failed during scan", zap.Error(err))
+				return false
 			}
-			break
+			f.recordEnd()
+			if f.fileInput.deleteAfterRead || f.fileInput.onComplete != nil {
+				f.finished = f.isFinished()
+			}
+			return false
+		}
+
+		if f.consumeHeader(scanner.Bytes()) {
+			f.Offset = scanner.Pos()
+			continue
 		}
 
-		if err := f.emit(ctx, scanner.Bytes()); err != nil {
+		// Stop reading when the rate limit is reached, so that other files are
+		// not starved. The rest of the file will be read during later polls.
+		if !f.limiter.allow(len(scanner.Bytes())) {
+			return false
+		}
+
+		if err := f.emit(ctx, scanner.Bytes(), nil); err != nil {
 			f.Error("Failed to emit entry", zap.Error(err))
 		}
 		f.Offset = scanner.Pos()
 	}
 }
 
+// updateHashFingerprint recomputes a partial hash fingerprint, since the file may
+// have grown to contain more of, or all of, the hashed range
+func (f *Reader) updateHashFingerprint() {
+	if f.fileInput.fingerprintStrategy != fingerprintStrategyHash || !f.Fingerprint.Partial {
+		return
+	}
+
+	fp, err := f.fileInput.NewFingerprint(f.file)
+	if err != nil {
+		f.Errorw("Failed to update fingerprint", zap.Error(err))
+		return
+	}
+	if fp.StartsWith(f.Fingerprint) {
+		f.Fingerprint = fp
+	}
+}
+
+// readHeader reads the header lines at the beginning of the file
+func (f *Reader) readHeader() error {
+	src, err := f.openAt(0)
+	if err != nil {
+		return err
+	}
+
+	scanner := NewPositionalScanner(src, f.fileInput.MaxLogSize, 0, f.splitFunc)
+	for scanner.Scan() {
+		if !f.consumeHeader(scanner.Bytes()) {
+			break
+		}
+	}
+	return getScannerError(scanner)
+}
+
+// consumeHeader returns true if the token is part of the file's header,
+// in which case the values parsed from the token are saved on the reader
+func (f *Reader) consumeHeader(token []byte) bool {
+	header := f.fileInput.header
+	if header == nil || f.HeaderComplete {
+		return false
+	}
+
+	line, err := f.decode(token)
+	if err != nil || !header.isHeader(line, f.HeaderLines) {
+		f.HeaderComplete = true
+		return false
+	}
+
+	if f.HeaderValues == nil {
+		f.HeaderValues = make(map[string]string)
+	}
+	header.parse(line, f.HeaderValues)
+	f.HeaderLines++
+	return true
+}
+
+// checkTruncation resets the reader to the beginning of the file if the file
+// is now smaller than the offset that has already been read. The current first
+// bytes of the file are compared to the stored fingerprint, in order to distinguish
+// a file which was truncated, such as by copytruncate rotation, from a file whose
+// content was replaced. A truncated file keeps its identity, while a replaced file
+// is read as a new file.
+func (f *Reader) checkTruncation() error {
+	if f.compression != compressionNone {
+		return nil
+	}
+
+	info, err := f.file.Stat()
+	if err != nil {
+		return fmt.Errorf("stat: %s", err)
+	}
+	if info.Size() >= f.Offset {
+		return nil
+	}
+
+	fp, err := f.fileInput.NewFingerprint(f.file)
+	if err != nil {
+		return err
+	}
+	// An empty fingerprint would match any file, so the stored fingerprint is kept until there is content
+	empty := len(fp.FirstBytes) == 0 && len(fp.FileID) == 0
+
+	if !empty && !fp.StartsWith(f.Fingerprint) && !f.Fingerprint.StartsWith(fp) {
+		atomic.AddUint64(&f.fileInput.replaced, 1)
+		f.Infow("File content was replaced. Reading as a new file", "offset", f.Offset, "size", info.Size())
+		f.Fingerprint = fp
+		f.Encoding = ""
+		f.resetContent()
+		return nil
+	}
+
+	atomic.AddUint64(&f.fileInput.truncated, 1)
+	f.Infow("File was truncated. Reading from the beginning", "offset", f.Offset, "size", info.Size())
+	// The fingerprint only shrinks, to the content which remains, so that the file is still matched next poll
+	if !empty && len(fp.FirstBytes) < len(f.Fingerprint.FirstBytes) {
+		fp.FileID = f.Fingerprint.FileID
+		f.Fingerprint = fp
+	}
+	f.resetContent()
+	return nil
+}
+
+// resetContent resets the state of the content which has been read, so that the file is read from the beginning
+func (f *Reader) resetContent() {
+	f.Offset = 0
+	f.HeaderValues = nil
+	f.HeaderComplete = false
+	f.HeaderLines = 0
+	f.CRIPartials = nil
+}
+
+// isFinished returns true if the entire file has been read, and the file
+// has not been modified for at least the minimum idle time
+func (f *Reader) isFinished() bool {
+	info, err := f.file.Stat()
+	if err != nil {
+		return false
+	}
+	if f.compression == compressionNone && f.Offset < info.Size() {
+		return false
+	}
+	return time.Since(info.ModTime()) >= f.fileInput.minIdle
+}
+
+// openAt returns a reader positioned at the given offset. For compressed
+// files, the offset refers to a position in the decompressed stream.
+func (f *Reader) openAt(offset int64) (io.Reader, error) {
+	if f.compression == compressionNone {
+		if _, err := f.file.Seek(offset, 0); err != nil {
+			return nil, err
+		}
+		return f.file, nil
+	}
+
+	r, err := newDecompressor(f.file, f.compression)
+	if err != nil {
+		return nil, fmt.Errorf("open %s stream: %s", f.compression, err)
+	}
+	if _, err := io.CopyN(ioutil.Discard, r, offset); err != nil {
+		return nil, fmt.Errorf("skip to offset: %s", err)
+	}
+	return r, nil
+}
+
 // Close will close the file
 func (f *Reader) Close() error {
 	return f.file.Close()
 }
 
-// Emit creates an entry with the decoded message and sends it to the next
-// operator in the pipeline
-func (f *Reader) emit(ctx context.Context, msgBuf []byte) error {
+// Emit creates an entry with the decoded message and any additional attributes,
+// and sends it to the next operator in the pipeline
+func (f *Reader) emit(ctx context.Context, msgBuf []byte, attributes map[string]string) error {
+	if f.fileInput.format == formatCRI {
+		return f.emitCRI(ctx, msgBuf, attributes)
+	}
+	return f.emitEntry(ctx, msgBuf, attributes, time.Time{})
+}
+
+// emitEntry creates and sends an entry. The entry's timestamp
+// is set if the timestamp is not zero.
+func (f *Reader) emitEntry(ctx context.Context, msgBuf []byte, attributes map[string]string, timestamp time.Time) error {
 	// Skip the entry if it's empty
 	if len(msgBuf) == 0 {
 		return nil
@@ -139,6 +513,9 @@ func (f *Reader) emit(ctx context.Context, msgBuf []byte) error {
 	if err != nil {
 		return fmt.Errorf("create entry: %s", err)
 	}
+	if !timestamp.IsZero() {
+		e.Timestamp = timestamp
+	}
 
 	if err := e.Set(f.fileInput.FilePathField, f.Path); err != nil {
 		return err
@@ -146,6 +523,18 @@ func (f *Reader) emit(ctx context.Context, msgBuf []byte) error {
 	if err := e.Set(f.fileInput.FileNameField, filepath.Base(f.Path)); err != nil {
 		return err
 	}
+	for key, value := range f.metadata {
+		e.AddAttribute(key, value)
+	}
+	if f.fileInput.header != nil {
+		f.fileInput.header.apply(e, f.HeaderValues)
+	}
+	if f.fileInput.autoEncoding {
+		e.AddAttribute("file_encoding", f.Encoding)
+	}
+	for key, value := range attributes {
+		e.AddAttribute(key, value)
+	}
 	f.fileInput.Write(ctx, e)
 	return nil
 }
@@ -161,6 +550,10 @@ func (f *Reader) decode(msgBuf []byte) (string, error) {
 		} else if err != nil {
 			return "", fmt.Errorf("transform encoding: %s", err)
 		}
+		if f.fileInput.autoEncoding {
+			// The byte order mark is not part of the content
+			return strings.TrimPrefix(string(f.decodeBuffer[:nDst]), "\ufeff"), nil
+		}
 		return string(f.decodeBuffer[:nDst]), nil
 	}
 }
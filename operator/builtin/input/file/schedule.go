@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v3"
+)
+
+// FileSetStats describes how the files matched by one include pattern are being read
+type FileSetStats struct {
+	// Include is the include pattern which matches the files
+	Include string
+
+	// MatchedFiles is the number of files matched during the latest poll cycle
+	MatchedFiles int
+
+	// QueuedFiles is the number of matched files waiting to be read in a later poll
+	QueuedFiles int
+
+	// MaxTimeSinceRead is the longest time since any of the files was last read,
+	// or was first matched if it has not been read yet
+	MaxTimeSinceRead time.Duration
+}
+
+// fileSchedule records when a file was first matched, and when it was last read
+type fileSchedule struct {
+	matched  time.Time
+	lastRead time.Time
+}
+
+// scheduler tracks when each matched file was last read. When more files match
+// than can be read at once, the files which were read least recently are read
+// first, so that every file is read in turn.
+type scheduler struct {
+	mux      sync.Mutex
+	includes []string
+	files    map[string]*fileSchedule
+	queued   map[string]struct{}
+}
+
+func newScheduler(includes []string) *scheduler {
+	return &scheduler{
+		includes: includes,
+		files:    make(map[string]*fileSchedule),
+		queued:   make(map[string]struct{}),
+	}
+}
+
+// update records the files matched during a new poll cycle,
+// and forgets the files which no longer match
+func (s *scheduler) update(matches []string, now time.Time) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	files := make(map[string]*fileSchedule, len(matches))
+	for _, path := range matches {
+		if file, ok := s.files[path]; ok {
+			files[path] = file
+		} else {
+			files[path] = &fileSchedule{matched: now}
+		}
+	}
+	s.files = files
+}
+
+// sortLeastRecentlyRead sorts the paths so that files which have
+// not been read yet come first, followed by those read least recently
+func (s *scheduler) sortLeastRecentlyRead(paths []string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	lastRead := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		if file, ok := s.files[path]; ok {
+			lastRead[path] = file.lastRead
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		return lastRead[paths[i]].Before(lastRead[paths[j]])
+	})
+}
+
+// setQueued records the paths which are waiting to be read in a later poll
+func (s *scheduler) setQueued(paths []string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.queued = make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		s.queued[path] = struct{}{}
+	}
+}
+
+// markRead records that the readers' files were read
+func (s *scheduler) markRead(readers []*Reader, now time.Time) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for _, reader := range readers {
+		if file, ok := s.files[reader.Path]; ok {
+			file.lastRead = now
+		}
+	}
+}
+
+// stats returns the stats of each include pattern. Each file
+// is counted under the first include pattern which matches it.
+func (s *scheduler) stats(now time.Time) []FileSetStats {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	stats := make([]FileSetStats, len(s.includes))
+	for i, include := range s.includes {
+		stats[i].Include = include
+	}
+
+	for path, file := range s.files {
+		i := s.fileSet(path)
+		if i < 0 {
+			continue
+		}
+
+		stats[i].MatchedFiles++
+		if _, ok := s.queued[path]; ok {
+			stats[i].QueuedFiles++
+		}
+
+		since := file.lastRead
+		if since.IsZero() {
+			since = file.matched
+		}
+		if d := now.Sub(since); d > stats[i].MaxTimeSinceRead {
+			stats[i].MaxTimeSinceRead = d
+		}
+	}
+	return stats
+}
+
+// fileSet returns the index of the first include pattern which matches the path
+func (s *scheduler) fileSet(path string) int {
+	for i, include := range s.includes {
+		if ok, _ := doublestar.PathMatch(include, path); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// Stats returns the stats of the files matched by each include pattern
+func (f *InputOperator) Stats() []FileSetStats {
+	return f.scheduler.stats(time.Now())
+}
+
+// logStats logs the stats of each file set at the start of a poll cycle
+func (f *InputOperator) logStats() {
+	for _, stats := range f.Stats() {
+		f.Debugw("File set stats",
+			"include", stats.Include,
+			"matched_files", stats.MatchedFiles,
+			"queued_files", stats.QueuedFiles,
+			"max_time_since_read", stats.MaxTimeSinceRead,
+		)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestSchedulerSortLeastRecentlyRead(t *testing.T) {
+	s := newScheduler([]string{"*"})
+	now := time.Now()
+	s.update([]string{"a", "b", "c", "d"}, now)
+	s.markRead([]*Reader{{Path: "a"}}, now.Add(3*time.Second))
+	s.markRead([]*Reader{{Path: "b"}}, now.Add(1*time.Second))
+	s.markRead([]*Reader{{Path: "c"}}, now.Add(2*time.Second))
+
+	paths := []string{"a", "b", "c", "d"}
+	s.sortLeastRecentlyRead(paths)
+	require.Equal(t, []string{"d", "b", "c", "a"}, paths)
+}
+
+func TestSchedulerForgetsUnmatchedFiles(t *testing.T) {
+	s := newScheduler([]string{"*"})
+	now := time.Now()
+	s.update([]string{"a", "b"}, now)
+	s.markRead([]*Reader{{Path: "a"}, {Path: "b"}}, now)
+	s.update([]string{"b"}, now)
+	require.Len(t, s.files, 1)
+	require.Contains(t, s.files, "b")
+}
+
+func TestSchedulerStats(t *testing.T) {
+	s := newScheduler([]string{"/logs/a/*", "/logs/*/*"})
+	now := time.Now()
+	s.update([]string{"/logs/a/1", "/logs/a/2", "/logs/b/1", "/other/1"}, now.Add(-time.Minute))
+	s.markRead([]*Reader{{Path: "/logs/a/1"}}, now.Add(-time.Second))
+	s.setQueued([]string{"/logs/a/2"})
+
+	stats := s.stats(now)
+	require.Equal(t, []FileSetStats{
+		{
+			Include:          "/logs/a/*",
+			MatchedFiles:     2,
+			QueuedFiles:      1,
+			MaxTimeSinceRead: time.Minute,
+		},
+		{
+			Include:          "/logs/*/*",
+			MatchedFiles:     1,
+			QueuedFiles:      0,
+			MaxTimeSinceRead: time.Minute,
+		},
+	}, stats)
+}
+
+// LeastRecentlyReadFirst tests that when more files match than can be read
+// at once, a new file is read before files which have already been read
+func TestLeastRecentlyReadFirst(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.MaxConcurrentFiles = 2
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	temp1 := openTempWithPattern(t, tempDir, "a")
+	writeString(t, temp1, "testlog1\n")
+	temp2 := openTempWithPattern(t, tempDir, "b")
+	writeString(t, temp2, "testlog2\n")
+
+	operator.poll(context.Background())
+	waitForMessages(t, logReceived, []string{"testlog1", "testlog2"})
+
+	temp3 := openTempWithPattern(t, tempDir, "c")
+	writeString(t, temp3, "testlog3\n")
+	writeString(t, temp2, "testlog4\n")
+
+	// The new file is read first, and the file which was read least recently is queued
+	operator.poll(context.Background())
+	require.Equal(t, filepath.Join(tempDir, "*"), operator.Stats()[0].Include)
+	require.Equal(t, 3, operator.Stats()[0].MatchedFiles)
+	require.Equal(t, 1, operator.Stats()[0].QueuedFiles)
+	waitForMessage(t, logReceived, "testlog3")
+	expectNoMessages(t, logReceived)
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog4")
+	require.Equal(t, 0, operator.Stats()[0].QueuedFiles)
+}
@@ -0,0 +1,33 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package file
+
+import (
+	"os"
+)
+
+// openForReading opens the file for reading. Open files do not prevent other
+// processes from renaming or deleting them, so shareDelete has no effect.
+func openForReading(path string, _ bool) (*os.File, error) {
+	return os.Open(path)
+}
+
+// isLocked returns true if the error was caused by another process
+// locking the file. File locks are advisory, so reads are never blocked.
+func isLocked(_ error) bool {
+	return false
+}
@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ShareDeleteRename tests that a file which is open for reading
+// can be renamed and deleted by another process
+func TestShareDeleteRename(t *testing.T) {
+	t.Parallel()
+	_, _, tempDir := newTestFileOperator(t, nil, nil)
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+	require.NoError(t, temp.Close())
+
+	file, err := openForReading(temp.Name(), true)
+	require.NoError(t, err)
+	defer file.Close()
+
+	rotated := filepath.Join(tempDir, "rotated.log")
+	require.NoError(t, os.Rename(temp.Name(), rotated))
+	require.NoError(t, os.Remove(rotated))
+}
+
+// RetainLocked tests that the offset of a file which is locked for
+// longer than the known files are normally kept is not forgotten
+func TestRetainLocked(t *testing.T) {
+	t.Parallel()
+	operator, _, _ := newTestFileOperator(t, nil, nil)
+
+	locked, err := operator.NewReader("locked.log", nil, &Fingerprint{FirstBytes: []byte("locked")})
+	require.NoError(t, err)
+	locked.Offset = 10
+	other, err := operator.NewReader("other.log", nil, &Fingerprint{FirstBytes: []byte("other")})
+	require.NoError(t, err)
+	operator.knownFiles = []*Reader{locked, other}
+
+	for i := 0; i < 5; i++ {
+		for _, reader := range operator.knownFiles {
+			reader.generation++
+		}
+		operator.retainKnownReaders("locked.log")
+		operator.saveCurrent(nil)
+	}
+
+	require.Equal(t, []*Reader{locked}, operator.knownFiles)
+}
+
+func TestIsLocked(t *testing.T) {
+	require.False(t, isLocked(os.ErrNotExist))
+	require.False(t, isLocked(os.ErrPermission))
+}
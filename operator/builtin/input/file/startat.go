@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// startPosition is a position in a file from which to start reading,
+// other than the beginning or the end of the file
+type startPosition struct {
+	offset int64
+	time   time.Time
+}
+
+// parseStartPosition parses a start_at value which is a byte offset or an RFC 3339 timestamp
+func parseStartPosition(startAt string) (*startPosition, error) {
+	if offset, err := strconv.ParseInt(startAt, 10, 64); err == nil {
+		if offset < 0 {
+			return nil, fmt.Errorf("start_at offset must not be negative")
+		}
+		return &startPosition{offset: offset}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, startAt); err == nil {
+		return &startPosition{time: t}, nil
+	}
+
+	return nil, fmt.Errorf("invalid start_at location '%s'", startAt)
+}
+
+// StartAtTimeConfig is the configuration used to find the timestamps
+// of entries when start_at is a timestamp
+type StartAtTimeConfig struct {
+	Regex      string `mapstructure:"regex,omitempty"       json:"regex,omitempty"       yaml:"regex,omitempty"`
+	Layout     string `mapstructure:"layout,omitempty"      json:"layout,omitempty"      yaml:"layout,omitempty"`
+	LayoutType string `mapstructure:"layout_type,omitempty" json:"layout_type,omitempty" yaml:"layout_type,omitempty"`
+	Location   string `mapstructure:"location,omitempty"    json:"location,omitempty"    yaml:"location,omitempty"`
+}
+
+// Build will build a timestamp finder from the supplied configuration
+func (c StartAtTimeConfig) Build(context operator.BuildContext) (*timestampFinder, error) {
+	parseFrom := entry.NewBodyField()
+	parser := helper.NewTimeParser()
+	parser.ParseFrom = &parseFrom
+	parser.Layout = c.Layout
+	if c.LayoutType != "" {
+		parser.LayoutType = c.LayoutType
+	}
+	parser.Location = c.Location
+	if err := parser.Validate(context); err != nil {
+		return nil, fmt.Errorf("start_at_time: %s", err)
+	}
+
+	finder := &timestampFinder{parser: parser}
+	if c.Regex != "" {
+		regex, err := regexp.Compile(c.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("compiling start_at_time regex: %s", err)
+		}
+		if regex.NumSubexp() == 0 {
+			return nil, fmt.Errorf("start_at_time regex must contain a capture group")
+		}
+		finder.regex = regex
+	}
+	return finder, nil
+}
+
+// timestampFinder parses the timestamp of an entry
+type timestampFinder struct {
+	regex  *regexp.Regexp
+	parser helper.TimeParser
+}
+
+// find returns the timestamp of the entry, or false if it does not contain one
+func (t *timestampFinder) find(line string) (time.Time, bool) {
+	value := line
+	if t.regex != nil {
+		matches := t.regex.FindStringSubmatch(line)
+		if matches == nil {
+			return time.Time{}, false
+		}
+		value = matches[1]
+	}
+
+	e := entry.New()
+	e.Body = value
+	if err := t.parser.Parse(e); err != nil {
+		return time.Time{}, false
+	}
+	return e.Timestamp, true
+}
+
+// seekStartPosition sets the offset to the start position. When the start
+// position is a timestamp, the offset is set to the first entry with a
+// timestamp at or after it. Entries without a timestamp are skipped.
+func (f *Reader) seekStartPosition(start *startPosition) error {
+	if start.time.IsZero() {
+		size, err := f.contentSize()
+		if err != nil {
+			return err
+		}
+		f.Offset = start.offset
+		if f.Offset > size {
+			f.Offset = size
+		}
+		return nil
+	}
+
+	src, err := f.openAt(0)
+	if err != nil {
+		return err
+	}
+
+	var pos int64
+	scanner := NewPositionalScanner(src, f.fileInput.MaxLogSize, 0, f.splitFunc)
+	for scanner.Scan() {
+		line, err := f.decode(scanner.Bytes())
+		if err == nil {
+			if t, ok := f.fileInput.startAtTime.find(line); ok && !t.Before(start.time) {
+				break
+			}
+		}
+		pos = scanner.Pos()
+	}
+	if err := getScannerError(scanner); err != nil {
+		return err
+	}
+
+	f.Offset = pos
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestStartAtOffset(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.StartAt = "9"
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\ntestlog2\n")
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog2")
+	expectNoMessages(t, logReceived)
+}
+
+// StartAtOffsetPastEnd tests that an offset past the end of
+// a file starts reading from the end of the file
+func TestStartAtOffsetPastEnd(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.StartAt = "1000"
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+
+	writeString(t, temp, "testlog2\n")
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog2")
+}
+
+func TestStartAtTimestamp(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.StartAt = "2021-06-01T10:00:00Z"
+		cfg.StartAtTime = &StartAtTimeConfig{
+			Regex:    `^(\S+ \S+)`,
+			Layout:   "%Y-%m-%d %H:%M:%S",
+			Location: "UTC",
+		}
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "2021-06-01 09:00:00 testlog1\n")
+	writeString(t, temp, "no timestamp\n")
+	writeString(t, temp, "2021-06-01 10:00:00 testlog2\n")
+	writeString(t, temp, "2021-06-01 09:30:00 testlog3\n")
+
+	operator.poll(context.Background())
+	waitForMessages(t, logReceived, []string{
+		"2021-06-01 10:00:00 testlog2",
+		"2021-06-01 09:30:00 testlog3",
+	})
+	expectNoMessages(t, logReceived)
+}
+
+// StartAtTimestampNoneAfter tests that a file with no entries after the
+// timestamp starts reading from the end of its last complete entry
+func TestStartAtTimestampNoneAfter(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.StartAt = "2021-06-01T10:00:00Z"
+		cfg.StartAtTime = &StartAtTimeConfig{
+			Regex:      `^(\d+) `,
+			Layout:     "s",
+			LayoutType: "epoch",
+		}
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "1622538000 testlog1\n1622541")
+
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+
+	writeString(t, temp, "600 testlog2\n")
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "1622541600 testlog2")
+}
+
+// StartAtTimestampNewFile tests that files created after
+// startup are read from the beginning
+func TestStartAtTimestampNewFile(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.StartAt = "2021-06-01T10:00:00Z"
+		cfg.StartAtTime = &StartAtTimeConfig{
+			Regex:    `^(\S+ \S+)`,
+			Layout:   "%Y-%m-%d %H:%M:%S",
+			Location: "UTC",
+		}
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	operator.poll(context.Background())
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "2021-06-01 09:00:00 testlog1\n")
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "2021-06-01 09:00:00 testlog1")
+}
@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	followSymlinksNever       = "never"
+	followSymlinksFiles       = "files"
+	followSymlinksDirectories = "directories"
+	followSymlinksAll         = "all"
+)
+
+func followsSymlinkedFiles(policy string) bool {
+	return policy == followSymlinksFiles || policy == followSymlinksAll
+}
+
+func followsSymlinkedDirs(policy string) bool {
+	return policy == followSymlinksDirectories || policy == followSymlinksAll
+}
+
+// filterSymlinks removes the matches of the include pattern which are not
+// allowed by the symlink policy
+func filterSymlinks(include string, matches []string, policy string) []string {
+	if policy == followSymlinksAll {
+		return matches
+	}
+
+	root, _ := watchRoot(filepath.Clean(include))
+	filtered := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if !followsSymlinkedFiles(policy) && isSymlink(match) {
+			continue
+		}
+		if !followsSymlinkedDirs(policy) && hasSymlinkedDir(root, match) {
+			continue
+		}
+		filtered = append(filtered, match)
+	}
+	return filtered
+}
+
+// hasSymlinkedDir returns true if any directory between the root and
+// the file is a symlink. The root itself may be a symlink.
+func hasSymlinkedDir(root, path string) bool {
+	dir := filepath.Dir(path)
+	for dir != root && strings.HasPrefix(dir, root) {
+		if isSymlink(dir) {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return false
+}
+
+func isSymlink(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
+
+// dedupSymlinkTargets removes paths which resolve to the same file as an earlier path,
+// so that a file which is reachable through multiple symlinks is only read once
+func dedupSymlinkTargets(paths []string) []string {
+	targets := make(map[string]struct{}, len(paths))
+	deduped := make([]string, 0, len(paths))
+	for _, path := range paths {
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			// The file may have been removed since it was matched
+			continue
+		}
+		if abs, err := filepath.Abs(target); err == nil {
+			target = abs
+		}
+		if _, ok := targets[target]; ok {
+			continue
+		}
+		targets[target] = struct{}{}
+		deduped = append(deduped, path)
+	}
+	return deduped
+}
@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// newSymlinkTree creates a `logs` directory containing a regular file and a
+// symlinked file in `dir`, and a symlinked directory `linkdir`. The symlinks
+// point to files outside of the `logs` directory.
+func newSymlinkTree(t *testing.T) (tempDir string, regular, fileLink, dirLink string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Creating symlinks requires elevated privileges on Windows")
+	}
+
+	tempDir = testutil.NewTempDir(t)
+	target := filepath.Join(tempDir, "target")
+	require.NoError(t, os.MkdirAll(target, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(target, "file.log"), []byte("testlog1\n"), 0600))
+	targetDir := filepath.Join(tempDir, "targetdir")
+	require.NoError(t, os.MkdirAll(targetDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(targetDir, "other.log"), []byte("testlog2\n"), 0600))
+
+	logs := filepath.Join(tempDir, "logs")
+	require.NoError(t, os.MkdirAll(filepath.Join(logs, "dir"), 0755))
+	regular = filepath.Join(logs, "dir", "regular.log")
+	require.NoError(t, ioutil.WriteFile(regular, []byte("testlog3\n"), 0600))
+	fileLink = filepath.Join(logs, "dir", "link.log")
+	require.NoError(t, os.Symlink(filepath.Join(target, "file.log"), fileLink))
+	require.NoError(t, os.Symlink(targetDir, filepath.Join(logs, "linkdir")))
+	dirLink = filepath.Join(logs, "linkdir", "other.log")
+
+	return tempDir, regular, fileLink, dirLink
+}
+
+func TestFollowSymlinksPolicy(t *testing.T) {
+	cases := []struct {
+		policy       string
+		expectFile   bool
+		expectDirLog bool
+	}{
+		{followSymlinksNever, false, false},
+		{followSymlinksFiles, true, false},
+		{followSymlinksDirectories, false, true},
+		{followSymlinksAll, true, true},
+	}
+
+	for _, tc := range cases {
+		for _, pattern := range []string{"*/*.log", "**/*.log"} {
+			t.Run(tc.policy+pattern, func(t *testing.T) {
+				tempDir, regular, fileLink, dirLink := newSymlinkTree(t)
+				include := filepath.Join(tempDir, "logs", pattern)
+
+				expected := []string{regular}
+				if tc.expectFile {
+					expected = append(expected, fileLink)
+				}
+				if tc.expectDirLog {
+					expected = append(expected, dirLink)
+				}
+
+				matches := getMatches([]string{include}, nil, tc.policy)
+				require.ElementsMatch(t, expected, matches)
+			})
+		}
+	}
+}
+
+// FollowSymlinksDedup tests that a file which is reachable through both
+// a symlink and its real path is only matched once
+func TestFollowSymlinksDedup(t *testing.T) {
+	tempDir, _, fileLink, _ := newSymlinkTree(t)
+	realPath := filepath.Join(tempDir, "target", "file.log")
+
+	includes := []string{fileLink, filepath.Join(tempDir, "target", "*.log")}
+	matches := getMatches(includes, nil, followSymlinksAll)
+	require.Equal(t, []string{fileLink}, matches)
+	require.NotContains(t, matches, realPath)
+}
+
+// FollowSymlinksLoop tests that a symlink to a parent directory
+// does not cause the directory tree to be walked forever
+func TestFollowSymlinksLoop(t *testing.T) {
+	tempDir, regular, _, _ := newSymlinkTree(t)
+	require.NoError(t, os.Symlink(filepath.Join(tempDir, "logs"), filepath.Join(tempDir, "logs", "dir", "parent")))
+
+	include := filepath.Join(tempDir, "logs", "**", "regular.log")
+	matches := getMatches([]string{include}, nil, followSymlinksAll)
+	require.Equal(t, []string{regular}, matches)
+}
+
+func TestFollowSymlinksReadOnce(t *testing.T) {
+	tempDir, _, _, _ := newSymlinkTree(t)
+	operator, logReceived, _ := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Include = []string{
+			filepath.Join(tempDir, "logs", "**", "*.log"),
+			filepath.Join(tempDir, "target", "*.log"),
+			filepath.Join(tempDir, "targetdir", "*.log"),
+		}
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+
+	operator.poll(context.Background())
+	waitForMessages(t, logReceived, []string{"testlog1", "testlog2", "testlog3"})
+	expectNoMessages(t, logReceived)
+}
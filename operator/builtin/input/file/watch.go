@@ -0,0 +1,160 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v3"
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+const (
+	watchModePoll   = "poll"
+	watchModeNotify = "notify"
+)
+
+// notifyDebounce is the time to wait after a notification before polling,
+// so that a burst of notifications results in a single poll
+const notifyDebounce = 20 * time.Millisecond
+
+// startWatcher starts a goroutine which watches the directories of the include
+// patterns, and signals the returned channel when a matching file changes
+func (f *InputOperator) startWatcher(ctx context.Context) (<-chan struct{}, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	recursiveRoots := make([]string, 0, len(f.Include))
+	for _, include := range f.Include {
+		root, recursive := watchRoot(include)
+		if recursive {
+			recursiveRoots = append(recursiveRoots, root)
+		}
+		f.addWatch(watcher, root, recursive)
+	}
+
+	notify := make(chan struct{}, 1)
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		defer watcher.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				// Watch new directories which may contain matching files
+				if event.Op&fsnotify.Create != 0 && isUnderAny(event.Name, recursiveRoots) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						f.addWatch(watcher, event.Name, true)
+						notifyNonBlocking(notify)
+						continue
+					}
+				}
+
+				if f.isIncluded(event.Name) {
+					notifyNonBlocking(notify)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				f.Warnw("File watcher error", zap.Error(err))
+			}
+		}
+	}()
+
+	return notify, nil
+}
+
+// addWatch adds a watch for the directory, and optionally all of its subdirectories.
+// Directories which can not be watched are still discovered by polling.
+func (f *InputOperator) addWatch(watcher *fsnotify.Watcher, dir string, recursive bool) {
+	if !recursive {
+		if err := watcher.Add(dir); err != nil {
+			f.Warnw("Failed to watch directory. Falling back to polling", "path", dir, zap.Error(err))
+		}
+		return
+	}
+
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if err := watcher.Add(path); err != nil {
+			f.Warnw("Failed to watch directory. Falling back to polling", "path", path, zap.Error(err))
+		}
+		return nil
+	})
+}
+
+// isIncluded returns true if the path matches an include pattern and no exclude patterns
+func (f *InputOperator) isIncluded(path string) bool {
+	for _, exclude := range f.Exclude {
+		if matches, _ := doublestar.PathMatch(exclude, path); matches {
+			return false
+		}
+	}
+	for _, include := range f.Include {
+		if matches, _ := doublestar.PathMatch(include, path); matches {
+			return true
+		}
+	}
+	return false
+}
+
+// watchRoot returns the deepest directory of the pattern that does not contain
+// glob characters, and whether the pattern can match files in its subdirectories
+func watchRoot(pattern string) (string, bool) {
+	root := filepath.Dir(pattern)
+	recursive := false
+	for hasGlobMeta(root) {
+		root = filepath.Dir(root)
+		recursive = true
+	}
+	return root, recursive
+}
+
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[{")
+}
+
+func isUnderAny(path string, roots []string) bool {
+	for _, root := range roots {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+func notifyNonBlocking(notify chan<- struct{}) {
+	select {
+	case notify <- struct{}{}:
+	default:
+	}
+}
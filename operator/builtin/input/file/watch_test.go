@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestWatchRoot(t *testing.T) {
+	cases := []struct {
+		pattern           string
+		expectedRoot      string
+		expectedRecursive bool
+	}{
+		{filepath.Join("var", "log", "*.log"), filepath.Join("var", "log"), false},
+		{filepath.Join("var", "log", "app.log"), filepath.Join("var", "log"), false},
+		{filepath.Join("var", "log", "*", "*.log"), filepath.Join("var", "log"), true},
+		{filepath.Join("var", "log", "**", "*.log"), filepath.Join("var", "log"), true},
+		{filepath.Join("var", "*", "app", "*.log"), "var", true},
+		{"*.log", ".", false},
+	}
+
+	for _, tc := range cases {
+		root, recursive := watchRoot(tc.pattern)
+		require.Equal(t, tc.expectedRoot, root, tc.pattern)
+		require.Equal(t, tc.expectedRecursive, recursive, tc.pattern)
+	}
+}
+
+// NotifyNewFile tests that a file created after startup is read
+// without waiting for the poll interval in notify watch mode
+func TestNotifyNewFile(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.WatchMode = "notify"
+		cfg.PollInterval.Duration = time.Hour
+	}, nil)
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+	waitForMessage(t, logReceived, "testlog1")
+
+	writeString(t, temp, "testlog2\n")
+	waitForMessage(t, logReceived, "testlog2")
+}
+
+// NotifyNewDirectory tests that files in a directory created after
+// startup are read when the include pattern matches subdirectories
+func TestNotifyNewDirectory(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.WatchMode = "notify"
+		cfg.PollInterval.Duration = time.Hour
+	}, nil)
+	operator.Include = []string{fmt.Sprintf("%s/*/*.log", tempDir)}
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	subDir := filepath.Join(tempDir, "subdir")
+	require.NoError(t, os.Mkdir(subDir, 0755))
+
+	// Give the watcher a chance to watch the new directory
+	time.Sleep(100 * time.Millisecond)
+
+	temp := openFile(t, filepath.Join(subDir, "app.log"))
+	writeString(t, temp, "testlog1\n")
+	waitForMessage(t, logReceived, "testlog1")
+}
@@ -0,0 +1,298 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentforward
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"time"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// eventTimeType is the msgpack extension type of an EventTime
+const eventTimeType = 0
+
+// message is a decoded forward protocol message, which contains the
+// events of a single tag, and a chunk ID if the client expects an ack
+type message struct {
+	tag    string
+	events []event
+	chunk  string
+}
+
+type event struct {
+	timestamp time.Time
+	record    map[string]interface{}
+}
+
+// eventTime is the EventTime msgpack extension, which holds
+// a timestamp as seconds and nanoseconds since the epoch
+type eventTime struct {
+	time.Time
+}
+
+func (t *eventTime) ExtensionType() int8 { return eventTimeType }
+
+func (t *eventTime) Len() int { return 8 }
+
+func (t *eventTime) MarshalBinaryTo(b []byte) error {
+	binary.BigEndian.PutUint32(b[:4], uint32(t.Unix()))
+	binary.BigEndian.PutUint32(b[4:], uint32(t.Nanosecond()))
+	return nil
+}
+
+func (t *eventTime) UnmarshalBinary(b []byte) error {
+	if len(b) != 8 {
+		return fmt.Errorf("invalid EventTime length %d", len(b))
+	}
+	sec := binary.BigEndian.Uint32(b[:4])
+	nsec := binary.BigEndian.Uint32(b[4:])
+	t.Time = time.Unix(int64(sec), int64(nsec))
+	return nil
+}
+
+// readMessage reads a message in any of the forward protocol's
+// Message, Forward, PackedForward, or CompressedPackedForward modes
+func readMessage(r *msgp.Reader) (*message, error) {
+	size, err := r.ReadArrayHeader()
+	if err != nil {
+		return nil, err
+	}
+	if size < 2 || size > 4 {
+		return nil, fmt.Errorf("invalid message of %d elements", size)
+	}
+
+	msg := &message{}
+	msg.tag, err = r.ReadString()
+	if err != nil {
+		return nil, fmt.Errorf("read tag: %s", err)
+	}
+
+	typ, err := r.NextType()
+	if err != nil {
+		return nil, err
+	}
+
+	var packed []byte
+	remaining := size - 2
+	switch typ {
+	case msgp.ArrayType:
+		// Forward mode
+		count, err := r.ReadArrayHeader()
+		if err != nil {
+			return nil, err
+		}
+		msg.events = make([]event, 0, count)
+		for i := uint32(0); i < count; i++ {
+			e, err := readEntry(r)
+			if err != nil {
+				return nil, err
+			}
+			msg.events = append(msg.events, e)
+		}
+	case msgp.BinType:
+		// PackedForward mode
+		packed, err = r.ReadBytes(nil)
+		if err != nil {
+			return nil, err
+		}
+	case msgp.StrType:
+		// PackedForward mode, from clients which send the entries as a string
+		packed, err = r.ReadStringAsBytes(nil)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		// Message mode
+		if remaining == 0 {
+			return nil, fmt.Errorf("message is missing its record")
+		}
+		e := event{}
+		e.timestamp, err = readEventTime(r)
+		if err != nil {
+			return nil, err
+		}
+		e.record, err = readRecord(r)
+		if err != nil {
+			return nil, err
+		}
+		msg.events = []event{e}
+		remaining--
+	}
+
+	var compressed string
+	if remaining > 0 {
+		msg.chunk, compressed, err = readOptions(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if packed != nil {
+		msg.events, err = readPackedEntries(packed, compressed)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+// readPackedEntries reads the entries of a PackedForward
+// or CompressedPackedForward message
+func readPackedEntries(packed []byte, compressed string) ([]event, error) {
+	switch compressed {
+	case "":
+	case "gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(packed))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		packed, err = ioutil.ReadAll(gz)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported compression '%s'", compressed)
+	}
+
+	events := make([]event, 0)
+	r := msgp.NewReader(bytes.NewReader(packed))
+	for {
+		e, err := readEntry(r)
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+}
+
+// readEntry reads an entry of the form [time, record]
+func readEntry(r *msgp.Reader) (event, error) {
+	size, err := r.ReadArrayHeader()
+	if err != nil {
+		return event{}, err
+	}
+	if size != 2 {
+		return event{}, fmt.Errorf("invalid entry of %d elements", size)
+	}
+
+	timestamp, err := readEventTime(r)
+	if err != nil {
+		return event{}, err
+	}
+	record, err := readRecord(r)
+	if err != nil {
+		return event{}, err
+	}
+	return event{timestamp: timestamp, record: record}, nil
+}
+
+// readEventTime reads a timestamp, which is either an integer number
+// of seconds since the epoch, or an EventTime
+func readEventTime(r *msgp.Reader) (time.Time, error) {
+	typ, err := r.NextType()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch typ {
+	case msgp.IntType, msgp.UintType:
+		sec, err := r.ReadInt64()
+		return time.Unix(sec, 0), err
+	case msgp.Float32Type, msgp.Float64Type:
+		f, err := r.ReadFloat64()
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)), err
+	case msgp.ExtensionType:
+		t := &eventTime{}
+		err := r.ReadExtension(t)
+		return t.Time, err
+	default:
+		return time.Time{}, fmt.Errorf("invalid time of type %s", typ)
+	}
+}
+
+// readRecord reads a record, converting any binary values to strings,
+// since clients may send strings as binary
+func readRecord(r *msgp.Reader) (map[string]interface{}, error) {
+	value, err := r.ReadIntf()
+	if err != nil {
+		return nil, err
+	}
+	record, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid record of type %T", value)
+	}
+	return binaryToString(record).(map[string]interface{}), nil
+}
+
+func binaryToString(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case map[string]interface{}:
+		for key, nested := range v {
+			v[key] = binaryToString(nested)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = binaryToString(nested)
+		}
+	}
+	return value
+}
+
+// readOptions reads the chunk ID and compression from the options of a message
+func readOptions(r *msgp.Reader) (chunk string, compressed string, err error) {
+	value, err := r.ReadIntf()
+	if err != nil {
+		return "", "", err
+	}
+	if value == nil {
+		return "", "", nil
+	}
+	options, ok := value.(map[string]interface{})
+	if !ok {
+		return "", "", fmt.Errorf("invalid options of type %T", value)
+	}
+
+	chunk, _ = binaryToString(options["chunk"]).(string)
+	compressed, _ = binaryToString(options["compressed"]).(string)
+	return chunk, compressed, nil
+}
+
+// writeAck acknowledges the message with the chunk ID
+func writeAck(w *msgp.Writer, chunk string) error {
+	if err := w.WriteMapHeader(1); err != nil {
+		return err
+	}
+	if err := w.WriteString("ack"); err != nil {
+		return err
+	}
+	if err := w.WriteString(chunk); err != nil {
+		return err
+	}
+	return w.Flush()
+}
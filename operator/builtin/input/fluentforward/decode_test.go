@@ -0,0 +1,231 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentforward
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// encode builds a msgpack payload with the given writer calls
+func encode(t *testing.T, write func(w *msgp.Writer)) []byte {
+	var buf bytes.Buffer
+	w := msgp.NewWriter(&buf)
+	write(w)
+	require.NoError(t, w.Flush())
+	return buf.Bytes()
+}
+
+func writeEntry(w *msgp.Writer, ts interface{}, record map[string]interface{}) {
+	_ = w.WriteArrayHeader(2)
+	switch v := ts.(type) {
+	case time.Time:
+		_ = w.WriteExtension(&eventTime{v})
+	default:
+		_ = w.WriteIntf(v)
+	}
+	_ = w.WriteMapStrIntf(record)
+}
+
+func TestReadMessage(t *testing.T) {
+	ts := time.Unix(1600000000, 123456789)
+	record := map[string]interface{}{"message": "hello"}
+
+	packed := func(t *testing.T) []byte {
+		return encode(t, func(w *msgp.Writer) {
+			writeEntry(w, ts, record)
+			writeEntry(w, ts, record)
+		})
+	}
+
+	cases := []struct {
+		name      string
+		payload   func(t *testing.T) []byte
+		expected  *message
+		expectErr bool
+	}{
+		{
+			"Message",
+			func(t *testing.T) []byte {
+				return encode(t, func(w *msgp.Writer) {
+					_ = w.WriteArrayHeader(3)
+					_ = w.WriteString("app")
+					_ = w.WriteExtension(&eventTime{ts})
+					_ = w.WriteMapStrIntf(record)
+				})
+			},
+			&message{tag: "app", events: []event{{ts, record}}},
+			false,
+		},
+		{
+			"MessageIntegerTime",
+			func(t *testing.T) []byte {
+				return encode(t, func(w *msgp.Writer) {
+					_ = w.WriteArrayHeader(3)
+					_ = w.WriteString("app")
+					_ = w.WriteInt64(1600000000)
+					_ = w.WriteMapStrIntf(record)
+				})
+			},
+			&message{tag: "app", events: []event{{time.Unix(1600000000, 0), record}}},
+			false,
+		},
+		{
+			"MessageWithChunk",
+			func(t *testing.T) []byte {
+				return encode(t, func(w *msgp.Writer) {
+					_ = w.WriteArrayHeader(4)
+					_ = w.WriteString("app")
+					_ = w.WriteExtension(&eventTime{ts})
+					_ = w.WriteMapStrIntf(record)
+					_ = w.WriteMapStrIntf(map[string]interface{}{"chunk": "abc"})
+				})
+			},
+			&message{tag: "app", events: []event{{ts, record}}, chunk: "abc"},
+			false,
+		},
+		{
+			"MessageBinaryValues",
+			func(t *testing.T) []byte {
+				return encode(t, func(w *msgp.Writer) {
+					_ = w.WriteArrayHeader(3)
+					_ = w.WriteString("app")
+					_ = w.WriteExtension(&eventTime{ts})
+					_ = w.WriteMapStrIntf(map[string]interface{}{"message": []byte("hello")})
+				})
+			},
+			&message{tag: "app", events: []event{{ts, record}}},
+			false,
+		},
+		{
+			"Forward",
+			func(t *testing.T) []byte {
+				return encode(t, func(w *msgp.Writer) {
+					_ = w.WriteArrayHeader(2)
+					_ = w.WriteString("app")
+					_ = w.WriteArrayHeader(2)
+					writeEntry(w, ts, record)
+					writeEntry(w, int64(1600000000), record)
+				})
+			},
+			&message{tag: "app", events: []event{{ts, record}, {time.Unix(1600000000, 0), record}}},
+			false,
+		},
+		{
+			"PackedForward",
+			func(t *testing.T) []byte {
+				return encode(t, func(w *msgp.Writer) {
+					_ = w.WriteArrayHeader(3)
+					_ = w.WriteString("app")
+					_ = w.WriteBytes(packed(t))
+					_ = w.WriteMapStrIntf(map[string]interface{}{"chunk": "abc"})
+				})
+			},
+			&message{tag: "app", events: []event{{ts, record}, {ts, record}}, chunk: "abc"},
+			false,
+		},
+		{
+			"CompressedPackedForward",
+			func(t *testing.T) []byte {
+				var buf bytes.Buffer
+				gz := gzip.NewWriter(&buf)
+				_, err := gz.Write(packed(t))
+				require.NoError(t, err)
+				require.NoError(t, gz.Close())
+
+				return encode(t, func(w *msgp.Writer) {
+					_ = w.WriteArrayHeader(3)
+					_ = w.WriteString("app")
+					_ = w.WriteBytes(buf.Bytes())
+					_ = w.WriteMapStrIntf(map[string]interface{}{"compressed": "gzip"})
+				})
+			},
+			&message{tag: "app", events: []event{{ts, record}, {ts, record}}},
+			false,
+		},
+		{
+			"UnsupportedCompression",
+			func(t *testing.T) []byte {
+				return encode(t, func(w *msgp.Writer) {
+					_ = w.WriteArrayHeader(3)
+					_ = w.WriteString("app")
+					_ = w.WriteBytes(packed(t))
+					_ = w.WriteMapStrIntf(map[string]interface{}{"compressed": "zstd"})
+				})
+			},
+			nil,
+			true,
+		},
+		{
+			"NotAnArray",
+			func(t *testing.T) []byte {
+				return encode(t, func(w *msgp.Writer) {
+					_ = w.WriteString("app")
+				})
+			},
+			nil,
+			true,
+		},
+		{
+			"MissingRecord",
+			func(t *testing.T) []byte {
+				return encode(t, func(w *msgp.Writer) {
+					_ = w.WriteArrayHeader(2)
+					_ = w.WriteString("app")
+					_ = w.WriteInt64(1600000000)
+				})
+			},
+			nil,
+			true,
+		},
+		{
+			"RecordNotAMap",
+			func(t *testing.T) []byte {
+				return encode(t, func(w *msgp.Writer) {
+					_ = w.WriteArrayHeader(3)
+					_ = w.WriteString("app")
+					_ = w.WriteInt64(1600000000)
+					_ = w.WriteString("hello")
+				})
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := msgp.NewReader(bytes.NewReader(tc.payload(t)))
+			msg, err := readMessage(r)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected.tag, msg.tag)
+			require.Equal(t, tc.expected.chunk, msg.chunk)
+			require.Len(t, msg.events, len(tc.expected.events))
+			for i, e := range tc.expected.events {
+				require.True(t, e.timestamp.Equal(msg.events[i].timestamp))
+				require.Equal(t, e.record, msg.events[i].record)
+			}
+		})
+	}
+}
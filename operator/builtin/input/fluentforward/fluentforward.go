@@ -0,0 +1,201 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentforward
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jpillora/backoff"
+	"github.com/tinylib/msgp/msgp"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// tagAttribute is the attribute which holds the tag of an event
+const tagAttribute = "fluent.tag"
+
+func init() {
+	operator.Register("fluent_forward_input", func() operator.Builder { return NewFluentForwardInputConfig("") })
+}
+
+// NewFluentForwardInputConfig creates a new fluent forward input config with default values
+func NewFluentForwardInputConfig(operatorID string) *FluentForwardInputConfig {
+	return &FluentForwardInputConfig{
+		InputConfig: helper.NewInputConfig(operatorID, "fluent_forward_input"),
+	}
+}
+
+// FluentForwardInputConfig is the configuration of a fluent forward input operator.
+type FluentForwardInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	ListenAddress string `mapstructure:"listen_address,omitempty" json:"listen_address,omitempty" yaml:"listen_address,omitempty"`
+}
+
+// Build will build a fluent forward input operator.
+func (c FluentForwardInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ListenAddress == "" {
+		return nil, fmt.Errorf("missing required parameter 'listen_address'")
+	}
+
+	// validate the input address
+	if _, err := net.ResolveTCPAddr("tcp", c.ListenAddress); err != nil {
+		return nil, fmt.Errorf("failed to resolve listen_address: %s", err)
+	}
+
+	fluentForwardInput := &FluentForwardInput{
+		InputOperator: inputOperator,
+		address:       c.ListenAddress,
+		backoff: backoff.Backoff{
+			Max: 3 * time.Second,
+		},
+	}
+
+	return []operator.Operator{fluentForwardInput}, nil
+}
+
+// FluentForwardInput is an operator that receives events with the fluentd forward protocol.
+type FluentForwardInput struct {
+	helper.InputOperator
+	address string
+
+	listener net.Listener
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	backoff  backoff.Backoff
+}
+
+// Start will start listening for forward protocol connections.
+func (f *FluentForwardInput) Start(_ operator.Persister) error {
+	listener, err := net.Listen("tcp", f.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on interface: %w", err)
+	}
+	f.listener = listener
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+	f.goListen(ctx)
+	return nil
+}
+
+// goListen will listen for forward protocol connections.
+func (f *FluentForwardInput) goListen(ctx context.Context) {
+	f.wg.Add(1)
+
+	go func() {
+		defer f.wg.Done()
+
+		for {
+			conn, err := f.listener.Accept()
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+					f.Debugw("Listener accept error", zap.Error(err))
+					time.Sleep(f.backoff.Duration())
+					continue
+				}
+			}
+			f.backoff.Reset()
+
+			f.Debugf("Received connection: %s", conn.RemoteAddr().String())
+			subctx, cancel := context.WithCancel(ctx)
+			f.goHandleClose(subctx, conn)
+			f.goHandleMessages(subctx, conn, cancel)
+		}
+	}()
+}
+
+// goHandleClose will wait for the context to finish before closing a connection.
+func (f *FluentForwardInput) goHandleClose(ctx context.Context, conn net.Conn) {
+	f.wg.Add(1)
+
+	go func() {
+		defer f.wg.Done()
+		<-ctx.Done()
+		f.Debugf("Closing connection: %s", conn.RemoteAddr().String())
+		if err := conn.Close(); err != nil {
+			f.Errorf("Failed to close connection: %s", err)
+		}
+	}()
+}
+
+// goHandleMessages will handle messages from a forward protocol connection.
+// A message which can not be decoded closes the connection, since the
+// beginning of the next message can not be found.
+func (f *FluentForwardInput) goHandleMessages(ctx context.Context, conn net.Conn, cancel context.CancelFunc) {
+	f.wg.Add(1)
+
+	go func() {
+		defer f.wg.Done()
+		defer cancel()
+
+		reader := msgp.NewReader(conn)
+		writer := msgp.NewWriter(conn)
+		for {
+			msg, err := readMessage(reader)
+			if err != nil {
+				if err != io.EOF && ctx.Err() == nil {
+					f.Errorw("Failed to decode message", zap.Error(err))
+				}
+				return
+			}
+
+			for _, e := range msg.events {
+				entry, err := f.NewEntry(e.record)
+				if err != nil {
+					f.Errorw("Failed to create entry", zap.Error(err))
+					continue
+				}
+				entry.Timestamp = e.timestamp
+				entry.AddAttribute(tagAttribute, msg.tag)
+				f.Write(ctx, entry)
+			}
+
+			if msg.chunk != "" {
+				if err := writeAck(writer, msg.chunk); err != nil {
+					f.Errorw("Failed to acknowledge message", zap.Error(err))
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Stop will stop listening for forward protocol connections.
+func (f *FluentForwardInput) Stop() error {
+	f.cancel()
+
+	if err := f.listener.Close(); err != nil {
+		return err
+	}
+
+	f.wg.Wait()
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentforward
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestFluentForwardInput(t *testing.T) (*testutil.FakeOutput, net.Conn) {
+	cfg := NewFluentForwardInputConfig("test_id")
+	cfg.ListenAddress = "127.0.0.1:0"
+	cfg.OutputIDs = []string{"fake"}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	input := ops[0].(*FluentForwardInput)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, input.SetOutputs([]operator.Operator{fakeOutput}))
+
+	require.NoError(t, input.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() { require.NoError(t, input.Stop()) })
+
+	conn, err := net.Dial("tcp", input.listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return fakeOutput, conn
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*FluentForwardInputConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *FluentForwardInputConfig) {},
+			false,
+		},
+		{
+			"MissingListenAddress",
+			func(cfg *FluentForwardInputConfig) {
+				cfg.ListenAddress = ""
+			},
+			true,
+		},
+		{
+			"InvalidListenAddress",
+			func(cfg *FluentForwardInputConfig) {
+				cfg.ListenAddress = "missing-port"
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewFluentForwardInputConfig("test_id")
+			cfg.ListenAddress = "127.0.0.1:24224"
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFluentForwardInput(t *testing.T) {
+	fakeOutput, conn := newTestFluentForwardInput(t)
+	ts := time.Unix(1600000000, 123456789)
+
+	_, err := conn.Write(encode(t, func(w *msgp.Writer) {
+		_ = w.WriteArrayHeader(2)
+		_ = w.WriteString("app.access")
+		_ = w.WriteArrayHeader(2)
+		writeEntry(w, ts, map[string]interface{}{"message": "first"})
+		writeEntry(w, ts, map[string]interface{}{"message": "second"})
+	}))
+	require.NoError(t, err)
+
+	for _, expected := range []string{"first", "second"} {
+		select {
+		case e := <-fakeOutput.Received:
+			require.Equal(t, map[string]interface{}{"message": expected}, e.Body)
+			require.Equal(t, map[string]string{"fluent.tag": "app.access"}, e.Attributes)
+			require.True(t, ts.Equal(e.Timestamp))
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for entry")
+		}
+	}
+}
+
+func TestFluentForwardInputAck(t *testing.T) {
+	fakeOutput, conn := newTestFluentForwardInput(t)
+
+	_, err := conn.Write(encode(t, func(w *msgp.Writer) {
+		_ = w.WriteArrayHeader(4)
+		_ = w.WriteString("app")
+		_ = w.WriteInt64(1600000000)
+		_ = w.WriteMapStrIntf(map[string]interface{}{"message": "hello"})
+		_ = w.WriteMapStrIntf(map[string]interface{}{"chunk": "abc"})
+	}))
+	require.NoError(t, err)
+	fakeOutput.ExpectBody(t, map[string]interface{}{"message": "hello"})
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	ack := map[string]interface{}{}
+	require.NoError(t, msgp.NewReader(conn).ReadMapStrIntf(ack))
+	require.Equal(t, map[string]interface{}{"ack": "abc"}, ack)
+}
+
+func TestFluentForwardInputInvalidMessage(t *testing.T) {
+	_, conn := newTestFluentForwardInput(t)
+
+	_, err := conn.Write(encode(t, func(w *msgp.Writer) {
+		_ = w.WriteString("invalid")
+	}))
+	require.NoError(t, err)
+
+	// the connection is closed since the stream can not be recovered
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	require.Equal(t, io.EOF, err)
+}
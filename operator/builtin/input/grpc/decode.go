@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/input/internal/grpcwire"
+)
+
+// The field numbers of the messages defined in logs.proto.
+// Unknown fields are skipped, as protobuf requires.
+const (
+	batchSequence = 1
+	batchRecords  = 2
+
+	recordTimeUnixNano = 1
+	recordSeverityText = 2
+	recordBody         = 3
+	recordAttributes   = 4
+
+	mapEntryKey   = 1
+	mapEntryValue = 2
+
+	ackSequence = 1
+)
+
+// logBatch is a decoded LogBatch
+type logBatch struct {
+	sequence uint64
+	records  []*logRecord
+}
+
+// logRecord is a decoded LogRecord, whose body has not yet been decoded
+type logRecord struct {
+	timestamp    time.Time
+	severityText string
+	body         []byte
+	attributes   map[string]string
+}
+
+// decodeBatch decodes a LogBatch
+func decodeBatch(b []byte) (*logBatch, error) {
+	batch := &logBatch{}
+	err := grpcwire.ForEachField(b, func(f grpcwire.Field) error {
+		switch f.Num {
+		case batchSequence:
+			batch.sequence = f.Value
+		case batchRecords:
+			record, err := decodeRecord(f.Bytes)
+			if err != nil {
+				return fmt.Errorf("records: %s", err)
+			}
+			batch.records = append(batch.records, record)
+		}
+		return nil
+	})
+	return batch, err
+}
+
+func decodeRecord(b []byte) (*logRecord, error) {
+	record := &logRecord{}
+	err := grpcwire.ForEachField(b, func(f grpcwire.Field) error {
+		switch f.Num {
+		case recordTimeUnixNano:
+			if f.Value != 0 {
+				record.timestamp = time.Unix(0, int64(f.Value))
+			}
+		case recordSeverityText:
+			record.severityText = string(f.Bytes)
+		case recordBody:
+			record.body = f.Bytes
+		case recordAttributes:
+			if record.attributes == nil {
+				record.attributes = make(map[string]string)
+			}
+			return decodeMapEntry(f.Bytes, record.attributes)
+		}
+		return nil
+	})
+	return record, err
+}
+
+// decodeMapEntry decodes an entry of a map<string, string> field into m
+func decodeMapEntry(b []byte, m map[string]string) error {
+	var key, value string
+	err := grpcwire.ForEachField(b, func(f grpcwire.Field) error {
+		switch f.Num {
+		case mapEntryKey:
+			key = string(f.Bytes)
+		case mapEntryValue:
+			value = string(f.Bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	m[key] = value
+	return nil
+}
+
+// encodeAck encodes the LogBatchAck of a batch
+func encodeAck(sequence uint64) []byte {
+	if sequence == 0 {
+		return nil
+	}
+	b := protowire.AppendTag(nil, ackSequence, protowire.VarintType)
+	return protowire.AppendVarint(b, sequence)
+}
@@ -0,0 +1,415 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/input/internal/grpcwire"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// DefaultMaxMessageSize is the max size of a log batch
+	// if MaxMessageSize is not set
+	DefaultMaxMessageSize = 4 * 1024 * 1024
+
+	// streamPath is the path of the LogService Stream method
+	streamPath = "/opentelemetry.logcollection.v1.LogService/Stream"
+
+	// handshakeTimeout is how long a TLS handshake may take
+	handshakeTimeout = 10 * time.Second
+
+	bodyFormatText  = "text"
+	bodyFormatJSON  = "json"
+	bodyFormatBytes = "bytes"
+)
+
+func init() {
+	operator.Register("grpc_input", func() operator.Builder { return NewGRPCInputConfig("") })
+}
+
+// NewGRPCInputConfig creates a new gRPC input config with default values
+func NewGRPCInputConfig(operatorID string) *GRPCInputConfig {
+	return &GRPCInputConfig{
+		InputConfig:    helper.NewInputConfig(operatorID, "grpc_input"),
+		MaxMessageSize: DefaultMaxMessageSize,
+		BodyFormat:     bodyFormatText,
+		Encoding:       helper.NewEncodingConfig(),
+	}
+}
+
+// GRPCInputConfig is the configuration of a gRPC input operator.
+type GRPCInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	ListenAddress    string                  `mapstructure:"listen_address,omitempty"    json:"listen_address,omitempty"    yaml:"listen_address,omitempty"`
+	TLS              *helper.TLSServerConfig `mapstructure:"tls,omitempty"               json:"tls,omitempty"               yaml:"tls,omitempty"`
+	MaxMessageSize   helper.ByteSize         `mapstructure:"max_message_size,omitempty"  json:"max_message_size,omitempty"  yaml:"max_message_size,omitempty"`
+	BodyFormat       string                  `mapstructure:"body_format,omitempty"       json:"body_format,omitempty"       yaml:"body_format,omitempty"`
+	Encoding         helper.EncodingConfig   `mapstructure:",squash,omitempty"           json:",inline,omitempty"           yaml:",inline,omitempty"`
+	MetadataResource map[string]string       `mapstructure:"metadata_resource,omitempty" json:"metadata_resource,omitempty" yaml:"metadata_resource,omitempty"`
+}
+
+// Build will build a gRPC input operator.
+func (c GRPCInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ListenAddress == "" {
+		return nil, fmt.Errorf("missing required parameter 'listen_address'")
+	}
+
+	// validate the input address
+	if _, err := net.ResolveTCPAddr("tcp", c.ListenAddress); err != nil {
+		return nil, fmt.Errorf("failed to resolve listen_address: %s", err)
+	}
+
+	if c.MaxMessageSize <= 0 {
+		return nil, fmt.Errorf("`max_message_size` must be positive")
+	}
+
+	encoding, err := c.Encoding.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	var decodeBody func([]byte) (interface{}, error)
+	switch c.BodyFormat {
+	case bodyFormatText, "":
+		decodeBody = func(b []byte) (interface{}, error) {
+			return encoding.Decode(b)
+		}
+	case bodyFormatJSON:
+		decodeBody = decodeJSONBody
+	case bodyFormatBytes:
+		decodeBody = func(b []byte) (interface{}, error) {
+			return append([]byte{}, b...), nil
+		}
+	default:
+		return nil, fmt.Errorf("invalid body_format '%s'", c.BodyFormat)
+	}
+
+	// gRPC metadata keys are case insensitive
+	metadataResource := make(map[string]string, len(c.MetadataResource))
+	for key, resourceKey := range c.MetadataResource {
+		if key == "" || resourceKey == "" {
+			return nil, fmt.Errorf("invalid metadata_resource '%s: %s'", key, resourceKey)
+		}
+		metadataResource[strings.ToLower(key)] = resourceKey
+	}
+
+	grpcInput := &GRPCInput{
+		InputOperator:    inputOperator,
+		address:          c.ListenAddress,
+		maxMessageSize:   int64(c.MaxMessageSize),
+		decodeBody:       decodeBody,
+		metadataResource: metadataResource,
+		server:           &http2.Server{},
+	}
+
+	if c.TLS != nil {
+		grpcInput.tls, err = c.TLS.LoadTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []operator.Operator{grpcInput}, nil
+}
+
+// decodeJSONBody decodes a body which contains a JSON value
+func decodeJSONBody(b []byte) (interface{}, error) {
+	var body interface{}
+	if err := json.Unmarshal(b, &body); err != nil {
+		return nil, fmt.Errorf("decode json body: %s", err)
+	}
+	return body, nil
+}
+
+// GRPCInput is an operator that receives log batches over gRPC streams.
+type GRPCInput struct {
+	helper.InputOperator
+	address          string
+	maxMessageSize   int64
+	decodeBody       func([]byte) (interface{}, error)
+	metadataResource map[string]string
+	tls              *tls.Config
+	server           *http2.Server
+
+	listener net.Listener
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	// conns and streams are tracked so that they can be closed,
+	// and waited for, when the operator is stopped
+	mux     sync.Mutex
+	stopped bool
+	conns   map[net.Conn]struct{}
+	streams sync.WaitGroup
+}
+
+// Start will start listening for log streams.
+func (g *GRPCInput) Start(_ operator.Persister) error {
+	listener, err := net.Listen("tcp", g.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on interface: %w", err)
+	}
+	if g.tls != nil {
+		config := g.tls.Clone()
+		config.NextProtos = []string{http2.NextProtoTLS}
+		listener = tls.NewListener(listener, config)
+	}
+	g.listener = listener
+	g.conns = make(map[net.Conn]struct{})
+	g.stopped = false
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g.cancel = cancel
+	g.goListen(ctx)
+	return nil
+}
+
+// goListen will accept connections, and serve each with HTTP/2.
+func (g *GRPCInput) goListen(ctx context.Context) {
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		for {
+			conn, err := g.listener.Accept()
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+					g.Debugw("Listener accept error", zap.Error(err))
+					time.Sleep(100 * time.Millisecond)
+					continue
+				}
+			}
+
+			if !g.trackConn(conn) {
+				conn.Close()
+				return
+			}
+			g.goServe(ctx, conn)
+		}
+	}()
+}
+
+// goServe will serve a connection. gRPC clients which do not use
+// TLS are expected to use HTTP/2 without an upgrade.
+func (g *GRPCInput) goServe(ctx context.Context, conn net.Conn) {
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+		defer g.untrackConn(conn)
+		defer conn.Close()
+
+		// The HTTP/2 server requires that the TLS handshake is complete
+		if tlsConn, ok := conn.(*tls.Conn); ok {
+			_ = tlsConn.SetDeadline(time.Now().Add(handshakeTimeout))
+			if err := tlsConn.Handshake(); err != nil {
+				g.Debugw("TLS handshake failed", zap.Error(err))
+				return
+			}
+			_ = tlsConn.SetDeadline(time.Time{})
+		}
+
+		g.server.ServeConn(conn, &http2.ServeConnOpts{
+			Context: ctx,
+			Handler: http.HandlerFunc(g.handleStream),
+		})
+	}()
+}
+
+func (g *GRPCInput) trackConn(conn net.Conn) bool {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	if g.stopped {
+		return false
+	}
+	g.conns[conn] = struct{}{}
+	return true
+}
+
+func (g *GRPCInput) untrackConn(conn net.Conn) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	delete(g.conns, conn)
+}
+
+// Stop will stop listening for log streams. Streams are closed, and
+// batches which have not been acknowledged must be sent again.
+func (g *GRPCInput) Stop() error {
+	g.cancel()
+	err := g.listener.Close()
+
+	g.mux.Lock()
+	g.stopped = true
+	for conn := range g.conns {
+		conn.Close()
+	}
+	g.mux.Unlock()
+
+	g.wg.Wait()
+	g.streams.Wait()
+	return err
+}
+
+// handleStream handles a LogService Stream call. Each batch is only read
+// once the logs of the previous batch have been written and acknowledged,
+// so HTTP/2 flow control slows clients which send faster than logs are written.
+func (g *GRPCInput) handleStream(w http.ResponseWriter, r *http.Request) {
+	if !grpcwire.IsGRPC(r) {
+		http.Error(w, "expected a gRPC request", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if r.URL.Path != streamPath {
+		grpcwire.WriteTrailersOnly(w, grpcwire.CodeUnimplemented, fmt.Sprintf("unknown method %s", r.URL.Path))
+		return
+	}
+
+	encoding := r.Header.Get("Grpc-Encoding")
+	if !grpcwire.SupportedEncoding(encoding) {
+		grpcwire.WriteTrailersOnly(w, grpcwire.CodeUnimplemented, fmt.Sprintf("unsupported grpc-encoding '%s'", encoding))
+		return
+	}
+
+	g.mux.Lock()
+	if g.stopped {
+		g.mux.Unlock()
+		grpcwire.WriteTrailersOnly(w, grpcwire.CodeUnavailable, "operator stopped")
+		return
+	}
+	g.streams.Add(1)
+	g.mux.Unlock()
+	defer g.streams.Done()
+
+	resource := g.streamResource(r.Header)
+
+	w.Header().Set("Content-Type", "application/grpc")
+	w.WriteHeader(http.StatusOK)
+	flusher := w.(http.Flusher)
+	flusher.Flush()
+
+	ctx := r.Context()
+	for {
+		message, code, err := grpcwire.ReadMessage(r.Body, encoding, g.maxMessageSize)
+		switch {
+		case err == io.EOF:
+			grpcwire.WriteStatus(w, grpcwire.CodeOK, "")
+			return
+		case ctx.Err() != nil:
+			grpcwire.WriteStatus(w, grpcwire.CodeUnavailable, "operator stopped")
+			return
+		case err != nil:
+			grpcwire.WriteStatus(w, code, err.Error())
+			return
+		}
+
+		batch, err := decodeBatch(message)
+		if err != nil {
+			grpcwire.WriteStatus(w, grpcwire.CodeInvalidArgument, fmt.Sprintf("decode log batch: %s", err))
+			return
+		}
+
+		// Every entry of the batch is created before any is written,
+		// so that a batch is either written in full or rejected
+		entries := make([]*entry.Entry, 0, len(batch.records))
+		for i, record := range batch.records {
+			e, err := g.newEntry(record, resource)
+			if err != nil {
+				grpcwire.WriteStatus(w, grpcwire.CodeInvalidArgument, fmt.Sprintf("record %d: %s", i, err))
+				return
+			}
+			entries = append(entries, e)
+		}
+
+		for _, e := range entries {
+			g.Write(ctx, e)
+		}
+
+		if _, err := w.Write(grpcwire.Frame(encodeAck(batch.sequence))); err != nil {
+			g.Debugw("Failed to acknowledge batch", zap.Error(err))
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// streamResource returns the resource keys configured for the metadata of a stream
+func (g *GRPCInput) streamResource(header http.Header) map[string]string {
+	resource := make(map[string]string, len(g.metadataResource))
+	for key, resourceKey := range g.metadataResource {
+		if value := header.Get(key); value != "" {
+			resource[resourceKey] = value
+		}
+	}
+	return resource
+}
+
+// newEntry creates an entry from a log record. The attributes and resource
+// configured on the operator are added to those of the log record and stream.
+func (g *GRPCInput) newEntry(record *logRecord, resource map[string]string) (*entry.Entry, error) {
+	body, err := g.decodeBody(record.body)
+	if err != nil {
+		return nil, err
+	}
+
+	e := entry.New()
+	if !record.timestamp.IsZero() {
+		e.Timestamp = record.timestamp
+	}
+	e.SeverityText = record.severityText
+
+	for key, value := range record.attributes {
+		e.AddAttribute(key, value)
+	}
+	for key, value := range resource {
+		e.AddResourceKey(key, value)
+	}
+
+	if err := e.Set(g.WriteTo, body); err != nil {
+		return nil, fmt.Errorf("add body to entry: %s", err)
+	}
+	if err := g.Attribute(e); err != nil {
+		return nil, fmt.Errorf("add attributes to entry: %s", err)
+	}
+	if err := g.Identify(e); err != nil {
+		return nil, fmt.Errorf("add resource keys to entry: %s", err)
+	}
+	return e, nil
+}
@@ -0,0 +1,612 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtls"
+	"golang.org/x/net/http2"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/input/internal/grpcwire"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestGRPCInput(t *testing.T, cfgMod func(*GRPCInputConfig), outputs ...operator.Operator) (*GRPCInput, *testutil.FakeOutput) {
+	cfg := NewGRPCInputConfig("test_id")
+	cfg.ListenAddress = "127.0.0.1:0"
+	cfg.OutputIDs = []string{"fake"}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	grpcInput := ops[0].(*GRPCInput)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	if len(outputs) == 0 {
+		outputs = []operator.Operator{fakeOutput}
+	}
+	require.NoError(t, grpcInput.SetOutputs(outputs))
+
+	require.NoError(t, grpcInput.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() { require.NoError(t, grpcInput.Stop()) })
+
+	return grpcInput, fakeOutput
+}
+
+// grpcClient is an HTTP/2 client which does not use TLS
+func grpcClient() *http.Client {
+	return &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+}
+
+// testStream is the client side of a Stream call
+type testStream struct {
+	t      *testing.T
+	body   *io.PipeWriter
+	resp   *http.Response
+	respCh chan *http.Response
+}
+
+func openStream(t *testing.T, client *http.Client, url string, header http.Header) *testStream {
+	reader, writer := io.Pipe()
+	req, err := http.NewRequest(http.MethodPost, url+streamPath, reader)
+	require.NoError(t, err)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	stream := &testStream{t: t, body: writer, respCh: make(chan *http.Response, 1)}
+	go func() {
+		resp, err := client.Do(req)
+		if err != nil {
+			writer.CloseWithError(err)
+			close(stream.respCh)
+			return
+		}
+		stream.respCh <- resp
+	}()
+	t.Cleanup(func() {
+		writer.Close()
+		if resp := stream.response(); resp != nil {
+			resp.Body.Close()
+		}
+	})
+	return stream
+}
+
+func (s *testStream) response() *http.Response {
+	if s.resp == nil {
+		select {
+		case resp := <-s.respCh:
+			s.resp = resp
+		case <-time.After(time.Second):
+			require.FailNow(s.t, "Timed out waiting for response")
+		}
+	}
+	return s.resp
+}
+
+func (s *testStream) send(message []byte) {
+	_, err := s.body.Write(grpcwire.Frame(message))
+	require.NoError(s.t, err)
+}
+
+// expectAck reads the next acknowledgement of the stream
+func (s *testStream) expectAck() uint64 {
+	prefix := make([]byte, 5)
+	_, err := io.ReadFull(s.response().Body, prefix)
+	require.NoError(s.t, err)
+	message := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+	_, err = io.ReadFull(s.resp.Body, message)
+	require.NoError(s.t, err)
+
+	var sequence uint64
+	require.NoError(s.t, grpcwire.ForEachField(message, func(f grpcwire.Field) error {
+		if f.Num == ackSequence {
+			sequence = f.Value
+		}
+		return nil
+	}))
+	return sequence
+}
+
+// expectStatus closes the stream, and returns its status and message
+func (s *testStream) expectStatus() (string, string) {
+	s.body.Close()
+	resp := s.response()
+	_, err := ioutil.ReadAll(resp.Body)
+	require.NoError(s.t, err)
+	return resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+}
+
+func batch(sequence uint64, records ...[]byte) []byte {
+	b := protowire.AppendTag(nil, batchSequence, protowire.VarintType)
+	b = protowire.AppendVarint(b, sequence)
+	for _, record := range records {
+		b = protowire.AppendTag(b, batchRecords, protowire.BytesType)
+		b = protowire.AppendBytes(b, record)
+	}
+	return b
+}
+
+func record(timestamp time.Time, severityText string, body string, attributes map[string]string) []byte {
+	var b []byte
+	if !timestamp.IsZero() {
+		b = protowire.AppendTag(b, recordTimeUnixNano, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, uint64(timestamp.UnixNano()))
+	}
+	if severityText != "" {
+		b = protowire.AppendTag(b, recordSeverityText, protowire.BytesType)
+		b = protowire.AppendString(b, severityText)
+	}
+	b = protowire.AppendTag(b, recordBody, protowire.BytesType)
+	b = protowire.AppendString(b, body)
+	for key, value := range attributes {
+		entry := protowire.AppendTag(nil, mapEntryKey, protowire.BytesType)
+		entry = protowire.AppendString(entry, key)
+		entry = protowire.AppendTag(entry, mapEntryValue, protowire.BytesType)
+		entry = protowire.AppendString(entry, value)
+		b = protowire.AppendTag(b, recordAttributes, protowire.BytesType)
+		b = protowire.AppendBytes(b, entry)
+	}
+	return b
+}
+
+func expectEntry(t *testing.T, output *testutil.FakeOutput) *entry.Entry {
+	select {
+	case e := <-output.Received:
+		return e
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+	return nil
+}
+
+func expectNoEntry(t *testing.T, output *testutil.FakeOutput) {
+	select {
+	case e := <-output.Received:
+		require.FailNow(t, "Unexpected entry", "%v", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*GRPCInputConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *GRPCInputConfig) {},
+			false,
+		},
+		{
+			"MissingListenAddress",
+			func(cfg *GRPCInputConfig) {
+				cfg.ListenAddress = ""
+			},
+			true,
+		},
+		{
+			"InvalidListenAddress",
+			func(cfg *GRPCInputConfig) {
+				cfg.ListenAddress = "localhost"
+			},
+			true,
+		},
+		{
+			"ZeroMaxMessageSize",
+			func(cfg *GRPCInputConfig) {
+				cfg.MaxMessageSize = 0
+			},
+			true,
+		},
+		{
+			"BodyFormatJSON",
+			func(cfg *GRPCInputConfig) {
+				cfg.BodyFormat = "json"
+			},
+			false,
+		},
+		{
+			"BodyFormatBytes",
+			func(cfg *GRPCInputConfig) {
+				cfg.BodyFormat = "bytes"
+			},
+			false,
+		},
+		{
+			"InvalidBodyFormat",
+			func(cfg *GRPCInputConfig) {
+				cfg.BodyFormat = "xml"
+			},
+			true,
+		},
+		{
+			"InvalidEncoding",
+			func(cfg *GRPCInputConfig) {
+				cfg.Encoding.Encoding = "ebcdic"
+			},
+			true,
+		},
+		{
+			"MetadataResource",
+			func(cfg *GRPCInputConfig) {
+				cfg.MetadataResource = map[string]string{"X-Service-Name": "service.name"}
+			},
+			false,
+		},
+		{
+			"EmptyMetadataResourceKey",
+			func(cfg *GRPCInputConfig) {
+				cfg.MetadataResource = map[string]string{"x-service-name": ""}
+			},
+			true,
+		},
+		{
+			"MissingTLSCertificate",
+			func(cfg *GRPCInputConfig) {
+				cfg.TLS = helper.NewTLSServerConfig(&configtls.TLSServerSetting{
+					TLSSetting: configtls.TLSSetting{
+						CertFile: "/tmp/cert/missing",
+						KeyFile:  "/tmp/key/missing",
+					},
+				})
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewGRPCInputConfig("test_id")
+			cfg.ListenAddress = "127.0.0.1:4320"
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestGRPCInputStream(t *testing.T) {
+	grpcInput, output := newTestGRPCInput(t, func(cfg *GRPCInputConfig) {
+		cfg.MetadataResource = map[string]string{"X-Service-Name": "service.name"}
+	})
+
+	header := http.Header{}
+	header.Set("X-Service-Name", "checkout")
+	stream := openStream(t, grpcClient(), "http://"+grpcInput.listener.Addr().String(), header)
+
+	timestamp := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	stream.send(batch(1,
+		record(timestamp, "INFO", "first", map[string]string{"thread": "main"}),
+		record(time.Time{}, "", "second", nil),
+	))
+	require.Equal(t, uint64(1), stream.expectAck())
+
+	e := expectEntry(t, output)
+	require.Equal(t, "first", e.Body)
+	require.True(t, timestamp.Equal(e.Timestamp))
+	require.Equal(t, "INFO", e.SeverityText)
+	require.Equal(t, map[string]string{"thread": "main"}, e.Attributes)
+	require.Equal(t, map[string]string{"service.name": "checkout"}, e.Resource)
+
+	e = expectEntry(t, output)
+	require.Equal(t, "second", e.Body)
+	require.Equal(t, map[string]string{"service.name": "checkout"}, e.Resource)
+
+	stream.send(batch(2, record(time.Time{}, "", "third", nil)))
+	require.Equal(t, uint64(2), stream.expectAck())
+	require.Equal(t, "third", expectEntry(t, output).Body)
+
+	status, _ := stream.expectStatus()
+	require.Equal(t, "0", status)
+}
+
+func TestGRPCInputBodyFormat(t *testing.T) {
+	cases := []struct {
+		format   string
+		body     string
+		expected interface{}
+	}{
+		{"text", "message", "message"},
+		{"json", `{"message":"hello","count":2}`, map[string]interface{}{"message": "hello", "count": float64(2)}},
+		{"bytes", "message", []byte("message")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.format, func(t *testing.T) {
+			grpcInput, output := newTestGRPCInput(t, func(cfg *GRPCInputConfig) {
+				cfg.BodyFormat = tc.format
+			})
+
+			stream := openStream(t, grpcClient(), "http://"+grpcInput.listener.Addr().String(), nil)
+			stream.send(batch(1, record(time.Time{}, "", tc.body, nil)))
+			require.Equal(t, uint64(1), stream.expectAck())
+			require.Equal(t, tc.expected, expectEntry(t, output).Body)
+		})
+	}
+}
+
+func TestGRPCInputGzip(t *testing.T) {
+	grpcInput, output := newTestGRPCInput(t, nil)
+
+	header := http.Header{}
+	header.Set("Grpc-Encoding", "gzip")
+	stream := openStream(t, grpcClient(), "http://"+grpcInput.listener.Addr().String(), header)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write(batch(7, record(time.Time{}, "", "compressed", nil)))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	frame := grpcwire.Frame(buf.Bytes())
+	frame[0] = 1
+	_, err = stream.body.Write(frame)
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(7), stream.expectAck())
+	require.Equal(t, "compressed", expectEntry(t, output).Body)
+}
+
+func TestGRPCInputErrors(t *testing.T) {
+	cases := []struct {
+		name           string
+		cfgMod         func(*GRPCInputConfig)
+		message        []byte
+		expectedStatus string
+	}{
+		{
+			"InvalidBatch",
+			nil,
+			[]byte{0xff},
+			"3",
+		},
+		{
+			"InvalidJSONBody",
+			func(cfg *GRPCInputConfig) {
+				cfg.BodyFormat = "json"
+			},
+			batch(1, record(time.Time{}, "", `{"valid":true}`, nil), record(time.Time{}, "", "invalid", nil)),
+			"3",
+		},
+		{
+			"MessageTooLarge",
+			func(cfg *GRPCInputConfig) {
+				cfg.MaxMessageSize = 16
+			},
+			batch(1, record(time.Time{}, "", "a message which is too large", nil)),
+			"8",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			grpcInput, output := newTestGRPCInput(t, tc.cfgMod)
+
+			stream := openStream(t, grpcClient(), "http://"+grpcInput.listener.Addr().String(), nil)
+			stream.send(tc.message)
+			status, message := stream.expectStatus()
+			require.Equal(t, tc.expectedStatus, status)
+			require.NotEmpty(t, message)
+
+			// A batch is either written in full or rejected
+			expectNoEntry(t, output)
+		})
+	}
+}
+
+func TestGRPCInputUnknownMethod(t *testing.T) {
+	grpcInput, _ := newTestGRPCInput(t, nil)
+
+	req, err := http.NewRequest(http.MethodPost, "http://"+grpcInput.listener.Addr().String()+"/unknown.Service/Method", bytes.NewReader(grpcwire.Frame(nil)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/grpc")
+
+	resp, err := grpcClient().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	_, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "12", resp.Header.Get("Grpc-Status"))
+}
+
+func TestGRPCInputBackpressure(t *testing.T) {
+	blocked := testutil.NewMockOperator("$.output")
+	unblock := make(chan struct{})
+	received := make(chan *entry.Entry, 10)
+	blocked.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		<-unblock
+		received <- args.Get(1).(*entry.Entry)
+	}).Return(nil)
+
+	grpcInput, _ := newTestGRPCInput(t, func(cfg *GRPCInputConfig) {
+		cfg.OutputIDs = []string{"output"}
+	}, blocked)
+	stream := openStream(t, grpcClient(), "http://"+grpcInput.listener.Addr().String(), nil)
+	stream.send(batch(1, record(time.Time{}, "", "blocked", nil)))
+
+	// The batch is not acknowledged until its entries have been written
+	acked := make(chan uint64, 1)
+	go func() { acked <- stream.expectAck() }()
+	select {
+	case <-acked:
+		require.FailNow(t, "Batch acknowledged before it was written")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(unblock)
+	select {
+	case sequence := <-acked:
+		require.Equal(t, uint64(1), sequence)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for acknowledgement")
+	}
+	require.Equal(t, "blocked", (<-received).Body)
+}
+
+func TestGRPCInputStopClosesStreams(t *testing.T) {
+	cfg := NewGRPCInputConfig("test_id")
+	cfg.ListenAddress = "127.0.0.1:0"
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	grpcInput := ops[0].(*GRPCInput)
+	output := testutil.NewFakeOutput(t)
+	require.NoError(t, grpcInput.SetOutputs([]operator.Operator{output}))
+	require.NoError(t, grpcInput.Start(testutil.NewMockPersister("test")))
+
+	stream := openStream(t, grpcClient(), "http://"+grpcInput.listener.Addr().String(), nil)
+	stream.send(batch(1, record(time.Time{}, "", "message", nil)))
+	require.Equal(t, uint64(1), stream.expectAck())
+	expectEntry(t, output)
+
+	// An open stream does not prevent the operator from stopping
+	stopped := make(chan error, 1)
+	go func() { stopped <- grpcInput.Stop() }()
+	select {
+	case err := <-stopped:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for operator to stop")
+	}
+}
+
+// writeCertificate creates a certificate signed by the parent, or a self-signed CA
+// certificate if the parent is nil, and writes it and its key to dir
+func writeCertificate(t *testing.T, dir, name string, template *x509.Certificate, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parentCert, parentKey := template, interface{}(key)
+	if parent != nil {
+		parentCert = parent.Leaf
+		parentKey = parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0600))
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	cert.Leaf, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestGRPCInputMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := writeCertificate(t, dir, "ca", &x509.Certificate{
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	writeCertificate(t, dir, "server", &x509.Certificate{
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+	client := writeCertificate(t, dir, "client", &x509.Certificate{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &ca)
+
+	grpcInput, output := newTestGRPCInput(t, func(cfg *GRPCInputConfig) {
+		cfg.TLS = helper.NewTLSServerConfig(&configtls.TLSServerSetting{
+			TLSSetting: configtls.TLSSetting{
+				CertFile: filepath.Join(dir, "server.crt"),
+				KeyFile:  filepath.Join(dir, "server.key"),
+			},
+			ClientCAFile: filepath.Join(dir, "ca.crt"),
+		})
+	})
+	url := "https://" + grpcInput.listener.Addr().String()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+	tlsClient := func(certificates ...tls.Certificate) *http.Client {
+		return &http.Client{
+			Transport: &http2.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs:      roots,
+					Certificates: certificates,
+					MinVersion:   tls.VersionTLS12,
+				},
+			},
+		}
+	}
+
+	stream := openStream(t, tlsClient(client), url, nil)
+	stream.send(batch(1, record(time.Time{}, "", "secure", nil)))
+	require.Equal(t, uint64(1), stream.expectAck())
+	require.Equal(t, "secure", expectEntry(t, output).Body)
+
+	// Clients without a certificate signed by the client CA are rejected
+	req, err := http.NewRequest(http.MethodPost, url+streamPath, bytes.NewReader(grpcwire.Frame(nil)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/grpc")
+	_, err = tlsClient().Do(req)
+	require.Error(t, err)
+}
@@ -0,0 +1,328 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// DefaultMaxRequestSize is the max size of a request body
+	// if MaxRequestSize is not set
+	DefaultMaxRequestSize = 10 * 1024 * 1024
+
+	// shutdownTimeout is how long requests which are being
+	// handled are waited for when the operator is stopped
+	shutdownTimeout = 5 * time.Second
+
+	// bearerPrefix is the scheme of the Authorization header of a bearer token
+	bearerPrefix = "Bearer "
+)
+
+func init() {
+	operator.Register("http_input", func() operator.Builder { return NewHTTPInputConfig("") })
+}
+
+// NewHTTPInputConfig creates a new HTTP input config with default values
+func NewHTTPInputConfig(operatorID string) *HTTPInputConfig {
+	return &HTTPInputConfig{
+		InputConfig:    helper.NewInputConfig(operatorID, "http_input"),
+		Path:           "/",
+		MaxRequestSize: DefaultMaxRequestSize,
+	}
+}
+
+// HTTPInputConfig is the configuration of an http input operator.
+type HTTPInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	ListenAddress    string                  `mapstructure:"listen_address,omitempty"    json:"listen_address,omitempty"    yaml:"listen_address,omitempty"`
+	Path             string                  `mapstructure:"path,omitempty"              json:"path,omitempty"              yaml:"path,omitempty"`
+	TLS              *helper.TLSServerConfig `mapstructure:"tls,omitempty"               json:"tls,omitempty"               yaml:"tls,omitempty"`
+	BearerToken      string                  `mapstructure:"bearer_token,omitempty"      json:"bearer_token,omitempty"      yaml:"bearer_token,omitempty"`
+	BasicAuth        *BasicAuthConfig        `mapstructure:"basic_auth,omitempty"        json:"basic_auth,omitempty"        yaml:"basic_auth,omitempty"`
+	MaxRequestSize   helper.ByteSize         `mapstructure:"max_request_size,omitempty"  json:"max_request_size,omitempty"  yaml:"max_request_size,omitempty"`
+	HeaderAttributes map[string]string       `mapstructure:"header_attributes,omitempty" json:"header_attributes,omitempty" yaml:"header_attributes,omitempty"`
+}
+
+// BasicAuthConfig is the username and password required of requests
+type BasicAuthConfig struct {
+	Username string `mapstructure:"username,omitempty" json:"username,omitempty" yaml:"username,omitempty"`
+	Password string `mapstructure:"password,omitempty" json:"password,omitempty" yaml:"password,omitempty"`
+}
+
+// Build will build an http input operator.
+func (c HTTPInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ListenAddress == "" {
+		return nil, fmt.Errorf("missing required parameter 'listen_address'")
+	}
+
+	// validate the input address
+	if _, err := net.ResolveTCPAddr("tcp", c.ListenAddress); err != nil {
+		return nil, fmt.Errorf("failed to resolve listen_address: %s", err)
+	}
+
+	if !strings.HasPrefix(c.Path, "/") {
+		return nil, fmt.Errorf("invalid path '%s', must begin with '/'", c.Path)
+	}
+
+	if c.MaxRequestSize <= 0 {
+		return nil, fmt.Errorf("`max_request_size` must be positive")
+	}
+
+	if c.BearerToken != "" && c.BasicAuth != nil {
+		return nil, fmt.Errorf("only one of `bearer_token` and `basic_auth` can be set")
+	}
+
+	if c.BasicAuth != nil && c.BasicAuth.Username == "" {
+		return nil, fmt.Errorf("missing required parameter 'basic_auth.username'")
+	}
+
+	headerAttributes := make(map[string]string, len(c.HeaderAttributes))
+	for header, attribute := range c.HeaderAttributes {
+		headerAttributes[http.CanonicalHeaderKey(header)] = attribute
+	}
+
+	httpInput := &HTTPInput{
+		InputOperator:    inputOperator,
+		address:          c.ListenAddress,
+		path:             c.Path,
+		bearerToken:      c.BearerToken,
+		basicAuth:        c.BasicAuth,
+		maxRequestSize:   int64(c.MaxRequestSize),
+		headerAttributes: headerAttributes,
+		json:             jsoniter.ConfigFastest,
+	}
+
+	if c.TLS != nil {
+		httpInput.tls, err = c.TLS.LoadTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []operator.Operator{httpInput}, nil
+}
+
+// HTTPInput is an operator that receives log entries in http requests.
+type HTTPInput struct {
+	helper.InputOperator
+	address          string
+	path             string
+	bearerToken      string
+	basicAuth        *BasicAuthConfig
+	maxRequestSize   int64
+	headerAttributes map[string]string
+	json             jsoniter.API
+
+	listener net.Listener
+	server   *http.Server
+	wg       sync.WaitGroup
+	tls      *tls.Config
+}
+
+// Start will start listening for http requests.
+func (h *HTTPInput) Start(_ operator.Persister) error {
+	listener, err := net.Listen("tcp", h.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on interface: %w", err)
+	}
+	if h.tls != nil {
+		listener = tls.NewListener(listener, h.tls)
+	}
+	h.listener = listener
+
+	mux := http.NewServeMux()
+	mux.Handle(h.path, h)
+	h.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		if err := h.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			h.Errorw("HTTP server failed", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Stop will stop listening for http requests.
+func (h *HTTPInput) Stop() error {
+	// The server is not created if the operator failed to start
+	if h.server == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := h.server.Shutdown(ctx)
+	h.wg.Wait()
+	return err
+}
+
+// ServeHTTP handles a request containing newline delimited JSON or a JSON array.
+func (h *HTTPInput) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", h.authScheme())
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := h.readBody(w, r)
+	if err != nil {
+		h.Debugw("Failed to read request body", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	values, err := h.parseBody(body)
+	if err != nil {
+		h.Debugw("Failed to parse request body", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, value := range values {
+		entry, err := h.NewEntry(value)
+		if err != nil {
+			h.Errorw("Failed to create entry", zap.Error(err))
+			continue
+		}
+
+		for header, attribute := range h.headerAttributes {
+			if value := r.Header.Get(header); value != "" {
+				entry.AddAttribute(attribute, value)
+			}
+		}
+
+		h.Write(r.Context(), entry)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// authorized returns true if the request has the configured credentials, if any
+func (h *HTTPInput) authorized(r *http.Request) bool {
+	switch {
+	case h.bearerToken != "":
+		// The scheme is case insensitive, but is required
+		auth := r.Header.Get("Authorization")
+		if len(auth) < len(bearerPrefix) || !strings.EqualFold(auth[:len(bearerPrefix)], bearerPrefix) {
+			return false
+		}
+		token := auth[len(bearerPrefix):]
+		return subtle.ConstantTimeCompare([]byte(token), []byte(h.bearerToken)) == 1
+	case h.basicAuth != nil:
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			return false
+		}
+		usernameMatch := subtle.ConstantTimeCompare([]byte(username), []byte(h.basicAuth.Username))
+		passwordMatch := subtle.ConstantTimeCompare([]byte(password), []byte(h.basicAuth.Password))
+		return usernameMatch&passwordMatch == 1
+	default:
+		return true
+	}
+}
+
+func (h *HTTPInput) authScheme() string {
+	if h.basicAuth != nil {
+		return "Basic"
+	}
+	return "Bearer"
+}
+
+// readBody reads the request body, decompressing it if necessary
+func (h *HTTPInput) readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	var body io.Reader = http.MaxBytesReader(w, r.Body, h.maxRequestSize)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		// Limit the decompressed size as well as the compressed size
+		body = io.LimitReader(gz, h.maxRequestSize+1)
+	}
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > h.maxRequestSize {
+		return nil, fmt.Errorf("request body too large")
+	}
+	return data, nil
+}
+
+// parseBody parses a JSON array, or a sequence of JSON values such as newline
+// delimited JSON, into the values of the entries to create
+func (h *HTTPInput) parseBody(body []byte) ([]interface{}, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	if body[0] == '[' {
+		var values []interface{}
+		if err := h.json.Unmarshal(body, &values); err != nil {
+			return nil, fmt.Errorf("parse JSON array: %s", err)
+		}
+		return values, nil
+	}
+
+	values := make([]interface{}, 0, bytes.Count(body, []byte("\n"))+1)
+	decoder := h.json.NewDecoder(bytes.NewReader(body))
+	for decoder.More() {
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("parse JSON: %s", err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -0,0 +1,301 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtls"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestHTTPInput(t *testing.T, cfgMod func(*HTTPInputConfig)) (*HTTPInput, *testutil.FakeOutput, string) {
+	cfg := NewHTTPInputConfig("test_id")
+	cfg.ListenAddress = "127.0.0.1:0"
+	cfg.OutputIDs = []string{"fake"}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	httpInput := ops[0].(*HTTPInput)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, httpInput.SetOutputs([]operator.Operator{fakeOutput}))
+
+	require.NoError(t, httpInput.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() { require.NoError(t, httpInput.Stop()) })
+
+	return httpInput, fakeOutput, "http://" + httpInput.listener.Addr().String()
+}
+
+func post(t *testing.T, url string, body string, headers map[string]string) int {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+func expectNoEntry(t *testing.T, output *testutil.FakeOutput) {
+	select {
+	case e := <-output.Received:
+		require.FailNow(t, "Unexpected entry", "Body: %v", e.Body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*HTTPInputConfig)
+		expectErr bool
+	}{
+		{
+			"default",
+			func(c *HTTPInputConfig) {},
+			false,
+		},
+		{
+			"missing-address",
+			func(c *HTTPInputConfig) {
+				c.ListenAddress = ""
+			},
+			true,
+		},
+		{
+			"relative-path",
+			func(c *HTTPInputConfig) {
+				c.Path = "logs"
+			},
+			true,
+		},
+		{
+			"negative-max-request-size",
+			func(c *HTTPInputConfig) {
+				c.MaxRequestSize = -1
+			},
+			true,
+		},
+		{
+			"bearer-and-basic-auth",
+			func(c *HTTPInputConfig) {
+				c.BearerToken = "token"
+				c.BasicAuth = &BasicAuthConfig{Username: "user", Password: "pass"}
+			},
+			true,
+		},
+		{
+			"basic-auth-missing-username",
+			func(c *HTTPInputConfig) {
+				c.BasicAuth = &BasicAuthConfig{Password: "pass"}
+			},
+			true,
+		},
+		{
+			"tls-enabled-with-no-such-file-error",
+			func(c *HTTPInputConfig) {
+				c.TLS = helper.NewTLSServerConfig(&configtls.TLSServerSetting{
+					TLSSetting: configtls.TLSSetting{
+						CertFile: "/tmp/cert/missing",
+						KeyFile:  "/tmp/key/missing",
+					},
+				})
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewHTTPInputConfig("test_id")
+			cfg.ListenAddress = "10.0.0.1:9000"
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHTTPInput(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		expected []interface{}
+	}{
+		{
+			"NewlineDelimited",
+			"{\"message\":\"one\"}\n{\"message\":\"two\"}\n",
+			[]interface{}{
+				map[string]interface{}{"message": "one"},
+				map[string]interface{}{"message": "two"},
+			},
+		},
+		{
+			"Array",
+			`[{"message":"one"},"two"]`,
+			[]interface{}{
+				map[string]interface{}{"message": "one"},
+				"two",
+			},
+		},
+		{
+			"Empty",
+			"\n",
+			[]interface{}{},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, output, url := newTestHTTPInput(t, nil)
+			require.Equal(t, http.StatusOK, post(t, url, tc.body, nil))
+			for _, expected := range tc.expected {
+				output.ExpectBody(t, expected)
+			}
+			expectNoEntry(t, output)
+		})
+	}
+}
+
+func TestHTTPInputInvalidJSON(t *testing.T) {
+	_, output, url := newTestHTTPInput(t, nil)
+	require.Equal(t, http.StatusBadRequest, post(t, url, "{\"message\":\"one\"}\n{\"message\":", nil))
+	expectNoEntry(t, output)
+}
+
+func TestHTTPInputMethodNotAllowed(t *testing.T) {
+	_, _, url := newTestHTTPInput(t, nil)
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestHTTPInputPath(t *testing.T) {
+	_, output, url := newTestHTTPInput(t, func(cfg *HTTPInputConfig) {
+		cfg.Path = "/logs"
+	})
+	require.Equal(t, http.StatusNotFound, post(t, url+"/other", `"message"`, nil))
+	require.Equal(t, http.StatusOK, post(t, url+"/logs", `"message"`, nil))
+	output.ExpectBody(t, "message")
+}
+
+func TestHTTPInputMaxRequestSize(t *testing.T) {
+	_, output, url := newTestHTTPInput(t, func(cfg *HTTPInputConfig) {
+		cfg.MaxRequestSize = 16
+	})
+	require.Equal(t, http.StatusBadRequest, post(t, url, `"a message longer than the limit"`, nil))
+	expectNoEntry(t, output)
+}
+
+func TestHTTPInputGzip(t *testing.T) {
+	_, output, url := newTestHTTPInput(t, nil)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(`"message"`))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	require.Equal(t, http.StatusOK, post(t, url, buf.String(), map[string]string{"Content-Encoding": "gzip"}))
+	output.ExpectBody(t, "message")
+}
+
+func TestHTTPInputBearerToken(t *testing.T) {
+	_, output, url := newTestHTTPInput(t, func(cfg *HTTPInputConfig) {
+		cfg.BearerToken = "secret"
+	})
+	require.Equal(t, http.StatusUnauthorized, post(t, url, `"message"`, nil))
+	require.Equal(t, http.StatusUnauthorized, post(t, url, `"message"`, map[string]string{"Authorization": "Bearer wrong"}))
+	require.Equal(t, http.StatusUnauthorized, post(t, url, `"message"`, map[string]string{"Authorization": "secret"}))
+	require.Equal(t, http.StatusUnauthorized, post(t, url, `"message"`, map[string]string{"Authorization": "Basic secret"}))
+	expectNoEntry(t, output)
+
+	require.Equal(t, http.StatusOK, post(t, url, `"message"`, map[string]string{"Authorization": "Bearer secret"}))
+	output.ExpectBody(t, "message")
+	require.Equal(t, http.StatusOK, post(t, url, `"message"`, map[string]string{"Authorization": "bearer secret"}))
+	output.ExpectBody(t, "message")
+}
+
+func TestHTTPInputStopWithoutStart(t *testing.T) {
+	cfg := NewHTTPInputConfig("test_id")
+	cfg.ListenAddress = "127.0.0.1:0"
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	require.NoError(t, ops[0].Stop())
+}
+
+func TestHTTPInputBasicAuth(t *testing.T) {
+	_, output, url := newTestHTTPInput(t, func(cfg *HTTPInputConfig) {
+		cfg.BasicAuth = &BasicAuthConfig{Username: "user", Password: "pass"}
+	})
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(`"message"`))
+	require.NoError(t, err)
+	req.SetBasicAuth("user", "wrong")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	expectNoEntry(t, output)
+
+	req, err = http.NewRequest(http.MethodPost, url, strings.NewReader(`"message"`))
+	require.NoError(t, err)
+	req.SetBasicAuth("user", "pass")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	output.ExpectBody(t, "message")
+}
+
+func TestHTTPInputHeaderAttributes(t *testing.T) {
+	_, output, url := newTestHTTPInput(t, func(cfg *HTTPInputConfig) {
+		cfg.HeaderAttributes = map[string]string{
+			"x-source":   "source",
+			"X-Function": "function",
+		}
+	})
+	require.Equal(t, http.StatusOK, post(t, url, `"message"`, map[string]string{"X-Source": "webhook"}))
+
+	select {
+	case e := <-output.Received:
+		require.Equal(t, "message", e.Body)
+		require.Equal(t, map[string]string{"source": "webhook"}, e.Attributes)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
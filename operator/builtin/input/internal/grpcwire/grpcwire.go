@@ -0,0 +1,196 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpcwire contains the parts of the gRPC and protobuf wire formats
+// which are shared by the inputs that receive gRPC requests
+package grpcwire
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The gRPC status codes which are returned
+const (
+	CodeOK                = 0
+	CodeInvalidArgument   = 3
+	CodeResourceExhausted = 8
+	CodeUnimplemented     = 12
+	CodeUnavailable       = 14
+)
+
+// IsGRPC returns true if the request uses the gRPC protocol
+func IsGRPC(r *http.Request) bool {
+	return r.ProtoMajor == 2 && r.Method == http.MethodPost &&
+		strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// SupportedEncoding returns true if messages with the grpc-encoding can be decompressed
+func SupportedEncoding(encoding string) bool {
+	return encoding == "" || encoding == "identity" || encoding == "gzip"
+}
+
+// ReadMessage reads the next message of a call. gRPC messages are prefixed with
+// a compression flag and their length, and compressed messages are decompressed
+// with the grpc-encoding of the call. Messages larger than limit bytes, before or
+// after decompression, are rejected. io.EOF is returned at the end of the call,
+// and otherwise the status code with which the call should end if it fails.
+func ReadMessage(r io.Reader, encoding string, limit int64) ([]byte, int, error) {
+	prefix := make([]byte, 5)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, CodeInvalidArgument, fmt.Errorf("message is missing its prefix")
+		}
+		return nil, CodeInvalidArgument, err
+	}
+
+	compressed := prefix[0] == 1
+	length := int64(binary.BigEndian.Uint32(prefix[1:]))
+	if length > limit {
+		return nil, CodeResourceExhausted, fmt.Errorf("message larger than %d bytes", limit)
+	}
+
+	message := make([]byte, length)
+	if _, err := io.ReadFull(r, message); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, CodeInvalidArgument, fmt.Errorf("message length %d does not match its prefix", length)
+		}
+		return nil, CodeInvalidArgument, err
+	}
+
+	if !compressed {
+		return message, CodeOK, nil
+	}
+	return decompress(message, encoding, limit)
+}
+
+// decompress decompresses a message with the grpc-encoding of its call
+func decompress(message []byte, encoding string, limit int64) ([]byte, int, error) {
+	if encoding != "gzip" {
+		return nil, CodeUnimplemented, fmt.Errorf("unsupported grpc-encoding '%s' for a compressed message", encoding)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(message))
+	if err != nil {
+		return nil, CodeInvalidArgument, err
+	}
+	defer gz.Close()
+
+	message, err = ioutil.ReadAll(io.LimitReader(gz, limit+1))
+	if err != nil {
+		return nil, CodeInvalidArgument, err
+	}
+	if int64(len(message)) > limit {
+		return nil, CodeResourceExhausted, fmt.Errorf("message larger than %d bytes", limit)
+	}
+	return message, CodeOK, nil
+}
+
+// Frame prefixes an uncompressed message with its length
+func Frame(message []byte) []byte {
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	return append(frame, message...)
+}
+
+// WriteStatus writes the status of a call in the trailers of its response. The
+// trailers must be declared before the response is started, unless its headers
+// have already been flushed.
+func WriteStatus(w http.ResponseWriter, code int, message string) {
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+	if message != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", PercentEncode(message))
+	}
+}
+
+// WriteTrailersOnly writes the status of a call which is rejected before its
+// response is started. The status is sent in a response without a body,
+// whose headers take the place of its trailers.
+func WriteTrailersOnly(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	if message != "" {
+		w.Header().Set("Grpc-Message", PercentEncode(message))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// PercentEncode encodes a grpc-message, which may only contain printable ASCII
+func PercentEncode(message string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&buf, "%%%02X", c)
+			continue
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String()
+}
+
+// Field is a single decoded protobuf field. Value holds the value of
+// varint and fixed width fields, and Bytes the value of length-delimited
+// fields.
+type Field struct {
+	Num   protowire.Number
+	Type  protowire.Type
+	Value uint64
+	Bytes []byte
+}
+
+// ForEachField calls fn with each field of the protobuf message
+func ForEachField(b []byte, fn func(f Field) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		f := Field{Num: num, Type: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.Value, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			f.Value, n = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f.Value = uint64(v)
+		case protowire.BytesType:
+			f.Bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
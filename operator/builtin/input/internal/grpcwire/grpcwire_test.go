@@ -0,0 +1,179 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcwire
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestIsGRPC(t *testing.T) {
+	cases := []struct {
+		name        string
+		protoMajor  int
+		method      string
+		contentType string
+		expected    bool
+	}{
+		{"GRPC", 2, http.MethodPost, "application/grpc", true},
+		{"GRPCProto", 2, http.MethodPost, "application/grpc+proto", true},
+		{"HTTP1", 1, http.MethodPost, "application/grpc", false},
+		{"Get", 2, http.MethodGet, "application/grpc", false},
+		{"Protobuf", 2, http.MethodPost, "application/x-protobuf", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &http.Request{ProtoMajor: tc.protoMajor, Method: tc.method, Header: http.Header{}}
+			r.Header.Set("Content-Type", tc.contentType)
+			require.Equal(t, tc.expected, IsGRPC(r))
+		})
+	}
+}
+
+func gzipFrame(t *testing.T, message string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(message))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	frame := Frame(buf.Bytes())
+	frame[0] = 1
+	return frame
+}
+
+func TestReadMessage(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     []byte
+		encoding string
+		expected string
+		code     int
+	}{
+		{"Uncompressed", Frame([]byte("message")), "", "message", CodeOK},
+		{"UncompressedWithEncoding", Frame([]byte("message")), "gzip", "message", CodeOK},
+		{"Gzip", gzipFrame(t, "message"), "gzip", "message", CodeOK},
+		{"CompressedWithoutEncoding", gzipFrame(t, "message"), "", "", CodeUnimplemented},
+		{"UnsupportedEncoding", gzipFrame(t, "message"), "snappy", "", CodeUnimplemented},
+		{"InvalidGzip", append([]byte{1, 0, 0, 0, 1}, 0xff), "gzip", "", CodeInvalidArgument},
+		{"MissingPrefix", []byte{0, 0}, "", "", CodeInvalidArgument},
+		{"Truncated", Frame([]byte("message"))[:8], "", "", CodeInvalidArgument},
+		{"TooLarge", Frame([]byte(strings.Repeat("a", 100))), "", "", CodeResourceExhausted},
+		{"TooLargeDecompressed", gzipFrame(t, strings.Repeat("a", 100)), "gzip", "", CodeResourceExhausted},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			message, code, err := ReadMessage(bytes.NewReader(tc.body), tc.encoding, 64)
+			require.Equal(t, tc.code, code)
+			if tc.code != CodeOK {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(message))
+		})
+	}
+}
+
+func TestReadMessageEOF(t *testing.T) {
+	r := bytes.NewReader(append(Frame([]byte("first")), Frame([]byte("second"))...))
+	for _, expected := range []string{"first", "second"} {
+		message, _, err := ReadMessage(r, "", 16)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(message))
+	}
+
+	_, _, err := ReadMessage(r, "", 16)
+	require.Equal(t, io.EOF, err)
+}
+
+func TestSupportedEncoding(t *testing.T) {
+	for _, encoding := range []string{"", "identity", "gzip"} {
+		require.True(t, SupportedEncoding(encoding))
+	}
+	require.False(t, SupportedEncoding("snappy"))
+}
+
+func TestWriteStatus(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteStatus(w, CodeInvalidArgument, "100% invalid")
+	require.Equal(t, "3", w.Header().Get(http.TrailerPrefix+"Grpc-Status"))
+	require.Equal(t, "100%25 invalid", w.Header().Get(http.TrailerPrefix+"Grpc-Message"))
+}
+
+func TestWriteTrailersOnly(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteTrailersOnly(w, CodeUnimplemented, "unknown method")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/grpc", w.Header().Get("Content-Type"))
+	require.Equal(t, "12", w.Header().Get("Grpc-Status"))
+	require.Equal(t, "unknown method", w.Header().Get("Grpc-Message"))
+	require.Empty(t, w.Body.Bytes())
+}
+
+func TestPercentEncode(t *testing.T) {
+	require.Equal(t, "100%25 caf%C3%A9", PercentEncode("100% café"))
+}
+
+func TestForEachField(t *testing.T) {
+	b := protowire.AppendTag(nil, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, 150)
+	b = protowire.AppendTag(b, 2, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, 64)
+	b = protowire.AppendTag(b, 3, protowire.Fixed32Type)
+	b = protowire.AppendFixed32(b, 32)
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	b = protowire.AppendString(b, "value")
+
+	var fields []Field
+	err := ForEachField(b, func(f Field) error {
+		fields = append(fields, f)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []Field{
+		{Num: 1, Type: protowire.VarintType, Value: 150},
+		{Num: 2, Type: protowire.Fixed64Type, Value: 64},
+		{Num: 3, Type: protowire.Fixed32Type, Value: 32},
+		{Num: 4, Type: protowire.BytesType, Bytes: []byte("value")},
+	}, fields)
+}
+
+func TestForEachFieldErrors(t *testing.T) {
+	t.Run("Truncated", func(t *testing.T) {
+		b := protowire.AppendTag(nil, 1, protowire.BytesType)
+		b = protowire.AppendVarint(b, 10)
+		err := ForEachField(append(b, "short"...), func(Field) error { return nil })
+		require.Error(t, err)
+	})
+
+	t.Run("Callback", func(t *testing.T) {
+		b := protowire.AppendTag(nil, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+		err := ForEachField(b, func(Field) error { return fmt.Errorf("stop") })
+		require.EqualError(t, err, "stop")
+	})
+}
@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
+	"regexp"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -50,9 +52,13 @@ func NewJournaldInputConfig(operatorID string) *JournaldInputConfig {
 type JournaldInputConfig struct {
 	helper.InputConfig `mapstructure:",squash" yaml:",inline"`
 
-	Directory *string  `mapstructure:"directory,omitempty" json:"directory,omitempty" yaml:"directory,omitempty"`
-	Files     []string `mapstructure:"files,omitempty"     json:"files,omitempty"     yaml:"files,omitempty"`
-	StartAt   string   `mapstructure:"start_at,omitempty"  json:"start_at,omitempty"  yaml:"start_at,omitempty"`
+	Directory *string             `mapstructure:"directory,omitempty" json:"directory,omitempty" yaml:"directory,omitempty"`
+	Files     []string            `mapstructure:"files,omitempty"     json:"files,omitempty"     yaml:"files,omitempty"`
+	StartAt   string              `mapstructure:"start_at,omitempty"  json:"start_at,omitempty"  yaml:"start_at,omitempty"`
+	Units     []string            `mapstructure:"units,omitempty"     json:"units,omitempty"     yaml:"units,omitempty"`
+	Priority  string              `mapstructure:"priority,omitempty"  json:"priority,omitempty"  yaml:"priority,omitempty"`
+	Matches   []map[string]string `mapstructure:"matches,omitempty"   json:"matches,omitempty"   yaml:"matches,omitempty"`
+	Grep      string              `mapstructure:"grep,omitempty"      json:"grep,omitempty"      yaml:"grep,omitempty"`
 }
 
 // Build will build a journald input operator from the supplied configuration
@@ -90,6 +96,12 @@ func (c JournaldInputConfig) Build(buildContext operator.BuildContext) ([]operat
 		}
 	}
 
+	filterArgs, err := c.filterArgs()
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, filterArgs...)
+
 	journaldInput := &JournaldInput{
 		InputOperator: inputOperator,
 		newCmd: func(ctx context.Context, cursor []byte) cmd {
@@ -103,6 +115,60 @@ func (c JournaldInputConfig) Build(buildContext operator.BuildContext) ([]operat
 	return []operator.Operator{journaldInput}, nil
 }
 
+// priorityPattern matches a journald priority, or a range of priorities,
+// given by name or number
+var priorityPattern = regexp.MustCompile(`^(emerg|alert|crit|err|warning|notice|info|debug|[0-7])(\.\.(emerg|alert|crit|err|warning|notice|info|debug|[0-7]))?$`)
+
+// fieldPattern matches the name of a journald field
+var fieldPattern = regexp.MustCompile(`^[A-Z0-9_]+$`)
+
+// filterArgs returns the journalctl arguments which filter the entries read.
+// An entry must match all of the filters. The fields of each match must all
+// match, and an entry must match at least one of the matches.
+func (c JournaldInputConfig) filterArgs() ([]string, error) {
+	args := make([]string, 0, 2*len(c.Units)+4)
+
+	for _, unit := range c.Units {
+		args = append(args, "--unit", unit)
+	}
+
+	if c.Priority != "" {
+		if !priorityPattern.MatchString(c.Priority) {
+			return nil, fmt.Errorf("invalid priority '%s'", c.Priority)
+		}
+		args = append(args, "--priority", c.Priority)
+	}
+
+	if c.Grep != "" {
+		args = append(args, "--grep", c.Grep)
+	}
+
+	// Matches are positional arguments, in which "+" separates alternatives
+	for i, match := range c.Matches {
+		if len(match) == 0 {
+			return nil, fmt.Errorf("match %d is empty", i)
+		}
+		if i > 0 {
+			args = append(args, "+")
+		}
+
+		fields := make([]string, 0, len(match))
+		for field := range match {
+			if !fieldPattern.MatchString(field) {
+				return nil, fmt.Errorf("invalid match field '%s'", field)
+			}
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			args = append(args, field+"="+match[field])
+		}
+	}
+
+	return args, nil
+}
+
 // JournaldInput is an operator that process logs using journald
 type JournaldInput struct {
 	helper.InputOperator
@@ -21,6 +21,7 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"os/exec"
 	"testing"
 	"time"
 
@@ -134,3 +135,146 @@ func TestJournaldInputConfig(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expect, &actual)
 }
+
+func TestJournaldInputFilters(t *testing.T) {
+	baseArgs := []string{"journalctl", "--utc", "--output=json", "--follow"}
+
+	cases := []struct {
+		name         string
+		modify       func(*JournaldInputConfig)
+		expectedArgs []string
+		expectErr    bool
+	}{
+		{
+			"NoFilters",
+			func(cfg *JournaldInputConfig) {},
+			nil,
+			false,
+		},
+		{
+			"Units",
+			func(cfg *JournaldInputConfig) {
+				cfg.Units = []string{"ssh", "docker.service"}
+			},
+			[]string{"--unit", "ssh", "--unit", "docker.service"},
+			false,
+		},
+		{
+			"Priority",
+			func(cfg *JournaldInputConfig) {
+				cfg.Priority = "warning"
+			},
+			[]string{"--priority", "warning"},
+			false,
+		},
+		{
+			"PriorityRange",
+			func(cfg *JournaldInputConfig) {
+				cfg.Priority = "0..err"
+			},
+			[]string{"--priority", "0..err"},
+			false,
+		},
+		{
+			"InvalidPriority",
+			func(cfg *JournaldInputConfig) {
+				cfg.Priority = "error"
+			},
+			nil,
+			true,
+		},
+		{
+			"Grep",
+			func(cfg *JournaldInputConfig) {
+				cfg.Grep = "^(ERROR|WARN)"
+			},
+			[]string{"--grep", "^(ERROR|WARN)"},
+			false,
+		},
+		{
+			"Matches",
+			func(cfg *JournaldInputConfig) {
+				cfg.Matches = []map[string]string{
+					{"_SYSTEMD_SLICE": "system.slice", "_TRANSPORT": "stdout"},
+					{"_TRANSPORT": "kernel"},
+				}
+			},
+			[]string{"_SYSTEMD_SLICE=system.slice", "_TRANSPORT=stdout", "+", "_TRANSPORT=kernel"},
+			false,
+		},
+		{
+			"EmptyMatch",
+			func(cfg *JournaldInputConfig) {
+				cfg.Matches = []map[string]string{{}}
+			},
+			nil,
+			true,
+		},
+		{
+			"InvalidMatchField",
+			func(cfg *JournaldInputConfig) {
+				cfg.Matches = []map[string]string{{"_systemd_slice": "system.slice"}}
+			},
+			nil,
+			true,
+		},
+		{
+			"All",
+			func(cfg *JournaldInputConfig) {
+				cfg.Units = []string{"ssh"}
+				cfg.Priority = "info"
+				cfg.Grep = "session"
+				cfg.Matches = []map[string]string{{"_HOSTNAME": "myhostname"}}
+			},
+			[]string{"--unit", "ssh", "--priority", "info", "--grep", "session", "_HOSTNAME=myhostname"},
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewJournaldInputConfig("my_journald_input")
+			tc.modify(cfg)
+
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			cmd := ops[0].(*JournaldInput).newCmd(context.Background(), nil).(*exec.Cmd)
+			require.Equal(t, append(baseArgs, tc.expectedArgs...), cmd.Args)
+		})
+	}
+}
+
+func TestJournaldInputConfigFilters(t *testing.T) {
+	expect := NewJournaldInputConfig("my_journald_input")
+	expect.Units = []string{"ssh", "docker"}
+	expect.Priority = "err"
+	expect.Grep = "failed"
+	expect.Matches = []map[string]string{
+		{"_SYSTEMD_SLICE": "system.slice"},
+	}
+
+	input := map[string]interface{}{
+		"id":       "my_journald_input",
+		"type":     "journald_input",
+		"start_at": "end",
+		"units":    []interface{}{"ssh", "docker"},
+		"priority": "err",
+		"grep":     "failed",
+		"matches": []interface{}{
+			map[string]interface{}{"_SYSTEMD_SLICE": "system.slice"},
+		},
+		"write_to":   "$body",
+		"attributes": map[string]interface{}{},
+		"resource":   map[string]interface{}{},
+	}
+
+	var actual JournaldInputConfig
+	err := helper.UnmarshalMapstructure(input, &actual)
+	require.NoError(t, err)
+	require.Equal(t, expect, &actual)
+}
@@ -16,17 +16,27 @@ package k8sevent
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
 	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	watch "k8s.io/apimachinery/pkg/watch"
+	coordinationv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/errors"
@@ -34,6 +44,19 @@ import (
 	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 )
 
+const (
+	// resourceVersionsKey is the key under which the resource versions of namespaces are persisted
+	resourceVersionsKey = "resource_versions"
+
+	// persistInterval is how often the resource versions of namespaces are persisted
+	persistInterval = time.Second
+
+	// namespaceFile holds the namespace of the pod the operator is running in
+	namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+	defaultLeaseName = "k8s-event-input"
+)
+
 func init() {
 	operator.Register("k8s_event_input", func() operator.Builder { return NewK8sEventsConfig("") })
 }
@@ -51,9 +74,24 @@ func NewK8sEventsConfig(operatorID string) *K8sEventsConfig {
 // K8sEventsConfig is the configuration of K8sEvents operator
 type K8sEventsConfig struct {
 	helper.InputConfig `yaml:",inline"`
-	Namespaces         []string        `json:"namespaces" yaml:"namespaces"`
-	DiscoverNamespaces bool            `json:"discover_namespaces" yaml:"discover_namespaces"`
-	DiscoveryInterval  helper.Duration `json:"discovery_interval" yaml:"discovery_interval"`
+
+	Namespaces         []string              `mapstructure:"namespaces"                json:"namespaces"                yaml:"namespaces"`
+	DiscoverNamespaces bool                  `mapstructure:"discover_namespaces"       json:"discover_namespaces"       yaml:"discover_namespaces"`
+	DiscoveryInterval  helper.Duration       `mapstructure:"discovery_interval"        json:"discovery_interval"        yaml:"discovery_interval"`
+	FieldSelector      string                `mapstructure:"field_selector,omitempty"  json:"field_selector,omitempty"  yaml:"field_selector,omitempty"`
+	LabelSelector      string                `mapstructure:"label_selector,omitempty"  json:"label_selector,omitempty"  yaml:"label_selector,omitempty"`
+	LeaderElection     *LeaderElectionConfig `mapstructure:"leader_election,omitempty" json:"leader_election,omitempty" yaml:"leader_election,omitempty"`
+}
+
+// LeaderElectionConfig is the configuration of the leader election between replicas,
+// so that only the replica holding the lease watches events
+type LeaderElectionConfig struct {
+	LeaseName      string          `mapstructure:"lease_name,omitempty"      json:"lease_name,omitempty"      yaml:"lease_name,omitempty"`
+	LeaseNamespace string          `mapstructure:"lease_namespace,omitempty" json:"lease_namespace,omitempty" yaml:"lease_namespace,omitempty"`
+	Identity       string          `mapstructure:"identity,omitempty"        json:"identity,omitempty"        yaml:"identity,omitempty"`
+	LeaseDuration  helper.Duration `mapstructure:"lease_duration,omitempty"  json:"lease_duration,omitempty"  yaml:"lease_duration,omitempty"`
+	RenewDeadline  helper.Duration `mapstructure:"renew_deadline,omitempty"  json:"renew_deadline,omitempty"  yaml:"renew_deadline,omitempty"`
+	RetryPeriod    helper.Duration `mapstructure:"retry_period,omitempty"    json:"retry_period,omitempty"    yaml:"retry_period,omitempty"`
 }
 
 // Build will build a k8s_event_input operator from the supplied configuration
@@ -67,23 +105,89 @@ func (c K8sEventsConfig) Build(context operator.BuildContext) ([]operator.Operat
 		return nil, fmt.Errorf("`namespaces` must be specified or `discover_namespaces` enabled")
 	}
 
+	if _, err := fields.ParseSelector(c.FieldSelector); err != nil {
+		return nil, fmt.Errorf("invalid field_selector '%s': %s", c.FieldSelector, err)
+	}
+
+	if _, err := labels.Parse(c.LabelSelector); err != nil {
+		return nil, fmt.Errorf("invalid label_selector '%s': %s", c.LabelSelector, err)
+	}
+
+	var leaderElection *LeaderElectionConfig
+	if c.LeaderElection != nil {
+		leaderElection, err = c.LeaderElection.build()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Explicitly configured namespaces are an allow-list, so
+	// namespaces are only discovered if none are configured
 	op := &K8sEvents{
 		InputOperator:      input,
-		namespaces:         c.Namespaces,
-		discoverNamespaces: c.DiscoverNamespaces,
+		namespaces:         append([]string{}, c.Namespaces...),
+		discoverNamespaces: c.DiscoverNamespaces && len(c.Namespaces) == 0,
 		discoveryInterval:  c.DiscoveryInterval,
+		fieldSelector:      c.FieldSelector,
+		labelSelector:      c.LabelSelector,
+		leaderElection:     leaderElection,
 	}
 
 	return []operator.Operator{op}, nil
 }
 
+// build returns a copy of the leader election configuration with default values applied
+func (c LeaderElectionConfig) build() (*LeaderElectionConfig, error) {
+	if c.LeaseName == "" {
+		c.LeaseName = defaultLeaseName
+	}
+
+	if c.Identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("get hostname for leader_election identity: %s", err)
+		}
+		c.Identity = hostname
+	}
+
+	if c.LeaseDuration.Raw() == 0 {
+		c.LeaseDuration = helper.NewDuration(15 * time.Second)
+	}
+	if c.RenewDeadline.Raw() == 0 {
+		c.RenewDeadline = helper.NewDuration(10 * time.Second)
+	}
+	if c.RetryPeriod.Raw() == 0 {
+		c.RetryPeriod = helper.NewDuration(2 * time.Second)
+	}
+
+	switch {
+	case c.LeaseDuration.Raw() < 0 || c.RenewDeadline.Raw() < 0 || c.RetryPeriod.Raw() < 0:
+		return nil, fmt.Errorf("leader_election durations must be positive")
+	case c.LeaseDuration.Raw() <= c.RenewDeadline.Raw():
+		return nil, fmt.Errorf("`lease_duration` must be greater than `renew_deadline`")
+	case c.RenewDeadline.Raw() <= time.Duration(leaderelection.JitterFactor*float64(c.RetryPeriod.Raw())):
+		return nil, fmt.Errorf("`renew_deadline` must be greater than %v times `retry_period`", leaderelection.JitterFactor)
+	}
+
+	return &c, nil
+}
+
 // K8sEvents is an operator for generating logs from k8s events
 type K8sEvents struct {
 	helper.InputOperator
 	client             corev1.CoreV1Interface
+	coordinationClient coordinationv1.CoordinationV1Interface
 	discoverNamespaces bool
 	discoveryInterval  helper.Duration
 	namespaces         []string
+	fieldSelector      string
+	labelSelector      string
+	leaderElection     *LeaderElectionConfig
+
+	resourceVersions   map[string]string
+	dirty              bool
+	resourceVersionMux sync.Mutex
+	persister          operator.Persister
 
 	cancel       func()
 	wg           sync.WaitGroup
@@ -91,9 +195,15 @@ type K8sEvents struct {
 }
 
 // Start implements the operator.Operator interface
-func (k *K8sEvents) Start(_ operator.Persister) error {
+func (k *K8sEvents) Start(persister operator.Persister) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	k.cancel = cancel
+	k.persister = persister
+	k.resourceVersions = make(map[string]string)
+
+	if err := k.loadResourceVersions(ctx); err != nil {
+		return errors.Wrap(err, "load resource versions")
+	}
 
 	// Currently, we only support running in the cluster. In contrast to the
 	// k8s_metadata_decorator, it may make sense to relax this restriction
@@ -111,6 +221,13 @@ func (k *K8sEvents) Start(_ operator.Persister) error {
 		return errors.Wrap(err, "build client")
 	}
 
+	if k.leaderElection != nil {
+		k.coordinationClient, err = coordinationv1.NewForConfig(config)
+		if err != nil {
+			return errors.Wrap(err, "build coordination client")
+		}
+	}
+
 	if k.discoverNamespaces {
 		namespaces, err := listNamespaces(ctx, k.client)
 		if err != nil {
@@ -133,15 +250,18 @@ func (k *K8sEvents) Start(_ operator.Persister) error {
 		testWatcher.Stop()
 	}
 
-	for _, ns := range k.namespaces {
-		k.startWatchingNamespace(ctx, ns)
-	}
+	k.startPersisting(ctx)
 
-	// Find and watch namespaces if in discovery mode
-	if k.discoverNamespaces {
-		k.startFindingNamespaces(ctx, k.client)
+	if k.leaderElection == nil {
+		k.startWatching(ctx)
+		return nil
 	}
 
+	elector, err := k.newLeaderElector()
+	if err != nil {
+		return errors.Wrap(err, "build leader elector")
+	}
+	k.startElecting(ctx, elector)
 	return nil
 }
 
@@ -149,7 +269,83 @@ func (k *K8sEvents) Start(_ operator.Persister) error {
 func (k *K8sEvents) Stop() error {
 	k.cancel()
 	k.wg.Wait()
-	return nil
+	return k.persistResourceVersions(context.Background())
+}
+
+// startWatching watches the events of each namespace, and finds
+// namespaces to watch if in discovery mode, until the context is canceled
+func (k *K8sEvents) startWatching(ctx context.Context) {
+	k.namespaceMux.Lock()
+	namespaces := append([]string{}, k.namespaces...)
+	k.namespaceMux.Unlock()
+
+	for _, ns := range namespaces {
+		k.startWatchingNamespace(ctx, ns)
+	}
+
+	// Find and watch namespaces if in discovery mode
+	if k.discoverNamespaces {
+		k.startFindingNamespaces(ctx, k.client)
+	}
+}
+
+// newLeaderElector creates a leader elector which watches events while it holds the lease
+func (k *K8sEvents) newLeaderElector() (*leaderelection.LeaderElector, error) {
+	namespace := k.leaderElection.LeaseNamespace
+	if namespace == "" {
+		data, err := ioutil.ReadFile(namespaceFile)
+		if err != nil {
+			return nil, fmt.Errorf("`lease_namespace` not specified, and the pod namespace could not be read: %s", err)
+		}
+		namespace = strings.TrimSpace(string(data))
+	}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      k.leaderElection.LeaseName,
+			Namespace: namespace,
+		},
+		Client: k.coordinationClient,
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: k.leaderElection.Identity,
+		},
+	}
+
+	return leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   k.leaderElection.LeaseDuration.Raw(),
+		RenewDeadline:   k.leaderElection.RenewDeadline.Raw(),
+		RetryPeriod:     k.leaderElection.RetryPeriod.Raw(),
+		ReleaseOnCancel: true,
+		Name:            k.leaderElection.LeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				k.Infow("Acquired lease, watching events", "lease", k.leaderElection.LeaseName)
+				k.startWatching(ctx)
+			},
+			OnStoppedLeading: func() {
+				k.Infow("Released lease, stopped watching events", "lease", k.leaderElection.LeaseName)
+			},
+		},
+	})
+}
+
+// startElecting creates a goroutine that contends for the lease until the context is
+// canceled. Events are watched with the context of each term in which the lease is held.
+func (k *K8sEvents) startElecting(ctx context.Context, elector *leaderelection.LeaderElector) {
+	k.wg.Add(1)
+	go func() {
+		defer k.wg.Done()
+		for {
+			elector.Run(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+		}
+	}()
 }
 
 // listNamespaces gets a full list of namespaces from the client
@@ -210,18 +406,36 @@ func (k *K8sEvents) startWatchingNamespace(ctx context.Context, ns string) {
 			default:
 			}
 
-			watcher, err := k.client.Events(ns).Watch(ctx, metav1.ListOptions{})
-			if err != nil {
+			watcher, err := k.client.Events(ns).Watch(ctx, metav1.ListOptions{
+				FieldSelector:       k.fieldSelector,
+				LabelSelector:       k.labelSelector,
+				ResourceVersion:     k.resourceVersion(ns),
+				AllowWatchBookmarks: true,
+			})
+			switch {
+			case err == nil:
+			case ctx.Err() != nil:
+				return
+			case isExpired(err):
+				k.Warnw("Resource version expired, resuming from the events retained by the server", "namespace", ns)
+				k.setResourceVersion(ns, "")
+				continue
+			default:
 				k.Errorw("Failed to start watcher", zap.Error(err))
 				k.removeNamespace(ns)
 				return
 			}
 
-			k.consumeWatchEvents(ctx, watcher.ResultChan())
+			k.consumeWatchEvents(ctx, ns, watcher)
 		}
 	}()
 }
 
+// isExpired returns true if an error indicates that a resource version is too old to watch from
+func isExpired(err error) bool {
+	return apierrors.IsResourceExpired(err) || apierrors.IsGone(err)
+}
+
 // addNamespace will add a namespace.
 func (k *K8sEvents) addNamespace(namespace string) {
 	k.namespaceMux.Lock()
@@ -257,17 +471,43 @@ func (k *K8sEvents) removeNamespace(namespace string) {
 }
 
 // consumeWatchEvents will read events from the watcher channel until the channel is closed
-// or the context is canceled
-func (k *K8sEvents) consumeWatchEvents(ctx context.Context, events <-chan watch.Event) {
+// or the context is canceled. The resource version of each event is recorded, so that
+// the namespace is watched from it when the watcher is restarted.
+func (k *K8sEvents) consumeWatchEvents(ctx context.Context, ns string, watcher watch.Interface) {
+	defer watcher.Stop()
+
+	events := watcher.ResultChan()
 	for {
 		select {
 		case event, ok := <-events:
 			if !ok {
-				k.Error("Watcher channel closed")
+				k.Debugw("Watcher channel closed", "namespace", ns)
 				return
 			}
 
-			typedEvent := event.Object.(*apiv1.Event)
+			switch event.Type {
+			case watch.Error:
+				err := apierrors.FromObject(event.Object)
+				if isExpired(err) {
+					k.Warnw("Resource version expired, resuming from the events retained by the server", "namespace", ns)
+					k.setResourceVersion(ns, "")
+				} else {
+					k.Errorw("Watcher error", zap.Error(err))
+				}
+				return
+			case watch.Bookmark:
+				if meta, err := metaAccessor(event.Object); err == nil {
+					k.setResourceVersion(ns, meta.GetResourceVersion())
+				}
+				continue
+			}
+
+			typedEvent, ok := event.Object.(*apiv1.Event)
+			if !ok {
+				k.Errorw("Unexpected object in watch event", "type", fmt.Sprintf("%T", event.Object))
+				continue
+			}
+
 			body, err := runtime.DefaultUnstructuredConverter.ToUnstructured(event.Object)
 			if err != nil {
 				k.Error("Failed to convert event to map", zap.Error(err))
@@ -293,12 +533,94 @@ func (k *K8sEvents) consumeWatchEvents(ctx context.Context, events <-chan watch.
 			entry.AddAttribute("event_type", string(event.Type))
 			k.populateResource(typedEvent, entry)
 			k.Write(ctx, entry)
+			k.setResourceVersion(ns, typedEvent.ResourceVersion)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// metaAccessor returns the object metadata of a watched object
+func metaAccessor(obj runtime.Object) (metav1.Object, error) {
+	accessor, ok := obj.(metav1.ObjectMetaAccessor)
+	if !ok {
+		return nil, fmt.Errorf("object %T has no metadata", obj)
+	}
+	return accessor.GetObjectMeta(), nil
+}
+
+// resourceVersion returns the resource version from which to watch a namespace
+func (k *K8sEvents) resourceVersion(ns string) string {
+	k.resourceVersionMux.Lock()
+	defer k.resourceVersionMux.Unlock()
+	return k.resourceVersions[ns]
+}
+
+// setResourceVersion records the resource version from which to watch a namespace
+func (k *K8sEvents) setResourceVersion(ns, version string) {
+	k.resourceVersionMux.Lock()
+	defer k.resourceVersionMux.Unlock()
+
+	if current, ok := k.resourceVersions[ns]; !ok || current != version {
+		k.resourceVersions[ns] = version
+		k.dirty = true
+	}
+}
+
+// startPersisting creates a goroutine that periodically persists the resource versions of namespaces
+func (k *K8sEvents) startPersisting(ctx context.Context) {
+	k.wg.Add(1)
+	go func() {
+		defer k.wg.Done()
+
+		ticker := time.NewTicker(persistInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := k.persistResourceVersions(ctx); err != nil {
+					k.Errorw("Failed to persist resource versions", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// loadResourceVersions loads the resource versions of namespaces from the persister
+func (k *K8sEvents) loadResourceVersions(ctx context.Context) error {
+	data, err := k.persister.Get(ctx, resourceVersionsKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &k.resourceVersions)
+}
+
+// persistResourceVersions saves the resource versions of namespaces to the persister, if they have changed
+func (k *K8sEvents) persistResourceVersions(ctx context.Context) error {
+	k.resourceVersionMux.Lock()
+	defer k.resourceVersionMux.Unlock()
+
+	if !k.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(k.resourceVersions)
+	if err != nil {
+		return err
+	}
+	if err := k.persister.Set(ctx, resourceVersionsKey, data); err != nil {
+		return err
+	}
+	k.dirty = false
+	return nil
+}
+
 // populateResource uses the keys from Event.ObjectMeta to populate the resource of the entry
 func (k *K8sEvents) populateResource(event *apiv1.Event, entry *entry.Entry) {
 	io := event.InvolvedObject
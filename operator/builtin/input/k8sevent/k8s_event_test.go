@@ -16,6 +16,8 @@ package k8sevent
 
 import (
 	"context"
+	"net/http"
+	"sync"
 	"testing"
 	"time"
 
@@ -25,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
 	fakev1 "k8s.io/client-go/kubernetes/typed/core/v1/fake"
 	fakeTest "k8s.io/client-go/testing"
 
@@ -75,8 +78,10 @@ func TestWatchNamespace(t *testing.T) {
 		client: &fakev1.FakeCoreV1{
 			Fake: fakeAPI,
 		},
-		namespaces: []string{"test_namespace"},
-		cancel:     cancel,
+		namespaces:       []string{"test_namespace"},
+		resourceVersions: map[string]string{},
+		persister:        testutil.NewMockPersister("test"),
+		cancel:           cancel,
 	}
 
 	fake := testutil.NewFakeOutput(t)
@@ -123,3 +128,371 @@ func TestListNamespaces(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []string{"test1", "test2"}, namespaces)
 }
+
+func newTestEvent(resourceVersion string) runtime.Object {
+	return &apiv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "testpodname." + resourceVersion,
+			ResourceVersion: resourceVersion,
+		},
+		InvolvedObject: apiv1.ObjectReference{
+			Kind:      "Pod",
+			Name:      "testpodname",
+			Namespace: "test_namespace",
+		},
+		LastTimestamp: metav1.Time{
+			Time: fakeTime,
+		},
+	}
+}
+
+// watchRecorder returns a watcher of the given events for each watch,
+// and records the options of each watch. If close is set, watchers
+// are closed after their events are read.
+type watchRecorder struct {
+	sync.Mutex
+	options []fakeTest.WatchRestrictions
+	events  [][]watch.Event
+	close   bool
+}
+
+func (w *watchRecorder) reactor(action fakeTest.Action) (bool, watch.Interface, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.options = append(w.options, action.(fakeTest.WatchAction).GetWatchRestrictions())
+
+	watcher := watch.NewFakeWithChanSize(10, false)
+	if len(w.events) > 0 {
+		for _, event := range w.events[0] {
+			watcher.Action(event.Type, event.Object)
+		}
+		w.events = w.events[1:]
+		if w.close {
+			watcher.Stop()
+		}
+	}
+	return true, watcher, nil
+}
+
+func (w *watchRecorder) watches() []fakeTest.WatchRestrictions {
+	w.Lock()
+	defer w.Unlock()
+	return append([]fakeTest.WatchRestrictions{}, w.options...)
+}
+
+func newTestOperator(t *testing.T, recorder *watchRecorder) *K8sEvents {
+	cfg := NewK8sEventsConfig("test_id")
+	cfg.Namespaces = []string{"test_namespace"}
+	cfg.FieldSelector = "type=Warning"
+	cfg.LabelSelector = "app=test"
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*K8sEvents)
+
+	fakeAPI := &fakeTest.Fake{}
+	fakeAPI.AddWatchReactor("events", recorder.reactor)
+	op.client = &fakev1.FakeCoreV1{Fake: fakeAPI}
+	op.resourceVersions = map[string]string{}
+	op.persister = testutil.NewMockPersister("test")
+	return op
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*K8sEventsConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *K8sEventsConfig) {},
+			false,
+		},
+		{
+			"NoNamespaces",
+			func(cfg *K8sEventsConfig) {
+				cfg.DiscoverNamespaces = false
+			},
+			true,
+		},
+		{
+			"Selectors",
+			func(cfg *K8sEventsConfig) {
+				cfg.FieldSelector = "involvedObject.kind=Pod,type!=Normal"
+				cfg.LabelSelector = "app in (web, api)"
+			},
+			false,
+		},
+		{
+			"InvalidFieldSelector",
+			func(cfg *K8sEventsConfig) {
+				cfg.FieldSelector = "type"
+			},
+			true,
+		},
+		{
+			"InvalidLabelSelector",
+			func(cfg *K8sEventsConfig) {
+				cfg.LabelSelector = "app in web"
+			},
+			true,
+		},
+		{
+			"LeaderElection",
+			func(cfg *K8sEventsConfig) {
+				cfg.LeaderElection = &LeaderElectionConfig{}
+			},
+			false,
+		},
+		{
+			"LeaseDurationNotGreaterThanRenewDeadline",
+			func(cfg *K8sEventsConfig) {
+				cfg.LeaderElection = &LeaderElectionConfig{
+					LeaseDuration: helper.NewDuration(10 * time.Second),
+				}
+			},
+			true,
+		},
+		{
+			"RenewDeadlineNotGreaterThanRetryPeriod",
+			func(cfg *K8sEventsConfig) {
+				cfg.LeaderElection = &LeaderElectionConfig{
+					RetryPeriod: helper.NewDuration(9 * time.Second),
+				}
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewK8sEventsConfig("test_id")
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestBuildLeaderElectionDefaults(t *testing.T) {
+	cfg := NewK8sEventsConfig("test_id")
+	cfg.LeaderElection = &LeaderElectionConfig{LeaseNamespace: "monitoring"}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	leaderElection := ops[0].(*K8sEvents).leaderElection
+	require.Equal(t, defaultLeaseName, leaderElection.LeaseName)
+	require.Equal(t, "monitoring", leaderElection.LeaseNamespace)
+	require.NotEmpty(t, leaderElection.Identity)
+	require.Equal(t, 15*time.Second, leaderElection.LeaseDuration.Raw())
+	require.Equal(t, 10*time.Second, leaderElection.RenewDeadline.Raw())
+	require.Equal(t, 2*time.Second, leaderElection.RetryPeriod.Raw())
+}
+
+func TestBuildNamespaceAllowList(t *testing.T) {
+	cfg := NewK8sEventsConfig("test_id")
+	cfg.Namespaces = []string{"default", "kube-system"}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	op := ops[0].(*K8sEvents)
+	require.Equal(t, []string{"default", "kube-system"}, op.namespaces)
+	require.False(t, op.discoverNamespaces)
+}
+
+func TestWatchSelectorsAndResourceVersion(t *testing.T) {
+	recorder := &watchRecorder{
+		events: [][]watch.Event{
+			{
+				{Type: watch.Added, Object: newTestEvent("10")},
+				{Type: watch.Added, Object: newTestEvent("11")},
+			},
+		},
+	}
+	op := newTestOperator(t, recorder)
+	op.resourceVersions["test_namespace"] = "9"
+
+	fake := testutil.NewFakeOutput(t)
+	op.OutputOperators = []operator.Operator{fake}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	op.cancel = cancel
+	op.startWatchingNamespace(ctx, "test_namespace")
+
+	for i := 0; i < 2; i++ {
+		select {
+		case entry := <-fake.Received:
+			require.Equal(t, "ADDED", entry.Attributes["event_type"])
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for entry")
+		}
+	}
+	require.Eventually(t, func() bool {
+		return op.resourceVersion("test_namespace") == "11"
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, op.Stop())
+
+	watches := recorder.watches()
+	require.NotEmpty(t, watches)
+	require.Equal(t, "9", watches[0].ResourceVersion)
+	require.Equal(t, "type=Warning", watches[0].Fields.String())
+	require.Equal(t, "app=test", watches[0].Labels.String())
+
+	data, err := op.persister.Get(context.Background(), resourceVersionsKey)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"test_namespace":"11"}`, string(data))
+}
+
+func TestWatchResumesAfterClose(t *testing.T) {
+	recorder := &watchRecorder{
+		events: [][]watch.Event{
+			{
+				{Type: watch.Added, Object: newTestEvent("10")},
+			},
+			{
+				{Type: watch.Bookmark, Object: &apiv1.Event{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "15"}}},
+			},
+		},
+		close: true,
+	}
+	op := newTestOperator(t, recorder)
+
+	fake := testutil.NewFakeOutput(t)
+	op.OutputOperators = []operator.Operator{fake}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	op.cancel = cancel
+	op.startWatchingNamespace(ctx, "test_namespace")
+	defer op.Stop()
+
+	select {
+	case <-fake.Received:
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+
+	// Each watcher is closed after its events, and the namespace
+	// is watched again from the last resource version
+	require.Eventually(t, func() bool {
+		return len(recorder.watches()) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	watches := recorder.watches()
+	require.Equal(t, "", watches[0].ResourceVersion)
+	require.Equal(t, "10", watches[1].ResourceVersion)
+	require.Equal(t, "15", watches[2].ResourceVersion)
+	require.Equal(t, "15", op.resourceVersion("test_namespace"))
+}
+
+func TestWatchExpiredResourceVersion(t *testing.T) {
+	expired := &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusGone,
+		Reason:  metav1.StatusReasonExpired,
+		Message: "too old resource version",
+	}
+	recorder := &watchRecorder{
+		events: [][]watch.Event{
+			{
+				{Type: watch.Error, Object: expired},
+			},
+			{
+				{Type: watch.Added, Object: newTestEvent("20")},
+			},
+		},
+	}
+	op := newTestOperator(t, recorder)
+	op.resourceVersions["test_namespace"] = "5"
+
+	fake := testutil.NewFakeOutput(t)
+	op.OutputOperators = []operator.Operator{fake}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	op.cancel = cancel
+	op.startWatchingNamespace(ctx, "test_namespace")
+	defer op.Stop()
+
+	select {
+	case <-fake.Received:
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+
+	watches := recorder.watches()
+	require.Len(t, watches, 2)
+	require.Equal(t, "5", watches[0].ResourceVersion)
+	require.Equal(t, "", watches[1].ResourceVersion)
+}
+
+func TestLoadResourceVersions(t *testing.T) {
+	persister := testutil.NewMockPersister("test")
+	require.NoError(t, persister.Set(context.Background(), resourceVersionsKey, []byte(`{"default":"42"}`)))
+
+	op := &K8sEvents{persister: persister, resourceVersions: map[string]string{}}
+	require.NoError(t, op.loadResourceVersions(context.Background()))
+	require.Equal(t, "42", op.resourceVersion("default"))
+}
+
+func TestLeaderElection(t *testing.T) {
+	cfg := NewK8sEventsConfig("test_id")
+	cfg.Namespaces = []string{"test_namespace"}
+	cfg.LeaderElection = &LeaderElectionConfig{
+		LeaseNamespace: "default",
+		Identity:       "replica-1",
+		LeaseDuration:  helper.NewDuration(3 * time.Second),
+		RenewDeadline:  helper.NewDuration(2 * time.Second),
+		RetryPeriod:    helper.NewDuration(100 * time.Millisecond),
+	}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*K8sEvents)
+
+	recorder := &watchRecorder{
+		events: [][]watch.Event{
+			{
+				{Type: watch.Added, Object: newTestEvent("10")},
+			},
+		},
+	}
+	clientset := fakeclient.NewSimpleClientset()
+	clientset.PrependWatchReactor("events", recorder.reactor)
+	op.client = clientset.CoreV1()
+	op.coordinationClient = clientset.CoordinationV1()
+	op.resourceVersions = map[string]string{}
+	op.persister = testutil.NewMockPersister("test")
+
+	fake := testutil.NewFakeOutput(t)
+	op.OutputOperators = []operator.Operator{fake}
+
+	elector, err := op.newLeaderElector()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	op.cancel = cancel
+	op.startElecting(ctx, elector)
+
+	select {
+	case <-fake.Received:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+
+	lease, err := clientset.CoordinationV1().Leases("default").Get(context.Background(), defaultLeaseName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "replica-1", *lease.Spec.HolderIdentity)
+
+	require.NoError(t, op.Stop())
+
+	// The lease is released when the operator stops
+	lease, err = clientset.CoordinationV1().Leases("default").Get(context.Background(), defaultLeaseName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Empty(t, *lease.Spec.HolderIdentity)
+}
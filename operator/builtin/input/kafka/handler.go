@@ -0,0 +1,157 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+)
+
+// groupHandler handles the partitions claimed by the consumer group
+type groupHandler struct {
+	input *KafkaInput
+}
+
+// Setup resumes each claimed partition from its persisted offset, if it is later
+// than the offset committed to kafka. Offsets which are committed to kafka can be
+// lost when the operator is stopped before they are committed.
+func (h *groupHandler) Setup(session sarama.ConsumerGroupSession) error {
+	for topic, partitions := range session.Claims() {
+		for _, partition := range partitions {
+			if offset, ok := h.input.offsets.get(topic, partition); ok {
+				session.MarkOffset(topic, partition, offset, "")
+			}
+		}
+	}
+	return nil
+}
+
+// Cleanup persists the consumed offsets at the end of a session
+func (h *groupHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	return h.input.offsets.persist(context.Background(), h.input.persister)
+}
+
+// ConsumeClaim creates an entry from each message of a claimed partition
+func (h *groupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case <-session.Context().Done():
+			return nil
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			h.input.handleMessage(session.Context(), msg)
+			session.MarkMessage(msg, "")
+			h.input.offsets.set(msg.Topic, msg.Partition, msg.Offset+1)
+		}
+	}
+}
+
+// handleMessage creates an entry from a message
+func (k *KafkaInput) handleMessage(ctx context.Context, msg *sarama.ConsumerMessage) {
+	decoded, err := k.encoding.Decode(msg.Value)
+	if err != nil {
+		k.Errorw("Failed to decode message", zap.Error(err))
+		return
+	}
+
+	entry, err := k.NewEntry(decoded)
+	if err != nil {
+		k.Errorw("Failed to create entry", zap.Error(err))
+		return
+	}
+
+	entry.AddAttribute("kafka.topic", msg.Topic)
+	entry.AddAttribute("kafka.partition", strconv.FormatInt(int64(msg.Partition), 10))
+	for _, header := range msg.Headers {
+		if attribute, ok := k.headerAttributes[string(header.Key)]; ok {
+			entry.AddAttribute(attribute, string(header.Value))
+		}
+	}
+
+	k.Write(ctx, entry)
+}
+
+// offsetStore holds the next offset to consume from each partition
+type offsetStore struct {
+	sync.Mutex
+	offsets map[string]map[int32]int64
+	dirty   bool
+}
+
+func newOffsetStore() *offsetStore {
+	return &offsetStore{
+		offsets: make(map[string]map[int32]int64),
+	}
+}
+
+func (s *offsetStore) get(topic string, partition int32) (int64, bool) {
+	s.Lock()
+	defer s.Unlock()
+	offset, ok := s.offsets[topic][partition]
+	return offset, ok
+}
+
+func (s *offsetStore) set(topic string, partition int32, offset int64) {
+	s.Lock()
+	defer s.Unlock()
+	if s.offsets[topic] == nil {
+		s.offsets[topic] = make(map[int32]int64)
+	}
+	s.offsets[topic][partition] = offset
+	s.dirty = true
+}
+
+// load loads the offsets from the persister
+func (s *offsetStore) load(ctx context.Context, persister operator.Persister) error {
+	data, err := persister.Get(ctx, offsetsKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	return json.Unmarshal(data, &s.offsets)
+}
+
+// persist saves the offsets to the persister, if they have changed
+func (s *offsetStore) persist(ctx context.Context, persister operator.Persister) error {
+	s.Lock()
+	defer s.Unlock()
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(s.offsets)
+	if err != nil {
+		return err
+	}
+	if err := persister.Set(ctx, offsetsKey, data); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
@@ -0,0 +1,438 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/jpillora/backoff"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	startAtBeginning = "beginning"
+	startAtEnd       = "end"
+
+	saslPlain       = "PLAIN"
+	saslScramSHA256 = "SCRAM-SHA-256"
+	saslScramSHA512 = "SCRAM-SHA-512"
+
+	// offsetsKey is the key under which consumed offsets are persisted
+	offsetsKey = "offsets"
+
+	// persistInterval is how often consumed offsets are persisted
+	persistInterval = time.Second
+)
+
+func init() {
+	operator.Register("kafka_input", func() operator.Builder { return NewKafkaInputConfig("") })
+}
+
+// NewKafkaInputConfig creates a new Kafka input config with default values
+func NewKafkaInputConfig(operatorID string) *KafkaInputConfig {
+	return &KafkaInputConfig{
+		InputConfig:          helper.NewInputConfig(operatorID, "kafka_input"),
+		GroupID:              "opentelemetry-log-collection",
+		ClientID:             "opentelemetry-log-collection",
+		ProtocolVersion:      "2.0.0",
+		StartAt:              startAtEnd,
+		TopicRefreshInterval: helper.NewDuration(time.Minute),
+		Encoding:             helper.NewEncodingConfig(),
+	}
+}
+
+// KafkaInputConfig is the configuration of a kafka input operator.
+type KafkaInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	Brokers              []string                `mapstructure:"brokers,omitempty"                json:"brokers,omitempty"                yaml:"brokers,omitempty"`
+	Topics               []string                `mapstructure:"topics,omitempty"                 json:"topics,omitempty"                 yaml:"topics,omitempty"`
+	TopicPattern         string                  `mapstructure:"topic_pattern,omitempty"          json:"topic_pattern,omitempty"          yaml:"topic_pattern,omitempty"`
+	TopicRefreshInterval helper.Duration         `mapstructure:"topic_refresh_interval,omitempty" json:"topic_refresh_interval,omitempty" yaml:"topic_refresh_interval,omitempty"`
+	GroupID              string                  `mapstructure:"group_id,omitempty"               json:"group_id,omitempty"               yaml:"group_id,omitempty"`
+	ClientID             string                  `mapstructure:"client_id,omitempty"              json:"client_id,omitempty"              yaml:"client_id,omitempty"`
+	ProtocolVersion      string                  `mapstructure:"protocol_version,omitempty"       json:"protocol_version,omitempty"       yaml:"protocol_version,omitempty"`
+	StartAt              string                  `mapstructure:"start_at,omitempty"               json:"start_at,omitempty"               yaml:"start_at,omitempty"`
+	TLS                  *helper.TLSClientConfig `mapstructure:"tls,omitempty"                    json:"tls,omitempty"                    yaml:"tls,omitempty"`
+	SASL                 *SASLConfig             `mapstructure:"sasl,omitempty"                   json:"sasl,omitempty"                   yaml:"sasl,omitempty"`
+	HeaderAttributes     map[string]string       `mapstructure:"header_attributes,omitempty"      json:"header_attributes,omitempty"      yaml:"header_attributes,omitempty"`
+	Encoding             helper.EncodingConfig   `mapstructure:",squash,omitempty"                json:",inline,omitempty"                yaml:",inline,omitempty"`
+}
+
+// SASLConfig is the configuration of SASL authentication with the brokers
+type SASLConfig struct {
+	Mechanism string `mapstructure:"mechanism,omitempty" json:"mechanism,omitempty" yaml:"mechanism,omitempty"`
+	Username  string `mapstructure:"username,omitempty"  json:"username,omitempty"  yaml:"username,omitempty"`
+	Password  string `mapstructure:"password,omitempty"  json:"password,omitempty"  yaml:"password,omitempty"`
+}
+
+// Build will build a kafka input operator.
+func (c KafkaInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Brokers) == 0 {
+		return nil, fmt.Errorf("missing required parameter 'brokers'")
+	}
+
+	if len(c.Topics) == 0 && c.TopicPattern == "" {
+		return nil, fmt.Errorf("one of 'topics' or 'topic_pattern' is required")
+	}
+	if len(c.Topics) > 0 && c.TopicPattern != "" {
+		return nil, fmt.Errorf("only one of 'topics' and 'topic_pattern' can be set")
+	}
+
+	var topicPattern *regexp.Regexp
+	if c.TopicPattern != "" {
+		topicPattern, err = regexp.Compile(c.TopicPattern)
+		if err != nil {
+			return nil, fmt.Errorf("compiling topic_pattern: %s", err)
+		}
+		if c.TopicRefreshInterval.Raw() <= 0 {
+			return nil, fmt.Errorf("`topic_refresh_interval` must be positive")
+		}
+	}
+
+	if c.GroupID == "" {
+		return nil, fmt.Errorf("missing required parameter 'group_id'")
+	}
+
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.ClientID = c.ClientID
+	saramaConfig.Consumer.Return.Errors = true
+
+	saramaConfig.Version, err = sarama.ParseKafkaVersion(c.ProtocolVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protocol_version '%s'", c.ProtocolVersion)
+	}
+
+	switch c.StartAt {
+	case startAtBeginning:
+		saramaConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+	case startAtEnd:
+		saramaConfig.Consumer.Offsets.Initial = sarama.OffsetNewest
+	default:
+		return nil, fmt.Errorf("invalid start_at location '%s'", c.StartAt)
+	}
+
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.LoadTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig != nil {
+			saramaConfig.Net.TLS.Enable = true
+			saramaConfig.Net.TLS.Config = tlsConfig
+		}
+	}
+
+	if c.SASL != nil {
+		if err := c.SASL.configure(saramaConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := saramaConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid kafka configuration: %s", err)
+	}
+
+	encoding, err := c.Encoding.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	kafkaInput := &KafkaInput{
+		InputOperator:        inputOperator,
+		brokers:              c.Brokers,
+		topics:               c.Topics,
+		topicPattern:         topicPattern,
+		topicRefreshInterval: c.TopicRefreshInterval.Raw(),
+		groupID:              c.GroupID,
+		saramaConfig:         saramaConfig,
+		headerAttributes:     c.HeaderAttributes,
+		encoding:             encoding,
+		offsets:              newOffsetStore(),
+		backoff: backoff.Backoff{
+			Max: 30 * time.Second,
+		},
+	}
+
+	return []operator.Operator{kafkaInput}, nil
+}
+
+// configure enables SASL authentication in the sarama configuration
+func (c SASLConfig) configure(saramaConfig *sarama.Config) error {
+	if c.Username == "" {
+		return fmt.Errorf("missing required parameter 'sasl.username'")
+	}
+
+	saramaConfig.Net.SASL.Enable = true
+	saramaConfig.Net.SASL.User = c.Username
+	saramaConfig.Net.SASL.Password = c.Password
+
+	switch c.Mechanism {
+	case saslPlain, "":
+		saramaConfig.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	case saslScramSHA256:
+		saramaConfig.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		saramaConfig.Net.SASL.SCRAMClientGeneratorFunc = newScramClient(sha256Generator)
+	case saslScramSHA512:
+		saramaConfig.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+		saramaConfig.Net.SASL.SCRAMClientGeneratorFunc = newScramClient(sha512Generator)
+	default:
+		return fmt.Errorf("invalid sasl mechanism '%s'", c.Mechanism)
+	}
+	return nil
+}
+
+// KafkaInput is an operator that consumes log entries from kafka topics.
+type KafkaInput struct {
+	helper.InputOperator
+	brokers              []string
+	topics               []string
+	topicPattern         *regexp.Regexp
+	topicRefreshInterval time.Duration
+	groupID              string
+	saramaConfig         *sarama.Config
+	headerAttributes     map[string]string
+	encoding             helper.Encoding
+
+	client    sarama.Client
+	group     sarama.ConsumerGroup
+	offsets   *offsetStore
+	persister operator.Persister
+	backoff   backoff.Backoff
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// Start will start consuming from the kafka topics.
+func (k *KafkaInput) Start(persister operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	k.cancel = cancel
+	k.persister = persister
+
+	if err := k.offsets.load(ctx, persister); err != nil {
+		return fmt.Errorf("failed to load offsets: %s", err)
+	}
+
+	client, err := sarama.NewClient(k.brokers, k.saramaConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to kafka: %w", err)
+	}
+	k.client = client
+
+	group, err := sarama.NewConsumerGroupFromClient(k.groupID, client)
+	if err != nil {
+		client.Close()
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+	k.group = group
+
+	k.goConsume(ctx)
+	k.goHandleErrors(ctx)
+	k.goPersistOffsets(ctx)
+	return nil
+}
+
+// Stop will stop consuming from the kafka topics.
+func (k *KafkaInput) Stop() error {
+	k.cancel()
+	if k.group == nil {
+		return nil
+	}
+
+	if err := k.group.Close(); err != nil {
+		k.Errorw("Failed to close consumer group", zap.Error(err))
+	}
+	k.wg.Wait()
+
+	if err := k.client.Close(); err != nil {
+		k.Errorw("Failed to close kafka client", zap.Error(err))
+	}
+	return k.offsets.persist(context.Background(), k.persister)
+}
+
+// goConsume consumes from the subscribed topics until the context is done.
+// A consumer group session ends when the group is rebalanced, and when the
+// topics which match the topic pattern change, after which a new one is started.
+func (k *KafkaInput) goConsume(ctx context.Context) {
+	k.wg.Add(1)
+
+	go func() {
+		defer k.wg.Done()
+
+		handler := &groupHandler{input: k}
+		for {
+			topics, err := k.resolveTopics()
+			switch {
+			case err != nil:
+				k.Errorw("Failed to list topics", zap.Error(err))
+				k.wait(ctx, k.backoff.Duration())
+			case len(topics) == 0:
+				k.Debugw("No topics match the topic pattern", "topic_pattern", k.topicPattern.String())
+				k.wait(ctx, k.topicRefreshInterval)
+			default:
+				sessionCtx, cancel := context.WithCancel(ctx)
+				if k.topicPattern != nil {
+					k.goWatchTopics(sessionCtx, topics, cancel)
+				}
+				err := k.group.Consume(sessionCtx, topics, handler)
+				cancel()
+				if err != nil && ctx.Err() == nil {
+					k.Errorw("Consumer group session failed", zap.Error(err))
+					k.wait(ctx, k.backoff.Duration())
+				} else {
+					k.backoff.Reset()
+				}
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+}
+
+// goWatchTopics cancels the consumer group session when the topics
+// which match the topic pattern are no longer the subscribed topics
+func (k *KafkaInput) goWatchTopics(ctx context.Context, subscribed []string, cancel context.CancelFunc) {
+	k.wg.Add(1)
+
+	go func() {
+		defer k.wg.Done()
+
+		ticker := time.NewTicker(k.topicRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				topics, err := k.resolveTopics()
+				if err != nil {
+					k.Errorw("Failed to list topics", zap.Error(err))
+					continue
+				}
+				if !equalTopics(topics, subscribed) {
+					k.Infow("Subscribed topics changed", "topics", topics)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+}
+
+// goHandleErrors logs the errors returned by the consumer group.
+func (k *KafkaInput) goHandleErrors(ctx context.Context) {
+	k.wg.Add(1)
+
+	go func() {
+		defer k.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err, ok := <-k.group.Errors():
+				if !ok {
+					return
+				}
+				k.Errorw("Consumer group error", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// goPersistOffsets periodically persists the consumed offsets.
+func (k *KafkaInput) goPersistOffsets(ctx context.Context) {
+	k.wg.Add(1)
+
+	go func() {
+		defer k.wg.Done()
+
+		ticker := time.NewTicker(persistInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := k.offsets.persist(ctx, k.persister); err != nil {
+					k.Errorw("Failed to persist offsets", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// resolveTopics returns the configured topics, or the topics
+// which match the topic pattern, in sorted order
+func (k *KafkaInput) resolveTopics() ([]string, error) {
+	if k.topicPattern == nil {
+		return k.topics, nil
+	}
+
+	if err := k.client.RefreshMetadata(); err != nil {
+		return nil, err
+	}
+	all, err := k.client.Topics()
+	if err != nil {
+		return nil, err
+	}
+	return matchTopics(all, k.topicPattern), nil
+}
+
+func (k *KafkaInput) wait(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+// matchTopics returns the topics which match the pattern, in sorted order
+func matchTopics(topics []string, pattern *regexp.Regexp) []string {
+	matched := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		if pattern.MatchString(topic) {
+			matched = append(matched, topic)
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+func equalTopics(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
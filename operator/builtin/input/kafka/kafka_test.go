@@ -0,0 +1,289 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*KafkaInputConfig)
+		expectErr bool
+	}{
+		{
+			"default",
+			func(c *KafkaInputConfig) {},
+			false,
+		},
+		{
+			"missing-brokers",
+			func(c *KafkaInputConfig) {
+				c.Brokers = nil
+			},
+			true,
+		},
+		{
+			"missing-topics",
+			func(c *KafkaInputConfig) {
+				c.Topics = nil
+			},
+			true,
+		},
+		{
+			"topic-pattern",
+			func(c *KafkaInputConfig) {
+				c.Topics = nil
+				c.TopicPattern = "^logs-.*"
+			},
+			false,
+		},
+		{
+			"topics-and-topic-pattern",
+			func(c *KafkaInputConfig) {
+				c.TopicPattern = "^logs-.*"
+			},
+			true,
+		},
+		{
+			"invalid-topic-pattern",
+			func(c *KafkaInputConfig) {
+				c.Topics = nil
+				c.TopicPattern = "^logs-("
+			},
+			true,
+		},
+		{
+			"missing-group-id",
+			func(c *KafkaInputConfig) {
+				c.GroupID = ""
+			},
+			true,
+		},
+		{
+			"invalid-protocol-version",
+			func(c *KafkaInputConfig) {
+				c.ProtocolVersion = "latest"
+			},
+			true,
+		},
+		{
+			"invalid-start-at",
+			func(c *KafkaInputConfig) {
+				c.StartAt = "middle"
+			},
+			true,
+		},
+		{
+			"sasl-scram",
+			func(c *KafkaInputConfig) {
+				c.SASL = &SASLConfig{Mechanism: "SCRAM-SHA-512", Username: "user", Password: "pass"}
+			},
+			false,
+		},
+		{
+			"sasl-missing-username",
+			func(c *KafkaInputConfig) {
+				c.SASL = &SASLConfig{Password: "pass"}
+			},
+			true,
+		},
+		{
+			"sasl-invalid-mechanism",
+			func(c *KafkaInputConfig) {
+				c.SASL = &SASLConfig{Mechanism: "GSSAPI", Username: "user"}
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewKafkaInputConfig("test_id")
+			cfg.Brokers = []string{"localhost:9092"}
+			cfg.Topics = []string{"logs"}
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestMatchTopics(t *testing.T) {
+	topics := []string{"logs-web", "metrics", "logs-db", "__consumer_offsets"}
+	require.Equal(t, []string{"logs-db", "logs-web"}, matchTopics(topics, regexp.MustCompile("^logs-")))
+}
+
+func newTestKafkaInput(t *testing.T, cfgMod func(*KafkaInputConfig)) (*KafkaInput, *testutil.FakeOutput) {
+	cfg := NewKafkaInputConfig("test_id")
+	cfg.Brokers = []string{"localhost:9092"}
+	cfg.Topics = []string{"logs"}
+	cfg.OutputIDs = []string{"fake"}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	kafkaInput := ops[0].(*KafkaInput)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, kafkaInput.SetOutputs([]operator.Operator{fakeOutput}))
+	kafkaInput.persister = testutil.NewMockPersister("test")
+
+	return kafkaInput, fakeOutput
+}
+
+type fakeSession struct {
+	ctx     context.Context
+	claims  map[string][]int32
+	offsets map[string]map[int32]int64
+}
+
+func newFakeSession(ctx context.Context, claims map[string][]int32) *fakeSession {
+	return &fakeSession{
+		ctx:     ctx,
+		claims:  claims,
+		offsets: make(map[string]map[int32]int64),
+	}
+}
+
+func (s *fakeSession) Claims() map[string][]int32 { return s.claims }
+func (s *fakeSession) MemberID() string           { return "member" }
+func (s *fakeSession) GenerationID() int32        { return 1 }
+func (s *fakeSession) Commit()                    {}
+func (s *fakeSession) Context() context.Context   { return s.ctx }
+
+func (s *fakeSession) MarkOffset(topic string, partition int32, offset int64, _ string) {
+	if s.offsets[topic] == nil {
+		s.offsets[topic] = make(map[int32]int64)
+	}
+	s.offsets[topic][partition] = offset
+}
+
+func (s *fakeSession) ResetOffset(topic string, partition int32, offset int64, metadata string) {
+	s.MarkOffset(topic, partition, offset, metadata)
+}
+
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.MarkOffset(msg.Topic, msg.Partition, msg.Offset+1, metadata)
+}
+
+type fakeClaim struct {
+	topic     string
+	partition int32
+	messages  chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string                            { return c.topic }
+func (c *fakeClaim) Partition() int32                         { return c.partition }
+func (c *fakeClaim) InitialOffset() int64                     { return 0 }
+func (c *fakeClaim) HighWaterMarkOffset() int64               { return 0 }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func TestConsumeClaim(t *testing.T) {
+	kafkaInput, output := newTestKafkaInput(t, func(cfg *KafkaInputConfig) {
+		cfg.HeaderAttributes = map[string]string{"source": "log.source"}
+	})
+
+	session := newFakeSession(context.Background(), map[string][]int32{"logs": {2}})
+	claim := &fakeClaim{topic: "logs", partition: 2, messages: make(chan *sarama.ConsumerMessage, 2)}
+	claim.messages <- &sarama.ConsumerMessage{
+		Topic:     "logs",
+		Partition: 2,
+		Offset:    41,
+		Value:     []byte("message1"),
+		Headers:   []*sarama.RecordHeader{{Key: []byte("source"), Value: []byte("web")}, {Key: []byte("other"), Value: []byte("x")}},
+	}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "logs", Partition: 2, Offset: 42, Value: []byte("message2")}
+	close(claim.messages)
+
+	handler := &groupHandler{input: kafkaInput}
+	require.NoError(t, handler.ConsumeClaim(session, claim))
+
+	expectEntry(t, output, "message1", map[string]string{
+		"kafka.topic":     "logs",
+		"kafka.partition": "2",
+		"log.source":      "web",
+	})
+	expectEntry(t, output, "message2", map[string]string{
+		"kafka.topic":     "logs",
+		"kafka.partition": "2",
+	})
+	require.Equal(t, int64(43), session.offsets["logs"][2])
+
+	offset, ok := kafkaInput.offsets.get("logs", 2)
+	require.True(t, ok)
+	require.Equal(t, int64(43), offset)
+}
+
+// PersistedOffsets tests that offsets which were persisted by a previous
+// run are used when partitions are claimed
+func TestPersistedOffsets(t *testing.T) {
+	kafkaInput, _ := newTestKafkaInput(t, nil)
+	kafkaInput.offsets.set("logs", 0, 100)
+
+	handler := &groupHandler{input: kafkaInput}
+	require.NoError(t, handler.Cleanup(newFakeSession(context.Background(), nil)))
+
+	// A new operator loads the offsets from the same persister
+	restarted, _ := newTestKafkaInput(t, nil)
+	require.NoError(t, restarted.offsets.load(context.Background(), kafkaInput.persister))
+
+	session := newFakeSession(context.Background(), map[string][]int32{"logs": {0, 1}})
+	handler = &groupHandler{input: restarted}
+	require.NoError(t, handler.Setup(session))
+	require.Equal(t, map[string]map[int32]int64{"logs": {0: 100}}, session.offsets)
+}
+
+func TestConsumeClaimSessionDone(t *testing.T) {
+	kafkaInput, _ := newTestKafkaInput(t, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	session := newFakeSession(ctx, nil)
+	claim := &fakeClaim{topic: "logs", messages: make(chan *sarama.ConsumerMessage)}
+
+	handler := &groupHandler{input: kafkaInput}
+	require.NoError(t, handler.ConsumeClaim(session, claim))
+}
+
+func expectEntry(t *testing.T, output *testutil.FakeOutput, body string, attributes map[string]string) {
+	select {
+	case e := <-output.Received:
+		require.Equal(t, body, e.Body)
+		require.Equal(t, attributes, e.Attributes)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
+
+var _ sarama.ConsumerGroupSession = (*fakeSession)(nil)
+var _ sarama.ConsumerGroupClaim = (*fakeClaim)(nil)
@@ -0,0 +1,264 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lumberjack
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jpillora/backoff"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// timestampField is the field of a beat event which holds its timestamp
+	timestampField = "@timestamp"
+
+	// keepaliveInterval is how often a client is sent an empty acknowledgement
+	// while the events of a batch are written, so that it does not time out
+	keepaliveInterval = 3 * time.Second
+)
+
+func init() {
+	operator.Register("lumberjack_input", func() operator.Builder { return NewLumberjackInputConfig("") })
+}
+
+// NewLumberjackInputConfig creates a new lumberjack input config with default values
+func NewLumberjackInputConfig(operatorID string) *LumberjackInputConfig {
+	return &LumberjackInputConfig{
+		InputConfig: helper.NewInputConfig(operatorID, "lumberjack_input"),
+	}
+}
+
+// LumberjackInputConfig is the configuration of a lumberjack input operator.
+type LumberjackInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	ListenAddress string                  `mapstructure:"listen_address,omitempty" json:"listen_address,omitempty" yaml:"listen_address,omitempty"`
+	TLS           *helper.TLSServerConfig `mapstructure:"tls,omitempty"            json:"tls,omitempty"            yaml:"tls,omitempty"`
+}
+
+// Build will build a lumberjack input operator.
+func (c LumberjackInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ListenAddress == "" {
+		return nil, fmt.Errorf("missing required parameter 'listen_address'")
+	}
+
+	// validate the input address
+	if _, err := net.ResolveTCPAddr("tcp", c.ListenAddress); err != nil {
+		return nil, fmt.Errorf("failed to resolve listen_address: %s", err)
+	}
+
+	lumberjackInput := &LumberjackInput{
+		InputOperator: inputOperator,
+		address:       c.ListenAddress,
+		json:          jsoniter.ConfigFastest,
+		keepalive:     keepaliveInterval,
+		backoff: backoff.Backoff{
+			Max: 3 * time.Second,
+		},
+	}
+
+	if c.TLS != nil {
+		lumberjackInput.tls, err = c.TLS.LoadTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []operator.Operator{lumberjackInput}, nil
+}
+
+// LumberjackInput is an operator that receives events from Beats with the lumberjack protocol.
+type LumberjackInput struct {
+	helper.InputOperator
+	address   string
+	tls       *tls.Config
+	json      jsoniter.API
+	keepalive time.Duration
+
+	listener net.Listener
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	backoff  backoff.Backoff
+}
+
+// Start will start listening for lumberjack connections.
+func (l *LumberjackInput) Start(_ operator.Persister) error {
+	var listener net.Listener
+	var err error
+	if l.tls == nil {
+		listener, err = net.Listen("tcp", l.address)
+	} else {
+		listener, err = tls.Listen("tcp", l.address, l.tls)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to listen on interface: %w", err)
+	}
+	l.listener = listener
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.goListen(ctx)
+	return nil
+}
+
+// goListen will listen for lumberjack connections.
+func (l *LumberjackInput) goListen(ctx context.Context) {
+	l.wg.Add(1)
+
+	go func() {
+		defer l.wg.Done()
+
+		for {
+			conn, err := l.listener.Accept()
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+					l.Debugw("Listener accept error", zap.Error(err))
+					time.Sleep(l.backoff.Duration())
+					continue
+				}
+			}
+			l.backoff.Reset()
+
+			l.Debugf("Received connection: %s", conn.RemoteAddr().String())
+			subctx, cancel := context.WithCancel(ctx)
+			l.goHandleClose(subctx, conn)
+			l.goHandleBatches(subctx, conn, cancel)
+		}
+	}()
+}
+
+// goHandleClose will wait for the context to finish before closing a connection.
+func (l *LumberjackInput) goHandleClose(ctx context.Context, conn net.Conn) {
+	l.wg.Add(1)
+
+	go func() {
+		defer l.wg.Done()
+		<-ctx.Done()
+		l.Debugf("Closing connection: %s", conn.RemoteAddr().String())
+		if err := conn.Close(); err != nil {
+			l.Errorf("Failed to close connection: %s", err)
+		}
+	}()
+}
+
+// goHandleBatches will handle batches of events from a lumberjack connection.
+// A batch which can not be decoded closes the connection, and is not
+// acknowledged, so that the client sends it again.
+func (l *LumberjackInput) goHandleBatches(ctx context.Context, conn net.Conn, cancel context.CancelFunc) {
+	l.wg.Add(1)
+
+	go func() {
+		defer l.wg.Done()
+		defer cancel()
+
+		r := &reader{r: bufio.NewReader(conn), json: l.json}
+		for {
+			events, err := r.readBatch()
+			if err != nil {
+				if err != io.EOF && ctx.Err() == nil {
+					l.Errorw("Failed to decode batch", zap.Error(err))
+				}
+				return
+			}
+			if len(events) == 0 {
+				continue
+			}
+
+			if err := l.writeBatch(ctx, conn, events); err != nil {
+				l.Errorw("Failed to acknowledge batch", zap.Error(err))
+				return
+			}
+		}
+	}()
+}
+
+// writeBatch writes the events of a batch, and then acknowledges the batch.
+// The client is sent keepalives while the events are written.
+func (l *LumberjackInput) writeBatch(ctx context.Context, conn net.Conn, events []*event) error {
+	var keepaliveErr error
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(l.keepalive)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, keepaliveErr = conn.Write(ackFrame(0)); keepaliveErr != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for _, e := range events {
+		entry, err := l.NewEntry(e.fields)
+		if err != nil {
+			l.Errorw("Failed to create entry", zap.Error(err))
+			continue
+		}
+		if timestamp, ok := e.fields[timestampField].(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+				entry.Timestamp = t
+			}
+		}
+		l.Write(ctx, entry)
+	}
+
+	close(done)
+	wg.Wait()
+	if keepaliveErr != nil {
+		return keepaliveErr
+	}
+
+	_, err := conn.Write(ackFrame(events[len(events)-1].sequence))
+	return err
+}
+
+// Stop will stop listening for lumberjack connections.
+func (l *LumberjackInput) Stop() error {
+	l.cancel()
+
+	if err := l.listener.Close(); err != nil {
+		return err
+	}
+
+	l.wg.Wait()
+	return nil
+}
@@ -0,0 +1,212 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lumberjack
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtls"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestLumberjackInput(t *testing.T, cfgMod func(*LumberjackInputConfig), output operator.Operator) (*LumberjackInput, net.Conn) {
+	cfg := NewLumberjackInputConfig("test_id")
+	cfg.ListenAddress = "127.0.0.1:0"
+	cfg.OutputIDs = []string{"fake"}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	input := ops[0].(*LumberjackInput)
+	input.keepalive = 50 * time.Millisecond
+
+	require.NoError(t, input.SetOutputs([]operator.Operator{output}))
+	require.NoError(t, input.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() { require.NoError(t, input.Stop()) })
+
+	conn, err := net.Dial("tcp", input.listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return input, conn
+}
+
+func expectAck(t *testing.T, conn net.Conn) uint32 {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	frame := make([]byte, 6)
+	_, err := io.ReadFull(conn, frame)
+	require.NoError(t, err)
+	require.Equal(t, []byte{protocolVersion, frameAck}, frame[:2])
+	return binary.BigEndian.Uint32(frame[2:])
+}
+
+func expectEntry(t *testing.T, output *testutil.FakeOutput) *entry.Entry {
+	select {
+	case e := <-output.Received:
+		return e
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+	return nil
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*LumberjackInputConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *LumberjackInputConfig) {},
+			false,
+		},
+		{
+			"MissingListenAddress",
+			func(cfg *LumberjackInputConfig) {
+				cfg.ListenAddress = ""
+			},
+			true,
+		},
+		{
+			"InvalidListenAddress",
+			func(cfg *LumberjackInputConfig) {
+				cfg.ListenAddress = "missing-port"
+			},
+			true,
+		},
+		{
+			"MissingTLSCertificate",
+			func(cfg *LumberjackInputConfig) {
+				cfg.TLS = helper.NewTLSServerConfig(&configtls.TLSServerSetting{
+					TLSSetting: configtls.TLSSetting{
+						CertFile: "/tmp/cert/missing",
+						KeyFile:  "/tmp/key/missing",
+					},
+				})
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewLumberjackInputConfig("test_id")
+			cfg.ListenAddress = "127.0.0.1:5044"
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLumberjackInput(t *testing.T) {
+	output := testutil.NewFakeOutput(t)
+	_, conn := newTestLumberjackInput(t, nil, output)
+
+	_, err := conn.Write(windowFrame(2))
+	require.NoError(t, err)
+	_, err = conn.Write(compressedFrame(t,
+		jsonFrame(1, `{"@timestamp":"2021-06-01T12:00:00.123Z","message":"first","host":{"name":"web-1"},"@metadata":{"beat":"filebeat"}}`),
+		jsonFrame(2, `{"message":"second"}`),
+	))
+	require.NoError(t, err)
+
+	require.Equal(t, uint32(2), expectAck(t, conn))
+
+	e := expectEntry(t, output)
+	require.Equal(t, time.Date(2021, 6, 1, 12, 0, 0, 123000000, time.UTC), e.Timestamp)
+	require.Equal(t, map[string]interface{}{
+		"@timestamp": "2021-06-01T12:00:00.123Z",
+		"message":    "first",
+		"host":       map[string]interface{}{"name": "web-1"},
+		"@metadata":  map[string]interface{}{"beat": "filebeat"},
+	}, e.Body)
+
+	e = expectEntry(t, output)
+	require.Equal(t, map[string]interface{}{"message": "second"}, e.Body)
+
+	// Further batches are received on the same connection
+	_, err = conn.Write(windowFrame(1))
+	require.NoError(t, err)
+	_, err = conn.Write(dataFrame(3, "line", "third"))
+	require.NoError(t, err)
+
+	require.Equal(t, uint32(3), expectAck(t, conn))
+	require.Equal(t, map[string]interface{}{"line": "third"}, expectEntry(t, output).Body)
+}
+
+func TestLumberjackInputKeepalive(t *testing.T) {
+	blocked := testutil.NewMockOperator("$.output")
+	unblock := make(chan struct{})
+	blocked.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		<-unblock
+	}).Return(nil)
+
+	_, conn := newTestLumberjackInput(t, func(cfg *LumberjackInputConfig) {
+		cfg.OutputIDs = []string{"output"}
+	}, blocked)
+
+	_, err := conn.Write(append(windowFrame(1), jsonFrame(7, `{"message":"blocked"}`)...))
+	require.NoError(t, err)
+
+	// Empty acknowledgements are sent while the batch is written
+	require.Equal(t, uint32(0), expectAck(t, conn))
+	require.Equal(t, uint32(0), expectAck(t, conn))
+
+	close(unblock)
+	for {
+		if sequence := expectAck(t, conn); sequence != 0 {
+			require.Equal(t, uint32(7), sequence)
+			break
+		}
+	}
+}
+
+func TestLumberjackInputInvalidBatch(t *testing.T) {
+	output := testutil.NewFakeOutput(t)
+	_, conn := newTestLumberjackInput(t, nil, output)
+
+	_, err := conn.Write(append(windowFrame(1), jsonFrame(1, `{"message"`)...))
+	require.NoError(t, err)
+
+	// The connection is closed without the batch being acknowledged
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	_, err = conn.Read(make([]byte, 6))
+	require.Equal(t, io.EOF, err)
+
+	select {
+	case e := <-output.Received:
+		require.FailNow(t, "Unexpected entry", "%v", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
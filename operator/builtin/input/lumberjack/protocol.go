@@ -0,0 +1,245 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lumberjack
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// The frames of version 2 of the lumberjack protocol. Each frame starts
+// with the version of the protocol and the type of the frame.
+const (
+	protocolVersion = '2'
+
+	frameWindow     = 'W'
+	frameJSON       = 'J'
+	frameData       = 'D'
+	frameCompressed = 'C'
+	frameAck        = 'A'
+
+	// maxPayloadSize is the largest payload of a frame which is read,
+	// including the payload of a compressed frame once decompressed
+	maxPayloadSize = 64 * 1024 * 1024
+)
+
+// event is an event of a batch, with the sequence number with which it is acknowledged
+type event struct {
+	sequence uint32
+	fields   map[string]interface{}
+}
+
+// reader reads batches of events from a lumberjack connection
+type reader struct {
+	r    io.Reader
+	json jsoniter.API
+}
+
+// readBatch reads a window frame, and the events of the window which follow it.
+// The events may be sent in several frames, which may be compressed.
+func (r *reader) readBatch() ([]*event, error) {
+	typ, err := readHeader(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if typ != frameWindow {
+		return nil, fmt.Errorf("expected window frame, got frame type '%c'", typ)
+	}
+
+	size, err := readUint32(r.r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*event, 0, minInt(int(size), 1024))
+	for uint32(len(events)) < size {
+		typ, err := readHeader(r.r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		events, err = r.readFrame(r.r, typ, events)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// readFrame reads the payload of a frame, and appends its events to events
+func (r *reader) readFrame(src io.Reader, typ byte, events []*event) ([]*event, error) {
+	switch typ {
+	case frameJSON:
+		e, err := r.readJSONFrame(src)
+		if err != nil {
+			return nil, err
+		}
+		return append(events, e), nil
+	case frameData:
+		e, err := readDataFrame(src)
+		if err != nil {
+			return nil, err
+		}
+		return append(events, e), nil
+	case frameCompressed:
+		return r.readCompressedFrame(src, events)
+	default:
+		return nil, fmt.Errorf("unexpected frame type '%c'", typ)
+	}
+}
+
+// readJSONFrame reads an event encoded as a JSON object
+func (r *reader) readJSONFrame(src io.Reader) (*event, error) {
+	sequence, err := readUint32(src)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	payload, err := readPayload(src)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if err := r.json.Unmarshal(payload, &fields); err != nil {
+		return nil, fmt.Errorf("decode json event %d: %s", sequence, err)
+	}
+	return &event{sequence: sequence, fields: fields}, nil
+}
+
+// readDataFrame reads an event encoded as string key-value pairs,
+// as sent by clients which predate JSON frames
+func readDataFrame(src io.Reader) (*event, error) {
+	sequence, err := readUint32(src)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	pairs, err := readUint32(src)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	fields := make(map[string]interface{}, minInt(int(pairs), 64))
+	for i := uint32(0); i < pairs; i++ {
+		key, err := readPayload(src)
+		if err != nil {
+			return nil, err
+		}
+		value, err := readPayload(src)
+		if err != nil {
+			return nil, err
+		}
+		fields[string(key)] = string(value)
+	}
+	return &event{sequence: sequence, fields: fields}, nil
+}
+
+// readCompressedFrame reads a zlib compressed payload of frames
+func (r *reader) readCompressedFrame(src io.Reader, events []*event) ([]*event, error) {
+	payload, err := readPayload(src)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("decompress frame: %s", err)
+	}
+	defer zr.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(zr, maxPayloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("decompress frame: %s", err)
+	}
+	if len(data) > maxPayloadSize {
+		return nil, fmt.Errorf("decompressed frame larger than %d bytes", maxPayloadSize)
+	}
+
+	frames := bytes.NewReader(data)
+	for frames.Len() > 0 {
+		typ, err := readHeader(frames)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		events, err = r.readFrame(frames, typ, events)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// readHeader reads the version and type of a frame
+func readHeader(src io.Reader) (byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(src, header); err != nil {
+		return 0, err
+	}
+	if header[0] != protocolVersion {
+		return 0, fmt.Errorf("unsupported protocol version '%c'", header[0])
+	}
+	return header[1], nil
+}
+
+// readPayload reads a payload which is prefixed with its length
+func readPayload(src io.Reader) ([]byte, error) {
+	length, err := readUint32(src)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if length > maxPayloadSize {
+		return nil, fmt.Errorf("frame payload larger than %d bytes", maxPayloadSize)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(src, payload); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return payload, nil
+}
+
+func readUint32(src io.Reader) (uint32, error) {
+	b := make([]byte, 4)
+	if _, err := io.ReadFull(src, b); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+// unexpectedEOF reports the end of the connection within a batch as an error
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// ackFrame returns a frame which acknowledges the events of a batch up to the sequence
+func ackFrame(sequence uint32) []byte {
+	frame := []byte{protocolVersion, frameAck, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(frame[2:], sequence)
+	return frame
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
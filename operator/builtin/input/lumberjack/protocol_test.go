@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lumberjack
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func windowFrame(size uint32) []byte {
+	frame := []byte{protocolVersion, frameWindow, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(frame[2:], size)
+	return frame
+}
+
+func jsonFrame(sequence uint32, payload string) []byte {
+	frame := []byte{protocolVersion, frameJSON}
+	frame = appendUint32(frame, sequence)
+	frame = appendUint32(frame, uint32(len(payload)))
+	return append(frame, payload...)
+}
+
+func dataFrame(sequence uint32, pairs ...string) []byte {
+	frame := []byte{protocolVersion, frameData}
+	frame = appendUint32(frame, sequence)
+	frame = appendUint32(frame, uint32(len(pairs)/2))
+	for _, s := range pairs {
+		frame = appendUint32(frame, uint32(len(s)))
+		frame = append(frame, s...)
+	}
+	return frame
+}
+
+func compressedFrame(t *testing.T, frames ...[]byte) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	for _, frame := range frames {
+		_, err := zw.Write(frame)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	frame := []byte{protocolVersion, frameCompressed}
+	frame = appendUint32(frame, uint32(buf.Len()))
+	return append(frame, buf.Bytes()...)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	n := make([]byte, 4)
+	binary.BigEndian.PutUint32(n, v)
+	return append(b, n...)
+}
+
+func newTestReader(frames ...[]byte) *reader {
+	return &reader{r: bytes.NewReader(bytes.Join(frames, nil)), json: jsoniter.ConfigFastest}
+}
+
+func TestReadBatch(t *testing.T) {
+	r := newTestReader(
+		windowFrame(3),
+		jsonFrame(1, `{"message":"first","count":1}`),
+		compressedFrame(t,
+			jsonFrame(2, `{"message":"second"}`),
+			dataFrame(3, "line", "third", "offset", "42"),
+		),
+	)
+
+	events, err := r.readBatch()
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	require.Equal(t, uint32(1), events[0].sequence)
+	require.Equal(t, map[string]interface{}{"message": "first", "count": float64(1)}, events[0].fields)
+	require.Equal(t, uint32(2), events[1].sequence)
+	require.Equal(t, map[string]interface{}{"message": "second"}, events[1].fields)
+	require.Equal(t, uint32(3), events[2].sequence)
+	require.Equal(t, map[string]interface{}{"line": "third", "offset": "42"}, events[2].fields)
+
+	_, err = r.readBatch()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestReadBatchErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		frames [][]byte
+	}{
+		{
+			"UnsupportedVersion",
+			[][]byte{{'1', frameWindow, 0, 0, 0, 1}},
+		},
+		{
+			"MissingWindow",
+			[][]byte{jsonFrame(1, `{}`)},
+		},
+		{
+			"UnexpectedFrameType",
+			[][]byte{windowFrame(1), {protocolVersion, 'X'}},
+		},
+		{
+			"InvalidJSON",
+			[][]byte{windowFrame(1), jsonFrame(1, `{"message"`)},
+		},
+		{
+			"IncompleteBatch",
+			[][]byte{windowFrame(2), jsonFrame(1, `{}`)},
+		},
+		{
+			"TruncatedFrame",
+			[][]byte{windowFrame(1), jsonFrame(1, `{"message":"truncated"}`)[:10]},
+		},
+		{
+			"InvalidCompressedFrame",
+			[][]byte{windowFrame(1), {protocolVersion, frameCompressed, 0, 0, 0, 3, 1, 2, 3}},
+		},
+		{
+			"PayloadTooLarge",
+			[][]byte{windowFrame(1), {protocolVersion, frameJSON, 0, 0, 0, 1, 0xff, 0xff, 0xff, 0xff}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newTestReader(tc.frames...).readBatch()
+			require.Error(t, err)
+			require.NotEqual(t, io.EOF, err)
+		})
+	}
+}
+
+func TestAckFrame(t *testing.T) {
+	require.Equal(t, []byte{'2', 'A', 0, 0, 1, 2}, ackFrame(258))
+}
@@ -0,0 +1,56 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mqtt
+
+import (
+	"crypto/tls"
+	"net/url"
+	"time"
+)
+
+// message is a message received from the broker
+type message struct {
+	topic   string
+	payload []byte
+
+	// ack acknowledges a QoS 1 message to the broker, and does nothing for a QoS 0 message
+	ack func()
+}
+
+// client is an MQTT client, which subscribes to the topics whenever it connects
+type client interface {
+	// start connects to the broker in the background, reconnecting whenever the connection is lost.
+	// The handler is called with each message received, in order.
+	start(handler func(message)) error
+
+	// stop disconnects from the broker, and waits for the handler to return
+	stop()
+}
+
+// clientOptions are the options of a client, for all protocol versions
+type clientOptions struct {
+	broker       *url.URL
+	clientID     string
+	topics       []string
+	qos          byte
+	cleanSession bool
+	keepAlive    time.Duration
+	username     string
+	password     string
+	tlsConfig    *tls.Config
+}
+
+// maxReconnectInterval is the longest time between attempts to connect to the broker
+const maxReconnectInterval = 30 * time.Second
@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mqtt
+
+import (
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"go.uber.org/zap"
+)
+
+// v3Client is a client of MQTT 3.1.1 brokers
+type v3Client struct {
+	options clientOptions
+	logger  *zap.SugaredLogger
+	client  paho.Client
+}
+
+func newV3Client(options clientOptions, logger *zap.SugaredLogger) *v3Client {
+	return &v3Client{
+		options: options,
+		logger:  logger,
+	}
+}
+
+// start connects to the broker in the background. The paho client reconnects
+// whenever the connection is lost, and subscribes to the topics each time.
+func (c *v3Client) start(handler func(message)) error {
+	callback := func(_ paho.Client, msg paho.Message) {
+		handler(message{
+			topic:   msg.Topic(),
+			payload: msg.Payload(),
+			ack:     msg.Ack,
+		})
+	}
+
+	filters := make(map[string]byte, len(c.options.topics))
+	for _, topic := range c.options.topics {
+		filters[topic] = c.options.qos
+	}
+
+	options := paho.NewClientOptions().
+		AddBroker(c.options.broker.String()).
+		SetClientID(c.options.clientID).
+		SetProtocolVersion(4).
+		SetCleanSession(c.options.cleanSession).
+		SetKeepAlive(c.options.keepAlive).
+		SetUsername(c.options.username).
+		SetPassword(c.options.password).
+		SetTLSConfig(c.options.tlsConfig).
+		SetOrderMatters(true).
+		SetAutoAckDisabled(true).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(time.Second).
+		SetMaxReconnectInterval(maxReconnectInterval).
+		SetOnConnectHandler(func(client paho.Client) {
+			c.logger.Debugw("Connected to broker", "broker", c.options.broker.Host)
+			token := client.SubscribeMultiple(filters, callback)
+			if token.Wait(); token.Error() != nil {
+				c.logger.Errorw("Failed to subscribe", zap.Error(token.Error()))
+			}
+		}).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			c.logger.Warnw("Connection to broker lost", zap.Error(err))
+		})
+
+	c.client = paho.NewClient(options)
+	c.client.Connect()
+	return nil
+}
+
+// stop disconnects from the broker
+func (c *v3Client) stop() {
+	if c.client != nil {
+		c.client.Disconnect(250)
+	}
+}
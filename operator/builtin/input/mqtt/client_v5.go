@@ -0,0 +1,173 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/jpillora/backoff"
+	"go.uber.org/zap"
+)
+
+// v5Client is a client of MQTT 5 brokers
+type v5Client struct {
+	options clientOptions
+	logger  *zap.SugaredLogger
+	backoff backoff.Backoff
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func newV5Client(options clientOptions, logger *zap.SugaredLogger) *v5Client {
+	return &v5Client{
+		options: options,
+		logger:  logger,
+		backoff: backoff.Backoff{
+			Max: maxReconnectInterval,
+		},
+	}
+}
+
+// start connects to the broker in the background, and reconnects whenever the connection is lost.
+func (c *v5Client) start(handler func(message)) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		for {
+			err := c.run(ctx, handler)
+			if ctx.Err() != nil {
+				return
+			}
+
+			c.logger.Warnw("Connection to broker lost", zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.backoff.Duration()):
+			}
+		}
+	}()
+	return nil
+}
+
+// stop disconnects from the broker
+func (c *v5Client) stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+}
+
+// run connects to the broker and subscribes to the topics, and returns once the
+// connection is lost, or once the context is done.
+func (c *v5Client) run(ctx context.Context, handler func(message)) error {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	disconnected := make(chan error, 1)
+	var client *paho.Client
+	client = paho.NewClient(paho.ClientConfig{
+		Conn: conn,
+		Router: paho.NewSingleHandlerRouter(func(publish *paho.Publish) {
+			handler(message{
+				topic:   publish.Topic,
+				payload: publish.Payload,
+				ack: func() {
+					_ = client.Ack(publish)
+				},
+			})
+		}),
+		EnableManualAcknowledgment: true,
+		OnClientError: func(err error) {
+			select {
+			case disconnected <- err:
+			default:
+			}
+		},
+		OnServerDisconnect: func(d *paho.Disconnect) {
+			select {
+			case disconnected <- fmt.Errorf("disconnected by broker with reason code %d", d.ReasonCode):
+			default:
+			}
+		},
+	})
+
+	connect := &paho.Connect{
+		ClientID:   c.options.clientID,
+		KeepAlive:  uint16(c.options.keepAlive / time.Second),
+		CleanStart: c.options.cleanSession,
+	}
+	if !c.options.cleanSession {
+		// The session is kept by the broker until the client reconnects
+		expiry := uint32(math.MaxUint32)
+		connect.Properties = &paho.ConnectProperties{SessionExpiryInterval: &expiry}
+	}
+	if c.options.username != "" {
+		connect.Username = c.options.username
+		connect.UsernameFlag = true
+	}
+	if c.options.password != "" {
+		connect.Password = []byte(c.options.password)
+		connect.PasswordFlag = true
+	}
+
+	if _, err := client.Connect(ctx, connect); err != nil {
+		return fmt.Errorf("connect: %s", err)
+	}
+	c.logger.Debugw("Connected to broker", "broker", c.options.broker.Host)
+
+	subscribe := &paho.Subscribe{
+		Subscriptions: make(map[string]paho.SubscribeOptions, len(c.options.topics)),
+	}
+	for _, topic := range c.options.topics {
+		subscribe.Subscriptions[topic] = paho.SubscribeOptions{QoS: c.options.qos}
+	}
+	if _, err := client.Subscribe(ctx, subscribe); err != nil {
+		_ = client.Disconnect(&paho.Disconnect{})
+		return fmt.Errorf("subscribe: %s", err)
+	}
+	c.backoff.Reset()
+
+	select {
+	case <-ctx.Done():
+		return client.Disconnect(&paho.Disconnect{})
+	case err := <-disconnected:
+		return err
+	}
+}
+
+// dial opens a connection to the broker
+func (c *v5Client) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	if c.options.tlsConfig == nil {
+		return dialer.DialContext(ctx, "tcp", c.options.broker.Host)
+	}
+
+	tlsDialer := &tls.Dialer{NetDialer: dialer, Config: c.options.tlsConfig}
+	return tlsDialer.DialContext(ctx, "tcp", c.options.broker.Host)
+}
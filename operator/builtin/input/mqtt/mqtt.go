@@ -0,0 +1,264 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	protocolVersion311 = "3.1.1"
+	protocolVersion5   = "5"
+)
+
+func init() {
+	operator.Register("mqtt_input", func() operator.Builder { return NewMQTTInputConfig("") })
+}
+
+// NewMQTTInputConfig creates a new MQTT input config with default values
+func NewMQTTInputConfig(operatorID string) *MQTTInputConfig {
+	return &MQTTInputConfig{
+		InputConfig:     helper.NewInputConfig(operatorID, "mqtt_input"),
+		Broker:          "tcp://localhost:1883",
+		ProtocolVersion: protocolVersion311,
+		QoS:             1,
+		KeepAlive:       helper.NewDuration(30 * time.Second),
+		Encoding:        helper.NewEncodingConfig(),
+	}
+}
+
+// MQTTInputConfig is the configuration of an MQTT input operator.
+type MQTTInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	Broker          string                  `mapstructure:"broker,omitempty"           json:"broker,omitempty"           yaml:"broker,omitempty"`
+	ProtocolVersion string                  `mapstructure:"protocol_version,omitempty" json:"protocol_version,omitempty" yaml:"protocol_version,omitempty"`
+	ClientID        string                  `mapstructure:"client_id,omitempty"        json:"client_id,omitempty"        yaml:"client_id,omitempty"`
+	Topics          []string                `mapstructure:"topics,omitempty"           json:"topics,omitempty"           yaml:"topics,omitempty"`
+	QoS             int                     `mapstructure:"qos,omitempty"              json:"qos,omitempty"              yaml:"qos,omitempty"`
+	CleanSession    bool                    `mapstructure:"clean_session,omitempty"    json:"clean_session,omitempty"    yaml:"clean_session,omitempty"`
+	KeepAlive       helper.Duration         `mapstructure:"keep_alive,omitempty"       json:"keep_alive,omitempty"       yaml:"keep_alive,omitempty"`
+	Username        string                  `mapstructure:"username,omitempty"         json:"username,omitempty"         yaml:"username,omitempty"`
+	Password        string                  `mapstructure:"password,omitempty"         json:"password,omitempty"         yaml:"password,omitempty"`
+	TLS             *helper.TLSClientConfig `mapstructure:"tls,omitempty"              json:"tls,omitempty"              yaml:"tls,omitempty"`
+	TopicAttributes []string                `mapstructure:"topic_attributes,omitempty" json:"topic_attributes,omitempty" yaml:"topic_attributes,omitempty"`
+	Encoding        helper.EncodingConfig   `mapstructure:",squash,omitempty"          json:",inline,omitempty"          yaml:",inline,omitempty"`
+}
+
+// Build will build an MQTT input operator.
+func (c MQTTInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Broker == "" {
+		return nil, fmt.Errorf("missing required parameter 'broker'")
+	}
+
+	broker, err := url.Parse(c.Broker)
+	if err != nil || broker.Host == "" {
+		return nil, fmt.Errorf("invalid broker '%s'", c.Broker)
+	}
+
+	var secure bool
+	switch broker.Scheme {
+	case "tcp", "mqtt":
+		if broker.Port() == "" {
+			broker.Host += ":1883"
+		}
+	case "ssl", "tls", "mqtts":
+		secure = true
+		if broker.Port() == "" {
+			broker.Host += ":8883"
+		}
+	default:
+		return nil, fmt.Errorf("invalid broker scheme '%s'", broker.Scheme)
+	}
+
+	if len(c.Topics) == 0 {
+		return nil, fmt.Errorf("missing required parameter 'topics'")
+	}
+
+	for _, topic := range c.Topics {
+		if err := validateTopicFilter(topic); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.QoS != 0 && c.QoS != 1 {
+		return nil, fmt.Errorf("invalid qos '%d'", c.QoS)
+	}
+
+	if c.KeepAlive.Raw() < time.Second {
+		return nil, fmt.Errorf("`keep_alive` must be at least 1s")
+	}
+
+	// A persistent session must be resumed with the same client ID, so the client ID defaults to the hostname
+	clientID := c.ClientID
+	if clientID == "" {
+		clientID, err = os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname for default client_id: %s", err)
+		}
+	}
+
+	var tlsConfig *tls.Config
+	if secure {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if c.TLS != nil {
+			loaded, err := c.TLS.LoadTLSConfig()
+			if err != nil {
+				return nil, err
+			}
+			if loaded != nil {
+				tlsConfig = loaded
+			}
+		}
+	}
+
+	options := clientOptions{
+		broker:       broker,
+		clientID:     clientID,
+		topics:       c.Topics,
+		qos:          byte(c.QoS),
+		cleanSession: c.CleanSession,
+		keepAlive:    c.KeepAlive.Raw(),
+		username:     c.Username,
+		password:     c.Password,
+		tlsConfig:    tlsConfig,
+	}
+
+	var client client
+	switch c.ProtocolVersion {
+	case protocolVersion311:
+		client = newV3Client(options, inputOperator.SugaredLogger)
+	case protocolVersion5:
+		client = newV5Client(options, inputOperator.SugaredLogger)
+	default:
+		return nil, fmt.Errorf("invalid protocol_version '%s'", c.ProtocolVersion)
+	}
+
+	encoding, err := c.Encoding.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	mqttInput := &MQTTInput{
+		InputOperator:   inputOperator,
+		client:          client,
+		topicAttributes: c.TopicAttributes,
+		encoding:        encoding,
+	}
+
+	return []operator.Operator{mqttInput}, nil
+}
+
+// validateTopicFilter returns an error if a topic filter uses wildcards incorrectly.
+// A multi-level wildcard must be the last level, and wildcards must occupy a whole level.
+func validateTopicFilter(topic string) error {
+	if topic == "" {
+		return fmt.Errorf("invalid topic ''")
+	}
+
+	levels := strings.Split(topic, "/")
+	for i, level := range levels {
+		switch {
+		case level == "#" && i != len(levels)-1:
+			return fmt.Errorf("invalid topic '%s'", topic)
+		case level != "#" && level != "+" && strings.ContainsAny(level, "#+"):
+			return fmt.Errorf("invalid topic '%s'", topic)
+		}
+	}
+	return nil
+}
+
+// MQTTInput is an operator that receives log entries from MQTT topics.
+type MQTTInput struct {
+	helper.InputOperator
+	client          client
+	topicAttributes []string
+	encoding        helper.Encoding
+
+	cancel context.CancelFunc
+}
+
+// Start will connect to the broker and subscribe to the topics. The connection
+// is made in the background, and is retried until the operator is stopped.
+func (m *MQTTInput) Start(_ operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+
+	return m.client.start(func(msg message) {
+		m.handleMessage(ctx, msg)
+	})
+}
+
+// Stop will disconnect from the broker. Messages which were received but not
+// yet written are not acknowledged, and are redelivered when a persistent
+// session is resumed.
+func (m *MQTTInput) Stop() error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.client.stop()
+	return nil
+}
+
+// handleMessage creates an entry from a message, and acknowledges the message
+// once the entry has been written.
+func (m *MQTTInput) handleMessage(ctx context.Context, msg message) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	decoded, err := m.encoding.Decode(msg.payload)
+	if err != nil {
+		m.Errorw("Failed to decode message", zap.Error(err))
+		msg.ack()
+		return
+	}
+
+	entry, err := m.NewEntry(decoded)
+	if err != nil {
+		m.Errorw("Failed to create entry", zap.Error(err))
+		msg.ack()
+		return
+	}
+
+	entry.AddAttribute("mqtt.topic", msg.topic)
+	levels := strings.Split(msg.topic, "/")
+	for i, attribute := range m.topicAttributes {
+		if attribute != "" && i < len(levels) {
+			entry.AddAttribute(attribute, levels[i])
+		}
+	}
+
+	m.Write(ctx, entry)
+	if ctx.Err() == nil {
+		msg.ack()
+	}
+}
@@ -0,0 +1,576 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mqtt
+
+import (
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	v5packets "github.com/eclipse/paho.golang/packets"
+	v3packets "github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+var protocolVersions = []string{protocolVersion311, protocolVersion5}
+
+// connectInfo is the content of a CONNECT packet received by the fake broker
+type connectInfo struct {
+	clientID     string
+	cleanSession bool
+	username     string
+	password     string
+}
+
+// subscription is a topic filter subscribed to, and its QoS
+type subscription struct {
+	topic string
+	qos   byte
+}
+
+// fakeBroker accepts a client of a protocol version, and acknowledges its
+// connections and subscriptions. Messages are published with publish, and
+// the messages acknowledged by the client are sent to acked.
+type fakeBroker struct {
+	t        *testing.T
+	v5       bool
+	listener net.Listener
+
+	connected  chan connectInfo
+	subscribed chan []subscription
+	acked      chan uint16
+
+	mu     sync.Mutex
+	conn   net.Conn
+	nextID uint16
+}
+
+func newFakeBroker(t *testing.T, protocolVersion string) *fakeBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	b := &fakeBroker{
+		t:          t,
+		v5:         protocolVersion == protocolVersion5,
+		listener:   listener,
+		connected:  make(chan connectInfo, 10),
+		subscribed: make(chan []subscription, 10),
+		acked:      make(chan uint16, 10),
+	}
+	t.Cleanup(func() {
+		listener.Close()
+		b.disconnect()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			b.mu.Lock()
+			b.conn = conn
+			b.mu.Unlock()
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) address() string {
+	return "tcp://" + b.listener.Addr().String()
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var err error
+		if b.v5 {
+			err = b.handleV5(conn)
+		} else {
+			err = b.handleV3(conn)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) handleV3(conn net.Conn) error {
+	packet, err := v3packets.ReadPacket(conn)
+	if err != nil {
+		return err
+	}
+
+	switch p := packet.(type) {
+	case *v3packets.ConnectPacket:
+		b.connected <- connectInfo{p.ClientIdentifier, p.CleanSession, p.Username, string(p.Password)}
+		return v3packets.NewControlPacket(v3packets.Connack).Write(conn)
+	case *v3packets.SubscribePacket:
+		var subscriptions []subscription
+		for i, topic := range p.Topics {
+			subscriptions = append(subscriptions, subscription{topic, p.Qoss[i]})
+		}
+		b.subscribed <- sortSubscriptions(subscriptions)
+		suback := v3packets.NewControlPacket(v3packets.Suback).(*v3packets.SubackPacket)
+		suback.MessageID = p.MessageID
+		suback.ReturnCodes = p.Qoss
+		return suback.Write(conn)
+	case *v3packets.PubackPacket:
+		b.acked <- p.MessageID
+	case *v3packets.PingreqPacket:
+		return v3packets.NewControlPacket(v3packets.Pingresp).Write(conn)
+	case *v3packets.DisconnectPacket:
+		return io.EOF
+	}
+	return nil
+}
+
+func (b *fakeBroker) handleV5(conn net.Conn) error {
+	packet, err := v5packets.ReadPacket(conn)
+	if err != nil {
+		return err
+	}
+
+	switch p := packet.Content.(type) {
+	case *v5packets.Connect:
+		b.connected <- connectInfo{p.ClientID, p.CleanStart, p.Username, string(p.Password)}
+		_, err = (&v5packets.Connack{Properties: &v5packets.Properties{}}).WriteTo(conn)
+		return err
+	case *v5packets.Subscribe:
+		var subscriptions []subscription
+		var reasons []byte
+		for topic, options := range p.Subscriptions {
+			subscriptions = append(subscriptions, subscription{topic, options.QoS})
+			reasons = append(reasons, options.QoS)
+		}
+		b.subscribed <- sortSubscriptions(subscriptions)
+		_, err = (&v5packets.Suback{PacketID: p.PacketID, Reasons: reasons, Properties: &v5packets.Properties{}}).WriteTo(conn)
+		return err
+	case *v5packets.Puback:
+		b.acked <- p.PacketID
+	case *v5packets.Pingreq:
+		_, err = (&v5packets.Pingresp{}).WriteTo(conn)
+		return err
+	case *v5packets.Disconnect:
+		return io.EOF
+	}
+	return nil
+}
+
+// publish sends a message to the connected client, and returns its packet ID
+func (b *fakeBroker) publish(topic, payload string, qos byte) uint16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var id uint16
+	if qos > 0 {
+		b.nextID++
+		id = b.nextID
+	}
+
+	var err error
+	if b.v5 {
+		_, err = (&v5packets.Publish{
+			Topic:      topic,
+			Payload:    []byte(payload),
+			QoS:        qos,
+			PacketID:   id,
+			Properties: &v5packets.Properties{},
+		}).WriteTo(b.conn)
+	} else {
+		publish := v3packets.NewControlPacket(v3packets.Publish).(*v3packets.PublishPacket)
+		publish.TopicName = topic
+		publish.Payload = []byte(payload)
+		publish.Qos = qos
+		publish.MessageID = id
+		err = publish.Write(b.conn)
+	}
+	require.NoError(b.t, err)
+	return id
+}
+
+// disconnect closes the connection of the client
+func (b *fakeBroker) disconnect() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn != nil {
+		b.conn.Close()
+	}
+}
+
+func (b *fakeBroker) expectConnect(t *testing.T) connectInfo {
+	select {
+	case info := <-b.connected:
+		return info
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Timed out waiting for connection")
+		return connectInfo{}
+	}
+}
+
+func (b *fakeBroker) expectSubscribe(t *testing.T) []subscription {
+	select {
+	case subscriptions := <-b.subscribed:
+		return subscriptions
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "Timed out waiting for subscription")
+		return nil
+	}
+}
+
+func (b *fakeBroker) expectAck(t *testing.T, id uint16) {
+	select {
+	case acked := <-b.acked:
+		require.Equal(t, id, acked)
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "Timed out waiting for acknowledgement")
+	}
+}
+
+func (b *fakeBroker) expectNoAck(t *testing.T) {
+	select {
+	case acked := <-b.acked:
+		require.FailNow(t, "Received unexpected acknowledgement", "%d", acked)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func sortSubscriptions(subscriptions []subscription) []subscription {
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].topic < subscriptions[j].topic
+	})
+	return subscriptions
+}
+
+func newTestMQTTInput(t *testing.T, broker *fakeBroker, protocolVersion string, output operator.Operator, cfgMod func(*MQTTInputConfig)) *MQTTInput {
+	cfg := NewMQTTInputConfig("test_id")
+	cfg.Broker = broker.address()
+	cfg.ProtocolVersion = protocolVersion
+	cfg.ClientID = "collector"
+	cfg.Topics = []string{"devices/+/logs"}
+	cfg.OutputIDs = []string{output.ID()}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	mqttInput := ops[0].(*MQTTInput)
+
+	require.NoError(t, mqttInput.SetOutputs([]operator.Operator{output}))
+	require.NoError(t, mqttInput.Start(testutil.NewMockPersister("test")))
+	return mqttInput
+}
+
+func expectEntry(t *testing.T, fakeOutput *testutil.FakeOutput) *entry.Entry {
+	select {
+	case e := <-fakeOutput.Received:
+		return e
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+		return nil
+	}
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name           string
+		modify         func(*MQTTInputConfig)
+		expectedBroker string
+		expectTLS      bool
+		expectErr      bool
+	}{
+		{
+			"Default",
+			func(cfg *MQTTInputConfig) {},
+			"localhost:1883",
+			false,
+			false,
+		},
+		{
+			"ProtocolVersion5",
+			func(cfg *MQTTInputConfig) {
+				cfg.ProtocolVersion = protocolVersion5
+			},
+			"localhost:1883",
+			false,
+			false,
+		},
+		{
+			"InvalidProtocolVersion",
+			func(cfg *MQTTInputConfig) {
+				cfg.ProtocolVersion = "3.1"
+			},
+			"",
+			false,
+			true,
+		},
+		{
+			"MissingBroker",
+			func(cfg *MQTTInputConfig) {
+				cfg.Broker = ""
+			},
+			"",
+			false,
+			true,
+		},
+		{
+			"BrokerPort",
+			func(cfg *MQTTInputConfig) {
+				cfg.Broker = "mqtt://mqtt.example.com:11883"
+			},
+			"mqtt.example.com:11883",
+			false,
+			false,
+		},
+		{
+			"TLSBroker",
+			func(cfg *MQTTInputConfig) {
+				cfg.Broker = "ssl://mqtt.example.com"
+			},
+			"mqtt.example.com:8883",
+			true,
+			false,
+		},
+		{
+			"InvalidBrokerScheme",
+			func(cfg *MQTTInputConfig) {
+				cfg.Broker = "http://mqtt.example.com"
+			},
+			"",
+			false,
+			true,
+		},
+		{
+			"InvalidBroker",
+			func(cfg *MQTTInputConfig) {
+				cfg.Broker = "localhost"
+			},
+			"",
+			false,
+			true,
+		},
+		{
+			"MissingTopics",
+			func(cfg *MQTTInputConfig) {
+				cfg.Topics = nil
+			},
+			"",
+			false,
+			true,
+		},
+		{
+			"InvalidTopic",
+			func(cfg *MQTTInputConfig) {
+				cfg.Topics = []string{"devices/#/logs"}
+			},
+			"",
+			false,
+			true,
+		},
+		{
+			"QoS0",
+			func(cfg *MQTTInputConfig) {
+				cfg.QoS = 0
+			},
+			"localhost:1883",
+			false,
+			false,
+		},
+		{
+			"InvalidQoS",
+			func(cfg *MQTTInputConfig) {
+				cfg.QoS = 2
+			},
+			"",
+			false,
+			true,
+		},
+		{
+			"InvalidKeepAlive",
+			func(cfg *MQTTInputConfig) {
+				cfg.KeepAlive.Duration = 0
+			},
+			"",
+			false,
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewMQTTInputConfig("test_id")
+			cfg.Topics = []string{"devices/+/logs"}
+			tc.modify(cfg)
+
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var options clientOptions
+			switch client := ops[0].(*MQTTInput).client.(type) {
+			case *v3Client:
+				options = client.options
+			case *v5Client:
+				options = client.options
+			}
+			require.Equal(t, tc.expectedBroker, options.broker.Host)
+			require.Equal(t, tc.expectTLS, options.tlsConfig != nil)
+			require.NotEmpty(t, options.clientID)
+		})
+	}
+}
+
+func TestValidateTopicFilter(t *testing.T) {
+	valid := []string{"devices/logs", "devices/+/logs", "devices/#", "#", "+", "+/+"}
+	for _, topic := range valid {
+		require.NoError(t, validateTopicFilter(topic), topic)
+	}
+
+	invalid := []string{"", "devices/#/logs", "devices/sensor+/logs", "devices/#logs"}
+	for _, topic := range invalid {
+		require.Error(t, validateTopicFilter(topic), topic)
+	}
+}
+
+func TestMQTTInput(t *testing.T) {
+	for _, protocolVersion := range protocolVersions {
+		t.Run(protocolVersion, func(t *testing.T) {
+			broker := newFakeBroker(t, protocolVersion)
+			fakeOutput := testutil.NewFakeOutput(t)
+			mqttInput := newTestMQTTInput(t, broker, protocolVersion, fakeOutput, func(cfg *MQTTInputConfig) {
+				cfg.Topics = []string{"devices/+/logs/#", "gateways/+/logs"}
+				cfg.Username = "collector"
+				cfg.Password = "secret"
+				cfg.TopicAttributes = []string{"", "device.id", "", "log.level"}
+			})
+			defer func() { require.NoError(t, mqttInput.Stop()) }()
+
+			require.Equal(t, connectInfo{"collector", false, "collector", "secret"}, broker.expectConnect(t))
+			require.Equal(t, []subscription{{"devices/+/logs/#", 1}, {"gateways/+/logs", 1}}, broker.expectSubscribe(t))
+
+			id := broker.publish("devices/sensor-1/logs/error", "overheating", 1)
+			e := expectEntry(t, fakeOutput)
+			require.Equal(t, "overheating", e.Body)
+			require.Equal(t, map[string]string{
+				"mqtt.topic": "devices/sensor-1/logs/error",
+				"device.id":  "sensor-1",
+				"log.level":  "error",
+			}, e.Attributes)
+			broker.expectAck(t, id)
+
+			// Segments which are not in the topic are not added
+			id = broker.publish("gateways/gw-1/logs", "started", 1)
+			e = expectEntry(t, fakeOutput)
+			require.Equal(t, map[string]string{
+				"mqtt.topic": "gateways/gw-1/logs",
+				"device.id":  "gw-1",
+			}, e.Attributes)
+			broker.expectAck(t, id)
+		})
+	}
+}
+
+func TestMQTTInputQoS0(t *testing.T) {
+	for _, protocolVersion := range protocolVersions {
+		t.Run(protocolVersion, func(t *testing.T) {
+			broker := newFakeBroker(t, protocolVersion)
+			fakeOutput := testutil.NewFakeOutput(t)
+			mqttInput := newTestMQTTInput(t, broker, protocolVersion, fakeOutput, func(cfg *MQTTInputConfig) {
+				cfg.QoS = 0
+				cfg.CleanSession = true
+			})
+			defer func() { require.NoError(t, mqttInput.Stop()) }()
+
+			require.True(t, broker.expectConnect(t).cleanSession)
+			require.Equal(t, []subscription{{"devices/+/logs", 0}}, broker.expectSubscribe(t))
+
+			broker.publish("devices/sensor-1/logs", "hello", 0)
+			e := expectEntry(t, fakeOutput)
+			require.Equal(t, "hello", e.Body)
+			broker.expectNoAck(t)
+		})
+	}
+}
+
+// AckAfterWrite tests that a message is only acknowledged once its entry has
+// been accepted by the next operator
+func TestMQTTInputAckAfterWrite(t *testing.T) {
+	for _, protocolVersion := range protocolVersions {
+		t.Run(protocolVersion, func(t *testing.T) {
+			broker := newFakeBroker(t, protocolVersion)
+
+			processing := make(chan struct{})
+			release := make(chan struct{})
+			mockOutput := testutil.NewMockOperator("$.output")
+			mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				close(processing)
+				<-release
+			}).Return(nil)
+
+			mqttInput := newTestMQTTInput(t, broker, protocolVersion, mockOutput, nil)
+			defer func() { require.NoError(t, mqttInput.Stop()) }()
+			broker.expectConnect(t)
+			broker.expectSubscribe(t)
+
+			id := broker.publish("devices/sensor-1/logs", "hello", 1)
+			select {
+			case <-processing:
+			case <-time.After(2 * time.Second):
+				require.FailNow(t, "Timed out waiting for entry")
+			}
+			broker.expectNoAck(t)
+
+			close(release)
+			broker.expectAck(t, id)
+		})
+	}
+}
+
+func TestMQTTInputReconnect(t *testing.T) {
+	for _, protocolVersion := range protocolVersions {
+		t.Run(protocolVersion, func(t *testing.T) {
+			broker := newFakeBroker(t, protocolVersion)
+			fakeOutput := testutil.NewFakeOutput(t)
+			mqttInput := newTestMQTTInput(t, broker, protocolVersion, fakeOutput, nil)
+			defer func() { require.NoError(t, mqttInput.Stop()) }()
+			broker.expectConnect(t)
+			broker.expectSubscribe(t)
+
+			broker.disconnect()
+
+			// The session is resumed, and the topics are subscribed to again
+			require.False(t, broker.expectConnect(t).cleanSession)
+			broker.expectSubscribe(t)
+
+			id := broker.publish("devices/sensor-1/logs", "hello", 1)
+			e := expectEntry(t, fakeOutput)
+			require.Equal(t, "hello", e.Body)
+			broker.expectAck(t, id)
+		})
+	}
+}
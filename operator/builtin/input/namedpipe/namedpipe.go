@@ -0,0 +1,179 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build linux
+
+package namedpipe
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const defaultMaxLogSize = 1024 * 1024
+
+func init() {
+	operator.Register("named_pipe_input", func() operator.Builder { return NewNamedPipeInputConfig("") })
+}
+
+// NewNamedPipeInputConfig creates a new named pipe input config with default values
+func NewNamedPipeInputConfig(operatorID string) *NamedPipeInputConfig {
+	return &NamedPipeInputConfig{
+		InputConfig: helper.NewInputConfig(operatorID, "named_pipe_input"),
+		MaxLogSize:  defaultMaxLogSize,
+		Multiline:   helper.NewMultilineConfig(),
+		Encoding:    helper.NewEncodingConfig(),
+	}
+}
+
+// NamedPipeInputConfig is the configuration of a named pipe input operator
+type NamedPipeInputConfig struct {
+	helper.InputConfig `mapstructure:",squash" yaml:",inline"`
+
+	Path       string                 `mapstructure:"path"                   json:"path"                   yaml:"path"`
+	MaxLogSize helper.ByteSize        `mapstructure:"max_log_size,omitempty" json:"max_log_size,omitempty" yaml:"max_log_size,omitempty"`
+	Multiline  helper.MultilineConfig `mapstructure:"multiline,omitempty"    json:"multiline,omitempty"    yaml:"multiline,omitempty"`
+	Encoding   helper.EncodingConfig  `mapstructure:",squash,omitempty"      json:",inline,omitempty"      yaml:",inline,omitempty"`
+}
+
+// Build will build a named pipe input operator
+func (c NamedPipeInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Path == "" {
+		return nil, fmt.Errorf("missing required parameter 'path'")
+	}
+
+	if c.MaxLogSize <= 0 {
+		return nil, fmt.Errorf("`max_log_size` must be positive")
+	}
+
+	encoding, err := c.Encoding.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	splitFunc, err := c.Multiline.Build(context, encoding.Encoding, true)
+	if err != nil {
+		return nil, err
+	}
+
+	namedPipeInput := &NamedPipeInput{
+		InputOperator: inputOperator,
+		path:          c.Path,
+		maxLogSize:    int(c.MaxLogSize),
+		encoding:      encoding,
+		splitFunc:     splitFunc,
+	}
+	return []operator.Operator{namedPipeInput}, nil
+}
+
+// NamedPipeInput is an operator that reads log entries from a named pipe
+type NamedPipeInput struct {
+	helper.InputOperator
+
+	path       string
+	maxLogSize int
+	encoding   helper.Encoding
+	splitFunc  bufio.SplitFunc
+
+	pipe   *os.File
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+// Start will start reading log entries from the named pipe
+func (n *NamedPipeInput) Start(_ operator.Persister) error {
+	info, err := os.Stat(n.path)
+	if err != nil {
+		return fmt.Errorf("stat named pipe: %s", err)
+	}
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		return fmt.Errorf("'%s' is not a named pipe", n.path)
+	}
+
+	// The pipe is opened for writing as well as reading, so that opening it does not
+	// block until a writer connects, and so that reads do not return EOF when all
+	// writers disconnect. Instead, reads wait until a writer reconnects.
+	pipe, err := os.OpenFile(n.path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("open named pipe: %s", err)
+	}
+	n.pipe = pipe
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n.cancel = cancel
+
+	n.wg.Add(1)
+	go n.readPipe(ctx)
+	return nil
+}
+
+// readPipe reads entries from the named pipe until it is closed
+func (n *NamedPipeInput) readPipe(ctx context.Context) {
+	defer n.wg.Done()
+
+	buf := make([]byte, 0, 4096)
+	scanner := bufio.NewScanner(n.pipe)
+	scanner.Buffer(buf, n.maxLogSize)
+	scanner.Split(n.splitFunc)
+
+	for scanner.Scan() {
+		decoded, err := n.encoding.Decode(scanner.Bytes())
+		if err != nil {
+			n.Errorw("Failed to decode data", zap.Error(err))
+			continue
+		}
+
+		entry, err := n.NewEntry(decoded)
+		if err != nil {
+			n.Errorw("Failed to create entry", zap.Error(err))
+			continue
+		}
+		n.Write(ctx, entry)
+	}
+
+	select {
+	case <-ctx.Done():
+		// The pipe was closed by Stop
+	default:
+		if err := scanner.Err(); err != nil {
+			n.Errorw("Failed to read named pipe", zap.Error(err))
+		}
+	}
+}
+
+// Stop will stop reading from the named pipe
+func (n *NamedPipeInput) Stop() error {
+	if n.cancel != nil {
+		n.cancel()
+	}
+	if n.pipe != nil {
+		// Closing the pipe interrupts a read which is waiting for data
+		n.pipe.Close()
+	}
+	n.wg.Wait()
+	return nil
+}
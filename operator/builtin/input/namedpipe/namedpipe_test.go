@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build linux
+
+package namedpipe
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestNamedPipeInput(t *testing.T, path string) (*NamedPipeInput, chan *entry.Entry) {
+	cfg := NewNamedPipeInputConfig("test_id")
+	cfg.Path = path
+	cfg.OutputIDs = []string{"fake"}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0]
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fakeOutput}))
+	return op.(*NamedPipeInput), fakeOutput.Received
+}
+
+func newTestPipe(t *testing.T) string {
+	path := filepath.Join(testutil.NewTempDir(t), "pipe")
+	require.NoError(t, syscall.Mkfifo(path, 0600))
+	return path
+}
+
+func writeToPipe(t *testing.T, path string, s string) {
+	pipe, err := os.OpenFile(path, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	defer pipe.Close()
+
+	_, err = pipe.Write([]byte(s))
+	require.NoError(t, err)
+}
+
+func expectMessages(t *testing.T, c chan *entry.Entry, expected []string) {
+	for _, msg := range expected {
+		select {
+		case e := <-c:
+			require.Equal(t, msg, e.Body)
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for message", msg)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	cfg := NewNamedPipeInputConfig("test_id")
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+
+	cfg.Path = "/tmp/pipe"
+	cfg.MaxLogSize = 0
+	_, err = cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+}
+
+func TestNotNamedPipe(t *testing.T) {
+	path := filepath.Join(testutil.NewTempDir(t), "file")
+	require.NoError(t, ioutil.WriteFile(path, []byte("testlog\n"), 0600))
+
+	input, _ := newTestNamedPipeInput(t, path)
+	require.Error(t, input.Start(testutil.NewMockPersister("test")))
+}
+
+func TestReadNamedPipe(t *testing.T) {
+	path := newTestPipe(t)
+	input, logReceived := newTestNamedPipeInput(t, path)
+
+	require.NoError(t, input.Start(testutil.NewMockPersister("test")))
+	defer input.Stop()
+
+	writeToPipe(t, path, "testlog1\ntestlog2\n")
+	expectMessages(t, logReceived, []string{"testlog1", "testlog2"})
+}
+
+// WriterReconnect tests that entries are read after the
+// writer disconnects and a new writer connects
+func TestWriterReconnect(t *testing.T) {
+	path := newTestPipe(t)
+	input, logReceived := newTestNamedPipeInput(t, path)
+
+	require.NoError(t, input.Start(testutil.NewMockPersister("test")))
+	defer input.Stop()
+
+	writeToPipe(t, path, "testlog1\n")
+	expectMessages(t, logReceived, []string{"testlog1"})
+
+	writeToPipe(t, path, "testlog2\n")
+	expectMessages(t, logReceived, []string{"testlog2"})
+}
+
+// StopWithoutWriter tests that the operator stops
+// while it is waiting for a writer to connect
+func TestStopWithoutWriter(t *testing.T) {
+	path := newTestPipe(t)
+	input, _ := newTestNamedPipeInput(t, path)
+
+	require.NoError(t, input.Start(testutil.NewMockPersister("test")))
+
+	stopped := make(chan struct{})
+	go func() {
+		require.NoError(t, input.Stop())
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for operator to stop")
+	}
+}
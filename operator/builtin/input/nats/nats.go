@@ -0,0 +1,479 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jpillora/backoff"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	startAtBeginning = "beginning"
+	startAtEnd       = "end"
+
+	// sequencesKey is the key under which acknowledged stream sequences are persisted
+	sequencesKey = "sequences"
+
+	// persistInterval is how often acknowledged stream sequences are persisted
+	persistInterval = time.Second
+)
+
+func init() {
+	operator.Register("nats_input", func() operator.Builder { return NewNATSInputConfig("") })
+}
+
+// NewNATSInputConfig creates a new NATS input config with default values
+func NewNATSInputConfig(operatorID string) *NATSInputConfig {
+	return &NATSInputConfig{
+		InputConfig: helper.NewInputConfig(operatorID, "nats_input"),
+		URL:         nats.DefaultURL,
+		Encoding:    helper.NewEncodingConfig(),
+	}
+}
+
+// NATSInputConfig is the configuration of a NATS input operator.
+type NATSInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	URL              string                  `mapstructure:"url,omitempty"               json:"url,omitempty"               yaml:"url,omitempty"`
+	Subjects         []string                `mapstructure:"subjects,omitempty"          json:"subjects,omitempty"          yaml:"subjects,omitempty"`
+	QueueGroup       string                  `mapstructure:"queue_group,omitempty"       json:"queue_group,omitempty"       yaml:"queue_group,omitempty"`
+	Username         string                  `mapstructure:"username,omitempty"          json:"username,omitempty"          yaml:"username,omitempty"`
+	Password         string                  `mapstructure:"password,omitempty"          json:"password,omitempty"          yaml:"password,omitempty"`
+	Token            string                  `mapstructure:"token,omitempty"             json:"token,omitempty"             yaml:"token,omitempty"`
+	CredentialsFile  string                  `mapstructure:"credentials_file,omitempty"  json:"credentials_file,omitempty"  yaml:"credentials_file,omitempty"`
+	TLS              *helper.TLSClientConfig `mapstructure:"tls,omitempty"               json:"tls,omitempty"               yaml:"tls,omitempty"`
+	JetStream        *JetStreamConfig        `mapstructure:"jetstream,omitempty"         json:"jetstream,omitempty"         yaml:"jetstream,omitempty"`
+	HeaderAttributes map[string]string       `mapstructure:"header_attributes,omitempty" json:"header_attributes,omitempty" yaml:"header_attributes,omitempty"`
+	Encoding         helper.EncodingConfig   `mapstructure:",squash,omitempty"           json:",inline,omitempty"           yaml:",inline,omitempty"`
+}
+
+// JetStreamConfig is the configuration of JetStream consumers
+type JetStreamConfig struct {
+	Stream  string `mapstructure:"stream,omitempty"   json:"stream,omitempty"   yaml:"stream,omitempty"`
+	Durable string `mapstructure:"durable,omitempty"  json:"durable,omitempty"  yaml:"durable,omitempty"`
+	StartAt string `mapstructure:"start_at,omitempty" json:"start_at,omitempty" yaml:"start_at,omitempty"`
+}
+
+// Build will build a NATS input operator.
+func (c NATSInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.URL == "" {
+		return nil, fmt.Errorf("missing required parameter 'url'")
+	}
+
+	if len(c.Subjects) == 0 {
+		return nil, fmt.Errorf("missing required parameter 'subjects'")
+	}
+
+	if c.Token != "" && (c.Username != "" || c.CredentialsFile != "") {
+		return nil, fmt.Errorf("only one of 'token', 'username' and 'credentials_file' can be set")
+	}
+	if c.Username != "" && c.CredentialsFile != "" {
+		return nil, fmt.Errorf("only one of 'token', 'username' and 'credentials_file' can be set")
+	}
+
+	options := []nats.Option{
+		nats.Name(inputOperator.ID()),
+		nats.MaxReconnects(-1),
+		nats.RetryOnFailedConnect(true),
+	}
+	switch {
+	case c.Username != "":
+		options = append(options, nats.UserInfo(c.Username, c.Password))
+	case c.Token != "":
+		options = append(options, nats.Token(c.Token))
+	case c.CredentialsFile != "":
+		options = append(options, nats.UserCredentials(c.CredentialsFile))
+	}
+
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.LoadTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig != nil {
+			options = append(options, nats.Secure(tlsConfig))
+		}
+	}
+
+	var jetStream *JetStreamConfig
+	if c.JetStream != nil {
+		jetStream = &JetStreamConfig{
+			Stream:  c.JetStream.Stream,
+			Durable: c.JetStream.Durable,
+			StartAt: c.JetStream.StartAt,
+		}
+
+		// Members of a queue group must share a consumer, so it is durable by default
+		if jetStream.Durable == "" {
+			jetStream.Durable = c.QueueGroup
+		}
+		if strings.ContainsAny(jetStream.Durable, ".*>") {
+			return nil, fmt.Errorf("invalid durable name '%s'", jetStream.Durable)
+		}
+
+		switch jetStream.StartAt {
+		case startAtBeginning:
+		case startAtEnd, "":
+			jetStream.StartAt = startAtEnd
+		default:
+			return nil, fmt.Errorf("invalid start_at location '%s'", jetStream.StartAt)
+		}
+	}
+
+	encoding, err := c.Encoding.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	natsInput := &NATSInput{
+		InputOperator:    inputOperator,
+		url:              c.URL,
+		options:          options,
+		subjects:         c.Subjects,
+		queueGroup:       c.QueueGroup,
+		jetStream:        jetStream,
+		headerAttributes: c.HeaderAttributes,
+		encoding:         encoding,
+		sequences:        newSequenceStore(),
+		backoff: backoff.Backoff{
+			Max: 30 * time.Second,
+		},
+	}
+
+	return []operator.Operator{natsInput}, nil
+}
+
+// NATSInput is an operator that receives log entries from NATS subjects.
+type NATSInput struct {
+	helper.InputOperator
+	url              string
+	options          []nats.Option
+	subjects         []string
+	queueGroup       string
+	jetStream        *JetStreamConfig
+	headerAttributes map[string]string
+	encoding         helper.Encoding
+
+	conn      *nats.Conn
+	closed    chan struct{}
+	sequences *sequenceStore
+	persister operator.Persister
+	backoff   backoff.Backoff
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// Start will start receiving from the NATS subjects.
+func (n *NATSInput) Start(persister operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	n.cancel = cancel
+	n.persister = persister
+
+	if err := n.sequences.load(ctx, persister); err != nil {
+		return fmt.Errorf("failed to load sequences: %s", err)
+	}
+
+	n.closed = make(chan struct{})
+	options := append(append([]nats.Option{}, n.options...),
+		nats.ClosedHandler(func(*nats.Conn) { close(n.closed) }),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				n.Warnw("Disconnected from NATS", zap.Error(err))
+			}
+		}),
+		nats.ErrorHandler(func(_ *nats.Conn, _ *nats.Subscription, err error) {
+			n.Errorw("NATS error", zap.Error(err))
+		}),
+	)
+
+	conn, err := nats.Connect(n.url, options...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	n.conn = conn
+
+	n.goSubscribe(ctx)
+	if n.jetStream != nil {
+		n.goPersistSequences(ctx)
+	}
+	return nil
+}
+
+// Stop will stop receiving from the NATS subjects. Messages which were received
+// but not yet written are not acknowledged, and are redelivered by JetStream.
+func (n *NATSInput) Stop() error {
+	n.cancel()
+	if n.conn == nil {
+		return nil
+	}
+
+	// Draining waits for the messages being handled before closing the connection
+	if err := n.conn.Drain(); err != nil {
+		n.conn.Close()
+	}
+	<-n.closed
+	n.wg.Wait()
+
+	if n.jetStream == nil {
+		return nil
+	}
+	return n.sequences.persist(context.Background(), n.persister)
+}
+
+// goSubscribe subscribes to each subject. JetStream subscriptions require the
+// server to be available, so they are retried until they succeed.
+func (n *NATSInput) goSubscribe(ctx context.Context) {
+	n.wg.Add(1)
+
+	go func() {
+		defer n.wg.Done()
+
+		for _, subject := range n.subjects {
+			for {
+				err := n.subscribe(ctx, subject)
+				if err == nil {
+					n.backoff.Reset()
+					break
+				}
+
+				n.Errorw("Failed to subscribe", zap.String("subject", subject), zap.Error(err))
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(n.backoff.Duration()):
+				}
+			}
+		}
+	}()
+}
+
+// subscribe creates a subscription to a subject, which handles each message received.
+// The subscription lasts until the connection is closed.
+func (n *NATSInput) subscribe(ctx context.Context, subject string) error {
+	handler := func(msg *nats.Msg) {
+		n.handleMessage(ctx, subject, msg)
+	}
+
+	var err error
+	if n.jetStream == nil {
+		if n.queueGroup != "" {
+			_, err = n.conn.QueueSubscribe(subject, n.queueGroup, handler)
+		} else {
+			_, err = n.conn.Subscribe(subject, handler)
+		}
+		return err
+	}
+
+	js, err := n.conn.JetStream()
+	if err != nil {
+		return err
+	}
+
+	options := []nats.SubOpt{nats.ManualAck(), nats.AckExplicit()}
+	if n.jetStream.Stream != "" {
+		options = append(options, nats.BindStream(n.jetStream.Stream))
+	}
+	if durable := n.durableName(subject); durable != "" {
+		options = append(options, nats.Durable(durable))
+	}
+
+	// The start position only applies when the consumer is created. A consumer
+	// which already exists resumes from its own position.
+	sequence, ok := n.sequences.get(subject)
+	switch {
+	case ok:
+		options = append(options, nats.StartSequence(sequence+1))
+	case n.jetStream.StartAt == startAtBeginning:
+		options = append(options, nats.DeliverAll())
+	default:
+		options = append(options, nats.DeliverNew())
+	}
+
+	if n.queueGroup != "" {
+		_, err = js.QueueSubscribe(subject, n.queueGroup, handler, options...)
+	} else {
+		_, err = js.Subscribe(subject, handler, options...)
+	}
+	return err
+}
+
+// durableName returns the name of the durable consumer of a subject. A durable
+// consumer receives a single subject, so when there are several subjects, the
+// subject is appended to the configured name.
+func (n *NATSInput) durableName(subject string) string {
+	if n.jetStream.Durable == "" || len(n.subjects) == 1 {
+		return n.jetStream.Durable
+	}
+	return n.jetStream.Durable + "_" + strings.NewReplacer(".", "_", "*", "_", ">", "_").Replace(subject)
+}
+
+// handleMessage creates an entry from a message, and acknowledges JetStream
+// messages once the entry has been written.
+func (n *NATSInput) handleMessage(ctx context.Context, subject string, msg *nats.Msg) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	decoded, err := n.encoding.Decode(msg.Data)
+	if err != nil {
+		n.Errorw("Failed to decode message", zap.Error(err))
+		n.ack(ctx, subject, msg)
+		return
+	}
+
+	entry, err := n.NewEntry(decoded)
+	if err != nil {
+		n.Errorw("Failed to create entry", zap.Error(err))
+		n.ack(ctx, subject, msg)
+		return
+	}
+
+	entry.AddAttribute("nats.subject", msg.Subject)
+	for header, attribute := range n.headerAttributes {
+		if value := msg.Header.Get(header); value != "" {
+			entry.AddAttribute(attribute, value)
+		}
+	}
+
+	if n.jetStream != nil {
+		if metadata, err := msg.Metadata(); err == nil {
+			entry.Timestamp = metadata.Timestamp
+			entry.AddAttribute("nats.stream", metadata.Stream)
+			entry.AddAttribute("nats.sequence", strconv.FormatUint(metadata.Sequence.Stream, 10))
+		}
+	}
+
+	n.Write(ctx, entry)
+	n.ack(ctx, subject, msg)
+}
+
+// ack acknowledges a JetStream message, and records its stream sequence as that of the subscribed subject
+func (n *NATSInput) ack(ctx context.Context, subject string, msg *nats.Msg) {
+	if n.jetStream == nil || ctx.Err() != nil {
+		return
+	}
+
+	if err := msg.Ack(); err != nil {
+		n.Errorw("Failed to acknowledge message", zap.Error(err))
+		return
+	}
+
+	if metadata, err := msg.Metadata(); err == nil {
+		n.sequences.set(subject, metadata.Sequence.Stream)
+	}
+}
+
+// goPersistSequences periodically persists the acknowledged stream sequences.
+func (n *NATSInput) goPersistSequences(ctx context.Context) {
+	n.wg.Add(1)
+
+	go func() {
+		defer n.wg.Done()
+
+		ticker := time.NewTicker(persistInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := n.sequences.persist(ctx, n.persister); err != nil {
+					n.Errorw("Failed to persist sequences", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// sequenceStore holds the last acknowledged stream sequence of each subject
+type sequenceStore struct {
+	sync.Mutex
+	sequences map[string]uint64
+	dirty     bool
+}
+
+func newSequenceStore() *sequenceStore {
+	return &sequenceStore{
+		sequences: make(map[string]uint64),
+	}
+}
+
+func (s *sequenceStore) get(subject string) (uint64, bool) {
+	s.Lock()
+	defer s.Unlock()
+	sequence, ok := s.sequences[subject]
+	return sequence, ok
+}
+
+func (s *sequenceStore) set(subject string, sequence uint64) {
+	s.Lock()
+	defer s.Unlock()
+	if sequence > s.sequences[subject] {
+		s.sequences[subject] = sequence
+		s.dirty = true
+	}
+}
+
+// load loads the sequences from the persister
+func (s *sequenceStore) load(ctx context.Context, persister operator.Persister) error {
+	data, err := persister.Get(ctx, sequencesKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	return json.Unmarshal(data, &s.sequences)
+}
+
+// persist saves the sequences to the persister, if they have changed
+func (s *sequenceStore) persist(ctx context.Context, persister operator.Persister) error {
+	s.Lock()
+	defer s.Unlock()
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(s.sequences)
+	if err != nil {
+		return err
+	}
+	if err := persister.Set(ctx, sequencesKey, data); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
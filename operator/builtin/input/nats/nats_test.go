@@ -0,0 +1,433 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func runTestServer(t *testing.T) *server.Server {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+	s := natsserver.RunServer(&opts)
+	t.Cleanup(s.Shutdown)
+	return s
+}
+
+func newTestConn(t *testing.T, s *server.Server) (*nats.Conn, nats.JetStreamContext) {
+	conn, err := nats.Connect(s.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	js, err := conn.JetStream()
+	require.NoError(t, err)
+	return conn, js
+}
+
+func addTestStream(t *testing.T, js nats.JetStreamContext) {
+	_, err := js.AddStream(&nats.StreamConfig{
+		Name:     "LOGS",
+		Subjects: []string{"logs.>"},
+	})
+	require.NoError(t, err)
+}
+
+func newTestNATSInput(t *testing.T, s *server.Server, persister operator.Persister, cfgMod func(*NATSInputConfig)) (*NATSInput, *testutil.FakeOutput) {
+	cfg := NewNATSInputConfig("test_id")
+	cfg.URL = s.ClientURL()
+	cfg.Subjects = []string{"logs.app"}
+	cfg.OutputIDs = []string{"fake"}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	natsInput := ops[0].(*NATSInput)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, natsInput.SetOutputs([]operator.Operator{fakeOutput}))
+	require.NoError(t, natsInput.Start(persister))
+	return natsInput, fakeOutput
+}
+
+func startTestNATSInput(t *testing.T, s *server.Server, cfgMod func(*NATSInputConfig)) *testutil.FakeOutput {
+	natsInput, fakeOutput := newTestNATSInput(t, s, testutil.NewMockPersister("test"), cfgMod)
+	t.Cleanup(func() { require.NoError(t, natsInput.Stop()) })
+	return fakeOutput
+}
+
+// waitForSubscription waits for the operator to subscribe, since core NATS
+// messages published before then are not received
+func waitForSubscription(t *testing.T, s *server.Server, count uint32) {
+	require.Eventually(t, func() bool {
+		return s.NumSubscriptions() >= count
+	}, time.Second, 10*time.Millisecond)
+}
+
+func expectEntry(t *testing.T, fakeOutput *testutil.FakeOutput) *entry.Entry {
+	select {
+	case e := <-fakeOutput.Received:
+		return e
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+		return nil
+	}
+}
+
+func expectNoEntry(t *testing.T, fakeOutput *testutil.FakeOutput) {
+	select {
+	case e := <-fakeOutput.Received:
+		require.FailNow(t, "Received unexpected entry", "%v", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*NATSInputConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *NATSInputConfig) {},
+			false,
+		},
+		{
+			"MissingURL",
+			func(cfg *NATSInputConfig) {
+				cfg.URL = ""
+			},
+			true,
+		},
+		{
+			"MissingSubjects",
+			func(cfg *NATSInputConfig) {
+				cfg.Subjects = nil
+			},
+			true,
+		},
+		{
+			"UsernameAndToken",
+			func(cfg *NATSInputConfig) {
+				cfg.Username = "collector"
+				cfg.Token = "secret"
+			},
+			true,
+		},
+		{
+			"UsernameAndCredentialsFile",
+			func(cfg *NATSInputConfig) {
+				cfg.Username = "collector"
+				cfg.CredentialsFile = "collector.creds"
+			},
+			true,
+		},
+		{
+			"JetStream",
+			func(cfg *NATSInputConfig) {
+				cfg.JetStream = &JetStreamConfig{}
+			},
+			false,
+		},
+		{
+			"JetStreamStartAtBeginning",
+			func(cfg *NATSInputConfig) {
+				cfg.JetStream = &JetStreamConfig{StartAt: startAtBeginning}
+			},
+			false,
+		},
+		{
+			"JetStreamInvalidStartAt",
+			func(cfg *NATSInputConfig) {
+				cfg.JetStream = &JetStreamConfig{StartAt: "middle"}
+			},
+			true,
+		},
+		{
+			"JetStreamInvalidDurable",
+			func(cfg *NATSInputConfig) {
+				cfg.JetStream = &JetStreamConfig{Durable: "logs.app"}
+			},
+			true,
+		},
+		{
+			"InvalidEncoding",
+			func(cfg *NATSInputConfig) {
+				cfg.Encoding.Encoding = "invalid"
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewNATSInputConfig("test_id")
+			cfg.Subjects = []string{"logs.app"}
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNATSInput(t *testing.T) {
+	s := runTestServer(t)
+	conn, _ := newTestConn(t, s)
+	fakeOutput := startTestNATSInput(t, s, func(cfg *NATSInputConfig) {
+		cfg.Subjects = []string{"logs.>"}
+		cfg.HeaderAttributes = map[string]string{"Service": "service.name"}
+	})
+	waitForSubscription(t, s, 1)
+
+	msg := nats.NewMsg("logs.app")
+	msg.Data = []byte("hello")
+	msg.Header.Set("Service", "checkout")
+	msg.Header.Set("Ignored", "value")
+	require.NoError(t, conn.PublishMsg(msg))
+
+	e := expectEntry(t, fakeOutput)
+	require.Equal(t, "hello", e.Body)
+	require.Equal(t, map[string]string{
+		"nats.subject": "logs.app",
+		"service.name": "checkout",
+	}, e.Attributes)
+}
+
+func TestNATSInputQueueGroup(t *testing.T) {
+	s := runTestServer(t)
+	conn, _ := newTestConn(t, s)
+	queueGroup := func(cfg *NATSInputConfig) {
+		cfg.QueueGroup = "collectors"
+	}
+	first := startTestNATSInput(t, s, queueGroup)
+	second := startTestNATSInput(t, s, queueGroup)
+	waitForSubscription(t, s, 2)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, conn.Publish("logs.app", []byte("hello")))
+	}
+
+	// Each message is received by only one member of the queue group
+	received := 0
+	for received < 10 {
+		select {
+		case <-first.Received:
+		case <-second.Received:
+		case <-time.After(2 * time.Second):
+			require.FailNow(t, "Timed out waiting for entries")
+		}
+		received++
+	}
+	expectNoEntry(t, first)
+	expectNoEntry(t, second)
+}
+
+func TestNATSInputJetStream(t *testing.T) {
+	s := runTestServer(t)
+	_, js := newTestConn(t, s)
+	addTestStream(t, js)
+
+	msg := nats.NewMsg("logs.app")
+	msg.Data = []byte("published before start")
+	msg.Header.Set("Service", "checkout")
+	_, err := js.PublishMsg(msg)
+	require.NoError(t, err)
+
+	fakeOutput := startTestNATSInput(t, s, func(cfg *NATSInputConfig) {
+		cfg.JetStream = &JetStreamConfig{Durable: "collector", StartAt: startAtBeginning}
+		cfg.HeaderAttributes = map[string]string{"Service": "service.name"}
+	})
+
+	e := expectEntry(t, fakeOutput)
+	require.Equal(t, "published before start", e.Body)
+	require.Equal(t, map[string]string{
+		"nats.subject":  "logs.app",
+		"nats.stream":   "LOGS",
+		"nats.sequence": "1",
+		"service.name":  "checkout",
+	}, e.Attributes)
+
+	// The message is acknowledged once it has been written
+	require.Eventually(t, func() bool {
+		info, err := js.ConsumerInfo("LOGS", "collector")
+		return err == nil && info.AckFloor.Stream == 1 && info.NumAckPending == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestNATSInputJetStreamStartAtEnd(t *testing.T) {
+	s := runTestServer(t)
+	_, js := newTestConn(t, s)
+	addTestStream(t, js)
+
+	_, err := js.Publish("logs.app", []byte("old"))
+	require.NoError(t, err)
+
+	fakeOutput := startTestNATSInput(t, s, func(cfg *NATSInputConfig) {
+		cfg.JetStream = &JetStreamConfig{Durable: "collector"}
+	})
+	require.Eventually(t, func() bool {
+		_, err := js.ConsumerInfo("LOGS", "collector")
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+	expectNoEntry(t, fakeOutput)
+
+	_, err = js.Publish("logs.app", []byte("new"))
+	require.NoError(t, err)
+	e := expectEntry(t, fakeOutput)
+	require.Equal(t, "new", e.Body)
+}
+
+func TestNATSInputJetStreamQueueGroup(t *testing.T) {
+	s := runTestServer(t)
+	_, js := newTestConn(t, s)
+	addTestStream(t, js)
+
+	queueGroup := func(cfg *NATSInputConfig) {
+		cfg.QueueGroup = "collectors"
+		cfg.JetStream = &JetStreamConfig{StartAt: startAtBeginning}
+	}
+	first := startTestNATSInput(t, s, queueGroup)
+	require.Eventually(t, func() bool {
+		_, err := js.ConsumerInfo("LOGS", "collectors")
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+	second := startTestNATSInput(t, s, queueGroup)
+
+	for i := 0; i < 10; i++ {
+		_, err := js.Publish("logs.app", []byte("hello"))
+		require.NoError(t, err)
+	}
+
+	// The members of the queue group share the durable consumer named after the group
+	received := 0
+	for received < 10 {
+		select {
+		case <-first.Received:
+		case <-second.Received:
+		case <-time.After(2 * time.Second):
+			require.FailNow(t, "Timed out waiting for entries")
+		}
+		received++
+	}
+	expectNoEntry(t, first)
+	expectNoEntry(t, second)
+}
+
+// PersistedSequence tests that a consumer which is created by a restarted
+// operator resumes after the last sequence acknowledged before it stopped
+func TestNATSInputJetStreamPersistedSequence(t *testing.T) {
+	s := runTestServer(t)
+	_, js := newTestConn(t, s)
+	addTestStream(t, js)
+	persister := testutil.NewMockPersister("test")
+	jetStream := func(cfg *NATSInputConfig) {
+		cfg.JetStream = &JetStreamConfig{StartAt: startAtBeginning}
+	}
+
+	_, err := js.Publish("logs.app", []byte("first"))
+	require.NoError(t, err)
+
+	natsInput, fakeOutput := newTestNATSInput(t, s, persister, jetStream)
+	e := expectEntry(t, fakeOutput)
+	require.Equal(t, "first", e.Body)
+	require.Eventually(t, func() bool {
+		sequence, ok := natsInput.sequences.get("logs.app")
+		return ok && sequence == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, natsInput.Stop())
+
+	_, err = js.Publish("logs.app", []byte("second"))
+	require.NoError(t, err)
+
+	// The ephemeral consumer of the first operator is not reused
+	natsInput, fakeOutput = newTestNATSInput(t, s, persister, jetStream)
+	defer func() { require.NoError(t, natsInput.Stop()) }()
+	e = expectEntry(t, fakeOutput)
+	require.Equal(t, "second", e.Body)
+	expectNoEntry(t, fakeOutput)
+}
+
+func TestNATSInputServerUnavailable(t *testing.T) {
+	s := runTestServer(t)
+	url := s.ClientURL()
+	s.Shutdown()
+
+	cfg := NewNATSInputConfig("test_id")
+	cfg.URL = url
+	cfg.Subjects = []string{"logs.app"}
+	cfg.JetStream = &JetStreamConfig{}
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	natsInput := ops[0].(*NATSInput)
+	require.NoError(t, natsInput.SetOutputs([]operator.Operator{testutil.NewFakeOutput(t)}))
+
+	// The operator starts while the server is unavailable, and keeps trying to connect
+	require.NoError(t, natsInput.Start(testutil.NewMockPersister("test")))
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, natsInput.Stop())
+}
+
+func TestSequenceStore(t *testing.T) {
+	persister := testutil.NewMockPersister("test")
+	store := newSequenceStore()
+	store.set("logs.app", 5)
+	store.set("logs.app", 3)
+	require.NoError(t, store.persist(context.Background(), persister))
+
+	loaded := newSequenceStore()
+	require.NoError(t, loaded.load(context.Background(), persister))
+	sequence, ok := loaded.get("logs.app")
+	require.True(t, ok)
+	require.Equal(t, uint64(5), sequence)
+
+	_, ok = loaded.get("logs.db")
+	require.False(t, ok)
+}
+
+func TestDurableName(t *testing.T) {
+	n := &NATSInput{
+		subjects:  []string{"logs.app"},
+		jetStream: &JetStreamConfig{Durable: "collector"},
+	}
+	require.Equal(t, "collector", n.durableName("logs.app"))
+
+	n.subjects = []string{"logs.app", "logs.db.>"}
+	require.Equal(t, "collector_logs_app", n.durableName("logs.app"))
+	require.Equal(t, "collector_logs_db__", n.durableName("logs.db.>"))
+
+	n.jetStream.Durable = ""
+	require.Equal(t, "", n.durableName("logs.app"))
+}
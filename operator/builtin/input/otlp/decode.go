@@ -0,0 +1,349 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/input/internal/grpcwire"
+)
+
+// The field numbers of the OTLP log messages which are decoded.
+// Unknown fields are skipped, as protobuf requires.
+const (
+	requestResourceLogs = 1
+
+	resourceLogsResource   = 1
+	resourceLogsLibrary    = 2
+	resourceAttributes     = 1
+	libraryLogsLibrary     = 1
+	libraryLogsLogs        = 2
+	libraryName            = 1
+	libraryVersion         = 2
+	recordTimeUnixNano     = 1
+	recordSeverityNumber   = 2
+	recordSeverityText     = 3
+	recordBody             = 5
+	recordAttributes       = 6
+	recordFlags            = 8
+	recordTraceID          = 9
+	recordSpanID           = 10
+	recordObservedUnixNano = 11
+
+	keyValueKey   = 1
+	keyValueValue = 2
+
+	anyValueString = 1
+	anyValueBool   = 2
+	anyValueInt    = 3
+	anyValueDouble = 4
+	anyValueArray  = 5
+	anyValueKvlist = 6
+	anyValueBytes  = 7
+
+	listValues = 1
+)
+
+// severities maps OTLP severity numbers to entry severities. Each OTLP
+// severity range of four numbers corresponds to an entry severity and
+// its three finer-grained variants.
+var severities = map[uint64]entry.Severity{
+	1: entry.Trace, 2: entry.Trace2, 3: entry.Trace3, 4: entry.Trace4,
+	5: entry.Debug, 6: entry.Debug2, 7: entry.Debug3, 8: entry.Debug4,
+	9: entry.Info, 10: entry.Info2, 11: entry.Info3, 12: entry.Info4,
+	13: entry.Warning, 14: entry.Warning2, 15: entry.Warning3, 16: entry.Warning4,
+	17: entry.Error, 18: entry.Error2, 19: entry.Error3, 20: entry.Error4,
+	21: entry.Emergency, 22: entry.Emergency2, 23: entry.Emergency3, 24: entry.Emergency4,
+}
+
+// logRecord is a log record with the resource and instrumentation
+// library to which it belongs
+type logRecord struct {
+	resource       map[string]string
+	libraryName    string
+	libraryVersion string
+
+	timestamp    time.Time
+	severity     entry.Severity
+	severityText string
+	body         interface{}
+	attributes   map[string]string
+	traceID      []byte
+	spanID       []byte
+	traceFlags   []byte
+}
+
+// decodeRequest decodes the log records of an ExportLogsServiceRequest
+func decodeRequest(b []byte) ([]*logRecord, error) {
+	records := make([]*logRecord, 0)
+	err := grpcwire.ForEachField(b, func(f grpcwire.Field) error {
+		if f.Num != requestResourceLogs {
+			return nil
+		}
+		resourceRecords, err := decodeResourceLogs(f.Bytes)
+		if err != nil {
+			return fmt.Errorf("resource_logs: %s", err)
+		}
+		records = append(records, resourceRecords...)
+		return nil
+	})
+	return records, err
+}
+
+func decodeResourceLogs(b []byte) ([]*logRecord, error) {
+	// The resource may follow the logs which belong to it
+	var resource map[string]string
+	var libraryLogs [][]byte
+	err := grpcwire.ForEachField(b, func(f grpcwire.Field) error {
+		switch f.Num {
+		case resourceLogsResource:
+			var err error
+			resource, err = decodeResource(f.Bytes)
+			return err
+		case resourceLogsLibrary:
+			libraryLogs = append(libraryLogs, f.Bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*logRecord, 0)
+	for _, b := range libraryLogs {
+		libraryRecords, err := decodeLibraryLogs(b, resource)
+		if err != nil {
+			return nil, fmt.Errorf("instrumentation_library_logs: %s", err)
+		}
+		records = append(records, libraryRecords...)
+	}
+	return records, nil
+}
+
+func decodeResource(b []byte) (map[string]string, error) {
+	var attributes map[string]string
+	err := grpcwire.ForEachField(b, func(f grpcwire.Field) error {
+		if f.Num != resourceAttributes {
+			return nil
+		}
+		if attributes == nil {
+			attributes = make(map[string]string)
+		}
+		return decodeAttribute(f.Bytes, attributes)
+	})
+	return attributes, err
+}
+
+func decodeLibraryLogs(b []byte, resource map[string]string) ([]*logRecord, error) {
+	var name, version string
+	var logs [][]byte
+	err := grpcwire.ForEachField(b, func(f grpcwire.Field) error {
+		switch f.Num {
+		case libraryLogsLibrary:
+			return grpcwire.ForEachField(f.Bytes, func(f grpcwire.Field) error {
+				switch f.Num {
+				case libraryName:
+					name = string(f.Bytes)
+				case libraryVersion:
+					version = string(f.Bytes)
+				}
+				return nil
+			})
+		case libraryLogsLogs:
+			logs = append(logs, f.Bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*logRecord, 0, len(logs))
+	for _, b := range logs {
+		record, err := decodeLogRecord(b)
+		if err != nil {
+			return nil, fmt.Errorf("logs: %s", err)
+		}
+		record.resource = resource
+		record.libraryName = name
+		record.libraryVersion = version
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func decodeLogRecord(b []byte) (*logRecord, error) {
+	record := &logRecord{}
+	var observed time.Time
+	err := grpcwire.ForEachField(b, func(f grpcwire.Field) error {
+		var err error
+		switch f.Num {
+		case recordTimeUnixNano:
+			if f.Value != 0 {
+				record.timestamp = time.Unix(0, int64(f.Value))
+			}
+		case recordObservedUnixNano:
+			if f.Value != 0 {
+				observed = time.Unix(0, int64(f.Value))
+			}
+		case recordSeverityNumber:
+			record.severity = severities[f.Value]
+		case recordSeverityText:
+			record.severityText = string(f.Bytes)
+		case recordBody:
+			record.body, err = decodeAnyValue(f.Bytes)
+		case recordAttributes:
+			if record.attributes == nil {
+				record.attributes = make(map[string]string)
+			}
+			err = decodeAttribute(f.Bytes, record.attributes)
+		case recordFlags:
+			if f.Value != 0 {
+				record.traceFlags = []byte{byte(f.Value)}
+			}
+		case recordTraceID:
+			if len(f.Bytes) > 0 {
+				record.traceID = append([]byte(nil), f.Bytes...)
+			}
+		case recordSpanID:
+			if len(f.Bytes) > 0 {
+				record.spanID = append([]byte(nil), f.Bytes...)
+			}
+		}
+		return err
+	})
+	if record.timestamp.IsZero() {
+		record.timestamp = observed
+	}
+	return record, err
+}
+
+// decodeAttribute decodes a KeyValue into the attributes. Values which are
+// not strings are formatted, since the attributes of an entry are strings.
+func decodeAttribute(b []byte, attributes map[string]string) error {
+	var key string
+	var value interface{}
+	err := grpcwire.ForEachField(b, func(f grpcwire.Field) error {
+		var err error
+		switch f.Num {
+		case keyValueKey:
+			key = string(f.Bytes)
+		case keyValueValue:
+			value, err = decodeAnyValue(f.Bytes)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	formatted, err := formatValue(value)
+	if err != nil {
+		return fmt.Errorf("attribute '%s': %s", key, err)
+	}
+	attributes[key] = formatted
+	return nil
+}
+
+// decodeAnyValue decodes an AnyValue into the equivalent Go value
+func decodeAnyValue(b []byte) (interface{}, error) {
+	var value interface{}
+	err := grpcwire.ForEachField(b, func(f grpcwire.Field) error {
+		var err error
+		switch f.Num {
+		case anyValueString:
+			value = string(f.Bytes)
+		case anyValueBool:
+			value = f.Value != 0
+		case anyValueInt:
+			value = int64(f.Value)
+		case anyValueDouble:
+			value = math.Float64frombits(f.Value)
+		case anyValueArray:
+			value, err = decodeArrayValue(f.Bytes)
+		case anyValueKvlist:
+			value, err = decodeKvlistValue(f.Bytes)
+		case anyValueBytes:
+			value = append([]byte(nil), f.Bytes...)
+		}
+		return err
+	})
+	return value, err
+}
+
+func decodeArrayValue(b []byte) ([]interface{}, error) {
+	values := make([]interface{}, 0)
+	err := grpcwire.ForEachField(b, func(f grpcwire.Field) error {
+		if f.Num != listValues {
+			return nil
+		}
+		value, err := decodeAnyValue(f.Bytes)
+		values = append(values, value)
+		return err
+	})
+	return values, err
+}
+
+func decodeKvlistValue(b []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	err := grpcwire.ForEachField(b, func(f grpcwire.Field) error {
+		if f.Num != listValues {
+			return nil
+		}
+		var key string
+		var value interface{}
+		err := grpcwire.ForEachField(f.Bytes, func(f grpcwire.Field) error {
+			var err error
+			switch f.Num {
+			case keyValueKey:
+				key = string(f.Bytes)
+			case keyValueValue:
+				value, err = decodeAnyValue(f.Bytes)
+			}
+			return err
+		})
+		values[key] = value
+		return err
+	})
+	return values, err
+}
+
+// formatValue formats a decoded value as a string. Arrays and maps are
+// formatted as JSON, and bytes are base64 encoded.
+func formatValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v), nil
+	default:
+		formatted, err := json.Marshal(v)
+		return string(formatted), err
+	}
+}
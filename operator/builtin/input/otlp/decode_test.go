@@ -0,0 +1,166 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+)
+
+func message(fields ...[]byte) []byte {
+	return bytes.Join(fields, nil)
+}
+
+func bytesField(num protowire.Number, b []byte) []byte {
+	return protowire.AppendBytes(protowire.AppendTag(nil, num, protowire.BytesType), b)
+}
+
+func stringField(num protowire.Number, s string) []byte {
+	return bytesField(num, []byte(s))
+}
+
+func varintField(num protowire.Number, v uint64) []byte {
+	return protowire.AppendVarint(protowire.AppendTag(nil, num, protowire.VarintType), v)
+}
+
+func fixed64Field(num protowire.Number, v uint64) []byte {
+	return protowire.AppendFixed64(protowire.AppendTag(nil, num, protowire.Fixed64Type), v)
+}
+
+func fixed32Field(num protowire.Number, v uint32) []byte {
+	return protowire.AppendFixed32(protowire.AppendTag(nil, num, protowire.Fixed32Type), v)
+}
+
+func keyValue(key string, value []byte) []byte {
+	return message(stringField(keyValueKey, key), bytesField(keyValueValue, value))
+}
+
+func stringValue(s string) []byte {
+	return stringField(anyValueString, s)
+}
+
+// testRequest is an export request containing a single
+// log record with values of each type
+func testRequest() []byte {
+	body := bytesField(anyValueKvlist, message(
+		bytesField(listValues, keyValue("message", stringValue("hello"))),
+		bytesField(listValues, keyValue("count", varintField(anyValueInt, 3))),
+		bytesField(listValues, keyValue("tags", bytesField(anyValueArray, message(
+			bytesField(listValues, stringValue("a")),
+			bytesField(listValues, varintField(anyValueBool, 1)),
+		)))),
+	))
+
+	record := message(
+		fixed64Field(recordTimeUnixNano, 1600000000000000001),
+		varintField(recordSeverityNumber, 18),
+		stringField(recordSeverityText, "ERROR2"),
+		bytesField(recordBody, body),
+		bytesField(recordAttributes, keyValue("http.method", stringValue("GET"))),
+		bytesField(recordAttributes, keyValue("http.status_code", varintField(anyValueInt, 500))),
+		bytesField(recordAttributes, keyValue("duration", fixed64Field(anyValueDouble, math.Float64bits(1.5)))),
+		fixed32Field(recordFlags, 1),
+		bytesField(recordTraceID, []byte{0x48, 0x01, 0x40, 0xf3, 0xd7, 0x70, 0xa5, 0xae, 0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff}),
+		bytesField(recordSpanID, []byte{0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff}),
+		// An unknown field is skipped
+		stringField(100, "unknown"),
+	)
+
+	libraryLogs := message(
+		bytesField(libraryLogsLogs, record),
+		bytesField(libraryLogsLibrary, message(
+			stringField(libraryName, "checkout"),
+			stringField(libraryVersion, "1.0.0"),
+		)),
+	)
+
+	// The resource follows the logs which belong to it
+	resourceLogs := message(
+		bytesField(resourceLogsLibrary, libraryLogs),
+		bytesField(resourceLogsResource, bytesField(resourceAttributes, keyValue("service.name", stringValue("shop")))),
+	)
+
+	return bytesField(requestResourceLogs, resourceLogs)
+}
+
+func TestDecodeRequest(t *testing.T) {
+	records, err := decodeRequest(testRequest())
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	require.Equal(t, &logRecord{
+		resource:       map[string]string{"service.name": "shop"},
+		libraryName:    "checkout",
+		libraryVersion: "1.0.0",
+		timestamp:      time.Unix(0, 1600000000000000001),
+		severity:       entry.Error2,
+		severityText:   "ERROR2",
+		body: map[string]interface{}{
+			"message": "hello",
+			"count":   int64(3),
+			"tags":    []interface{}{"a", true},
+		},
+		attributes: map[string]string{
+			"http.method":      "GET",
+			"http.status_code": "500",
+			"duration":         "1.5",
+		},
+		traceID:    []byte{0x48, 0x01, 0x40, 0xf3, 0xd7, 0x70, 0xa5, 0xae, 0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff},
+		spanID:     []byte{0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff},
+		traceFlags: []byte{1},
+	}, records[0])
+}
+
+func TestDecodeObservedTimestamp(t *testing.T) {
+	record, err := decodeLogRecord(fixed64Field(recordObservedUnixNano, 1600000000000000000))
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(0, 1600000000000000000), record.timestamp)
+}
+
+func TestDecodeInvalidRequest(t *testing.T) {
+	// A field which is truncated
+	request := bytesField(requestResourceLogs, testRequest())
+	_, err := decodeRequest(request[:len(request)-3])
+	require.Error(t, err)
+}
+
+func TestFormatValue(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, ""},
+		{"value", "value"},
+		{true, "true"},
+		{int64(-12), "-12"},
+		{0.25, "0.25"},
+		{[]byte("value"), "dmFsdWU="},
+		{[]interface{}{"a", int64(1)}, `["a",1]`},
+		{map[string]interface{}{"key": "value"}, `{"key":"value"}`},
+	}
+
+	for _, tc := range cases {
+		formatted, err := formatValue(tc.value)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, formatted)
+	}
+}
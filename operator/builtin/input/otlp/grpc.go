@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/input/internal/grpcwire"
+)
+
+// grpcExportPath is the path of gRPC log export requests
+const grpcExportPath = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+
+// handleGRPC handles a unary gRPC export request. gRPC messages are sent over
+// HTTP/2 with a five byte prefix, which contains a compression flag and the
+// length of the message, and the status of the call is sent in the trailers.
+func (o *OTLPInput) handleGRPC(w http.ResponseWriter, r *http.Request) {
+	if !grpcwire.IsGRPC(r) {
+		http.Error(w, "expected a gRPC request", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if r.URL.Path != grpcExportPath {
+		writeGRPCStatus(w, grpcwire.CodeUnimplemented, fmt.Sprintf("unknown method %s", r.URL.Path))
+		return
+	}
+
+	encoding := r.Header.Get("Grpc-Encoding")
+	if !grpcwire.SupportedEncoding(encoding) {
+		writeGRPCStatus(w, grpcwire.CodeUnimplemented, fmt.Sprintf("unsupported grpc-encoding '%s'", encoding))
+		return
+	}
+
+	// A unary request contains exactly one message
+	message, code, err := grpcwire.ReadMessage(r.Body, encoding, o.maxRequestSize)
+	if err == io.EOF {
+		code, err = grpcwire.CodeInvalidArgument, fmt.Errorf("request does not contain a message")
+	}
+	if err != nil {
+		writeGRPCStatus(w, code, err.Error())
+		return
+	}
+	if n, _ := r.Body.Read(make([]byte, 1)); n > 0 {
+		writeGRPCStatus(w, grpcwire.CodeInvalidArgument, "request contains more than one message")
+		return
+	}
+
+	if err := o.export(r.Context(), message); err != nil {
+		o.Debugw("Failed to decode export request", zap.Error(err))
+		writeGRPCStatus(w, grpcwire.CodeInvalidArgument, err.Error())
+		return
+	}
+	writeGRPCStatus(w, grpcwire.CodeOK, "")
+}
+
+// writeGRPCStatus writes the response to a unary gRPC request. A successful
+// response contains an empty ExportLogsServiceResponse. The trailers are declared
+// before the response is started, since it may not have a body to precede them.
+func writeGRPCStatus(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Add("Trailer", "Grpc-Status")
+	w.Header().Add("Trailer", "Grpc-Message")
+	w.WriteHeader(http.StatusOK)
+	if code == grpcwire.CodeOK {
+		_, _ = w.Write(grpcwire.Frame(nil))
+	}
+	grpcwire.WriteStatus(w, code, message)
+}
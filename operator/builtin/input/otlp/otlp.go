@@ -0,0 +1,348 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// DefaultMaxRequestSize is the max size of an export request
+	// if MaxRequestSize is not set
+	DefaultMaxRequestSize = 4 * 1024 * 1024
+
+	// httpPath is the path of OTLP/HTTP log export requests
+	httpPath = "/v1/logs"
+
+	// shutdownTimeout is how long requests which are being
+	// handled are waited for when the operator is stopped
+	shutdownTimeout = 5 * time.Second
+
+	// maxDiscardSize is the most of the body of a rejected request which
+	// is read and discarded before the response is sent
+	maxDiscardSize = 4 * 1024 * 1024
+)
+
+func init() {
+	operator.Register("otlp_input", func() operator.Builder { return NewOTLPInputConfig("") })
+}
+
+// NewOTLPInputConfig creates a new OTLP input config with default values
+func NewOTLPInputConfig(operatorID string) *OTLPInputConfig {
+	return &OTLPInputConfig{
+		InputConfig:    helper.NewInputConfig(operatorID, "otlp_input"),
+		MaxRequestSize: DefaultMaxRequestSize,
+	}
+}
+
+// OTLPInputConfig is the configuration of an OTLP input operator.
+type OTLPInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	GRPCListenAddress string                  `mapstructure:"grpc_listen_address,omitempty" json:"grpc_listen_address,omitempty" yaml:"grpc_listen_address,omitempty"`
+	HTTPListenAddress string                  `mapstructure:"http_listen_address,omitempty" json:"http_listen_address,omitempty" yaml:"http_listen_address,omitempty"`
+	TLS               *helper.TLSServerConfig `mapstructure:"tls,omitempty"                 json:"tls,omitempty"                 yaml:"tls,omitempty"`
+	MaxRequestSize    helper.ByteSize         `mapstructure:"max_request_size,omitempty"    json:"max_request_size,omitempty"    yaml:"max_request_size,omitempty"`
+}
+
+// Build will build an OTLP input operator.
+func (c OTLPInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.GRPCListenAddress == "" && c.HTTPListenAddress == "" {
+		return nil, fmt.Errorf("at least one of 'grpc_listen_address' and 'http_listen_address' is required")
+	}
+
+	// validate the input addresses
+	if c.GRPCListenAddress != "" {
+		if _, err := net.ResolveTCPAddr("tcp", c.GRPCListenAddress); err != nil {
+			return nil, fmt.Errorf("failed to resolve grpc_listen_address: %s", err)
+		}
+	}
+	if c.HTTPListenAddress != "" {
+		if _, err := net.ResolveTCPAddr("tcp", c.HTTPListenAddress); err != nil {
+			return nil, fmt.Errorf("failed to resolve http_listen_address: %s", err)
+		}
+	}
+
+	if c.MaxRequestSize <= 0 {
+		return nil, fmt.Errorf("`max_request_size` must be positive")
+	}
+
+	otlpInput := &OTLPInput{
+		InputOperator:  inputOperator,
+		grpcAddress:    c.GRPCListenAddress,
+		httpAddress:    c.HTTPListenAddress,
+		maxRequestSize: int64(c.MaxRequestSize),
+	}
+
+	if c.TLS != nil {
+		otlpInput.tls, err = c.TLS.LoadTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []operator.Operator{otlpInput}, nil
+}
+
+// OTLPInput is an operator that receives log entries in OTLP export requests.
+type OTLPInput struct {
+	helper.InputOperator
+	grpcAddress    string
+	httpAddress    string
+	maxRequestSize int64
+	tls            *tls.Config
+
+	grpcListener net.Listener
+	httpListener net.Listener
+	servers      []*http.Server
+	wg           sync.WaitGroup
+}
+
+// Start will start listening for export requests.
+func (o *OTLPInput) Start(_ operator.Persister) error {
+	if o.grpcAddress != "" {
+		listener, err := o.listen(o.grpcAddress, []string{http2.NextProtoTLS})
+		if err != nil {
+			return err
+		}
+		o.grpcListener = listener
+
+		server := &http.Server{Handler: http.HandlerFunc(o.handleGRPC)}
+		if o.tls == nil {
+			// gRPC requires HTTP/2, which must be negotiated
+			// without TLS when TLS is not used
+			server.Handler = h2c.NewHandler(server.Handler, &http2.Server{})
+		} else if err := http2.ConfigureServer(server, nil); err != nil {
+			listener.Close()
+			return fmt.Errorf("failed to configure http2: %s", err)
+		}
+		o.serve(server, listener)
+	}
+
+	if o.httpAddress != "" {
+		listener, err := o.listen(o.httpAddress, []string{http2.NextProtoTLS, "http/1.1"})
+		if err != nil {
+			o.shutdown()
+			return err
+		}
+		o.httpListener = listener
+
+		mux := http.NewServeMux()
+		mux.HandleFunc(httpPath, o.handleHTTP)
+		o.serve(&http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}, listener)
+	}
+	return nil
+}
+
+func (o *OTLPInput) listen(address string, protocols []string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on interface: %w", err)
+	}
+	if o.tls == nil {
+		return listener, nil
+	}
+
+	config := o.tls.Clone()
+	config.NextProtos = protocols
+	return tls.NewListener(listener, config), nil
+}
+
+func (o *OTLPInput) serve(server *http.Server, listener net.Listener) {
+	o.servers = append(o.servers, server)
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			o.Errorw("Server failed", zap.Error(err))
+		}
+	}()
+}
+
+// Stop will stop listening for export requests.
+func (o *OTLPInput) Stop() error {
+	err := o.shutdown()
+	o.wg.Wait()
+	return err
+}
+
+func (o *OTLPInput) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	var firstErr error
+	for _, server := range o.servers {
+		if err := server.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	o.servers = nil
+	return firstErr
+}
+
+// handleHTTP handles an OTLP/HTTP export request encoded as protobuf
+func (o *OTLPInput) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if contentType := r.Header.Get("Content-Type"); contentType != "application/x-protobuf" {
+		http.Error(w, fmt.Sprintf("unsupported content type '%s', only application/x-protobuf is supported", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			rejectHTTP(w, r, err)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	data, err := readLimited(body, o.maxRequestSize)
+	if err != nil {
+		rejectHTTP(w, r, err)
+		return
+	}
+
+	if err := o.export(r.Context(), data); err != nil {
+		o.Debugw("Failed to decode export request", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The response is an empty ExportLogsServiceResponse
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+}
+
+// rejectHTTP responds to an OTLP/HTTP request whose body could not be read.
+// The rest of the body is discarded first, so that the client receives the
+// response rather than a reset connection while it is still sending.
+func rejectHTTP(w http.ResponseWriter, r *http.Request, err error) {
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(r.Body, maxDiscardSize))
+
+	var tooLarge *tooLargeError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// export decodes an ExportLogsServiceRequest and sends its log records as entries
+func (o *OTLPInput) export(ctx context.Context, data []byte) error {
+	records, err := decodeRequest(data)
+	if err != nil {
+		return fmt.Errorf("decode export request: %s", err)
+	}
+
+	for _, record := range records {
+		entry, err := o.newEntry(record)
+		if err != nil {
+			o.Errorw("Failed to create entry", zap.Error(err))
+			continue
+		}
+		o.Write(ctx, entry)
+	}
+	return nil
+}
+
+// newEntry creates an entry from a log record. The attributes and resource
+// configured on the operator are added to those of the log record.
+func (o *OTLPInput) newEntry(record *logRecord) (*entry.Entry, error) {
+	e := entry.New()
+	if !record.timestamp.IsZero() {
+		e.Timestamp = record.timestamp
+	}
+	e.Severity = record.severity
+	e.SeverityText = record.severityText
+	e.TraceId = record.traceID
+	e.SpanId = record.spanID
+	e.TraceFlags = record.traceFlags
+
+	for key, value := range record.attributes {
+		e.AddAttribute(key, value)
+	}
+	if record.libraryName != "" {
+		e.AddAttribute("otel.library.name", record.libraryName)
+	}
+	if record.libraryVersion != "" {
+		e.AddAttribute("otel.library.version", record.libraryVersion)
+	}
+	for key, value := range record.resource {
+		e.AddResourceKey(key, value)
+	}
+
+	if err := e.Set(o.WriteTo, record.body); err != nil {
+		return nil, fmt.Errorf("add body to entry: %s", err)
+	}
+	if err := o.Attribute(e); err != nil {
+		return nil, fmt.Errorf("add attributes to entry: %s", err)
+	}
+	if err := o.Identify(e); err != nil {
+		return nil, fmt.Errorf("add resource keys to entry: %s", err)
+	}
+	return e, nil
+}
+
+// tooLargeError is returned by readLimited if there are more bytes than the limit
+type tooLargeError struct {
+	limit int64
+}
+
+func (e *tooLargeError) Error() string {
+	return fmt.Sprintf("request larger than %d bytes", e.limit)
+}
+
+// readLimited reads at most limit bytes, and returns an error if there are more
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, &tooLargeError{limit: limit}
+	}
+	return data, nil
+}
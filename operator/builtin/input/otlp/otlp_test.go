@@ -0,0 +1,295 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/input/internal/grpcwire"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestOTLPInput(t *testing.T, cfgMod func(*OTLPInputConfig)) (*OTLPInput, *testutil.FakeOutput) {
+	cfg := NewOTLPInputConfig("test_id")
+	cfg.GRPCListenAddress = "127.0.0.1:0"
+	cfg.HTTPListenAddress = "127.0.0.1:0"
+	cfg.OutputIDs = []string{"fake"}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	otlpInput := ops[0].(*OTLPInput)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, otlpInput.SetOutputs([]operator.Operator{fakeOutput}))
+
+	require.NoError(t, otlpInput.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() {
+		// Connections which the client dialed but never used would
+		// otherwise keep the server from shutting down
+		http.DefaultClient.CloseIdleConnections()
+		require.NoError(t, otlpInput.Stop())
+	})
+
+	return otlpInput, fakeOutput
+}
+
+// grpcClient is an HTTP/2 client which does not use TLS
+func grpcClient() *http.Client {
+	return &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+}
+
+func grpcRequest(t *testing.T, address string, path string, message []byte) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, "http://"+address+path, bytes.NewReader(grpcwire.Frame(message)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	resp, err := grpcClient().Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func expectEntry(t *testing.T, output *testutil.FakeOutput) *entry.Entry {
+	select {
+	case e := <-output.Received:
+		return e
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+	return nil
+}
+
+func expectTestEntry(t *testing.T, output *testutil.FakeOutput) {
+	e := expectEntry(t, output)
+	require.Equal(t, time.Unix(0, 1600000000000000001), e.Timestamp)
+	require.Equal(t, entry.Error2, e.Severity)
+	require.Equal(t, "ERROR2", e.SeverityText)
+	require.Equal(t, map[string]interface{}{
+		"message": "hello",
+		"count":   int64(3),
+		"tags":    []interface{}{"a", true},
+	}, e.Body)
+	require.Equal(t, map[string]string{
+		"http.method":          "GET",
+		"http.status_code":     "500",
+		"duration":             "1.5",
+		"otel.library.name":    "checkout",
+		"otel.library.version": "1.0.0",
+	}, e.Attributes)
+	require.Equal(t, map[string]string{"service.name": "shop"}, e.Resource)
+	require.Len(t, e.TraceId, 16)
+	require.Len(t, e.SpanId, 8)
+	require.Equal(t, []byte{1}, e.TraceFlags)
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*OTLPInputConfig)
+		expectErr bool
+	}{
+		{
+			"grpc",
+			func(c *OTLPInputConfig) {
+				c.GRPCListenAddress = "10.0.0.1:4317"
+			},
+			false,
+		},
+		{
+			"http",
+			func(c *OTLPInputConfig) {
+				c.HTTPListenAddress = "10.0.0.1:4318"
+			},
+			false,
+		},
+		{
+			"missing-address",
+			func(c *OTLPInputConfig) {},
+			true,
+		},
+		{
+			"invalid-address",
+			func(c *OTLPInputConfig) {
+				c.GRPCListenAddress = "10.0.0.1"
+			},
+			true,
+		},
+		{
+			"negative-max-request-size",
+			func(c *OTLPInputConfig) {
+				c.HTTPListenAddress = "10.0.0.1:4318"
+				c.MaxRequestSize = -1
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewOTLPInputConfig("test_id")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestOTLPInputHTTP(t *testing.T) {
+	otlpInput, output := newTestOTLPInput(t, nil)
+	url := "http://" + otlpInput.httpListener.Addr().String() + httpPath
+
+	resp, err := http.Post(url, "application/x-protobuf", bytes.NewReader(testRequest()))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	expectTestEntry(t, output)
+}
+
+func TestOTLPInputHTTPGzip(t *testing.T) {
+	otlpInput, output := newTestOTLPInput(t, nil)
+	url := "http://" + otlpInput.httpListener.Addr().String() + httpPath
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write(testRequest())
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	req, err := http.NewRequest(http.MethodPost, url, &buf)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	expectTestEntry(t, output)
+}
+
+func TestOTLPInputHTTPErrors(t *testing.T) {
+	otlpInput, _ := newTestOTLPInput(t, func(cfg *OTLPInputConfig) {
+		cfg.MaxRequestSize = 1024
+	})
+	url := "http://" + otlpInput.httpListener.Addr().String() + httpPath
+
+	// The JSON encoding of OTLP/HTTP is not supported
+	resp, err := http.Post(url, "application/json", bytes.NewReader([]byte("{}")))
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	require.Equal(t, "unsupported content type 'application/json', only application/x-protobuf is supported\n", string(body))
+
+	resp, err = http.Post(url, "application/x-protobuf", bytes.NewReader([]byte{0x0a, 0x10}))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Post(url, "application/x-protobuf", bytes.NewReader(make([]byte, 2048)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	// The connection is reused after a request is rejected for its size
+	resp, err = http.Post(url, "application/x-protobuf", bytes.NewReader(testRequest()))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestOTLPInputGRPC(t *testing.T) {
+	otlpInput, output := newTestOTLPInput(t, nil)
+
+	resp := grpcRequest(t, otlpInput.grpcListener.Addr().String(), grpcExportPath, testRequest())
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, make([]byte, 5), body)
+	require.Equal(t, "0", resp.Trailer.Get("Grpc-Status"))
+	expectTestEntry(t, output)
+}
+
+func TestOTLPInputGRPCErrors(t *testing.T) {
+	otlpInput, _ := newTestOTLPInput(t, nil)
+	address := otlpInput.grpcListener.Addr().String()
+
+	resp := grpcRequest(t, address, "/opentelemetry.proto.collector.trace.v1.TraceService/Export", nil)
+	_, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, "12", resp.Trailer.Get("Grpc-Status"))
+
+	resp = grpcRequest(t, address, grpcExportPath, []byte{0x0a, 0x10})
+	_, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, "3", resp.Trailer.Get("Grpc-Status"))
+	require.NotEmpty(t, resp.Trailer.Get("Grpc-Message"))
+
+	req, err := http.NewRequest(http.MethodPost, "http://"+address+grpcExportPath, bytes.NewReader(grpcwire.Frame(testRequest())))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("Grpc-Encoding", "snappy")
+	resp, err = grpcClient().Do(req)
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, "12", resp.Trailer.Get("Grpc-Status"))
+	require.Equal(t, "unsupported grpc-encoding 'snappy'", resp.Trailer.Get("Grpc-Message"))
+}
+
+func TestOTLPInputConfiguredAttributes(t *testing.T) {
+	otlpInput, output := newTestOTLPInput(t, func(cfg *OTLPInputConfig) {
+		cfg.Attributes = map[string]helper.ExprStringConfig{"http.method": "POST"}
+	})
+	url := "http://" + otlpInput.httpListener.Addr().String() + httpPath
+
+	resp, err := http.Post(url, "application/x-protobuf", bytes.NewReader(testRequest()))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	e := expectEntry(t, output)
+	require.Equal(t, "POST", e.Attributes["http.method"])
+}
@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// receivedMessage is a message pulled from a subscription
+type receivedMessage struct {
+	AckID   string `json:"ackId"`
+	Message struct {
+		Data        []byte            `json:"data"`
+		Attributes  map[string]string `json:"attributes"`
+		MessageID   string            `json:"messageId"`
+		PublishTime time.Time         `json:"publishTime"`
+	} `json:"message"`
+}
+
+// subscriptionClient is a minimal client of the Pub/Sub REST API for a single subscription
+type subscriptionClient struct {
+	client          *http.Client
+	subscriptionURL string
+}
+
+func newSubscriptionClient(client *http.Client, endpoint, subscription string) *subscriptionClient {
+	return &subscriptionClient{
+		client:          client,
+		subscriptionURL: strings.TrimSuffix(endpoint, "/") + "/v1/" + subscription,
+	}
+}
+
+// pull waits for messages from the subscription
+func (c *subscriptionClient) pull(ctx context.Context, maxMessages int) ([]receivedMessage, error) {
+	request := map[string]interface{}{"maxMessages": maxMessages}
+	var response struct {
+		ReceivedMessages []receivedMessage `json:"receivedMessages"`
+	}
+	if err := c.post(ctx, ":pull", request, &response); err != nil {
+		return nil, err
+	}
+	return response.ReceivedMessages, nil
+}
+
+// acknowledge acknowledges messages, so that they are not delivered again
+func (c *subscriptionClient) acknowledge(ctx context.Context, ackIDs []string) error {
+	request := map[string]interface{}{"ackIds": ackIDs}
+	return c.post(ctx, ":acknowledge", request, nil)
+}
+
+func (c *subscriptionClient) post(ctx context.Context, method string, request interface{}, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.subscriptionURL+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return apiError(resp.StatusCode, respBody)
+	}
+	if response == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, response)
+}
+
+// apiError returns the error described by the body of a failed response
+func apiError(statusCode int, body []byte) error {
+	var errResponse struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResponse); err != nil || errResponse.Error.Message == "" {
+		return fmt.Errorf("request failed with status %d", statusCode)
+	}
+	return fmt.Errorf("request failed with status %d (%s): %s", statusCode, errResponse.Error.Status, errResponse.Error.Message)
+}
@@ -0,0 +1,249 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jpillora/backoff"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	defaultEndpoint = "https://pubsub.googleapis.com"
+	pubsubScope     = "https://www.googleapis.com/auth/pubsub"
+)
+
+// subscriptionPattern matches the full name of a subscription
+var subscriptionPattern = regexp.MustCompile(`^projects/[^/]+/subscriptions/[^/]+$`)
+
+func init() {
+	operator.Register("pubsub_input", func() operator.Builder { return NewPubSubInputConfig("") })
+}
+
+// NewPubSubInputConfig creates a new Pub/Sub input config with default values
+func NewPubSubInputConfig(operatorID string) *PubSubInputConfig {
+	return &PubSubInputConfig{
+		InputConfig: helper.NewInputConfig(operatorID, "pubsub_input"),
+		Endpoint:    defaultEndpoint,
+		MaxMessages: 100,
+	}
+}
+
+// PubSubInputConfig is the configuration of a Pub/Sub input operator.
+type PubSubInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	ProjectID       string `mapstructure:"project_id,omitempty"       json:"project_id,omitempty"       yaml:"project_id,omitempty"`
+	Subscription    string `mapstructure:"subscription,omitempty"     json:"subscription,omitempty"     yaml:"subscription,omitempty"`
+	CredentialsFile string `mapstructure:"credentials_file,omitempty" json:"credentials_file,omitempty" yaml:"credentials_file,omitempty"`
+	Endpoint        string `mapstructure:"endpoint,omitempty"         json:"endpoint,omitempty"         yaml:"endpoint,omitempty"`
+	MaxMessages     int    `mapstructure:"max_messages,omitempty"     json:"max_messages,omitempty"     yaml:"max_messages,omitempty"`
+	JSONDecode      bool   `mapstructure:"json_decode,omitempty"      json:"json_decode,omitempty"      yaml:"json_decode,omitempty"`
+}
+
+// Build will build a Pub/Sub input operator.
+func (c PubSubInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Subscription == "" {
+		return nil, fmt.Errorf("missing required parameter 'subscription'")
+	}
+
+	// The subscription is either its full name, or its ID in the project
+	subscription := c.Subscription
+	if !subscriptionPattern.MatchString(subscription) {
+		if strings.Contains(subscription, "/") {
+			return nil, fmt.Errorf("invalid subscription '%s'", subscription)
+		}
+		if c.ProjectID == "" {
+			return nil, fmt.Errorf("missing required parameter 'project_id'")
+		}
+		subscription = fmt.Sprintf("projects/%s/subscriptions/%s", c.ProjectID, subscription)
+	}
+
+	if !strings.HasPrefix(c.Endpoint, "https://") && !strings.HasPrefix(c.Endpoint, "http://") {
+		return nil, fmt.Errorf("invalid endpoint '%s'", c.Endpoint)
+	}
+
+	if c.MaxMessages <= 0 {
+		return nil, fmt.Errorf("`max_messages` must be positive")
+	}
+
+	pubSubInput := &PubSubInput{
+		InputOperator:   inputOperator,
+		subscription:    subscription,
+		credentialsFile: c.CredentialsFile,
+		endpoint:        c.Endpoint,
+		maxMessages:     c.MaxMessages,
+		jsonDecode:      c.JSONDecode,
+		backoff: backoff.Backoff{
+			Max: 30 * time.Second,
+		},
+	}
+
+	return []operator.Operator{pubSubInput}, nil
+}
+
+// PubSubInput is an operator that pulls messages from a Pub/Sub subscription.
+type PubSubInput struct {
+	helper.InputOperator
+	subscription    string
+	credentialsFile string
+	endpoint        string
+	maxMessages     int
+	jsonDecode      bool
+
+	client  *subscriptionClient
+	backoff backoff.Backoff
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// Start will start pulling messages from the subscription.
+func (p *PubSubInput) Start(_ operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	httpClient, err := p.newHTTPClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find credentials: %s", err)
+	}
+	p.client = newSubscriptionClient(httpClient, p.endpoint, p.subscription)
+
+	p.goPull(ctx)
+	return nil
+}
+
+// newHTTPClient creates a client which authenticates requests with the credentials
+// file, or the application default credentials. Requests to an endpoint over
+// plain HTTP, such as that of the Pub/Sub emulator, are not authenticated.
+func (p *PubSubInput) newHTTPClient(ctx context.Context) (*http.Client, error) {
+	if strings.HasPrefix(p.endpoint, "http://") {
+		return &http.Client{}, nil
+	}
+
+	var creds *google.Credentials
+	if p.credentialsFile != "" {
+		data, err := ioutil.ReadFile(p.credentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		creds, err = google.CredentialsFromJSON(ctx, data, pubsubScope)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		creds, err = google.FindDefaultCredentials(ctx, pubsubScope)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return oauth2.NewClient(ctx, creds.TokenSource), nil
+}
+
+// Stop will stop pulling messages from the subscription.
+func (p *PubSubInput) Stop() error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+	return nil
+}
+
+// goPull will pull messages from the subscription until the operator is stopped.
+func (p *PubSubInput) goPull(ctx context.Context) {
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+
+		for {
+			messages, err := p.client.pull(ctx, p.maxMessages)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				p.Errorw("Failed to pull messages", zap.Error(err))
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(p.backoff.Duration()):
+				}
+				continue
+			}
+			p.backoff.Reset()
+
+			p.handleMessages(ctx, messages)
+		}
+	}()
+}
+
+// handleMessages writes an entry for each message, and acknowledges
+// the messages once their entries have been written
+func (p *PubSubInput) handleMessages(ctx context.Context, messages []receivedMessage) {
+	ackIDs := make([]string, 0, len(messages))
+	for _, message := range messages {
+		entry, err := p.NewEntry(p.body(message.Message.Data))
+		if err != nil {
+			p.Errorw("Failed to create entry", zap.String("message_id", message.Message.MessageID), zap.Error(err))
+			continue
+		}
+		if !message.Message.PublishTime.IsZero() {
+			entry.Timestamp = message.Message.PublishTime
+		}
+		for key, value := range message.Message.Attributes {
+			entry.AddAttribute(key, value)
+		}
+		p.Write(ctx, entry)
+		ackIDs = append(ackIDs, message.AckID)
+	}
+
+	if len(ackIDs) == 0 || ctx.Err() != nil {
+		return
+	}
+	if err := p.client.acknowledge(ctx, ackIDs); err != nil && ctx.Err() == nil {
+		p.Errorw("Failed to acknowledge messages", zap.Error(err))
+	}
+}
+
+// body returns the body of a message's entry. With JSON decoding, data
+// which is a JSON object is decoded, and other data is kept as a string.
+func (p *PubSubInput) body(data []byte) interface{} {
+	if p.jsonDecode {
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(data, &decoded); err == nil {
+			return decoded
+		}
+	}
+	return string(data)
+}
@@ -0,0 +1,282 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+const testSubscription = "projects/test-project/subscriptions/logs"
+
+// fakePubSub serves the pull and acknowledge methods of a subscription
+type fakePubSub struct {
+	sync.Mutex
+	messages []map[string]interface{}
+	acked    []string
+	pullErr  bool
+}
+
+func (f *fakePubSub) publish(ackID, data string, attributes map[string]string) {
+	f.Lock()
+	defer f.Unlock()
+	f.messages = append(f.messages, map[string]interface{}{
+		"ackId": ackID,
+		"message": map[string]interface{}{
+			"data":        []byte(data),
+			"attributes":  attributes,
+			"messageId":   "id-" + ackID,
+			"publishTime": "2021-06-01T12:00:00.123Z",
+		},
+	})
+}
+
+func (f *fakePubSub) ackedIDs() []string {
+	f.Lock()
+	defer f.Unlock()
+	return append([]string{}, f.acked...)
+}
+
+func (f *fakePubSub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	switch r.URL.Path {
+	case "/v1/" + testSubscription + ":pull":
+		if f.pullErr {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error":{"code":403,"message":"permission denied","status":"PERMISSION_DENIED"}}`)
+			return
+		}
+		var request struct {
+			MaxMessages int `json:"maxMessages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		n := request.MaxMessages
+		if n > len(f.messages) {
+			n = len(f.messages)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"receivedMessages": f.messages[:n]})
+		f.messages = f.messages[n:]
+		if n == 0 {
+			// the real API waits for messages before responding
+			time.Sleep(10 * time.Millisecond)
+		}
+	case "/v1/" + testSubscription + ":acknowledge":
+		var request struct {
+			AckIDs []string `json:"ackIds"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		f.acked = append(f.acked, request.AckIDs...)
+		fmt.Fprint(w, `{}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestPubSubInput(t *testing.T, cfgMod func(*PubSubInputConfig)) (*fakePubSub, *testutil.FakeOutput) {
+	fake := &fakePubSub{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	cfg := NewPubSubInputConfig("test_id")
+	cfg.ProjectID = "test-project"
+	cfg.Subscription = "logs"
+	cfg.Endpoint = server.URL
+	cfg.OutputIDs = []string{"fake"}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	pubSubInput := ops[0].(*PubSubInput)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, pubSubInput.SetOutputs([]operator.Operator{fakeOutput}))
+	require.NoError(t, pubSubInput.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() { require.NoError(t, pubSubInput.Stop()) })
+	return fake, fakeOutput
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name         string
+		modify       func(*PubSubInputConfig)
+		subscription string
+		expectErr    bool
+	}{
+		{
+			"SubscriptionID",
+			func(cfg *PubSubInputConfig) {},
+			testSubscription,
+			false,
+		},
+		{
+			"SubscriptionName",
+			func(cfg *PubSubInputConfig) {
+				cfg.ProjectID = ""
+				cfg.Subscription = "projects/other-project/subscriptions/audit"
+			},
+			"projects/other-project/subscriptions/audit",
+			false,
+		},
+		{
+			"MissingSubscription",
+			func(cfg *PubSubInputConfig) {
+				cfg.Subscription = ""
+			},
+			"",
+			true,
+		},
+		{
+			"MissingProjectID",
+			func(cfg *PubSubInputConfig) {
+				cfg.ProjectID = ""
+			},
+			"",
+			true,
+		},
+		{
+			"InvalidSubscription",
+			func(cfg *PubSubInputConfig) {
+				cfg.Subscription = "subscriptions/logs"
+			},
+			"",
+			true,
+		},
+		{
+			"InvalidEndpoint",
+			func(cfg *PubSubInputConfig) {
+				cfg.Endpoint = "pubsub.googleapis.com"
+			},
+			"",
+			true,
+		},
+		{
+			"ZeroMaxMessages",
+			func(cfg *PubSubInputConfig) {
+				cfg.MaxMessages = 0
+			},
+			"",
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewPubSubInputConfig("test_id")
+			cfg.ProjectID = "test-project"
+			cfg.Subscription = "logs"
+			tc.modify(cfg)
+
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.subscription, ops[0].(*PubSubInput).subscription)
+		})
+	}
+}
+
+func TestPubSubInput(t *testing.T) {
+	fake, fakeOutput := newTestPubSubInput(t, nil)
+	fake.publish("1", `{"severity":"ERROR"}`, map[string]string{"logging.googleapis.com/timestamp": "2021-06-01T11:59:59Z"})
+	fake.publish("2", "plain text", nil)
+
+	select {
+	case e := <-fakeOutput.Received:
+		require.Equal(t, `{"severity":"ERROR"}`, e.Body)
+		require.Equal(t, map[string]string{"logging.googleapis.com/timestamp": "2021-06-01T11:59:59Z"}, e.Attributes)
+		require.Equal(t, time.Date(2021, 6, 1, 12, 0, 0, 123000000, time.UTC), e.Timestamp.UTC())
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+	fakeOutput.ExpectBody(t, "plain text")
+
+	require.Eventually(t, func() bool {
+		return len(fake.ackedIDs()) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"1", "2"}, fake.ackedIDs())
+}
+
+func TestPubSubInputJSONDecode(t *testing.T) {
+	fake, fakeOutput := newTestPubSubInput(t, func(cfg *PubSubInputConfig) {
+		cfg.JSONDecode = true
+	})
+	fake.publish("1", `{"severity":"ERROR","textPayload":"failed"}`, nil)
+	fake.publish("2", "not json", nil)
+
+	fakeOutput.ExpectBody(t, map[string]interface{}{"severity": "ERROR", "textPayload": "failed"})
+	fakeOutput.ExpectBody(t, "not json")
+}
+
+func TestPubSubInputPullError(t *testing.T) {
+	fake, fakeOutput := newTestPubSubInput(t, nil)
+	fake.Lock()
+	fake.pullErr = true
+	fake.Unlock()
+
+	// pulling is retried after an error
+	time.Sleep(50 * time.Millisecond)
+	fake.Lock()
+	fake.pullErr = false
+	fake.Unlock()
+	fake.publish("1", "after error", nil)
+
+	select {
+	case e := <-fakeOutput.Received:
+		require.Equal(t, "after error", e.Body)
+	case <-time.After(3 * time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
+
+func TestPubSubInputInvalidCredentialsFile(t *testing.T) {
+	credentialsFile := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, ioutil.WriteFile(credentialsFile, []byte("not json"), 0600))
+
+	cfg := NewPubSubInputConfig("test_id")
+	cfg.ProjectID = "test-project"
+	cfg.Subscription = "logs"
+	cfg.CredentialsFile = credentialsFile
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	require.Error(t, ops[0].Start(testutil.NewMockPersister("test")))
+	require.NoError(t, ops[0].Stop())
+}
+
+func TestAPIError(t *testing.T) {
+	err := apiError(http.StatusNotFound, []byte(`{"error":{"code":404,"message":"Resource not found","status":"NOT_FOUND"}}`))
+	require.EqualError(t, err, "request failed with status 404 (NOT_FOUND): Resource not found")
+
+	err = apiError(http.StatusBadGateway, []byte(`<html></html>`))
+	require.EqualError(t, err, "request failed with status 502")
+}
@@ -0,0 +1,438 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redisstreams
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jpillora/backoff"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	startAtBeginning = "beginning"
+	startAtEnd       = "end"
+)
+
+func init() {
+	operator.Register("redis_streams_input", func() operator.Builder { return NewRedisStreamsInputConfig("") })
+}
+
+// NewRedisStreamsInputConfig creates a new Redis Streams input config with default values
+func NewRedisStreamsInputConfig(operatorID string) *RedisStreamsInputConfig {
+	return &RedisStreamsInputConfig{
+		InputConfig:      helper.NewInputConfig(operatorID, "redis_streams_input"),
+		Address:          "localhost:6379",
+		Group:            "opentelemetry-log-collection",
+		StartAt:          startAtEnd,
+		BatchSize:        100,
+		BlockTimeout:     helper.NewDuration(5 * time.Second),
+		ClaimMinIdleTime: helper.NewDuration(time.Minute),
+	}
+}
+
+// RedisStreamsInputConfig is the configuration of a Redis Streams input operator.
+type RedisStreamsInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	Address          string                  `mapstructure:"address,omitempty"             json:"address,omitempty"             yaml:"address,omitempty"`
+	Username         string                  `mapstructure:"username,omitempty"            json:"username,omitempty"            yaml:"username,omitempty"`
+	Password         string                  `mapstructure:"password,omitempty"            json:"password,omitempty"            yaml:"password,omitempty"`
+	DB               int                     `mapstructure:"db,omitempty"                  json:"db,omitempty"                  yaml:"db,omitempty"`
+	TLS              *helper.TLSClientConfig `mapstructure:"tls,omitempty"                 json:"tls,omitempty"                 yaml:"tls,omitempty"`
+	Streams          []string                `mapstructure:"streams,omitempty"             json:"streams,omitempty"             yaml:"streams,omitempty"`
+	Group            string                  `mapstructure:"group,omitempty"               json:"group,omitempty"               yaml:"group,omitempty"`
+	Consumer         string                  `mapstructure:"consumer,omitempty"            json:"consumer,omitempty"            yaml:"consumer,omitempty"`
+	StartAt          string                  `mapstructure:"start_at,omitempty"            json:"start_at,omitempty"            yaml:"start_at,omitempty"`
+	BatchSize        int                     `mapstructure:"batch_size,omitempty"          json:"batch_size,omitempty"          yaml:"batch_size,omitempty"`
+	BlockTimeout     helper.Duration         `mapstructure:"block_timeout,omitempty"       json:"block_timeout,omitempty"       yaml:"block_timeout,omitempty"`
+	ClaimMinIdleTime helper.Duration         `mapstructure:"claim_min_idle_time,omitempty" json:"claim_min_idle_time,omitempty" yaml:"claim_min_idle_time,omitempty"`
+}
+
+// Build will build a Redis Streams input operator.
+func (c RedisStreamsInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Address == "" {
+		return nil, fmt.Errorf("missing required parameter 'address'")
+	}
+
+	if len(c.Streams) == 0 {
+		return nil, fmt.Errorf("missing required parameter 'streams'")
+	}
+
+	if c.Group == "" {
+		return nil, fmt.Errorf("missing required parameter 'group'")
+	}
+
+	// Each collector must be a distinct consumer, so the consumer defaults to the hostname
+	consumer := c.Consumer
+	if consumer == "" {
+		consumer, err = os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname for default consumer: %s", err)
+		}
+	}
+
+	var groupStart string
+	switch c.StartAt {
+	case startAtBeginning:
+		groupStart = "0"
+	case startAtEnd:
+		groupStart = "$"
+	default:
+		return nil, fmt.Errorf("invalid start_at location '%s'", c.StartAt)
+	}
+
+	if c.BatchSize <= 0 {
+		return nil, fmt.Errorf("`batch_size` must be positive")
+	}
+
+	if c.BlockTimeout.Raw() <= 0 {
+		return nil, fmt.Errorf("`block_timeout` must be positive")
+	}
+
+	if c.ClaimMinIdleTime.Raw() <= 0 {
+		return nil, fmt.Errorf("`claim_min_idle_time` must be positive")
+	}
+
+	options := &redis.Options{
+		Addr:     c.Address,
+		Username: c.Username,
+		Password: c.Password,
+		DB:       c.DB,
+	}
+	if c.TLS != nil {
+		options.TLSConfig, err = c.TLS.LoadTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	redisStreamsInput := &RedisStreamsInput{
+		InputOperator:    inputOperator,
+		options:          options,
+		streams:          c.Streams,
+		group:            c.Group,
+		consumer:         consumer,
+		groupStart:       groupStart,
+		batchSize:        int64(c.BatchSize),
+		blockTimeout:     c.BlockTimeout.Raw(),
+		claimMinIdleTime: c.ClaimMinIdleTime.Raw(),
+		backoff: backoff.Backoff{
+			Max: 30 * time.Second,
+		},
+	}
+
+	return []operator.Operator{redisStreamsInput}, nil
+}
+
+// RedisStreamsInput is an operator that reads entries from Redis Streams with a consumer group.
+type RedisStreamsInput struct {
+	helper.InputOperator
+	options          *redis.Options
+	streams          []string
+	group            string
+	consumer         string
+	groupStart       string
+	batchSize        int64
+	blockTimeout     time.Duration
+	claimMinIdleTime time.Duration
+
+	client  *redis.Client
+	backoff backoff.Backoff
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// Start will start reading from the streams.
+func (r *RedisStreamsInput) Start(_ operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.client = redis.NewClient(r.options)
+	r.goConsume(ctx)
+	return nil
+}
+
+// Stop will stop reading from the streams. Entries which were read but not
+// acknowledged remain pending, and are read again when the operator restarts.
+func (r *RedisStreamsInput) Stop() error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	if r.client == nil {
+		return nil
+	}
+
+	// Closing the client interrupts a blocked read
+	err := r.client.Close()
+	r.wg.Wait()
+	return err
+}
+
+// goConsume will read from the streams until the operator is stopped.
+// After connecting, entries left pending by this consumer and those claimed from
+// idle consumers are read before new entries.
+func (r *RedisStreamsInput) goConsume(ctx context.Context) {
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+
+		for {
+			err := r.consume(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+
+			r.Errorw("Failed to read from streams", zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(r.backoff.Duration()):
+			}
+		}
+	}()
+}
+
+// consume will prepare the consumer group and read from the streams, until an error occurs.
+func (r *RedisStreamsInput) consume(ctx context.Context) error {
+	for _, stream := range r.streams {
+		if err := r.createGroup(ctx, stream); err != nil {
+			return err
+		}
+	}
+
+	for _, stream := range r.streams {
+		if err := r.readPending(ctx, stream); err != nil {
+			return fmt.Errorf("read pending entries of %s: %s", stream, err)
+		}
+		if err := r.claimPending(ctx, stream); err != nil {
+			return fmt.Errorf("claim pending entries of %s: %s", stream, err)
+		}
+	}
+
+	for {
+		streams, err := r.readNew(ctx)
+		if err != nil {
+			return err
+		}
+		r.backoff.Reset()
+		r.handleStreams(ctx, streams)
+	}
+}
+
+// createGroup will create the consumer group of a stream, and the stream, if they do not exist.
+func (r *RedisStreamsInput) createGroup(ctx context.Context, stream string) error {
+	err := r.client.XGroupCreateMkStream(ctx, stream, r.group, r.groupStart).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("create group %s of %s: %s", r.group, stream, err)
+	}
+	return nil
+}
+
+// readPending will read the entries of a stream which were delivered to this
+// consumer, but not acknowledged.
+func (r *RedisStreamsInput) readPending(ctx context.Context, stream string) error {
+	id := "0"
+	for {
+		streams, err := r.read(ctx, stream, id, -1)
+		if err != nil {
+			return err
+		}
+		if len(streams) == 0 || len(streams[0].Messages) == 0 {
+			return nil
+		}
+
+		r.handleStreams(ctx, streams)
+		if ctx.Err() != nil {
+			return nil
+		}
+		id = streams[0].Messages[len(streams[0].Messages)-1].ID
+	}
+}
+
+// claimPending will claim the pending entries of a stream which have been idle for
+// at least the claim min idle time, such as those of a consumer which was removed.
+func (r *RedisStreamsInput) claimPending(ctx context.Context, stream string) error {
+	start := "-"
+	for {
+		pending, err := r.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: stream,
+			Group:  r.group,
+			Start:  start,
+			End:    "+",
+			Count:  r.batchSize,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		var ids []string
+		for _, p := range pending {
+			if p.Consumer != r.consumer && p.Idle >= r.claimMinIdleTime {
+				ids = append(ids, p.ID)
+			}
+		}
+
+		if len(ids) > 0 {
+			messages, err := r.client.XClaim(ctx, &redis.XClaimArgs{
+				Stream:   stream,
+				Group:    r.group,
+				Consumer: r.consumer,
+				MinIdle:  r.claimMinIdleTime,
+				Messages: ids,
+			}).Result()
+			if err != nil {
+				return err
+			}
+			r.Debugw("Claimed pending entries", zap.String("stream", stream), zap.Int("count", len(messages)))
+			r.handleStreams(ctx, []redis.XStream{{Stream: stream, Messages: messages}})
+			if ctx.Err() != nil {
+				return nil
+			}
+		}
+
+		start, err = nextID(pending[len(pending)-1].ID)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readNew will read the entries of the streams which were not yet delivered to
+// a consumer of the group, blocking for up to the block timeout.
+func (r *RedisStreamsInput) readNew(ctx context.Context) ([]redis.XStream, error) {
+	args := make([]string, 0, 2*len(r.streams))
+	args = append(args, r.streams...)
+	for range r.streams {
+		args = append(args, ">")
+	}
+	return r.readGroup(ctx, args, r.blockTimeout)
+}
+
+// read will read the entries of a stream after the id. A negative block does not block.
+func (r *RedisStreamsInput) read(ctx context.Context, stream, id string, block time.Duration) ([]redis.XStream, error) {
+	return r.readGroup(ctx, []string{stream, id}, block)
+}
+
+// readGroup will read the entries of the streams in args, which are followed by their ids.
+func (r *RedisStreamsInput) readGroup(ctx context.Context, args []string, block time.Duration) ([]redis.XStream, error) {
+	streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    r.group,
+		Consumer: r.consumer,
+		Streams:  args,
+		Count:    r.batchSize,
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return streams, err
+}
+
+// handleStreams writes an entry for each message of the streams, and acknowledges
+// the messages once their entries have been written.
+func (r *RedisStreamsInput) handleStreams(ctx context.Context, streams []redis.XStream) {
+	for _, stream := range streams {
+		ids := make([]string, 0, len(stream.Messages))
+		for _, message := range stream.Messages {
+			// Messages which were deleted while pending are returned without values
+			if message.Values != nil {
+				r.handleMessage(ctx, stream.Stream, message)
+			}
+			ids = append(ids, message.ID)
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		if err := r.client.XAck(ctx, stream.Stream, r.group, ids...).Err(); err != nil && ctx.Err() == nil {
+			r.Errorw("Failed to acknowledge messages", zap.String("stream", stream.Stream), zap.Error(err))
+		}
+	}
+}
+
+// handleMessage creates an entry from the fields of a message.
+func (r *RedisStreamsInput) handleMessage(ctx context.Context, stream string, message redis.XMessage) {
+	entry, err := r.NewEntry(message.Values)
+	if err != nil {
+		r.Errorw("Failed to create entry", zap.String("id", message.ID), zap.Error(err))
+		return
+	}
+
+	if timestamp, err := idTime(message.ID); err == nil {
+		entry.Timestamp = timestamp
+	}
+	entry.AddAttribute("redis.stream", stream)
+	entry.AddAttribute("redis.message_id", message.ID)
+
+	r.Write(ctx, entry)
+}
+
+// idTime returns the time an entry was added to a stream, from the milliseconds part of its id.
+func idTime(id string) (time.Time, error) {
+	ms, err := strconv.ParseInt(strings.SplitN(id, "-", 2)[0], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid id '%s'", id)
+	}
+	return time.Unix(0, ms*int64(time.Millisecond)), nil
+}
+
+// nextID returns the smallest id which is greater than the id.
+func nextID(id string) (string, error) {
+	parts := strings.SplitN(id, "-", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid id '%s'", id)
+	}
+
+	ms, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid id '%s'", id)
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid id '%s'", id)
+	}
+
+	if seq == ^uint64(0) {
+		return strconv.FormatUint(ms+1, 10) + "-0", nil
+	}
+	return strconv.FormatUint(ms, 10) + "-" + strconv.FormatUint(seq+1, 10), nil
+}
@@ -0,0 +1,406 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redisstreams
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtls"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestRedisStreamsInput(t *testing.T, server *miniredis.Miniredis, cfgMod func(*RedisStreamsInputConfig)) *testutil.FakeOutput {
+	cfg := NewRedisStreamsInputConfig("test_id")
+	cfg.Address = server.Addr()
+	cfg.Streams = []string{"logs"}
+	cfg.Consumer = "collector"
+	cfg.StartAt = startAtBeginning
+	cfg.BlockTimeout = helper.NewDuration(10 * time.Millisecond)
+	cfg.OutputIDs = []string{"fake"}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	redisStreamsInput := ops[0].(*RedisStreamsInput)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, redisStreamsInput.SetOutputs([]operator.Operator{fakeOutput}))
+	require.NoError(t, redisStreamsInput.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() { require.NoError(t, redisStreamsInput.Stop()) })
+	return fakeOutput
+}
+
+func newTestClient(t *testing.T, server *miniredis.Miniredis) *redis.Client {
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func pendingCount(t *testing.T, client *redis.Client, stream string) int64 {
+	pending, err := client.XPending(context.Background(), stream, "opentelemetry-log-collection").Result()
+	require.NoError(t, err)
+	return pending.Count
+}
+
+func expectEntry(t *testing.T, fakeOutput *testutil.FakeOutput) *entry.Entry {
+	select {
+	case e := <-fakeOutput.Received:
+		return e
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+		return nil
+	}
+}
+
+func expectNoEntry(t *testing.T, fakeOutput *testutil.FakeOutput) {
+	select {
+	case e := <-fakeOutput.Received:
+		require.FailNow(t, "Received unexpected entry", "%v", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*RedisStreamsInputConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *RedisStreamsInputConfig) {},
+			false,
+		},
+		{
+			"MissingAddress",
+			func(cfg *RedisStreamsInputConfig) {
+				cfg.Address = ""
+			},
+			true,
+		},
+		{
+			"MissingStreams",
+			func(cfg *RedisStreamsInputConfig) {
+				cfg.Streams = nil
+			},
+			true,
+		},
+		{
+			"MissingGroup",
+			func(cfg *RedisStreamsInputConfig) {
+				cfg.Group = ""
+			},
+			true,
+		},
+		{
+			"StartAtBeginning",
+			func(cfg *RedisStreamsInputConfig) {
+				cfg.StartAt = startAtBeginning
+			},
+			false,
+		},
+		{
+			"InvalidStartAt",
+			func(cfg *RedisStreamsInputConfig) {
+				cfg.StartAt = "middle"
+			},
+			true,
+		},
+		{
+			"InvalidBatchSize",
+			func(cfg *RedisStreamsInputConfig) {
+				cfg.BatchSize = 0
+			},
+			true,
+		},
+		{
+			"InvalidBlockTimeout",
+			func(cfg *RedisStreamsInputConfig) {
+				cfg.BlockTimeout = helper.NewDuration(0)
+			},
+			true,
+		},
+		{
+			"InvalidClaimMinIdleTime",
+			func(cfg *RedisStreamsInputConfig) {
+				cfg.ClaimMinIdleTime = helper.NewDuration(-time.Second)
+			},
+			true,
+		},
+		{
+			"TLS",
+			func(cfg *RedisStreamsInputConfig) {
+				cfg.TLS = helper.NewTLSClientConfig(&configtls.TLSClientSetting{})
+			},
+			false,
+		},
+		{
+			"InvalidTLS",
+			func(cfg *RedisStreamsInputConfig) {
+				cfg.TLS = helper.NewTLSClientConfig(&configtls.TLSClientSetting{
+					TLSSetting: configtls.TLSSetting{CAFile: "/does/not/exist"},
+				})
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRedisStreamsInputConfig("test_id")
+			cfg.Streams = []string{"logs"}
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestBuildDefaultConsumer(t *testing.T) {
+	cfg := NewRedisStreamsInputConfig("test_id")
+	cfg.Streams = []string{"logs"}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	require.NotEmpty(t, ops[0].(*RedisStreamsInput).consumer)
+}
+
+func TestRedisStreamsInput(t *testing.T) {
+	server := miniredis.RunT(t)
+	id, err := server.XAdd("logs", "1622548800123-0", []string{"message", "hello", "level", "info"})
+	require.NoError(t, err)
+
+	fakeOutput := newTestRedisStreamsInput(t, server, nil)
+
+	e := expectEntry(t, fakeOutput)
+	require.Equal(t, map[string]interface{}{"message": "hello", "level": "info"}, e.Body)
+	require.Equal(t, map[string]string{
+		"redis.stream":     "logs",
+		"redis.message_id": id,
+	}, e.Attributes)
+	require.Equal(t, time.Unix(1622548800, 123000000), e.Timestamp)
+
+	client := newTestClient(t, server)
+	require.Eventually(t, func() bool {
+		return pendingCount(t, client, "logs") == 0
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = server.XAdd("logs", "*", []string{"message", "world"})
+	require.NoError(t, err)
+	e = expectEntry(t, fakeOutput)
+	require.Equal(t, map[string]interface{}{"message": "world"}, e.Body)
+}
+
+func TestRedisStreamsInputMultipleStreams(t *testing.T) {
+	server := miniredis.RunT(t)
+	_, err := server.XAdd("app", "*", []string{"message", "from app"})
+	require.NoError(t, err)
+	_, err = server.XAdd("audit", "*", []string{"message", "from audit"})
+	require.NoError(t, err)
+
+	fakeOutput := newTestRedisStreamsInput(t, server, func(cfg *RedisStreamsInputConfig) {
+		cfg.Streams = []string{"app", "audit"}
+	})
+
+	received := map[string]interface{}{}
+	for i := 0; i < 2; i++ {
+		e := expectEntry(t, fakeOutput)
+		received[e.Attributes["redis.stream"]] = e.Body.(map[string]interface{})["message"]
+	}
+	require.Equal(t, map[string]interface{}{"app": "from app", "audit": "from audit"}, received)
+}
+
+func TestRedisStreamsInputStartAtEnd(t *testing.T) {
+	server := miniredis.RunT(t)
+	_, err := server.XAdd("logs", "*", []string{"message", "old"})
+	require.NoError(t, err)
+
+	fakeOutput := newTestRedisStreamsInput(t, server, func(cfg *RedisStreamsInputConfig) {
+		cfg.StartAt = startAtEnd
+	})
+
+	client := newTestClient(t, server)
+	require.Eventually(t, func() bool {
+		groups, err := client.XInfoGroups(context.Background(), "logs").Result()
+		return err == nil && len(groups) == 1
+	}, time.Second, 10*time.Millisecond)
+	expectNoEntry(t, fakeOutput)
+
+	_, err = server.XAdd("logs", "*", []string{"message", "new"})
+	require.NoError(t, err)
+	e := expectEntry(t, fakeOutput)
+	require.Equal(t, map[string]interface{}{"message": "new"}, e.Body)
+}
+
+func TestRedisStreamsInputExistingGroup(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := newTestClient(t, server)
+	_, err := server.XAdd("logs", "*", []string{"message", "read"})
+	require.NoError(t, err)
+	require.NoError(t, client.XGroupCreate(context.Background(), "logs", "opentelemetry-log-collection", "$").Err())
+	_, err = server.XAdd("logs", "*", []string{"message", "unread"})
+	require.NoError(t, err)
+
+	fakeOutput := newTestRedisStreamsInput(t, server, nil)
+
+	e := expectEntry(t, fakeOutput)
+	require.Equal(t, map[string]interface{}{"message": "unread"}, e.Body)
+	expectNoEntry(t, fakeOutput)
+}
+
+func TestRedisStreamsInputPendingEntries(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := newTestClient(t, server)
+	require.NoError(t, client.XGroupCreateMkStream(context.Background(), "logs", "opentelemetry-log-collection", "0").Err())
+	_, err := server.XAdd("logs", "*", []string{"message", "pending"})
+	require.NoError(t, err)
+
+	// Deliver the entry to the consumer without acknowledging it, as if the collector stopped before writing it
+	_, err = client.XReadGroup(context.Background(), &redis.XReadGroupArgs{
+		Group:    "opentelemetry-log-collection",
+		Consumer: "collector",
+		Streams:  []string{"logs", ">"},
+		Block:    -1,
+	}).Result()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), pendingCount(t, client, "logs"))
+
+	fakeOutput := newTestRedisStreamsInput(t, server, nil)
+
+	e := expectEntry(t, fakeOutput)
+	require.Equal(t, map[string]interface{}{"message": "pending"}, e.Body)
+	require.Eventually(t, func() bool {
+		return pendingCount(t, client, "logs") == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRedisStreamsInputClaimEntries(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := newTestClient(t, server)
+	now := time.Now()
+	server.SetTime(now)
+	require.NoError(t, client.XGroupCreateMkStream(context.Background(), "logs", "opentelemetry-log-collection", "0").Err())
+	_, err := server.XAdd("logs", "*", []string{"message", "idle"})
+	require.NoError(t, err)
+	_, err = client.XReadGroup(context.Background(), &redis.XReadGroupArgs{
+		Group:    "opentelemetry-log-collection",
+		Consumer: "removed",
+		Streams:  []string{"logs", ">"},
+		Block:    -1,
+	}).Result()
+	require.NoError(t, err)
+
+	server.SetTime(now.Add(30 * time.Second))
+	_, err = server.XAdd("logs", "*", []string{"message", "recent"})
+	require.NoError(t, err)
+	_, err = client.XReadGroup(context.Background(), &redis.XReadGroupArgs{
+		Group:    "opentelemetry-log-collection",
+		Consumer: "busy",
+		Streams:  []string{"logs", ">"},
+		Block:    -1,
+	}).Result()
+	require.NoError(t, err)
+
+	server.SetTime(now.Add(time.Minute))
+	fakeOutput := newTestRedisStreamsInput(t, server, nil)
+
+	// Only the entry which has been idle for the claim min idle time is claimed
+	e := expectEntry(t, fakeOutput)
+	require.Equal(t, map[string]interface{}{"message": "idle"}, e.Body)
+	expectNoEntry(t, fakeOutput)
+
+	pending, err := client.XPendingExt(context.Background(), &redis.XPendingExtArgs{
+		Stream: "logs",
+		Group:  "opentelemetry-log-collection",
+		Start:  "-",
+		End:    "+",
+		Count:  10,
+	}).Result()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, "busy", pending[0].Consumer)
+}
+
+func TestRedisStreamsInputAuth(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireUserAuth("collector", "secret")
+	_, err := server.XAdd("logs", "*", []string{"message", "hello"})
+	require.NoError(t, err)
+
+	fakeOutput := newTestRedisStreamsInput(t, server, func(cfg *RedisStreamsInputConfig) {
+		cfg.Username = "collector"
+		cfg.Password = "secret"
+	})
+
+	e := expectEntry(t, fakeOutput)
+	require.Equal(t, map[string]interface{}{"message": "hello"}, e.Body)
+}
+
+func TestRedisStreamsInputReconnect(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireAuth("secret")
+
+	fakeOutput := newTestRedisStreamsInput(t, server, nil)
+	expectNoEntry(t, fakeOutput)
+
+	// The operator retries until it is able to connect
+	server.RequireAuth("")
+	_, err := server.XAdd("logs", "*", []string{"message", "hello"})
+	require.NoError(t, err)
+
+	select {
+	case e := <-fakeOutput.Received:
+		require.Equal(t, map[string]interface{}{"message": "hello"}, e.Body)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
+
+func TestNextID(t *testing.T) {
+	cases := []struct {
+		id       string
+		expected string
+	}{
+		{"1622548800123-0", "1622548800123-1"},
+		{"1622548800123-18446744073709551615", "1622548800124-0"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.id, func(t *testing.T) {
+			next, err := nextID(tc.id)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, next)
+		})
+	}
+
+	_, err := nextID("1622548800123")
+	require.Error(t, err)
+}
@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// object is an S3 object referenced by an event notification
+type object struct {
+	bucket string
+	key    string
+}
+
+// s3Notification is the subset of an S3 event notification used by the operator
+type s3Notification struct {
+	Event   string `json:"Event"`
+	Records []struct {
+		EventSource string `json:"eventSource"`
+		EventName   string `json:"eventName"`
+		S3          struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Key string `json:"key"`
+			} `json:"object"`
+		} `json:"s3"`
+	} `json:"Records"`
+}
+
+// snsNotification is an SNS notification, which wraps the S3 event
+// notification when S3 notifies an SNS topic which is subscribed to by the queue
+type snsNotification struct {
+	Type    string `json:"Type"`
+	Message string `json:"Message"`
+}
+
+// parseNotification returns the created objects referenced by the body of an
+// SQS message. Test events, and events of other kinds, reference no objects.
+func parseNotification(body string) ([]object, error) {
+	var sns snsNotification
+	if err := json.Unmarshal([]byte(body), &sns); err != nil {
+		return nil, fmt.Errorf("parse notification: %s", err)
+	}
+	if sns.Type == "Notification" {
+		body = sns.Message
+	}
+
+	var notification s3Notification
+	if err := json.Unmarshal([]byte(body), &notification); err != nil {
+		return nil, fmt.Errorf("parse s3 notification: %s", err)
+	}
+
+	objects := make([]object, 0, len(notification.Records))
+	for _, record := range notification.Records {
+		if record.EventSource != "aws:s3" || !strings.HasPrefix(record.EventName, "ObjectCreated:") {
+			continue
+		}
+
+		// Keys are URL encoded, with spaces encoded as plus signs
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid object key '%s': %s", record.S3.Object.Key, err)
+		}
+		objects = append(objects, object{bucket: record.S3.Bucket.Name, key: key})
+	}
+	return objects, nil
+}
@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const s3Event = `{"Records":[{"eventSource":"aws:s3","eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"logs"},"object":{"key":"AWSLogs/elb/access+log%3D1.gz"}}},{"eventSource":"aws:s3","eventName":"ObjectRemoved:Delete","s3":{"bucket":{"name":"logs"},"object":{"key":"removed.log"}}}]}`
+
+func TestParseNotification(t *testing.T) {
+	snsMessage, err := json.Marshal(map[string]string{
+		"Type":    "Notification",
+		"Message": s3Event,
+	})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name      string
+		body      string
+		expected  []object
+		expectErr bool
+	}{
+		{
+			"S3Event",
+			s3Event,
+			[]object{{bucket: "logs", key: "AWSLogs/elb/access log=1.gz"}},
+			false,
+		},
+		{
+			"SNSNotification",
+			string(snsMessage),
+			[]object{{bucket: "logs", key: "AWSLogs/elb/access log=1.gz"}},
+			false,
+		},
+		{
+			"TestEvent",
+			`{"Service":"Amazon S3","Event":"s3:TestEvent","Bucket":"logs"}`,
+			[]object{},
+			false,
+		},
+		{
+			"InvalidJSON",
+			`not json`,
+			nil,
+			true,
+		},
+		{
+			"InvalidKey",
+			`{"Records":[{"eventSource":"aws:s3","eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"logs"},"object":{"key":"%zz"}}}]}`,
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			objects, err := parseNotification(tc.body)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, objects)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	compressionAuto = "auto"
+	compressionNone = "none"
+	compressionGzip = "gzip"
+
+	bucketAttribute = "aws.s3.bucket"
+	keyAttribute    = "aws.s3.key"
+)
+
+// readObject downloads an object, and emits an entry for each of its lines,
+// or for each of its records when they are read from a JSON field
+func (s *S3Input) readObject(ctx context.Context, obj object) error {
+	output, err := s.s3Client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(obj.bucket),
+		Key:    aws.String(obj.key),
+	})
+	if err != nil {
+		return fmt.Errorf("get object: %w", err)
+	}
+	defer output.Body.Close()
+
+	reader, err := s.decompress(output.Body)
+	if err != nil {
+		return err
+	}
+
+	if s.jsonRecordsField != "" {
+		return s.readRecords(ctx, obj, reader)
+	}
+	return s.readLines(ctx, obj, reader)
+}
+
+// decompress wraps the content of an object with a decompressor. With
+// automatic detection, content which starts with the gzip header is decompressed.
+func (s *S3Input) decompress(body io.Reader) (io.Reader, error) {
+	compressed := s.compression == compressionGzip
+	buffered := bufio.NewReader(body)
+	if s.compression == compressionAuto {
+		header, err := buffered.Peek(2)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("read object: %w", err)
+		}
+		compressed = len(header) == 2 && header[0] == 0x1f && header[1] == 0x8b
+	}
+
+	if !compressed {
+		return buffered, nil
+	}
+	gz, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("open gzip stream: %w", err)
+	}
+	return gz, nil
+}
+
+func (s *S3Input) readLines(ctx context.Context, obj object, reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 16*1024), s.maxLogSize)
+	scanner.Split(s.splitFunc)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		decoded, err := s.encoding.Decode(scanner.Bytes())
+		if err != nil {
+			return fmt.Errorf("decode: %w", err)
+		}
+		if err := s.emit(ctx, obj, decoded); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read object: %w", err)
+	}
+	return nil
+}
+
+// readRecords emits an entry for each element of the array
+// in the JSON field of the object, such as CloudTrail's Records
+func (s *S3Input) readRecords(ctx context.Context, obj object, reader io.Reader) error {
+	var content map[string]json.RawMessage
+	if err := json.NewDecoder(reader).Decode(&content); err != nil {
+		return fmt.Errorf("parse object: %w", err)
+	}
+
+	raw, ok := content[s.jsonRecordsField]
+	if !ok {
+		return fmt.Errorf("object has no field '%s'", s.jsonRecordsField)
+	}
+
+	var records []interface{}
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return fmt.Errorf("field '%s' is not an array: %w", s.jsonRecordsField, err)
+	}
+
+	for _, record := range records {
+		if err := s.emit(ctx, obj, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *S3Input) emit(ctx context.Context, obj object, body interface{}) error {
+	entry, err := s.NewEntry(body)
+	if err != nil {
+		return fmt.Errorf("create entry: %w", err)
+	}
+	entry.AddAttribute(bucketAttribute, obj.bucket)
+	entry.AddAttribute(keyAttribute, obj.key)
+	s.Write(ctx, entry)
+	return nil
+}
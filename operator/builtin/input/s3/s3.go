@@ -0,0 +1,271 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/jpillora/backoff"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	defaultMaxLogSize  = 1024 * 1024
+	maxWaitTime        = 20 * time.Second
+	maxMessagesPerPoll = 10
+)
+
+func init() {
+	operator.Register("s3_input", func() operator.Builder { return NewS3InputConfig("") })
+}
+
+// NewS3InputConfig creates a new S3 input config with default values
+func NewS3InputConfig(operatorID string) *S3InputConfig {
+	return &S3InputConfig{
+		InputConfig: helper.NewInputConfig(operatorID, "s3_input"),
+		MaxMessages: maxMessagesPerPoll,
+		WaitTime:    helper.NewDuration(maxWaitTime),
+		Compression: compressionAuto,
+		MaxLogSize:  defaultMaxLogSize,
+		Multiline:   helper.NewMultilineConfig(),
+		Encoding:    helper.NewEncodingConfig(),
+	}
+}
+
+// S3InputConfig is the configuration of an S3 input operator.
+type S3InputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+	helper.AWSConfig   `mapstructure:",squash" yaml:",inline"`
+
+	QueueURL          string                 `mapstructure:"queue_url,omitempty"          json:"queue_url,omitempty"          yaml:"queue_url,omitempty"`
+	Endpoint          string                 `mapstructure:"endpoint,omitempty"           json:"endpoint,omitempty"           yaml:"endpoint,omitempty"`
+	MaxMessages       int64                  `mapstructure:"max_messages,omitempty"       json:"max_messages,omitempty"       yaml:"max_messages,omitempty"`
+	WaitTime          helper.Duration        `mapstructure:"wait_time,omitempty"          json:"wait_time,omitempty"          yaml:"wait_time,omitempty"`
+	VisibilityTimeout helper.Duration        `mapstructure:"visibility_timeout,omitempty" json:"visibility_timeout,omitempty" yaml:"visibility_timeout,omitempty"`
+	Compression       string                 `mapstructure:"compression,omitempty"        json:"compression,omitempty"        yaml:"compression,omitempty"`
+	JSONRecordsField  string                 `mapstructure:"json_records_field,omitempty" json:"json_records_field,omitempty" yaml:"json_records_field,omitempty"`
+	MaxLogSize        helper.ByteSize        `mapstructure:"max_log_size,omitempty"       json:"max_log_size,omitempty"       yaml:"max_log_size,omitempty"`
+	Multiline         helper.MultilineConfig `mapstructure:"multiline,omitempty"          json:"multiline,omitempty"          yaml:"multiline,omitempty"`
+	Encoding          helper.EncodingConfig  `mapstructure:",squash,omitempty"            json:",inline,omitempty"            yaml:",inline,omitempty"`
+}
+
+// Build will build an S3 input operator.
+func (c S3InputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.AWSConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	if c.QueueURL == "" {
+		return nil, fmt.Errorf("missing required parameter 'queue_url'")
+	}
+
+	if c.MaxMessages < 1 || c.MaxMessages > maxMessagesPerPoll {
+		return nil, fmt.Errorf("`max_messages` must be between 1 and %d", maxMessagesPerPoll)
+	}
+
+	if c.WaitTime.Raw() < 0 || c.WaitTime.Raw() > maxWaitTime {
+		return nil, fmt.Errorf("`wait_time` must be between 0s and %s", maxWaitTime)
+	}
+
+	if c.VisibilityTimeout.Raw() < 0 {
+		return nil, fmt.Errorf("`visibility_timeout` must not be negative")
+	}
+
+	switch c.Compression {
+	case compressionAuto, compressionNone, compressionGzip:
+	default:
+		return nil, fmt.Errorf("invalid compression '%s'", c.Compression)
+	}
+
+	if c.MaxLogSize <= 0 {
+		return nil, fmt.Errorf("`max_log_size` must be positive")
+	}
+
+	encoding, err := c.Encoding.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	// Objects are complete, so the last entry is flushed even if it is not terminated
+	splitFunc, err := c.Multiline.Build(context, encoding.Encoding, true)
+	if err != nil {
+		return nil, err
+	}
+
+	s3Input := &S3Input{
+		InputOperator:     inputOperator,
+		queueURL:          c.QueueURL,
+		maxMessages:       c.MaxMessages,
+		waitTime:          c.WaitTime.Raw(),
+		visibilityTimeout: c.VisibilityTimeout.Raw(),
+		compression:       c.Compression,
+		jsonRecordsField:  c.JSONRecordsField,
+		maxLogSize:        int(c.MaxLogSize),
+		splitFunc:         splitFunc,
+		encoding:          encoding,
+		newClients:        c.newClients,
+		backoff: backoff.Backoff{
+			Max: 30 * time.Second,
+		},
+	}
+
+	return []operator.Operator{s3Input}, nil
+}
+
+// newClients creates the SQS and S3 clients
+func (c S3InputConfig) newClients() (sqsiface.SQSAPI, s3iface.S3API, error) {
+	sess, err := c.AWSConfig.NewSession()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if c.Endpoint != "" {
+		// Custom endpoints, such as those of S3 compatible
+		// services, usually require path style addressing
+		config := aws.NewConfig().WithEndpoint(c.Endpoint).WithS3ForcePathStyle(true)
+		return sqs.New(sess, config), s3.New(sess, config), nil
+	}
+	return sqs.New(sess), s3.New(sess), nil
+}
+
+// S3Input is an operator that reads the S3 objects referenced by event notifications in an SQS queue.
+type S3Input struct {
+	helper.InputOperator
+	queueURL          string
+	maxMessages       int64
+	waitTime          time.Duration
+	visibilityTimeout time.Duration
+	compression       string
+	jsonRecordsField  string
+	maxLogSize        int
+	splitFunc         bufio.SplitFunc
+	encoding          helper.Encoding
+	newClients        func() (sqsiface.SQSAPI, s3iface.S3API, error)
+
+	sqsClient sqsiface.SQSAPI
+	s3Client  s3iface.S3API
+	backoff   backoff.Backoff
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// Start will start receiving messages from the queue.
+func (s *S3Input) Start(_ operator.Persister) error {
+	sqsClient, s3Client, err := s.newClients()
+	if err != nil {
+		return fmt.Errorf("failed to create aws clients: %s", err)
+	}
+	s.sqsClient = sqsClient
+	s.s3Client = s3Client
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.goReceive(ctx)
+	return nil
+}
+
+// Stop will stop receiving messages from the queue.
+func (s *S3Input) Stop() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// goReceive will receive messages from the queue, and read the objects
+// they reference. A message is only deleted once all of its objects have
+// been read, so that it is received again if an object can not be read.
+func (s *S3Input) goReceive(ctx context.Context) {
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			input := &sqs.ReceiveMessageInput{
+				QueueUrl:            aws.String(s.queueURL),
+				MaxNumberOfMessages: aws.Int64(s.maxMessages),
+				WaitTimeSeconds:     aws.Int64(int64(s.waitTime / time.Second)),
+			}
+			if s.visibilityTimeout > 0 {
+				input.VisibilityTimeout = aws.Int64(int64(s.visibilityTimeout / time.Second))
+			}
+
+			output, err := s.sqsClient.ReceiveMessageWithContext(ctx, input)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				s.Errorw("Failed to receive messages", zap.Error(err))
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(s.backoff.Duration()):
+				}
+				continue
+			}
+			s.backoff.Reset()
+
+			for _, message := range output.Messages {
+				s.handleMessage(ctx, message)
+			}
+		}
+	}()
+}
+
+// handleMessage reads the objects referenced by a message, and deletes the message
+func (s *S3Input) handleMessage(ctx context.Context, message *sqs.Message) {
+	objects, err := parseNotification(aws.StringValue(message.Body))
+	if err != nil {
+		s.Errorw("Failed to parse message", zap.String("message_id", aws.StringValue(message.MessageId)), zap.Error(err))
+		return
+	}
+
+	for _, obj := range objects {
+		if err := s.readObject(ctx, obj); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.Errorw("Failed to read object", zap.String("bucket", obj.bucket), zap.String("key", obj.key), zap.Error(err))
+			return
+		}
+	}
+
+	_, err = s.sqsClient.DeleteMessageWithContext(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(s.queueURL),
+		ReceiptHandle: message.ReceiptHandle,
+	})
+	if err != nil && ctx.Err() == nil {
+		s.Errorw("Failed to delete message", zap.String("message_id", aws.StringValue(message.MessageId)), zap.Error(err))
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// fakeSQS delivers each message once, and records the deleted messages
+type fakeSQS struct {
+	sqsiface.SQSAPI
+
+	sync.Mutex
+	messages []*sqs.Message
+	deleted  []string
+}
+
+func (f *fakeSQS) send(id, body string) {
+	f.Lock()
+	defer f.Unlock()
+	f.messages = append(f.messages, &sqs.Message{
+		MessageId:     aws.String(id),
+		ReceiptHandle: aws.String("receipt-" + id),
+		Body:          aws.String(body),
+	})
+}
+
+func (f *fakeSQS) deletedHandles() []string {
+	f.Lock()
+	defer f.Unlock()
+	return append([]string{}, f.deleted...)
+}
+
+func (f *fakeSQS) ReceiveMessageWithContext(ctx aws.Context, input *sqs.ReceiveMessageInput, _ ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	f.Lock()
+	messages := f.messages
+	f.messages = nil
+	f.Unlock()
+
+	if len(messages) == 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return &sqs.ReceiveMessageOutput{Messages: messages}, nil
+}
+
+func (f *fakeSQS) DeleteMessageWithContext(_ aws.Context, input *sqs.DeleteMessageInput, _ ...request.Option) (*sqs.DeleteMessageOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	f.deleted = append(f.deleted, aws.StringValue(input.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+// fakeS3 serves objects from memory
+type fakeS3 struct {
+	s3iface.S3API
+	objects map[string][]byte
+}
+
+func (f *fakeS3) GetObjectWithContext(_ aws.Context, input *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	content, ok := f.objects[aws.StringValue(input.Bucket)+"/"+aws.StringValue(input.Key)]
+	if !ok {
+		return nil, fmt.Errorf("NoSuchKey")
+	}
+	return &s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(content))}, nil
+}
+
+func notification(bucket string, keys ...string) string {
+	records := ""
+	for i, key := range keys {
+		if i > 0 {
+			records += ","
+		}
+		records += fmt.Sprintf(`{"eventSource":"aws:s3","eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"%s"},"object":{"key":"%s"}}}`, bucket, key)
+	}
+	return `{"Records":[` + records + `]}`
+}
+
+func gzipped(t *testing.T, content string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func newTestS3Input(t *testing.T, objects map[string][]byte, cfgMod func(*S3InputConfig)) (*fakeSQS, *testutil.FakeOutput) {
+	cfg := NewS3InputConfig("test_id")
+	cfg.QueueURL = "https://sqs.us-east-1.amazonaws.com/123456789012/logs"
+	cfg.OutputIDs = []string{"fake"}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	s3Input := ops[0].(*S3Input)
+
+	queue := &fakeSQS{}
+	s3Input.newClients = func() (sqsiface.SQSAPI, s3iface.S3API, error) {
+		return queue, &fakeS3{objects: objects}, nil
+	}
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, s3Input.SetOutputs([]operator.Operator{fakeOutput}))
+	require.NoError(t, s3Input.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() { require.NoError(t, s3Input.Stop()) })
+	return queue, fakeOutput
+}
+
+func expectBodies(t *testing.T, output *testutil.FakeOutput, expected ...interface{}) {
+	for _, body := range expected {
+		select {
+		case e := <-output.Received:
+			require.Equal(t, body, e.Body)
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for entry", "Body: %v", body)
+		}
+	}
+}
+
+func expectNoEntry(t *testing.T, output *testutil.FakeOutput) {
+	select {
+	case e := <-output.Received:
+		require.FailNow(t, "Unexpected entry", "Body: %v", e.Body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*S3InputConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *S3InputConfig) {},
+			false,
+		},
+		{
+			"MissingQueueURL",
+			func(cfg *S3InputConfig) {
+				cfg.QueueURL = ""
+			},
+			true,
+		},
+		{
+			"ExternalIDWithoutRole",
+			func(cfg *S3InputConfig) {
+				cfg.ExternalID = "secret"
+			},
+			true,
+		},
+		{
+			"TooManyMessages",
+			func(cfg *S3InputConfig) {
+				cfg.MaxMessages = 11
+			},
+			true,
+		},
+		{
+			"WaitTimeTooLong",
+			func(cfg *S3InputConfig) {
+				cfg.WaitTime.Duration = time.Minute
+			},
+			true,
+		},
+		{
+			"NegativeVisibilityTimeout",
+			func(cfg *S3InputConfig) {
+				cfg.VisibilityTimeout.Duration = -time.Second
+			},
+			true,
+		},
+		{
+			"InvalidCompression",
+			func(cfg *S3InputConfig) {
+				cfg.Compression = "zstd"
+			},
+			true,
+		},
+		{
+			"ZeroMaxLogSize",
+			func(cfg *S3InputConfig) {
+				cfg.MaxLogSize = 0
+			},
+			true,
+		},
+		{
+			"InvalidEncoding",
+			func(cfg *S3InputConfig) {
+				cfg.Encoding.Encoding = "invalid"
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewS3InputConfig("test_id")
+			cfg.QueueURL = "https://sqs.us-east-1.amazonaws.com/123456789012/logs"
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestS3Input(t *testing.T) {
+	objects := map[string][]byte{
+		"logs/plain.log":   []byte("first\nsecond\n"),
+		"logs/archive.gz":  gzipped(t, "third\nfourth"),
+		"logs/other/b.log": []byte("fifth\n"),
+	}
+	queue, fakeOutput := newTestS3Input(t, objects, nil)
+
+	queue.send("1", notification("logs", "plain.log", "archive.gz"))
+	select {
+	case e := <-fakeOutput.Received:
+		require.Equal(t, "first", e.Body)
+		require.Equal(t, map[string]string{
+			bucketAttribute: "logs",
+			keyAttribute:    "plain.log",
+		}, e.Attributes)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+	expectBodies(t, fakeOutput, "second", "third", "fourth")
+
+	queue.send("2", notification("logs", "other/b.log"))
+	expectBodies(t, fakeOutput, "fifth")
+	expectNoEntry(t, fakeOutput)
+	require.Equal(t, []string{"receipt-1", "receipt-2"}, queue.deletedHandles())
+}
+
+func TestS3InputFailedObject(t *testing.T) {
+	objects := map[string][]byte{
+		"logs/present.log": []byte("present\n"),
+	}
+	queue, fakeOutput := newTestS3Input(t, objects, nil)
+
+	// the message is not deleted, so that it is received again
+	queue.send("1", notification("logs", "present.log", "missing.log"))
+	queue.send("2", "not a notification")
+	expectBodies(t, fakeOutput, "present")
+	expectNoEntry(t, fakeOutput)
+	require.Empty(t, queue.deletedHandles())
+
+	// test events reference no objects, and are deleted
+	queue.send("3", `{"Service":"Amazon S3","Event":"s3:TestEvent"}`)
+	require.Eventually(t, func() bool {
+		return len(queue.deletedHandles()) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestS3InputJSONRecords(t *testing.T) {
+	objects := map[string][]byte{
+		"trail/events.json.gz": gzipped(t, `{"Records":[{"eventName":"ConsoleLogin"},{"eventName":"CreateBucket"}]}`),
+		"trail/invalid.json":   []byte(`{"Other":[]}`),
+	}
+	queue, fakeOutput := newTestS3Input(t, objects, func(cfg *S3InputConfig) {
+		cfg.JSONRecordsField = "Records"
+	})
+
+	queue.send("1", notification("trail", "events.json.gz"))
+	expectBodies(t, fakeOutput,
+		map[string]interface{}{"eventName": "ConsoleLogin"},
+		map[string]interface{}{"eventName": "CreateBucket"},
+	)
+	require.Eventually(t, func() bool {
+		return len(queue.deletedHandles()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	queue.send("2", notification("trail", "invalid.json"))
+	expectNoEntry(t, fakeOutput)
+	require.Len(t, queue.deletedHandles(), 1)
+}
+
+func TestS3InputCompressionNone(t *testing.T) {
+	objects := map[string][]byte{
+		"logs/binary": gzipped(t, "compressed"),
+	}
+	queue, fakeOutput := newTestS3Input(t, objects, func(cfg *S3InputConfig) {
+		cfg.Compression = compressionNone
+		cfg.Multiline.LineStartPattern = "^never$"
+	})
+
+	queue.send("1", notification("logs", "binary"))
+	select {
+	case e := <-fakeOutput.Received:
+		require.NotEqual(t, "compressed", e.Body)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// baseOIDs are the object identifiers defined by SNMPv2-SMI and SNMPv2-MIB,
+// which are resolved without loading any MIBs.
+var baseOIDs = map[string]string{
+	"iso":                   "1",
+	"org":                   "1.3",
+	"dod":                   "1.3.6",
+	"internet":              "1.3.6.1",
+	"directory":             "1.3.6.1.1",
+	"mgmt":                  "1.3.6.1.2",
+	"mib-2":                 "1.3.6.1.2.1",
+	"system":                "1.3.6.1.2.1.1",
+	"sysDescr":              "1.3.6.1.2.1.1.1",
+	"sysObjectID":           "1.3.6.1.2.1.1.2",
+	"sysUpTime":             "1.3.6.1.2.1.1.3",
+	"sysContact":            "1.3.6.1.2.1.1.4",
+	"sysName":               "1.3.6.1.2.1.1.5",
+	"sysLocation":           "1.3.6.1.2.1.1.6",
+	"interfaces":            "1.3.6.1.2.1.2",
+	"ifTable":               "1.3.6.1.2.1.2.2",
+	"ifEntry":               "1.3.6.1.2.1.2.2.1",
+	"ifIndex":               "1.3.6.1.2.1.2.2.1.1",
+	"ifDescr":               "1.3.6.1.2.1.2.2.1.2",
+	"ifType":                "1.3.6.1.2.1.2.2.1.3",
+	"ifAdminStatus":         "1.3.6.1.2.1.2.2.1.7",
+	"ifOperStatus":          "1.3.6.1.2.1.2.2.1.8",
+	"transmission":          "1.3.6.1.2.1.10",
+	"experimental":          "1.3.6.1.3",
+	"private":               "1.3.6.1.4",
+	"enterprises":           "1.3.6.1.4.1",
+	"security":              "1.3.6.1.5",
+	"snmpV2":                "1.3.6.1.6",
+	"snmpDomains":           "1.3.6.1.6.1",
+	"snmpProxys":            "1.3.6.1.6.2",
+	"snmpModules":           "1.3.6.1.6.3",
+	"snmpMIB":               "1.3.6.1.6.3.1",
+	"snmpMIBObjects":        "1.3.6.1.6.3.1.1",
+	"snmpTrap":              "1.3.6.1.6.3.1.1.4",
+	"snmpTrapOID":           "1.3.6.1.6.3.1.1.4.1",
+	"snmpTrapEnterprise":    "1.3.6.1.6.3.1.1.4.3",
+	"snmpTraps":             "1.3.6.1.6.3.1.1.5",
+	"coldStart":             "1.3.6.1.6.3.1.1.5.1",
+	"warmStart":             "1.3.6.1.6.3.1.1.5.2",
+	"linkDown":              "1.3.6.1.6.3.1.1.5.3",
+	"linkUp":                "1.3.6.1.6.3.1.1.5.4",
+	"authenticationFailure": "1.3.6.1.6.3.1.1.5.5",
+	"egpNeighborLoss":       "1.3.6.1.6.3.1.1.5.6",
+}
+
+var (
+	// importsPattern matches the IMPORTS clause of a MIB module
+	importsPattern = regexp.MustCompile(`(?s)\bIMPORTS\b.*?;`)
+
+	// definitionPattern matches the definition of an object identifier, capturing its name and value
+	definitionPattern = regexp.MustCompile(`([a-z][\w-]*)\s+(?:OBJECT\s+IDENTIFIER|OBJECT-TYPE|OBJECT-IDENTITY|MODULE-IDENTITY|NOTIFICATION-TYPE|OBJECT-GROUP|NOTIFICATION-GROUP|MODULE-COMPLIANCE|AGENT-CAPABILITIES)\b[^:]*?::=\s*\{([^}]*)\}`)
+
+	// namedNumberPattern matches a component of an object identifier value in the form name(number)
+	namedNumberPattern = regexp.MustCompile(`^([a-zA-Z][\w-]*)\((\d+)\)$`)
+)
+
+// OIDResolver resolves numeric object identifiers to names defined in MIBs.
+type OIDResolver struct {
+	names map[string]string
+}
+
+// NewOIDResolver creates a resolver of the base object identifiers and those defined in the MIBs at the paths.
+// A path may be a MIB file, or a directory of MIB files.
+func NewOIDResolver(paths []string) (*OIDResolver, error) {
+	definitions := map[string]mibDefinition{}
+	for _, path := range paths {
+		files, err := mibFiles(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("read mib %s: %s", file, err)
+			}
+			parseMIB(string(data), definitions)
+		}
+	}
+
+	oids := make(map[string]string, len(baseOIDs)+len(definitions))
+	for name, oid := range baseOIDs {
+		oids[name] = oid
+	}
+	for name := range definitions {
+		resolveDefinition(name, definitions, oids, map[string]bool{})
+	}
+
+	resolver := &OIDResolver{
+		names: make(map[string]string, len(oids)),
+	}
+	for name, oid := range oids {
+		resolver.names[oid] = name
+	}
+	return resolver, nil
+}
+
+// Resolve will resolve an object identifier to the name of its longest defined prefix,
+// followed by the remaining numeric components. If no prefix is defined, the numeric
+// object identifier is returned.
+func (r *OIDResolver) Resolve(oid string) string {
+	oid = strings.TrimPrefix(oid, ".")
+	for prefix := oid; prefix != ""; {
+		if name, ok := r.names[prefix]; ok {
+			return name + oid[len(prefix):]
+		}
+
+		i := strings.LastIndexByte(prefix, '.')
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+	return oid
+}
+
+// mibDefinition is an object identifier defined relative to its parent.
+type mibDefinition struct {
+	parent string
+	subIDs []string
+}
+
+// mibFiles will return the MIB files at a path.
+func mibFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mib path '%s': %s", path, err)
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("read mib directory %s: %s", path, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// parseMIB will parse the object identifier definitions of a MIB into definitions.
+// Names defined within an object identifier value, such as org(3), are also added.
+func parseMIB(data string, definitions map[string]mibDefinition) {
+	data = importsPattern.ReplaceAllString(stripMIB(data), "")
+
+	for _, match := range definitionPattern.FindAllStringSubmatch(data, -1) {
+		components := strings.Fields(match[2])
+		if len(components) < 2 {
+			continue
+		}
+
+		parent := components[0]
+		var subIDs []string
+		for _, component := range components[1:] {
+			if m := namedNumberPattern.FindStringSubmatch(component); m != nil {
+				subIDs = append(subIDs, m[2])
+				definitions[m[1]] = mibDefinition{parent: parent, subIDs: append([]string(nil), subIDs...)}
+				continue
+			}
+
+			if _, err := strconv.ParseUint(component, 10, 32); err != nil {
+				subIDs = nil
+				break
+			}
+			subIDs = append(subIDs, component)
+		}
+
+		if len(subIDs) > 0 {
+			definitions[match[1]] = mibDefinition{parent: parent, subIDs: subIDs}
+		}
+	}
+}
+
+// resolveDefinition will resolve the numeric object identifier of a definition, and the definitions of its parents.
+func resolveDefinition(name string, definitions map[string]mibDefinition, oids map[string]string, visiting map[string]bool) (string, bool) {
+	if oid, ok := oids[name]; ok {
+		return oid, true
+	}
+
+	definition, ok := definitions[name]
+	if !ok || visiting[name] {
+		return "", false
+	}
+	visiting[name] = true
+
+	parent, ok := resolveDefinition(definition.parent, definitions, oids, visiting)
+	if !ok {
+		return "", false
+	}
+
+	oid := parent + "." + strings.Join(definition.subIDs, ".")
+	oids[name] = oid
+	return oid, true
+}
+
+// stripMIB will remove the comments and quoted strings of a MIB, so that
+// descriptions are not mistaken for definitions.
+func stripMIB(data string) string {
+	var b strings.Builder
+	b.Grow(len(data))
+
+	for i := 0; i < len(data); i++ {
+		switch {
+		case data[i] == '"':
+			end := strings.IndexByte(data[i+1:], '"')
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 1
+			b.WriteByte(' ')
+		case strings.HasPrefix(data[i:], "--"):
+			// A comment ends at the end of the line, or at the next "--"
+			i += 2
+			for i < len(data) && data[i] != '\n' && !strings.HasPrefix(data[i:], "--") {
+				i++
+			}
+			if i < len(data) && data[i] == '\n' {
+				i--
+			} else {
+				i++
+			}
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(data[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDResolverBase(t *testing.T) {
+	resolver, err := NewOIDResolver(nil)
+	require.NoError(t, err)
+
+	cases := map[string]string{
+		".1.3.6.1.6.3.1.1.4.1.0": "snmpTrapOID.0",
+		"1.3.6.1.6.3.1.1.5.3":    "linkDown",
+		"1.3.6.1.2.1.2.2.1.2.7":  "ifDescr.7",
+		"1.3.6.1.4.1.99999.1.1":  "enterprises.99999.1.1",
+		"2.5.4.3":                "2.5.4.3",
+	}
+	for oid, expected := range cases {
+		require.Equal(t, expected, resolver.Resolve(oid), oid)
+	}
+}
+
+func TestOIDResolverMIB(t *testing.T) {
+	for _, path := range []string{"testdata", filepath.Join("testdata", "TEST-TRAP-MIB.txt")} {
+		resolver, err := NewOIDResolver([]string{path})
+		require.NoError(t, err)
+
+		cases := map[string]string{
+			"1.3.6.1.4.1.99999":       "testTrapMIB",
+			"1.3.6.1.4.1.99999.1.1.3": "testFanIndex.3",
+			"1.3.6.1.4.1.99999.1.2.3": "testFanStatus.3",
+			"1.3.6.1.4.1.99999.2.1":   "testFanFailed",
+			"1.3.6.1.4.1.99999.3":     "testTrapMIB.3",
+			"1.3.6.1.4.1.99998.1":     "testLegacy.1",
+		}
+		for oid, expected := range cases {
+			require.Equal(t, expected, resolver.Resolve(oid), oid)
+		}
+	}
+}
+
+func TestOIDResolverInvalidPath(t *testing.T) {
+	_, err := NewOIDResolver([]string{filepath.Join("testdata", "missing")})
+	require.Error(t, err)
+}
+
+func TestStripMIB(t *testing.T) {
+	mib := "a -- comment\nb -- inline -- c \"quoted -- \" d"
+	require.Equal(t, "a  \nb   c   d", stripMIB(mib))
+}
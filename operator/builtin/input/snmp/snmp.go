@@ -0,0 +1,405 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmp
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gosnmp/gosnmp"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// maxTrapSize is the maximum size of a trap, which is the maximum size of a UDP packet
+	maxTrapSize = 64 * 1024
+
+	sysUpTimeOID   = "1.3.6.1.2.1.1.3.0"
+	snmpTrapOIDOID = "1.3.6.1.6.3.1.1.4.1.0"
+	snmpTrapsOID   = "1.3.6.1.6.3.1.1.5"
+)
+
+func init() {
+	operator.Register("snmp_trap_input", func() operator.Builder { return NewSNMPTrapInputConfig("") })
+}
+
+// NewSNMPTrapInputConfig creates a new snmp trap input config with default values
+func NewSNMPTrapInputConfig(operatorID string) *SNMPTrapInputConfig {
+	return &SNMPTrapInputConfig{
+		InputConfig:   helper.NewInputConfig(operatorID, "snmp_trap_input"),
+		ListenAddress: "0.0.0.0:162",
+	}
+}
+
+// SNMPTrapInputConfig is the configuration of an snmp trap input operator.
+type SNMPTrapInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	ListenAddress string        `mapstructure:"listen_address,omitempty" json:"listen_address,omitempty" yaml:"listen_address,omitempty"`
+	Community     string        `mapstructure:"community,omitempty"      json:"community,omitempty"      yaml:"community,omitempty"`
+	V3            *SNMPv3Config `mapstructure:"v3,omitempty"             json:"v3,omitempty"             yaml:"v3,omitempty"`
+	MIBPaths      []string      `mapstructure:"mib_paths,omitempty"      json:"mib_paths,omitempty"      yaml:"mib_paths,omitempty"`
+}
+
+// SNMPv3Config is the configuration of the user which SNMPv3 traps are authenticated as.
+type SNMPv3Config struct {
+	User            string `mapstructure:"user,omitempty"             json:"user,omitempty"             yaml:"user,omitempty"`
+	EngineID        string `mapstructure:"engine_id,omitempty"        json:"engine_id,omitempty"        yaml:"engine_id,omitempty"`
+	AuthProtocol    string `mapstructure:"auth_protocol,omitempty"    json:"auth_protocol,omitempty"    yaml:"auth_protocol,omitempty"`
+	AuthPassword    string `mapstructure:"auth_password,omitempty"    json:"auth_password,omitempty"    yaml:"auth_password,omitempty"`
+	PrivacyProtocol string `mapstructure:"privacy_protocol,omitempty" json:"privacy_protocol,omitempty" yaml:"privacy_protocol,omitempty"`
+	PrivacyPassword string `mapstructure:"privacy_password,omitempty" json:"privacy_password,omitempty" yaml:"privacy_password,omitempty"`
+}
+
+var authProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+	"md5":    gosnmp.MD5,
+	"sha":    gosnmp.SHA,
+	"sha224": gosnmp.SHA224,
+	"sha256": gosnmp.SHA256,
+	"sha384": gosnmp.SHA384,
+	"sha512": gosnmp.SHA512,
+}
+
+var privacyProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	"des":     gosnmp.DES,
+	"aes":     gosnmp.AES,
+	"aes192":  gosnmp.AES192,
+	"aes256":  gosnmp.AES256,
+	"aes192c": gosnmp.AES192C,
+	"aes256c": gosnmp.AES256C,
+}
+
+// Build will build an snmp trap input operator.
+func (c SNMPTrapInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ListenAddress == "" {
+		return nil, fmt.Errorf("missing required parameter 'listen_address'")
+	}
+
+	address, err := net.ResolveUDPAddr("udp", c.ListenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve listen_address: %s", err)
+	}
+
+	params := &gosnmp.GoSNMP{
+		Version: gosnmp.Version2c,
+	}
+	if c.V3 != nil {
+		if err := c.V3.configure(params); err != nil {
+			return nil, err
+		}
+	}
+
+	resolver, err := NewOIDResolver(c.MIBPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	snmpTrapInput := &SNMPTrapInput{
+		InputOperator: inputOperator,
+		address:       address,
+		community:     c.Community,
+		params:        params,
+		resolver:      resolver,
+	}
+	if c.V3 != nil {
+		snmpTrapInput.user = c.V3.User
+	}
+	return []operator.Operator{snmpTrapInput}, nil
+}
+
+// configure will configure the params to authenticate and decrypt SNMPv3 traps.
+func (c *SNMPv3Config) configure(params *gosnmp.GoSNMP) error {
+	if c.User == "" {
+		return fmt.Errorf("missing required parameter 'v3.user'")
+	}
+
+	security := &gosnmp.UsmSecurityParameters{
+		UserName:               c.User,
+		AuthenticationProtocol: gosnmp.NoAuth,
+		PrivacyProtocol:        gosnmp.NoPriv,
+	}
+	flags := gosnmp.NoAuthNoPriv
+
+	if c.AuthProtocol != "" {
+		protocol, ok := authProtocols[strings.ToLower(c.AuthProtocol)]
+		if !ok {
+			return fmt.Errorf("invalid auth_protocol '%s'", c.AuthProtocol)
+		}
+		if c.AuthPassword == "" {
+			return fmt.Errorf("missing required parameter 'v3.auth_password'")
+		}
+		if c.EngineID == "" {
+			return fmt.Errorf("missing required parameter 'v3.engine_id'")
+		}
+
+		engineID, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(c.EngineID), "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid engine_id '%s': %s", c.EngineID, err)
+		}
+
+		security.AuthoritativeEngineID = string(engineID)
+		security.AuthenticationProtocol = protocol
+		security.AuthenticationPassphrase = c.AuthPassword
+		flags = gosnmp.AuthNoPriv
+	}
+
+	if c.PrivacyProtocol != "" {
+		protocol, ok := privacyProtocols[strings.ToLower(c.PrivacyProtocol)]
+		if !ok {
+			return fmt.Errorf("invalid privacy_protocol '%s'", c.PrivacyProtocol)
+		}
+		if flags != gosnmp.AuthNoPriv {
+			return fmt.Errorf("privacy_protocol requires an auth_protocol")
+		}
+		if c.PrivacyPassword == "" {
+			return fmt.Errorf("missing required parameter 'v3.privacy_password'")
+		}
+
+		security.PrivacyProtocol = protocol
+		security.PrivacyPassphrase = c.PrivacyPassword
+		flags = gosnmp.AuthPriv
+	}
+
+	params.Version = gosnmp.Version3
+	params.SecurityModel = gosnmp.UserSecurityModel
+	params.MsgFlags = flags
+	params.SecurityParameters = security
+	return nil
+}
+
+// SNMPTrapInput is an operator that receives snmp traps.
+type SNMPTrapInput struct {
+	helper.InputOperator
+	address   *net.UDPAddr
+	community string
+	user      string
+	params    *gosnmp.GoSNMP
+	resolver  *OIDResolver
+
+	connection net.PacketConn
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// Start will start listening for traps.
+func (s *SNMPTrapInput) Start(_ operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	conn, err := net.ListenUDP("udp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to open connection: %s", err)
+	}
+	s.connection = conn
+
+	s.goHandleTraps(ctx)
+	return nil
+}
+
+// goHandleTraps will handle traps received on the connection.
+func (s *SNMPTrapInput) goHandleTraps(ctx context.Context) {
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		buf := make([]byte, maxTrapSize)
+		for {
+			n, addr, err := s.connection.ReadFrom(buf)
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+					s.Errorw("Failed reading traps", zap.Error(err))
+					continue
+				}
+			}
+
+			packet := s.params.UnmarshalTrap(buf[:n], false)
+			if packet == nil {
+				s.Debugw("Discarding invalid or unauthenticated trap", zap.String("net.peer.ip", addrIP(addr)))
+				continue
+			}
+
+			if !s.accept(packet) {
+				s.Debugw("Discarding trap with unexpected credentials", zap.String("net.peer.ip", addrIP(addr)))
+				continue
+			}
+
+			s.handleTrap(ctx, packet, addr)
+
+			if packet.PDUType == gosnmp.InformRequest {
+				s.respond(packet, addr)
+			}
+		}
+	}()
+}
+
+// accept will check that the community or user of a trap is the configured one.
+func (s *SNMPTrapInput) accept(packet *gosnmp.SnmpPacket) bool {
+	if packet.Version == gosnmp.Version3 {
+		security, ok := packet.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+		return ok && s.user != "" && security.UserName == s.user
+	}
+	return s.community == "" || packet.Community == s.community
+}
+
+// handleTrap will create an entry from a trap and send it to the operator's output.
+func (s *SNMPTrapInput) handleTrap(ctx context.Context, packet *gosnmp.SnmpPacket, addr net.Addr) {
+	entry, err := s.NewEntry(s.parseTrap(packet))
+	if err != nil {
+		s.Errorw("Failed to create entry", zap.Error(err))
+		return
+	}
+
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		entry.AddAttribute("net.peer.ip", udpAddr.IP.String())
+		entry.AddAttribute("net.peer.port", strconv.Itoa(udpAddr.Port))
+	}
+
+	s.Write(ctx, entry)
+}
+
+// parseTrap will parse the body of an entry from a trap.
+// The trap OID is derived from the generic and specific trap of SNMPv1 traps, as described in RFC 3584.
+func (s *SNMPTrapInput) parseTrap(packet *gosnmp.SnmpPacket) map[string]interface{} {
+	body := map[string]interface{}{}
+	varbinds := map[string]interface{}{}
+
+	var trapOID string
+	switch packet.Version {
+	case gosnmp.Version1:
+		body["version"] = "v1"
+		body["enterprise"] = s.resolver.Resolve(packet.Enterprise)
+		body["agent_address"] = packet.AgentAddress
+		body["generic_trap"] = packet.GenericTrap
+		body["specific_trap"] = packet.SpecificTrap
+		body["uptime"] = packet.Timestamp
+
+		if packet.GenericTrap == 6 {
+			trapOID = strings.TrimPrefix(packet.Enterprise, ".") + ".0." + strconv.Itoa(packet.SpecificTrap)
+		} else {
+			trapOID = snmpTrapsOID + "." + strconv.Itoa(packet.GenericTrap+1)
+		}
+	case gosnmp.Version2c:
+		body["version"] = "v2c"
+	case gosnmp.Version3:
+		body["version"] = "v3"
+		if security, ok := packet.SecurityParameters.(*gosnmp.UsmSecurityParameters); ok {
+			body["user"] = security.UserName
+		}
+	}
+
+	for _, variable := range packet.Variables {
+		oid := strings.TrimPrefix(variable.Name, ".")
+		switch {
+		case oid == sysUpTimeOID && packet.Version != gosnmp.Version1:
+			body["uptime"] = variable.Value
+		case oid == snmpTrapOIDOID && packet.Version != gosnmp.Version1:
+			if value, ok := variable.Value.(string); ok {
+				trapOID = strings.TrimPrefix(value, ".")
+			}
+		default:
+			varbinds[s.resolver.Resolve(oid)] = s.parseValue(variable)
+		}
+	}
+
+	if packet.PDUType == gosnmp.InformRequest {
+		body["pdu_type"] = "inform"
+	} else {
+		body["pdu_type"] = "trap"
+	}
+
+	if trapOID != "" {
+		body["trap_oid"] = trapOID
+		body["trap"] = s.resolver.Resolve(trapOID)
+	}
+	body["varbinds"] = varbinds
+	return body
+}
+
+// parseValue will parse the value of a varbind into a type which can be represented in an entry.
+func (s *SNMPTrapInput) parseValue(variable gosnmp.SnmpPDU) interface{} {
+	switch variable.Type {
+	case gosnmp.OctetString:
+		value, ok := variable.Value.([]byte)
+		if !ok {
+			return variable.Value
+		}
+		if utf8.Valid(value) {
+			return string(value)
+		}
+		return hex.EncodeToString(value)
+	case gosnmp.ObjectIdentifier:
+		if value, ok := variable.Value.(string); ok {
+			return s.resolver.Resolve(value)
+		}
+		return variable.Value
+	case gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView:
+		return nil
+	default:
+		return variable.Value
+	}
+}
+
+// respond will acknowledge an inform request, by sending it back as a response.
+func (s *SNMPTrapInput) respond(packet *gosnmp.SnmpPacket, addr net.Addr) {
+	packet.PDUType = gosnmp.GetResponse
+	packet.Error = gosnmp.NoError
+	packet.ErrorIndex = 0
+
+	response, err := packet.MarshalMsg()
+	if err != nil {
+		s.Errorw("Failed to marshal inform response", zap.Error(err))
+		return
+	}
+
+	if _, err := s.connection.WriteTo(response, addr); err != nil {
+		s.Errorw("Failed to send inform response", zap.Error(err))
+	}
+}
+
+// Stop will stop listening for traps.
+func (s *SNMPTrapInput) Stop() error {
+	s.cancel()
+	if s.connection != nil {
+		s.connection.Close()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// addrIP will return the ip of a udp address.
+func addrIP(addr net.Addr) string {
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		return udpAddr.IP.String()
+	}
+	return ""
+}
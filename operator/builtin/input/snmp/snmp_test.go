@@ -0,0 +1,410 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmp
+
+import (
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+const testEngineID = "80001f8880e9630000d61ff449"
+
+func newTestSNMPTrapInput(t *testing.T, cfgMod func(*SNMPTrapInputConfig)) (*SNMPTrapInput, *testutil.FakeOutput) {
+	cfg := NewSNMPTrapInputConfig("test_id")
+	cfg.ListenAddress = "127.0.0.1:0"
+	cfg.MIBPaths = []string{"testdata"}
+	cfg.OutputIDs = []string{"fake"}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	snmpTrapInput := ops[0].(*SNMPTrapInput)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, snmpTrapInput.SetOutputs([]operator.Operator{fakeOutput}))
+
+	require.NoError(t, snmpTrapInput.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() { require.NoError(t, snmpTrapInput.Stop()) })
+	return snmpTrapInput, fakeOutput
+}
+
+func newTestSender(t *testing.T, input *SNMPTrapInput, modify func(*gosnmp.GoSNMP)) *gosnmp.GoSNMP {
+	sender := &gosnmp.GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(input.connection.LocalAddr().(*net.UDPAddr).Port),
+		Version:   gosnmp.Version2c,
+		Community: "public",
+		Timeout:   time.Second,
+	}
+	if modify != nil {
+		modify(sender)
+	}
+	require.NoError(t, sender.Connect())
+	t.Cleanup(func() { sender.Conn.Close() })
+	return sender
+}
+
+func newTestV3Params(authPassword string) *gosnmp.UsmSecurityParameters {
+	engineID, _ := hex.DecodeString(testEngineID)
+	return &gosnmp.UsmSecurityParameters{
+		UserName:                 "collector",
+		AuthoritativeEngineID:    string(engineID),
+		AuthenticationProtocol:   gosnmp.SHA,
+		AuthenticationPassphrase: authPassword,
+		PrivacyProtocol:          gosnmp.AES,
+		PrivacyPassphrase:        "privacypassword",
+	}
+}
+
+var testTrap = gosnmp.SnmpTrap{
+	Variables: []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.99999.2.1"},
+		{Name: ".1.3.6.1.4.1.99999.1.1.3", Type: gosnmp.Integer, Value: 3},
+		{Name: ".1.3.6.1.4.1.99999.1.2.3", Type: gosnmp.Integer, Value: 2},
+		{Name: ".1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: "switch01"},
+		{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.99999"},
+	},
+}
+
+func expectTrap(t *testing.T, fakeOutput *testutil.FakeOutput, version string, extra map[string]interface{}) *entry.Entry {
+	select {
+	case e := <-fakeOutput.Received:
+		body, ok := e.Body.(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, version, body["version"])
+		require.Equal(t, "1.3.6.1.4.1.99999.2.1", body["trap_oid"])
+		require.Equal(t, "testFanFailed", body["trap"])
+		require.Equal(t, map[string]interface{}{
+			"testFanIndex.3":  3,
+			"testFanStatus.3": 2,
+			"sysName.0":       "switch01",
+			"sysObjectID.0":   "testTrapMIB",
+		}, body["varbinds"])
+		for k, v := range extra {
+			require.Equal(t, v, body[k], k)
+		}
+		require.Equal(t, "127.0.0.1", e.Attributes["net.peer.ip"])
+		return e
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for trap")
+	}
+	return nil
+}
+
+func expectNoEntry(t *testing.T, fakeOutput *testutil.FakeOutput) {
+	select {
+	case e := <-fakeOutput.Received:
+		require.FailNow(t, "Unexpected entry", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*SNMPTrapInputConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *SNMPTrapInputConfig) {},
+			false,
+		},
+		{
+			"MissingListenAddress",
+			func(cfg *SNMPTrapInputConfig) {
+				cfg.ListenAddress = ""
+			},
+			true,
+		},
+		{
+			"InvalidListenAddress",
+			func(cfg *SNMPTrapInputConfig) {
+				cfg.ListenAddress = "127.0.0.1:badport"
+			},
+			true,
+		},
+		{
+			"InvalidMIBPath",
+			func(cfg *SNMPTrapInputConfig) {
+				cfg.MIBPaths = []string{"testdata/missing"}
+			},
+			true,
+		},
+		{
+			"V3NoAuth",
+			func(cfg *SNMPTrapInputConfig) {
+				cfg.V3 = &SNMPv3Config{User: "collector"}
+			},
+			false,
+		},
+		{
+			"V3AuthPriv",
+			func(cfg *SNMPTrapInputConfig) {
+				cfg.V3 = &SNMPv3Config{
+					User:            "collector",
+					EngineID:        "0x" + testEngineID,
+					AuthProtocol:    "SHA256",
+					AuthPassword:    "authpassword",
+					PrivacyProtocol: "aes",
+					PrivacyPassword: "privacypassword",
+				}
+			},
+			false,
+		},
+		{
+			"V3MissingUser",
+			func(cfg *SNMPTrapInputConfig) {
+				cfg.V3 = &SNMPv3Config{}
+			},
+			true,
+		},
+		{
+			"V3InvalidAuthProtocol",
+			func(cfg *SNMPTrapInputConfig) {
+				cfg.V3 = &SNMPv3Config{User: "collector", EngineID: testEngineID, AuthProtocol: "sha1024", AuthPassword: "authpassword"}
+			},
+			true,
+		},
+		{
+			"V3MissingAuthPassword",
+			func(cfg *SNMPTrapInputConfig) {
+				cfg.V3 = &SNMPv3Config{User: "collector", EngineID: testEngineID, AuthProtocol: "sha"}
+			},
+			true,
+		},
+		{
+			"V3MissingEngineID",
+			func(cfg *SNMPTrapInputConfig) {
+				cfg.V3 = &SNMPv3Config{User: "collector", AuthProtocol: "sha", AuthPassword: "authpassword"}
+			},
+			true,
+		},
+		{
+			"V3InvalidEngineID",
+			func(cfg *SNMPTrapInputConfig) {
+				cfg.V3 = &SNMPv3Config{User: "collector", EngineID: "engine", AuthProtocol: "sha", AuthPassword: "authpassword"}
+			},
+			true,
+		},
+		{
+			"V3PrivacyWithoutAuth",
+			func(cfg *SNMPTrapInputConfig) {
+				cfg.V3 = &SNMPv3Config{User: "collector", PrivacyProtocol: "aes", PrivacyPassword: "privacypassword"}
+			},
+			true,
+		},
+		{
+			"V3InvalidPrivacyProtocol",
+			func(cfg *SNMPTrapInputConfig) {
+				cfg.V3 = &SNMPv3Config{User: "collector", EngineID: testEngineID, AuthProtocol: "sha", AuthPassword: "authpassword", PrivacyProtocol: "rot13", PrivacyPassword: "privacypassword"}
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewSNMPTrapInputConfig("test_id")
+			cfg.ListenAddress = "127.0.0.1:0"
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSNMPTrapInputV2c(t *testing.T) {
+	input, fakeOutput := newTestSNMPTrapInput(t, nil)
+	sender := newTestSender(t, input, nil)
+
+	_, err := sender.SendTrap(testTrap)
+	require.NoError(t, err)
+
+	e := expectTrap(t, fakeOutput, "v2c", map[string]interface{}{"pdu_type": "trap"})
+	require.Contains(t, e.Body, "uptime")
+	require.NotContains(t, e.Body, "community")
+}
+
+func TestSNMPTrapInputV1(t *testing.T) {
+	input, fakeOutput := newTestSNMPTrapInput(t, nil)
+	sender := newTestSender(t, input, func(sender *gosnmp.GoSNMP) {
+		sender.Version = gosnmp.Version1
+	})
+
+	_, err := sender.SendTrap(gosnmp.SnmpTrap{
+		Variables:    []gosnmp.SnmpPDU{{Name: ".1.3.6.1.2.1.2.2.1.1.2", Type: gosnmp.Integer, Value: 2}},
+		Enterprise:   ".1.3.6.1.4.1.99999",
+		AgentAddress: "10.0.0.1",
+		GenericTrap:  2,
+		Timestamp:    300,
+	})
+	require.NoError(t, err)
+
+	select {
+	case e := <-fakeOutput.Received:
+		require.Equal(t, map[string]interface{}{
+			"version":       "v1",
+			"pdu_type":      "trap",
+			"enterprise":    "testTrapMIB",
+			"agent_address": "10.0.0.1",
+			"generic_trap":  2,
+			"specific_trap": 0,
+			"uptime":        uint(300),
+			"trap_oid":      "1.3.6.1.6.3.1.1.5.3",
+			"trap":          "linkDown",
+			"varbinds": map[string]interface{}{
+				"ifIndex.2": 2,
+			},
+		}, e.Body)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for trap")
+	}
+}
+
+func TestSNMPTrapInputV1EnterpriseSpecific(t *testing.T) {
+	input, fakeOutput := newTestSNMPTrapInput(t, nil)
+	sender := newTestSender(t, input, func(sender *gosnmp.GoSNMP) {
+		sender.Version = gosnmp.Version1
+	})
+
+	_, err := sender.SendTrap(gosnmp.SnmpTrap{
+		Variables:    []gosnmp.SnmpPDU{{Name: ".1.3.6.1.4.1.99999.1.1.1", Type: gosnmp.Integer, Value: 1}},
+		Enterprise:   ".1.3.6.1.4.1.99999",
+		AgentAddress: "10.0.0.1",
+		GenericTrap:  6,
+		SpecificTrap: 7,
+	})
+	require.NoError(t, err)
+
+	select {
+	case e := <-fakeOutput.Received:
+		body := e.Body.(map[string]interface{})
+		require.Equal(t, "1.3.6.1.4.1.99999.0.7", body["trap_oid"])
+		require.Equal(t, "testTrapMIB.0.7", body["trap"])
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for trap")
+	}
+}
+
+func TestSNMPTrapInputCommunity(t *testing.T) {
+	input, fakeOutput := newTestSNMPTrapInput(t, func(cfg *SNMPTrapInputConfig) {
+		cfg.Community = "secret"
+	})
+
+	wrong := newTestSender(t, input, nil)
+	_, err := wrong.SendTrap(testTrap)
+	require.NoError(t, err)
+	expectNoEntry(t, fakeOutput)
+
+	right := newTestSender(t, input, func(sender *gosnmp.GoSNMP) {
+		sender.Community = "secret"
+	})
+	_, err = right.SendTrap(testTrap)
+	require.NoError(t, err)
+	expectTrap(t, fakeOutput, "v2c", nil)
+}
+
+func TestSNMPTrapInputV3(t *testing.T) {
+	input, fakeOutput := newTestSNMPTrapInput(t, func(cfg *SNMPTrapInputConfig) {
+		cfg.V3 = &SNMPv3Config{
+			User:            "collector",
+			EngineID:        testEngineID,
+			AuthProtocol:    "sha",
+			AuthPassword:    "authpassword",
+			PrivacyProtocol: "aes",
+			PrivacyPassword: "privacypassword",
+		}
+	})
+
+	v3 := func(authPassword string) func(*gosnmp.GoSNMP) {
+		return func(sender *gosnmp.GoSNMP) {
+			sender.Version = gosnmp.Version3
+			sender.SecurityModel = gosnmp.UserSecurityModel
+			sender.MsgFlags = gosnmp.AuthPriv
+			sender.SecurityParameters = newTestV3Params(authPassword)
+		}
+	}
+
+	wrong := newTestSender(t, input, v3("wrongpassword"))
+	_, err := wrong.SendTrap(testTrap)
+	require.NoError(t, err)
+	expectNoEntry(t, fakeOutput)
+
+	right := newTestSender(t, input, v3("authpassword"))
+	_, err = right.SendTrap(testTrap)
+	require.NoError(t, err)
+	expectTrap(t, fakeOutput, "v3", map[string]interface{}{"user": "collector"})
+
+	// SNMPv2c traps are still received when SNMPv3 is configured
+	v2c := newTestSender(t, input, nil)
+	_, err = v2c.SendTrap(testTrap)
+	require.NoError(t, err)
+	expectTrap(t, fakeOutput, "v2c", nil)
+}
+
+func TestSNMPTrapInputV3NotConfigured(t *testing.T) {
+	input, fakeOutput := newTestSNMPTrapInput(t, nil)
+	sender := newTestSender(t, input, func(sender *gosnmp.GoSNMP) {
+		sender.Version = gosnmp.Version3
+		sender.SecurityModel = gosnmp.UserSecurityModel
+		sender.MsgFlags = gosnmp.AuthPriv
+		sender.SecurityParameters = newTestV3Params("authpassword")
+	})
+
+	_, err := sender.SendTrap(testTrap)
+	require.NoError(t, err)
+	expectNoEntry(t, fakeOutput)
+}
+
+func TestSNMPTrapInputInform(t *testing.T) {
+	input, fakeOutput := newTestSNMPTrapInput(t, nil)
+	sender := newTestSender(t, input, nil)
+
+	inform := testTrap
+	inform.IsInform = true
+	result, err := sender.SendTrap(inform)
+	require.NoError(t, err)
+	require.Equal(t, gosnmp.GetResponse, result.PDUType)
+
+	expectTrap(t, fakeOutput, "v2c", map[string]interface{}{"pdu_type": "inform"})
+}
+
+func TestParseValue(t *testing.T) {
+	input, _ := newTestSNMPTrapInput(t, nil)
+
+	require.Equal(t, "eth0", input.parseValue(gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte("eth0")}))
+	require.Equal(t, "00ff10", input.parseValue(gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{0x00, 0xff, 0x10}}))
+	require.Equal(t, "linkUp", input.parseValue(gosnmp.SnmpPDU{Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.4"}))
+	require.Equal(t, "10.0.0.1", input.parseValue(gosnmp.SnmpPDU{Type: gosnmp.IPAddress, Value: "10.0.0.1"}))
+	require.Equal(t, uint64(42), input.parseValue(gosnmp.SnmpPDU{Type: gosnmp.Counter64, Value: uint64(42)}))
+	require.Nil(t, input.parseValue(gosnmp.SnmpPDU{Type: gosnmp.NoSuchInstance}))
+}
@@ -17,6 +17,7 @@ package stdin
 import (
 	"bufio"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"os"
 	"sync"
@@ -28,6 +29,18 @@ import (
 	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 )
 
+// Framings of the records written to stdin
+const (
+	framingNewline   = "newline"
+	framingNull      = "null"
+	framingDelimiter = "delimiter"
+	framingVarint    = "varint"
+	framingUint32    = "uint32"
+
+	// defaultMaxLogSize is the max size of a record if MaxLogSize is not set
+	defaultMaxLogSize = 1024 * 1024
+)
+
 func init() {
 	operator.Register("stdin", func() operator.Builder { return NewStdinInputConfig("") })
 }
@@ -36,12 +49,18 @@ func init() {
 func NewStdinInputConfig(operatorID string) *StdinInputConfig {
 	return &StdinInputConfig{
 		InputConfig: helper.NewInputConfig(operatorID, "stdin"),
+		Framing:     framingNewline,
+		MaxLogSize:  defaultMaxLogSize,
 	}
 }
 
 // StdinInputConfig is the configuration of a stdin input operator.
 type StdinInputConfig struct {
 	helper.InputConfig `yaml:",inline"`
+
+	Framing    string          `mapstructure:"framing,omitempty"      json:"framing,omitempty"      yaml:"framing,omitempty"`
+	Delimiter  string          `mapstructure:"delimiter,omitempty"    json:"delimiter,omitempty"    yaml:"delimiter,omitempty"`
+	MaxLogSize helper.ByteSize `mapstructure:"max_log_size,omitempty" json:"max_log_size,omitempty" yaml:"max_log_size,omitempty"`
 }
 
 // Build will build a stdin input operator.
@@ -51,9 +70,38 @@ func (c *StdinInputConfig) Build(context operator.BuildContext) ([]operator.Oper
 		return nil, err
 	}
 
+	if c.MaxLogSize <= 0 {
+		return nil, fmt.Errorf("`max_log_size` must be positive")
+	}
+
+	if c.Delimiter != "" && c.Framing != framingDelimiter {
+		return nil, fmt.Errorf("`delimiter` can only be used with the `delimiter` framing")
+	}
+
+	var splitFunc bufio.SplitFunc
+	switch c.Framing {
+	case "", framingNewline:
+		splitFunc = bufio.ScanLines
+	case framingNull:
+		splitFunc = helper.NewDelimiterSplitFunc(0)
+	case framingDelimiter:
+		if len(c.Delimiter) != 1 {
+			return nil, fmt.Errorf("invalid delimiter '%s', which must be a single byte", c.Delimiter)
+		}
+		splitFunc = helper.NewDelimiterSplitFunc(c.Delimiter[0])
+	case framingVarint:
+		splitFunc = helper.NewVarintSplitFunc(int(c.MaxLogSize))
+	case framingUint32:
+		splitFunc = helper.NewUint32SplitFunc(int(c.MaxLogSize))
+	default:
+		return nil, fmt.Errorf("invalid framing '%s'", c.Framing)
+	}
+
 	stdinInput := &StdinInput{
 		InputOperator: inputOperator,
 		stdin:         os.Stdin,
+		splitFunc:     splitFunc,
+		maxLogSize:    int(c.MaxLogSize),
 	}
 	return []operator.Operator{stdinInput}, nil
 }
@@ -61,9 +109,11 @@ func (c *StdinInputConfig) Build(context operator.BuildContext) ([]operator.Oper
 // StdinInput is an operator that reads input from stdin
 type StdinInput struct {
 	helper.InputOperator
-	wg     sync.WaitGroup
-	cancel context.CancelFunc
-	stdin  *os.File
+	wg         sync.WaitGroup
+	cancel     context.CancelFunc
+	stdin      *os.File
+	splitFunc  bufio.SplitFunc
+	maxLogSize int
 }
 
 // Start will start generating log entries.
@@ -81,7 +131,10 @@ func (g *StdinInput) Start(_ operator.Persister) error {
 		return nil
 	}
 
+	// The buffer must hold a whole record, and the prefix of a length prefixed record
 	scanner := bufio.NewScanner(g.stdin)
+	scanner.Buffer(make([]byte, 0, 4096), g.maxLogSize+binary.MaxVarintLen64)
+	scanner.Split(g.splitFunc)
 
 	g.wg.Add(1)
 	go func() {
@@ -95,7 +148,7 @@ func (g *StdinInput) Start(_ operator.Persister) error {
 
 			if ok := scanner.Scan(); !ok {
 				if err := scanner.Err(); err != nil {
-					g.Errorf("Scanning failed", zap.Error(err))
+					g.Errorw("Scanning failed", zap.Error(err))
 				}
 				g.Infow("Stdin has been closed")
 				return
@@ -15,8 +15,10 @@
 package stdin
 
 import (
+	"encoding/binary"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -47,3 +49,202 @@ func TestStdin(t *testing.T) {
 	w.Close()
 	fake.ExpectBody(t, "test")
 }
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*StdinInputConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *StdinInputConfig) {},
+			false,
+		},
+		{
+			"Null",
+			func(cfg *StdinInputConfig) {
+				cfg.Framing = "null"
+			},
+			false,
+		},
+		{
+			"Delimiter",
+			func(cfg *StdinInputConfig) {
+				cfg.Framing = "delimiter"
+				cfg.Delimiter = "|"
+			},
+			false,
+		},
+		{
+			"MissingDelimiter",
+			func(cfg *StdinInputConfig) {
+				cfg.Framing = "delimiter"
+			},
+			true,
+		},
+		{
+			"MultiByteDelimiter",
+			func(cfg *StdinInputConfig) {
+				cfg.Framing = "delimiter"
+				cfg.Delimiter = "||"
+			},
+			true,
+		},
+		{
+			"DelimiterWithoutDelimiterFraming",
+			func(cfg *StdinInputConfig) {
+				cfg.Delimiter = "|"
+			},
+			true,
+		},
+		{
+			"Varint",
+			func(cfg *StdinInputConfig) {
+				cfg.Framing = "varint"
+			},
+			false,
+		},
+		{
+			"Uint32",
+			func(cfg *StdinInputConfig) {
+				cfg.Framing = "uint32"
+			},
+			false,
+		},
+		{
+			"InvalidFraming",
+			func(cfg *StdinInputConfig) {
+				cfg.Framing = "octet_counting"
+			},
+			true,
+		},
+		{
+			"ZeroMaxLogSize",
+			func(cfg *StdinInputConfig) {
+				cfg.MaxLogSize = 0
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewStdinInputConfig("")
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func uint32Record(record string) string {
+	prefix := make([]byte, 4)
+	binary.BigEndian.PutUint32(prefix, uint32(len(record)))
+	return string(prefix) + record
+}
+
+func varintRecord(record string) string {
+	prefix := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(prefix, uint64(len(record)))
+	return string(prefix[:n]) + record
+}
+
+func TestStdinFraming(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*StdinInputConfig)
+		input    string
+		expected []string
+	}{
+		{
+			"Newline",
+			func(cfg *StdinInputConfig) {},
+			"first\r\nsecond\n",
+			[]string{"first", "second"},
+		},
+		{
+			"Null",
+			func(cfg *StdinInputConfig) {
+				cfg.Framing = "null"
+			},
+			"./a file\n\x00./b\x00",
+			[]string{"./a file\n", "./b"},
+		},
+		{
+			"Delimiter",
+			func(cfg *StdinInputConfig) {
+				cfg.Framing = "delimiter"
+				cfg.Delimiter = "\x1e"
+			},
+			"first\x1esecond",
+			[]string{"first", "second"},
+		},
+		{
+			"Varint",
+			func(cfg *StdinInputConfig) {
+				cfg.Framing = "varint"
+			},
+			varintRecord("first\n") + varintRecord("second"),
+			[]string{"first\n", "second"},
+		},
+		{
+			"Uint32",
+			func(cfg *StdinInputConfig) {
+				cfg.Framing = "uint32"
+			},
+			uint32Record("\x00binary") + uint32Record("second"),
+			[]string{"\x00binary", "second"},
+		},
+		{
+			"MaxLogSize",
+			func(cfg *StdinInputConfig) {
+				cfg.Framing = "uint32"
+				cfg.MaxLogSize = 8
+			},
+			uint32Record("first") + uint32Record("too long record") + uint32Record("third"),
+			[]string{"first"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewStdinInputConfig("")
+			cfg.OutputIDs = []string{"fake"}
+			tc.modify(cfg)
+
+			op, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+
+			fake := testutil.NewFakeOutput(t)
+			require.NoError(t, op[0].SetOutputs([]operator.Operator{fake}))
+
+			r, w, err := os.Pipe()
+			require.NoError(t, err)
+
+			stdin := op[0].(*StdinInput)
+			stdin.stdin = r
+
+			require.NoError(t, stdin.Start(testutil.NewMockPersister("test")))
+			defer stdin.Stop()
+
+			_, err = w.WriteString(tc.input)
+			require.NoError(t, err)
+			w.Close()
+
+			for _, expected := range tc.expected {
+				fake.ExpectBody(t, expected)
+			}
+			select {
+			case e := <-fake.Received:
+				require.FailNow(t, "Unexpected entry", "%v", e)
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	}
+}
@@ -127,6 +127,7 @@ udp:
 protocol: rfc5424
 tcp:
   listen_address: localhost:1234
+  framing: octet_counting
   tls:
     ca_file: /tmp/test.ca 
 `
@@ -134,5 +135,6 @@ tcp:
 	require.NoError(t, err)
 	require.Equal(t, syslog.RFC5424, cfg.Protocol)
 	require.Equal(t, "localhost:1234", cfg.Tcp.ListenAddress)
+	require.Equal(t, "octet_counting", cfg.Tcp.Framing)
 	require.Equal(t, "/tmp/test.ca", cfg.Tcp.TLS.CAFile)
 }
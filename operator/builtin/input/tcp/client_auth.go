@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	// ClientAuthRequire requires every client to present a certificate signed by the client CA
+	ClientAuthRequire = "require"
+
+	// ClientAuthRequest verifies a client certificate when one is presented, but accepts clients without one
+	ClientAuthRequest = "request"
+)
+
+// ClientAuthConfig is the configuration of client certificate authorization.
+type ClientAuthConfig struct {
+	Mode         string   `mapstructure:"mode,omitempty"          json:"mode,omitempty"          yaml:"mode,omitempty"`
+	AllowedNames []string `mapstructure:"allowed_names,omitempty" json:"allowed_names,omitempty" yaml:"allowed_names,omitempty"`
+}
+
+// apply will configure a server tls config to authorize client certificates.
+func (c ClientAuthConfig) apply(config *tls.Config) error {
+	if config.ClientCAs == nil {
+		return fmt.Errorf("missing required parameter 'tls.client_ca_file'")
+	}
+
+	switch c.Mode {
+	case "", ClientAuthRequire:
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	case ClientAuthRequest:
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	default:
+		return fmt.Errorf("invalid client_auth mode '%s'", c.Mode)
+	}
+
+	if len(c.AllowedNames) == 0 {
+		return nil
+	}
+
+	allowed := make(map[string]bool, len(c.AllowedNames))
+	for _, name := range c.AllowedNames {
+		allowed[name] = true
+	}
+
+	config.VerifyPeerCertificate = func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(verifiedChains) == 0 || len(verifiedChains[0]) == 0 {
+			return fmt.Errorf("client certificate required by allowed_names")
+		}
+
+		leaf := verifiedChains[0][0]
+		for _, name := range certificateNames(leaf) {
+			if allowed[name] {
+				return nil
+			}
+		}
+		return fmt.Errorf("client certificate '%s' is not allowed", leaf.Subject)
+	}
+	return nil
+}
+
+// certificateNames will return the common name and subject alternative names of a certificate.
+func certificateNames(cert *x509.Certificate) []string {
+	names := make([]string, 0, 1+len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.IPAddresses)+len(cert.URIs))
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+	return names
+}
+
+// clientCertificateAttributes will return the attributes describing the certificate a client presented,
+// or nil if no certificate was presented.
+func clientCertificateAttributes(state tls.ConnectionState) map[string]string {
+	if len(state.PeerCertificates) == 0 {
+		return nil
+	}
+
+	cert := state.PeerCertificates[0]
+	fingerprint := sha256.Sum256(cert.Raw)
+	return map[string]string{
+		"tls.client.subject":       cert.Subject.String(),
+		"tls.client.issuer":        cert.Issuer.String(),
+		"tls.client.hash.sha256":   strings.ToUpper(hex.EncodeToString(fingerprint[:])),
+		"tls.client.serial_number": cert.SerialNumber.String(),
+	}
+}
@@ -0,0 +1,346 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtls"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func writeCertificate(t *testing.T, dir, name string, template *x509.Certificate, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parentCert, parentKey := template, interface{}(key)
+	if parent != nil {
+		parentCert = parent.Leaf
+		parentKey = parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0600))
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	cert.Leaf, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+type testCertificates struct {
+	dir     string
+	ca      tls.Certificate
+	client  tls.Certificate
+	service tls.Certificate
+	other   tls.Certificate
+}
+
+func newTestCertificates(t *testing.T) testCertificates {
+	dir := t.TempDir()
+	ca := writeCertificate(t, dir, "ca", &x509.Certificate{
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	otherCA := writeCertificate(t, dir, "other-ca", &x509.Certificate{
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	writeCertificate(t, dir, "server", &x509.Certificate{
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+
+	return testCertificates{
+		dir: dir,
+		ca:  ca,
+		client: writeCertificate(t, dir, "client", &x509.Certificate{
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}, &ca),
+		service: writeCertificate(t, dir, "service", &x509.Certificate{
+			DNSNames:    []string{"shipper.example.com"},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}, &ca),
+		other: writeCertificate(t, dir, "client", &x509.Certificate{
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}, &otherCA),
+	}
+}
+
+func (c testCertificates) tlsConfig(clientCA bool) *helper.TLSServerConfig {
+	setting := &configtls.TLSServerSetting{
+		TLSSetting: configtls.TLSSetting{
+			CertFile: filepath.Join(c.dir, "server.crt"),
+			KeyFile:  filepath.Join(c.dir, "server.key"),
+		},
+	}
+	if clientCA {
+		setting.ClientCAFile = filepath.Join(c.dir, "ca.crt")
+	}
+	return helper.NewTLSServerConfig(setting)
+}
+
+func TestClientAuthBuild(t *testing.T) {
+	certs := newTestCertificates(t)
+
+	cases := []struct {
+		name      string
+		modify    func(*TCPInputConfig)
+		expectErr bool
+	}{
+		{
+			"Require",
+			func(cfg *TCPInputConfig) {
+				cfg.ClientAuth = &ClientAuthConfig{Mode: ClientAuthRequire}
+			},
+			false,
+		},
+		{
+			"Request",
+			func(cfg *TCPInputConfig) {
+				cfg.ClientAuth = &ClientAuthConfig{Mode: ClientAuthRequest, AllowedNames: []string{"client"}}
+			},
+			false,
+		},
+		{
+			"InvalidMode",
+			func(cfg *TCPInputConfig) {
+				cfg.ClientAuth = &ClientAuthConfig{Mode: "optional"}
+			},
+			true,
+		},
+		{
+			"MissingClientCA",
+			func(cfg *TCPInputConfig) {
+				cfg.TLS = certs.tlsConfig(false)
+				cfg.ClientAuth = &ClientAuthConfig{}
+			},
+			true,
+		},
+		{
+			"MissingTLS",
+			func(cfg *TCPInputConfig) {
+				cfg.TLS = nil
+				cfg.ClientAuth = &ClientAuthConfig{}
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewTCPInputConfig("test_id")
+			cfg.ListenAddress = ":0"
+			cfg.TLS = certs.tlsConfig(true)
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestClientAuthConfigUnmarshal(t *testing.T) {
+	expect := &ClientAuthConfig{
+		Mode:         ClientAuthRequest,
+		AllowedNames: []string{"client", "shipper.example.com"},
+	}
+
+	input := map[string]interface{}{
+		"mode":          "request",
+		"allowed_names": []interface{}{"client", "shipper.example.com"},
+	}
+
+	var actual ClientAuthConfig
+	require.NoError(t, helper.UnmarshalMapstructure(input, &actual))
+	require.Equal(t, expect, &actual)
+}
+
+func TestTCPInputClientAuth(t *testing.T) {
+	certs := newTestCertificates(t)
+
+	cases := []struct {
+		name        string
+		clientAuth  ClientAuthConfig
+		certificate *tls.Certificate
+		expectAllow bool
+	}{
+		{
+			"Require",
+			ClientAuthConfig{},
+			&certs.client,
+			true,
+		},
+		{
+			"RequireWithoutCertificate",
+			ClientAuthConfig{},
+			nil,
+			false,
+		},
+		{
+			"RequireUntrustedCertificate",
+			ClientAuthConfig{},
+			&certs.other,
+			false,
+		},
+		{
+			"RequestWithoutCertificate",
+			ClientAuthConfig{Mode: ClientAuthRequest},
+			nil,
+			true,
+		},
+		{
+			"AllowedCommonName",
+			ClientAuthConfig{AllowedNames: []string{"client"}},
+			&certs.client,
+			true,
+		},
+		{
+			"AllowedDNSName",
+			ClientAuthConfig{AllowedNames: []string{"shipper.example.com"}},
+			&certs.service,
+			true,
+		},
+		{
+			"NotAllowed",
+			ClientAuthConfig{AllowedNames: []string{"shipper.example.com"}},
+			&certs.client,
+			false,
+		},
+		{
+			"RequestAllowedWithoutCertificate",
+			ClientAuthConfig{Mode: ClientAuthRequest, AllowedNames: []string{"client"}},
+			nil,
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewTCPInputConfig("test_id")
+			cfg.ListenAddress = "127.0.0.1:0"
+			cfg.TLS = certs.tlsConfig(true)
+			clientAuth := tc.clientAuth
+			cfg.ClientAuth = &clientAuth
+			cfg.OutputIDs = []string{"fake"}
+
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			tcpInput := ops[0].(*TCPInput)
+
+			fakeOutput := testutil.NewFakeOutput(t)
+			require.NoError(t, tcpInput.SetOutputs([]operator.Operator{fakeOutput}))
+			require.NoError(t, tcpInput.Start(testutil.NewMockPersister("test")))
+			defer tcpInput.Stop()
+
+			roots := x509.NewCertPool()
+			roots.AddCert(certs.ca.Leaf)
+			clientConfig := &tls.Config{
+				RootCAs:    roots,
+				MinVersion: tls.VersionTLS12,
+			}
+			if tc.certificate != nil {
+				clientConfig.Certificates = []tls.Certificate{*tc.certificate}
+			}
+
+			conn, err := tls.Dial("tcp", tcpInput.listener.Addr().String(), clientConfig)
+			if err == nil {
+				defer conn.Close()
+				_, err = conn.Write([]byte("message1\nmessage2\n"))
+			}
+
+			if !tc.expectAllow {
+				if err == nil {
+					// With TLS 1.3 the client learns that its certificate was rejected on its first read
+					require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+					_, err = conn.Read(make([]byte, 1))
+					require.Error(t, err)
+				}
+				select {
+				case e := <-fakeOutput.Received:
+					require.FailNow(t, "Unexpected entry: %s", e)
+				case <-time.After(100 * time.Millisecond):
+				}
+				return
+			}
+			require.NoError(t, err)
+
+			for _, body := range []string{"message1", "message2"} {
+				e := expectEntry(t, fakeOutput)
+				require.Equal(t, body, e.Body)
+
+				if tc.certificate == nil {
+					require.Empty(t, e.Attributes)
+					continue
+				}
+				fingerprint := sha256.Sum256(tc.certificate.Leaf.Raw)
+				require.Equal(t, map[string]string{
+					"tls.client.subject":       "CN=" + tc.certificate.Leaf.Subject.CommonName,
+					"tls.client.issuer":        "CN=ca",
+					"tls.client.hash.sha256":   strings.ToUpper(hex.EncodeToString(fingerprint[:])),
+					"tls.client.serial_number": tc.certificate.Leaf.SerialNumber.String(),
+				}, e.Attributes)
+			}
+		})
+	}
+}
+
+func expectEntry(t *testing.T, output *testutil.FakeOutput) *entry.Entry {
+	select {
+	case e := <-output.Received:
+		return e
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+		return nil
+	}
+}
@@ -62,9 +62,11 @@ type TCPInputConfig struct {
 	MaxLogSize    helper.ByteSize         `mapstructure:"max_log_size,omitempty"          json:"max_log_size,omitempty"         yaml:"max_log_size,omitempty"`
 	ListenAddress string                  `mapstructure:"listen_address,omitempty"        json:"listen_address,omitempty"       yaml:"listen_address,omitempty"`
 	TLS           *helper.TLSServerConfig `mapstructure:"tls,omitempty"                   json:"tls,omitempty"                  yaml:"tls,omitempty"`
+	ClientAuth    *ClientAuthConfig       `mapstructure:"client_auth,omitempty"           json:"client_auth,omitempty"          yaml:"client_auth,omitempty"`
 	AddAttributes bool                    `mapstructure:"add_attributes,omitempty"        json:"add_attributes,omitempty"       yaml:"add_attributes,omitempty"`
 	Encoding      helper.EncodingConfig   `mapstructure:",squash,omitempty"               json:",inline,omitempty"              yaml:",inline,omitempty"`
 	Multiline     helper.MultilineConfig  `mapstructure:"multiline,omitempty"             json:"multiline,omitempty"            yaml:"multiline,omitempty"`
+	Framing       string                  `mapstructure:"framing,omitempty"               json:"framing,omitempty"              yaml:"framing,omitempty"`
 }
 
 // Build will build a tcp input operator.
@@ -103,6 +105,11 @@ func (c TCPInputConfig) Build(context operator.BuildContext) ([]operator.Operato
 		return nil, err
 	}
 
+	newSplitFunc, err := helper.NewFramingSplitFuncBuilder(c.Framing, c.Multiline, splitFunc)
+	if err != nil {
+		return nil, err
+	}
+
 	var resolver *helper.IPResolver = nil
 	if c.AddAttributes {
 		resolver = helper.NewIpResolver()
@@ -114,7 +121,7 @@ func (c TCPInputConfig) Build(context operator.BuildContext) ([]operator.Operato
 		MaxLogSize:    int(c.MaxLogSize),
 		addAttributes: c.AddAttributes,
 		encoding:      encoding,
-		splitFunc:     splitFunc,
+		newSplitFunc:  newSplitFunc,
 		backoff: backoff.Backoff{
 			Max: 3 * time.Second,
 		},
@@ -128,6 +135,15 @@ func (c TCPInputConfig) Build(context operator.BuildContext) ([]operator.Operato
 		}
 	}
 
+	if c.ClientAuth != nil {
+		if tcpInput.tls == nil {
+			return nil, fmt.Errorf("client_auth requires tls to be configured")
+		}
+		if err := c.ClientAuth.apply(tcpInput.tls); err != nil {
+			return nil, err
+		}
+	}
+
 	return []operator.Operator{tcpInput}, nil
 }
 
@@ -144,9 +160,9 @@ type TCPInput struct {
 	tls      *tls.Config
 	backoff  backoff.Backoff
 
-	encoding  helper.Encoding
-	splitFunc bufio.SplitFunc
-	resolver  *helper.IPResolver
+	encoding     helper.Encoding
+	newSplitFunc func() bufio.SplitFunc
+	resolver     *helper.IPResolver
 }
 
 // Start will start listening for log entries over tcp.
@@ -234,11 +250,20 @@ func (t *TCPInput) goHandleMessages(ctx context.Context, conn net.Conn, cancel c
 		defer t.wg.Done()
 		defer cancel()
 
+		var tlsAttributes map[string]string
+		if tlsConn, ok := conn.(*tls.Conn); ok {
+			if err := tlsConn.Handshake(); err != nil {
+				t.Debugw("TLS handshake failed", zap.Error(err), zap.String("remote_addr", conn.RemoteAddr().String()))
+				return
+			}
+			tlsAttributes = clientCertificateAttributes(tlsConn.ConnectionState())
+		}
+
 		buf := make([]byte, 0, t.MaxLogSize)
 		scanner := bufio.NewScanner(conn)
 		scanner.Buffer(buf, t.MaxLogSize)
 
-		scanner.Split(t.splitFunc)
+		scanner.Split(t.newSplitFunc())
 
 		for scanner.Scan() {
 			decoded, err := t.encoding.Decode(scanner.Bytes())
@@ -270,6 +295,10 @@ func (t *TCPInput) goHandleMessages(ctx context.Context, conn net.Conn, cancel c
 				}
 			}
 
+			for k, v := range tlsAttributes {
+				entry.AddAttribute(k, v)
+			}
+
 			t.Write(ctx, entry)
 		}
 		if err := scanner.Err(); err != nil {
@@ -315,6 +315,41 @@ func TestBuild(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"framing-octet-counting",
+			TCPInputConfig{
+				ListenAddress: "10.0.0.1:9000",
+				Framing:       "octet_counting",
+			},
+			false,
+		},
+		{
+			"framing-auto",
+			TCPInputConfig{
+				ListenAddress: "10.0.0.1:9000",
+				Framing:       "auto",
+			},
+			false,
+		},
+		{
+			"framing-invalid",
+			TCPInputConfig{
+				ListenAddress: "10.0.0.1:9000",
+				Framing:       "length",
+			},
+			true,
+		},
+		{
+			"framing-octet-counting-with-multiline",
+			TCPInputConfig{
+				ListenAddress: "10.0.0.1:9000",
+				Framing:       "octet_counting",
+				Multiline: helper.MultilineConfig{
+					LineStartPattern: "^<",
+				},
+			},
+			true,
+		},
 		{
 			"tls-enabled-with-no-such-file-error",
 			TCPInputConfig{
@@ -332,6 +367,10 @@ func TestBuild(t *testing.T) {
 			cfg.ListenAddress = tc.inputBody.ListenAddress
 			cfg.MaxLogSize = tc.inputBody.MaxLogSize
 			cfg.TLS = tc.inputBody.TLS
+			cfg.Framing = tc.inputBody.Framing
+			if tc.inputBody.Multiline.LineStartPattern != "" {
+				cfg.Multiline = tc.inputBody.Multiline
+			}
 			_, err := cfg.Build(testutil.NewBuildContext(t))
 			if tc.expectErr {
 				require.Error(t, err)
@@ -357,6 +396,60 @@ func TestTLSTcpInput(t *testing.T) {
 	t.Run("CarriageReturn", tlsTCPInputTest([]byte("message\r\n"), []string{"message"}))
 }
 
+func TestTcpInputFraming(t *testing.T) {
+	cases := []struct {
+		name     string
+		framing  string
+		inputs   []string
+		expected []string
+	}{
+		{
+			"OctetCounting",
+			helper.FramingOctetCounting,
+			[]string{"16 <34>1 first\nline17 <34>1 second line"},
+			[]string{"<34>1 first\nline", "<34>1 second line"},
+		},
+		{
+			"Auto",
+			helper.FramingAuto,
+			[]string{"16 <34>1 first\nline", "<34>1 second\n12 monkeys\n"},
+			[]string{"<34>1 first\nline", "<34>1 second", "12 monkeys"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewTCPInputConfig("test_id")
+			cfg.ListenAddress = ":0"
+			cfg.Framing = tc.framing
+
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			tcpInput := ops[0].(*TCPInput)
+
+			fakeOutput := testutil.NewFakeOutput(t)
+			tcpInput.InputOperator.OutputOperators = []operator.Operator{fakeOutput}
+
+			require.NoError(t, tcpInput.Start(testutil.NewMockPersister("test")))
+			defer tcpInput.Stop()
+
+			// Each input is written on its own connection, whose framing is detected separately
+			for i, input := range tc.inputs {
+				conn, err := net.Dial("tcp", tcpInput.listener.Addr().String())
+				require.NoError(t, err)
+				defer conn.Close()
+
+				_, err = conn.Write([]byte(input))
+				require.NoError(t, err)
+				fakeOutput.ExpectBody(t, tc.expected[i])
+			}
+			for _, expected := range tc.expected[len(tc.inputs):] {
+				fakeOutput.ExpectBody(t, expected)
+			}
+		})
+	}
+}
+
 func BenchmarkTcpInput(b *testing.B) {
 	cfg := NewTCPInputConfig("test_id")
 	cfg.ListenAddress = ":0"
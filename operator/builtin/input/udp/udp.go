@@ -22,6 +22,8 @@ import (
 	"net"
 	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -32,6 +34,12 @@ import (
 const (
 	// Maximum UDP packet size
 	MaxUDPSize = 64 * 1024
+
+	// DefaultQueueSize is the number of packets which can wait to be processed by workers
+	DefaultQueueSize = 10000
+
+	// dropReportInterval is how often the number of dropped packets is logged
+	dropReportInterval = 10 * time.Second
 )
 
 func init() {
@@ -47,6 +55,8 @@ func NewUDPInputConfig(operatorID string) *UDPInputConfig {
 			LineStartPattern: "",
 			LineEndPattern:   ".^", // Use never matching regex to not split data by default
 		},
+		Readers:   1,
+		QueueSize: DefaultQueueSize,
 	}
 }
 
@@ -54,10 +64,14 @@ func NewUDPInputConfig(operatorID string) *UDPInputConfig {
 type UDPInputConfig struct {
 	helper.InputConfig `yaml:",inline"`
 
-	ListenAddress string                 `mapstructure:"listen_address,omitempty"        json:"listen_address,omitempty"       yaml:"listen_address,omitempty"`
-	AddAttributes bool                   `mapstructure:"add_attributes,omitempty"        json:"add_attributes,omitempty"       yaml:"add_attributes,omitempty"`
-	Encoding      helper.EncodingConfig  `mapstructure:",squash,omitempty"               json:",inline,omitempty"              yaml:",inline,omitempty"`
-	Multiline     helper.MultilineConfig `mapstructure:"multiline,omitempty"             json:"multiline,omitempty"            yaml:"multiline,omitempty"`
+	ListenAddress     string                 `mapstructure:"listen_address,omitempty"        json:"listen_address,omitempty"       yaml:"listen_address,omitempty"`
+	AddAttributes     bool                   `mapstructure:"add_attributes,omitempty"        json:"add_attributes,omitempty"       yaml:"add_attributes,omitempty"`
+	Encoding          helper.EncodingConfig  `mapstructure:",squash,omitempty"               json:",inline,omitempty"              yaml:",inline,omitempty"`
+	Multiline         helper.MultilineConfig `mapstructure:"multiline,omitempty"             json:"multiline,omitempty"            yaml:"multiline,omitempty"`
+	Readers           int                    `mapstructure:"readers,omitempty"               json:"readers,omitempty"              yaml:"readers,omitempty"`
+	ReceiveBufferSize helper.ByteSize        `mapstructure:"receive_buffer_size,omitempty"   json:"receive_buffer_size,omitempty"  yaml:"receive_buffer_size,omitempty"`
+	Workers           int                    `mapstructure:"workers,omitempty"               json:"workers,omitempty"              yaml:"workers,omitempty"`
+	QueueSize         int                    `mapstructure:"queue_size,omitempty"            json:"queue_size,omitempty"           yaml:"queue_size,omitempty"`
 }
 
 // Build will build a udp input operator.
@@ -76,6 +90,22 @@ func (c UDPInputConfig) Build(context operator.BuildContext) ([]operator.Operato
 		return nil, fmt.Errorf("failed to resolve listen_address: %s", err)
 	}
 
+	if c.Readers <= 0 {
+		return nil, fmt.Errorf("`readers` must be positive")
+	}
+
+	if c.ReceiveBufferSize < 0 {
+		return nil, fmt.Errorf("`receive_buffer_size` must not be negative")
+	}
+
+	if c.Workers < 0 {
+		return nil, fmt.Errorf("`workers` must not be negative")
+	}
+
+	if c.Workers > 0 && c.QueueSize <= 0 {
+		return nil, fmt.Errorf("`queue_size` must be positive")
+	}
+
 	encoding, err := c.Encoding.Build(context)
 	if err != nil {
 		return nil, err
@@ -92,58 +122,130 @@ func (c UDPInputConfig) Build(context operator.BuildContext) ([]operator.Operato
 	}
 
 	udpInput := &UDPInput{
-		InputOperator: inputOperator,
-		address:       address,
-		buffer:        make([]byte, MaxUDPSize),
-		addAttributes: c.AddAttributes,
-		encoding:      encoding,
-		splitFunc:     splitFunc,
-		resolver:      resolver,
+		InputOperator:     inputOperator,
+		address:           address,
+		addAttributes:     c.AddAttributes,
+		readers:           c.Readers,
+		receiveBufferSize: int(c.ReceiveBufferSize),
+		workers:           c.Workers,
+		queueSize:         c.QueueSize,
+		encoding:          encoding,
+		splitFunc:         splitFunc,
+		resolver:          resolver,
 	}
 	return []operator.Operator{udpInput}, nil
 }
 
 // UDPInput is an operator that listens to a socket for log entries.
 type UDPInput struct {
-	buffer []byte
 	helper.InputOperator
-	address       *net.UDPAddr
-	addAttributes bool
-
-	connection net.PacketConn
-	cancel     context.CancelFunc
-	wg         sync.WaitGroup
+	address           *net.UDPAddr
+	addAttributes     bool
+	readers           int
+	receiveBufferSize int
+	workers           int
+	queueSize         int
+
+	connections []net.PacketConn
+	packets     chan packet
+	dropped     uint64
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
 
 	encoding  helper.Encoding
 	splitFunc bufio.SplitFunc
 	resolver  *helper.IPResolver
 }
 
+// packet is a message received by a reader and waiting to be processed by a worker.
+type packet struct {
+	message    []byte
+	remoteAddr net.Addr
+}
+
 // Start will start listening for messages on a socket.
 func (u *UDPInput) Start(persister operator.Persister) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	u.cancel = cancel
 
-	conn, err := net.ListenUDP("udp", u.address)
+	connections, err := u.listen(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to open connection: %s", err)
 	}
-	u.connection = conn
+	u.connections = connections
+
+	if u.workers > 0 {
+		u.packets = make(chan packet, u.queueSize)
+		for i := 0; i < u.workers; i++ {
+			u.goProcessPackets(ctx)
+		}
+		u.goReportDrops(ctx)
+	}
 
-	u.goHandleMessages(ctx)
+	for i := 0; i < u.readers; i++ {
+		u.goHandleMessages(ctx, u.connections[i%len(u.connections)])
+	}
 	return nil
 }
 
+// listen will open a socket for each reader if the platform supports SO_REUSEPORT,
+// and otherwise a single socket which is shared by the readers.
+func (u *UDPInput) listen(ctx context.Context) ([]net.PacketConn, error) {
+	count := 1
+	if reusePortSupported {
+		count = u.readers
+	}
+
+	address := u.address.String()
+	connections := make([]net.PacketConn, 0, count)
+	for i := 0; i < count; i++ {
+		conn, err := u.listenPacket(ctx, address, count > 1)
+		if err != nil {
+			for _, c := range connections {
+				c.Close()
+			}
+			return nil, err
+		}
+
+		// If the port is chosen by the system, the other sockets must share it
+		address = conn.LocalAddr().String()
+		connections = append(connections, conn)
+	}
+	return connections, nil
+}
+
+// listenPacket will open a socket with the configured receive buffer size.
+func (u *UDPInput) listenPacket(ctx context.Context, address string, reusePort bool) (net.PacketConn, error) {
+	listenConfig := net.ListenConfig{}
+	if reusePort {
+		listenConfig.Control = reusePortControl
+	}
+
+	conn, err := listenConfig.ListenPacket(ctx, "udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.receiveBufferSize > 0 {
+		if err := conn.(*net.UDPConn).SetReadBuffer(u.receiveBufferSize); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("set receive buffer size: %s", err)
+		}
+	}
+	return conn, nil
+}
+
 // goHandleMessages will handle messages from a udp connection.
-func (u *UDPInput) goHandleMessages(ctx context.Context) {
+func (u *UDPInput) goHandleMessages(ctx context.Context, conn net.PacketConn) {
 	u.wg.Add(1)
 
 	go func() {
 		defer u.wg.Done()
 
-		buf := make([]byte, 0, MaxUDPSize)
+		buffer := make([]byte, MaxUDPSize)
+		scanBuf := make([]byte, 0, MaxUDPSize)
 		for {
-			message, remoteAddr, err := u.readMessage()
+			message, remoteAddr, err := readMessage(conn, buffer)
 			if err != nil {
 				select {
 				case <-ctx.Done():
@@ -154,69 +256,138 @@ func (u *UDPInput) goHandleMessages(ctx context.Context) {
 				break
 			}
 
-			scanner := bufio.NewScanner(bytes.NewReader(message))
-			scanner.Buffer(buf, MaxUDPSize)
+			if u.packets == nil {
+				u.processMessage(ctx, conn, message, remoteAddr, scanBuf)
+				continue
+			}
 
-			scanner.Split(u.splitFunc)
+			select {
+			case u.packets <- packet{message: append([]byte(nil), message...), remoteAddr: remoteAddr}:
+			default:
+				atomic.AddUint64(&u.dropped, 1)
+			}
+		}
+	}()
+}
 
-			for scanner.Scan() {
-				decoded, err := u.encoding.Decode(scanner.Bytes())
-				if err != nil {
-					u.Errorw("Failed to decode data", zap.Error(err))
-					continue
-				}
+// goProcessPackets will process packets queued by the readers.
+func (u *UDPInput) goProcessPackets(ctx context.Context) {
+	u.wg.Add(1)
 
-				entry, err := u.NewEntry(decoded)
-				if err != nil {
-					u.Errorw("Failed to create entry", zap.Error(err))
-					continue
-				}
+	go func() {
+		defer u.wg.Done()
 
-				if u.addAttributes {
-					entry.AddAttribute("net.transport", "IP.UDP")
-					if addr, ok := u.connection.LocalAddr().(*net.UDPAddr); ok {
-						ip := addr.IP.String()
-						entry.AddAttribute("net.host.ip", addr.IP.String())
-						entry.AddAttribute("net.host.port", strconv.FormatInt(int64(addr.Port), 10))
-						entry.AddAttribute("net.host.name", u.resolver.GetHostFromIp(ip))
-					}
-
-					if addr, ok := remoteAddr.(*net.UDPAddr); ok {
-						ip := addr.IP.String()
-						entry.AddAttribute("net.peer.ip", ip)
-						entry.AddAttribute("net.peer.port", strconv.FormatInt(int64(addr.Port), 10))
-						entry.AddAttribute("net.peer.name", u.resolver.GetHostFromIp(ip))
-					}
+		scanBuf := make([]byte, 0, MaxUDPSize)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case p := <-u.packets:
+				u.processMessage(ctx, u.connections[0], p.message, p.remoteAddr, scanBuf)
+			}
+		}
+	}()
+}
+
+// goReportDrops will periodically log the number of packets dropped because the queue was full.
+func (u *UDPInput) goReportDrops(ctx context.Context) {
+	u.wg.Add(1)
+
+	go func() {
+		defer u.wg.Done()
+
+		ticker := time.NewTicker(dropReportInterval)
+		defer ticker.Stop()
+
+		var reported uint64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				dropped := u.Dropped()
+				if dropped > reported {
+					u.Warnw("Dropped packets because the processing queue was full", "dropped", dropped-reported, "total_dropped", dropped)
+					reported = dropped
 				}
+			}
+		}
+	}()
+}
+
+// Dropped returns the number of packets dropped because the processing queue was full.
+func (u *UDPInput) Dropped() uint64 {
+	return atomic.LoadUint64(&u.dropped)
+}
+
+// processMessage will create and write entries from a message.
+func (u *UDPInput) processMessage(ctx context.Context, conn net.PacketConn, message []byte, remoteAddr net.Addr, scanBuf []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(message))
+	scanner.Buffer(scanBuf, MaxUDPSize)
+
+	scanner.Split(u.splitFunc)
+
+	for scanner.Scan() {
+		decoded, err := u.encoding.Decode(scanner.Bytes())
+		if err != nil {
+			u.Errorw("Failed to decode data", zap.Error(err))
+			continue
+		}
 
-				u.Write(ctx, entry)
+		entry, err := u.NewEntry(decoded)
+		if err != nil {
+			u.Errorw("Failed to create entry", zap.Error(err))
+			continue
+		}
+
+		if u.addAttributes {
+			entry.AddAttribute("net.transport", "IP.UDP")
+			if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+				ip := addr.IP.String()
+				entry.AddAttribute("net.host.ip", addr.IP.String())
+				entry.AddAttribute("net.host.port", strconv.FormatInt(int64(addr.Port), 10))
+				entry.AddAttribute("net.host.name", u.resolver.GetHostFromIp(ip))
 			}
-			if err := scanner.Err(); err != nil {
-				u.Errorw("Scanner error", zap.Error(err))
+
+			if addr, ok := remoteAddr.(*net.UDPAddr); ok {
+				ip := addr.IP.String()
+				entry.AddAttribute("net.peer.ip", ip)
+				entry.AddAttribute("net.peer.port", strconv.FormatInt(int64(addr.Port), 10))
+				entry.AddAttribute("net.peer.name", u.resolver.GetHostFromIp(ip))
 			}
 		}
-	}()
+
+		u.Write(ctx, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		u.Errorw("Scanner error", zap.Error(err))
+	}
 }
 
 // readMessage will read log messages from the connection.
-func (u *UDPInput) readMessage() ([]byte, net.Addr, error) {
-	n, addr, err := u.connection.ReadFrom(u.buffer)
+func readMessage(conn net.PacketConn, buffer []byte) ([]byte, net.Addr, error) {
+	n, addr, err := conn.ReadFrom(buffer)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Remove trailing characters and NULs
-	for ; (n > 0) && (u.buffer[n-1] < 32); n-- {
+	for ; (n > 0) && (buffer[n-1] < 32); n-- {
 	}
 
-	return u.buffer[:n], addr, nil
+	return buffer[:n], addr, nil
 }
 
 // Stop will stop listening for udp messages.
 func (u *UDPInput) Stop() error {
 	u.cancel()
-	u.connection.Close()
+	for _, conn := range u.connections {
+		conn.Close()
+	}
 	u.wg.Wait()
+	if dropped := u.Dropped(); dropped > 0 {
+		u.Warnw("Dropped packets because the processing queue was full", "total_dropped", dropped)
+	}
 	if u.resolver != nil {
 		u.resolver.Stop()
 	}
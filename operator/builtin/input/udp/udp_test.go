@@ -52,7 +52,7 @@ func udpInputTest(input []byte, expected []string) func(t *testing.T) {
 		require.NoError(t, err)
 		defer udpInput.Stop()
 
-		conn, err := net.Dial("udp", udpInput.connection.LocalAddr().String())
+		conn, err := net.Dial("udp", udpInput.connections[0].LocalAddr().String())
 		require.NoError(t, err)
 		defer conn.Close()
 
@@ -102,7 +102,7 @@ func udpInputAttributesTest(input []byte, expected []string) func(t *testing.T)
 		require.NoError(t, err)
 		defer udpInput.Stop()
 
-		conn, err := net.Dial("udp", udpInput.connection.LocalAddr().String())
+		conn, err := net.Dial("udp", udpInput.connections[0].LocalAddr().String())
 		require.NoError(t, err)
 		defer conn.Close()
 
@@ -115,8 +115,8 @@ func udpInputAttributesTest(input []byte, expected []string) func(t *testing.T)
 				expectedAttributes := map[string]string{
 					"net.transport": "IP.UDP",
 				}
-				// LocalAddr for udpInput.connection is a server address
-				if addr, ok := udpInput.connection.LocalAddr().(*net.UDPAddr); ok {
+				// LocalAddr for udpInput.connections is a server address
+				if addr, ok := udpInput.connections[0].LocalAddr().(*net.UDPAddr); ok {
 					ip := addr.IP.String()
 					expectedAttributes["net.host.ip"] = addr.IP.String()
 					expectedAttributes["net.host.port"] = strconv.FormatInt(int64(addr.Port), 10)
@@ -159,6 +159,191 @@ func TestUDPInputAttributes(t *testing.T) {
 	t.Run("NewlineInMessage", udpInputAttributesTest([]byte("message1\nmessage2\n"), []string{"message1\nmessage2"}))
 }
 
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*UDPInputConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *UDPInputConfig) {},
+			false,
+		},
+		{
+			"Concurrent",
+			func(cfg *UDPInputConfig) {
+				cfg.Readers = 4
+				cfg.Workers = 8
+				cfg.ReceiveBufferSize = 4 * 1024 * 1024
+			},
+			false,
+		},
+		{
+			"MissingListenAddress",
+			func(cfg *UDPInputConfig) {
+				cfg.ListenAddress = ""
+			},
+			true,
+		},
+		{
+			"ZeroReaders",
+			func(cfg *UDPInputConfig) {
+				cfg.Readers = 0
+			},
+			true,
+		},
+		{
+			"NegativeReceiveBufferSize",
+			func(cfg *UDPInputConfig) {
+				cfg.ReceiveBufferSize = -1
+			},
+			true,
+		},
+		{
+			"NegativeWorkers",
+			func(cfg *UDPInputConfig) {
+				cfg.Workers = -1
+			},
+			true,
+		},
+		{
+			"WorkersWithoutQueue",
+			func(cfg *UDPInputConfig) {
+				cfg.Workers = 2
+				cfg.QueueSize = 0
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewUDPInputConfig("test_input")
+			cfg.ListenAddress = ":0"
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestUDPInputConcurrent(t *testing.T) {
+	cases := []struct {
+		name    string
+		readers int
+		workers int
+	}{
+		{"Readers", 4, 0},
+		{"Workers", 1, 4},
+		{"ReadersAndWorkers", 4, 4},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewUDPInputConfig("test_input")
+			cfg.ListenAddress = "127.0.0.1:0"
+			cfg.Readers = tc.readers
+			cfg.Workers = tc.workers
+			cfg.ReceiveBufferSize = 1024 * 1024
+			cfg.OutputIDs = []string{"fake"}
+
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			udpInput := ops[0].(*UDPInput)
+
+			fakeOutput := testutil.NewFakeOutput(t)
+			require.NoError(t, udpInput.SetOutputs([]operator.Operator{fakeOutput}))
+			require.NoError(t, udpInput.Start(testutil.NewMockPersister("test")))
+			defer udpInput.Stop()
+
+			if reusePortSupported {
+				require.Len(t, udpInput.connections, tc.readers)
+			}
+			for _, conn := range udpInput.connections {
+				require.Equal(t, udpInput.connections[0].LocalAddr(), conn.LocalAddr())
+			}
+
+			// Senders on different ports are distributed between the sockets
+			const senders = 8
+			for i := 0; i < senders; i++ {
+				conn, err := net.Dial("udp", udpInput.connections[0].LocalAddr().String())
+				require.NoError(t, err)
+				defer conn.Close()
+				_, err = conn.Write([]byte("message" + strconv.Itoa(i)))
+				require.NoError(t, err)
+			}
+
+			received := map[interface{}]bool{}
+			for i := 0; i < senders; i++ {
+				select {
+				case e := <-fakeOutput.Received:
+					received[e.Body] = true
+				case <-time.After(time.Second):
+					require.FailNow(t, "Timed out waiting for entry")
+				}
+			}
+			require.Len(t, received, senders)
+			require.Equal(t, uint64(0), udpInput.Dropped())
+		})
+	}
+}
+
+func TestUDPInputDropsWhenQueueFull(t *testing.T) {
+	cfg := NewUDPInputConfig("test_input")
+	cfg.ListenAddress = "127.0.0.1:0"
+	cfg.Workers = 1
+	cfg.QueueSize = 1
+	cfg.OutputIDs = []string{"output"}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	udpInput := ops[0].(*UDPInput)
+
+	// The output blocks until the test ends, so the worker and queue fill
+	unblock := make(chan struct{})
+	processed := make(chan struct{}, 1)
+	mockOutput := testutil.NewMockOperator("$.output")
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		select {
+		case processed <- struct{}{}:
+		default:
+		}
+		<-unblock
+	}).Return(nil)
+	require.NoError(t, udpInput.SetOutputs([]operator.Operator{mockOutput}))
+
+	require.NoError(t, udpInput.Start(testutil.NewMockPersister("test")))
+	defer udpInput.Stop()
+	defer close(unblock)
+
+	conn, err := net.Dial("udp", udpInput.connections[0].LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("first"))
+	require.NoError(t, err)
+	select {
+	case <-processed:
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+
+	for i := 0; i < 10; i++ {
+		_, err = conn.Write([]byte("message"))
+		require.NoError(t, err)
+	}
+
+	require.Eventually(t, func() bool {
+		return udpInput.Dropped() == 9
+	}, time.Second, 10*time.Millisecond)
+}
+
 func BenchmarkUdpInput(b *testing.B) {
 	cfg := NewUDPInputConfig("test_id")
 	cfg.ListenAddress = ":0"
@@ -176,7 +361,7 @@ func BenchmarkUdpInput(b *testing.B) {
 
 	done := make(chan struct{})
 	go func() {
-		conn, err := net.Dial("udp", udpInput.connection.LocalAddr().String())
+		conn, err := net.Dial("udp", udpInput.connections[0].LocalAddr().String())
 		require.NoError(b, err)
 		defer udpInput.Stop()
 		defer conn.Close()
@@ -0,0 +1,410 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package uds
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jpillora/backoff"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	socketTypeStream   = "stream"
+	socketTypeDatagram = "datagram"
+
+	defaultMaxLogSize = 1024 * 1024
+)
+
+func init() {
+	operator.Register("uds_input", func() operator.Builder { return NewUDSInputConfig("") })
+}
+
+// NewUDSInputConfig creates a new unix domain socket input config with default values
+func NewUDSInputConfig(operatorID string) *UDSInputConfig {
+	return &UDSInputConfig{
+		InputConfig: helper.NewInputConfig(operatorID, "uds_input"),
+		SocketType:  socketTypeStream,
+		MaxLogSize:  defaultMaxLogSize,
+		Multiline:   helper.NewMultilineConfig(),
+		Encoding:    helper.NewEncodingConfig(),
+	}
+}
+
+// UDSInputConfig is the configuration of a unix domain socket input operator
+type UDSInputConfig struct {
+	helper.InputConfig `yaml:",inline"`
+
+	SocketPath        string                 `mapstructure:"socket_path,omitempty"        json:"socket_path,omitempty"        yaml:"socket_path,omitempty"`
+	SocketType        string                 `mapstructure:"socket_type,omitempty"        json:"socket_type,omitempty"        yaml:"socket_type,omitempty"`
+	SocketPermissions string                 `mapstructure:"socket_permissions,omitempty" json:"socket_permissions,omitempty" yaml:"socket_permissions,omitempty"`
+	SocketOwner       string                 `mapstructure:"socket_owner,omitempty"       json:"socket_owner,omitempty"       yaml:"socket_owner,omitempty"`
+	SocketGroup       string                 `mapstructure:"socket_group,omitempty"       json:"socket_group,omitempty"       yaml:"socket_group,omitempty"`
+	Framing           string                 `mapstructure:"framing,omitempty"            json:"framing,omitempty"            yaml:"framing,omitempty"`
+	MaxLogSize        helper.ByteSize        `mapstructure:"max_log_size,omitempty"       json:"max_log_size,omitempty"       yaml:"max_log_size,omitempty"`
+	Multiline         helper.MultilineConfig `mapstructure:"multiline,omitempty"          json:"multiline,omitempty"          yaml:"multiline,omitempty"`
+	Encoding          helper.EncodingConfig  `mapstructure:",squash,omitempty"            json:",inline,omitempty"            yaml:",inline,omitempty"`
+}
+
+// Build will build a unix domain socket input operator
+func (c UDSInputConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.SocketPath == "" {
+		return nil, fmt.Errorf("missing required parameter 'socket_path'")
+	}
+
+	if c.MaxLogSize <= 0 {
+		return nil, fmt.Errorf("`max_log_size` must be positive")
+	}
+
+	permissions := os.FileMode(0)
+	if c.SocketPermissions != "" {
+		mode, err := strconv.ParseUint(c.SocketPermissions, 8, 32)
+		if err != nil || mode > 0777 {
+			return nil, fmt.Errorf("invalid socket_permissions '%s'", c.SocketPermissions)
+		}
+		permissions = os.FileMode(mode)
+	}
+
+	uid, err := lookupID(c.SocketOwner, func(name string) (string, error) {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", err
+		}
+		return u.Uid, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid socket_owner '%s': %s", c.SocketOwner, err)
+	}
+
+	gid, err := lookupID(c.SocketGroup, func(name string) (string, error) {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return "", err
+		}
+		return g.Gid, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid socket_group '%s': %s", c.SocketGroup, err)
+	}
+
+	encoding, err := c.Encoding.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	udsInput := &UDSInput{
+		InputOperator: inputOperator,
+		path:          c.SocketPath,
+		socketType:    c.SocketType,
+		permissions:   permissions,
+		uid:           uid,
+		gid:           gid,
+		maxLogSize:    int(c.MaxLogSize),
+		encoding:      encoding,
+		backoff: backoff.Backoff{
+			Max: 3 * time.Second,
+		},
+	}
+
+	switch c.SocketType {
+	case socketTypeStream:
+		splitFunc, err := c.Multiline.Build(context, encoding.Encoding, true)
+		if err != nil {
+			return nil, err
+		}
+		udsInput.newSplitFunc, err = helper.NewFramingSplitFuncBuilder(c.Framing, c.Multiline, splitFunc)
+		if err != nil {
+			return nil, err
+		}
+	case socketTypeDatagram:
+		if c.Framing != "" && c.Framing != helper.FramingNewline {
+			return nil, fmt.Errorf("`framing` can only be used with the `stream` socket type")
+		}
+		if c.Multiline.LineStartPattern != "" || c.Multiline.LineEndPattern != "" {
+			return nil, fmt.Errorf("`multiline` can only be used with the `stream` socket type")
+		}
+	default:
+		return nil, fmt.Errorf("invalid socket_type '%s'", c.SocketType)
+	}
+
+	return []operator.Operator{udsInput}, nil
+}
+
+// lookupID returns the numeric ID of a user or group, which is given
+// by name or ID. If no user or group is given, it returns -1.
+func lookupID(nameOrID string, lookup func(string) (string, error)) (int, error) {
+	if nameOrID == "" {
+		return -1, nil
+	}
+	if id, err := strconv.Atoi(nameOrID); err == nil {
+		return id, nil
+	}
+
+	id, err := lookup(nameOrID)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(id)
+}
+
+// UDSInput is an operator that listens on a unix domain socket for log entries
+type UDSInput struct {
+	helper.InputOperator
+	path         string
+	socketType   string
+	permissions  os.FileMode
+	uid          int
+	gid          int
+	maxLogSize   int
+	encoding     helper.Encoding
+	newSplitFunc func() bufio.SplitFunc
+
+	listener net.Listener
+	conn     net.PacketConn
+	backoff  backoff.Backoff
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// Start will start listening on the unix domain socket
+func (u *UDSInput) Start(_ operator.Persister) error {
+	if err := u.removeStaleSocket(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	u.cancel = cancel
+
+	switch u.socketType {
+	case socketTypeStream:
+		listener, err := net.Listen("unix", u.path)
+		if err != nil {
+			return fmt.Errorf("failed to listen on socket: %s", err)
+		}
+		u.listener = listener
+	case socketTypeDatagram:
+		conn, err := net.ListenPacket("unixgram", u.path)
+		if err != nil {
+			return fmt.Errorf("failed to listen on socket: %s", err)
+		}
+		u.conn = conn
+	}
+
+	if err := u.setOwnership(); err != nil {
+		u.closeSocket()
+		return err
+	}
+
+	if u.listener != nil {
+		u.goListen(ctx)
+	} else {
+		u.goHandleDatagrams(ctx)
+	}
+	return nil
+}
+
+// removeStaleSocket removes a socket left at the path, such as by a
+// previous run of the agent which did not stop cleanly, so that the
+// socket can be recreated. Files which are not sockets are not removed.
+func (u *UDSInput) removeStaleSocket() error {
+	info, err := os.Lstat(u.path)
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return fmt.Errorf("stat socket: %s", err)
+	case info.Mode()&os.ModeSocket == 0:
+		return fmt.Errorf("'%s' exists and is not a socket", u.path)
+	}
+
+	if err := os.Remove(u.path); err != nil {
+		return fmt.Errorf("remove stale socket: %s", err)
+	}
+	return nil
+}
+
+// setOwnership sets the configured permissions and ownership of the socket
+func (u *UDSInput) setOwnership() error {
+	if u.permissions != 0 {
+		if err := os.Chmod(u.path, u.permissions); err != nil {
+			return fmt.Errorf("set socket permissions: %s", err)
+		}
+	}
+	if u.uid != -1 || u.gid != -1 {
+		if err := os.Chown(u.path, u.uid, u.gid); err != nil {
+			return fmt.Errorf("set socket ownership: %s", err)
+		}
+	}
+	return nil
+}
+
+// goListen will accept connections to a stream socket
+func (u *UDSInput) goListen(ctx context.Context) {
+	u.wg.Add(1)
+
+	go func() {
+		defer u.wg.Done()
+
+		for {
+			conn, err := u.listener.Accept()
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+					u.Debugw("Listener accept error", zap.Error(err))
+					time.Sleep(u.backoff.Duration())
+					continue
+				}
+			}
+			u.backoff.Reset()
+
+			subctx, cancel := context.WithCancel(ctx)
+			u.goHandleClose(subctx, conn)
+			u.goHandleStream(subctx, conn, cancel)
+		}
+	}()
+}
+
+// goHandleClose will wait for the context to finish before closing a connection
+func (u *UDSInput) goHandleClose(ctx context.Context, conn net.Conn) {
+	u.wg.Add(1)
+
+	go func() {
+		defer u.wg.Done()
+		<-ctx.Done()
+		if err := conn.Close(); err != nil {
+			u.Errorw("Failed to close connection", zap.Error(err))
+		}
+	}()
+}
+
+// goHandleStream will read messages from a connection to a stream socket
+func (u *UDSInput) goHandleStream(ctx context.Context, conn net.Conn, cancel context.CancelFunc) {
+	u.wg.Add(1)
+
+	go func() {
+		defer u.wg.Done()
+		defer cancel()
+
+		scanner := bufio.NewScanner(conn)
+		scanner.Buffer(make([]byte, 0, 4096), u.maxLogSize)
+		scanner.Split(u.newSplitFunc())
+
+		for scanner.Scan() {
+			u.handleMessage(ctx, scanner.Bytes())
+		}
+
+		select {
+		case <-ctx.Done():
+			// The connection was closed by Stop
+		default:
+			if err := scanner.Err(); err != nil {
+				u.Errorw("Scanner error", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// goHandleDatagrams will read messages from a datagram socket. Each datagram is a message.
+func (u *UDSInput) goHandleDatagrams(ctx context.Context) {
+	u.wg.Add(1)
+
+	go func() {
+		defer u.wg.Done()
+
+		buf := make([]byte, u.maxLogSize)
+		for {
+			n, _, err := u.conn.ReadFrom(buf)
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+					u.Errorw("Failed to read datagram", zap.Error(err))
+					time.Sleep(u.backoff.Duration())
+					continue
+				}
+			}
+			u.backoff.Reset()
+
+			// Remove trailing newlines and NULs
+			for ; n > 0 && (buf[n-1] == '\n' || buf[n-1] == '\r' || buf[n-1] == 0); n-- {
+			}
+			if n == 0 {
+				continue
+			}
+			u.handleMessage(ctx, buf[:n])
+		}
+	}()
+}
+
+// handleMessage creates an entry from a message
+func (u *UDSInput) handleMessage(ctx context.Context, message []byte) {
+	decoded, err := u.encoding.Decode(message)
+	if err != nil {
+		u.Errorw("Failed to decode data", zap.Error(err))
+		return
+	}
+
+	entry, err := u.NewEntry(decoded)
+	if err != nil {
+		u.Errorw("Failed to create entry", zap.Error(err))
+		return
+	}
+	u.Write(ctx, entry)
+}
+
+// closeSocket closes the socket. Closing a stream listener removes
+// its socket file, but the file of a datagram socket is removed here.
+func (u *UDSInput) closeSocket() {
+	if u.listener != nil {
+		u.listener.Close()
+	}
+	if u.conn != nil {
+		u.conn.Close()
+		if err := os.Remove(u.path); err != nil && !os.IsNotExist(err) {
+			u.Errorw("Failed to remove socket", zap.Error(err))
+		}
+	}
+}
+
+// Stop will stop listening on the unix domain socket
+func (u *UDSInput) Stop() error {
+	if u.cancel != nil {
+		u.cancel()
+	}
+	u.closeSocket()
+	u.wg.Wait()
+	return nil
+}
@@ -0,0 +1,302 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package uds
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestUDSInput(t *testing.T, cfgMod func(*UDSInputConfig)) (*UDSInput, *testutil.FakeOutput) {
+	cfg := NewUDSInputConfig("test_id")
+	cfg.SocketPath = filepath.Join(t.TempDir(), "test.sock")
+	cfg.OutputIDs = []string{"fake"}
+	if cfgMod != nil {
+		cfgMod(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	udsInput := ops[0].(*UDSInput)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, udsInput.SetOutputs([]operator.Operator{fakeOutput}))
+	return udsInput, fakeOutput
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*UDSInputConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *UDSInputConfig) {},
+			false,
+		},
+		{
+			"MissingSocketPath",
+			func(cfg *UDSInputConfig) {
+				cfg.SocketPath = ""
+			},
+			true,
+		},
+		{
+			"Datagram",
+			func(cfg *UDSInputConfig) {
+				cfg.SocketType = socketTypeDatagram
+			},
+			false,
+		},
+		{
+			"InvalidSocketType",
+			func(cfg *UDSInputConfig) {
+				cfg.SocketType = "seqpacket"
+			},
+			true,
+		},
+		{
+			"Permissions",
+			func(cfg *UDSInputConfig) {
+				cfg.SocketPermissions = "0660"
+			},
+			false,
+		},
+		{
+			"InvalidPermissions",
+			func(cfg *UDSInputConfig) {
+				cfg.SocketPermissions = "0999"
+			},
+			true,
+		},
+		{
+			"PermissionsOutOfRange",
+			func(cfg *UDSInputConfig) {
+				cfg.SocketPermissions = "7777"
+			},
+			true,
+		},
+		{
+			"NumericOwnerAndGroup",
+			func(cfg *UDSInputConfig) {
+				cfg.SocketOwner = "1000"
+				cfg.SocketGroup = "1000"
+			},
+			false,
+		},
+		{
+			"UnknownOwner",
+			func(cfg *UDSInputConfig) {
+				cfg.SocketOwner = "no-such-user-for-uds-input"
+			},
+			true,
+		},
+		{
+			"UnknownGroup",
+			func(cfg *UDSInputConfig) {
+				cfg.SocketGroup = "no-such-group-for-uds-input"
+			},
+			true,
+		},
+		{
+			"OctetCounting",
+			func(cfg *UDSInputConfig) {
+				cfg.Framing = helper.FramingOctetCounting
+			},
+			false,
+		},
+		{
+			"InvalidFraming",
+			func(cfg *UDSInputConfig) {
+				cfg.Framing = "length"
+			},
+			true,
+		},
+		{
+			"DatagramWithFraming",
+			func(cfg *UDSInputConfig) {
+				cfg.SocketType = socketTypeDatagram
+				cfg.Framing = helper.FramingOctetCounting
+			},
+			true,
+		},
+		{
+			"DatagramWithMultiline",
+			func(cfg *UDSInputConfig) {
+				cfg.SocketType = socketTypeDatagram
+				cfg.Multiline.LineStartPattern = "^<"
+			},
+			true,
+		},
+		{
+			"ZeroMaxLogSize",
+			func(cfg *UDSInputConfig) {
+				cfg.MaxLogSize = 0
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewUDSInputConfig("test_id")
+			cfg.SocketPath = "/tmp/test.sock"
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestUDSInputStream(t *testing.T) {
+	udsInput, fakeOutput := newTestUDSInput(t, nil)
+	require.NoError(t, udsInput.Start(testutil.NewMockPersister("test")))
+	defer udsInput.Stop()
+
+	conn, err := net.Dial("unix", udsInput.path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("message1\nmessage2\r\n"))
+	require.NoError(t, err)
+
+	fakeOutput.ExpectBody(t, "message1")
+	fakeOutput.ExpectBody(t, "message2")
+}
+
+func TestUDSInputStreamOctetCounting(t *testing.T) {
+	udsInput, fakeOutput := newTestUDSInput(t, func(cfg *UDSInputConfig) {
+		cfg.Framing = helper.FramingOctetCounting
+	})
+	require.NoError(t, udsInput.Start(testutil.NewMockPersister("test")))
+	defer udsInput.Stop()
+
+	conn, err := net.Dial("unix", udsInput.path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("13 first\nmessage6 second"))
+	require.NoError(t, err)
+
+	fakeOutput.ExpectBody(t, "first\nmessage")
+	fakeOutput.ExpectBody(t, "second")
+}
+
+func TestUDSInputDatagram(t *testing.T) {
+	udsInput, fakeOutput := newTestUDSInput(t, func(cfg *UDSInputConfig) {
+		cfg.SocketType = socketTypeDatagram
+	})
+	require.NoError(t, udsInput.Start(testutil.NewMockPersister("test")))
+	defer udsInput.Stop()
+
+	conn, err := net.Dial("unixgram", udsInput.path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, message := range []string{"<13>Jun  1 12:00:00 host app: first\n", "second\nline\x00", "\n"} {
+		_, err = conn.Write([]byte(message))
+		require.NoError(t, err)
+	}
+
+	fakeOutput.ExpectBody(t, "<13>Jun  1 12:00:00 host app: first")
+	fakeOutput.ExpectBody(t, "second\nline")
+	expectNoEntry(t, fakeOutput)
+}
+
+func TestUDSInputPermissions(t *testing.T) {
+	for _, socketType := range []string{socketTypeStream, socketTypeDatagram} {
+		t.Run(socketType, func(t *testing.T) {
+			udsInput, _ := newTestUDSInput(t, func(cfg *UDSInputConfig) {
+				cfg.SocketType = socketType
+				cfg.SocketPermissions = "0620"
+				cfg.SocketOwner = strconv.Itoa(os.Getuid())
+				cfg.SocketGroup = strconv.Itoa(os.Getgid())
+			})
+			require.NoError(t, udsInput.Start(testutil.NewMockPersister("test")))
+
+			info, err := os.Stat(udsInput.path)
+			require.NoError(t, err)
+			require.NotZero(t, info.Mode()&os.ModeSocket)
+			require.Equal(t, os.FileMode(0620), info.Mode().Perm())
+
+			// The socket is removed when the operator stops
+			require.NoError(t, udsInput.Stop())
+			_, err = os.Stat(udsInput.path)
+			require.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestUDSInputStaleSocket(t *testing.T) {
+	udsInput, fakeOutput := newTestUDSInput(t, nil)
+
+	// A socket left by a process which did not stop cleanly
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: udsInput.path, Net: "unix"})
+	require.NoError(t, err)
+	listener.SetUnlinkOnClose(false)
+	require.NoError(t, listener.Close())
+	_, err = os.Stat(udsInput.path)
+	require.NoError(t, err)
+
+	require.NoError(t, udsInput.Start(testutil.NewMockPersister("test")))
+	defer udsInput.Stop()
+
+	conn, err := net.Dial("unix", udsInput.path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("message\n"))
+	require.NoError(t, err)
+	fakeOutput.ExpectBody(t, "message")
+}
+
+func TestUDSInputNotSocket(t *testing.T) {
+	udsInput, _ := newTestUDSInput(t, nil)
+	require.NoError(t, ioutil.WriteFile(udsInput.path, []byte("data"), 0600))
+
+	require.Error(t, udsInput.Start(testutil.NewMockPersister("test")))
+	require.NoError(t, udsInput.Stop())
+
+	data, err := ioutil.ReadFile(udsInput.path)
+	require.NoError(t, err)
+	require.Equal(t, []byte("data"), data)
+}
+
+func expectNoEntry(t *testing.T, fakeOutput *testutil.FakeOutput) {
+	select {
+	case e := <-fakeOutput.Received:
+		require.FailNow(t, "Unexpected entry", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -34,6 +34,9 @@ var (
 	updateBookmarkProc        SyscallProc = api.NewProc("EvtUpdateBookmark")
 	openPublisherMetadataProc SyscallProc = api.NewProc("EvtOpenPublisherMetadata")
 	formatMessageProc         SyscallProc = api.NewProc("EvtFormatMessage")
+	openSessionProc           SyscallProc = api.NewProc("EvtOpenSession")
+	openChannelEnumProc       SyscallProc = api.NewProc("EvtOpenChannelEnum")
+	nextChannelPathProc       SyscallProc = api.NewProc("EvtNextChannelPath")
 )
 
 // SyscallProc is a syscall procedure.
@@ -68,6 +71,31 @@ const (
 	EvtFormatMessageXML uint32 = 9
 )
 
+const (
+	// EvtRPCLogin is a login class that indicates the login information is an EvtRPCLoginInfo.
+	EvtRPCLogin uint32 = 1
+)
+
+const (
+	// EvtRPCLoginAuthDefault is a flag to use the default authentication method.
+	EvtRPCLoginAuthDefault uint32 = 0
+	// EvtRPCLoginAuthNegotiate is a flag to use the negotiate authentication method.
+	EvtRPCLoginAuthNegotiate uint32 = 1
+	// EvtRPCLoginAuthKerberos is a flag to use the kerberos authentication method.
+	EvtRPCLoginAuthKerberos uint32 = 2
+	// EvtRPCLoginAuthNTLM is a flag to use the NTLM authentication method.
+	EvtRPCLoginAuthNTLM uint32 = 3
+)
+
+// EvtRPCLoginInfo is the login information used to open a session to a remote computer (https://docs.microsoft.com/en-us/windows/win32/api/winevt/ns-winevt-evt_rpc_login)
+type EvtRPCLoginInfo struct {
+	Server   *uint16
+	User     *uint16
+	Domain   *uint16
+	Password *uint16
+	Flags    uint32
+}
+
 const (
 	// EvtRenderEventXML is a flag to render an event as an XML string
 	EvtRenderEventXML uint32 = 1
@@ -154,3 +182,33 @@ func evtFormatMessage(publisherMetadata uintptr, event uintptr, messageID uint32
 
 	return nil
 }
+
+// evtOpenSession is the direct syscall implementation of EvtOpenSession (https://docs.microsoft.com/en-us/windows/win32/api/winevt/nf-winevt-evtopensession)
+func evtOpenSession(loginClass uint32, login *EvtRPCLoginInfo, timeout uint32, flags uint32) (uintptr, error) {
+	handle, _, err := openSessionProc.Call(uintptr(loginClass), uintptr(unsafe.Pointer(login)), uintptr(timeout), uintptr(flags))
+	if err != ErrorSuccess {
+		return 0, err
+	}
+
+	return handle, nil
+}
+
+// evtOpenChannelEnum is the direct syscall implementation of EvtOpenChannelEnum (https://docs.microsoft.com/en-us/windows/win32/api/winevt/nf-winevt-evtopenchannelenum)
+func evtOpenChannelEnum(session uintptr, flags uint32) (uintptr, error) {
+	handle, _, err := openChannelEnumProc.Call(session, uintptr(flags))
+	if err != ErrorSuccess {
+		return 0, err
+	}
+
+	return handle, nil
+}
+
+// evtNextChannelPath is the direct syscall implementation of EvtNextChannelPath (https://docs.microsoft.com/en-us/windows/win32/api/winevt/nf-winevt-evtnextchannelpath)
+func evtNextChannelPath(channelEnum uintptr, channelPathBufferSize uint32, channelPathBuffer *uint16, channelPathBufferUsed *uint32) error {
+	_, _, err := nextChannelPathProc.Call(channelEnum, uintptr(channelPathBufferSize), uintptr(unsafe.Pointer(channelPathBuffer)), uintptr(unsafe.Pointer(channelPathBufferUsed)))
+	if err != ErrorSuccess {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build windows
+
+package windows
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// forwardedEventsChannel is the channel that events collected by windows event forwarding are stored in.
+const forwardedEventsChannel = "ForwardedEvents"
+
+// channelReader reads events from a subscription to a single channel, and tracks its bookmark.
+type channelReader struct {
+	channel      string
+	bookmark     Bookmark
+	subscription Subscription
+}
+
+// newChannelReader will create a new channel reader with empty handles.
+func newChannelReader(channel string) *channelReader {
+	return &channelReader{
+		channel:      channel,
+		bookmark:     NewBookmark(),
+		subscription: NewSubscription(),
+	}
+}
+
+// Close will close the subscription and bookmark of the channel.
+func (c *channelReader) Close() error {
+	if err := c.subscription.Close(); err != nil {
+		return fmt.Errorf("failed to close subscription: %s", err)
+	}
+
+	if err := c.bookmark.Close(); err != nil {
+		return fmt.Errorf("failed to close bookmark: %s", err)
+	}
+
+	return nil
+}
+
+// listChannels will list the paths of all channels of the session's computer.
+func listChannels(session Session) ([]string, error) {
+	handle, err := evtOpenChannelEnum(session.handle, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel enumerator: %s", err)
+	}
+	defer evtClose(handle)
+
+	var channels []string
+	buffer := make([]uint16, 256)
+	for {
+		var bufferUsed uint32
+		err := evtNextChannelPath(handle, uint32(len(buffer)), &buffer[0], &bufferUsed)
+		switch err {
+		case nil:
+			channels = append(channels, syscall.UTF16ToString(buffer[:bufferUsed]))
+		case ErrorInsufficientBuffer:
+			buffer = make([]uint16, bufferUsed)
+		case ErrorNoMoreItems:
+			return channels, nil
+		default:
+			return nil, fmt.Errorf("syscall to 'EvtNextChannelPath' failed: %s", err)
+		}
+	}
+}
@@ -19,13 +19,22 @@ package windows
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/jpillora/backoff"
+
 	"github.com/open-telemetry/opentelemetry-log-collection/operator"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 )
 
+const (
+	renderModeFormatted  = "formatted"
+	renderModeStructured = "structured"
+	renderModeRaw        = "raw"
+)
+
 func init() {
 	operator.Register("windows_eventlog_input", func() operator.Builder { return NewDefaultConfig() })
 }
@@ -37,6 +46,17 @@ type EventLogConfig struct {
 	MaxReads           int             `mapstructure:"max_reads,omitempty" json:"max_reads,omitempty" yaml:"max_reads,omitempty"`
 	StartAt            string          `mapstructure:"start_at,omitempty" json:"start_at,omitempty" yaml:"start_at,omitempty"`
 	PollInterval       helper.Duration `mapstructure:"poll_interval,omitempty" json:"poll_interval,omitempty" yaml:"poll_interval,omitempty"`
+	Remote             RemoteConfig    `mapstructure:"remote,omitempty" json:"remote,omitempty" yaml:"remote,omitempty"`
+	RenderMode         string          `mapstructure:"render_mode,omitempty" json:"render_mode,omitempty" yaml:"render_mode,omitempty"`
+}
+
+// RemoteConfig is the configuration of a remote computer to read events from.
+type RemoteConfig struct {
+	Server         string `mapstructure:"server,omitempty" json:"server,omitempty" yaml:"server,omitempty"`
+	Username       string `mapstructure:"username,omitempty" json:"username,omitempty" yaml:"username,omitempty"`
+	Password       string `mapstructure:"password,omitempty" json:"password,omitempty" yaml:"password,omitempty"`
+	Domain         string `mapstructure:"domain,omitempty" json:"domain,omitempty" yaml:"domain,omitempty"`
+	Authentication string `mapstructure:"authentication,omitempty" json:"authentication,omitempty" yaml:"authentication,omitempty"`
 }
 
 // Build will build a windows event log operator.
@@ -50,6 +70,12 @@ func (c *EventLogConfig) Build(context operator.BuildContext) ([]operator.Operat
 		return nil, fmt.Errorf("missing required `channel` field")
 	}
 
+	if isChannelPattern(c.Channel) {
+		if _, err := matchChannels(c.Channel, nil); err != nil {
+			return nil, fmt.Errorf("invalid channel pattern '%s': %s", c.Channel, err)
+		}
+	}
+
 	if c.MaxReads < 1 {
 		return nil, fmt.Errorf("the `max_reads` field must be greater than zero")
 	}
@@ -58,6 +84,20 @@ func (c *EventLogConfig) Build(context operator.BuildContext) ([]operator.Operat
 		return nil, fmt.Errorf("the `start_at` field must be set to `beginning` or `end`")
 	}
 
+	switch c.RenderMode {
+	case renderModeFormatted, renderModeStructured, renderModeRaw:
+	default:
+		return nil, fmt.Errorf("the `render_mode` field must be set to `formatted`, `structured` or `raw`")
+	}
+
+	if c.Remote.Server == "" && c.Remote != (RemoteConfig{}) {
+		return nil, fmt.Errorf("missing required `remote.server` field")
+	}
+
+	if _, ok := authenticationFlags[c.Remote.Authentication]; !ok {
+		return nil, fmt.Errorf("the `remote.authentication` field must be set to `default`, `negotiate`, `kerberos` or `ntlm`")
+	}
+
 	eventLogInput := &EventLogInput{
 		InputOperator: inputOperator,
 		buffer:        NewBuffer(),
@@ -65,6 +105,11 @@ func (c *EventLogConfig) Build(context operator.BuildContext) ([]operator.Operat
 		maxReads:      c.MaxReads,
 		startAt:       c.StartAt,
 		pollInterval:  c.PollInterval,
+		remote:        c.Remote,
+		renderMode:    c.RenderMode,
+		backoff: backoff.Backoff{
+			Max: time.Minute,
+		},
 	}
 	return []operator.Operator{eventLogInput}, nil
 }
@@ -75,6 +120,7 @@ func NewDefaultConfig() *EventLogConfig {
 		InputConfig: helper.NewInputConfig("", "windows_eventlog_input"),
 		MaxReads:    100,
 		StartAt:     "end",
+		RenderMode:  renderModeFormatted,
 		PollInterval: helper.Duration{
 			Duration: 1 * time.Second,
 		},
@@ -84,13 +130,16 @@ func NewDefaultConfig() *EventLogConfig {
 // EventLogInput is an operator that creates entries using the windows event log api.
 type EventLogInput struct {
 	helper.InputOperator
-	bookmark     Bookmark
-	subscription Subscription
+	session      Session
+	readers      []*channelReader
 	buffer       Buffer
 	channel      string
 	maxReads     int
 	startAt      string
 	pollInterval helper.Duration
+	remote       RemoteConfig
+	renderMode   string
+	backoff      backoff.Backoff
 	persister    operator.Persister
 	cancel       context.CancelFunc
 	wg           sync.WaitGroup
@@ -103,21 +152,14 @@ func (e *EventLogInput) Start(persister operator.Persister) error {
 
 	e.persister = persister
 
-	e.bookmark = NewBookmark()
-	offsetXML, err := e.getBookmarkOffset(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to retrieve bookmark offset: %s", err)
-	}
-
-	if offsetXML != "" {
-		if err := e.bookmark.Open(offsetXML); err != nil {
-			return fmt.Errorf("failed to open bookmark: %s", err)
+	e.session = NewSession()
+	if err := e.openSubscriptions(ctx); err != nil {
+		if e.remote.Server == "" {
+			return err
 		}
-	}
-
-	e.subscription = NewSubscription()
-	if err := e.subscription.Open(e.channel, e.startAt, e.bookmark); err != nil {
-		return fmt.Errorf("failed to open subscription: %s", err)
+		// A remote computer may be temporarily unreachable, so the
+		// subscriptions are reopened when reading instead of failing to start.
+		e.Errorf("Failed to connect to %s: %s", e.remote.Server, err)
 	}
 
 	e.wg.Add(1)
@@ -130,17 +172,134 @@ func (e *EventLogInput) Stop() error {
 	e.cancel()
 	e.wg.Wait()
 
-	if err := e.subscription.Close(); err != nil {
-		return fmt.Errorf("failed to close subscription: %s", err)
+	return e.closeSubscriptions()
+}
+
+// openSubscriptions will open a session to the remote computer, if configured, and a subscription to each channel.
+func (e *EventLogInput) openSubscriptions(ctx context.Context) error {
+	if e.remote.Server != "" {
+		if err := e.session.Open(e.remote); err != nil {
+			return fmt.Errorf("failed to open session: %s", err)
+		}
+	}
+
+	channels, err := e.resolveChannels()
+	if err != nil {
+		_ = e.session.Close()
+		return err
+	}
+
+	for _, channel := range channels {
+		reader, err := e.openChannelReader(ctx, channel)
+		if err != nil {
+			if isChannelPattern(e.channel) {
+				// Channels matched by a pattern may be disabled, or not readable by the user
+				e.Warnf("Skipping %s channel: %s", channel, err)
+				continue
+			}
+			_ = e.session.Close()
+			return err
+		}
+		e.readers = append(e.readers, reader)
 	}
 
-	if err := e.bookmark.Close(); err != nil {
-		return fmt.Errorf("failed to close bookmark: %s", err)
+	if len(e.readers) == 0 {
+		_ = e.session.Close()
+		return fmt.Errorf("failed to open a subscription to any channel matching %s", e.channel)
 	}
 
 	return nil
 }
 
+// resolveChannels will resolve the channels to subscribe to, expanding the channel if it is a pattern.
+func (e *EventLogInput) resolveChannels() ([]string, error) {
+	if !isChannelPattern(e.channel) {
+		return []string{e.channel}, nil
+	}
+
+	available, err := listChannels(e.session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channels: %s", err)
+	}
+
+	channels, err := matchChannels(e.channel, available)
+	if err != nil {
+		return nil, fmt.Errorf("invalid channel pattern '%s': %s", e.channel, err)
+	}
+
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("no channels match %s", e.channel)
+	}
+
+	return channels, nil
+}
+
+// openChannelReader will open the bookmark of a channel and a subscription to it.
+func (e *EventLogInput) openChannelReader(ctx context.Context, channel string) (*channelReader, error) {
+	reader := newChannelReader(channel)
+
+	offsetXML, err := e.getBookmarkOffset(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve bookmark offset: %s", err)
+	}
+
+	if offsetXML != "" {
+		if err := reader.bookmark.Open(offsetXML); err != nil {
+			return nil, fmt.Errorf("failed to open bookmark: %s", err)
+		}
+	}
+
+	if err := reader.subscription.Open(e.session, channel, e.startAt, reader.bookmark); err != nil {
+		_ = reader.bookmark.Close()
+		return nil, fmt.Errorf("failed to open subscription: %s", err)
+	}
+
+	return reader, nil
+}
+
+// closeSubscriptions will close the subscription to each channel and the session to the remote computer.
+func (e *EventLogInput) closeSubscriptions() error {
+	var closeErr error
+	for _, reader := range e.readers {
+		if err := reader.Close(); err != nil && closeErr == nil {
+			closeErr = fmt.Errorf("failed to close %s channel: %s", reader.channel, err)
+		}
+	}
+	e.readers = nil
+
+	if err := e.session.Close(); err != nil && closeErr == nil {
+		closeErr = fmt.Errorf("failed to close session: %s", err)
+	}
+	e.session = NewSession()
+
+	return closeErr
+}
+
+// reconnect will reopen the subscriptions to the remote computer, retrying with backoff until it succeeds.
+// The subscriptions resume after their persisted bookmarks, so no events are lost while disconnected.
+func (e *EventLogInput) reconnect(ctx context.Context) {
+	if err := e.closeSubscriptions(); err != nil {
+		e.Debugf("Failed to close broken subscriptions: %s", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.backoff.Duration()):
+		}
+
+		if err := e.openSubscriptions(ctx); err != nil {
+			e.Errorf("Failed to reconnect to %s: %s", e.remote.Server, err)
+			continue
+		}
+
+		e.backoff.Reset()
+		e.Infof("Reconnected to %s", e.remote.Server)
+		return
+	}
+}
+
 // readOnInterval will read events with respect to the polling interval.
 func (e *EventLogInput) readOnInterval(ctx context.Context) {
 	defer e.wg.Done()
@@ -153,46 +312,65 @@ func (e *EventLogInput) readOnInterval(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+			if len(e.readers) == 0 {
+				e.reconnect(ctx)
+			}
 			e.readToEnd(ctx)
 		}
 	}
 }
 
-// readToEnd will read events from the subscription until it reaches the end of the channel.
+// readToEnd will read events from each subscription until it reaches the end of its channel.
 func (e *EventLogInput) readToEnd(ctx context.Context) {
+	for _, reader := range e.readers {
+		if err := e.readChannelToEnd(ctx, reader); err != nil {
+			e.Errorf("Failed to read events from %s channel: %s", reader.channel, err)
+			if e.remote.Server != "" {
+				e.reconnect(ctx)
+				return
+			}
+		}
+	}
+}
+
+// readChannelToEnd will read events from the subscription of a channel until it reaches the end of the channel.
+func (e *EventLogInput) readChannelToEnd(ctx context.Context, reader *channelReader) error {
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			return nil
 		default:
-			if count := e.read(ctx); count == 0 {
-				return
+			count, err := e.read(ctx, reader)
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				return nil
 			}
 		}
 	}
 }
 
-// read will read events from the subscription.
-func (e *EventLogInput) read(ctx context.Context) int {
-	events, err := e.subscription.Read(e.maxReads)
+// read will read events from the subscription of a channel.
+func (e *EventLogInput) read(ctx context.Context, reader *channelReader) (int, error) {
+	events, err := reader.subscription.Read(e.maxReads)
 	if err != nil {
-		e.Errorf("Failed to read events from subscription: %s", err)
-		return 0
+		return 0, err
 	}
 
 	for i, event := range events {
-		e.processEvent(ctx, event)
+		e.processEvent(ctx, reader.channel, event)
 		if len(events) == i+1 {
-			e.updateBookmarkOffset(ctx, event)
+			e.updateBookmarkOffset(ctx, reader, event)
 		}
 		event.Close()
 	}
 
-	return len(events)
+	return len(events), nil
 }
 
 // processEvent will process and send an event retrieved from windows event log.
-func (e *EventLogInput) processEvent(ctx context.Context, event Event) {
+func (e *EventLogInput) processEvent(ctx context.Context, channel string, event Event) {
 	simpleEvent, err := event.RenderSimple(e.buffer)
 	if err != nil {
 		e.Errorf("Failed to render simple event: %s", err)
@@ -200,9 +378,9 @@ func (e *EventLogInput) processEvent(ctx context.Context, event Event) {
 	}
 
 	publisher := NewPublisher()
-	if err := publisher.Open(simpleEvent.Provider.Name); err != nil {
+	if err := publisher.Open(e.session, simpleEvent.Provider.Name); err != nil {
 		e.Errorf("Failed to open publisher: %s")
-		e.sendEvent(ctx, simpleEvent)
+		e.sendEvent(ctx, channel, simpleEvent)
 		return
 	}
 	defer publisher.Close()
@@ -210,16 +388,25 @@ func (e *EventLogInput) processEvent(ctx context.Context, event Event) {
 	formattedEvent, err := event.RenderFormatted(e.buffer, publisher)
 	if err != nil {
 		e.Errorf("Failed to render formatted event: %s", err)
-		e.sendEvent(ctx, simpleEvent)
+		e.sendEvent(ctx, channel, simpleEvent)
 		return
 	}
 
-	e.sendEvent(ctx, formattedEvent)
+	e.sendEvent(ctx, channel, formattedEvent)
 }
 
 // sendEvent will send EventXML as an entry to the operator's output.
-func (e *EventLogInput) sendEvent(ctx context.Context, eventXML EventXML) {
-	body := eventXML.parseBody()
+func (e *EventLogInput) sendEvent(ctx context.Context, channel string, eventXML EventXML) {
+	var body interface{}
+	switch e.renderMode {
+	case renderModeStructured:
+		body = eventXML.parseStructuredBody()
+	case renderModeRaw:
+		body = eventXML.Original
+	default:
+		body = eventXML.parseBody()
+	}
+
 	entry, err := e.NewEntry(body)
 	if err != nil {
 		e.Errorf("Failed to create entry: %s", err)
@@ -228,29 +415,42 @@ func (e *EventLogInput) sendEvent(ctx context.Context, eventXML EventXML) {
 
 	entry.Timestamp = eventXML.parseTimestamp()
 	entry.Severity = eventXML.parseSeverity()
+	if strings.EqualFold(channel, forwardedEventsChannel) {
+		// Forwarded events are logged by the computer which forwarded them, rather than this one
+		entry.AddResourceKey("host.name", eventXML.Computer)
+	}
 	e.Write(ctx, entry)
 }
 
 // getBookmarkXML will get the bookmark xml from the offsets database.
-func (e *EventLogInput) getBookmarkOffset(ctx context.Context) (string, error) {
-	bytes, err := e.persister.Get(ctx, e.channel)
+func (e *EventLogInput) getBookmarkOffset(ctx context.Context, channel string) (string, error) {
+	bytes, err := e.persister.Get(ctx, e.bookmarkKey(channel))
 	return string(bytes), err
 }
 
+// bookmarkKey will return the key of the bookmark of a channel in the offsets database.
+// Bookmarks of remote computers are keyed by server, so each remote channel is tracked separately.
+func (e *EventLogInput) bookmarkKey(channel string) string {
+	if e.remote.Server == "" {
+		return channel
+	}
+	return e.remote.Server + "/" + channel
+}
+
 // updateBookmark will update the bookmark xml and save it in the offsets database.
-func (e *EventLogInput) updateBookmarkOffset(ctx context.Context, event Event) {
-	if err := e.bookmark.Update(event); err != nil {
+func (e *EventLogInput) updateBookmarkOffset(ctx context.Context, reader *channelReader, event Event) {
+	if err := reader.bookmark.Update(event); err != nil {
 		e.Errorf("Failed to update bookmark from event: %s", err)
 		return
 	}
 
-	bookmarkXML, err := e.bookmark.Render(e.buffer)
+	bookmarkXML, err := reader.bookmark.Render(e.buffer)
 	if err != nil {
 		e.Errorf("Failed to render bookmark xml: %s", err)
 		return
 	}
 
-	if err := e.persister.Set(ctx, e.channel, []byte(bookmarkXML)); err != nil {
+	if err := e.persister.Set(ctx, e.bookmarkKey(reader.channel), []byte(bookmarkXML)); err != nil {
 		e.Errorf("failed to set offsets: %s", err)
 		return
 	}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
 )
 
 func TestEventLogConfig(t *testing.T) {
@@ -35,6 +36,7 @@ func TestEventLogConfig(t *testing.T) {
 		"max_reads":     100,
 		"start_at":      "end",
 		"poll_interval": "1s",
+		"render_mode":   "formatted",
 		"attributes":    map[string]interface{}{},
 		"resource":      map[string]interface{}{},
 		"write_to":      "$body.to",
@@ -45,3 +47,138 @@ func TestEventLogConfig(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expect, &actual)
 }
+
+func TestEventLogConfigRemote(t *testing.T) {
+	expect := NewDefaultConfig()
+	expect.Channel = "security"
+	expect.Remote = RemoteConfig{
+		Server:         "dc01.example.com",
+		Username:       "collector",
+		Password:       "secret",
+		Domain:         "EXAMPLE",
+		Authentication: "kerberos",
+	}
+
+	input := map[string]interface{}{
+		"id":            "",
+		"type":          "windows_eventlog_input",
+		"channel":       "security",
+		"max_reads":     100,
+		"start_at":      "end",
+		"poll_interval": "1s",
+		"render_mode":   "formatted",
+		"remote": map[string]interface{}{
+			"server":         "dc01.example.com",
+			"username":       "collector",
+			"password":       "secret",
+			"domain":         "EXAMPLE",
+			"authentication": "kerberos",
+		},
+		"attributes": map[string]interface{}{},
+		"resource":   map[string]interface{}{},
+		"write_to":   "$body",
+	}
+
+	var actual EventLogConfig
+	err := helper.UnmarshalMapstructure(input, &actual)
+	require.NoError(t, err)
+	require.Equal(t, expect, &actual)
+}
+
+func TestEventLogBuildRenderMode(t *testing.T) {
+	cases := []struct {
+		name       string
+		renderMode string
+		expectErr  bool
+	}{
+		{"Formatted", "formatted", false},
+		{"Structured", "structured", false},
+		{"Raw", "raw", false},
+		{"Invalid", "rendered", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewDefaultConfig()
+			cfg.Channel = "security"
+			cfg.RenderMode = tc.renderMode
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEventLogBuildRemote(t *testing.T) {
+	cases := []struct {
+		name      string
+		remote    RemoteConfig
+		expectErr bool
+	}{
+		{
+			"Local",
+			RemoteConfig{},
+			false,
+		},
+		{
+			"Remote",
+			RemoteConfig{Server: "dc01", Username: "collector", Password: "secret"},
+			false,
+		},
+		{
+			"MissingServer",
+			RemoteConfig{Username: "collector", Password: "secret"},
+			true,
+		},
+		{
+			"InvalidAuthentication",
+			RemoteConfig{Server: "dc01", Authentication: "basic"},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewDefaultConfig()
+			cfg.Channel = "security"
+			cfg.Remote = tc.remote
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEventLogBuildChannelPattern(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Channel = "Microsoft-Windows-*/Operational"
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	cfg.Channel = "Microsoft-Windows-[/Operational"
+	_, err = cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid channel pattern")
+}
+
+func TestEventLogBookmarkKey(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Channel = "security"
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	require.Equal(t, "security", ops[0].(*EventLogInput).bookmarkKey("security"))
+
+	cfg.Remote = RemoteConfig{Server: "dc01"}
+	ops, err = cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	require.Equal(t, "dc01/security", ops[0].(*EventLogInput).bookmarkKey("security"))
+}
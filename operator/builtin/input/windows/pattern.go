@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windows
+
+import (
+	"path"
+	"strings"
+)
+
+// isChannelPattern will check if a channel contains wildcards.
+func isChannelPattern(channel string) bool {
+	return strings.ContainsAny(channel, "*?[")
+}
+
+// matchChannels will return the channels that match the pattern, ignoring case.
+// As with path.Match, a `*` does not match the `/` which separates a channel from its type.
+func matchChannels(pattern string, channels []string) ([]string, error) {
+	pattern = strings.ToLower(pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, channel := range channels {
+		if ok, _ := path.Match(pattern, strings.ToLower(channel)); ok {
+			matches = append(matches, channel)
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windows
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsChannelPattern(t *testing.T) {
+	require.False(t, isChannelPattern("Security"))
+	require.False(t, isChannelPattern("Microsoft-Windows-Sysmon/Operational"))
+	require.True(t, isChannelPattern("Microsoft-Windows-*/Operational"))
+	require.True(t, isChannelPattern("Application?"))
+	require.True(t, isChannelPattern("[AS]*"))
+}
+
+func TestMatchChannels(t *testing.T) {
+	channels := []string{
+		"Application",
+		"ForwardedEvents",
+		"Security",
+		"System",
+		"Microsoft-Windows-Sysmon/Operational",
+		"Microsoft-Windows-PowerShell/Operational",
+		"Microsoft-Windows-PowerShell/Admin",
+		"Microsoft-Windows-TaskScheduler/Maintenance/Operational",
+	}
+
+	cases := []struct {
+		name     string
+		pattern  string
+		expected []string
+	}{
+		{
+			"Operational",
+			"Microsoft-Windows-*/Operational",
+			[]string{"Microsoft-Windows-Sysmon/Operational", "Microsoft-Windows-PowerShell/Operational"},
+		},
+		{
+			"IgnoreCase",
+			"microsoft-windows-powershell/*",
+			[]string{"Microsoft-Windows-PowerShell/Operational", "Microsoft-Windows-PowerShell/Admin"},
+		},
+		{
+			"SeparatorNotMatched",
+			"Microsoft-Windows-*",
+			nil,
+		},
+		{
+			"CharacterClass",
+			"S[ey]*",
+			[]string{"Security", "System"},
+		},
+		{
+			"NoMatches",
+			"Setup*",
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			matches, err := matchChannels(tc.pattern, channels)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, matches)
+		})
+	}
+}
+
+func TestMatchChannelsInvalidPattern(t *testing.T) {
+	_, err := matchChannels("Microsoft-[", nil)
+	require.Error(t, err)
+}
@@ -24,7 +24,7 @@ import (
 
 func TestPublisherOpenPreexisting(t *testing.T) {
 	publisher := Publisher{handle: 5}
-	err := publisher.Open("")
+	err := publisher.Open(NewSession(), "")
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "publisher handle is already open")
 }
@@ -32,7 +32,7 @@ func TestPublisherOpenPreexisting(t *testing.T) {
 func TestPublisherOpenInvalidUTF8(t *testing.T) {
 	publisher := NewPublisher()
 	invalidUTF8 := "\u0000"
-	err := publisher.Open(invalidUTF8)
+	err := publisher.Open(NewSession(), invalidUTF8)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "failed to convert provider to utf16")
 }
@@ -41,7 +41,7 @@ func TestPublisherOpenSyscallFailure(t *testing.T) {
 	publisher := NewPublisher()
 	provider := "provider"
 	openPublisherMetadataProc = SimpleMockProc(0, 0, ErrorNotSupported)
-	err := publisher.Open(provider)
+	err := publisher.Open(NewSession(), provider)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "failed to open publisher handle")
 }
@@ -50,7 +50,7 @@ func TestPublisherOpenSuccess(t *testing.T) {
 	publisher := NewPublisher()
 	provider := "provider"
 	openPublisherMetadataProc = SimpleMockProc(5, 0, ErrorSuccess)
-	err := publisher.Open(provider)
+	err := publisher.Open(NewSession(), provider)
 	require.NoError(t, err)
 	require.Equal(t, uintptr(5), publisher.handle)
 }
@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build windows
+
+package windows
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionOpenPreexisting(t *testing.T) {
+	session := Session{handle: 5}
+	err := session.Open(RemoteConfig{Server: "remote"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "session handle is already open")
+}
+
+func TestSessionOpenInvalidAuthentication(t *testing.T) {
+	session := NewSession()
+	err := session.Open(RemoteConfig{Server: "remote", Authentication: "basic"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid authentication")
+}
+
+func TestSessionOpenInvalidUTF8(t *testing.T) {
+	session := NewSession()
+	invalidUTF8 := "\u0000"
+	err := session.Open(RemoteConfig{Server: invalidUTF8})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to convert login info to utf16")
+}
+
+func TestSessionOpenSyscallFailure(t *testing.T) {
+	session := NewSession()
+	openSessionProc = SimpleMockProc(0, 0, ErrorNotSupported)
+	err := session.Open(RemoteConfig{Server: "remote", Username: "user", Password: "password"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to open session handle")
+}
+
+func TestSessionOpenSuccess(t *testing.T) {
+	session := NewSession()
+	openSessionProc = SimpleMockProc(5, 0, ErrorSuccess)
+	err := session.Open(RemoteConfig{Server: "remote", Username: "user", Password: "password", Authentication: "kerberos"})
+	require.NoError(t, err)
+	require.Equal(t, uintptr(5), session.handle)
+}
+
+func TestSessionCloseWhenAlreadyClosed(t *testing.T) {
+	session := NewSession()
+	err := session.Close()
+	require.NoError(t, err)
+}
+
+func TestSessionCloseSyscallFailure(t *testing.T) {
+	session := Session{handle: 5}
+	closeProc = SimpleMockProc(0, 0, ErrorNotSupported)
+	err := session.Close()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to close session handle")
+}
+
+func TestSessionCloseSuccess(t *testing.T) {
+	session := Session{handle: 5}
+	closeProc = SimpleMockProc(1, 0, ErrorSuccess)
+	err := session.Close()
+	require.NoError(t, err)
+	require.Equal(t, uintptr(0), session.handle)
+}
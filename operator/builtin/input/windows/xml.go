@@ -17,6 +17,7 @@ package windows
 import (
 	"encoding/xml"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
@@ -35,6 +36,19 @@ type EventXML struct {
 	Task        string      `xml:"RenderingInfo>Task"`
 	Opcode      string      `xml:"RenderingInfo>Opcode"`
 	Keywords    []string    `xml:"RenderingInfo>Keywords>Keyword"`
+
+	LevelValue   string      `xml:"System>Level"`
+	TaskValue    string      `xml:"System>Task"`
+	OpcodeValue  string      `xml:"System>Opcode"`
+	KeywordsMask string      `xml:"System>Keywords"`
+	Correlation  Correlation `xml:"System>Correlation"`
+	Execution    Execution   `xml:"System>Execution"`
+	Security     Security    `xml:"System>Security"`
+	EventData    EventData   `xml:"EventData"`
+	UserData     *xmlNode    `xml:"UserData"`
+
+	// Original is the xml the event was unmarshalled from.
+	Original string `xml:"-"`
 }
 
 // parseTimestamp will parse the timestamp of the event.
@@ -90,6 +104,52 @@ func (e *EventXML) parseBody() map[string]interface{} {
 	return body
 }
 
+// parseStructuredBody will parse a body from the event, including its typed event data and system properties.
+// The numeric system values are used for the level, task, opcode and keywords if they were not rendered.
+func (e *EventXML) parseStructuredBody() map[string]interface{} {
+	body := e.parseBody()
+
+	if e.Level == "" {
+		body["level"] = e.LevelValue
+	}
+	if e.Task == "" {
+		body["task"] = e.TaskValue
+	}
+	if e.Opcode == "" {
+		body["opcode"] = e.OpcodeValue
+	}
+	if len(e.Keywords) == 0 && e.KeywordsMask != "" {
+		body["keywords"] = []string{e.KeywordsMask}
+	}
+
+	body["event_data"] = e.EventData.parse()
+	if e.UserData != nil {
+		if userData, ok := e.UserData.parse().(map[string]interface{}); ok {
+			body["user_data"] = userData
+		}
+	}
+
+	if e.Correlation.ActivityID != "" || e.Correlation.RelatedActivityID != "" {
+		body["correlation"] = map[string]interface{}{
+			"activity_id":         e.Correlation.ActivityID,
+			"related_activity_id": e.Correlation.RelatedActivityID,
+		}
+	}
+
+	body["execution"] = map[string]interface{}{
+		"process_id": e.Execution.ProcessID,
+		"thread_id":  e.Execution.ThreadID,
+	}
+
+	if e.Security.UserID != "" {
+		body["security"] = map[string]interface{}{
+			"user_id": e.Security.UserID,
+		}
+	}
+
+	return body
+}
+
 // parseMessage will attempt to parse a message into a message and details
 func (e *EventXML) parseMessage() (string, map[string]interface{}) {
 	switch e.Channel {
@@ -106,6 +166,7 @@ func unmarshalEventXML(bytes []byte) (EventXML, error) {
 	if err := xml.Unmarshal(bytes, &eventXML); err != nil {
 		return EventXML{}, fmt.Errorf("failed to unmarshal xml bytes into event: %s", err)
 	}
+	eventXML.Original = string(bytes)
 	return eventXML, nil
 }
 
@@ -126,3 +187,74 @@ type Provider struct {
 	GUID            string `xml:"Guid,attr"`
 	EventSourceName string `xml:"EventSourceName,attr"`
 }
+
+// Correlation is the activity identifiers used to correlate the event with other events.
+type Correlation struct {
+	ActivityID        string `xml:"ActivityID,attr"`
+	RelatedActivityID string `xml:"RelatedActivityID,attr"`
+}
+
+// Execution is the process and thread that logged the event.
+type Execution struct {
+	ProcessID uint32 `xml:"ProcessID,attr"`
+	ThreadID  uint32 `xml:"ThreadID,attr"`
+}
+
+// Security is the security identifier of the user that logged the event.
+type Security struct {
+	UserID string `xml:"UserID,attr"`
+}
+
+// EventData is the typed data of the event.
+type EventData struct {
+	Data   []Data `xml:"Data"`
+	Binary string `xml:"Binary"`
+}
+
+// Data is a value of the event data, which may be named.
+type Data struct {
+	Name  string `xml:"Name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// parse will parse the event data into a map of names to values.
+// Values without a name are collected in order under the `data` key.
+func (d *EventData) parse() map[string]interface{} {
+	result := make(map[string]interface{}, len(d.Data))
+	var unnamed []string
+	for _, data := range d.Data {
+		if data.Name == "" {
+			unnamed = append(unnamed, data.Value)
+			continue
+		}
+		result[data.Name] = data.Value
+	}
+
+	if len(unnamed) > 0 {
+		result["data"] = unnamed
+	}
+	if d.Binary != "" {
+		result["binary"] = d.Binary
+	}
+	return result
+}
+
+// xmlNode is an arbitrary xml element, used for the provider defined user data of the event.
+type xmlNode struct {
+	XMLName xml.Name
+	Content string    `xml:",chardata"`
+	Nodes   []xmlNode `xml:",any"`
+}
+
+// parse will parse the node into its trimmed content, or a map of child names to values.
+func (n *xmlNode) parse() interface{} {
+	if len(n.Nodes) == 0 {
+		return strings.TrimSpace(n.Content)
+	}
+
+	result := make(map[string]interface{}, len(n.Nodes))
+	for _, node := range n.Nodes {
+		result[node.XMLName.Local] = node.parse()
+	}
+	return result
+}
@@ -15,6 +15,8 @@
 package windows
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -106,3 +108,81 @@ func TestParseBody(t *testing.T) {
 
 	require.Equal(t, expected, xml.parseBody())
 }
+
+func TestParseStructuredBodyEventData(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "xml", "eventdata.xml"))
+	require.NoError(t, err)
+
+	xml, err := unmarshalEventXML(data)
+	require.NoError(t, err)
+	require.Equal(t, string(data), xml.Original)
+
+	body := xml.parseStructuredBody()
+	require.Equal(t, "0", body["level"])
+	require.Equal(t, "12544", body["task"])
+	require.Equal(t, "0", body["opcode"])
+	require.Equal(t, []string{"0x8020000000000000"}, body["keywords"])
+	require.Equal(t, map[string]interface{}{
+		"SubjectUserSid": "S-1-5-18",
+		"TargetUserName": "alice",
+		"LogonType":      "3",
+		"IpAddress":      "10.0.0.5",
+	}, body["event_data"])
+	require.Equal(t, map[string]interface{}{
+		"activity_id":         "{ad0c2ed4-5b8c-0000-f42e-0cad8c5bd701}",
+		"related_activity_id": "",
+	}, body["correlation"])
+	require.Equal(t, map[string]interface{}{
+		"process_id": uint32(668),
+		"thread_id":  uint32(712),
+	}, body["execution"])
+	require.NotContains(t, body, "user_data")
+	require.NotContains(t, body, "security")
+}
+
+func TestParseStructuredBodyUserData(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "xml", "userdata.xml"))
+	require.NoError(t, err)
+
+	xml, err := unmarshalEventXML(data)
+	require.NoError(t, err)
+
+	body := xml.parseStructuredBody()
+	require.Equal(t, "Information", body["level"])
+	require.Equal(t, "Log clear", body["task"])
+	require.Equal(t, "Info", body["opcode"])
+	require.Equal(t, []string{"Audit Success"}, body["keywords"])
+	require.Equal(t, map[string]interface{}{}, body["event_data"])
+	require.Equal(t, map[string]interface{}{
+		"LogFileCleared": map[string]interface{}{
+			"SubjectUserSid":    "S-1-5-21-1004336348-1177238915-682003330-500",
+			"SubjectUserName":   "admin",
+			"SubjectDomainName": "EXAMPLE",
+		},
+	}, body["user_data"])
+	require.Equal(t, map[string]interface{}{
+		"activity_id":         "{ad0c2ed4-5b8c-0000-f42e-0cad8c5bd701}",
+		"related_activity_id": "{00000000-0000-0000-0000-000000000001}",
+	}, body["correlation"])
+	require.Equal(t, map[string]interface{}{
+		"user_id": "S-1-5-21-1004336348-1177238915-682003330-500",
+	}, body["security"])
+}
+
+func TestParseEventDataUnnamed(t *testing.T) {
+	eventData := EventData{
+		Data: []Data{
+			{Value: "first"},
+			{Name: "named", Value: "value"},
+			{Value: "second"},
+		},
+		Binary: "0A0B",
+	}
+
+	expected := map[string]interface{}{
+		"named":  "value",
+		"data":   []string{"first", "second"},
+		"binary": "0A0B",
+	}
+	require.Equal(t, expected, eventData.parse())
+}
@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// magicByte is the first byte of a message in the Confluent wire format, which is followed
+// by the 4 byte big endian ID of its schema.
+const magicByte = 0
+
+func init() {
+	operator.Register("avro_parser", func() operator.Builder { return NewAvroParserConfig("") })
+}
+
+// NewAvroParserConfig creates a new avro parser config with default values
+func NewAvroParserConfig(operatorID string) *AvroParserConfig {
+	return &AvroParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "avro_parser"),
+	}
+}
+
+// AvroParserConfig is the configuration of an avro parser operator.
+type AvroParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	Schema         string                `mapstructure:"schema,omitempty"          json:"schema,omitempty"          yaml:"schema,omitempty"`
+	SchemaRegistry *SchemaRegistryConfig `mapstructure:"schema_registry,omitempty" json:"schema_registry,omitempty" yaml:"schema_registry,omitempty"`
+}
+
+// Build will build an avro parser operator.
+func (c AvroParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	avroParser := &AvroParser{
+		ParserOperator: parserOperator,
+	}
+
+	switch {
+	case c.Schema != "" && c.SchemaRegistry != nil:
+		return nil, fmt.Errorf("only one of 'schema' or 'schema_registry' can be configured")
+	case c.Schema != "":
+		avroParser.schema, err = parseSchema(c.Schema)
+		if err != nil {
+			return nil, err
+		}
+	case c.SchemaRegistry != nil:
+		avroParser.registry, err = c.SchemaRegistry.build()
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("one of 'schema' or 'schema_registry' must be configured")
+	}
+
+	return []operator.Operator{avroParser}, nil
+}
+
+// AvroParser is an operator that parses avro encoded data.
+type AvroParser struct {
+	helper.ParserOperator
+	schema   *schema
+	registry *registryClient
+}
+
+// Process will parse an entry field as avro.
+func (p *AvroParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ParserOperator.ProcessWith(ctx, entry, func(value interface{}) (interface{}, error) {
+		return p.parse(ctx, value)
+	})
+}
+
+// parse will parse a value as avro. When a schema registry is configured, the value must
+// be in the Confluent wire format, which identifies the schema it was encoded with.
+func (p *AvroParser) parse(ctx context.Context, value interface{}) (interface{}, error) {
+	var data []byte
+	switch m := value.(type) {
+	case string:
+		data = []byte(m)
+	case []byte:
+		data = m
+	default:
+		return nil, fmt.Errorf("type %T cannot be parsed as avro", value)
+	}
+
+	if p.registry == nil {
+		return p.schema.decode(data)
+	}
+
+	if len(data) < 5 || data[0] != magicByte {
+		return nil, fmt.Errorf("data is not in the schema registry wire format")
+	}
+
+	s, err := p.registry.schema(ctx, binary.BigEndian.Uint32(data[1:5]))
+	if err != nil {
+		return nil, err
+	}
+	return s.decode(data[5:])
+}
@@ -0,0 +1,254 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+const testSchema = `{
+	"type": "record",
+	"name": "Log",
+	"fields": [
+		{"name": "message", "type": "string"},
+		{"name": "level", "type": {"type": "enum", "name": "Level", "symbols": ["INFO", "ERROR"]}},
+		{"name": "trace_id", "type": ["null", "string"]}
+	]
+}`
+
+func testRecord() []byte {
+	return concat(encodeString("request served"), encodeLong(1), encodeLong(1), encodeString("abc"))
+}
+
+func expectedRecord() map[string]interface{} {
+	return map[string]interface{}{
+		"message":  "request served",
+		"level":    "ERROR",
+		"trace_id": "abc",
+	}
+}
+
+// wireFormat will prefix data with the magic byte and schema ID of the Confluent wire format.
+func wireFormat(id byte, data []byte) []byte {
+	return append([]byte{magicByte, 0, 0, 0, id}, data...)
+}
+
+// newTestRegistry will start a schema registry which serves the test schema with ID 1, and counts its requests.
+func newTestRegistry(t *testing.T) (*httptest.Server, *int64) {
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+
+		if username, password, ok := r.BasicAuth(); ok && (username != "user" || password != "pass") {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error_code": 401, "message": "Unauthorized"}`)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/schemas/ids/1":
+			json.NewEncoder(w).Encode(schemaResponse{Schema: testSchema})
+		case "/schemas/ids/2":
+			json.NewEncoder(w).Encode(schemaResponse{Schema: `{"type": "string"}`, SchemaType: "AVRO"})
+		case "/schemas/ids/3":
+			json.NewEncoder(w).Encode(schemaResponse{Schema: `{}`, SchemaType: "PROTOBUF"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code": 40403, "message": "Schema not found"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newTestParser(t *testing.T, modify func(*AvroParserConfig)) (*AvroParser, *testutil.FakeOutput) {
+	cfg := NewAvroParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	if modify != nil {
+		modify(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*AvroParser)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fakeOutput}))
+	return parser, fakeOutput
+}
+
+func TestAvroParserBuildFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*AvroParserConfig)
+		expected string
+	}{
+		{
+			"MissingSchema",
+			func(cfg *AvroParserConfig) {},
+			"one of 'schema' or 'schema_registry' must be configured",
+		},
+		{
+			"SchemaAndRegistry",
+			func(cfg *AvroParserConfig) {
+				cfg.Schema = `"string"`
+				cfg.SchemaRegistry = &SchemaRegistryConfig{Endpoint: "http://localhost:8081"}
+			},
+			"only one of 'schema' or 'schema_registry' can be configured",
+		},
+		{
+			"InvalidSchema",
+			func(cfg *AvroParserConfig) { cfg.Schema = `{"type": "record"}` },
+			"invalid avro schema",
+		},
+		{
+			"MissingEndpoint",
+			func(cfg *AvroParserConfig) { cfg.SchemaRegistry = &SchemaRegistryConfig{} },
+			"missing required field 'schema_registry.endpoint'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewAvroParserConfig("test")
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestAvroParserParseSchema(t *testing.T) {
+	parser, _ := newTestParser(t, func(cfg *AvroParserConfig) {
+		cfg.Schema = testSchema
+	})
+
+	actual, err := parser.parse(context.Background(), testRecord())
+	require.NoError(t, err)
+	require.Equal(t, expectedRecord(), actual)
+
+	actual, err = parser.parse(context.Background(), string(testRecord()))
+	require.NoError(t, err)
+	require.Equal(t, expectedRecord(), actual)
+
+	_, err = parser.parse(context.Background(), 1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "type int cannot be parsed as avro")
+}
+
+func TestAvroParserParseSchemaRegistry(t *testing.T) {
+	server, requests := newTestRegistry(t)
+
+	parser, _ := newTestParser(t, func(cfg *AvroParserConfig) {
+		cfg.SchemaRegistry = &SchemaRegistryConfig{
+			Endpoint: server.URL + "/",
+			Username: "user",
+			Password: "pass",
+		}
+	})
+
+	actual, err := parser.parse(context.Background(), wireFormat(1, testRecord()))
+	require.NoError(t, err)
+	require.Equal(t, expectedRecord(), actual)
+
+	actual, err = parser.parse(context.Background(), wireFormat(2, encodeString("plain")))
+	require.NoError(t, err)
+	require.Equal(t, "plain", actual)
+
+	// Schemas are only fetched once
+	actual, err = parser.parse(context.Background(), wireFormat(1, testRecord()))
+	require.NoError(t, err)
+	require.Equal(t, expectedRecord(), actual)
+	require.Equal(t, int64(2), atomic.LoadInt64(requests))
+}
+
+func TestAvroParserParseSchemaRegistryErrors(t *testing.T) {
+	server, requests := newTestRegistry(t)
+
+	cases := []struct {
+		name     string
+		password string
+		input    []byte
+		expected string
+	}{
+		{"Short", "pass", []byte{magicByte, 0, 0}, "data is not in the schema registry wire format"},
+		{"InvalidMagicByte", "pass", append([]byte{1}, wireFormat(1, testRecord())[1:]...), "data is not in the schema registry wire format"},
+		{"UnknownSchema", "pass", wireFormat(9, testRecord()), "fetch schema 9: request failed with status 404"},
+		{"UnsupportedSchemaType", "pass", wireFormat(3, testRecord()), "fetch schema 3: unsupported schema type 'PROTOBUF'"},
+		{"Unauthorized", "wrong", wireFormat(1, testRecord()), "fetch schema 1: request failed with status 401"},
+		{"InvalidData", "pass", wireFormat(1, encodeLong(50)), "unexpected end of data"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, func(cfg *AvroParserConfig) {
+				cfg.SchemaRegistry = &SchemaRegistryConfig{
+					Endpoint: server.URL,
+					Username: "user",
+					Password: tc.password,
+				}
+			})
+
+			_, err := parser.parse(context.Background(), tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+
+	// Failures to fetch a schema are not cached
+	parser, _ := newTestParser(t, func(cfg *AvroParserConfig) {
+		cfg.SchemaRegistry = &SchemaRegistryConfig{Endpoint: server.URL}
+	})
+	before := atomic.LoadInt64(requests)
+	for i := 0; i < 2; i++ {
+		_, err := parser.parse(context.Background(), wireFormat(9, testRecord()))
+		require.Error(t, err)
+	}
+	require.Equal(t, before+2, atomic.LoadInt64(requests))
+}
+
+func TestAvroParserProcess(t *testing.T) {
+	server, _ := newTestRegistry(t)
+
+	parser, fakeOutput := newTestParser(t, func(cfg *AvroParserConfig) {
+		cfg.SchemaRegistry = &SchemaRegistryConfig{Endpoint: server.URL}
+	})
+
+	e := entry.New()
+	e.Body = wireFormat(1, testRecord())
+	require.NoError(t, parser.Process(context.Background(), e))
+
+	select {
+	case out := <-fakeOutput.Received:
+		require.Equal(t, expectedRecord(), out.Body)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestAvroParserConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "schema",
+			Expect: func() *AvroParserConfig {
+				cfg := defaultCfg()
+				cfg.Schema = `{"type": "record", "name": "Log", "fields": [{"name": "message", "type": "string"}]}` + "\n"
+				return cfg
+			}(),
+		},
+		{
+			Name: "schema_registry",
+			Expect: func() *AvroParserConfig {
+				cfg := defaultCfg()
+				cfg.SchemaRegistry = &SchemaRegistryConfig{
+					Endpoint: "http://localhost:8081",
+					Username: "user",
+					Password: "pass",
+					Timeout:  helper.NewDuration(5 * time.Second),
+				}
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *AvroParserConfig {
+	return NewAvroParserConfig("avro_parser")
+}
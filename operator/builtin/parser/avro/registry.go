@@ -0,0 +1,143 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// DefaultRegistryTimeout is the timeout of requests to a schema registry, if not configured
+const DefaultRegistryTimeout = 10 * time.Second
+
+// SchemaRegistryConfig is the configuration of a Confluent Schema Registry client.
+type SchemaRegistryConfig struct {
+	Endpoint string                  `mapstructure:"endpoint"           json:"endpoint"           yaml:"endpoint"`
+	Username string                  `mapstructure:"username,omitempty" json:"username,omitempty" yaml:"username,omitempty"`
+	Password string                  `mapstructure:"password,omitempty" json:"password,omitempty" yaml:"password,omitempty"`
+	Timeout  helper.Duration         `mapstructure:"timeout,omitempty"  json:"timeout,omitempty"  yaml:"timeout,omitempty"`
+	TLS      *helper.TLSClientConfig `mapstructure:"tls,omitempty"      json:"tls,omitempty"      yaml:"tls,omitempty"`
+}
+
+// build will build a client of the schema registry.
+func (c SchemaRegistryConfig) build() (*registryClient, error) {
+	if c.Endpoint == "" {
+		return nil, fmt.Errorf("missing required field 'schema_registry.endpoint'")
+	}
+
+	timeout := c.Timeout.Raw()
+	if timeout == 0 {
+		timeout = DefaultRegistryTimeout
+	}
+
+	transport := &http.Transport{}
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.LoadTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &registryClient{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+		},
+		endpoint: strings.TrimSuffix(c.Endpoint, "/"),
+		username: c.Username,
+		password: c.Password,
+		schemas:  map[uint32]*schema{},
+	}, nil
+}
+
+// registryClient is a minimal client of the Confluent Schema Registry API, which caches the schemas it fetches.
+type registryClient struct {
+	client   *http.Client
+	endpoint string
+	username string
+	password string
+
+	mux     sync.RWMutex
+	schemas map[uint32]*schema
+}
+
+// schemaResponse is the response to a request for a schema by its ID
+type schemaResponse struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType"`
+}
+
+// schema will get the schema with an ID, fetching it from the registry if it is not cached.
+func (c *registryClient) schema(ctx context.Context, id uint32) (*schema, error) {
+	c.mux.RLock()
+	s, ok := c.schemas[id]
+	c.mux.RUnlock()
+	if ok {
+		return s, nil
+	}
+
+	s, err := c.fetch(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("fetch schema %d: %s", id, err)
+	}
+
+	c.mux.Lock()
+	c.schemas[id] = s
+	c.mux.Unlock()
+	return s, nil
+}
+
+func (c *registryClient) fetch(ctx context.Context, id uint32) (*schema, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/schemas/ids/%d", c.endpoint, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var response schemaResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	if response.SchemaType != "" && response.SchemaType != "AVRO" {
+		return nil, fmt.Errorf("unsupported schema type '%s'", response.SchemaType)
+	}
+	return parseSchema(response.Schema)
+}
@@ -0,0 +1,424 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// schema is a parsed Avro schema.
+type schema struct {
+	// typ is the name of a primitive type, or one of record, enum, array, map, union or fixed
+	typ string
+
+	// name is the full name of a named type
+	name string
+
+	fields   []field
+	symbols  []string
+	items    *schema
+	values   *schema
+	branches []*schema
+	size     int
+}
+
+// field is a field of a record.
+type field struct {
+	name   string
+	schema *schema
+}
+
+// primitiveTypes are the names of the Avro primitive types.
+var primitiveTypes = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"int":     true,
+	"long":    true,
+	"float":   true,
+	"double":  true,
+	"bytes":   true,
+	"string":  true,
+}
+
+// parseSchema will parse an Avro schema from its JSON representation.
+func parseSchema(text string) (*schema, error) {
+	var raw interface{}
+	if err := json.Unmarshal([]byte(text), &raw); err != nil {
+		return nil, fmt.Errorf("invalid avro schema: %s", err)
+	}
+
+	p := &schemaParser{named: map[string]*schema{}}
+	s, err := p.parse(raw, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid avro schema: %s", err)
+	}
+	return s, nil
+}
+
+// schemaParser parses a schema, keeping track of the named types it has defined.
+type schemaParser struct {
+	named map[string]*schema
+}
+
+func (p *schemaParser) parse(raw interface{}, namespace string) (*schema, error) {
+	switch r := raw.(type) {
+	case string:
+		if primitiveTypes[r] {
+			return &schema{typ: r}, nil
+		}
+		return p.reference(r, namespace)
+	case []interface{}:
+		union := &schema{typ: "union"}
+		for _, branch := range r {
+			s, err := p.parse(branch, namespace)
+			if err != nil {
+				return nil, err
+			}
+			union.branches = append(union.branches, s)
+		}
+		return union, nil
+	case map[string]interface{}:
+		return p.parseComplex(r, namespace)
+	default:
+		return nil, fmt.Errorf("unexpected %T in schema", raw)
+	}
+}
+
+// reference will find a named type which has already been defined.
+func (p *schemaParser) reference(name, namespace string) (*schema, error) {
+	if !strings.Contains(name, ".") && namespace != "" {
+		if s, ok := p.named[namespace+"."+name]; ok {
+			return s, nil
+		}
+	}
+	if s, ok := p.named[name]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown type '%s'", name)
+}
+
+func (p *schemaParser) parseComplex(raw map[string]interface{}, namespace string) (*schema, error) {
+	typ, ok := raw["type"].(string)
+	if !ok {
+		// The type is itself a schema, such as {"type": {"type": "array", ...}}
+		if nested, ok := raw["type"]; ok {
+			return p.parse(nested, namespace)
+		}
+		return nil, fmt.Errorf("missing type")
+	}
+
+	switch typ {
+	case "record", "error":
+		s, namespace, err := p.define(raw, "record", namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		rawFields, ok := raw["fields"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("record '%s' is missing fields", s.name)
+		}
+		for _, rawField := range rawFields {
+			f, ok := rawField.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid field of record '%s'", s.name)
+			}
+			name, ok := f["name"].(string)
+			if !ok {
+				return nil, fmt.Errorf("field of record '%s' is missing a name", s.name)
+			}
+			fieldType, ok := f["type"]
+			if !ok {
+				return nil, fmt.Errorf("field '%s' of record '%s' is missing a type", name, s.name)
+			}
+			fieldSchema, err := p.parse(fieldType, namespace)
+			if err != nil {
+				return nil, err
+			}
+			s.fields = append(s.fields, field{name: name, schema: fieldSchema})
+		}
+		return s, nil
+	case "enum":
+		s, _, err := p.define(raw, "enum", namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		rawSymbols, ok := raw["symbols"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("enum '%s' is missing symbols", s.name)
+		}
+		for _, rawSymbol := range rawSymbols {
+			symbol, ok := rawSymbol.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid symbol of enum '%s'", s.name)
+			}
+			s.symbols = append(s.symbols, symbol)
+		}
+		return s, nil
+	case "fixed":
+		s, _, err := p.define(raw, "fixed", namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		size, ok := raw["size"].(float64)
+		if !ok || size < 0 || size != math.Trunc(size) {
+			return nil, fmt.Errorf("fixed '%s' is missing a valid size", s.name)
+		}
+		s.size = int(size)
+		return s, nil
+	case "array":
+		items, ok := raw["items"]
+		if !ok {
+			return nil, fmt.Errorf("array is missing items")
+		}
+		itemSchema, err := p.parse(items, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &schema{typ: "array", items: itemSchema}, nil
+	case "map":
+		values, ok := raw["values"]
+		if !ok {
+			return nil, fmt.Errorf("map is missing values")
+		}
+		valueSchema, err := p.parse(values, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &schema{typ: "map", values: valueSchema}, nil
+	default:
+		// A primitive type, possibly annotated with a logical type, or a reference
+		return p.parse(typ, namespace)
+	}
+}
+
+// define will create a named type, and register it so that it may be referenced. The namespace
+// of the type is returned, as it is the enclosing namespace of any types defined within it.
+func (p *schemaParser) define(raw map[string]interface{}, typ, namespace string) (*schema, string, error) {
+	name, ok := raw["name"].(string)
+	if !ok || name == "" {
+		return nil, "", fmt.Errorf("%s is missing a name", typ)
+	}
+
+	if ns, ok := raw["namespace"].(string); ok {
+		namespace = ns
+	}
+
+	fullName := name
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		namespace = name[:i]
+	} else if namespace != "" {
+		fullName = namespace + "." + name
+	}
+
+	if _, ok := p.named[fullName]; ok {
+		return nil, "", fmt.Errorf("type '%s' is defined more than once", fullName)
+	}
+
+	s := &schema{typ: typ, name: fullName}
+	p.named[fullName] = s
+	return s, namespace, nil
+}
+
+// decode will decode a datum which is encoded with the Avro binary encoding.
+func (s *schema) decode(data []byte) (interface{}, error) {
+	d := &decoder{data: data}
+	value, err := d.decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if remaining := len(d.data) - d.pos; remaining > 0 {
+		return nil, fmt.Errorf("unexpected %d bytes after datum", remaining)
+	}
+	return value, nil
+}
+
+// errUnexpectedEnd is returned when the data ends before a datum is decoded.
+var errUnexpectedEnd = fmt.Errorf("unexpected end of data")
+
+// decoder decodes values from a buffer.
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) decode(s *schema) (interface{}, error) {
+	switch s.typ {
+	case "null":
+		return nil, nil
+	case "boolean":
+		b, err := d.read(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+	case "int", "long":
+		return d.readLong()
+	case "float":
+		b, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), nil
+	case "double":
+		b, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case "bytes":
+		b, err := d.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	case "string":
+		b, err := d.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case "fixed":
+		b, err := d.read(s.size)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	case "enum":
+		index, err := d.readLong()
+		if err != nil {
+			return nil, err
+		}
+		if index < 0 || index >= int64(len(s.symbols)) {
+			return nil, fmt.Errorf("invalid index %d of enum '%s'", index, s.name)
+		}
+		return s.symbols[index], nil
+	case "union":
+		index, err := d.readLong()
+		if err != nil {
+			return nil, err
+		}
+		if index < 0 || index >= int64(len(s.branches)) {
+			return nil, fmt.Errorf("invalid index %d of union", index)
+		}
+		return d.decode(s.branches[index])
+	case "record":
+		record := make(map[string]interface{}, len(s.fields))
+		for _, f := range s.fields {
+			value, err := d.decode(f.schema)
+			if err != nil {
+				return nil, err
+			}
+			record[f.name] = value
+		}
+		return record, nil
+	case "array":
+		array := []interface{}{}
+		err := d.readBlocks(func() error {
+			value, err := d.decode(s.items)
+			if err != nil {
+				return err
+			}
+			array = append(array, value)
+			return nil
+		})
+		return array, err
+	case "map":
+		values := map[string]interface{}{}
+		err := d.readBlocks(func() error {
+			key, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			value, err := d.decode(s.values)
+			if err != nil {
+				return err
+			}
+			values[string(key)] = value
+			return nil
+		})
+		return values, err
+	default:
+		return nil, fmt.Errorf("unsupported type '%s'", s.typ)
+	}
+}
+
+// read will read a number of bytes.
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.pos {
+		return nil, errUnexpectedEnd
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// readLong will read a zig-zag encoded variable length integer.
+func (d *decoder) readLong() (int64, error) {
+	value, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, errUnexpectedEnd
+	}
+	d.pos += n
+	return int64(value>>1) ^ -int64(value&1), nil
+}
+
+// readBytes will read a length prefixed sequence of bytes.
+func (d *decoder) readBytes() ([]byte, error) {
+	length, err := d.readLong()
+	if err != nil {
+		return nil, err
+	}
+	if length > int64(len(d.data)-d.pos) {
+		return nil, errUnexpectedEnd
+	}
+	return d.read(int(length))
+}
+
+// readBlocks will read the blocks of an array or map, calling readItem for each of their items.
+func (d *decoder) readBlocks(readItem func() error) error {
+	for {
+		count, err := d.readLong()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		if count < 0 {
+			// A negative count is followed by the size of the block in bytes
+			count = -count
+			if _, err := d.readLong(); err != nil {
+				return err
+			}
+		}
+		// Guard against counts which could not have been encoded in the remaining data
+		if count > int64(len(d.data)-d.pos) {
+			return errUnexpectedEnd
+		}
+		for i := int64(0); i < count; i++ {
+			if err := readItem(); err != nil {
+				return err
+			}
+		}
+	}
+}
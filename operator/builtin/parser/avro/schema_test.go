@@ -0,0 +1,198 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// encodeLong will encode a value as a zig-zag variable length integer.
+func encodeLong(v int64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64((v<<1)^(v>>63)))
+	return buf[:n]
+}
+
+// encodeString will encode a length prefixed string.
+func encodeString(s string) []byte {
+	return append(encodeLong(int64(len(s))), s...)
+}
+
+// concat will concatenate encoded values.
+func concat(values ...[]byte) []byte {
+	var data []byte
+	for _, v := range values {
+		data = append(data, v...)
+	}
+	return data
+}
+
+func TestSchemaDecode(t *testing.T) {
+	float := make([]byte, 4)
+	binary.LittleEndian.PutUint32(float, math.Float32bits(1.5))
+	double := make([]byte, 8)
+	binary.LittleEndian.PutUint64(double, math.Float64bits(-2.25))
+
+	cases := []struct {
+		name     string
+		schema   string
+		data     []byte
+		expected interface{}
+	}{
+		{"Null", `"null"`, nil, nil},
+		{"BooleanTrue", `"boolean"`, []byte{1}, true},
+		{"BooleanFalse", `{"type": "boolean"}`, []byte{0}, false},
+		{"Int", `"int"`, encodeLong(-64), int64(-64)},
+		{"Long", `"long"`, encodeLong(1 << 40), int64(1 << 40)},
+		{"LogicalType", `{"type": "long", "logicalType": "timestamp-millis"}`, encodeLong(1600000000000), int64(1600000000000)},
+		{"Float", `"float"`, float, 1.5},
+		{"Double", `"double"`, double, -2.25},
+		{"Bytes", `"bytes"`, encodeString("raw"), "cmF3"},
+		{"String", `"string"`, encodeString("hello"), "hello"},
+		{"Fixed", `{"type": "fixed", "name": "Hash", "size": 2}`, []byte{0xff, 0x00}, "/wA="},
+		{"Enum", `{"type": "enum", "name": "Level", "symbols": ["INFO", "ERROR"]}`, encodeLong(1), "ERROR"},
+		{"UnionNull", `["null", "string"]`, encodeLong(0), nil},
+		{"UnionString", `["null", "string"]`, concat(encodeLong(1), encodeString("x")), "x"},
+		{
+			"Array",
+			`{"type": "array", "items": "long"}`,
+			concat(encodeLong(2), encodeLong(1), encodeLong(2), encodeLong(1), encodeLong(3), encodeLong(0)),
+			[]interface{}{int64(1), int64(2), int64(3)},
+		},
+		{
+			"ArrayBlockSize",
+			`{"type": "array", "items": "long"}`,
+			concat(encodeLong(-2), encodeLong(2), encodeLong(1), encodeLong(2), encodeLong(0)),
+			[]interface{}{int64(1), int64(2)},
+		},
+		{"EmptyArray", `{"type": "array", "items": "long"}`, encodeLong(0), []interface{}{}},
+		{
+			"Map",
+			`{"type": "map", "values": "string"}`,
+			concat(encodeLong(1), encodeString("k"), encodeString("v"), encodeLong(0)),
+			map[string]interface{}{"k": "v"},
+		},
+		{
+			"Record",
+			`{
+				"type": "record",
+				"name": "Log",
+				"namespace": "com.example",
+				"fields": [
+					{"name": "message", "type": "string"},
+					{"name": "level", "type": {"type": "enum", "name": "Level", "symbols": ["INFO", "ERROR"]}},
+					{"name": "previous", "type": ["null", "Level"]},
+					{"name": "source", "type": {"type": "record", "name": "Source", "fields": [{"name": "host", "type": "string"}]}},
+					{"name": "origin", "type": "com.example.Source"}
+				]
+			}`,
+			concat(encodeString("hi"), encodeLong(1), encodeLong(1), encodeLong(0), encodeString("a"), encodeString("b")),
+			map[string]interface{}{
+				"message":  "hi",
+				"level":    "ERROR",
+				"previous": "INFO",
+				"source":   map[string]interface{}{"host": "a"},
+				"origin":   map[string]interface{}{"host": "b"},
+			},
+		},
+		{
+			"RecursiveRecord",
+			`{"type": "record", "name": "Node", "fields": [{"name": "value", "type": "long"}, {"name": "next", "type": ["null", "Node"]}]}`,
+			concat(encodeLong(1), encodeLong(1), encodeLong(2), encodeLong(0)),
+			map[string]interface{}{
+				"value": int64(1),
+				"next": map[string]interface{}{
+					"value": int64(2),
+					"next":  nil,
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := parseSchema(tc.schema)
+			require.NoError(t, err)
+
+			actual, err := s.decode(tc.data)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestSchemaDecodeErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		schema   string
+		data     []byte
+		expected string
+	}{
+		{"Empty", `"long"`, nil, "unexpected end of data"},
+		{"ShortString", `"string"`, concat(encodeLong(5), []byte("abc")), "unexpected end of data"},
+		{"ShortDouble", `"double"`, []byte{1, 2}, "unexpected end of data"},
+		{"TrailingData", `"long"`, concat(encodeLong(1), encodeLong(2)), "unexpected 1 bytes after datum"},
+		{"InvalidEnumIndex", `{"type": "enum", "name": "Level", "symbols": ["INFO"]}`, encodeLong(3), "invalid index 3 of enum 'Level'"},
+		{"InvalidUnionIndex", `["null", "string"]`, encodeLong(2), "invalid index 2 of union"},
+		{"ArrayCountTooLarge", `{"type": "array", "items": "long"}`, encodeLong(1000), "unexpected end of data"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := parseSchema(tc.schema)
+			require.NoError(t, err)
+
+			_, err = s.decode(tc.data)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		schema   string
+		expected string
+	}{
+		{"InvalidJSON", `{`, "invalid avro schema"},
+		{"UnknownType", `"timestamp"`, "unknown type 'timestamp'"},
+		{"MissingType", `{"name": "x"}`, "missing type"},
+		{"MissingName", `{"type": "record", "fields": []}`, "record is missing a name"},
+		{"MissingFields", `{"type": "record", "name": "Log"}`, "record 'Log' is missing fields"},
+		{"MissingFieldType", `{"type": "record", "name": "Log", "fields": [{"name": "a"}]}`, "field 'a' of record 'Log' is missing a type"},
+		{"MissingSymbols", `{"type": "enum", "name": "Level"}`, "enum 'Level' is missing symbols"},
+		{"InvalidSize", `{"type": "fixed", "name": "Hash", "size": -1}`, "fixed 'Hash' is missing a valid size"},
+		{"MissingItems", `{"type": "array"}`, "array is missing items"},
+		{"MissingValues", `{"type": "map"}`, "map is missing values"},
+		{
+			"DuplicateName",
+			`["null", {"type": "fixed", "name": "a.Hash", "size": 1}, {"type": "fixed", "name": "Hash", "namespace": "a", "size": 2}]`,
+			"type 'a.Hash' is defined more than once",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseSchema(tc.schema)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
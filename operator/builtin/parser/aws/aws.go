@@ -0,0 +1,230 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// Formats of AWS logs
+const (
+	ALBFormat        = "alb"
+	ELBFormat        = "elb"
+	CloudTrailFormat = "cloudtrail"
+	VPCFlowFormat    = "vpc_flow"
+)
+
+func init() {
+	operator.Register("aws_parser", func() operator.Builder { return NewAWSParserConfig("") })
+}
+
+// NewAWSParserConfig creates a new AWS parser config with default values
+func NewAWSParserConfig(operatorID string) *AWSParserConfig {
+	return &AWSParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "aws_parser"),
+	}
+}
+
+// AWSParserConfig is the configuration of an AWS parser operator.
+type AWSParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	Format string   `mapstructure:"format"           json:"format"           yaml:"format"`
+	Fields []string `mapstructure:"fields,omitempty" json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// Build will build an AWS parser operator.
+func (c AWSParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	var timeField, timeLayoutType, timeLayout, severityField string
+	switch c.Format {
+	case ALBFormat:
+		timeField, timeLayoutType, timeLayout, severityField = "time", helper.GotimeKey, timeRFC3339, "elb_status_code"
+	case ELBFormat:
+		timeField, timeLayoutType, timeLayout, severityField = "timestamp", helper.GotimeKey, timeRFC3339, "elb_status_code"
+	case CloudTrailFormat:
+		timeField, timeLayoutType, timeLayout = "eventTime", helper.GotimeKey, timeRFC3339
+	case VPCFlowFormat:
+		timeField, timeLayoutType, timeLayout = "start", helper.EpochKey, "s"
+	case "":
+		return nil, fmt.Errorf("missing required field 'format'")
+	default:
+		return nil, fmt.Errorf("invalid format '%s', must be one of '%s', '%s', '%s' or '%s'",
+			c.Format, ALBFormat, ELBFormat, CloudTrailFormat, VPCFlowFormat)
+	}
+
+	if len(c.Fields) > 0 && c.Format != VPCFlowFormat {
+		return nil, fmt.Errorf("'fields' can only be set when 'format' is '%s'", VPCFlowFormat)
+	}
+
+	// The timestamp and severity are parsed from the fields within parse_to, unless they are configured
+	if parseTo, ok := c.ParseTo.FieldInterface.(entry.BodyField); ok {
+		if c.TimeParser == nil {
+			timeParser := helper.NewTimeParser()
+			parseFrom := entry.Field{FieldInterface: parseTo.Child(timeField)}
+			timeParser.ParseFrom = &parseFrom
+			timeParser.PreserveTo = &parseFrom
+			timeParser.LayoutType = timeLayoutType
+			timeParser.Layout = timeLayout
+			c.TimeParser = &timeParser
+		}
+		if c.SeverityParserConfig == nil && severityField != "" {
+			severityParser := helper.NewSeverityParserConfig()
+			parseFrom := entry.Field{FieldInterface: parseTo.Child(severityField)}
+			severityParser.ParseFrom = &parseFrom
+			severityParser.PreserveTo = &parseFrom
+			severityParser.Mapping = statusSeverity
+			c.SeverityParserConfig = &severityParser
+		}
+	}
+
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	awsParser := &AWSParser{
+		ParserOperator: parserOperator,
+		format:         c.Format,
+		json:           jsoniter.ConfigFastest,
+	}
+
+	if len(c.Fields) > 0 {
+		awsParser.fields = make([]string, 0, len(c.Fields))
+		for _, field := range c.Fields {
+			// Fields may be copied from the format of a flow log, such as ${srcaddr}
+			field = strings.TrimSuffix(strings.TrimPrefix(field, "${"), "}")
+			awsParser.fields = append(awsParser.fields, fieldKey(field))
+		}
+	}
+
+	return []operator.Operator{awsParser}, nil
+}
+
+// timeRFC3339 is the layout of timestamps in AWS logs, which may include fractional seconds
+const timeRFC3339 = "2006-01-02T15:04:05Z07:00"
+
+// statusSeverity maps the status codes of load balancer access logs to severities
+var statusSeverity = map[interface{}]interface{}{
+	"info":    []interface{}{helper.HTTP2xx, helper.HTTP3xx},
+	"warning": helper.HTTP4xx,
+	"error":   helper.HTTP5xx,
+}
+
+// AWSParser is an operator that parses logs generated by AWS services.
+type AWSParser struct {
+	helper.ParserOperator
+	format string
+	fields []string
+	json   jsoniter.API
+}
+
+// Process will parse an entry. A CloudTrail log file with multiple records
+// is split into an entry for each record.
+func (p *AWSParser) Process(ctx context.Context, e *entry.Entry) error {
+	switch p.format {
+	case ALBFormat:
+		return p.ParserOperator.ProcessWith(ctx, e, p.parseALB)
+	case ELBFormat:
+		return p.ParserOperator.ProcessWith(ctx, e, p.parseELB)
+	case VPCFlowFormat:
+		return p.ParserOperator.ProcessWith(ctx, e, p.parseVPCFlow)
+	default:
+		return p.processCloudTrail(ctx, e)
+	}
+}
+
+// processCloudTrail will parse the CloudTrail records of an entry, and write an entry for each record.
+func (p *AWSParser) processCloudTrail(ctx context.Context, e *entry.Entry) error {
+	skip, err := p.Skip(ctx, e)
+	if err != nil {
+		return p.HandleEntryError(ctx, e, err)
+	}
+	if skip {
+		p.Write(ctx, e)
+		return nil
+	}
+
+	value, ok := e.Get(p.ParseFrom)
+	if !ok {
+		// Handled by ProcessWith, as with any other missing field
+		return p.ParserOperator.ProcessWith(ctx, e, p.parseCloudTrailRecord)
+	}
+
+	records, err := p.cloudTrailRecords(value)
+	if err != nil {
+		return p.HandleEntryError(ctx, e, err)
+	}
+
+	// The log file is removed before the entry is copied for each record, so that it is not copied repeatedly
+	_, _ = e.Delete(p.ParseFrom)
+	for _, record := range records {
+		split := e.Copy()
+		if err := split.Set(p.ParseFrom, record); err != nil {
+			return p.HandleEntryError(ctx, e, err)
+		}
+		if err := p.ParserOperator.ProcessWith(ctx, split, p.parseCloudTrailRecord); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloudTrailRecords will return the records of a CloudTrail log file. A value
+// which does not contain a Records array is a single record.
+func (p *AWSParser) cloudTrailRecords(value interface{}) ([]interface{}, error) {
+	var document map[string]interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		document = v
+	case string:
+		if err := p.json.UnmarshalFromString(v, &document); err != nil {
+			return nil, fmt.Errorf("parse cloudtrail log: %s", err)
+		}
+	case []byte:
+		if err := p.json.Unmarshal(v, &document); err != nil {
+			return nil, fmt.Errorf("parse cloudtrail log: %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("type %T cannot be parsed as cloudtrail", value)
+	}
+
+	raw, ok := document["Records"]
+	if !ok {
+		return []interface{}{document}, nil
+	}
+
+	records, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cloudtrail Records must be an array, got %T", raw)
+	}
+	return records, nil
+}
+
+// parseCloudTrailRecord will validate a single CloudTrail record.
+func (p *AWSParser) parseCloudTrailRecord(value interface{}) (interface{}, error) {
+	record, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cloudtrail record must be an object, got %T", value)
+	}
+	return record, nil
+}
@@ -0,0 +1,370 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T, modify func(*AWSParserConfig)) (*AWSParser, *testutil.FakeOutput) {
+	cfg := NewAWSParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	modify(cfg)
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*AWSParser)
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+	return op, fake
+}
+
+func expectEntry(t *testing.T, fake *testutil.FakeOutput) *entry.Entry {
+	select {
+	case e := <-fake.Received:
+		return e
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+		return nil
+	}
+}
+
+func TestAWSParserBuildFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*AWSParserConfig)
+		expected string
+	}{
+		{
+			"MissingFormat",
+			func(cfg *AWSParserConfig) {},
+			"missing required field 'format'",
+		},
+		{
+			"InvalidFormat",
+			func(cfg *AWSParserConfig) {
+				cfg.Format = "s3"
+			},
+			"invalid format 's3'",
+		},
+		{
+			"FieldsWithoutVPCFlow",
+			func(cfg *AWSParserConfig) {
+				cfg.Format = ALBFormat
+				cfg.Fields = []string{"version"}
+			},
+			"'fields' can only be set when 'format' is 'vpc_flow'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewAWSParserConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestAWSParserALB(t *testing.T) {
+	op, fake := newTestParser(t, func(cfg *AWSParserConfig) {
+		cfg.Format = ALBFormat
+	})
+
+	e := entry.New()
+	e.Body = `http 2018-07-02T22:23:00.186641Z app/my-loadbalancer/50dc6c495c0c9188 192.168.131.39:2817 10.0.0.1:80 0.000 0.001 0.000 503 200 34 366 "GET http://www.example.com:80/ HTTP/1.1" "curl/7.46.0" - - arn:aws:elasticloadbalancing:us-east-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067 "Root=1-58337262-36d228ad5d99923122bbe354" "-" "-" 0 2018-07-02T22:22:48.364000Z "forward" "-" "-" "10.0.0.1:80" "200" "-" "-"`
+	require.NoError(t, op.Process(context.Background(), e))
+
+	result := expectEntry(t, fake)
+	require.Equal(t, map[string]interface{}{
+		"type":                     "http",
+		"time":                     "2018-07-02T22:23:00.186641Z",
+		"elb":                      "app/my-loadbalancer/50dc6c495c0c9188",
+		"client_ip":                "192.168.131.39",
+		"client_port":              "2817",
+		"target_ip":                "10.0.0.1",
+		"target_port":              "80",
+		"request_processing_time":  "0.000",
+		"target_processing_time":   "0.001",
+		"response_processing_time": "0.000",
+		"elb_status_code":          "503",
+		"target_status_code":       "200",
+		"received_bytes":           "34",
+		"sent_bytes":               "366",
+		"request":                  "GET http://www.example.com:80/ HTTP/1.1",
+		"request_method":           "GET",
+		"request_url":              "http://www.example.com:80/",
+		"request_protocol":         "HTTP/1.1",
+		"user_agent":               "curl/7.46.0",
+		"target_group_arn":         "arn:aws:elasticloadbalancing:us-east-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067",
+		"trace_id":                 "Root=1-58337262-36d228ad5d99923122bbe354",
+		"matched_rule_priority":    "0",
+		"request_creation_time":    "2018-07-02T22:22:48.364000Z",
+		"actions_executed":         "forward",
+		"target_list":              "10.0.0.1:80",
+		"target_status_code_list":  "200",
+	}, result.Body)
+	require.Equal(t, time.Date(2018, time.July, 2, 22, 23, 0, 186641000, time.UTC), result.Timestamp.UTC())
+	require.Equal(t, entry.Error, result.Severity)
+}
+
+func TestAWSParserELB(t *testing.T) {
+	op, fake := newTestParser(t, func(cfg *AWSParserConfig) {
+		cfg.Format = ELBFormat
+	})
+
+	e := entry.New()
+	e.Body = `2015-05-13T23:39:43.945958Z my-loadbalancer 192.168.131.39:2817 10.0.0.1:80 0.000073 0.001048 0.000057 200 200 0 29 "GET http://www.example.com:80/ HTTP/1.1" "curl/7.38.0" - -`
+	require.NoError(t, op.Process(context.Background(), e))
+
+	result := expectEntry(t, fake)
+	require.Equal(t, map[string]interface{}{
+		"timestamp":                "2015-05-13T23:39:43.945958Z",
+		"elb":                      "my-loadbalancer",
+		"client_ip":                "192.168.131.39",
+		"client_port":              "2817",
+		"backend_ip":               "10.0.0.1",
+		"backend_port":             "80",
+		"request_processing_time":  "0.000073",
+		"backend_processing_time":  "0.001048",
+		"response_processing_time": "0.000057",
+		"elb_status_code":          "200",
+		"backend_status_code":      "200",
+		"received_bytes":           "0",
+		"sent_bytes":               "29",
+		"request":                  "GET http://www.example.com:80/ HTTP/1.1",
+		"request_method":           "GET",
+		"request_url":              "http://www.example.com:80/",
+		"request_protocol":         "HTTP/1.1",
+		"user_agent":               "curl/7.38.0",
+	}, result.Body)
+	require.Equal(t, time.Date(2015, time.May, 13, 23, 39, 43, 945958000, time.UTC), result.Timestamp.UTC())
+	require.Equal(t, entry.Info, result.Severity)
+}
+
+func TestAWSParserAccessLogFailure(t *testing.T) {
+	op, _ := newTestParser(t, func(cfg *AWSParserConfig) {
+		cfg.Format = ALBFormat
+	})
+
+	_, err := op.parseALB("http 2018-07-02T22:23:00.186641Z")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "alb access log has 2 fields, expected at least 15")
+
+	_, err = op.parseALB(`http "unterminated`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unterminated quoted field")
+
+	_, err = op.parseALB(1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "type int cannot be parsed as alb")
+}
+
+func TestAWSParserVPCFlow(t *testing.T) {
+	v2 := map[string]interface{}{
+		"version":      "2",
+		"account_id":   "123456789010",
+		"interface_id": "eni-1235b8ca123456789",
+		"srcaddr":      "172.31.16.139",
+		"dstaddr":      "172.31.16.21",
+		"srcport":      "20641",
+		"dstport":      "22",
+		"protocol":     "6",
+		"packets":      "20",
+		"bytes":        "4249",
+		"start":        "1418530010",
+		"end":          "1418530070",
+		"action":       "ACCEPT",
+		"log_status":   "OK",
+	}
+
+	v5 := map[string]interface{}{}
+	for k, v := range v2 {
+		v5[k] = v
+	}
+	v5["version"] = "5"
+	v5["vpc_id"] = "vpc-abcdefab012345678"
+	v5["subnet_id"] = "subnet-aaaaaaaa012345678"
+	v5["instance_id"] = "i-01234567890123456"
+	v5["tcp_flags"] = "3"
+	v5["type"] = "IPv4"
+	v5["pkt_srcaddr"] = "172.31.16.139"
+	v5["pkt_dstaddr"] = "172.31.16.21"
+	v5["region"] = "us-east-1"
+	v5["az_id"] = "use1-az1"
+	v5["flow_direction"] = "ingress"
+	v5["traffic_path"] = "1"
+
+	cases := []struct {
+		name     string
+		fields   []string
+		input    string
+		expected map[string]interface{}
+	}{
+		{
+			"Version2",
+			nil,
+			"2 123456789010 eni-1235b8ca123456789 172.31.16.139 172.31.16.21 20641 22 6 20 4249 1418530010 1418530070 ACCEPT OK",
+			v2,
+		},
+		{
+			"Version5",
+			nil,
+			"5 123456789010 eni-1235b8ca123456789 172.31.16.139 172.31.16.21 20641 22 6 20 4249 1418530010 1418530070 ACCEPT OK vpc-abcdefab012345678 subnet-aaaaaaaa012345678 i-01234567890123456 3 IPv4 172.31.16.139 172.31.16.21 us-east-1 use1-az1 - - - - ingress 1",
+			v5,
+		},
+		{
+			"CustomFields",
+			[]string{"${version}", "${vpc-id}", "${srcaddr}", "${start}", "${pkt-src-aws-service}"},
+			"5 vpc-abcdefab012345678 172.31.16.139 1418530010 S3",
+			map[string]interface{}{
+				"version":             "5",
+				"vpc_id":              "vpc-abcdefab012345678",
+				"srcaddr":             "172.31.16.139",
+				"start":               "1418530010",
+				"pkt_src_aws_service": "S3",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			op, fake := newTestParser(t, func(cfg *AWSParserConfig) {
+				cfg.Format = VPCFlowFormat
+				cfg.Fields = tc.fields
+			})
+
+			e := entry.New()
+			e.Body = tc.input
+			require.NoError(t, op.Process(context.Background(), e))
+
+			result := expectEntry(t, fake)
+			require.Equal(t, tc.expected, result.Body)
+			require.Equal(t, time.Unix(1418530010, 0).UTC(), result.Timestamp.UTC())
+		})
+	}
+}
+
+func TestAWSParserVPCFlowFailure(t *testing.T) {
+	op, _ := newTestParser(t, func(cfg *AWSParserConfig) {
+		cfg.Format = VPCFlowFormat
+	})
+
+	_, err := op.parseVPCFlow("version account-id interface-id")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unknown version 'version'")
+
+	_, err = op.parseVPCFlow("3 123456789010 eni-1235b8ca123456789")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "vpc flow log has 3 fields, expected 21")
+
+	_, err = op.parseVPCFlow("")
+	require.Error(t, err)
+}
+
+func TestAWSParserCloudTrail(t *testing.T) {
+	op, fake := newTestParser(t, func(cfg *AWSParserConfig) {
+		cfg.Format = CloudTrailFormat
+	})
+
+	e := entry.New()
+	e.AddAttribute("file", "cloudtrail.json")
+	e.Body = `{"Records": [
+		{"eventVersion": "1.08", "eventTime": "2021-06-01T12:00:00Z", "eventSource": "s3.amazonaws.com", "eventName": "GetObject"},
+		{"eventVersion": "1.08", "eventTime": "2021-06-01T12:00:05Z", "eventSource": "ec2.amazonaws.com", "eventName": "RunInstances"}
+	]}`
+	require.NoError(t, op.Process(context.Background(), e))
+
+	first := expectEntry(t, fake)
+	require.Equal(t, map[string]interface{}{
+		"eventVersion": "1.08",
+		"eventTime":    "2021-06-01T12:00:00Z",
+		"eventSource":  "s3.amazonaws.com",
+		"eventName":    "GetObject",
+	}, first.Body)
+	require.Equal(t, time.Date(2021, time.June, 1, 12, 0, 0, 0, time.UTC), first.Timestamp.UTC())
+	require.Equal(t, "cloudtrail.json", first.Attributes["file"])
+
+	second := expectEntry(t, fake)
+	require.Equal(t, "RunInstances", second.Body.(map[string]interface{})["eventName"])
+	require.Equal(t, time.Date(2021, time.June, 1, 12, 0, 5, 0, time.UTC), second.Timestamp.UTC())
+	require.Equal(t, "cloudtrail.json", second.Attributes["file"])
+}
+
+func TestAWSParserCloudTrailSingleRecord(t *testing.T) {
+	op, fake := newTestParser(t, func(cfg *AWSParserConfig) {
+		cfg.Format = CloudTrailFormat
+		cfg.ParseFrom = entry.NewBodyField("message")
+		cfg.ParseTo = entry.NewBodyField("cloudtrail")
+	})
+
+	e := entry.New()
+	e.Body = map[string]interface{}{
+		"message": map[string]interface{}{
+			"eventTime": "2021-06-01T12:00:00Z",
+			"eventName": "GetObject",
+		},
+	}
+	require.NoError(t, op.Process(context.Background(), e))
+
+	result := expectEntry(t, fake)
+	require.Equal(t, map[string]interface{}{
+		"cloudtrail": map[string]interface{}{
+			"eventTime": "2021-06-01T12:00:00Z",
+			"eventName": "GetObject",
+		},
+	}, result.Body)
+	require.Equal(t, time.Date(2021, time.June, 1, 12, 0, 0, 0, time.UTC), result.Timestamp.UTC())
+}
+
+func TestAWSParserCloudTrailFailure(t *testing.T) {
+	op, _ := newTestParser(t, func(cfg *AWSParserConfig) {
+		cfg.Format = CloudTrailFormat
+		cfg.OnError = helper.DropOnError
+	})
+
+	cases := []struct {
+		name     string
+		body     interface{}
+		expected string
+	}{
+		{"InvalidJSON", `{"Records": [`, "parse cloudtrail log"},
+		{"RecordsNotArray", `{"Records": {}}`, "cloudtrail Records must be an array"},
+		{"RecordNotObject", `{"Records": ["event"]}`, "cloudtrail record must be an object"},
+		{"InvalidType", 1, "type int cannot be parsed as cloudtrail"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := entry.New()
+			e.Body = tc.body
+			err := op.Process(context.Background(), e)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
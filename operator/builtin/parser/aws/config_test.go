@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestAWSParserConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name: "alb",
+			Expect: func() *AWSParserConfig {
+				cfg := defaultCfg()
+				cfg.Format = ALBFormat
+				return cfg
+			}(),
+		},
+		{
+			Name: "cloudtrail",
+			Expect: func() *AWSParserConfig {
+				cfg := defaultCfg()
+				cfg.Format = CloudTrailFormat
+				cfg.ParseFrom = entry.NewBodyField("message")
+				return cfg
+			}(),
+		},
+		{
+			Name: "vpc_flow_fields",
+			Expect: func() *AWSParserConfig {
+				cfg := defaultCfg()
+				cfg.Format = VPCFlowFormat
+				cfg.Fields = []string{"${version}", "${vpc-id}", "${srcaddr}", "${dstaddr}", "${start}"}
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *AWSParserConfig {
+	return NewAWSParserConfig("aws_parser")
+}
@@ -0,0 +1,245 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// albFields are the fields of an Application Load Balancer access log entry, in order.
+// Fields which have been added to the format over time may be missing from older entries.
+var albFields = []string{
+	"type",
+	"time",
+	"elb",
+	"client",
+	"target",
+	"request_processing_time",
+	"target_processing_time",
+	"response_processing_time",
+	"elb_status_code",
+	"target_status_code",
+	"received_bytes",
+	"sent_bytes",
+	"request",
+	"user_agent",
+	"ssl_cipher",
+	"ssl_protocol",
+	"target_group_arn",
+	"trace_id",
+	"domain_name",
+	"chosen_cert_arn",
+	"matched_rule_priority",
+	"request_creation_time",
+	"actions_executed",
+	"redirect_url",
+	"error_reason",
+	"target_list",
+	"target_status_code_list",
+	"classification",
+	"classification_reason",
+}
+
+// elbFields are the fields of a Classic Load Balancer access log entry, in order.
+var elbFields = []string{
+	"timestamp",
+	"elb",
+	"client",
+	"backend",
+	"request_processing_time",
+	"backend_processing_time",
+	"response_processing_time",
+	"elb_status_code",
+	"backend_status_code",
+	"received_bytes",
+	"sent_bytes",
+	"request",
+	"user_agent",
+	"ssl_cipher",
+	"ssl_protocol",
+}
+
+// vpcFlowFields are the fields which were added to VPC flow logs in each version, in the
+// order of the default format. A flow log of a version contains the fields of all prior versions.
+var vpcFlowFields = map[string][]string{
+	"2": {"version", "account_id", "interface_id", "srcaddr", "dstaddr", "srcport", "dstport", "protocol", "packets", "bytes", "start", "end", "action", "log_status"},
+	"3": {"vpc_id", "subnet_id", "instance_id", "tcp_flags", "type", "pkt_srcaddr", "pkt_dstaddr"},
+	"4": {"region", "az_id", "sublocation_type", "sublocation_id"},
+	"5": {"pkt_src_aws_service", "pkt_dst_aws_service", "flow_direction", "traffic_path"},
+}
+
+// vpcFlowVersionFields will return the fields of a flow log which contains all fields up to a version.
+func vpcFlowVersionFields(version string) ([]string, bool) {
+	if _, ok := vpcFlowFields[version]; !ok {
+		return nil, false
+	}
+
+	var fields []string
+	for _, v := range []string{"2", "3", "4", "5"} {
+		fields = append(fields, vpcFlowFields[v]...)
+		if v == version {
+			break
+		}
+	}
+	return fields, true
+}
+
+// fieldKey will convert the name of a field, such as account-id, to the key it is parsed to.
+func fieldKey(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// parseALB will parse an Application Load Balancer access log entry.
+func (p *AWSParser) parseALB(value interface{}) (interface{}, error) {
+	return parseAccessLog(value, albFields, "alb")
+}
+
+// parseELB will parse a Classic Load Balancer access log entry.
+func (p *AWSParser) parseELB(value interface{}) (interface{}, error) {
+	return parseAccessLog(value, elbFields, "elb")
+}
+
+// parseAccessLog will parse a load balancer access log entry. The addresses of the client and
+// target are split into their ip and port, and the request is split into its method, url and protocol.
+func parseAccessLog(value interface{}, fields []string, format string) (interface{}, error) {
+	line, err := valueString(value, format)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := splitFields(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) < len(elbFields) {
+		return nil, fmt.Errorf("%s access log has %d fields, expected at least %d", format, len(tokens), len(elbFields))
+	}
+
+	parsed := make(map[string]interface{}, len(fields))
+	for i, token := range tokens {
+		if i >= len(fields) {
+			// Fields added to the format after these fields were known are ignored
+			break
+		}
+		if token == "-" {
+			continue
+		}
+
+		switch key := fields[i]; key {
+		case "client", "target", "backend":
+			host, port, err := net.SplitHostPort(token)
+			if err != nil {
+				parsed[key] = token
+				continue
+			}
+			parsed[key+"_ip"] = host
+			parsed[key+"_port"] = port
+		case "request":
+			parsed[key] = token
+			if parts := strings.SplitN(token, " ", 3); len(parts) == 3 {
+				parsed["request_method"] = parts[0]
+				parsed["request_url"] = parts[1]
+				parsed["request_protocol"] = parts[2]
+			}
+		default:
+			parsed[key] = token
+		}
+	}
+	return parsed, nil
+}
+
+// parseVPCFlow will parse a VPC flow log record. Unless the fields are configured, the fields
+// of the default format of the record's version are used. Fields without a value are omitted.
+func (p *AWSParser) parseVPCFlow(value interface{}) (interface{}, error) {
+	line, err := valueString(value, "vpc flow log")
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := strings.Fields(line)
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("vpc flow log is empty")
+	}
+
+	fields := p.fields
+	if fields == nil {
+		var ok bool
+		fields, ok = vpcFlowVersionFields(tokens[0])
+		if !ok {
+			return nil, fmt.Errorf("vpc flow log has unknown version '%s', configure 'fields' for a custom format", tokens[0])
+		}
+	}
+
+	if len(tokens) != len(fields) {
+		return nil, fmt.Errorf("vpc flow log has %d fields, expected %d", len(tokens), len(fields))
+	}
+
+	parsed := make(map[string]interface{}, len(fields))
+	for i, token := range tokens {
+		if token == "-" {
+			continue
+		}
+		parsed[fields[i]] = token
+	}
+	return parsed, nil
+}
+
+// valueString will return the string of a value to be parsed.
+func valueString(value interface{}, format string) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("type %T cannot be parsed as %s", value, format)
+	}
+}
+
+// splitFields will split a line into its space separated fields. A field may be
+// quoted, in which case it may contain spaces, and quotes escaped by a backslash.
+func splitFields(line string) ([]string, error) {
+	var fields []string
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ':
+			i++
+		case '"':
+			var field strings.Builder
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+				}
+				field.WriteByte(line[i])
+			}
+			if i == len(line) {
+				return nil, fmt.Errorf("unterminated quoted field")
+			}
+			i++
+			fields = append(fields, field.String())
+		default:
+			end := strings.IndexByte(line[i:], ' ')
+			if end < 0 {
+				end = len(line) - i
+			}
+			fields = append(fields, line[i:i+end])
+			i += end
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,268 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cef
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// headerFields are the names of the fields of a CEF header, in order.
+var headerFields = []string{"version", "device_vendor", "device_product", "device_version", "signature_id", "name", "severity"}
+
+// extensionKeyPattern matches a valid key of the extension section.
+var extensionKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.\[\]-]+$`)
+
+func init() {
+	operator.Register("cef_parser", func() operator.Builder { return NewCEFParserConfig("") })
+}
+
+// NewCEFParserConfig creates a new CEF parser config with default values
+func NewCEFParserConfig(operatorID string) *CEFParserConfig {
+	return &CEFParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "cef_parser"),
+	}
+}
+
+// CEFParserConfig is the configuration of a CEF parser operator.
+type CEFParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	MapSeverity bool `mapstructure:"map_severity,omitempty" json:"map_severity,omitempty" yaml:"map_severity,omitempty"`
+}
+
+// Build will build a CEF parser operator.
+func (c CEFParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	if c.MapSeverity && c.SeverityParserConfig != nil {
+		return nil, fmt.Errorf("map_severity can not be used with a severity parser")
+	}
+
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	cefParser := &CEFParser{
+		ParserOperator: parserOperator,
+		mapSeverity:    c.MapSeverity,
+	}
+
+	return []operator.Operator{cefParser}, nil
+}
+
+// CEFParser is an operator that parses CEF.
+type CEFParser struct {
+	helper.ParserOperator
+	mapSeverity bool
+}
+
+// Process will parse an entry field as CEF.
+func (p *CEFParser) Process(ctx context.Context, entry *entry.Entry) error {
+	if p.mapSeverity {
+		return p.ParserOperator.ProcessWithCallback(ctx, entry, p.parse, p.promoteSeverity)
+	}
+	return p.ParserOperator.ProcessWith(ctx, entry, p.parse)
+}
+
+// parse will parse a value as CEF. Any text before the CEF prefix, such as a syslog header, is ignored.
+func (p *CEFParser) parse(value interface{}) (interface{}, error) {
+	var raw string
+	switch m := value.(type) {
+	case string:
+		raw = m
+	case []byte:
+		raw = string(m)
+	default:
+		return nil, fmt.Errorf("type %T cannot be parsed as CEF", value)
+	}
+
+	start := strings.Index(raw, "CEF:")
+	if start < 0 {
+		return nil, fmt.Errorf("missing CEF prefix")
+	}
+	raw = raw[start+len("CEF:"):]
+
+	header, extension, err := splitHeader(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedValues := make(map[string]interface{}, len(headerFields)+1)
+	for i, field := range headerFields {
+		parsedValues[field] = header[i]
+	}
+
+	extensions, err := parseExtension(extension)
+	if err != nil {
+		return nil, err
+	}
+	parsedValues["extensions"] = extensions
+
+	return parsedValues, nil
+}
+
+// splitHeader will split the unescaped fields of a CEF header from the extension which follows it.
+func splitHeader(raw string) ([]string, string, error) {
+	header := make([]string, 0, len(headerFields))
+	var field strings.Builder
+	for i := 0; i < len(raw); i++ {
+		switch {
+		case raw[i] == '\\' && i+1 < len(raw) && (raw[i+1] == '|' || raw[i+1] == '\\'):
+			i++
+			field.WriteByte(raw[i])
+		case raw[i] == '|':
+			header = append(header, field.String())
+			field.Reset()
+			if len(header) == len(headerFields) {
+				return header, raw[i+1:], nil
+			}
+		default:
+			field.WriteByte(raw[i])
+		}
+	}
+	return nil, "", fmt.Errorf("invalid CEF header: expected %d fields, found %d", len(headerFields), len(header))
+}
+
+// parseExtension will parse the key value pairs of a CEF extension. Values may contain spaces,
+// and an equals sign which is not escaped is only the start of a new pair if it follows a space
+// and a valid key.
+func parseExtension(extension string) (map[string]interface{}, error) {
+	extensions := map[string]interface{}{}
+	extension = strings.TrimLeft(extension, " ")
+	if strings.TrimSpace(extension) == "" {
+		return extensions, nil
+	}
+
+	// Find the start of each key, and the position of the equals sign which follows it
+	type pair struct{ keyStart, equals int }
+	var pairs []pair
+	for i := 0; i < len(extension); i++ {
+		switch extension[i] {
+		case '\\':
+			i++
+		case '=':
+			keyStart := strings.LastIndexByte(extension[:i], ' ') + 1
+			if len(pairs) > 0 && keyStart <= pairs[len(pairs)-1].equals {
+				continue
+			}
+			if !extensionKeyPattern.MatchString(extension[keyStart:i]) {
+				continue
+			}
+			pairs = append(pairs, pair{keyStart: keyStart, equals: i})
+		}
+	}
+
+	if len(pairs) == 0 || pairs[0].keyStart != 0 {
+		return nil, fmt.Errorf("invalid CEF extension: expected a key at '%s'", extension)
+	}
+
+	for i, pr := range pairs {
+		end := len(extension)
+		if i+1 < len(pairs) {
+			end = pairs[i+1].keyStart
+		}
+		key := extension[pr.keyStart:pr.equals]
+		extensions[key] = unescapeValue(strings.TrimRight(extension[pr.equals+1:end], " "))
+	}
+	return extensions, nil
+}
+
+// unescapeValue will unescape an extension value.
+func unescapeValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		switch value[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case '=', '\\':
+			b.WriteByte(value[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// promoteSeverity will set the severity of an entry from its parsed CEF severity. Numeric severities
+// range from 0 to 10, and are grouped as Low (0-3), Medium (4-6), High (7-8) and Very-High (9-10).
+func (p *CEFParser) promoteSeverity(e *entry.Entry) error {
+	value, ok := e.Get(p.ParseTo)
+	if !ok {
+		return nil
+	}
+	parsed, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	severity, ok := parsed["severity"].(string)
+	if !ok {
+		return nil
+	}
+
+	e.Severity = mapSeverity(severity)
+	e.SeverityText = severity
+	return nil
+}
+
+// mapSeverity will map a CEF severity to an entry severity.
+func mapSeverity(severity string) entry.Severity {
+	if level, err := strconv.Atoi(severity); err == nil {
+		switch {
+		case level >= 0 && level <= 3:
+			return entry.Info
+		case level >= 4 && level <= 6:
+			return entry.Warning
+		case level >= 7 && level <= 8:
+			return entry.Error
+		case level >= 9 && level <= 10:
+			return entry.Critical
+		default:
+			return entry.Default
+		}
+	}
+
+	switch strings.ToLower(severity) {
+	case "low":
+		return entry.Info
+	case "medium":
+		return entry.Warning
+	case "high":
+		return entry.Error
+	case "very-high":
+		return entry.Critical
+	default:
+		return entry.Default
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cef
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T, modify func(*CEFParserConfig)) (*CEFParser, *testutil.FakeOutput) {
+	cfg := NewCEFParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	if modify != nil {
+		modify(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*CEFParser)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fakeOutput}))
+	return parser, fakeOutput
+}
+
+func TestCEFParserBuildFailure(t *testing.T) {
+	cfg := NewCEFParserConfig("test")
+	cfg.MapSeverity = true
+	severityField := entry.NewBodyField("severity")
+	severityParser := helper.NewSeverityParserConfig()
+	severityParser.ParseFrom = &severityField
+	cfg.SeverityParserConfig = &severityParser
+
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "map_severity can not be used with a severity parser")
+}
+
+func TestCEFParserParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    interface{}
+		expected map[string]interface{}
+	}{
+		{
+			"Simple",
+			"CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2 spt=1232",
+			map[string]interface{}{
+				"version":        "0",
+				"device_vendor":  "Security",
+				"device_product": "threatmanager",
+				"device_version": "1.0",
+				"signature_id":   "100",
+				"name":           "worm successfully stopped",
+				"severity":       "10",
+				"extensions": map[string]interface{}{
+					"src": "10.0.0.1",
+					"dst": "2.1.2.2",
+					"spt": "1232",
+				},
+			},
+		},
+		{
+			"Bytes",
+			[]byte("CEF:1|Vendor|Product|2|sig|name|Low|"),
+			map[string]interface{}{
+				"version":        "1",
+				"device_vendor":  "Vendor",
+				"device_product": "Product",
+				"device_version": "2",
+				"signature_id":   "sig",
+				"name":           "name",
+				"severity":       "Low",
+				"extensions":     map[string]interface{}{},
+			},
+		},
+		{
+			"SyslogPrefix",
+			"Sep 19 08:26:10 host CEF:0|Vendor|Product|1.0|42|Login|3|suser=jane",
+			map[string]interface{}{
+				"version":        "0",
+				"device_vendor":  "Vendor",
+				"device_product": "Product",
+				"device_version": "1.0",
+				"signature_id":   "42",
+				"name":           "Login",
+				"severity":       "3",
+				"extensions": map[string]interface{}{
+					"suser": "jane",
+				},
+			},
+		},
+		{
+			"EscapedHeader",
+			`CEF:0|security\|corp|threat\\manager|1.0|100|detected a \| in message|10|src=10.0.0.1`,
+			map[string]interface{}{
+				"version":        "0",
+				"device_vendor":  "security|corp",
+				"device_product": `threat\manager`,
+				"device_version": "1.0",
+				"signature_id":   "100",
+				"name":           "detected a | in message",
+				"severity":       "10",
+				"extensions": map[string]interface{}{
+					"src": "10.0.0.1",
+				},
+			},
+		},
+		{
+			"ExtensionValues",
+			`CEF:0|V|P|1|1|N|5|msg=Detected a threat. No action needed cs1Label=Rule Name cs1=block all request=https://example.com/a?b=c&d=e act=blocked`,
+			map[string]interface{}{
+				"version":        "0",
+				"device_vendor":  "V",
+				"device_product": "P",
+				"device_version": "1",
+				"signature_id":   "1",
+				"name":           "N",
+				"severity":       "5",
+				"extensions": map[string]interface{}{
+					"msg":      "Detected a threat. No action needed",
+					"cs1Label": "Rule Name",
+					"cs1":      "block all",
+					"request":  "https://example.com/a?b=c&d=e",
+					"act":      "blocked",
+				},
+			},
+		},
+		{
+			"ExtensionEscapes",
+			`CEF:0|V|P|1|1|N|5|msg=a\=b c\\d line1\nline2\r filePath=C:\\Windows\\ cs2=|pipe| empty=`,
+			map[string]interface{}{
+				"version":        "0",
+				"device_vendor":  "V",
+				"device_product": "P",
+				"device_version": "1",
+				"signature_id":   "1",
+				"name":           "N",
+				"severity":       "5",
+				"extensions": map[string]interface{}{
+					"msg":      "a=b c\\d line1\nline2\r",
+					"filePath": `C:\Windows\`,
+					"cs2":      "|pipe|",
+					"empty":    "",
+				},
+			},
+		},
+		{
+			"ExtensionSpaces",
+			"CEF:0|V|P|1|1|N|5|  src=10.0.0.1   dst=10.0.0.2  ",
+			map[string]interface{}{
+				"version":        "0",
+				"device_vendor":  "V",
+				"device_product": "P",
+				"device_version": "1",
+				"signature_id":   "1",
+				"name":           "N",
+				"severity":       "5",
+				"extensions": map[string]interface{}{
+					"src": "10.0.0.1",
+					"dst": "10.0.0.2",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, nil)
+			actual, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestCEFParserParseErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{"InvalidType", []int{}, "type []int cannot be parsed as CEF"},
+		{"MissingPrefix", "0|V|P|1|1|N|5|src=1", "missing CEF prefix"},
+		{"ShortHeader", "CEF:0|V|P|1|1|N", "expected 7 fields, found 5"},
+		{"MissingKey", "CEF:0|V|P|1|1|N|5|just text", "invalid CEF extension"},
+		{"TextBeforeKey", "CEF:0|V|P|1|1|N|5|text src=1", "invalid CEF extension"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, nil)
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestCEFParserMapSeverity(t *testing.T) {
+	cases := []struct {
+		severity string
+		expected entry.Severity
+	}{
+		{"0", entry.Info},
+		{"3", entry.Info},
+		{"4", entry.Warning},
+		{"6", entry.Warning},
+		{"7", entry.Error},
+		{"8", entry.Error},
+		{"9", entry.Critical},
+		{"10", entry.Critical},
+		{"11", entry.Default},
+		{"Low", entry.Info},
+		{"medium", entry.Warning},
+		{"High", entry.Error},
+		{"Very-High", entry.Critical},
+		{"Unknown", entry.Default},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.severity, func(t *testing.T) {
+			parser, fakeOutput := newTestParser(t, func(cfg *CEFParserConfig) {
+				cfg.MapSeverity = true
+			})
+
+			e := entry.New()
+			e.Body = "CEF:0|V|P|1|1|N|" + tc.severity + "|src=10.0.0.1"
+			require.NoError(t, parser.Process(context.Background(), e))
+
+			select {
+			case out := <-fakeOutput.Received:
+				require.Equal(t, tc.expected, out.Severity)
+				require.Equal(t, tc.severity, out.SeverityText)
+				require.Equal(t, tc.severity, out.Body.(map[string]interface{})["severity"])
+			case <-time.After(time.Second):
+				require.FailNow(t, "Timed out waiting for entry")
+			}
+		})
+	}
+}
+
+func TestCEFParserWithoutMapSeverity(t *testing.T) {
+	parser, fakeOutput := newTestParser(t, nil)
+
+	e := entry.New()
+	e.Body = "CEF:0|V|P|1|1|N|10|src=10.0.0.1"
+	require.NoError(t, parser.Process(context.Background(), e))
+
+	select {
+	case out := <-fakeOutput.Received:
+		require.Equal(t, entry.Default, out.Severity)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
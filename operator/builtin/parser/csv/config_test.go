@@ -42,6 +42,35 @@ func TestJSONParserConfig(t *testing.T) {
 				return p
 			}(),
 		},
+		{
+			Name: "header_attribute",
+			Expect: func() *CSVParserConfig {
+				p := defaultCfg()
+				p.HeaderAttribute = "header"
+				p.ParseFrom = entry.NewBodyField("message")
+				return p
+			}(),
+		},
+		{
+			Name: "quotes",
+			Expect: func() *CSVParserConfig {
+				p := defaultCfg()
+				p.Header = "id,severity,message"
+				p.LazyQuotes = true
+				p.Escape = "\\"
+				return p
+			}(),
+		},
+		{
+			Name: "field_count_policy",
+			Expect: func() *CSVParserConfig {
+				p := defaultCfg()
+				p.Header = "id,severity,message"
+				p.FieldCount = "overflow"
+				p.RestField = "extra"
+				return p
+			}(),
+		},
 		{
 			Name: "timestamp",
 			Expect: func() *CSVParserConfig {
@@ -18,6 +18,7 @@ import (
 	csvparser "encoding/csv"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator"
@@ -37,12 +38,36 @@ func NewCSVParserConfig(operatorID string) *CSVParserConfig {
 
 // CSVParserConfig is the configuration of a csv parser operator.
 type CSVParserConfig struct {
-	helper.ParserConfig `yaml:",inline"`
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
 
-	Header         string `json:"header" yaml:"header"`
-	FieldDelimiter string `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`
+	Header          string `mapstructure:"header"                            json:"header"                            yaml:"header"`
+	HeaderAttribute string `mapstructure:"header_attribute,omitempty"        json:"header_attribute,omitempty"        yaml:"header_attribute,omitempty"`
+	FieldDelimiter  string `mapstructure:"delimiter,omitempty"               json:"delimiter,omitempty"               yaml:"delimiter,omitempty"`
+	LazyQuotes      bool   `mapstructure:"lazy_quotes,omitempty"             json:"lazy_quotes,omitempty"             yaml:"lazy_quotes,omitempty"`
+	Escape          string `mapstructure:"escape,omitempty"                  json:"escape,omitempty"                  yaml:"escape,omitempty"`
+	FieldCount      string `mapstructure:"field_count_policy,omitempty"      json:"field_count_policy,omitempty"      yaml:"field_count_policy,omitempty"`
+	RestField       string `mapstructure:"rest_field,omitempty"              json:"rest_field,omitempty"              yaml:"rest_field,omitempty"`
 }
 
+// The policies for records which have a different number of fields than the header
+const (
+	// StrictFieldCount fails to parse records with a different number of fields
+	StrictFieldCount = "strict"
+	// TruncateFieldCount discards extra fields, and omits missing fields
+	TruncateFieldCount = "truncate"
+	// PadFieldCount discards extra fields, and sets missing fields to an empty string
+	PadFieldCount = "pad"
+	// OverflowFieldCount adds extra fields as a list under the rest field, and omits missing fields
+	OverflowFieldCount = "overflow"
+
+	// DefaultRestField is the key of extra fields when the overflow policy is used
+	DefaultRestField = "rest"
+)
+
+// maxHeaderCacheSize is the number of distinct headers read from attributes
+// which are kept parsed before the cache is cleared
+const maxHeaderCacheSize = 256
+
 // Build will build a csv parser operator.
 func (c CSVParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
 	parserOperator, err := c.ParserConfig.Build(context)
@@ -50,8 +75,12 @@ func (c CSVParserConfig) Build(context operator.BuildContext) ([]operator.Operat
 		return nil, err
 	}
 
-	if c.Header == "" {
-		return nil, fmt.Errorf("Missing required field 'header'")
+	if c.Header != "" && c.HeaderAttribute != "" {
+		return nil, fmt.Errorf("only one of 'header' or 'header_attribute' can be set")
+	}
+
+	if c.Header == "" && c.HeaderAttribute == "" {
+		return nil, fmt.Errorf("Missing required field 'header' or 'header_attribute'")
 	}
 
 	if c.FieldDelimiter == "" {
@@ -64,20 +93,58 @@ func (c CSVParserConfig) Build(context operator.BuildContext) ([]operator.Operat
 
 	fieldDelimiter := []rune(c.FieldDelimiter)[0]
 
-	if !strings.Contains(c.Header, c.FieldDelimiter) {
-		return nil, fmt.Errorf("missing field delimiter in header")
+	var escape rune
+	if c.Escape != "" {
+		if len([]rune(c.Escape)) != 1 {
+			return nil, fmt.Errorf("invalid 'escape': '%s'", c.Escape)
+		}
+		escape = []rune(c.Escape)[0]
+		if escape == fieldDelimiter || escape == '\r' || escape == '\n' {
+			return nil, fmt.Errorf("invalid 'escape': '%s'", c.Escape)
+		}
+		if escape == '"' {
+			// A quote escaped by another quote is the standard behavior
+			escape = 0
+		}
+	}
+
+	switch c.FieldCount {
+	case "":
+		c.FieldCount = StrictFieldCount
+	case StrictFieldCount, TruncateFieldCount, PadFieldCount, OverflowFieldCount:
+	default:
+		return nil, fmt.Errorf("invalid 'field_count_policy': '%s'", c.FieldCount)
 	}
 
-	numFields := len(strings.Split(c.Header, c.FieldDelimiter))
+	if c.RestField != "" && c.FieldCount != OverflowFieldCount {
+		return nil, fmt.Errorf("'rest_field' can only be set when 'field_count_policy' is '%s'", OverflowFieldCount)
+	}
+	if c.RestField == "" {
+		c.RestField = DefaultRestField
+	}
 
-	delimiterStr := string([]rune{fieldDelimiter})
 	csvParser := &CSVParser{
 		ParserOperator: parserOperator,
-		header:         strings.Split(c.Header, delimiterStr),
 		fieldDelimiter: fieldDelimiter,
-		numFields:      numFields,
+		lazyQuotes:     c.LazyQuotes,
+		escape:         escape,
+		fieldCount:     c.FieldCount,
+		restField:      c.RestField,
 	}
 
+	if c.HeaderAttribute != "" {
+		csvParser.headerAttribute = c.HeaderAttribute
+		csvParser.headerCache = make(map[string][]string)
+		return []operator.Operator{csvParser}, nil
+	}
+
+	if !strings.Contains(c.Header, c.FieldDelimiter) {
+		return nil, fmt.Errorf("missing field delimiter in header")
+	}
+
+	csvParser.header = strings.Split(c.Header, c.FieldDelimiter)
+	csvParser.numFields = len(csvParser.header)
+
 	return []operator.Operator{csvParser}, nil
 }
 
@@ -87,15 +154,67 @@ type CSVParser struct {
 	header         []string
 	fieldDelimiter rune
 	numFields      int
+	lazyQuotes     bool
+	escape         rune
+	fieldCount     string
+	restField      string
+
+	headerAttribute string
+	headerCache     map[string][]string
+	headerCacheMux  sync.Mutex
 }
 
 // Process will parse an entry for csv.
 func (r *CSVParser) Process(ctx context.Context, entry *entry.Entry) error {
-	return r.ParserOperator.ProcessWith(ctx, entry, r.parse)
+	if r.headerAttribute == "" {
+		return r.ParserOperator.ProcessWith(ctx, entry, r.parse)
+	}
+
+	headerLine, ok := entry.Attributes[r.headerAttribute]
+	if !ok {
+		err := fmt.Errorf("failed to read header from attribute '%s'", r.headerAttribute)
+		return r.HandleEntryError(ctx, entry, err)
+	}
+
+	header, err := r.readHeader(headerLine)
+	if err != nil {
+		return r.HandleEntryError(ctx, entry, err)
+	}
+
+	return r.ParserOperator.ProcessWith(ctx, entry, func(value interface{}) (interface{}, error) {
+		return r.parseWithHeader(value, header)
+	})
+}
+
+// readHeader will split a header line into field names, reusing the
+// result for header lines which have been seen before.
+func (r *CSVParser) readHeader(headerLine string) ([]string, error) {
+	r.headerCacheMux.Lock()
+	defer r.headerCacheMux.Unlock()
+
+	if header, ok := r.headerCache[headerLine]; ok {
+		return header, nil
+	}
+
+	header, err := r.readRecord(headerLine, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header from attribute '%s': %s", r.headerAttribute, err)
+	}
+
+	if len(r.headerCache) >= maxHeaderCacheSize {
+		r.headerCache = make(map[string][]string)
+	}
+	r.headerCache[headerLine] = header
+	return header, nil
 }
 
 // parse will parse a value using the supplied csv header.
 func (r *CSVParser) parse(value interface{}) (interface{}, error) {
+	return r.parseWithHeader(value, r.header)
+}
+
+// parseWithHeader will parse a value using the given csv header.
+func (r *CSVParser) parseWithHeader(value interface{}, header []string) (interface{}, error) {
 	var csvLine string
 	switch val := value.(type) {
 	case string:
@@ -104,20 +223,57 @@ func (r *CSVParser) parse(value interface{}) (interface{}, error) {
 		return nil, fmt.Errorf("type '%T' cannot be parsed as csv", value)
 	}
 
-	reader := csvparser.NewReader(strings.NewReader(csvLine))
-	reader.Comma = r.fieldDelimiter
-	reader.FieldsPerRecord = r.numFields
-	parsedValues := make(map[string]interface{})
-
-	record, err := reader.Read()
+	fieldsPerRecord := len(header)
+	if r.fieldCount != StrictFieldCount {
+		fieldsPerRecord = -1
+	}
 
+	record, err := r.readRecord(csvLine, fieldsPerRecord)
 	if err != nil {
 		return nil, err
 	}
 
-	for i, key := range r.header {
-		parsedValues[key] = record[i]
+	parsedValues := make(map[string]interface{}, len(header))
+	for i, key := range header {
+		switch {
+		case i < len(record):
+			parsedValues[key] = record[i]
+		case r.fieldCount == PadFieldCount:
+			parsedValues[key] = ""
+		}
+	}
+
+	if r.fieldCount == OverflowFieldCount && len(record) > len(header) {
+		rest := make([]interface{}, 0, len(record)-len(header))
+		for _, value := range record[len(header):] {
+			rest = append(rest, value)
+		}
+		parsedValues[r.restField] = rest
 	}
 
 	return parsedValues, nil
 }
+
+// readRecord will read the first record of a csv line. If fieldsPerRecord is
+// positive, the record must have exactly that many fields.
+func (r *CSVParser) readRecord(csvLine string, fieldsPerRecord int) ([]string, error) {
+	if r.escape != 0 {
+		record, err := splitEscaped(csvLine, r.fieldDelimiter, r.escape, r.lazyQuotes)
+		if err != nil {
+			return nil, err
+		}
+		if fieldsPerRecord > 0 && len(record) != fieldsPerRecord {
+			return nil, fmt.Errorf("record has %d fields, expected %d", len(record), fieldsPerRecord)
+		}
+		return record, nil
+	}
+
+	reader := csvparser.NewReader(strings.NewReader(csvLine))
+	reader.Comma = r.fieldDelimiter
+	reader.LazyQuotes = r.lazyQuotes
+	reader.FieldsPerRecord = fieldsPerRecord
+	if fieldsPerRecord == 0 {
+		reader.FieldsPerRecord = -1
+	}
+	return reader.Read()
+}
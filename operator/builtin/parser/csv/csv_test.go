@@ -190,6 +190,99 @@ func TestParserCSV(t *testing.T) {
 				"position": "agent",
 			},
 		},
+		{
+			"lazy quotes",
+			func(p *CSVParserConfig) {
+				p.Header = testHeader
+				p.LazyQuotes = true
+			},
+			"stanza,INFO,said \"hi\" twice",
+			map[string]interface{}{
+				"name": "stanza",
+				"sev":  "INFO",
+				"msg":  "said \"hi\" twice",
+			},
+		},
+		{
+			"escape",
+			func(p *CSVParserConfig) {
+				p.Header = testHeader
+				p.Escape = "\\"
+			},
+			"stanza,INFO,\"said \\\"hi\\\", twice\"",
+			map[string]interface{}{
+				"name": "stanza",
+				"sev":  "INFO",
+				"msg":  "said \"hi\", twice",
+			},
+		},
+		{
+			"truncate extra fields",
+			func(p *CSVParserConfig) {
+				p.Header = testHeader
+				p.FieldCount = TruncateFieldCount
+			},
+			"stanza,INFO,started agent,extra",
+			map[string]interface{}{
+				"name": "stanza",
+				"sev":  "INFO",
+				"msg":  "started agent",
+			},
+		},
+		{
+			"truncate missing fields",
+			func(p *CSVParserConfig) {
+				p.Header = testHeader
+				p.FieldCount = TruncateFieldCount
+			},
+			"stanza,INFO",
+			map[string]interface{}{
+				"name": "stanza",
+				"sev":  "INFO",
+			},
+		},
+		{
+			"pad missing fields",
+			func(p *CSVParserConfig) {
+				p.Header = testHeader
+				p.FieldCount = PadFieldCount
+			},
+			"stanza",
+			map[string]interface{}{
+				"name": "stanza",
+				"sev":  "",
+				"msg":  "",
+			},
+		},
+		{
+			"overflow extra fields",
+			func(p *CSVParserConfig) {
+				p.Header = testHeader
+				p.FieldCount = OverflowFieldCount
+			},
+			"stanza,INFO,started agent,extra,more",
+			map[string]interface{}{
+				"name": "stanza",
+				"sev":  "INFO",
+				"msg":  "started agent",
+				"rest": []interface{}{"extra", "more"},
+			},
+		},
+		{
+			"overflow custom rest field",
+			func(p *CSVParserConfig) {
+				p.Header = testHeader
+				p.FieldCount = OverflowFieldCount
+				p.RestField = "extra"
+			},
+			"stanza,INFO,started agent,more",
+			map[string]interface{}{
+				"name":  "stanza",
+				"sev":   "INFO",
+				"msg":   "started agent",
+				"extra": []interface{}{"more"},
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -287,6 +380,46 @@ func TestBuildParserCSV(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("HeaderAttribute", func(t *testing.T) {
+		c := newBasicCSVParser()
+		c.Header = ""
+		c.HeaderAttribute = "header"
+		_, err := c.Build(testutil.NewBuildContext(t))
+		require.NoError(t, err)
+	})
+
+	t.Run("HeaderAndHeaderAttribute", func(t *testing.T) {
+		c := newBasicCSVParser()
+		c.HeaderAttribute = "header"
+		_, err := c.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "only one of 'header' or 'header_attribute' can be set")
+	})
+
+	t.Run("InvalidEscape", func(t *testing.T) {
+		c := newBasicCSVParser()
+		c.Escape = ","
+		_, err := c.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid 'escape'")
+	})
+
+	t.Run("InvalidFieldCountPolicy", func(t *testing.T) {
+		c := newBasicCSVParser()
+		c.FieldCount = "ignore"
+		_, err := c.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid 'field_count_policy': 'ignore'")
+	})
+
+	t.Run("RestFieldWithoutOverflow", func(t *testing.T) {
+		c := newBasicCSVParser()
+		c.RestField = "extra"
+		_, err := c.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "'rest_field' can only be set")
+	})
+
 	t.Run("InvalidHeaderFieldMissingDelimiter", func(t *testing.T) {
 		c := newBasicCSVParser()
 		c.Header = "name"
@@ -311,3 +444,75 @@ func TestBuildParserCSV(t *testing.T) {
 		require.Contains(t, err.Error(), "missing field delimiter in header")
 	})
 }
+
+func TestParserCSVStrictFieldCount(t *testing.T) {
+	parser := newTestParser(t)
+	_, err := parser.parse("stanza,INFO,started agent,extra")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "wrong number of fields")
+
+	_, err = parser.parse("stanza,INFO")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "wrong number of fields")
+}
+
+func TestParserCSVHeaderAttribute(t *testing.T) {
+	cfg := NewCSVParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.HeaderAttribute = "header"
+	cfg.OnError = helper.DropOnError
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*CSVParser)
+
+	fake := testutil.NewFakeOutput(t)
+	op.SetOutputs([]operator.Operator{fake})
+
+	cases := []struct {
+		header   string
+		body     string
+		expected map[string]interface{}
+	}{
+		{
+			"name,sev,msg",
+			"stanza,INFO,started agent",
+			map[string]interface{}{"name": "stanza", "sev": "INFO", "msg": "started agent"},
+		},
+		{
+			"id,msg",
+			"1,\"hello, world\"",
+			map[string]interface{}{"id": "1", "msg": "hello, world"},
+		},
+		{
+			"name,sev,msg",
+			"stanza,DEBUG,stopped agent",
+			map[string]interface{}{"name": "stanza", "sev": "DEBUG", "msg": "stopped agent"},
+		},
+	}
+
+	for _, tc := range cases {
+		e := entry.New()
+		e.Body = tc.body
+		e.AddAttribute("header", tc.header)
+		require.NoError(t, op.Process(context.Background(), e))
+		fake.ExpectBody(t, tc.expected)
+	}
+	require.Len(t, op.headerCache, 2)
+
+	t.Run("MissingAttribute", func(t *testing.T) {
+		e := entry.New()
+		e.Body = "stanza,INFO,started agent"
+		err := op.Process(context.Background(), e)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read header from attribute 'header'")
+	})
+
+	t.Run("MismatchedFields", func(t *testing.T) {
+		e := entry.New()
+		e.Body = "stanza,INFO"
+		e.AddAttribute("header", "name,sev,msg")
+		err := op.Process(context.Background(), e)
+		require.Error(t, err)
+	})
+}
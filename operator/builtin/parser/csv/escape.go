@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// splitEscaped will split the first record of a csv line into its fields, where
+// any character following the escape character is taken literally. Fields may
+// be quoted, in which case a quote is escaped by the escape character or by a
+// second quote.
+func splitEscaped(line string, delimiter, escape rune, lazyQuotes bool) ([]string, error) {
+	runes := []rune(line)
+	var record []string
+	var field strings.Builder
+
+	quoted := false
+	fieldStart := true
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == escape && i+1 < len(runes):
+			i++
+			field.WriteRune(runes[i])
+		case quoted && c == '"':
+			switch {
+			case i+1 < len(runes) && runes[i+1] == '"':
+				i++
+				field.WriteRune('"')
+			case i+1 == len(runes) || runes[i+1] == delimiter || runes[i+1] == '\n' || runes[i+1] == '\r':
+				quoted = false
+			case lazyQuotes:
+				field.WriteRune('"')
+			default:
+				return nil, fmt.Errorf("extraneous or missing \" in quoted-field at position %d", i+1)
+			}
+		case quoted:
+			field.WriteRune(c)
+		case c == '"' && fieldStart:
+			quoted = true
+		case c == '"' && !lazyQuotes:
+			return nil, fmt.Errorf("bare \" in non-quoted-field at position %d", i+1)
+		case c == delimiter:
+			record = append(record, field.String())
+			field.Reset()
+			fieldStart = true
+			continue
+		case c == '\n':
+			return append(record, strings.TrimSuffix(field.String(), "\r")), nil
+		default:
+			field.WriteRune(c)
+		}
+		fieldStart = false
+	}
+
+	if quoted && !lazyQuotes {
+		return nil, fmt.Errorf("extraneous or missing \" in quoted-field")
+	}
+	return append(record, strings.TrimSuffix(field.String(), "\r")), nil
+}
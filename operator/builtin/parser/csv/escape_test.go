@@ -0,0 +1,135 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitEscaped(t *testing.T) {
+	cases := []struct {
+		name       string
+		line       string
+		lazyQuotes bool
+		expected   []string
+		expectErr  bool
+	}{
+		{
+			"Basic",
+			`a,b,c`,
+			false,
+			[]string{"a", "b", "c"},
+			false,
+		},
+		{
+			"EscapedDelimiter",
+			`a\,b,c`,
+			false,
+			[]string{"a,b", "c"},
+			false,
+		},
+		{
+			"EscapedQuoteInQuotes",
+			`"say \"hi\"",b`,
+			false,
+			[]string{`say "hi"`, "b"},
+			false,
+		},
+		{
+			"DoubledQuoteInQuotes",
+			`"say ""hi""",b`,
+			false,
+			[]string{`say "hi"`, "b"},
+			false,
+		},
+		{
+			"EscapedEscape",
+			`C:\\logs,b`,
+			false,
+			[]string{`C:\logs`, "b"},
+			false,
+		},
+		{
+			"TrailingEscape",
+			`a,b\`,
+			false,
+			[]string{"a", `b\`},
+			false,
+		},
+		{
+			"EmptyFields",
+			`,,`,
+			false,
+			[]string{"", "", ""},
+			false,
+		},
+		{
+			"FirstRecordOnly",
+			"a,b\r\nc,d",
+			false,
+			[]string{"a", "b"},
+			false,
+		},
+		{
+			"BareQuote",
+			`a"b,c`,
+			false,
+			nil,
+			true,
+		},
+		{
+			"BareQuoteLazy",
+			`a"b,c`,
+			true,
+			[]string{`a"b`, "c"},
+			false,
+		},
+		{
+			"ExtraneousQuote",
+			`"a"b,c`,
+			false,
+			nil,
+			true,
+		},
+		{
+			"ExtraneousQuoteLazy",
+			`"a"b,c`,
+			true,
+			[]string{`a"b,c`},
+			false,
+		},
+		{
+			"UnterminatedQuote",
+			`"a,b`,
+			false,
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			record, err := splitEscaped(tc.line, ',', '\\', tc.lazyQuotes)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, record)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dissect
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("dissect_parser", func() operator.Builder { return NewDissectParserConfig("") })
+}
+
+// NewDissectParserConfig creates a new dissect parser config with default values
+func NewDissectParserConfig(operatorID string) *DissectParserConfig {
+	return &DissectParserConfig{
+		ParserConfig:    helper.NewParserConfig(operatorID, "dissect_parser"),
+		AppendSeparator: " ",
+	}
+}
+
+// DissectParserConfig is the configuration of a dissect parser operator.
+type DissectParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	Pattern         string `mapstructure:"pattern"          json:"pattern"          yaml:"pattern"`
+	AppendSeparator string `mapstructure:"append_separator" json:"append_separator" yaml:"append_separator"`
+}
+
+// Build will build a dissect parser operator.
+func (c DissectParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Pattern == "" {
+		return nil, fmt.Errorf("missing required field 'pattern'")
+	}
+
+	p, err := compilePattern(c.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %s", err)
+	}
+
+	dissectParser := &DissectParser{
+		ParserOperator:  parserOperator,
+		pattern:         p,
+		appendSeparator: c.AppendSeparator,
+	}
+
+	return []operator.Operator{dissectParser}, nil
+}
+
+// DissectParser is an operator that parses values by splitting them on the literal delimiters of a pattern.
+type DissectParser struct {
+	helper.ParserOperator
+	pattern         *pattern
+	appendSeparator string
+}
+
+// Process will parse an entry with the dissect pattern.
+func (d *DissectParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return d.ParserOperator.ProcessWith(ctx, entry, d.parse)
+}
+
+// parse will parse a value with the dissect pattern.
+func (d *DissectParser) parse(value interface{}) (interface{}, error) {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return nil, fmt.Errorf("type '%T' cannot be parsed with a dissect pattern", value)
+	}
+
+	return d.pattern.dissect(raw, d.appendSeparator)
+}
@@ -0,0 +1,251 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dissect
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t testing.TB, modify func(*DissectParserConfig)) (*DissectParser, *testutil.FakeOutput) {
+	cfg := NewDissectParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	if modify != nil {
+		modify(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*DissectParser)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fakeOutput}))
+	return parser, fakeOutput
+}
+
+func TestDissectParserBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		pattern     string
+		expectedErr string
+	}{
+		{"Valid", "%{ts} %{level} [%{thread}] %{msg}", ""},
+		{"Prefix", "[%{level}] %{msg}", ""},
+		{"Missing", "", "missing required field 'pattern'"},
+		{"NoFields", "plain text", "pattern must contain at least one field"},
+		{"Unclosed", "%{ts} %{level", "field '%{level' is not closed"},
+		{"NoDelimiter", "%{ts}%{level}", "field '%{ts}' must be followed by a delimiter"},
+		{"DuplicateKey", "%{a} %{a}", "key 'a' is used more than once"},
+		{"EmptyAppend", "%{a} %{+}", "field '%{+}' is missing a key"},
+		{"Reference", "%{*a} %{&a}", "field '%{*a}' uses an unsupported modifier"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewDissectParserConfig("test")
+			cfg.Pattern = tc.pattern
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestDissectParserParse(t *testing.T) {
+	cases := []struct {
+		name      string
+		pattern   string
+		separator string
+		input     interface{}
+		expected  map[string]interface{}
+		expectErr bool
+	}{
+		{
+			"Basic",
+			"%{ts} %{level} [%{thread}] %{msg}",
+			" ",
+			"2021-06-01T12:00:00Z INFO [main] Starting service on port 8080",
+			map[string]interface{}{
+				"ts":     "2021-06-01T12:00:00Z",
+				"level":  "INFO",
+				"thread": "main",
+				"msg":    "Starting service on port 8080",
+			},
+			false,
+		},
+		{
+			"Bytes",
+			"%{level}: %{msg}",
+			" ",
+			[]byte("WARN: disk usage high"),
+			map[string]interface{}{"level": "WARN", "msg": "disk usage high"},
+			false,
+		},
+		{
+			"PrefixAndSuffix",
+			"<%{level}> %{msg}.",
+			" ",
+			"<ERROR> connection refused.",
+			map[string]interface{}{"level": "ERROR", "msg": "connection refused"},
+			false,
+		},
+		{
+			"Skipped",
+			"%{} %{?pid} %{msg}",
+			" ",
+			"host 1234 started",
+			map[string]interface{}{"msg": "started"},
+			false,
+		},
+		{
+			"Appended",
+			"%{date} %{+date} %{level} %{msg}",
+			"T",
+			"2021-06-01 12:00:00 INFO started",
+			map[string]interface{}{"date": "2021-06-01T12:00:00", "level": "INFO", "msg": "started"},
+			false,
+		},
+		{
+			"Padded",
+			"%{level->} %{msg}",
+			" ",
+			"INFO     started",
+			map[string]interface{}{"level": "INFO", "msg": "started"},
+			false,
+		},
+		{
+			"PaddedTimestamp",
+			"%{ts} %{level->} %{msg}",
+			" ",
+			"2021-06-01T12:00:00Z INFO  Starting service on port 8080",
+			map[string]interface{}{"ts": "2021-06-01T12:00:00Z", "level": "INFO", "msg": "Starting service on port 8080"},
+			false,
+		},
+		{
+			"EmptyValue",
+			"%{a},%{b},%{c}",
+			" ",
+			"1,,3",
+			map[string]interface{}{"a": "1", "b": "", "c": "3"},
+			false,
+		},
+		{
+			"MissingDelimiter",
+			"%{ts} %{level} [%{thread}] %{msg}",
+			" ",
+			"2021-06-01T12:00:00Z INFO main",
+			nil,
+			true,
+		},
+		{
+			"MissingPrefix",
+			"<%{level}> %{msg}",
+			" ",
+			"ERROR connection refused",
+			nil,
+			true,
+		},
+		{
+			"MissingSuffix",
+			"%{level}: %{msg}.",
+			" ",
+			"ERROR: connection refused",
+			nil,
+			true,
+		},
+		{
+			"InvalidType",
+			"%{level}: %{msg}",
+			" ",
+			123,
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, func(cfg *DissectParserConfig) {
+				cfg.Pattern = tc.pattern
+				cfg.AppendSeparator = tc.separator
+			})
+
+			parsed, err := parser.parse(tc.input)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+}
+
+func TestDissectParserProcess(t *testing.T) {
+	parser, fake := newTestParser(t, func(cfg *DissectParserConfig) {
+		cfg.Pattern = "%{level}: %{msg}"
+	})
+
+	e := entry.New()
+	e.Body = "INFO: started"
+	require.NoError(t, parser.Process(context.Background(), e))
+	fake.ExpectBody(t, map[string]interface{}{"level": "INFO", "msg": "started"})
+}
+
+const benchmarkValue = "2021-06-01T12:00:00Z INFO [main] Starting service on port 8080"
+
+func BenchmarkDissectParser(b *testing.B) {
+	parser, _ := newTestParser(b, func(cfg *DissectParserConfig) {
+		cfg.Pattern = "%{ts} %{level} [%{thread}] %{msg}"
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.parse(benchmarkValue); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEquivalentRegex parses the same value as BenchmarkDissectParser with an equivalent regex, for comparison
+func BenchmarkEquivalentRegex(b *testing.B) {
+	r := regexp.MustCompile(`^(?P<ts>[^ ]*) (?P<level>[^ ]*) \[(?P<thread>[^\]]*)\] (?P<msg>.*)$`)
+	names := r.SubexpNames()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matches := r.FindStringSubmatch(benchmarkValue)
+		if matches == nil {
+			b.Fatal("no match")
+		}
+		parsed := make(map[string]interface{}, len(names))
+		for j, name := range names[1:] {
+			parsed[name] = matches[j+1]
+		}
+	}
+}
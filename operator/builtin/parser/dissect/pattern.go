@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dissect
+
+import (
+	"fmt"
+	"strings"
+)
+
+// errNoMatch is returned when a value does not match a pattern.
+var errNoMatch = fmt.Errorf("dissect pattern does not match")
+
+// pattern is a compiled dissect pattern, such as '%{ts} %{level} [%{thread}] %{msg}'.
+type pattern struct {
+	// prefix is the literal text before the first field
+	prefix string
+	fields []field
+}
+
+// field is a field of a dissect pattern, and the literal delimiter which follows it.
+type field struct {
+	key       string
+	delimiter string
+
+	// skip is true if the value of the field is discarded, as with '%{}' or '%{?name}'
+	skip bool
+
+	// append is true if the value of the field is appended to the value of its key, as with '%{+name}'
+	append bool
+
+	// padded is true if repeated delimiters after the field are skipped, as with '%{name->}'
+	padded bool
+}
+
+// compilePattern will compile a dissect pattern.
+func compilePattern(source string) (*pattern, error) {
+	start := strings.Index(source, "%{")
+	if start < 0 {
+		return nil, fmt.Errorf("pattern must contain at least one field")
+	}
+
+	p := &pattern{prefix: source[:start]}
+	keys := map[string]bool{}
+	rest := source[start:]
+	for rest != "" {
+		end := strings.IndexByte(rest, '}')
+		if end < 0 {
+			return nil, fmt.Errorf("field '%s' is not closed", rest)
+		}
+
+		f, err := parseField(rest[2:end])
+		if err != nil {
+			return nil, err
+		}
+		rest = rest[end+1:]
+
+		next := strings.Index(rest, "%{")
+		if next < 0 {
+			next = len(rest)
+		}
+		f.delimiter = rest[:next]
+		rest = rest[next:]
+
+		if f.delimiter == "" && rest != "" {
+			return nil, fmt.Errorf("field '%%{%s}' must be followed by a delimiter", f.key)
+		}
+
+		if !f.skip {
+			if keys[f.key] && !f.append {
+				return nil, fmt.Errorf("key '%s' is used more than once, use '%%{+%s}' to append to it", f.key, f.key)
+			}
+			keys[f.key] = true
+		}
+		p.fields = append(p.fields, f)
+	}
+
+	return p, nil
+}
+
+// parseField will parse the contents of a field, such as '?name', '+name' or 'name->'.
+func parseField(contents string) (field, error) {
+	f := field{}
+	if strings.HasSuffix(contents, "->") {
+		f.padded = true
+		contents = strings.TrimSuffix(contents, "->")
+	}
+
+	switch {
+	case contents == "":
+		f.skip = true
+	case strings.HasPrefix(contents, "?"):
+		f.skip = true
+		f.key = contents[1:]
+	case strings.HasPrefix(contents, "+"):
+		f.append = true
+		f.key = contents[1:]
+		if f.key == "" {
+			return field{}, fmt.Errorf("field '%%{+}' is missing a key")
+		}
+	case strings.HasPrefix(contents, "&"), strings.HasPrefix(contents, "*"):
+		return field{}, fmt.Errorf("field '%%{%s}' uses an unsupported modifier", contents)
+	default:
+		f.key = contents
+	}
+	return f, nil
+}
+
+// dissect will split a value on the delimiters of the pattern. The values of appended fields are
+// joined to the value of their key with the separator.
+func (p *pattern) dissect(value, separator string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(value, p.prefix) {
+		return nil, errNoMatch
+	}
+
+	parsed := make(map[string]interface{}, len(p.fields))
+	rest := value[len(p.prefix):]
+	for i, f := range p.fields {
+		var v string
+		if i == len(p.fields)-1 {
+			// The last field is the remainder of the value, up to any trailing delimiter
+			if !strings.HasSuffix(rest, f.delimiter) {
+				return nil, errNoMatch
+			}
+			v = rest[:len(rest)-len(f.delimiter)]
+			for f.padded && f.delimiter != "" && strings.HasSuffix(v, f.delimiter) {
+				v = v[:len(v)-len(f.delimiter)]
+			}
+		} else {
+			end := strings.Index(rest, f.delimiter)
+			if end < 0 {
+				return nil, errNoMatch
+			}
+			v = rest[:end]
+			rest = rest[end+len(f.delimiter):]
+			for f.padded && strings.HasPrefix(rest, f.delimiter) {
+				rest = rest[len(f.delimiter):]
+			}
+		}
+
+		if f.skip {
+			continue
+		}
+		if existing, ok := parsed[f.key]; ok && f.append {
+			parsed[f.key] = existing.(string) + separator + v
+			continue
+		}
+		parsed[f.key] = v
+	}
+	return parsed, nil
+}
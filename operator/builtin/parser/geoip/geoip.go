@@ -0,0 +1,324 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/errors"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// DefaultLocale is the locale of the names written when none is configured
+const DefaultLocale = "en"
+
+// reloadDebounce is the time to wait after a database changes before reloading it,
+// so that a database which is written in several steps is reloaded once
+const reloadDebounce = 100 * time.Millisecond
+
+func init() {
+	operator.Register("geoip_parser", func() operator.Builder { return NewGeoIPParserConfig("") })
+}
+
+// NewGeoIPParserConfig creates a new GeoIP parser config with default values
+func NewGeoIPParserConfig(operatorID string) *GeoIPParserConfig {
+	return &GeoIPParserConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "geoip_parser"),
+		Locale:            DefaultLocale,
+	}
+}
+
+// GeoIPParserConfig is the configuration of a GeoIP parser operator.
+type GeoIPParserConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+
+	ParseFrom entry.Field `mapstructure:"parse_from"          json:"parse_from"          yaml:"parse_from"`
+	Databases []string    `mapstructure:"databases"           json:"databases"           yaml:"databases"`
+	Locale    string      `mapstructure:"locale,omitempty"    json:"locale,omitempty"    yaml:"locale,omitempty"`
+}
+
+// Build will build a GeoIP parser operator.
+func (c GeoIPParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ParseFrom.FieldInterface == nil {
+		return nil, fmt.Errorf("missing required parameter 'parse_from'")
+	}
+
+	if len(c.Databases) == 0 {
+		return nil, fmt.Errorf("missing required parameter 'databases'")
+	}
+
+	if c.Locale == "" {
+		c.Locale = DefaultLocale
+	}
+
+	paths := make([]string, 0, len(c.Databases))
+	databases := make([]*database, 0, len(c.Databases))
+	for _, path := range c.Databases {
+		path = filepath.Clean(path)
+		db, err := openDatabase(path)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+		databases = append(databases, db)
+	}
+
+	geoIPParser := &GeoIPParser{
+		TransformerOperator: transformerOperator,
+		parseFrom:           c.ParseFrom,
+		locale:              c.Locale,
+		paths:               paths,
+		databases:           databases,
+	}
+
+	return []operator.Operator{geoIPParser}, nil
+}
+
+// GeoIPParser is an operator that enriches entries with the location and
+// autonomous system of an IP address.
+type GeoIPParser struct {
+	helper.TransformerOperator
+	parseFrom entry.Field
+	locale    string
+	paths     []string
+
+	mu        sync.RWMutex
+	databases []*database
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Start will start watching the databases for changes.
+func (p *GeoIPParser) Start(_ operator.Persister) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create database watcher: %s", err)
+	}
+
+	watched := map[string]bool{}
+	for _, path := range p.paths {
+		dir := filepath.Dir(path)
+		if watched[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch database directory %s: %s", dir, err)
+		}
+		watched[dir] = true
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.wg.Add(1)
+	go p.watch(ctx, watcher)
+	return nil
+}
+
+// Stop will stop watching the databases for changes.
+func (p *GeoIPParser) Stop() error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+	return nil
+}
+
+// watch will reload a database when its file is written, created or replaced.
+func (p *GeoIPParser) watch(ctx context.Context, watcher *fsnotify.Watcher) {
+	defer p.wg.Done()
+	defer watcher.Close()
+
+	timer := time.NewTimer(reloadDebounce)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	pending := map[int]bool{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			for i, path := range p.paths {
+				if filepath.Clean(event.Name) == path {
+					pending[i] = true
+					timer.Reset(reloadDebounce)
+				}
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			p.Warnw("Database watcher error", zap.Error(err))
+		case <-timer.C:
+			for i := range pending {
+				p.reload(i)
+			}
+			pending = map[int]bool{}
+		}
+	}
+}
+
+// reload will replace a database with the current contents of its file.
+// The previous database continues to be used if the file can not be read.
+func (p *GeoIPParser) reload(index int) {
+	path := p.paths[index]
+	db, err := openDatabase(path)
+	if err != nil {
+		p.Warnw("Failed to reload database. Continuing to use the previous database", "path", path, zap.Error(err))
+		return
+	}
+
+	p.mu.Lock()
+	databases := make([]*database, len(p.databases))
+	copy(databases, p.databases)
+	databases[index] = db
+	p.databases = databases
+	p.mu.Unlock()
+
+	p.Infow("Reloaded database", "path", path, "database_type", db.databaseType)
+}
+
+// Process will enrich an entry with the location and autonomous system of an IP address.
+func (p *GeoIPParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ProcessWith(ctx, entry, p.parse)
+}
+
+// parse will look up the IP address of an entry in each database, and add the attributes of the records found.
+func (p *GeoIPParser) parse(e *entry.Entry) error {
+	value, ok := e.Get(p.parseFrom)
+	if !ok {
+		return errors.NewError(
+			"Entry is missing the expected parse_from field.",
+			"Ensure that all incoming entries contain the parse_from field.",
+			"parse_from", p.parseFrom.String(),
+		)
+	}
+
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("type %T cannot be parsed as an IP address", value)
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(raw))
+	if ip == nil {
+		return fmt.Errorf("invalid IP address '%s'", raw)
+	}
+
+	p.mu.RLock()
+	databases := p.databases
+	p.mu.RUnlock()
+
+	for i, db := range databases {
+		record, err := db.lookup(ip)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("lookup in %s", p.paths[i]))
+		}
+		if record != nil {
+			addAttributes(e, record, p.locale)
+		}
+	}
+	return nil
+}
+
+// addAttributes will add the attributes of a City, Country or ASN record to an entry.
+func addAttributes(e *entry.Entry, record map[string]interface{}, locale string) {
+	addAttribute(e, "geo.continent.code", lookupPath(record, "continent", "code"))
+	addAttribute(e, "geo.country.iso_code", lookupPath(record, "country", "iso_code"))
+	addAttribute(e, "geo.country.name", localizedName(lookupPath(record, "country"), locale))
+
+	if subdivisions, ok := record["subdivisions"].([]interface{}); ok && len(subdivisions) > 0 {
+		addAttribute(e, "geo.region.iso_code", lookupPath(subdivisions[0], "iso_code"))
+		addAttribute(e, "geo.region.name", localizedName(subdivisions[0], locale))
+	}
+
+	addAttribute(e, "geo.city.name", localizedName(lookupPath(record, "city"), locale))
+	addAttribute(e, "geo.postal.code", lookupPath(record, "postal", "code"))
+	addAttribute(e, "geo.location.lat", lookupPath(record, "location", "latitude"))
+	addAttribute(e, "geo.location.lon", lookupPath(record, "location", "longitude"))
+	addAttribute(e, "geo.timezone", lookupPath(record, "location", "time_zone"))
+
+	addAttribute(e, "as.number", record["autonomous_system_number"])
+	addAttribute(e, "as.organization.name", record["autonomous_system_organization"])
+}
+
+// addAttribute will add an attribute to an entry, if the value was found in the record.
+func addAttribute(e *entry.Entry, key string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		e.AddAttribute(key, v)
+	case float64:
+		e.AddAttribute(key, strconv.FormatFloat(v, 'f', -1, 64))
+	case uint64:
+		e.AddAttribute(key, strconv.FormatUint(v, 10))
+	case int64:
+		e.AddAttribute(key, strconv.FormatInt(v, 10))
+	}
+}
+
+// lookupPath will find a value nested within maps of a record.
+func lookupPath(value interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+// localizedName will find the name of a place in the locale, falling back to English.
+func localizedName(place interface{}, locale string) interface{} {
+	names, ok := lookupPath(place, "names").(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if name, ok := names[locale]; ok {
+		return name
+	}
+	return names[DefaultLocale]
+}
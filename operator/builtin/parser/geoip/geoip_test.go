@@ -0,0 +1,345 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoip
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+var testCityNetworks = []testNetwork{
+	{
+		"81.2.69.0/24",
+		map[string]interface{}{
+			"continent": map[string]interface{}{
+				"code":  "EU",
+				"names": map[string]interface{}{"en": "Europe"},
+			},
+			"country": map[string]interface{}{
+				"iso_code": "GB",
+				"names":    map[string]interface{}{"en": "United Kingdom", "de": "Vereinigtes Königreich"},
+			},
+			"subdivisions": []interface{}{
+				map[string]interface{}{
+					"iso_code": "ENG",
+					"names":    map[string]interface{}{"en": "England", "de": "England"},
+				},
+			},
+			"city": map[string]interface{}{
+				"names": map[string]interface{}{"en": "London"},
+			},
+			"postal": map[string]interface{}{
+				"code": "EC2V",
+			},
+			"location": map[string]interface{}{
+				"latitude":  51.5142,
+				"longitude": -0.0931,
+				"time_zone": "Europe/London",
+			},
+		},
+	},
+}
+
+var testASNNetworks = []testNetwork{
+	{
+		"81.2.64.0/19",
+		map[string]interface{}{
+			"autonomous_system_number":       uint32(20712),
+			"autonomous_system_organization": "Andrews & Arnold Ltd",
+		},
+	},
+}
+
+var expectedCityAttributes = map[string]string{
+	"geo.continent.code":   "EU",
+	"geo.country.iso_code": "GB",
+	"geo.country.name":     "United Kingdom",
+	"geo.region.iso_code":  "ENG",
+	"geo.region.name":      "England",
+	"geo.city.name":        "London",
+	"geo.postal.code":      "EC2V",
+	"geo.location.lat":     "51.5142",
+	"geo.location.lon":     "-0.0931",
+	"geo.timezone":         "Europe/London",
+}
+
+// writeTestDatabase will write a test database to a temporary directory, returning its path.
+func writeTestDatabase(t *testing.T, dir, name, databaseType string, networks []testNetwork) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, buildTestDatabase(t, 6, 28, databaseType, networks), 0600))
+	return path
+}
+
+func newTestParser(t *testing.T, modify func(*GeoIPParserConfig)) (*GeoIPParser, *testutil.FakeOutput) {
+	dir := t.TempDir()
+	cfg := NewGeoIPParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.ParseFrom = entry.NewBodyField("ip")
+	cfg.Databases = []string{
+		writeTestDatabase(t, dir, "GeoLite2-City.mmdb", "GeoLite2-City", testCityNetworks),
+		writeTestDatabase(t, dir, "GeoLite2-ASN.mmdb", "GeoLite2-ASN", testASNNetworks),
+	}
+	if modify != nil {
+		modify(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*GeoIPParser)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fakeOutput}))
+	return parser, fakeOutput
+}
+
+func TestGeoIPParserBuildFailure(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.mmdb")
+	require.NoError(t, ioutil.WriteFile(invalid, []byte("not a database"), 0600))
+
+	cases := []struct {
+		name      string
+		modify    func(*GeoIPParserConfig)
+		expectErr string
+	}{
+		{
+			"MissingParseFrom",
+			func(cfg *GeoIPParserConfig) {
+				cfg.Databases = []string{invalid}
+			},
+			"missing required parameter 'parse_from'",
+		},
+		{
+			"MissingDatabases",
+			func(cfg *GeoIPParserConfig) {
+				cfg.ParseFrom = entry.NewBodyField("ip")
+			},
+			"missing required parameter 'databases'",
+		},
+		{
+			"MissingDatabase",
+			func(cfg *GeoIPParserConfig) {
+				cfg.ParseFrom = entry.NewBodyField("ip")
+				cfg.Databases = []string{filepath.Join(dir, "missing.mmdb")}
+			},
+			"read database",
+		},
+		{
+			"InvalidDatabase",
+			func(cfg *GeoIPParserConfig) {
+				cfg.ParseFrom = entry.NewBodyField("ip")
+				cfg.Databases = []string{invalid}
+			},
+			"missing metadata",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewGeoIPParserConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectErr)
+		})
+	}
+}
+
+func TestGeoIPParserParse(t *testing.T) {
+	both := map[string]string{
+		"as.number":            "20712",
+		"as.organization.name": "Andrews & Arnold Ltd",
+	}
+	for k, v := range expectedCityAttributes {
+		both[k] = v
+	}
+
+	cases := []struct {
+		name     string
+		modify   func(*GeoIPParserConfig)
+		input    interface{}
+		expected map[string]string
+	}{
+		{
+			"CityAndASN",
+			nil,
+			"81.2.69.142",
+			both,
+		},
+		{
+			"ASNOnly",
+			nil,
+			"81.2.70.1",
+			map[string]string{
+				"as.number":            "20712",
+				"as.organization.name": "Andrews & Arnold Ltd",
+			},
+		},
+		{
+			"Bytes",
+			nil,
+			[]byte("81.2.70.1"),
+			map[string]string{
+				"as.number":            "20712",
+				"as.organization.name": "Andrews & Arnold Ltd",
+			},
+		},
+		{
+			"NotFound",
+			nil,
+			"10.0.0.1",
+			nil,
+		},
+		{
+			"IPv6NotFound",
+			nil,
+			"2001:db8::1",
+			nil,
+		},
+		{
+			"Locale",
+			func(cfg *GeoIPParserConfig) {
+				cfg.Locale = "de"
+				cfg.Databases = cfg.Databases[:1]
+			},
+			"81.2.69.142",
+			func() map[string]string {
+				expected := map[string]string{}
+				for k, v := range expectedCityAttributes {
+					expected[k] = v
+				}
+				expected["geo.country.name"] = "Vereinigtes Königreich"
+				return expected
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, tc.modify)
+
+			e := entry.New()
+			e.Body = map[string]interface{}{"ip": tc.input}
+			require.NoError(t, parser.parse(e))
+			require.Equal(t, tc.expected, e.Attributes)
+			require.Equal(t, map[string]interface{}{"ip": tc.input}, e.Body)
+		})
+	}
+}
+
+func TestGeoIPParserParseErrors(t *testing.T) {
+	cases := []struct {
+		name      string
+		body      interface{}
+		expectErr string
+	}{
+		{
+			"MissingField",
+			map[string]interface{}{"address": "81.2.69.142"},
+			"Entry is missing the expected parse_from field.",
+		},
+		{
+			"InvalidType",
+			map[string]interface{}{"ip": 1},
+			"type int cannot be parsed as an IP address",
+		},
+		{
+			"InvalidAddress",
+			map[string]interface{}{"ip": "81.2.69"},
+			"invalid IP address '81.2.69'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, nil)
+
+			e := entry.New()
+			e.Body = tc.body
+			err := parser.parse(e)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectErr)
+		})
+	}
+}
+
+func TestGeoIPParserProcess(t *testing.T) {
+	parser, fakeOutput := newTestParser(t, func(cfg *GeoIPParserConfig) {
+		cfg.ParseFrom = entry.NewAttributeField("net.peer.ip")
+	})
+
+	e := entry.New()
+	e.Body = "GET /index.html"
+	e.AddAttribute("net.peer.ip", "81.2.69.142")
+	require.NoError(t, parser.Process(context.Background(), e))
+
+	select {
+	case processed := <-fakeOutput.Received:
+		require.Equal(t, "GET /index.html", processed.Body)
+		require.Equal(t, "81.2.69.142", processed.Attributes["net.peer.ip"])
+		require.Equal(t, "London", processed.Attributes["geo.city.name"])
+		require.Equal(t, "20712", processed.Attributes["as.number"])
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry to be processed")
+	}
+}
+
+func TestGeoIPParserReload(t *testing.T) {
+	parser, _ := newTestParser(t, nil)
+	require.NoError(t, parser.Start(testutil.NewMockPersister("test")))
+	defer parser.Stop()
+
+	cityName := func() string {
+		e := entry.New()
+		e.Body = map[string]interface{}{"ip": "81.2.69.142"}
+		require.NoError(t, parser.parse(e))
+		return e.Attributes["geo.city.name"]
+	}
+	require.Equal(t, "London", cityName())
+
+	// An invalid database is ignored, and the previous database is used
+	cityPath := parser.paths[0]
+	require.NoError(t, ioutil.WriteFile(cityPath, []byte("not a database"), 0600))
+	time.Sleep(2 * reloadDebounce)
+	require.Equal(t, "London", cityName())
+
+	// A database which is replaced is reloaded
+	updated := []testNetwork{
+		{
+			"81.2.69.0/24",
+			map[string]interface{}{
+				"city": map[string]interface{}{
+					"names": map[string]interface{}{"en": "Manchester"},
+				},
+			},
+		},
+	}
+	tempPath := writeTestDatabase(t, t.TempDir(), "GeoLite2-City.mmdb.tmp", "GeoLite2-City", updated)
+	require.NoError(t, os.Rename(tempPath, cityPath))
+
+	require.Eventually(t, func() bool {
+		return cityName() == "Manchester"
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,380 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"net"
+)
+
+// metadataMarker precedes the metadata section at the end of a MaxMind DB file.
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparatorSize is the number of zero bytes between the search tree and the data section.
+const dataSectionSeparatorSize = 16
+
+// database is a MaxMind DB, such as a GeoLite2 or GeoIP2 database.
+type database struct {
+	databaseType string
+	ipVersion    uint64
+	nodeCount    uint64
+	recordSize   uint64
+
+	tree []byte
+	data []byte
+
+	// ipv4Start is the node at which IPv4 addresses are looked up in an IPv6 tree
+	ipv4Start uint64
+}
+
+// openDatabase will read and parse the MaxMind DB at a path.
+func openDatabase(path string) (*database, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read database: %s", err)
+	}
+
+	db, err := parseDatabase(buf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database %s: %s", path, err)
+	}
+	return db, nil
+}
+
+// parseDatabase will parse a MaxMind DB.
+func parseDatabase(buf []byte) (*database, error) {
+	markerIndex := bytes.LastIndex(buf, metadataMarker)
+	if markerIndex < 0 {
+		return nil, fmt.Errorf("missing metadata")
+	}
+
+	metadataDecoder := &dataDecoder{buf: buf[markerIndex+len(metadataMarker):]}
+	raw, _, err := metadataDecoder.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("decode metadata: %s", err)
+	}
+	metadata, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("metadata is not a map")
+	}
+
+	db := &database{}
+	db.databaseType, _ = metadata["database_type"].(string)
+	if db.nodeCount, ok = metadata["node_count"].(uint64); !ok {
+		return nil, fmt.Errorf("metadata is missing node_count")
+	}
+	if db.recordSize, ok = metadata["record_size"].(uint64); !ok {
+		return nil, fmt.Errorf("metadata is missing record_size")
+	}
+	if db.ipVersion, ok = metadata["ip_version"].(uint64); !ok {
+		return nil, fmt.Errorf("metadata is missing ip_version")
+	}
+
+	switch db.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("unsupported record size %d", db.recordSize)
+	}
+	if db.ipVersion != 4 && db.ipVersion != 6 {
+		return nil, fmt.Errorf("unsupported ip version %d", db.ipVersion)
+	}
+
+	treeSize := db.nodeCount * db.recordSize / 4
+	if treeSize+dataSectionSeparatorSize > uint64(markerIndex) {
+		return nil, fmt.Errorf("search tree is larger than the database")
+	}
+	db.tree = buf[:treeSize]
+	db.data = buf[treeSize+dataSectionSeparatorSize : markerIndex]
+
+	if db.ipVersion == 6 {
+		node := uint64(0)
+		for i := 0; i < 96 && node < db.nodeCount; i++ {
+			node = db.readRecord(node, 0)
+		}
+		db.ipv4Start = node
+	}
+	return db, nil
+}
+
+// lookup will find the record of an IP address. A nil record is returned
+// if the address is not in the database.
+func (db *database) lookup(ip net.IP) (map[string]interface{}, error) {
+	node := uint64(0)
+	bitCount := 128
+	if ipv4 := ip.To4(); ipv4 != nil {
+		ip = ipv4
+		bitCount = 32
+		node = db.ipv4Start
+	} else if db.ipVersion == 4 {
+		// An IPv4 database does not contain any IPv6 addresses
+		return nil, nil
+	}
+
+	for i := 0; i < bitCount && node < db.nodeCount; i++ {
+		bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+		node = db.readRecord(node, bit)
+	}
+
+	switch {
+	case node == db.nodeCount:
+		return nil, nil
+	case node < db.nodeCount:
+		return nil, fmt.Errorf("invalid search tree")
+	}
+
+	offset := node - db.nodeCount - dataSectionSeparatorSize
+	if offset >= uint64(len(db.data)) {
+		return nil, fmt.Errorf("invalid data pointer %d", offset)
+	}
+
+	decoder := &dataDecoder{buf: db.data}
+	value, _, err := decoder.decode(int(offset))
+	if err != nil {
+		return nil, fmt.Errorf("decode record: %s", err)
+	}
+
+	record, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("record is not a map")
+	}
+	return record, nil
+}
+
+// readRecord will read the left (0) or right (1) record of a node.
+func (db *database) readRecord(node uint64, bit byte) uint64 {
+	switch db.recordSize {
+	case 24:
+		b := db.tree[node*6+uint64(bit)*3:]
+		return uint64(b[0])<<16 | uint64(b[1])<<8 | uint64(b[2])
+	case 28:
+		b := db.tree[node*7:]
+		if bit == 0 {
+			return uint64(b[3]&0xF0)<<20 | uint64(b[0])<<16 | uint64(b[1])<<8 | uint64(b[2])
+		}
+		return uint64(b[3]&0x0F)<<24 | uint64(b[4])<<16 | uint64(b[5])<<8 | uint64(b[6])
+	default:
+		b := db.tree[node*8+uint64(bit)*4:]
+		return uint64(binary.BigEndian.Uint32(b))
+	}
+}
+
+// Data section field types
+const (
+	typeExtended  = 0
+	typePointer   = 1
+	typeString    = 2
+	typeDouble    = 3
+	typeBytes     = 4
+	typeUint16    = 5
+	typeUint32    = 6
+	typeMap       = 7
+	typeInt32     = 8
+	typeUint64    = 9
+	typeUint128   = 10
+	typeArray     = 11
+	typeContainer = 12
+	typeEnd       = 13
+	typeBool      = 14
+	typeFloat     = 15
+)
+
+// maxDecodeDepth guards against data which nests too deeply, or contains a cycle of pointers.
+const maxDecodeDepth = 64
+
+// errUnexpectedEnd is returned when a value extends past the end of the data.
+var errUnexpectedEnd = fmt.Errorf("unexpected end of data")
+
+// dataDecoder decodes values from the data section of a MaxMind DB.
+// Pointers are offsets from the start of buf.
+type dataDecoder struct {
+	buf   []byte
+	depth int
+}
+
+// decode will decode the value at an offset, returning it and the offset which follows it.
+func (d *dataDecoder) decode(offset int) (interface{}, int, error) {
+	d.depth++
+	defer func() { d.depth-- }()
+	if d.depth > maxDecodeDepth {
+		return nil, 0, fmt.Errorf("data is nested too deeply")
+	}
+
+	typ, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typ == typePointer {
+		pointer, next, err := d.decodePointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(pointer)
+		return value, next, err
+	}
+
+	switch typ {
+	case typeMap:
+		values := make(map[string]interface{}, size)
+		for i := 0; i < size; i++ {
+			var key, value interface{}
+			if key, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("map key is not a string")
+			}
+			if value, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+			values[keyString] = value
+		}
+		return values, offset, nil
+	case typeArray:
+		values := make([]interface{}, 0, size)
+		for i := 0; i < size; i++ {
+			var value interface{}
+			if value, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+			values = append(values, value)
+		}
+		return values, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	if size > len(d.buf)-offset {
+		return nil, 0, errUnexpectedEnd
+	}
+	b := d.buf[offset : offset+size]
+	next := offset + size
+
+	switch typ {
+	case typeString:
+		return string(b), next, nil
+	case typeBytes:
+		return append([]byte(nil), b...), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("invalid size %d of double", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("invalid size %d of float", size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), next, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("invalid size %d of unsigned integer", size)
+		}
+		return decodeUint(b), next, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("invalid size %d of int32", size)
+		}
+		return int64(int32(decodeUint(b))), next, nil
+	case typeUint128:
+		if size > 16 {
+			return nil, 0, fmt.Errorf("invalid size %d of uint128", size)
+		}
+		return new(big.Int).SetBytes(b).String(), next, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported data type %d", typ)
+	}
+}
+
+// decodeControl will decode the control byte of a field, returning its type,
+// size and the offset of its payload.
+func (d *dataDecoder) decodeControl(offset int) (int, int, int, error) {
+	if offset >= len(d.buf) {
+		return 0, 0, 0, errUnexpectedEnd
+	}
+	control := d.buf[offset]
+	offset++
+
+	typ := int(control >> 5)
+	if typ == typeExtended {
+		if offset >= len(d.buf) {
+			return 0, 0, 0, errUnexpectedEnd
+		}
+		typ = 7 + int(d.buf[offset])
+		offset++
+		if typ < typeInt32 || typ == typeContainer || typ == typeEnd {
+			return 0, 0, 0, fmt.Errorf("invalid extended type %d", typ)
+		}
+	}
+
+	size := int(control & 0x1F)
+	if typ == typePointer {
+		return typ, size, offset, nil
+	}
+
+	if size >= 29 {
+		n := size - 28
+		if n > len(d.buf)-offset {
+			return 0, 0, 0, errUnexpectedEnd
+		}
+		extra := int(decodeUint(d.buf[offset : offset+n]))
+		offset += n
+		switch n {
+		case 1:
+			size = 29 + extra
+		case 2:
+			size = 285 + extra
+		default:
+			size = 65821 + extra
+		}
+	}
+	return typ, size, offset, nil
+}
+
+// decodePointer will decode a pointer from the size bits of its control byte and the bytes which follow it.
+func (d *dataDecoder) decodePointer(size, offset int) (int, int, error) {
+	n := (size>>3)&0x3 + 1
+	if n > len(d.buf)-offset {
+		return 0, 0, errUnexpectedEnd
+	}
+	b := d.buf[offset : offset+n]
+
+	var pointer int
+	switch n {
+	case 1:
+		pointer = (size&0x7)<<8 | int(b[0])
+	case 2:
+		pointer = ((size&0x7)<<16 | int(decodeUint(b))) + 2048
+	case 3:
+		pointer = ((size&0x7)<<24 | int(decodeUint(b))) + 526336
+	default:
+		pointer = int(decodeUint(b))
+	}
+	return pointer, offset + n, nil
+}
+
+// decodeUint will decode a big-endian unsigned integer of up to 8 bytes.
+func decodeUint(b []byte) uint64 {
+	var value uint64
+	for _, c := range b {
+		value = value<<8 | uint64(c)
+	}
+	return value
+}
@@ -0,0 +1,390 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoip
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testNetwork is a network and its record, to be written to a test database.
+type testNetwork struct {
+	cidr   string
+	record map[string]interface{}
+}
+
+// testPointer is encoded as a pointer to an offset of the data section, which must be less than 2048.
+type testPointer int
+
+// testChild is a record of a node in the search tree of a test database.
+type testChild struct {
+	node   int
+	data   int
+	isNode bool
+	isData bool
+}
+
+// buildTestDatabase will build a MaxMind DB containing the networks.
+func buildTestDatabase(t *testing.T, ipVersion, recordSize int, databaseType string, networks []testNetwork) []byte {
+	nodes := [][2]testChild{{}}
+	var data []byte
+
+	for _, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network.cidr)
+		require.NoError(t, err)
+
+		ones, _ := ipNet.Mask.Size()
+		ip := ipNet.IP
+		if ipv4 := ip.To4(); ipv4 != nil {
+			ip = ipv4
+			if ipVersion == 6 {
+				ip = append(make(net.IP, 12), ipv4...)
+				ones += 96
+			}
+		}
+
+		node := 0
+		for i := 0; i < ones; i++ {
+			bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+			if i == ones-1 {
+				nodes[node][bit] = testChild{isData: true, data: len(data)}
+				break
+			}
+			if !nodes[node][bit].isNode {
+				nodes = append(nodes, [2]testChild{})
+				nodes[node][bit] = testChild{isNode: true, node: len(nodes) - 1}
+			}
+			node = nodes[node][bit].node
+		}
+		data = append(data, encodeTestValue(network.record)...)
+	}
+
+	nodeCount := len(nodes)
+	record := func(c testChild) uint64 {
+		switch {
+		case c.isNode:
+			return uint64(c.node)
+		case c.isData:
+			return uint64(nodeCount + dataSectionSeparatorSize + c.data)
+		default:
+			return uint64(nodeCount)
+		}
+	}
+
+	var buf []byte
+	for _, node := range nodes {
+		left, right := record(node[0]), record(node[1])
+		switch recordSize {
+		case 24:
+			buf = append(buf, byte(left>>16), byte(left>>8), byte(left), byte(right>>16), byte(right>>8), byte(right))
+		case 28:
+			buf = append(buf, byte(left>>16), byte(left>>8), byte(left), byte(left>>24)<<4|byte(right>>24)&0x0F, byte(right>>16), byte(right>>8), byte(right))
+		default:
+			buf = append(buf, byte(left>>24), byte(left>>16), byte(left>>8), byte(left), byte(right>>24), byte(right>>16), byte(right>>8), byte(right))
+		}
+	}
+
+	buf = append(buf, make([]byte, dataSectionSeparatorSize)...)
+	buf = append(buf, data...)
+	buf = append(buf, metadataMarker...)
+	buf = append(buf, encodeTestValue(map[string]interface{}{
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(recordSize),
+		"ip_version":                  uint16(ipVersion),
+		"database_type":               databaseType,
+		"languages":                   []interface{}{"en"},
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1600000000),
+	})...)
+	return buf
+}
+
+// encodeTestValue will encode a value in the format of the data section.
+func encodeTestValue(value interface{}) []byte {
+	switch v := value.(type) {
+	case testPointer:
+		return []byte{typePointer<<5 | byte(v>>8&0x7), byte(v)}
+	case string:
+		return append(encodeTestControl(typeString, len(v)), v...)
+	case []byte:
+		return append(encodeTestControl(typeBytes, len(v)), v...)
+	case float64:
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, math.Float64bits(v))
+		return append(encodeTestControl(typeDouble, 8), b...)
+	case float32:
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, math.Float32bits(v))
+		return append(encodeTestControl(typeFloat, 4), b...)
+	case uint16:
+		return append(encodeTestControl(typeUint16, 2), byte(v>>8), byte(v))
+	case uint32:
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, v)
+		return append(encodeTestControl(typeUint32, 4), b...)
+	case uint64:
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, v)
+		return append(encodeTestControl(typeUint64, 8), b...)
+	case int32:
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, uint32(v))
+		return append(encodeTestControl(typeInt32, 4), b...)
+	case bool:
+		if v {
+			return encodeTestControl(typeBool, 1)
+		}
+		return encodeTestControl(typeBool, 0)
+	case []interface{}:
+		b := encodeTestControl(typeArray, len(v))
+		for _, item := range v {
+			b = append(b, encodeTestValue(item)...)
+		}
+		return b
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		b := encodeTestControl(typeMap, len(v))
+		for _, key := range keys {
+			b = append(b, encodeTestValue(key)...)
+			b = append(b, encodeTestValue(v[key])...)
+		}
+		return b
+	default:
+		panic("unsupported test value")
+	}
+}
+
+// encodeTestControl will encode the control byte, and any extended type and size bytes, of a field.
+func encodeTestControl(typ, size int) []byte {
+	var b []byte
+	if typ > typeMap {
+		b = []byte{0, byte(typ - 7)}
+	} else {
+		b = []byte{byte(typ << 5)}
+	}
+
+	switch {
+	case size < 29:
+		b[0] |= byte(size)
+	case size < 285:
+		b[0] |= 29
+		b = append(b, byte(size-29))
+	case size < 65821:
+		b[0] |= 30
+		b = append(b, byte((size-285)>>8), byte(size-285))
+	default:
+		b[0] |= 31
+		b = append(b, byte((size-65821)>>16), byte((size-65821)>>8), byte(size-65821))
+	}
+	return b
+}
+
+func TestDatabaseLookup(t *testing.T) {
+	networks := []testNetwork{
+		{"81.2.69.0/24", map[string]interface{}{"name": "london"}},
+		{"89.160.20.128/25", map[string]interface{}{"name": "linkoping"}},
+		{"2001:218::/32", map[string]interface{}{"name": "japan"}},
+	}
+
+	cases := []struct {
+		name      string
+		ipVersion int
+		address   string
+		expected  map[string]interface{}
+	}{
+		{"IPv4", 6, "81.2.69.142", map[string]interface{}{"name": "london"}},
+		{"IPv4SmallerNetwork", 6, "89.160.20.129", map[string]interface{}{"name": "linkoping"}},
+		{"IPv4NotFound", 6, "89.160.20.1", nil},
+		{"IPv6", 6, "2001:218:1::1", map[string]interface{}{"name": "japan"}},
+		{"IPv6NotFound", 6, "2001:219::1", nil},
+		{"IPv4Database", 4, "81.2.69.1", map[string]interface{}{"name": "london"}},
+		{"IPv6InIPv4Database", 4, "2001:218:1::1", nil},
+	}
+
+	for _, recordSize := range []int{24, 28, 32} {
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				var dbNetworks []testNetwork
+				for _, network := range networks {
+					if tc.ipVersion == 4 && strings.Contains(network.cidr, ":") {
+						continue
+					}
+					dbNetworks = append(dbNetworks, network)
+				}
+
+				db, err := parseDatabase(buildTestDatabase(t, tc.ipVersion, recordSize, "Test", dbNetworks))
+				require.NoError(t, err)
+				require.Equal(t, "Test", db.databaseType)
+
+				record, err := db.lookup(net.ParseIP(tc.address))
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, record)
+			})
+		}
+	}
+}
+
+func TestParseDatabaseErrors(t *testing.T) {
+	// A search tree of a single node, followed by the data section separator
+	header := append(make([]byte, 6+dataSectionSeparatorSize), metadataMarker...)
+	withMetadata := func(metadata interface{}) []byte {
+		return append(append([]byte(nil), header...), encodeTestValue(metadata)...)
+	}
+
+	cases := []struct {
+		name      string
+		input     []byte
+		expectErr string
+	}{
+		{
+			"MissingMetadata",
+			[]byte("not a database"),
+			"missing metadata",
+		},
+		{
+			"TruncatedMetadata",
+			append(append([]byte(nil), header...), encodeTestControl(typeMap, 1)...),
+			"decode metadata",
+		},
+		{
+			"MetadataNotMap",
+			withMetadata("metadata"),
+			"metadata is not a map",
+		},
+		{
+			"MissingNodeCount",
+			withMetadata(map[string]interface{}{"record_size": uint16(24), "ip_version": uint16(6)}),
+			"missing node_count",
+		},
+		{
+			"UnsupportedRecordSize",
+			withMetadata(map[string]interface{}{"node_count": uint32(1), "record_size": uint16(20), "ip_version": uint16(6)}),
+			"unsupported record size 20",
+		},
+		{
+			"UnsupportedIPVersion",
+			withMetadata(map[string]interface{}{"node_count": uint32(1), "record_size": uint16(24), "ip_version": uint16(5)}),
+			"unsupported ip version 5",
+		},
+		{
+			"TreeTooLarge",
+			withMetadata(map[string]interface{}{"node_count": uint32(100), "record_size": uint16(24), "ip_version": uint16(6)}),
+			"search tree is larger than the database",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseDatabase(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectErr)
+		})
+	}
+}
+
+func TestDataDecoder(t *testing.T) {
+	longString := strings.Repeat("a", 300)
+	veryLongString := strings.Repeat("b", 70000)
+
+	cases := []struct {
+		name     string
+		input    []byte
+		expected interface{}
+	}{
+		{"String", encodeTestValue("hello"), "hello"},
+		{"LongString", encodeTestValue(longString), longString},
+		{"VeryLongString", encodeTestValue(veryLongString), veryLongString},
+		{"Double", encodeTestValue(51.5142), 51.5142},
+		{"Float", encodeTestValue(float32(1.5)), 1.5},
+		{"Bytes", encodeTestValue([]byte{1, 2}), []byte{1, 2}},
+		{"Uint16", encodeTestValue(uint16(443)), uint64(443)},
+		{"Uint32", encodeTestValue(uint32(15169)), uint64(15169)},
+		{"Uint64", encodeTestValue(uint64(1) << 40), uint64(1) << 40},
+		{"Int32", encodeTestValue(int32(-5)), int64(-5)},
+		{"Uint128", append(encodeTestControl(typeUint128, 9), 1, 0, 0, 0, 0, 0, 0, 0, 0), "18446744073709551616"},
+		{"True", encodeTestValue(true), true},
+		{"False", encodeTestValue(false), false},
+		{"Array", encodeTestValue([]interface{}{"a", uint16(1)}), []interface{}{"a", uint64(1)}},
+		{
+			"Map",
+			encodeTestValue(map[string]interface{}{"names": map[string]interface{}{"en": "London"}}),
+			map[string]interface{}{"names": map[string]interface{}{"en": "London"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			decoder := &dataDecoder{buf: tc.input}
+			value, next, err := decoder.decode(0)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, value)
+			require.Equal(t, len(tc.input), next)
+		})
+	}
+}
+
+func TestDataDecoderPointer(t *testing.T) {
+	buf := encodeTestValue("London")
+	mapOffset := len(buf)
+	buf = append(buf, encodeTestControl(typeMap, 2)...)
+	buf = append(buf, encodeTestValue("en")...)
+	buf = append(buf, encodeTestValue(testPointer(0))...)
+	buf = append(buf, encodeTestValue("de")...)
+	buf = append(buf, encodeTestValue(testPointer(0))...)
+
+	decoder := &dataDecoder{buf: buf}
+	value, next, err := decoder.decode(mapOffset)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"en": "London", "de": "London"}, value)
+	require.Equal(t, len(buf), next)
+}
+
+func TestDataDecoderErrors(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     []byte
+		expectErr string
+	}{
+		{"Empty", []byte{}, "unexpected end of data"},
+		{"TruncatedString", encodeTestValue("hello")[:3], "unexpected end of data"},
+		{"TruncatedSize", encodeTestControl(typeString, 300)[:1], "unexpected end of data"},
+		{"TruncatedPointer", encodeTestValue(testPointer(0))[:1], "unexpected end of data"},
+		{"InvalidExtendedType", []byte{0, 5}, "invalid extended type 12"},
+		{"InvalidDoubleSize", append(encodeTestControl(typeDouble, 4), 0, 0, 0, 0), "invalid size 4 of double"},
+		{"NonStringKey", append(encodeTestControl(typeMap, 1), encodeTestValue(uint16(1))...), "map key is not a string"},
+		{"PointerCycle", encodeTestValue(testPointer(0)), "nested too deeply"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			decoder := &dataDecoder{buf: tc.input}
+			_, _, err := decoder.decode(0)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectErr)
+		})
+	}
+}
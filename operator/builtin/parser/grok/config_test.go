@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grok
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestGrokParserConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "pattern",
+			Expect: func() *GrokParserConfig {
+				cfg := defaultCfg()
+				cfg.ParseFrom = entry.NewBodyField("from")
+				cfg.Pattern = "%{COMBINEDAPACHELOG}"
+				return cfg
+			}(),
+		},
+		{
+			Name: "patterns",
+			Expect: func() *GrokParserConfig {
+				cfg := defaultCfg()
+				cfg.Patterns = []string{"%{SYSLOGLINE}", "%{GREEDYDATA:message}"}
+				return cfg
+			}(),
+		},
+		{
+			Name: "pattern_definitions",
+			Expect: func() *GrokParserConfig {
+				cfg := defaultCfg()
+				cfg.Pattern = "%{QUEUE:queue_id}: %{GREEDYDATA:message}"
+				cfg.PatternDefinitions = map[string]string{
+					"QUEUE": "[0-9A-F]{10,11}",
+				}
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *GrokParserConfig {
+	return NewGrokParserConfig("grok_parser")
+}
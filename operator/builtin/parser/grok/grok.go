@@ -0,0 +1,277 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grok
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/errors"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("grok_parser", func() operator.Builder { return NewGrokParserConfig("") })
+}
+
+// NewGrokParserConfig creates a new grok parser config with default values
+func NewGrokParserConfig(operatorID string) *GrokParserConfig {
+	return &GrokParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "grok_parser"),
+	}
+}
+
+// GrokParserConfig is the configuration of a grok parser operator.
+type GrokParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	Pattern            string            `mapstructure:"pattern,omitempty"             json:"pattern,omitempty"             yaml:"pattern,omitempty"`
+	Patterns           []string          `mapstructure:"patterns,omitempty"            json:"patterns,omitempty"            yaml:"patterns,omitempty"`
+	PatternDefinitions map[string]string `mapstructure:"pattern_definitions,omitempty" json:"pattern_definitions,omitempty" yaml:"pattern_definitions,omitempty"`
+}
+
+// Build will build a grok parser operator.
+func (c GrokParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := c.Patterns
+	switch {
+	case c.Pattern != "" && len(c.Patterns) > 0:
+		return nil, fmt.Errorf("only one of 'pattern' or 'patterns' can be set")
+	case c.Pattern != "":
+		patterns = []string{c.Pattern}
+	case len(c.Patterns) == 0:
+		return nil, fmt.Errorf("missing required field 'pattern'")
+	}
+
+	definitions := make(map[string]string, len(builtinPatterns)+len(c.PatternDefinitions))
+	for name, pattern := range builtinPatterns {
+		definitions[name] = pattern
+	}
+	for name, pattern := range c.PatternDefinitions {
+		definitions[name] = pattern
+	}
+
+	grokParser := &GrokParser{
+		ParserOperator: parserOperator,
+	}
+	for _, pattern := range patterns {
+		compiled, err := compile(pattern, definitions)
+		if err != nil {
+			return nil, err
+		}
+		grokParser.patterns = append(grokParser.patterns, compiled)
+	}
+
+	return []operator.Operator{grokParser}, nil
+}
+
+// GrokParser is an operator that parses grok patterns in an entry.
+type GrokParser struct {
+	helper.ParserOperator
+	patterns []*compiledPattern
+}
+
+// Process will parse an entry with grok patterns.
+func (g *GrokParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return g.ParserOperator.ProcessWith(ctx, entry, g.parse)
+}
+
+// parse will parse a value with the first grok pattern which matches it.
+func (g *GrokParser) parse(value interface{}) (interface{}, error) {
+	var raw string
+	switch m := value.(type) {
+	case string:
+		raw = m
+	case []byte:
+		raw = string(m)
+	default:
+		return nil, fmt.Errorf("type '%T' cannot be parsed as grok", value)
+	}
+
+	for _, pattern := range g.patterns {
+		matches := pattern.regexp.FindStringSubmatchIndex(raw)
+		if matches == nil {
+			continue
+		}
+		return pattern.values(raw, matches)
+	}
+	return nil, fmt.Errorf("grok pattern does not match")
+}
+
+// capture is a named capture of a grok pattern.
+type capture struct {
+	field     string
+	valueType string
+	group     int
+}
+
+// compiledPattern is a grok pattern compiled to a regular expression.
+type compiledPattern struct {
+	regexp   *regexp.Regexp
+	captures []capture
+}
+
+// values will return the values captured by a match. Captures which matched nothing are
+// omitted, and when a field is captured more than once, the first value is used.
+func (p *compiledPattern) values(raw string, matches []int) (map[string]interface{}, error) {
+	parsedValues := map[string]interface{}{}
+	for _, c := range p.captures {
+		start, end := matches[2*c.group], matches[2*c.group+1]
+		if start < 0 || start == end {
+			continue
+		}
+		if _, ok := parsedValues[c.field]; ok {
+			continue
+		}
+
+		value, err := convert(raw[start:end], c.valueType)
+		if err != nil {
+			return nil, fmt.Errorf("convert field '%s': %s", c.field, err)
+		}
+		parsedValues[c.field] = value
+	}
+	return parsedValues, nil
+}
+
+// convert will convert a captured value to its type.
+func convert(value, valueType string) (interface{}, error) {
+	switch valueType {
+	case "int":
+		return strconv.ParseInt(value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(value, 64)
+	default:
+		return value, nil
+	}
+}
+
+var (
+	// referencePattern matches a reference to a grok pattern, in the form %{NAME}, %{NAME:field} or %{NAME:field:type}
+	referencePattern = regexp.MustCompile(`%\{([A-Za-z0-9_]+)(?::([^:}]+))?(?::([^:}]+))?\}`)
+
+	// namedGroupPattern matches the start of a named capture group in the form (?<name>, which RE2 writes as (?P<name>
+	namedGroupPattern = regexp.MustCompile(`\(\?<([A-Za-z_][A-Za-z0-9_]*)>`)
+)
+
+// compiler expands the references of a grok pattern.
+type compiler struct {
+	definitions map[string]string
+	captures    []capture
+	expanding   map[string]bool
+}
+
+// compile will compile a grok pattern to a regular expression.
+func compile(pattern string, definitions map[string]string) (*compiledPattern, error) {
+	c := &compiler{
+		definitions: definitions,
+		expanding:   map[string]bool{},
+	}
+
+	expanded, err := c.expand(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("compiling grok pattern '%s': %s", pattern, err)
+	}
+
+	// Named capture groups written in the pattern itself are captured as strings
+	captures := c.captures
+	for i, name := range r.SubexpNames() {
+		if name != "" && !strings.HasPrefix(name, "_grok") {
+			captures = append(captures, capture{field: name, group: i})
+		}
+	}
+	if len(captures) == 0 {
+		return nil, errors.NewError(
+			"no named captures in grok pattern",
+			"use named captures like '%{IP:client}' to specify the key name for the parsed field",
+		)
+	}
+
+	// Map capture group names to their indexes
+	groups := map[string]int{}
+	for i, name := range r.SubexpNames() {
+		groups[name] = i
+	}
+	for i := range c.captures {
+		captures[i].group = groups[fmt.Sprintf("_grok%d", i)]
+	}
+
+	return &compiledPattern{regexp: r, captures: captures}, nil
+}
+
+// expand will replace the references of a pattern with the patterns they refer to.
+func (c *compiler) expand(pattern string) (string, error) {
+	pattern = namedGroupPattern.ReplaceAllString(pattern, "(?P<$1>")
+
+	var expandErr error
+	expanded := referencePattern.ReplaceAllStringFunc(pattern, func(reference string) string {
+		if expandErr != nil {
+			return ""
+		}
+
+		parts := referencePattern.FindStringSubmatch(reference)
+		name, field, valueType := parts[1], parts[2], parts[3]
+
+		definition, ok := c.definitions[name]
+		if !ok {
+			expandErr = fmt.Errorf("grok pattern '%s' is not defined", name)
+			return ""
+		}
+		if c.expanding[name] {
+			expandErr = fmt.Errorf("grok pattern '%s' refers to itself", name)
+			return ""
+		}
+
+		switch valueType {
+		case "", "string", "int", "float":
+		default:
+			expandErr = fmt.Errorf("invalid type '%s' for field '%s', expected int or float", valueType, field)
+			return ""
+		}
+
+		c.expanding[name] = true
+		inner, err := c.expand(definition)
+		delete(c.expanding, name)
+		if err != nil {
+			expandErr = err
+			return ""
+		}
+
+		if field == "" {
+			return "(?:" + inner + ")"
+		}
+
+		group := fmt.Sprintf("_grok%d", len(c.captures))
+		c.captures = append(c.captures, capture{field: field, valueType: valueType})
+		return "(?P<" + group + ">" + inner + ")"
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return expanded, nil
+}
@@ -0,0 +1,371 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grok
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T, modify func(*GrokParserConfig)) (*GrokParser, *testutil.FakeOutput) {
+	cfg := NewGrokParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	modify(cfg)
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*GrokParser)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fakeOutput}))
+	return parser, fakeOutput
+}
+
+func TestBuiltinPatternsCompile(t *testing.T) {
+	for name := range builtinPatterns {
+		t.Run(name, func(t *testing.T) {
+			_, err := compile("%{"+name+":value}", builtinPatterns)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestGrokParserBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*GrokParserConfig)
+		expectedErr string
+	}{
+		{
+			"MissingPattern",
+			func(cfg *GrokParserConfig) {},
+			"missing required field 'pattern'",
+		},
+		{
+			"PatternAndPatterns",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{WORD:word}"
+				cfg.Patterns = []string{"%{INT:int}"}
+			},
+			"only one of 'pattern' or 'patterns' can be set",
+		},
+		{
+			"UndefinedPattern",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{NOPE:value}"
+			},
+			"grok pattern 'NOPE' is not defined",
+		},
+		{
+			"RecursivePattern",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{LOOP:value}"
+				cfg.PatternDefinitions = map[string]string{
+					"LOOP":  "a%{INNER}",
+					"INNER": "b%{LOOP}",
+				}
+			},
+			"refers to itself",
+		},
+		{
+			"InvalidType",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{INT:count:long}"
+			},
+			"invalid type 'long' for field 'count'",
+		},
+		{
+			"NoNamedCaptures",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{IP} %{WORD}"
+			},
+			"no named captures in grok pattern",
+		},
+		{
+			"InvalidRegex",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{WORD:word} (unclosed"
+			},
+			"compiling grok pattern",
+		},
+		{
+			"InvalidPatternInList",
+			func(cfg *GrokParserConfig) {
+				cfg.Patterns = []string{"%{WORD:word}", "%{NOPE:value}"}
+			},
+			"grok pattern 'NOPE' is not defined",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewGrokParserConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestGrokParserParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*GrokParserConfig)
+		input    interface{}
+		expected map[string]interface{}
+	}{
+		{
+			"CombinedApacheLog",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{COMBINEDAPACHELOG}"
+			},
+			`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08 [en] (Win98; I ;Nav)"`,
+			map[string]interface{}{
+				"clientip":    "127.0.0.1",
+				"ident":       "-",
+				"auth":        "frank",
+				"timestamp":   "10/Oct/2000:13:55:36 -0700",
+				"verb":        "GET",
+				"request":     "/apache_pb.gif",
+				"httpversion": "1.0",
+				"response":    "200",
+				"bytes":       "2326",
+				"referrer":    `"http://www.example.com/start.html"`,
+				"agent":       `"Mozilla/4.08 [en] (Win98; I ;Nav)"`,
+			},
+		},
+		{
+			"CommonApacheLogNoBytes",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{COMMONAPACHELOG}"
+			},
+			`2001:db8::1 - - [10/Oct/2000:13:55:36 -0700] "-" 408 -`,
+			map[string]interface{}{
+				"clientip":   "2001:db8::1",
+				"ident":      "-",
+				"auth":       "-",
+				"timestamp":  "10/Oct/2000:13:55:36 -0700",
+				"rawrequest": "-",
+				"response":   "408",
+			},
+		},
+		{
+			"SyslogLine",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{SYSLOGLINE}"
+			},
+			"Jun  1 12:00:00 web-01 sshd[4242]: Accepted publickey for deploy from 10.0.0.5",
+			map[string]interface{}{
+				"timestamp": "Jun  1 12:00:00",
+				"logsource": "web-01",
+				"program":   "sshd",
+				"pid":       "4242",
+				"message":   "Accepted publickey for deploy from 10.0.0.5",
+			},
+		},
+		{
+			"Syslog5424Line",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{SYSLOG5424LINE}"
+			},
+			"<34>1 2021-06-01T12:00:00.000Z host.example.com app 1234 ID47 - started",
+			map[string]interface{}{
+				"syslog5424_pri":   "34",
+				"syslog5424_ver":   "1",
+				"syslog5424_ts":    "2021-06-01T12:00:00.000Z",
+				"syslog5424_host":  "host.example.com",
+				"syslog5424_app":   "app",
+				"syslog5424_proc":  "1234",
+				"syslog5424_msgid": "ID47",
+				"syslog5424_msg":   "started",
+			},
+		},
+		{
+			"HTTPDErrorLog",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{HTTPD_ERRORLOG}"
+			},
+			"[Wed Oct 11 14:32:52 2000] [error] [client 127.0.0.1] client denied by server configuration",
+			map[string]interface{}{
+				"timestamp": "Wed Oct 11 14:32:52 2000",
+				"loglevel":  "error",
+				"clientip":  "127.0.0.1",
+				"message":   "client denied by server configuration",
+			},
+		},
+		{
+			"TypedCaptures",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = `%{WORD:method} %{URIPATHPARAM:path} %{INT:status:int} %{NUMBER:duration:float}ms %{INT:size:string}`
+			},
+			"GET /api/users?page=2 404 12.5ms 512",
+			map[string]interface{}{
+				"method":   "GET",
+				"path":     "/api/users?page=2",
+				"status":   int64(404),
+				"duration": 12.5,
+				"size":     "512",
+			},
+		},
+		{
+			"CustomDefinitions",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{POSTFIX_QUEUEID:queue_id}: %{POSTFIX_KV}"
+				cfg.PatternDefinitions = map[string]string{
+					"POSTFIX_QUEUEID": "[0-9A-F]{10,11}",
+					"POSTFIX_KV":      "to=<%{EMAILADDRESS:to}>, status=%{WORD:status}",
+				}
+			},
+			"4BF7B5A2C1: to=<jane@example.com>, status=sent",
+			map[string]interface{}{
+				"queue_id": "4BF7B5A2C1",
+				"to":       "jane@example.com",
+				"status":   "sent",
+			},
+		},
+		{
+			"OverrideBuiltin",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{WORD:word}"
+				cfg.PatternDefinitions = map[string]string{
+					"WORD": "[a-z]+",
+				}
+			},
+			"ABC def",
+			map[string]interface{}{
+				"word": "def",
+			},
+		},
+		{
+			"InlineNamedGroup",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = `(?<queue_id>[0-9A-F]{10}): %{GREEDYDATA:message}`
+			},
+			"4BF7B5A2C1: removed",
+			map[string]interface{}{
+				"queue_id": "4BF7B5A2C1",
+				"message":  "removed",
+			},
+		},
+		{
+			"EmptyCapturesOmitted",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = `%{WORD:level}(?: \[%{DATA:component}\])?:%{DATA:message}$`
+			},
+			"INFO:",
+			map[string]interface{}{
+				"level": "INFO",
+			},
+		},
+		{
+			"FirstMatchingPattern",
+			func(cfg *GrokParserConfig) {
+				cfg.Patterns = []string{
+					"%{IP:client} %{WORD:verb}",
+					"%{GREEDYDATA:message}",
+				}
+			},
+			"not a request",
+			map[string]interface{}{
+				"message": "not a request",
+			},
+		},
+		{
+			"Bytes",
+			func(cfg *GrokParserConfig) {
+				cfg.Pattern = "%{WORD:word}"
+			},
+			[]byte("hello"),
+			map[string]interface{}{
+				"word": "hello",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, tc.modify)
+			actual, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestGrokParserParseErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		pattern  string
+		input    interface{}
+		expected string
+	}{
+		{"InvalidType", "%{WORD:word}", []int{}, "type '[]int' cannot be parsed as grok"},
+		{"NoMatch", "^%{INT:count}$", "abc", "grok pattern does not match"},
+		{"ConversionError", "%{NOTSPACE:count:int}", "12abc", "convert field 'count'"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, func(cfg *GrokParserConfig) {
+				cfg.Pattern = tc.pattern
+			})
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestGrokParserProcess(t *testing.T) {
+	parser, fakeOutput := newTestParser(t, func(cfg *GrokParserConfig) {
+		cfg.Pattern = "%{TIMESTAMP_ISO8601:time} %{LOGLEVEL:level} %{GREEDYDATA:message}"
+
+		timeField := entry.NewBodyField("time")
+		timeParser := helper.NewTimeParser()
+		timeParser.ParseFrom = &timeField
+		timeParser.LayoutType = helper.GotimeKey
+		timeParser.Layout = time.RFC3339
+		cfg.TimeParser = &timeParser
+
+		severityField := entry.NewBodyField("level")
+		severityParser := helper.NewSeverityParserConfig()
+		severityParser.ParseFrom = &severityField
+		cfg.SeverityParserConfig = &severityParser
+	})
+
+	e := entry.New()
+	e.Body = "2021-06-01T12:00:00Z ERROR connection lost"
+	require.NoError(t, parser.Process(context.Background(), e))
+
+	select {
+	case out := <-fakeOutput.Received:
+		require.Equal(t, map[string]interface{}{
+			"message": "connection lost",
+		}, out.Body)
+		require.Equal(t, time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC), out.Timestamp.UTC())
+		require.Equal(t, entry.Error, out.Severity)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
@@ -92,6 +92,24 @@ func TestJSONParserConfig(t *testing.T) {
 				return cfg
 			}(),
 		},
+		{
+			Name: "parse_nested",
+			Expect: func() *JSONParserConfig {
+				cfg := defaultCfg()
+				cfg.ParseNested = true
+				cfg.MaxNestedDepth = 2
+				cfg.NestedFields = []string{"message", "payload"}
+				return cfg
+			}(),
+		},
+		{
+			Name: "fields",
+			Expect: func() *JSONParserConfig {
+				cfg := defaultCfg()
+				cfg.Fields = []string{"level", "http.status"}
+				return cfg
+			}(),
+		},
 	}
 
 	for _, tc := range cases {
@@ -17,6 +17,7 @@ package json
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -25,6 +26,9 @@ import (
 	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 )
 
+// DefaultMaxNestedDepth is the number of levels of nested JSON which are parsed when no limit is configured
+const DefaultMaxNestedDepth = 3
+
 func init() {
 	operator.Register("json_parser", func() operator.Builder { return NewJSONParserConfig("") })
 }
@@ -39,6 +43,11 @@ func NewJSONParserConfig(operatorID string) *JSONParserConfig {
 // JSONParserConfig is the configuration of a JSON parser operator.
 type JSONParserConfig struct {
 	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	ParseNested    bool     `mapstructure:"parse_nested,omitempty"     json:"parse_nested,omitempty"     yaml:"parse_nested,omitempty"`
+	MaxNestedDepth int      `mapstructure:"max_nested_depth,omitempty" json:"max_nested_depth,omitempty" yaml:"max_nested_depth,omitempty"`
+	NestedFields   []string `mapstructure:"nested_fields,omitempty"    json:"nested_fields,omitempty"    yaml:"nested_fields,omitempty"`
+	Fields         []string `mapstructure:"fields,omitempty"           json:"fields,omitempty"           yaml:"fields,omitempty"`
 }
 
 // Build will build a JSON parser operator.
@@ -48,9 +57,37 @@ func (c JSONParserConfig) Build(context operator.BuildContext) ([]operator.Opera
 		return nil, err
 	}
 
+	if !c.ParseNested && (c.MaxNestedDepth != 0 || len(c.NestedFields) > 0) {
+		return nil, fmt.Errorf("'max_nested_depth' and 'nested_fields' require 'parse_nested' to be enabled")
+	}
+
+	if c.MaxNestedDepth < 0 {
+		return nil, fmt.Errorf("invalid value for parameter 'max_nested_depth', must not be negative")
+	}
+
+	if c.MaxNestedDepth == 0 {
+		c.MaxNestedDepth = DefaultMaxNestedDepth
+	}
+
 	jsonParser := &JSONParser{
 		ParserOperator: parserOperator,
 		json:           jsoniter.ConfigFastest,
+		parseNested:    c.ParseNested,
+		maxNestedDepth: c.MaxNestedDepth,
+	}
+
+	if len(c.Fields) > 0 {
+		jsonParser.projection, err = newProjection(c.Fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.NestedFields) > 0 {
+		jsonParser.nestedFields = make(map[string]bool, len(c.NestedFields))
+		for _, field := range c.NestedFields {
+			jsonParser.nestedFields[field] = true
+		}
 	}
 
 	return []operator.Operator{jsonParser}, nil
@@ -60,6 +97,13 @@ func (c JSONParserConfig) Build(context operator.BuildContext) ([]operator.Opera
 type JSONParser struct {
 	helper.ParserOperator
 	json jsoniter.API
+
+	parseNested    bool
+	maxNestedDepth int
+	nestedFields   map[string]bool
+
+	// projection is the keys which are parsed, if only some fields are configured
+	projection projection
 }
 
 // Process will parse an entry for JSON.
@@ -72,12 +116,76 @@ func (j *JSONParser) parse(value interface{}) (interface{}, error) {
 	var parsedValue map[string]interface{}
 	switch m := value.(type) {
 	case string:
-		err := j.json.UnmarshalFromString(m, &parsedValue)
+		var err error
+		if j.projection != nil {
+			parsedValue, err = j.projection.parse(j.json, m)
+		} else {
+			err = j.json.UnmarshalFromString(m, &parsedValue)
+		}
 		if err != nil {
 			return nil, err
 		}
 	default:
 		return nil, fmt.Errorf("type %T cannot be parsed as JSON", value)
 	}
+
+	if j.parseNested {
+		j.parseNestedValues(parsedValue, 0, false)
+	}
 	return parsedValue, nil
 }
+
+// parseNestedValues will walk a parsed value, replacing strings which contain a JSON object
+// or array with their parsed value. Strings are only parsed within the allowed fields, and
+// while the number of levels of nested JSON is less than the maximum.
+func (j *JSONParser) parseNestedValues(value interface{}, depth int, allowed bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = j.parseNestedValues(child, depth, j.isNestedField(key))
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = j.parseNestedValues(child, depth, allowed)
+		}
+		return v
+	case string:
+		if !allowed || depth >= j.maxNestedDepth {
+			return v
+		}
+		nested, ok := j.parseNestedString(v)
+		if !ok {
+			return v
+		}
+		return j.parseNestedValues(nested, depth+1, allowed)
+	default:
+		return v
+	}
+}
+
+// isNestedField returns true if strings of the field may be parsed as JSON.
+// All fields are allowed when no fields are configured.
+func (j *JSONParser) isNestedField(key string) bool {
+	return j.nestedFields == nil || j.nestedFields[key]
+}
+
+// parseNestedString will parse a string which contains a JSON object or array.
+// Other strings, including those which are not valid JSON, are not parsed.
+func (j *JSONParser) parseNestedString(s string) (interface{}, bool) {
+	trimmed := strings.TrimSpace(s)
+	if len(trimmed) < 2 {
+		return nil, false
+	}
+
+	first, last := trimmed[0], trimmed[len(trimmed)-1]
+	if !(first == '{' && last == '}') && !(first == '[' && last == ']') {
+		return nil, false
+	}
+
+	var nested interface{}
+	if err := j.json.UnmarshalFromString(trimmed, &nested); err != nil {
+		return nil, false
+	}
+	return nested, true
+}
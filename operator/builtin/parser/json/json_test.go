@@ -262,3 +262,317 @@ parse_to: $.to`
 		require.Equal(t, expect, &actual)
 	})
 }
+
+func TestJSONParserNested(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*JSONParserConfig)
+		input    string
+		expected map[string]interface{}
+	}{
+		{
+			"Disabled",
+			func(cfg *JSONParserConfig) {},
+			`{"message":"{\"level\":\"info\"}"}`,
+			map[string]interface{}{
+				"message": `{"level":"info"}`,
+			},
+		},
+		{
+			"Object",
+			func(cfg *JSONParserConfig) {
+				cfg.ParseNested = true
+			},
+			`{"message":"{\"level\":\"info\",\"count\":1}","host":"a"}`,
+			map[string]interface{}{
+				"message": map[string]interface{}{
+					"level": "info",
+					"count": float64(1),
+				},
+				"host": "a",
+			},
+		},
+		{
+			"TwoLevels",
+			func(cfg *JSONParserConfig) {
+				cfg.ParseNested = true
+			},
+			`{"message":"{\"payload\":\"{\\\"user\\\":\\\"alice\\\"}\"}"}`,
+			map[string]interface{}{
+				"message": map[string]interface{}{
+					"payload": map[string]interface{}{
+						"user": "alice",
+					},
+				},
+			},
+		},
+		{
+			"ArrayAndNestedStructures",
+			func(cfg *JSONParserConfig) {
+				cfg.ParseNested = true
+			},
+			`{"envelope":{"items":["[1,2]","{\"a\":\"b\"}","plain"]}}`,
+			map[string]interface{}{
+				"envelope": map[string]interface{}{
+					"items": []interface{}{
+						[]interface{}{float64(1), float64(2)},
+						map[string]interface{}{"a": "b"},
+						"plain",
+					},
+				},
+			},
+		},
+		{
+			"NotJSON",
+			func(cfg *JSONParserConfig) {
+				cfg.ParseNested = true
+			},
+			`{"message":"{not json}","number":"123","quoted":"\"text\"","brace":"{"}`,
+			map[string]interface{}{
+				"message": "{not json}",
+				"number":  "123",
+				"quoted":  `"text"`,
+				"brace":   "{",
+			},
+		},
+		{
+			"MaxDepth",
+			func(cfg *JSONParserConfig) {
+				cfg.ParseNested = true
+				cfg.MaxNestedDepth = 1
+			},
+			`{"message":"{\"payload\":\"{\\\"user\\\":\\\"alice\\\"}\"}"}`,
+			map[string]interface{}{
+				"message": map[string]interface{}{
+					"payload": `{"user":"alice"}`,
+				},
+			},
+		},
+		{
+			"NestedFields",
+			func(cfg *JSONParserConfig) {
+				cfg.ParseNested = true
+				cfg.NestedFields = []string{"message", "payload"}
+			},
+			`{"message":"{\"payload\":\"{\\\"user\\\":\\\"alice\\\"}\",\"raw\":\"{\\\"x\\\":1}\"}","body":"{\"y\":2}"}`,
+			map[string]interface{}{
+				"message": map[string]interface{}{
+					"payload": map[string]interface{}{
+						"user": "alice",
+					},
+					"raw": `{"x":1}`,
+				},
+				"body": `{"y":2}`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewJSONParserConfig("test")
+			tc.modify(cfg)
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			parser := ops[0].(*JSONParser)
+
+			result, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestJSONParserNestedBuildFailure(t *testing.T) {
+	cfg := NewJSONParserConfig("test")
+	cfg.NestedFields = []string{"message"}
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "require 'parse_nested' to be enabled")
+
+	cfg = NewJSONParserConfig("test")
+	cfg.ParseNested = true
+	cfg.MaxNestedDepth = -1
+	_, err = cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid value for parameter 'max_nested_depth'")
+}
+
+func TestJSONParserFields(t *testing.T) {
+	input := `{"level":"info","message":"request served","http":{"method":"GET","status":200,"headers":{"accept":"*/*"}},"tags":["a","b"],"user":null}`
+
+	cases := []struct {
+		name      string
+		modify    func(*JSONParserConfig)
+		input     string
+		expected  map[string]interface{}
+		expectErr bool
+	}{
+		{
+			"TopLevel",
+			func(cfg *JSONParserConfig) {
+				cfg.Fields = []string{"level", "tags", "user"}
+			},
+			input,
+			map[string]interface{}{
+				"level": "info",
+				"tags":  []interface{}{"a", "b"},
+				"user":  nil,
+			},
+			false,
+		},
+		{
+			"Nested",
+			func(cfg *JSONParserConfig) {
+				cfg.Fields = []string{"level", "http.status", "http.headers.accept"}
+			},
+			input,
+			map[string]interface{}{
+				"level": "info",
+				"http": map[string]interface{}{
+					"status": float64(200),
+					"headers": map[string]interface{}{
+						"accept": "*/*",
+					},
+				},
+			},
+			false,
+		},
+		{
+			"WholeParent",
+			func(cfg *JSONParserConfig) {
+				cfg.Fields = []string{"http.status", "http"}
+			},
+			input,
+			map[string]interface{}{
+				"http": map[string]interface{}{
+					"method": "GET",
+					"status": float64(200),
+					"headers": map[string]interface{}{
+						"accept": "*/*",
+					},
+				},
+			},
+			false,
+		},
+		{
+			"Missing",
+			func(cfg *JSONParserConfig) {
+				cfg.Fields = []string{"trace_id", "message.text", "http.path"}
+			},
+			input,
+			map[string]interface{}{},
+			false,
+		},
+		{
+			"ParseNested",
+			func(cfg *JSONParserConfig) {
+				cfg.Fields = []string{"message"}
+				cfg.ParseNested = true
+			},
+			`{"message":"{\"level\":\"info\"}","host":"a"}`,
+			map[string]interface{}{
+				"message": map[string]interface{}{
+					"level": "info",
+				},
+			},
+			false,
+		},
+		{
+			"NotObject",
+			func(cfg *JSONParserConfig) {
+				cfg.Fields = []string{"level"}
+			},
+			`["info"]`,
+			nil,
+			true,
+		},
+		{
+			"Invalid",
+			func(cfg *JSONParserConfig) {
+				cfg.Fields = []string{"level"}
+			},
+			`{"level":"info",`,
+			nil,
+			true,
+		},
+		{
+			"InvalidSkipped",
+			func(cfg *JSONParserConfig) {
+				cfg.Fields = []string{"level"}
+			},
+			`{"message":{"a":},"level":"info"}`,
+			nil,
+			true,
+		},
+		{
+			"TrailingData",
+			func(cfg *JSONParserConfig) {
+				cfg.Fields = []string{"level"}
+			},
+			`{"level":"info"} {"level":"debug"}`,
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewJSONParserConfig("test")
+			tc.modify(cfg)
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			parser := ops[0].(*JSONParser)
+
+			result, err := parser.parse(tc.input)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestJSONParserFieldsBuildFailure(t *testing.T) {
+	for _, field := range []string{"", "http.", ".status", "http..status"} {
+		cfg := NewJSONParserConfig("test")
+		cfg.Fields = []string{"level", field}
+		_, err := cfg.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid field")
+	}
+}
+
+func BenchmarkJSONParser(b *testing.B) {
+	input := `{"timestamp":"2021-06-01T12:00:00.000Z","level":"info","logger":"http.server","message":"request served",` +
+		`"http":{"method":"GET","path":"/api/v1/users/123","status":200,"duration_ms":12.5,"bytes":5123,` +
+		`"headers":{"accept":"application/json","user-agent":"Mozilla/5.0 (X11; Linux x86_64)","x-request-id":"5f2b6c1e-8c1d-4c5e-9d3b-2a8e7f6d1c0b"}},` +
+		`"user":{"id":123,"name":"alice","roles":["admin","dev"]},"tags":["api","v1","users"],"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"}`
+
+	cases := []struct {
+		name   string
+		fields []string
+	}{
+		{"All", nil},
+		{"Fields", []string{"timestamp", "level", "message", "http.status"}},
+	}
+
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			cfg := NewJSONParserConfig("test")
+			cfg.Fields = tc.fields
+			ops, err := cfg.Build(testutil.NewBuildContext(b))
+			require.NoError(b, err)
+			parser := ops[0].(*JSONParser)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.parse(input); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// projection is a tree of the keys which are parsed from a JSON object. A key with a nil
+// projection has its whole value parsed, while the values of all other keys are skipped.
+type projection map[string]projection
+
+// newProjection will create a projection of fields, which are paths of keys separated by '.'.
+func newProjection(fields []string) (projection, error) {
+	root := projection{}
+	for _, field := range fields {
+		keys := strings.Split(field, ".")
+		node := root
+		for i, key := range keys {
+			if key == "" {
+				return nil, fmt.Errorf("invalid field '%s'", field)
+			}
+
+			if i == len(keys)-1 {
+				node[key] = nil
+				break
+			}
+
+			child, ok := node[key]
+			if ok && child == nil {
+				// The whole value of a parent is already parsed
+				break
+			}
+			if !ok {
+				child = projection{}
+				node[key] = child
+			}
+			node = child
+		}
+	}
+	return root, nil
+}
+
+// parse will parse the projected keys of a JSON object. The values of other keys are
+// skipped without being decoded, which is much cheaper than parsing the whole object.
+func (p projection) parse(api jsoniter.API, value string) (map[string]interface{}, error) {
+	iter := api.BorrowIterator([]byte(value))
+	defer api.ReturnIterator(iter)
+
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+
+	parsed := p.readObject(iter)
+	if iter.Error != nil {
+		return nil, iter.Error
+	}
+
+	iter.WhatIsNext()
+	if iter.Error != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON object")
+	}
+	return parsed, nil
+}
+
+// readObject will read the projected keys of the object at the position of the iterator. A key whose
+// projection has children is omitted if its value is not an object, or contains none of the children.
+func (p projection) readObject(iter *jsoniter.Iterator) map[string]interface{} {
+	parsed := map[string]interface{}{}
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, key string) bool {
+		child, ok := p[key]
+		switch {
+		case !ok:
+			iter.Skip()
+		case child == nil:
+			parsed[key] = iter.Read()
+		case iter.WhatIsNext() == jsoniter.ObjectValue:
+			if nested := child.readObject(iter); len(nested) > 0 {
+				parsed[key] = nested
+			}
+		default:
+			iter.Skip()
+		}
+		return iter.Error == nil
+	})
+	return parsed
+}
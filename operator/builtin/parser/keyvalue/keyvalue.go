@@ -0,0 +1,214 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyvalue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("key_value_parser", func() operator.Builder { return NewKVParserConfig("") })
+}
+
+// NewKVParserConfig creates a new key value parser config with default values
+func NewKVParserConfig(operatorID string) *KVParserConfig {
+	return &KVParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "key_value_parser"),
+		Delimiter:    "=",
+		Quotes:       `"'`,
+		Trim:         true,
+	}
+}
+
+// KVParserConfig is the configuration of a key value parser operator.
+type KVParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	Delimiter     string `mapstructure:"delimiter"                json:"delimiter"                yaml:"delimiter"`
+	PairDelimiter string `mapstructure:"pair_delimiter,omitempty" json:"pair_delimiter,omitempty" yaml:"pair_delimiter,omitempty"`
+	Quotes        string `mapstructure:"quotes"                   json:"quotes"                   yaml:"quotes"`
+	Trim          bool   `mapstructure:"trim"                     json:"trim"                     yaml:"trim"`
+}
+
+// Build will build a key value parser operator.
+func (c KVParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Delimiter == "" {
+		return nil, fmt.Errorf("missing required parameter 'delimiter'")
+	}
+
+	if c.Delimiter == c.PairDelimiter {
+		return nil, fmt.Errorf("delimiter and pair_delimiter must not be the same")
+	}
+
+	for _, quote := range c.Quotes {
+		if quote > unicode.MaxASCII {
+			return nil, fmt.Errorf("quote character '%c' must be an ASCII character", quote)
+		}
+		if strings.ContainsRune(c.Delimiter, quote) || strings.ContainsRune(c.PairDelimiter, quote) {
+			return nil, fmt.Errorf("quote character '%c' must not be part of a delimiter", quote)
+		}
+	}
+
+	kvParser := &KVParser{
+		ParserOperator: parserOperator,
+		delimiter:      c.Delimiter,
+		pairDelimiter:  c.PairDelimiter,
+		quotes:         c.Quotes,
+		trim:           c.Trim,
+	}
+
+	return []operator.Operator{kvParser}, nil
+}
+
+// KVParser is an operator that parses key value pairs.
+type KVParser struct {
+	helper.ParserOperator
+	delimiter     string
+	pairDelimiter string
+	quotes        string
+	trim          bool
+}
+
+// Process will parse an entry for key value pairs.
+func (kv *KVParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return kv.ParserOperator.ProcessWith(ctx, entry, kv.parse)
+}
+
+// parse will parse a value as key value pairs.
+func (kv *KVParser) parse(value interface{}) (interface{}, error) {
+	var raw string
+	switch m := value.(type) {
+	case string:
+		raw = m
+	case []byte:
+		raw = string(m)
+	default:
+		return nil, fmt.Errorf("type %T cannot be parsed as key value pairs", value)
+	}
+
+	parsedValues := map[string]interface{}{}
+	for _, pair := range kv.split(raw, kv.pairDelimiter) {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		parts := kv.splitN(pair, kv.delimiter, 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected delimiter '%s' in pair '%s'", kv.delimiter, pair)
+		}
+
+		key, err := kv.unquote(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			return nil, fmt.Errorf("missing key in pair '%s'", pair)
+		}
+
+		value, err := kv.unquote(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		parsedValues[key] = value
+	}
+
+	return parsedValues, nil
+}
+
+// split will split s around each instance of sep which is not quoted. If sep is empty,
+// s is split around each run of whitespace which is not quoted.
+func (kv *KVParser) split(s, sep string) []string {
+	return kv.splitN(s, sep, -1)
+}
+
+// splitN is like split, but returns at most n parts if n is positive.
+func (kv *KVParser) splitN(s, sep string, n int) []string {
+	var parts []string
+	var quote rune
+	start := 0
+	for i := 0; i < len(s); {
+		if n > 0 && len(parts) == n-1 {
+			break
+		}
+
+		r := rune(s[i])
+		switch {
+		case quote != 0:
+			if r == '\\' {
+				i += 2
+				continue
+			}
+			if r == quote {
+				quote = 0
+			}
+		case strings.ContainsRune(kv.quotes, r):
+			quote = r
+		case sep == "" && unicode.IsSpace(r):
+			parts = append(parts, s[start:i])
+			for i < len(s) && unicode.IsSpace(rune(s[i])) {
+				i++
+			}
+			start = i
+			continue
+		case sep != "" && strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			i += len(sep)
+			start = i
+			continue
+		}
+		i++
+	}
+	return append(parts, s[start:])
+}
+
+// unquote will trim a key or value, if configured, and remove the quotes around it.
+func (kv *KVParser) unquote(s string) (string, error) {
+	if kv.trim {
+		s = strings.TrimSpace(s)
+	}
+	if s == "" || !strings.ContainsRune(kv.quotes, rune(s[0])) {
+		return s, nil
+	}
+
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) && (s[i+1] == quote || s[i+1] == '\\') {
+				i++
+			}
+		case quote:
+			if i != len(s)-1 {
+				return "", fmt.Errorf("unexpected characters after quote in '%s'", s)
+			}
+			return b.String(), nil
+		}
+		b.WriteByte(s[i])
+	}
+	return "", fmt.Errorf("unterminated quote in '%s'", s)
+}
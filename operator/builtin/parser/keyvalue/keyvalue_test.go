@@ -0,0 +1,325 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyvalue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T, modify func(*KVParserConfig)) (*KVParser, *testutil.FakeOutput) {
+	cfg := NewKVParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	if modify != nil {
+		modify(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*KVParser)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fakeOutput}))
+	return parser, fakeOutput
+}
+
+func TestKVParserBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*KVParserConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *KVParserConfig) {},
+			false,
+		},
+		{
+			"NoQuotes",
+			func(cfg *KVParserConfig) {
+				cfg.Quotes = ""
+			},
+			false,
+		},
+		{
+			"MissingDelimiter",
+			func(cfg *KVParserConfig) {
+				cfg.Delimiter = ""
+			},
+			true,
+		},
+		{
+			"SameDelimiters",
+			func(cfg *KVParserConfig) {
+				cfg.PairDelimiter = "="
+			},
+			true,
+		},
+		{
+			"QuoteInDelimiter",
+			func(cfg *KVParserConfig) {
+				cfg.PairDelimiter = `"`
+			},
+			true,
+		},
+		{
+			"NonASCIIQuote",
+			func(cfg *KVParserConfig) {
+				cfg.Quotes = "«"
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewKVParserConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestKVParserParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*KVParserConfig)
+		input    interface{}
+		expected map[string]interface{}
+	}{
+		{
+			"Empty",
+			nil,
+			"",
+			map[string]interface{}{},
+		},
+		{
+			"Simple",
+			nil,
+			"action=allow src=10.0.0.1 dst=10.0.0.2",
+			map[string]interface{}{
+				"action": "allow",
+				"src":    "10.0.0.1",
+				"dst":    "10.0.0.2",
+			},
+		},
+		{
+			"Bytes",
+			nil,
+			[]byte("action=deny"),
+			map[string]interface{}{
+				"action": "deny",
+			},
+		},
+		{
+			"ExtraWhitespace",
+			nil,
+			"  a=1 \t b=2  ",
+			map[string]interface{}{
+				"a": "1",
+				"b": "2",
+			},
+		},
+		{
+			"Quoted",
+			nil,
+			`msg="connection reset by peer" user='jane doe' rule="allow=all"`,
+			map[string]interface{}{
+				"msg":  "connection reset by peer",
+				"user": "jane doe",
+				"rule": "allow=all",
+			},
+		},
+		{
+			"EscapedQuotes",
+			nil,
+			`msg="say \"hi\"" path="C:\\temp"`,
+			map[string]interface{}{
+				"msg":  `say "hi"`,
+				"path": `C:\temp`,
+			},
+		},
+		{
+			"QuotedKey",
+			nil,
+			`"user name"=jane`,
+			map[string]interface{}{
+				"user name": "jane",
+			},
+		},
+		{
+			"EmptyValue",
+			nil,
+			`a= b="" c=3`,
+			map[string]interface{}{
+				"a": "",
+				"b": "",
+				"c": "3",
+			},
+		},
+		{
+			"DelimiterInValue",
+			nil,
+			"url=/search?q=a",
+			map[string]interface{}{
+				"url": "/search?q=a",
+			},
+		},
+		{
+			"CommaPairDelimiter",
+			func(cfg *KVParserConfig) {
+				cfg.PairDelimiter = ","
+			},
+			`src = 10.0.0.1, msg = "a, b", action = drop,`,
+			map[string]interface{}{
+				"src":    "10.0.0.1",
+				"msg":    "a, b",
+				"action": "drop",
+			},
+		},
+		{
+			"SemicolonAndColon",
+			func(cfg *KVParserConfig) {
+				cfg.Delimiter = ":"
+				cfg.PairDelimiter = ";"
+			},
+			"user:root;cmd:ls -la;;tty:pts/0",
+			map[string]interface{}{
+				"user": "root",
+				"cmd":  "ls -la",
+				"tty":  "pts/0",
+			},
+		},
+		{
+			"MultiCharacterDelimiters",
+			func(cfg *KVParserConfig) {
+				cfg.Delimiter = "=>"
+				cfg.PairDelimiter = " | "
+			},
+			"a=>1 | b=>x=y | c=>3",
+			map[string]interface{}{
+				"a": "1",
+				"b": "x=y",
+				"c": "3",
+			},
+		},
+		{
+			"NoTrim",
+			func(cfg *KVParserConfig) {
+				cfg.PairDelimiter = ","
+				cfg.Trim = false
+			},
+			"a= 1 ,b =2",
+			map[string]interface{}{
+				"a":  " 1 ",
+				"b ": "2",
+			},
+		},
+		{
+			"NoQuotes",
+			func(cfg *KVParserConfig) {
+				cfg.Quotes = ""
+				cfg.PairDelimiter = ";"
+			},
+			`msg="quoted";a='b'`,
+			map[string]interface{}{
+				"msg": `"quoted"`,
+				"a":   `'b'`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, tc.modify)
+			actual, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestKVParserParseErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{"InvalidType", []int{}, "type []int cannot be parsed as key value pairs"},
+		{"MissingDelimiter", "a=1 b", "expected delimiter '=' in pair 'b'"},
+		{"MissingKey", "=1", "missing key in pair '=1'"},
+		{"UnterminatedQuote", `msg="never ends`, "unterminated quote"},
+		{"TextAfterQuote", `msg="quoted"suffix`, "unexpected characters after quote"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, nil)
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestKVParserProcess(t *testing.T) {
+	parser, fakeOutput := newTestParser(t, func(cfg *KVParserConfig) {
+		preserveTo := entry.NewBodyField("original")
+		cfg.PreserveTo = &preserveTo
+
+		timeField := entry.NewBodyField("time")
+		timeParser := helper.NewTimeParser()
+		timeParser.ParseFrom = &timeField
+		timeParser.LayoutType = helper.GotimeKey
+		timeParser.Layout = time.RFC3339
+		cfg.TimeParser = &timeParser
+
+		severityField := entry.NewBodyField("level")
+		severityParser := helper.NewSeverityParserConfig()
+		severityParser.ParseFrom = &severityField
+		cfg.SeverityParserConfig = &severityParser
+	})
+
+	line := `time=2021-06-01T12:00:00Z level=info action=allow msg="accepted connection"`
+	e := entry.New()
+	e.Body = line
+	require.NoError(t, parser.Process(context.Background(), e))
+
+	select {
+	case out := <-fakeOutput.Received:
+		require.Equal(t, map[string]interface{}{
+			"action":   "allow",
+			"msg":      "accepted connection",
+			"original": line,
+		}, out.Body)
+		require.Equal(t, time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC), out.Timestamp.UTC())
+		require.Equal(t, entry.Info, out.Severity)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
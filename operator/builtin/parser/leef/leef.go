@@ -0,0 +1,183 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leef
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// headerFields are the names of the fields common to the headers of all LEEF versions, in order.
+var headerFields = []string{"version", "device_vendor", "device_product", "device_version", "event_id"}
+
+// defaultDelimiter is the delimiter of attributes in LEEF 1.0, and in LEEF 2.0 when none is declared.
+const defaultDelimiter = "\t"
+
+func init() {
+	operator.Register("leef_parser", func() operator.Builder { return NewLEEFParserConfig("") })
+}
+
+// NewLEEFParserConfig creates a new LEEF parser config with default values
+func NewLEEFParserConfig(operatorID string) *LEEFParserConfig {
+	return &LEEFParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "leef_parser"),
+	}
+}
+
+// LEEFParserConfig is the configuration of a LEEF parser operator.
+type LEEFParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+}
+
+// Build will build a LEEF parser operator.
+func (c LEEFParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	leefParser := &LEEFParser{
+		ParserOperator: parserOperator,
+	}
+
+	return []operator.Operator{leefParser}, nil
+}
+
+// LEEFParser is an operator that parses LEEF.
+type LEEFParser struct {
+	helper.ParserOperator
+}
+
+// Process will parse an entry field as LEEF.
+func (p *LEEFParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ParserOperator.ProcessWith(ctx, entry, p.parse)
+}
+
+// parse will parse a value as LEEF. Any text before the LEEF prefix, such as a syslog header, is ignored.
+func (p *LEEFParser) parse(value interface{}) (interface{}, error) {
+	var raw string
+	switch m := value.(type) {
+	case string:
+		raw = m
+	case []byte:
+		raw = string(m)
+	default:
+		return nil, fmt.Errorf("type %T cannot be parsed as LEEF", value)
+	}
+
+	start := strings.Index(raw, "LEEF:")
+	if start < 0 {
+		return nil, fmt.Errorf("missing LEEF prefix")
+	}
+	raw = raw[start+len("LEEF:"):]
+
+	header := strings.SplitN(raw, "|", len(headerFields)+1)
+	if len(header) <= len(headerFields) {
+		return nil, fmt.Errorf("invalid LEEF header: expected %d fields terminated by '|', found %d", len(headerFields), len(header)-1)
+	}
+	attributes := header[len(headerFields)]
+
+	delimiter := defaultDelimiter
+	switch {
+	case strings.HasPrefix(header[0], "1."):
+	case strings.HasPrefix(header[0], "2."):
+		// LEEF 2.0 declares the delimiter of its attributes in an additional header field
+		i := strings.IndexByte(attributes, '|')
+		if i < 0 {
+			return nil, fmt.Errorf("invalid LEEF header: missing delimiter field of version %s", header[0])
+		}
+
+		var err error
+		delimiter, err = parseDelimiter(attributes[:i])
+		if err != nil {
+			return nil, err
+		}
+		attributes = attributes[i+1:]
+	default:
+		return nil, fmt.Errorf("unsupported LEEF version '%s'", header[0])
+	}
+
+	parsedValues := make(map[string]interface{}, len(headerFields)+1)
+	for i, field := range headerFields {
+		parsedValues[field] = header[i]
+	}
+
+	parsedAttributes, err := parseAttributes(attributes, delimiter)
+	if err != nil {
+		return nil, err
+	}
+	parsedValues["attributes"] = parsedAttributes
+
+	return parsedValues, nil
+}
+
+// parseDelimiter will parse the delimiter field of a LEEF 2.0 header. The delimiter is either
+// a single character, or its hex code point prefixed by "x" or "0x". If empty, the default is used.
+func parseDelimiter(field string) (string, error) {
+	if field == "" {
+		return defaultDelimiter, nil
+	}
+
+	if utf8.RuneCountInString(field) == 1 {
+		return field, nil
+	}
+
+	lower := strings.ToLower(field)
+	var hex string
+	switch {
+	case strings.HasPrefix(lower, "0x"):
+		hex = lower[2:]
+	case strings.HasPrefix(lower, "x"):
+		hex = lower[1:]
+	default:
+		return "", fmt.Errorf("invalid LEEF delimiter '%s'", field)
+	}
+
+	codePoint, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(codePoint)) {
+		return "", fmt.Errorf("invalid LEEF delimiter '%s'", field)
+	}
+	return string(rune(codePoint)), nil
+}
+
+// parseAttributes will parse the key value pairs of the attributes of a LEEF event. A value
+// may contain an equals sign, as only the first of a pair separates its key from its value.
+func parseAttributes(attributes, delimiter string) (map[string]interface{}, error) {
+	parsedAttributes := map[string]interface{}{}
+	for _, pair := range strings.Split(strings.TrimRight(attributes, "\r\n"), delimiter) {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		i := strings.IndexByte(pair, '=')
+		if i < 0 {
+			return nil, fmt.Errorf("invalid LEEF attribute '%s': missing '='", pair)
+		}
+
+		key := strings.TrimSpace(pair[:i])
+		if key == "" {
+			return nil, fmt.Errorf("invalid LEEF attribute '%s': missing key", pair)
+		}
+		parsedAttributes[key] = pair[i+1:]
+	}
+	return parsedAttributes, nil
+}
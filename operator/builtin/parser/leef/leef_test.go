@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leef
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T) (*LEEFParser, *testutil.FakeOutput) {
+	cfg := NewLEEFParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*LEEFParser)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fakeOutput}))
+	return parser, fakeOutput
+}
+
+func TestLEEFParserParse(t *testing.T) {
+	header := func(version string) map[string]interface{} {
+		return map[string]interface{}{
+			"version":        version,
+			"device_vendor":  "IBM",
+			"device_product": "QRadar",
+			"device_version": "7.4",
+			"event_id":       "42",
+		}
+	}
+	withAttributes := func(values map[string]interface{}, attributes map[string]interface{}) map[string]interface{} {
+		values["attributes"] = attributes
+		return values
+	}
+
+	cases := []struct {
+		name     string
+		input    interface{}
+		expected map[string]interface{}
+	}{
+		{
+			"Version1",
+			"LEEF:1.0|IBM|QRadar|7.4|42|src=10.0.0.1\tdst=10.0.0.2\tusrName=jane doe",
+			withAttributes(header("1.0"), map[string]interface{}{
+				"src":     "10.0.0.1",
+				"dst":     "10.0.0.2",
+				"usrName": "jane doe",
+			}),
+		},
+		{
+			"Bytes",
+			[]byte("LEEF:1.0|IBM|QRadar|7.4|42|src=10.0.0.1"),
+			withAttributes(header("1.0"), map[string]interface{}{
+				"src": "10.0.0.1",
+			}),
+		},
+		{
+			"SyslogPrefix",
+			"Jan 18 11:07:53 host LEEF:1.0|IBM|QRadar|7.4|42|src=10.0.0.1\n",
+			withAttributes(header("1.0"), map[string]interface{}{
+				"src": "10.0.0.1",
+			}),
+		},
+		{
+			"NoAttributes",
+			"LEEF:1.0|IBM|QRadar|7.4|42|",
+			withAttributes(header("1.0"), map[string]interface{}{}),
+		},
+		{
+			"EqualsInValue",
+			"LEEF:1.0|IBM|QRadar|7.4|42|url=https://example.com/?a=b\t\tsrc=10.0.0.1\t",
+			withAttributes(header("1.0"), map[string]interface{}{
+				"url": "https://example.com/?a=b",
+				"src": "10.0.0.1",
+			}),
+		},
+		{
+			"Version2Character",
+			"LEEF:2.0|IBM|QRadar|7.4|42|^|src=10.0.0.1^dst=10.0.0.2^msg=a\tb",
+			withAttributes(header("2.0"), map[string]interface{}{
+				"src": "10.0.0.1",
+				"dst": "10.0.0.2",
+				"msg": "a\tb",
+			}),
+		},
+		{
+			"Version2Hex",
+			"LEEF:2.0|IBM|QRadar|7.4|42|x5E|src=10.0.0.1^dst=10.0.0.2",
+			withAttributes(header("2.0"), map[string]interface{}{
+				"src": "10.0.0.1",
+				"dst": "10.0.0.2",
+			}),
+		},
+		{
+			"Version2PrefixedHex",
+			"LEEF:2.0|IBM|QRadar|7.4|42|0x7C|src=10.0.0.1|dst=10.0.0.2",
+			withAttributes(header("2.0"), map[string]interface{}{
+				"src": "10.0.0.1",
+				"dst": "10.0.0.2",
+			}),
+		},
+		{
+			"Version2DefaultDelimiter",
+			"LEEF:2.0|IBM|QRadar|7.4|42||src=10.0.0.1\tdst=10.0.0.2",
+			withAttributes(header("2.0"), map[string]interface{}{
+				"src": "10.0.0.1",
+				"dst": "10.0.0.2",
+			}),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t)
+			actual, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestLEEFParserParseErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{"InvalidType", []int{}, "type []int cannot be parsed as LEEF"},
+		{"MissingPrefix", "1.0|IBM|QRadar|7.4|42|src=10.0.0.1", "missing LEEF prefix"},
+		{"ShortHeader", "LEEF:1.0|IBM|QRadar|7.4", "expected 5 fields terminated by '|', found 3"},
+		{"UnsupportedVersion", "LEEF:3.0|IBM|QRadar|7.4|42|src=10.0.0.1", "unsupported LEEF version '3.0'"},
+		{"MissingDelimiterField", "LEEF:2.0|IBM|QRadar|7.4|42|src=10.0.0.1", "missing delimiter field"},
+		{"InvalidDelimiter", "LEEF:2.0|IBM|QRadar|7.4|42|ab|src=10.0.0.1", "invalid LEEF delimiter 'ab'"},
+		{"InvalidHexDelimiter", "LEEF:2.0|IBM|QRadar|7.4|42|xZZ|src=10.0.0.1", "invalid LEEF delimiter 'xZZ'"},
+		{"MissingEquals", "LEEF:1.0|IBM|QRadar|7.4|42|src", "missing '='"},
+		{"MissingKey", "LEEF:1.0|IBM|QRadar|7.4|42|=10.0.0.1", "missing key"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t)
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestLEEFParserProcess(t *testing.T) {
+	parser, fakeOutput := newTestParser(t)
+
+	e := entry.New()
+	e.Body = "LEEF:2.0|IBM|QRadar|7.4|42|^|src=10.0.0.1^sev=5"
+	require.NoError(t, parser.Process(context.Background(), e))
+
+	select {
+	case out := <-fakeOutput.Received:
+		expected := map[string]interface{}{
+			"version":        "2.0",
+			"device_vendor":  "IBM",
+			"device_product": "QRadar",
+			"device_version": "7.4",
+			"event_id":       "42",
+			"attributes": map[string]interface{}{
+				"src": "10.0.0.1",
+				"sev": "5",
+			},
+		}
+		require.Equal(t, expected, out.Body)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
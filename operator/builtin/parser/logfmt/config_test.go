@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfmt
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestLogfmtParserConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "parse_from_simple",
+			Expect: func() *LogfmtParserConfig {
+				cfg := defaultCfg()
+				cfg.ParseFrom = entry.NewBodyField("from")
+				return cfg
+			}(),
+		},
+		{
+			Name: "parse_to_simple",
+			Expect: func() *LogfmtParserConfig {
+				cfg := defaultCfg()
+				cfg.ParseTo = entry.NewBodyField("log")
+				return cfg
+			}(),
+		},
+		{
+			Name: "duplicate_keys",
+			Expect: func() *LogfmtParserConfig {
+				cfg := defaultCfg()
+				cfg.DuplicateKeys = DuplicateKeysArray
+				return cfg
+			}(),
+		},
+		{
+			Name: "timestamp",
+			Expect: func() *LogfmtParserConfig {
+				cfg := defaultCfg()
+				parseField := entry.NewBodyField("ts")
+				newTime := helper.TimeParser{
+					LayoutType: "gotime",
+					Layout:     "2006-01-02T15:04:05Z07:00",
+					ParseFrom:  &parseField,
+				}
+				cfg.TimeParser = &newTime
+				return cfg
+			}(),
+		},
+		{
+			Name: "severity",
+			Expect: func() *LogfmtParserConfig {
+				cfg := defaultCfg()
+				parseField := entry.NewBodyField("level")
+				severityField := helper.NewSeverityParserConfig()
+				severityField.ParseFrom = &parseField
+				cfg.SeverityParserConfig = &severityField
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *LogfmtParserConfig {
+	return NewLogfmtParserConfig("logfmt_parser")
+}
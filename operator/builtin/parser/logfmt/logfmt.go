@@ -0,0 +1,202 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfmt
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// DuplicateKeysLast keeps the last value of a key which appears more than once
+	DuplicateKeysLast = "last"
+
+	// DuplicateKeysFirst keeps the first value of a key which appears more than once
+	DuplicateKeysFirst = "first"
+
+	// DuplicateKeysArray collects the values of a key which appears more than once into an array
+	DuplicateKeysArray = "array"
+)
+
+func init() {
+	operator.Register("logfmt_parser", func() operator.Builder { return NewLogfmtParserConfig("") })
+}
+
+// NewLogfmtParserConfig creates a new logfmt parser config with default values
+func NewLogfmtParserConfig(operatorID string) *LogfmtParserConfig {
+	return &LogfmtParserConfig{
+		ParserConfig:  helper.NewParserConfig(operatorID, "logfmt_parser"),
+		DuplicateKeys: DuplicateKeysLast,
+	}
+}
+
+// LogfmtParserConfig is the configuration of a logfmt parser operator.
+type LogfmtParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	DuplicateKeys string `mapstructure:"duplicate_keys" json:"duplicate_keys" yaml:"duplicate_keys"`
+}
+
+// Build will build a logfmt parser operator.
+func (c LogfmtParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.DuplicateKeys {
+	case DuplicateKeysLast, DuplicateKeysFirst, DuplicateKeysArray:
+	default:
+		return nil, fmt.Errorf("invalid duplicate_keys '%s'", c.DuplicateKeys)
+	}
+
+	logfmtParser := &LogfmtParser{
+		ParserOperator: parserOperator,
+		duplicateKeys:  c.DuplicateKeys,
+	}
+
+	return []operator.Operator{logfmtParser}, nil
+}
+
+// LogfmtParser is an operator that parses logfmt.
+type LogfmtParser struct {
+	helper.ParserOperator
+	duplicateKeys string
+}
+
+// Process will parse an entry for logfmt.
+func (p *LogfmtParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ParserOperator.ProcessWith(ctx, entry, p.parse)
+}
+
+// parse will parse a value as logfmt.
+func (p *LogfmtParser) parse(value interface{}) (interface{}, error) {
+	var raw string
+	switch m := value.(type) {
+	case string:
+		raw = m
+	case []byte:
+		raw = string(m)
+	default:
+		return nil, fmt.Errorf("type %T cannot be parsed as logfmt", value)
+	}
+
+	parsedValues := map[string]interface{}{}
+	err := scanPairs(raw, func(key string, value interface{}) {
+		existing, ok := parsedValues[key]
+		switch {
+		case !ok:
+			parsedValues[key] = value
+		case p.duplicateKeys == DuplicateKeysLast:
+			parsedValues[key] = value
+		case p.duplicateKeys == DuplicateKeysArray:
+			if values, ok := existing.([]interface{}); ok {
+				parsedValues[key] = append(values, value)
+			} else {
+				parsedValues[key] = []interface{}{existing, value}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parsedValues, nil
+}
+
+// scanPairs will call fn with each key and value of a logfmt line. A key without a value,
+// such as `debug`, has the value true, and a key followed by an equals sign, such as `debug=`,
+// has an empty value.
+func scanPairs(line string, fn func(key string, value interface{})) error {
+	for i := 0; ; {
+		for i < len(line) && isSpace(line[i]) {
+			i++
+		}
+		if i == len(line) {
+			return nil
+		}
+
+		start := i
+		for i < len(line) && isKeyChar(line[i]) {
+			i++
+		}
+		if i == start {
+			return fmt.Errorf("unexpected character '%c' at position %d, expected a key", line[i], i)
+		}
+		key := line[start:i]
+
+		if i == len(line) || isSpace(line[i]) {
+			fn(key, true)
+			continue
+		}
+		if line[i] != '=' {
+			return fmt.Errorf("unexpected character '%c' at position %d, expected '=' after key '%s'", line[i], i, key)
+		}
+		i++
+
+		if i < len(line) && line[i] == '"' {
+			end, err := quotedEnd(line, i)
+			if err != nil {
+				return err
+			}
+
+			value, err := strconv.Unquote(line[i:end])
+			if err != nil {
+				return fmt.Errorf("invalid quoted value for key '%s': %s", key, err)
+			}
+			i = end
+
+			if i < len(line) && !isSpace(line[i]) {
+				return fmt.Errorf("unexpected character '%c' at position %d, expected a space after the quoted value of key '%s'", line[i], i, key)
+			}
+			fn(key, value)
+			continue
+		}
+
+		start = i
+		for i < len(line) && !isSpace(line[i]) {
+			if line[i] == '"' {
+				return fmt.Errorf("unexpected quote at position %d in the value of key '%s'", i, key)
+			}
+			i++
+		}
+		fn(key, line[start:i])
+	}
+}
+
+// quotedEnd will return the position following the closing quote of the quoted value which begins at start.
+func quotedEnd(line string, start int) (int, error) {
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated quoted value starting at position %d", start)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func isKeyChar(c byte) bool {
+	return c > ' ' && c != '=' && c != '"' && c != 0x7f
+}
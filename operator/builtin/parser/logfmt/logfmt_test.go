@@ -0,0 +1,261 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfmt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T, modify func(*LogfmtParserConfig)) (*LogfmtParser, *testutil.FakeOutput) {
+	cfg := NewLogfmtParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	if modify != nil {
+		modify(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*LogfmtParser)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fakeOutput}))
+	return parser, fakeOutput
+}
+
+func TestLogfmtParserConfigBuild(t *testing.T) {
+	cfg := NewLogfmtParserConfig("test")
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	require.IsType(t, &LogfmtParser{}, ops[0])
+}
+
+func TestLogfmtParserConfigBuildFailure(t *testing.T) {
+	cfg := NewLogfmtParserConfig("test")
+	cfg.DuplicateKeys = "merge"
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid duplicate_keys")
+}
+
+func TestLogfmtParserInvalidType(t *testing.T) {
+	parser, _ := newTestParser(t, nil)
+	_, err := parser.parse([]int{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "type []int cannot be parsed as logfmt")
+}
+
+func TestLogfmtParserParse(t *testing.T) {
+	cases := []struct {
+		name          string
+		input         interface{}
+		duplicateKeys string
+		expected      map[string]interface{}
+	}{
+		{
+			"Empty",
+			"",
+			DuplicateKeysLast,
+			map[string]interface{}{},
+		},
+		{
+			"Simple",
+			"ts=2021-06-01T12:00:00Z level=info msg=started",
+			DuplicateKeysLast,
+			map[string]interface{}{
+				"ts":    "2021-06-01T12:00:00Z",
+				"level": "info",
+				"msg":   "started",
+			},
+		},
+		{
+			"Bytes",
+			[]byte("level=info"),
+			DuplicateKeysLast,
+			map[string]interface{}{
+				"level": "info",
+			},
+		},
+		{
+			"Quoted",
+			`level=info msg="request completed" path=/api`,
+			DuplicateKeysLast,
+			map[string]interface{}{
+				"level": "info",
+				"msg":   "request completed",
+				"path":  "/api",
+			},
+		},
+		{
+			"EscapedQuotes",
+			`msg="user \"admin\" logged in\nagain" file="C:\\logs"`,
+			DuplicateKeysLast,
+			map[string]interface{}{
+				"msg":  "user \"admin\" logged in\nagain",
+				"file": `C:\logs`,
+			},
+		},
+		{
+			"QuotedEquals",
+			`query="a=b c=d"`,
+			DuplicateKeysLast,
+			map[string]interface{}{
+				"query": "a=b c=d",
+			},
+		},
+		{
+			"EmptyValues",
+			`a= b="" c=1`,
+			DuplicateKeysLast,
+			map[string]interface{}{
+				"a": "",
+				"b": "",
+				"c": "1",
+			},
+		},
+		{
+			"BareKeys",
+			"debug level=warn cached",
+			DuplicateKeysLast,
+			map[string]interface{}{
+				"debug":  true,
+				"level":  "warn",
+				"cached": true,
+			},
+		},
+		{
+			"UnquotedEquals",
+			"expr=a=b",
+			DuplicateKeysLast,
+			map[string]interface{}{
+				"expr": "a=b",
+			},
+		},
+		{
+			"ExtraWhitespace",
+			"  a=1 \t b=2  \n",
+			DuplicateKeysLast,
+			map[string]interface{}{
+				"a": "1",
+				"b": "2",
+			},
+		},
+		{
+			"DuplicateKeysLast",
+			"tag=a tag=b tag=c",
+			DuplicateKeysLast,
+			map[string]interface{}{
+				"tag": "c",
+			},
+		},
+		{
+			"DuplicateKeysFirst",
+			"tag=a tag=b tag=c",
+			DuplicateKeysFirst,
+			map[string]interface{}{
+				"tag": "a",
+			},
+		},
+		{
+			"DuplicateKeysArray",
+			"tag=a other=x tag=b tag=c",
+			DuplicateKeysArray,
+			map[string]interface{}{
+				"tag":   []interface{}{"a", "b", "c"},
+				"other": "x",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, func(cfg *LogfmtParserConfig) {
+				cfg.DuplicateKeys = tc.duplicateKeys
+			})
+			actual, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestLogfmtParserParseErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"MissingKey", "=value", "expected a key"},
+		{"UnterminatedQuote", `msg="never ends`, "unterminated quoted value"},
+		{"TextAfterQuote", `msg="quoted"suffix`, "expected a space after the quoted value of key 'msg'"},
+		{"QuoteInValue", `msg=un"quoted`, "unexpected quote"},
+		{"QuoteInKey", `k"ey=value`, "expected '=' after key 'k'"},
+		{"InvalidEscape", `msg="\q"`, "invalid quoted value for key 'msg'"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, nil)
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestLogfmtParserProcess(t *testing.T) {
+	parser, fakeOutput := newTestParser(t, func(cfg *LogfmtParserConfig) {
+		preserveTo := entry.NewBodyField("original")
+		cfg.PreserveTo = &preserveTo
+
+		timeField := entry.NewBodyField("ts")
+		timeParser := helper.NewTimeParser()
+		timeParser.ParseFrom = &timeField
+		timeParser.LayoutType = helper.GotimeKey
+		timeParser.Layout = time.RFC3339
+		cfg.TimeParser = &timeParser
+
+		severityField := entry.NewBodyField("level")
+		severityParser := helper.NewSeverityParserConfig()
+		severityParser.ParseFrom = &severityField
+		cfg.SeverityParserConfig = &severityParser
+	})
+
+	line := `ts=2021-06-01T12:00:00Z level=error msg="connection \"db\" lost" retry`
+	e := entry.New()
+	e.Body = line
+	require.NoError(t, parser.Process(context.Background(), e))
+
+	select {
+	case out := <-fakeOutput.Received:
+		require.Equal(t, map[string]interface{}{
+			"msg":      `connection "db" lost`,
+			"retry":    true,
+			"original": line,
+		}, out.Body)
+		require.Equal(t, time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC), out.Timestamp.UTC())
+		require.Equal(t, entry.Error, out.Severity)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalize
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// DurationType converts a duration to a number of seconds
+	DurationType = "duration"
+
+	// BytesType converts a size to a number of bytes
+	BytesType = "bytes"
+
+	// BooleanType converts a value to a boolean
+	BooleanType = "boolean"
+)
+
+// converters are the conversion functions of each type.
+var converters = map[string]func(interface{}) (interface{}, error){
+	DurationType: toSeconds,
+	BytesType:    toBytes,
+	BooleanType:  toBoolean,
+}
+
+// toSeconds will convert a duration, such as "1.5s" or "1h30m", to a float64 number of seconds.
+// A number is assumed to already be a number of seconds.
+func toSeconds(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		s := strings.TrimSpace(v)
+		if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+			return seconds, nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration '%s'", v)
+		}
+		return d.Seconds(), nil
+	case time.Duration:
+		return v.Seconds(), nil
+	default:
+		if n, ok := helper.ToFloat64(value); ok {
+			return n, nil
+		}
+		return nil, fmt.Errorf("type '%T' cannot be converted to a duration", value)
+	}
+}
+
+// byteSizePattern matches a size, capturing its number and unit
+var byteSizePattern = regexp.MustCompile(`^([0-9]*\.?[0-9]+)\s*([a-zA-Z]*)$`)
+
+// byteUnits are the multipliers of the units of a size. Units are matched case insensitively.
+var byteUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"tib": 1 << 40,
+	"p":   1000 * 1000 * 1000 * 1000 * 1000,
+	"pb":  1000 * 1000 * 1000 * 1000 * 1000,
+	"pib": 1 << 50,
+}
+
+// toBytes will convert a size, such as "2048KB" or "1.5GiB", to an int64 number of bytes.
+// A number is assumed to already be a number of bytes.
+func toBytes(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		s := strings.TrimSpace(v)
+		if bytes, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return bytes, nil
+		}
+
+		matches := byteSizePattern.FindStringSubmatch(s)
+		if matches == nil {
+			return nil, fmt.Errorf("invalid byte size '%s'", v)
+		}
+		multiplier, ok := byteUnits[strings.ToLower(matches[2])]
+		if !ok {
+			return nil, fmt.Errorf("invalid unit '%s' of byte size '%s'", matches[2], v)
+		}
+		numeral, err := strconv.ParseFloat(matches[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid byte size '%s'", v)
+		}
+
+		bytes := math.Round(numeral * multiplier)
+		if bytes >= math.MaxInt64 {
+			return nil, fmt.Errorf("byte size '%s' is too large", v)
+		}
+		return int64(bytes), nil
+	default:
+		n, ok := helper.ToFloat64(value)
+		if !ok {
+			return nil, fmt.Errorf("type '%T' cannot be converted to a byte size", value)
+		}
+		if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+			return nil, fmt.Errorf("invalid byte size %v", value)
+		}
+		return int64(n), nil
+	}
+}
+
+// toBoolean will convert a value, such as "yes", "off" or "true", to a boolean.
+func toBoolean(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "t", "yes", "y", "on", "1", "enabled":
+			return true, nil
+		case "false", "f", "no", "n", "off", "0", "disabled":
+			return false, nil
+		default:
+			return nil, fmt.Errorf("invalid boolean '%s'", v)
+		}
+	default:
+		n, ok := helper.ToFloat64(value)
+		if !ok {
+			return nil, fmt.Errorf("type '%T' cannot be converted to a boolean", value)
+		}
+		switch n {
+		case 1:
+			return true, nil
+		case 0:
+			return false, nil
+		default:
+			return nil, fmt.Errorf("invalid boolean %v", value)
+		}
+	}
+}
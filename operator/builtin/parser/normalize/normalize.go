@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalize
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("normalize_parser", func() operator.Builder { return NewNormalizeParserConfig("") })
+}
+
+// NewNormalizeParserConfig creates a new normalize parser config with default values
+func NewNormalizeParserConfig(operatorID string) *NormalizeParserConfig {
+	return &NormalizeParserConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "normalize_parser"),
+	}
+}
+
+// NormalizeParserConfig is the configuration of a normalize parser operator.
+type NormalizeParserConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+	Fields                   []FieldConfig `mapstructure:"fields" json:"fields" yaml:"fields"`
+}
+
+// FieldConfig is the configuration of a field to normalize.
+type FieldConfig struct {
+	Field entry.Field `mapstructure:"field" json:"field" yaml:"field"`
+	Type  string      `mapstructure:"type"  json:"type"  yaml:"type"`
+}
+
+// Build will build a normalize parser operator.
+func (c NormalizeParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Fields) == 0 {
+		return nil, fmt.Errorf("missing required field 'fields'")
+	}
+
+	normalizers := make([]normalizer, 0, len(c.Fields))
+	for i, f := range c.Fields {
+		if f.Field.FieldInterface == nil {
+			return nil, fmt.Errorf("missing field of fields[%d]", i)
+		}
+		// Attributes and resource values are strings, so only body fields can hold converted values
+		if _, ok := f.Field.FieldInterface.(entry.BodyField); !ok {
+			return nil, fmt.Errorf("field %s must be a body field", f.Field)
+		}
+		convert, ok := converters[f.Type]
+		if !ok {
+			return nil, fmt.Errorf("invalid type '%s' of field %s, must be one of %s, %s or %s", f.Type, f.Field, DurationType, BytesType, BooleanType)
+		}
+		normalizers = append(normalizers, normalizer{field: f.Field, convert: convert})
+	}
+
+	normalizeParser := &NormalizeParser{
+		TransformerOperator: transformerOperator,
+		normalizers:         normalizers,
+	}
+
+	return []operator.Operator{normalizeParser}, nil
+}
+
+// NormalizeParser is an operator that converts fields to canonical units and types.
+type NormalizeParser struct {
+	helper.TransformerOperator
+	normalizers []normalizer
+}
+
+// normalizer converts the value of a field.
+type normalizer struct {
+	field   entry.Field
+	convert func(interface{}) (interface{}, error)
+}
+
+// CanOutput will always return true for a parser operator.
+func (p *NormalizeParser) CanOutput() bool {
+	return true
+}
+
+// Process will normalize the fields of an entry.
+func (p *NormalizeParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ProcessWith(ctx, entry, p.normalize)
+}
+
+// normalize will convert the fields of an entry. Fields which do not exist are skipped. If any
+// field cannot be converted, the entry is not modified.
+func (p *NormalizeParser) normalize(e *entry.Entry) error {
+	converted := make([]interface{}, len(p.normalizers))
+	for i, n := range p.normalizers {
+		value, ok := e.Get(n.field)
+		if !ok {
+			continue
+		}
+		c, err := n.convert(value)
+		if err != nil {
+			return fmt.Errorf("normalize field %s: %s", n.field, err)
+		}
+		converted[i] = c
+	}
+
+	for i, n := range p.normalizers {
+		if converted[i] == nil {
+			continue
+		}
+		if err := e.Set(n.field, converted[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
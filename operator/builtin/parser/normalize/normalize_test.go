@@ -0,0 +1,203 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalize
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T, fields []FieldConfig) (*NormalizeParser, *testutil.FakeOutput) {
+	cfg := NewNormalizeParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Fields = fields
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*NormalizeParser)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fakeOutput}))
+	return parser, fakeOutput
+}
+
+func TestNormalizeParserBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		fields      []FieldConfig
+		expectedErr string
+	}{
+		{
+			"Valid",
+			[]FieldConfig{{Field: entry.NewBodyField("duration"), Type: DurationType}},
+			"",
+		},
+		{
+			"MissingFields",
+			nil,
+			"missing required field 'fields'",
+		},
+		{
+			"MissingField",
+			[]FieldConfig{{Type: DurationType}},
+			"missing field of fields[0]",
+		},
+		{
+			"InvalidType",
+			[]FieldConfig{{Field: entry.NewBodyField("duration"), Type: "time"}},
+			"invalid type 'time'",
+		},
+		{
+			"AttributeField",
+			[]FieldConfig{{Field: entry.NewAttributeField("size"), Type: BytesType}},
+			"must be a body field",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewNormalizeParserConfig("test")
+			cfg.Fields = tc.fields
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		name        string
+		typ         string
+		input       interface{}
+		expected    interface{}
+		expectedErr string
+	}{
+		{"DurationSeconds", DurationType, "1.5s", 1.5, ""},
+		{"DurationMilliseconds", DurationType, "250ms", 0.25, ""},
+		{"DurationCompound", DurationType, "1h30m", 5400.0, ""},
+		{"DurationNumericString", DurationType, "2.5", 2.5, ""},
+		{"DurationInt", DurationType, 3, 3.0, ""},
+		{"DurationFloat", DurationType, 0.5, 0.5, ""},
+		{"DurationNative", DurationType, 2 * time.Minute, 120.0, ""},
+		{"DurationInvalid", DurationType, "soon", nil, "invalid duration 'soon'"},
+		{"DurationInvalidType", DurationType, true, nil, "type 'bool' cannot be converted to a duration"},
+		{"BytesKilobytes", BytesType, "2048KB", int64(2048000), ""},
+		{"BytesKibibytes", BytesType, "2KiB", int64(2048), ""},
+		{"BytesFraction", BytesType, "1.5 GiB", int64(1610612736), ""},
+		{"BytesShortUnit", BytesType, "10m", int64(10000000), ""},
+		{"BytesUnit", BytesType, "512B", int64(512), ""},
+		{"BytesPlain", BytesType, "9007199254740993", int64(9007199254740993), ""},
+		{"BytesInt", BytesType, 1024, int64(1024), ""},
+		{"BytesFloat", BytesType, 1024.0, int64(1024), ""},
+		{"BytesFractionalNumber", BytesType, 1.5, nil, "invalid byte size 1.5"},
+		{"BytesInvalidUnit", BytesType, "10XB", nil, "invalid unit 'XB'"},
+		{"BytesInvalid", BytesType, "lots", nil, "invalid byte size 'lots'"},
+		{"BytesTooLarge", BytesType, "10000000PB", nil, "too large"},
+		{"BytesInvalidType", BytesType, true, nil, "type 'bool' cannot be converted to a byte size"},
+		{"BooleanYes", BooleanType, "yes", true, ""},
+		{"BooleanNo", BooleanType, "No", false, ""},
+		{"BooleanOn", BooleanType, "ON", true, ""},
+		{"BooleanOff", BooleanType, "off", false, ""},
+		{"BooleanTrue", BooleanType, "true", true, ""},
+		{"BooleanZero", BooleanType, "0", false, ""},
+		{"BooleanNative", BooleanType, true, true, ""},
+		{"BooleanOne", BooleanType, 1, true, ""},
+		{"BooleanInvalidNumber", BooleanType, 2, nil, "invalid boolean 2"},
+		{"BooleanInvalid", BooleanType, "maybe", nil, "invalid boolean 'maybe'"},
+		{"BooleanInvalidType", BooleanType, []string{}, nil, "type '[]string' cannot be converted to a boolean"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			converted, err := converters[tc.typ](tc.input)
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, converted)
+		})
+	}
+}
+
+func TestNormalizeParserProcess(t *testing.T) {
+	parser, fake := newTestParser(t, []FieldConfig{
+		{Field: entry.NewBodyField("duration"), Type: DurationType},
+		{Field: entry.NewBodyField("response", "size"), Type: BytesType},
+		{Field: entry.NewBodyField("enabled"), Type: BooleanType},
+		{Field: entry.NewBodyField("missing"), Type: BooleanType},
+	})
+
+	e := entry.New()
+	e.Body = map[string]interface{}{
+		"duration": "1.5s",
+		"enabled":  "yes",
+		"message":  "request complete",
+		"response": map[string]interface{}{"size": "2048KB"},
+	}
+	require.NoError(t, parser.Process(context.Background(), e))
+
+	select {
+	case received := <-fake.Received:
+		require.Equal(t, map[string]interface{}{
+			"duration": 1.5,
+			"enabled":  true,
+			"message":  "request complete",
+			"response": map[string]interface{}{"size": int64(2048000)},
+		}, received.Body)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
+
+func TestNormalizeParserProcessError(t *testing.T) {
+	parser, fake := newTestParser(t, []FieldConfig{
+		{Field: entry.NewBodyField("duration"), Type: DurationType},
+		{Field: entry.NewBodyField("enabled"), Type: BooleanType},
+	})
+	require.Equal(t, helper.SendOnError, parser.OnError)
+
+	e := entry.New()
+	e.Body = map[string]interface{}{
+		"duration": "1.5s",
+		"enabled":  "maybe",
+	}
+	require.NoError(t, parser.Process(context.Background(), e))
+
+	// The entry is sent unmodified when any field cannot be converted
+	select {
+	case received := <-fake.Received:
+		require.Equal(t, map[string]interface{}{
+			"duration": "1.5s",
+			"enabled":  "maybe",
+		}, received.Body)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pattern
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("pattern_parser", func() operator.Builder { return NewPatternParserConfig("") })
+}
+
+// NewPatternParserConfig creates a new pattern parser config with default values
+func NewPatternParserConfig(operatorID string) *PatternParserConfig {
+	return &PatternParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "pattern_parser"),
+	}
+}
+
+// PatternParserConfig is the configuration of a pattern parser operator.
+type PatternParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	Pattern string `mapstructure:"pattern" json:"pattern" yaml:"pattern"`
+}
+
+// Build will build a pattern parser operator.
+func (c PatternParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Pattern == "" {
+		return nil, fmt.Errorf("missing required field 'pattern'")
+	}
+
+	s, err := compileScanner(c.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %s", err)
+	}
+
+	patternParser := &PatternParser{
+		ParserOperator: parserOperator,
+		scanner:        s,
+	}
+
+	return []operator.Operator{patternParser}, nil
+}
+
+// PatternParser is an operator that parses values with a pattern of literals and typed placeholders.
+type PatternParser struct {
+	helper.ParserOperator
+	scanner *scanner
+}
+
+// Process will parse an entry with the pattern.
+func (p *PatternParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ParserOperator.ProcessWith(ctx, entry, p.parse)
+}
+
+// parse will parse a value with the pattern.
+func (p *PatternParser) parse(value interface{}) (interface{}, error) {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return nil, fmt.Errorf("type '%T' cannot be parsed with a pattern", value)
+	}
+
+	return p.scanner.scan(raw)
+}
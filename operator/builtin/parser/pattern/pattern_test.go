@@ -0,0 +1,282 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pattern
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t testing.TB, pattern string) (*PatternParser, *testutil.FakeOutput) {
+	cfg := NewPatternParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Pattern = pattern
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*PatternParser)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fakeOutput}))
+	return parser, fakeOutput
+}
+
+func TestPatternParserBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		pattern     string
+		expectedErr string
+	}{
+		{"Valid", "%ts{time} %s{level} %s{message}", ""},
+		{"NumberThenString", "%d{status}%s{unit}", ""},
+		{"Missing", "", "missing required field 'pattern'"},
+		{"NoPlaceholders", "plain text", "at least one named placeholder"},
+		{"OnlyUnnamed", "%s %d", "at least one named placeholder"},
+		{"UnknownPlaceholder", "%x{a}", "unknown placeholder at position 0"},
+		{"UnclosedName", "%s{message", "name of placeholder at position 0 is not closed"},
+		{"EmptyName", "%s{} %s{message}", "name of placeholder at position 0 is empty"},
+		{"DuplicateName", "%s{a} %s{a}", "name 'a' is used more than once"},
+		{"AdjacentStrings", "%s{a}%s{b}", "placeholder %s must be followed by a literal"},
+		{"AdjacentNumbers", "%d{a}%f{b}", "placeholder %d must be followed by a literal"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewPatternParserConfig("test")
+			cfg.Pattern = tc.pattern
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestPatternParserParse(t *testing.T) {
+	cases := []struct {
+		name        string
+		pattern     string
+		input       interface{}
+		expected    map[string]interface{}
+		expectedErr string
+	}{
+		{
+			"Basic",
+			"%ts{time} %s{level} %s{message}",
+			"2021-05-01T10:00:00Z INFO service started",
+			map[string]interface{}{
+				"time":    time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC),
+				"level":   "INFO",
+				"message": "service started",
+			},
+			"",
+		},
+		{
+			"Bytes",
+			"%s{level}: %s{message}",
+			[]byte("WARN: disk usage high"),
+			map[string]interface{}{"level": "WARN", "message": "disk usage high"},
+			"",
+		},
+		{
+			"Numbers",
+			"%s{method} %s{path} %d{status} %f{duration}ms",
+			"GET /index.html 200 12.5ms",
+			map[string]interface{}{"method": "GET", "path": "/index.html", "status": 200, "duration": 12.5},
+			"",
+		},
+		{
+			"SignedAndExponent",
+			"offset=%d{offset} ratio=%f{ratio}",
+			"offset=-42 ratio=1.5e-3",
+			map[string]interface{}{"offset": -42, "ratio": 0.0015},
+			"",
+		},
+		{
+			"NumberThenString",
+			"%d{size}%s{unit}",
+			"512KB",
+			map[string]interface{}{"size": 512, "unit": "KB"},
+			"",
+		},
+		{
+			"Unnamed",
+			"%ts %s [%d] %s{message}",
+			"2021-05-01T10:00:00Z host [4242] started",
+			map[string]interface{}{"message": "started"},
+			"",
+		},
+		{
+			"Percent",
+			"cpu=%f{cpu}%%",
+			"cpu=93.5%",
+			map[string]interface{}{"cpu": 93.5},
+			"",
+		},
+		{
+			"EmptyString",
+			"%s{a},%s{b}",
+			",x",
+			nil,
+			"pattern does not match",
+		},
+		{
+			"MissingLiteral",
+			"%s{level}: %s{message}",
+			"WARN disk usage high",
+			nil,
+			"pattern does not match",
+		},
+		{
+			"NotANumber",
+			"status=%d{status}",
+			"status=ok",
+			nil,
+			"pattern does not match",
+		},
+		{
+			"OnlySign",
+			"offset=%d{offset}",
+			"offset=-",
+			nil,
+			"pattern does not match",
+		},
+		{
+			"TrailingText",
+			"status=%d{status}",
+			"status=200 OK",
+			nil,
+			"pattern does not match",
+		},
+		{
+			"IntegerOverflow",
+			"count=%d{count}",
+			"count=99999999999999999999999",
+			nil,
+			"parse %d value",
+		},
+		{
+			"InvalidTimestamp",
+			"%ts{time} %s{message}",
+			"2021-05-01 started",
+			nil,
+			"parse %ts value",
+		},
+		{
+			"InvalidType",
+			"%s{level}: %s{message}",
+			123,
+			nil,
+			"cannot be parsed with a pattern",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, tc.pattern)
+			parsed, err := parser.parse(tc.input)
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+}
+
+func TestPatternParserProcessWithTimestamp(t *testing.T) {
+	cfg := NewPatternParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Pattern = "%ts{time} %s{level} %s{message}"
+	timeField := entry.NewBodyField("time")
+	cfg.TimeParser = &helper.TimeParser{
+		ParseFrom:  &timeField,
+		LayoutType: helper.NativeKey,
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*PatternParser)
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = "2021-05-01T10:00:00.123Z INFO service started"
+	require.NoError(t, parser.Process(context.Background(), e))
+
+	select {
+	case received := <-fake.Received:
+		require.Equal(t, map[string]interface{}{"level": "INFO", "message": "service started"}, received.Body)
+		require.Equal(t, time.Date(2021, 5, 1, 10, 0, 0, 123000000, time.UTC), received.Timestamp)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
+
+const benchmarkValue = "2021-05-01T10:00:00Z INFO 200 12.5 service started"
+
+func BenchmarkPatternParser(b *testing.B) {
+	parser, _ := newTestParser(b, "%ts{time} %s{level} %d{status} %f{duration} %s{message}")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.parse(benchmarkValue); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEquivalentRegex parses the same value as BenchmarkPatternParser with an equivalent regex, for comparison
+func BenchmarkEquivalentRegex(b *testing.B) {
+	r := regexp.MustCompile(`^(?P<time>\S+) (?P<level>\S+) (?P<status>[-+]?\d+) (?P<duration>[-+]?\d+(?:\.\d*)?) (?P<message>.*)$`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := r.FindStringSubmatch(benchmarkValue)
+		if m == nil {
+			b.Fatal("no match")
+		}
+		ts, err := time.Parse(time.RFC3339Nano, m[1])
+		if err != nil {
+			b.Fatal(err)
+		}
+		status, err := strconv.Atoi(m[3])
+		if err != nil {
+			b.Fatal(err)
+		}
+		duration, err := strconv.ParseFloat(m[4], 64)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = map[string]interface{}{"time": ts, "level": m[2], "status": status, "duration": duration, "message": m[5]}
+	}
+}
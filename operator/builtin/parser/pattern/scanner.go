@@ -0,0 +1,245 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pattern
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// These are the types of the placeholders of a pattern.
+const (
+	stringPlaceholder    = "s"
+	intPlaceholder       = "d"
+	floatPlaceholder     = "f"
+	timestampPlaceholder = "ts"
+)
+
+// token is either a literal, or a placeholder of a type which may have a name.
+type token struct {
+	literal string
+
+	placeholder string
+	name        string
+}
+
+// isLiteral returns true if the token is a literal.
+func (t token) isLiteral() bool {
+	return t.placeholder == ""
+}
+
+// delimited returns true if the value of a placeholder extends up to the literal which follows it.
+// Numeric placeholders end at the first character which cannot be part of the number.
+func (t token) delimited() bool {
+	return t.placeholder == stringPlaceholder || t.placeholder == timestampPlaceholder
+}
+
+// scanner scans values with a compiled pattern.
+type scanner struct {
+	tokens []token
+}
+
+// compileScanner will compile a pattern such as '%ts{time} %s{level} %s{message}'. A placeholder is
+// '%' followed by its type, and optionally its name in braces. The values of unnamed placeholders are
+// matched but not parsed. '%%' is a literal '%'.
+func compileScanner(pattern string) (*scanner, error) {
+	var tokens []token
+	var literal strings.Builder
+	names := map[string]bool{}
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' {
+			literal.WriteByte(pattern[i])
+			continue
+		}
+
+		rest := pattern[i+1:]
+		var t token
+		switch {
+		case strings.HasPrefix(rest, "%"):
+			literal.WriteByte('%')
+			i++
+			continue
+		case strings.HasPrefix(rest, timestampPlaceholder):
+			t.placeholder = timestampPlaceholder
+		case strings.HasPrefix(rest, stringPlaceholder):
+			t.placeholder = stringPlaceholder
+		case strings.HasPrefix(rest, intPlaceholder):
+			t.placeholder = intPlaceholder
+		case strings.HasPrefix(rest, floatPlaceholder):
+			t.placeholder = floatPlaceholder
+		default:
+			return nil, fmt.Errorf("unknown placeholder at position %d, must be one of %%ts, %%s, %%d or %%f", i)
+		}
+		i += len(t.placeholder)
+		rest = rest[len(t.placeholder):]
+
+		if strings.HasPrefix(rest, "{") {
+			end := strings.IndexByte(rest, '}')
+			if end < 0 {
+				return nil, fmt.Errorf("name of placeholder at position %d is not closed", i-len(t.placeholder))
+			}
+			t.name = rest[1:end]
+			if t.name == "" {
+				return nil, fmt.Errorf("name of placeholder at position %d is empty", i-len(t.placeholder))
+			}
+			if names[t.name] {
+				return nil, fmt.Errorf("name '%s' is used more than once", t.name)
+			}
+			names[t.name] = true
+			i += end + 1
+		}
+
+		if literal.Len() > 0 {
+			tokens = append(tokens, token{literal: literal.String()})
+			literal.Reset()
+		} else if len(tokens) > 0 && !tokens[len(tokens)-1].isLiteral() {
+			// A numeric value ends at the first character which cannot be part of it, so
+			// it may be followed by a string, but placeholders are otherwise ambiguous
+			previous := tokens[len(tokens)-1]
+			if previous.delimited() || !t.delimited() {
+				return nil, fmt.Errorf("placeholder %%%s must be followed by a literal", previous.placeholder)
+			}
+		}
+		tokens = append(tokens, t)
+	}
+
+	if literal.Len() > 0 {
+		tokens = append(tokens, token{literal: literal.String()})
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("pattern must contain at least one named placeholder")
+	}
+	return &scanner{tokens: tokens}, nil
+}
+
+// errNoMatch is returned when a value does not match a pattern.
+var errNoMatch = fmt.Errorf("pattern does not match")
+
+// scan will parse a value with the pattern. The whole value must be matched.
+func (s *scanner) scan(value string) (map[string]interface{}, error) {
+	parsed := make(map[string]interface{}, len(s.tokens))
+	rest := value
+	for i, t := range s.tokens {
+		if t.isLiteral() {
+			if !strings.HasPrefix(rest, t.literal) {
+				return nil, errNoMatch
+			}
+			rest = rest[len(t.literal):]
+			continue
+		}
+
+		var raw string
+		if t.delimited() {
+			raw, rest = s.delimitedValue(rest, i)
+		} else {
+			raw, rest = numericValue(rest, t.placeholder == floatPlaceholder)
+		}
+		if raw == "" {
+			return nil, errNoMatch
+		}
+
+		v, err := t.convert(raw)
+		if err != nil {
+			return nil, err
+		}
+		if t.name != "" {
+			parsed[t.name] = v
+		}
+	}
+
+	if rest != "" {
+		return nil, errNoMatch
+	}
+	return parsed, nil
+}
+
+// delimitedValue will return the value of a placeholder which extends up to the literal which follows it,
+// or to the end of the value, and the remainder of the value.
+func (s *scanner) delimitedValue(rest string, i int) (string, string) {
+	if i+1 == len(s.tokens) {
+		return rest, ""
+	}
+	end := strings.Index(rest, s.tokens[i+1].literal)
+	if end < 0 {
+		return "", rest
+	}
+	return rest[:end], rest[end:]
+}
+
+// numericValue will return the leading number of a value, and the remainder of the value.
+func numericValue(rest string, float bool) (string, string) {
+	i := 0
+	if i < len(rest) && (rest[i] == '-' || rest[i] == '+') {
+		i++
+	}
+	start := i
+	i = skipDigits(rest, i)
+	if float {
+		if i < len(rest) && rest[i] == '.' {
+			i = skipDigits(rest, i+1)
+		}
+		if i < len(rest) && (rest[i] == 'e' || rest[i] == 'E') {
+			j := i + 1
+			if j < len(rest) && (rest[j] == '-' || rest[j] == '+') {
+				j++
+			}
+			if k := skipDigits(rest, j); k > j {
+				i = k
+			}
+		}
+	}
+	if i == start || (i == start+1 && rest[start] == '.') {
+		return "", rest
+	}
+	return rest[:i], rest[i:]
+}
+
+// skipDigits returns the index of the first character from i which is not a digit.
+func skipDigits(s string, i int) int {
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+// convert will convert the raw value of a placeholder to its type.
+func (t token) convert(raw string) (interface{}, error) {
+	switch t.placeholder {
+	case intPlaceholder:
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse %%d value '%s': %s", raw, err)
+		}
+		return v, nil
+	case floatPlaceholder:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse %%f value '%s': %s", raw, err)
+		}
+		return v, nil
+	case timestampPlaceholder:
+		v, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse %%ts value '%s': %s", raw, err)
+		}
+		return v, nil
+	default:
+		return raw, nil
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestProtobufParserConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "message_type",
+			Expect: func() *ProtobufParserConfig {
+				cfg := defaultCfg()
+				cfg.DescriptorSet = "/etc/otel/events.pb"
+				cfg.MessageType = "acme.v1.Event"
+				return cfg
+			}(),
+		},
+		{
+			Name: "base64_length_delimited",
+			Expect: func() *ProtobufParserConfig {
+				cfg := defaultCfg()
+				cfg.DescriptorSet = "/etc/otel/events.pb"
+				cfg.MessageType = "acme.v1.Event"
+				cfg.Encoding = Base64Encoding
+				cfg.LengthDelimited = true
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *ProtobufParserConfig {
+	return NewProtobufParserConfig("protobuf_parser")
+}
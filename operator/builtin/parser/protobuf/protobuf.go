@@ -0,0 +1,243 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protobuf
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// RawEncoding decodes the bytes of the parsed field as a message
+	RawEncoding = "raw"
+
+	// Base64Encoding decodes the parsed field as base64 before decoding it as a message
+	Base64Encoding = "base64"
+)
+
+func init() {
+	operator.Register("protobuf_parser", func() operator.Builder { return NewProtobufParserConfig("") })
+}
+
+// NewProtobufParserConfig creates a new protobuf parser config with default values
+func NewProtobufParserConfig(operatorID string) *ProtobufParserConfig {
+	return &ProtobufParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "protobuf_parser"),
+		Encoding:     RawEncoding,
+	}
+}
+
+// ProtobufParserConfig is the configuration of a protobuf parser operator.
+type ProtobufParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	DescriptorSet   string `mapstructure:"descriptor_set"             json:"descriptor_set"             yaml:"descriptor_set"`
+	MessageType     string `mapstructure:"message_type"               json:"message_type"               yaml:"message_type"`
+	Encoding        string `mapstructure:"encoding,omitempty"         json:"encoding,omitempty"         yaml:"encoding,omitempty"`
+	LengthDelimited bool   `mapstructure:"length_delimited,omitempty" json:"length_delimited,omitempty" yaml:"length_delimited,omitempty"`
+}
+
+// Build will build a protobuf parser operator.
+func (c ProtobufParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.DescriptorSet == "" {
+		return nil, fmt.Errorf("missing required field 'descriptor_set'")
+	}
+
+	if c.MessageType == "" {
+		return nil, fmt.Errorf("missing required field 'message_type'")
+	}
+
+	switch c.Encoding {
+	case RawEncoding, Base64Encoding:
+	default:
+		return nil, fmt.Errorf("invalid encoding '%s', must be one of '%s' or '%s'", c.Encoding, RawEncoding, Base64Encoding)
+	}
+
+	messageType, err := loadMessageType(c.DescriptorSet, c.MessageType)
+	if err != nil {
+		return nil, err
+	}
+
+	protobufParser := &ProtobufParser{
+		ParserOperator:  parserOperator,
+		messageType:     messageType,
+		base64:          c.Encoding == Base64Encoding,
+		lengthDelimited: c.LengthDelimited,
+	}
+
+	return []operator.Operator{protobufParser}, nil
+}
+
+// loadMessageType will load a message type from a file containing a serialized FileDescriptorSet,
+// such as is written by protoc with the --descriptor_set_out and --include_imports flags.
+func loadMessageType(path, name string) (protoreflect.MessageType, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read descriptor_set: %s", err)
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("unmarshal descriptor_set %s: %s", path, err)
+	}
+
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("load descriptor_set %s: %s", path, err)
+	}
+
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("find message_type '%s': %s", name, err)
+	}
+
+	messageDescriptor, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("message_type '%s' is not a message", name)
+	}
+	return dynamicpb.NewMessageType(messageDescriptor), nil
+}
+
+// ProtobufParser is an operator that parses protobuf messages.
+type ProtobufParser struct {
+	helper.ParserOperator
+	messageType     protoreflect.MessageType
+	base64          bool
+	lengthDelimited bool
+}
+
+// Process will parse an entry field as a protobuf message.
+func (p *ProtobufParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ParserOperator.ProcessWith(ctx, entry, p.parse)
+}
+
+// parse will parse a value as a protobuf message.
+func (p *ProtobufParser) parse(value interface{}) (interface{}, error) {
+	var data []byte
+	switch m := value.(type) {
+	case string:
+		data = []byte(m)
+	case []byte:
+		data = m
+	default:
+		return nil, fmt.Errorf("type %T cannot be parsed as protobuf", value)
+	}
+
+	if p.base64 {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("decode base64: %s", err)
+		}
+		data = decoded
+	}
+
+	if p.lengthDelimited {
+		length, n := protowire.ConsumeVarint(data)
+		if n < 0 {
+			return nil, fmt.Errorf("invalid length prefix: %s", protowire.ParseError(n))
+		}
+		if uint64(len(data)-n) != length {
+			return nil, fmt.Errorf("length prefix of %d bytes does not match message of %d bytes", length, len(data)-n)
+		}
+		data = data[n:]
+	}
+
+	message := p.messageType.New()
+	if err := proto.Unmarshal(data, message.Interface()); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %s", message.Descriptor().FullName(), err)
+	}
+
+	return messageToMap(message), nil
+}
+
+// messageToMap will convert the populated fields of a message to a map, keyed by field name.
+func messageToMap(message protoreflect.Message) map[string]interface{} {
+	parsedValues := map[string]interface{}{}
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		parsedValues[string(field.Name())] = fieldValue(field, value)
+		return true
+	})
+	return parsedValues
+}
+
+// fieldValue will convert the value of a field, which may be repeated or a map.
+func fieldValue(field protoreflect.FieldDescriptor, value protoreflect.Value) interface{} {
+	switch {
+	case field.IsList():
+		list := value.List()
+		values := make([]interface{}, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			values = append(values, singularValue(field, list.Get(i)))
+		}
+		return values
+	case field.IsMap():
+		values := map[string]interface{}{}
+		value.Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+			values[key.String()] = singularValue(field.MapValue(), value)
+			return true
+		})
+		return values
+	default:
+		return singularValue(field, value)
+	}
+}
+
+// singularValue will convert a single value of a field. Enums are converted to the name of their
+// value, if it is defined, and bytes are encoded as base64.
+func singularValue(field protoreflect.FieldDescriptor, value protoreflect.Value) interface{} {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return value.Bool()
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return value.Int()
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return value.Uint()
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return value.Float()
+	case protoreflect.StringKind:
+		return value.String()
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(value.Bytes())
+	case protoreflect.EnumKind:
+		if enumValue := field.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return string(enumValue.Name())
+		}
+		return int64(value.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageToMap(value.Message())
+	default:
+		return value.Interface()
+	}
+}
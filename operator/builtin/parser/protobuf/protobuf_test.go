@@ -0,0 +1,390 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protobuf
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// testFile describes the messages used in tests:
+//
+//	package acme.v1;
+//	enum Level { LEVEL_UNSPECIFIED = 0; LEVEL_INFO = 1; LEVEL_ERROR = 2; }
+//	message Source { string host = 1; uint32 pid = 2; }
+//	message Event {
+//	  string message = 1;
+//	  int64 count = 2;
+//	  double ratio = 3;
+//	  bool ok = 4;
+//	  bytes payload = 5;
+//	  Level level = 6;
+//	  Source source = 7;
+//	  repeated string tags = 8;
+//	  map<string, int32> labels = 9;
+//	}
+func testFile() *descriptorpb.FileDescriptorProto {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("acme/v1/event.proto"),
+		Package: proto.String("acme.v1"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Level"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("LEVEL_UNSPECIFIED"), Number: proto.Int32(0)},
+				{Name: proto.String("LEVEL_INFO"), Number: proto.Int32(1)},
+				{Name: proto.String("LEVEL_ERROR"), Number: proto.Int32(2)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Source"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("host", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+					field("pid", 2, descriptorpb.FieldDescriptorProto_TYPE_UINT32, optional, ""),
+				},
+			},
+			{
+				Name: proto.String("Event"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("message", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+					field("count", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional, ""),
+					field("ratio", 3, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, optional, ""),
+					field("ok", 4, descriptorpb.FieldDescriptorProto_TYPE_BOOL, optional, ""),
+					field("payload", 5, descriptorpb.FieldDescriptorProto_TYPE_BYTES, optional, ""),
+					field("level", 6, descriptorpb.FieldDescriptorProto_TYPE_ENUM, optional, ".acme.v1.Level"),
+					field("source", 7, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional, ".acme.v1.Source"),
+					field("tags", 8, descriptorpb.FieldDescriptorProto_TYPE_STRING, repeated, ""),
+					field("labels", 9, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated, ".acme.v1.Event.LabelsEntry"),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name: proto.String("LabelsEntry"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+						field("value", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32, optional, ""),
+					},
+					Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				}},
+			},
+		},
+	}
+}
+
+// writeDescriptorSet will write the test file as a FileDescriptorSet in a temporary directory.
+func writeDescriptorSet(t *testing.T) string {
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{testFile()},
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "event.pb")
+	require.NoError(t, ioutil.WriteFile(path, data, 0600))
+	return path
+}
+
+// testEvent will marshal an event with all of its fields populated.
+func testEvent(t *testing.T) []byte {
+	file, err := protodesc.NewFile(testFile(), nil)
+	require.NoError(t, err)
+	descriptor := file.Messages().ByName("Event")
+	fields := descriptor.Fields()
+
+	source := dynamicpb.NewMessage(file.Messages().ByName("Source"))
+	source.Set(source.Descriptor().Fields().ByName("host"), protoreflect.ValueOfString("web-1"))
+	source.Set(source.Descriptor().Fields().ByName("pid"), protoreflect.ValueOfUint32(42))
+
+	event := dynamicpb.NewMessage(descriptor)
+	event.Set(fields.ByName("message"), protoreflect.ValueOfString("request served"))
+	event.Set(fields.ByName("count"), protoreflect.ValueOfInt64(-7))
+	event.Set(fields.ByName("ratio"), protoreflect.ValueOfFloat64(0.5))
+	event.Set(fields.ByName("ok"), protoreflect.ValueOfBool(true))
+	event.Set(fields.ByName("payload"), protoreflect.ValueOfBytes([]byte("raw")))
+	event.Set(fields.ByName("level"), protoreflect.ValueOfEnum(2))
+	event.Set(fields.ByName("source"), protoreflect.ValueOfMessage(source))
+	tags := event.Mutable(fields.ByName("tags")).List()
+	tags.Append(protoreflect.ValueOfString("a"))
+	tags.Append(protoreflect.ValueOfString("b"))
+	labels := event.Mutable(fields.ByName("labels")).Map()
+	labels.Set(protoreflect.ValueOfString("code").MapKey(), protoreflect.ValueOfInt32(200))
+
+	data, err := proto.Marshal(event)
+	require.NoError(t, err)
+	return data
+}
+
+func expectedEvent() map[string]interface{} {
+	return map[string]interface{}{
+		"message": "request served",
+		"count":   int64(-7),
+		"ratio":   0.5,
+		"ok":      true,
+		"payload": "cmF3",
+		"level":   "LEVEL_ERROR",
+		"source": map[string]interface{}{
+			"host": "web-1",
+			"pid":  uint64(42),
+		},
+		"tags": []interface{}{"a", "b"},
+		"labels": map[string]interface{}{
+			"code": int64(200),
+		},
+	}
+}
+
+func newTestParser(t *testing.T, modify func(*ProtobufParserConfig)) (*ProtobufParser, *testutil.FakeOutput) {
+	cfg := NewProtobufParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.DescriptorSet = writeDescriptorSet(t)
+	cfg.MessageType = "acme.v1.Event"
+	if modify != nil {
+		modify(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*ProtobufParser)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fakeOutput}))
+	return parser, fakeOutput
+}
+
+func TestProtobufParserBuildFailure(t *testing.T) {
+	invalidDescriptorSet := filepath.Join(t.TempDir(), "invalid.pb")
+	require.NoError(t, ioutil.WriteFile(invalidDescriptorSet, []byte("not a descriptor set"), 0600))
+
+	cases := []struct {
+		name     string
+		modify   func(*ProtobufParserConfig)
+		expected string
+	}{
+		{
+			"MissingDescriptorSet",
+			func(cfg *ProtobufParserConfig) { cfg.DescriptorSet = "" },
+			"missing required field 'descriptor_set'",
+		},
+		{
+			"MissingMessageType",
+			func(cfg *ProtobufParserConfig) { cfg.MessageType = "" },
+			"missing required field 'message_type'",
+		},
+		{
+			"InvalidEncoding",
+			func(cfg *ProtobufParserConfig) { cfg.Encoding = "hex" },
+			"invalid encoding 'hex'",
+		},
+		{
+			"NonexistentDescriptorSet",
+			func(cfg *ProtobufParserConfig) { cfg.DescriptorSet = filepath.Join(t.TempDir(), "missing.pb") },
+			"read descriptor_set",
+		},
+		{
+			"InvalidDescriptorSet",
+			func(cfg *ProtobufParserConfig) { cfg.DescriptorSet = invalidDescriptorSet },
+			"unmarshal descriptor_set",
+		},
+		{
+			"UnknownMessageType",
+			func(cfg *ProtobufParserConfig) { cfg.MessageType = "acme.v1.Missing" },
+			"find message_type 'acme.v1.Missing'",
+		},
+		{
+			"NotAMessage",
+			func(cfg *ProtobufParserConfig) { cfg.MessageType = "acme.v1.Level" },
+			"message_type 'acme.v1.Level' is not a message",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewProtobufParserConfig("test")
+			cfg.DescriptorSet = writeDescriptorSet(t)
+			cfg.MessageType = "acme.v1.Event"
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestProtobufParserParse(t *testing.T) {
+	event := testEvent(t)
+	delimited := protowire.AppendVarint(nil, uint64(len(event)))
+	delimited = append(delimited, event...)
+
+	cases := []struct {
+		name     string
+		modify   func(*ProtobufParserConfig)
+		input    interface{}
+		expected map[string]interface{}
+	}{
+		{
+			"Bytes",
+			nil,
+			event,
+			expectedEvent(),
+		},
+		{
+			"String",
+			nil,
+			string(event),
+			expectedEvent(),
+		},
+		{
+			"Empty",
+			nil,
+			[]byte{},
+			map[string]interface{}{},
+		},
+		{
+			"Base64",
+			func(cfg *ProtobufParserConfig) { cfg.Encoding = Base64Encoding },
+			base64.StdEncoding.EncodeToString(event) + "\n",
+			expectedEvent(),
+		},
+		{
+			"LengthDelimited",
+			func(cfg *ProtobufParserConfig) { cfg.LengthDelimited = true },
+			delimited,
+			expectedEvent(),
+		},
+		{
+			"Base64LengthDelimited",
+			func(cfg *ProtobufParserConfig) {
+				cfg.Encoding = Base64Encoding
+				cfg.LengthDelimited = true
+			},
+			base64.StdEncoding.EncodeToString(delimited),
+			expectedEvent(),
+		},
+		{
+			"UndefinedEnumValue",
+			nil,
+			protowire.AppendVarint(protowire.AppendTag(nil, 6, protowire.VarintType), 9),
+			map[string]interface{}{
+				"level": int64(9),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, tc.modify)
+			actual, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestProtobufParserParseErrors(t *testing.T) {
+	event := testEvent(t)
+
+	cases := []struct {
+		name     string
+		modify   func(*ProtobufParserConfig)
+		input    interface{}
+		expected string
+	}{
+		{
+			"InvalidType",
+			nil,
+			map[string]interface{}{},
+			"type map[string]interface {} cannot be parsed as protobuf",
+		},
+		{
+			"InvalidMessage",
+			nil,
+			[]byte{0x0a, 0x10, 'a'},
+			"unmarshal acme.v1.Event",
+		},
+		{
+			"InvalidBase64",
+			func(cfg *ProtobufParserConfig) { cfg.Encoding = Base64Encoding },
+			"not base64!",
+			"decode base64",
+		},
+		{
+			"MissingLengthPrefix",
+			func(cfg *ProtobufParserConfig) { cfg.LengthDelimited = true },
+			[]byte{},
+			"invalid length prefix",
+		},
+		{
+			"LengthMismatch",
+			func(cfg *ProtobufParserConfig) { cfg.LengthDelimited = true },
+			append([]byte{byte(len(event) + 1)}, event...),
+			"does not match message",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, tc.modify)
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestProtobufParserProcess(t *testing.T) {
+	parser, fakeOutput := newTestParser(t, nil)
+
+	e := entry.New()
+	e.Body = testEvent(t)
+	require.NoError(t, parser.Process(context.Background(), e))
+
+	select {
+	case out := <-fakeOutput.Received:
+		require.Equal(t, expectedEvent(), out.Body)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
@@ -100,6 +100,36 @@ func TestRegexParserGoldenConfig(t *testing.T) {
 				return cfg
 			}(),
 		},
+		{
+			Name: "preset",
+			Expect: func() *RegexParserConfig {
+				cfg := defaultCfg()
+				cfg.Preset = "apache_combined"
+				return cfg
+			}(),
+		},
+		{
+			Name: "patterns",
+			Expect: func() *RegexParserConfig {
+				cfg := defaultCfg()
+				cfg.Patterns = []string{"^GET (?P<path>.*)$", "^POST (?P<path>.*)$"}
+				cfg.PerformanceMode = true
+				return cfg
+			}(),
+		},
+		{
+			Name: "named_patterns",
+			Expect: func() *RegexParserConfig {
+				cfg := defaultCfg()
+				cfg.NamedPatterns = []NamedPatternConfig{
+					{Name: "access", Regex: "^(?P<method>GET|POST) (?P<path>.*)$"},
+					{Name: "error", Regex: "^ERROR (?P<message>.*)$"},
+				}
+				cfg.PatternAttribute = "log.format"
+				cfg.OnNoMatch = "drop"
+				return cfg
+			}(),
+		},
 	}
 
 	for _, tc := range cases {
@@ -0,0 +1,154 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package regex
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// preset is a built-in regex for a common log format, along with the
+// timestamp and severity of its parsed fields.
+type preset struct {
+	regex string
+
+	timeField  string
+	timeLayout string
+
+	severityField   string
+	severityMapping map[interface{}]interface{}
+}
+
+const (
+	// accessLogRegex matches the Apache common log format, which is the prefix of the combined format
+	accessLogRegex = `^(?P<remote_addr>\S+) (?P<ident>\S+) (?P<remote_user>\S+) \[(?P<timestamp>[^\]]+)\] "(?:(?P<method>\S+) (?P<path>\S+)(?: (?P<protocol>[^"]*))?|[^"]*)" (?P<status>\d{3}) (?P<bytes>\S+)`
+
+	// accessLogTimeLayout is the time layout of Apache and Nginx access logs
+	accessLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+)
+
+// accessLogSeverity maps the status codes of an access log to severities
+var accessLogSeverity = map[interface{}]interface{}{
+	"info":    []interface{}{helper.HTTP2xx, helper.HTTP3xx},
+	"warning": helper.HTTP4xx,
+	"error":   helper.HTTP5xx,
+}
+
+// errorLogSeverity maps the levels of Apache and Nginx error logs which are not
+// already understood by the default severity mapping
+var errorLogSeverity = map[interface{}]interface{}{
+	"emergency": "emerg",
+	"trace":     []interface{}{"trace1", "trace2", "trace3", "trace4", "trace5", "trace6", "trace7", "trace8"},
+}
+
+var presets = map[string]preset{
+	"apache_common": {
+		regex:           accessLogRegex + `$`,
+		timeField:       "timestamp",
+		timeLayout:      accessLogTimeLayout,
+		severityField:   "status",
+		severityMapping: accessLogSeverity,
+	},
+	"apache_combined": {
+		regex:           accessLogRegex + ` "(?P<referer>[^"]*)" "(?P<user_agent>[^"]*)"$`,
+		timeField:       "timestamp",
+		timeLayout:      accessLogTimeLayout,
+		severityField:   "status",
+		severityMapping: accessLogSeverity,
+	},
+	"apache_error": {
+		regex:           `^\[(?P<timestamp>[^\]]+)\] \[(?:(?P<module>[^:\]]+):)?(?P<level>[^\]]+)\](?: \[pid (?P<pid>\d+)(?::tid (?P<tid>\d+))?\])?(?: \[client (?P<client>[^\]]+)\])? (?:(?P<error_code>AH\d+): )?(?P<message>.*)$`,
+		timeField:       "timestamp",
+		timeLayout:      "Mon Jan _2 15:04:05 2006",
+		severityField:   "level",
+		severityMapping: errorLogSeverity,
+	},
+	"nginx_access": {
+		regex:           `^(?P<remote_addr>\S+) - (?P<remote_user>\S+) \[(?P<timestamp>[^\]]+)\] "(?:(?P<method>\S+) (?P<path>\S+)(?: (?P<protocol>[^"]*))?|[^"]*)" (?P<status>\d{3}) (?P<bytes>\S+) "(?P<referer>[^"]*)" "(?P<user_agent>[^"]*)"(?: "(?P<forwarded_for>[^"]*)")?$`,
+		timeField:       "timestamp",
+		timeLayout:      accessLogTimeLayout,
+		severityField:   "status",
+		severityMapping: accessLogSeverity,
+	},
+	"nginx_error": {
+		regex:           `^(?P<timestamp>\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}) \[(?P<level>\w+)\] (?P<pid>\d+)#(?P<tid>\d+): (?:\*(?P<connection_id>\d+) )?(?P<message>.*)$`,
+		timeField:       "timestamp",
+		timeLayout:      "2006/01/02 15:04:05",
+		severityField:   "level",
+		severityMapping: errorLogSeverity,
+	},
+}
+
+// presetNames returns the sorted names of the presets.
+func presetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply will configure the regex of the parser, along with its timestamp and severity
+// if they are not already configured. As with any parser, the timestamp field is removed
+// once it is parsed. The timestamp and severity are parsed from the
+// fields of the preset within parse_to, so they are only configured when parse_to is
+// a body field.
+func (p preset) apply(c *RegexParserConfig) {
+	c.Regex = p.regex
+
+	parseTo, ok := c.ParseTo.FieldInterface.(entry.BodyField)
+	if !ok {
+		return
+	}
+
+	if c.TimeParser == nil {
+		timeParser := helper.NewTimeParser()
+		parseFrom := entry.Field{FieldInterface: parseTo.Child(p.timeField)}
+		timeParser.ParseFrom = &parseFrom
+		timeParser.LayoutType = helper.GotimeKey
+		timeParser.Layout = p.timeLayout
+		c.TimeParser = &timeParser
+	}
+
+	if c.SeverityParserConfig == nil {
+		severityParser := helper.NewSeverityParserConfig()
+		parseFrom := entry.Field{FieldInterface: parseTo.Child(p.severityField)}
+		severityParser.ParseFrom = &parseFrom
+		// The status code or level is kept, as it is more specific than the severity
+		severityParser.PreserveTo = &parseFrom
+		severityParser.Mapping = p.severityMapping
+		c.SeverityParserConfig = &severityParser
+	}
+}
+
+// applyPreset will configure the parser with the named preset.
+func (c *RegexParserConfig) applyPreset() error {
+	p, ok := presets[c.Preset]
+	if !ok {
+		return fmt.Errorf("invalid preset '%s', must be one of %v", c.Preset, presetNames())
+	}
+	if c.Regex != "" {
+		return fmt.Errorf("only one of 'regex' or 'preset' can be set")
+	}
+	if len(c.Patterns) > 0 {
+		return fmt.Errorf("only one of 'patterns' or 'preset' can be set")
+	}
+	p.apply(c)
+	return nil
+}
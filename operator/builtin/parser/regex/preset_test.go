@@ -0,0 +1,210 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package regex
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestRegexParserPresets(t *testing.T) {
+	cases := []struct {
+		preset           string
+		input            string
+		expectedBody     map[string]interface{}
+		expectedTime     time.Time
+		expectedSeverity entry.Severity
+	}{
+		{
+			"apache_common",
+			`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`,
+			map[string]interface{}{
+				"remote_addr": "127.0.0.1",
+				"ident":       "-",
+				"remote_user": "frank",
+				"method":      "GET",
+				"path":        "/apache_pb.gif",
+				"protocol":    "HTTP/1.0",
+				"status":      "200",
+				"bytes":       "2326",
+			},
+			time.Date(2000, time.October, 10, 20, 55, 36, 0, time.UTC),
+			entry.Info,
+		},
+		{
+			"apache_combined",
+			`127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /missing HTTP/1.1" 404 209 "http://www.example.com/start.html" "Mozilla/4.08 [en] (Win98; I ;Nav)"`,
+			map[string]interface{}{
+				"remote_addr": "127.0.0.1",
+				"ident":       "-",
+				"remote_user": "-",
+				"method":      "GET",
+				"path":        "/missing",
+				"protocol":    "HTTP/1.1",
+				"status":      "404",
+				"bytes":       "209",
+				"referer":     "http://www.example.com/start.html",
+				"user_agent":  "Mozilla/4.08 [en] (Win98; I ;Nav)",
+			},
+			time.Date(2000, time.October, 10, 20, 55, 36, 0, time.UTC),
+			entry.Warning,
+		},
+		{
+			"apache_error",
+			`[Wed Oct 11 14:32:52.123456 2000] [core:error] [pid 35708:tid 4328636416] [client 72.15.99.187:51234] AH00124: Request exceeded the limit of 10 internal redirects`,
+			map[string]interface{}{
+				"module":     "core",
+				"level":      "error",
+				"pid":        "35708",
+				"tid":        "4328636416",
+				"client":     "72.15.99.187:51234",
+				"error_code": "AH00124",
+				"message":    "Request exceeded the limit of 10 internal redirects",
+			},
+			time.Date(2000, time.October, 11, 14, 32, 52, 123456000, time.Local),
+			entry.Error,
+		},
+		{
+			"apache_error",
+			`[Wed Oct 11 14:32:52 2000] [emerg] [client 127.0.0.1] client denied by server configuration: /export/home/live/ap/htdocs/test`,
+			map[string]interface{}{
+				"module":     "",
+				"level":      "emerg",
+				"pid":        "",
+				"tid":        "",
+				"client":     "127.0.0.1",
+				"error_code": "",
+				"message":    "client denied by server configuration: /export/home/live/ap/htdocs/test",
+			},
+			time.Date(2000, time.October, 11, 14, 32, 52, 0, time.Local),
+			entry.Emergency,
+		},
+		{
+			"nginx_access",
+			`192.168.1.10 - - [17/May/2021:08:05:32 +0000] "POST /api/login HTTP/1.1" 502 157 "-" "curl/7.64.1"`,
+			map[string]interface{}{
+				"remote_addr":   "192.168.1.10",
+				"remote_user":   "-",
+				"method":        "POST",
+				"path":          "/api/login",
+				"protocol":      "HTTP/1.1",
+				"status":        "502",
+				"bytes":         "157",
+				"referer":       "-",
+				"user_agent":    "curl/7.64.1",
+				"forwarded_for": "",
+			},
+			time.Date(2021, time.May, 17, 8, 5, 32, 0, time.UTC),
+			entry.Error,
+		},
+		{
+			"nginx_error",
+			`2021/05/17 08:05:32 [warn] 1234#0: *5 upstream server temporarily disabled while connecting to upstream, client: 192.168.1.10`,
+			map[string]interface{}{
+				"level":         "warn",
+				"pid":           "1234",
+				"tid":           "0",
+				"connection_id": "5",
+				"message":       "upstream server temporarily disabled while connecting to upstream, client: 192.168.1.10",
+			},
+			time.Date(2021, time.May, 17, 8, 5, 32, 0, time.Local),
+			entry.Warning,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.preset, func(t *testing.T) {
+			cfg := NewRegexParserConfig("test")
+			cfg.OutputIDs = []string{"fake"}
+			cfg.Preset = tc.preset
+
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			fake := testutil.NewFakeOutput(t)
+			require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+			e := entry.New()
+			e.Body = tc.input
+			require.NoError(t, op.Process(context.Background(), e))
+
+			select {
+			case result := <-fake.Received:
+				require.Equal(t, tc.expectedBody, result.Body)
+				require.True(t, tc.expectedTime.Equal(result.Timestamp), "expected %s, got %s", tc.expectedTime, result.Timestamp)
+				require.Equal(t, tc.expectedSeverity, result.Severity)
+			case <-time.After(time.Second):
+				require.FailNow(t, "Timed out waiting for entry")
+			}
+		})
+	}
+}
+
+func TestRegexParserPresetParseTo(t *testing.T) {
+	cfg := NewRegexParserConfig("test")
+	cfg.Preset = "nginx_error"
+	cfg.ParseTo = entry.NewBodyField("nginx")
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*RegexParser)
+	require.Equal(t, entry.NewBodyField("nginx", "timestamp"), *parser.TimeParser.ParseFrom)
+	require.Equal(t, entry.NewBodyField("nginx", "level"), parser.SeverityParser.ParseFrom)
+}
+
+func TestRegexParserPresetKeepsConfiguredTimestamp(t *testing.T) {
+	parseFrom := entry.NewBodyField("time")
+	timeParser := helper.NewTimeParser()
+	timeParser.ParseFrom = &parseFrom
+	timeParser.Layout = "%Y"
+
+	cfg := NewRegexParserConfig("test")
+	cfg.Preset = "apache_common"
+	cfg.TimeParser = &timeParser
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*RegexParser)
+	require.Equal(t, parseFrom, *parser.TimeParser.ParseFrom)
+	require.NotNil(t, parser.SeverityParser)
+}
+
+func TestRegexParserPresetBuildFailure(t *testing.T) {
+	t.Run("Unknown", func(t *testing.T) {
+		cfg := NewRegexParserConfig("test")
+		cfg.Preset = "iis"
+		_, err := cfg.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid preset 'iis'")
+	})
+
+	t.Run("WithRegex", func(t *testing.T) {
+		cfg := NewRegexParserConfig("test")
+		cfg.Preset = "nginx_access"
+		cfg.Regex = "^(?P<message>.*)$"
+		_, err := cfg.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "only one of 'regex' or 'preset' can be set")
+	})
+}
@@ -18,6 +18,8 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"regexp/syntax"
+	"strings"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/errors"
@@ -36,27 +38,133 @@ func NewRegexParserConfig(operatorID string) *RegexParserConfig {
 	}
 }
 
+// These are the actions of a regex parser when a value matches none of its patterns.
+const (
+	ErrorOnNoMatch = "error"
+	SendOnNoMatch  = "send"
+	DropOnNoMatch  = "drop"
+)
+
 // RegexParserConfig is the configuration of a regex parser operator.
 type RegexParserConfig struct {
 	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
 
+	Regex            string               `mapstructure:"regex"                       json:"regex"                       yaml:"regex"`
+	Patterns         []string             `mapstructure:"patterns,omitempty"          json:"patterns,omitempty"          yaml:"patterns,omitempty"`
+	NamedPatterns    []NamedPatternConfig `mapstructure:"named_patterns,omitempty"    json:"named_patterns,omitempty"    yaml:"named_patterns,omitempty"`
+	Preset           string               `mapstructure:"preset,omitempty"            json:"preset,omitempty"            yaml:"preset,omitempty"`
+	PerformanceMode  bool                 `mapstructure:"performance_mode,omitempty"  json:"performance_mode,omitempty"  yaml:"performance_mode,omitempty"`
+	PatternAttribute string               `mapstructure:"pattern_attribute,omitempty" json:"pattern_attribute,omitempty" yaml:"pattern_attribute,omitempty"`
+	OnNoMatch        string               `mapstructure:"on_no_match,omitempty"       json:"on_no_match,omitempty"       yaml:"on_no_match,omitempty"`
+}
+
+// NamedPatternConfig is a regex of a regex parser, and the name which identifies it.
+type NamedPatternConfig struct {
+	Name  string `mapstructure:"name"  json:"name"  yaml:"name"`
 	Regex string `mapstructure:"regex" json:"regex" yaml:"regex"`
 }
 
 // Build will build a regex parser operator.
 func (c RegexParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	if c.Preset != "" {
+		if err := c.applyPreset(); err != nil {
+			return nil, err
+		}
+	}
+
 	parserOperator, err := c.ParserConfig.Build(context)
 	if err != nil {
 		return nil, err
 	}
 
-	if c.Regex == "" {
-		return nil, fmt.Errorf("missing required field 'regex'")
+	if c.Regex != "" && len(c.Patterns) > 0 {
+		return nil, fmt.Errorf("only one of 'regex' or 'patterns' can be set")
+	}
+	if len(c.NamedPatterns) > 0 && (c.Regex != "" || len(c.Patterns) > 0) {
+		return nil, fmt.Errorf("only one of 'regex', 'patterns' or 'named_patterns' can be set")
+	}
+	if c.PatternAttribute != "" && len(c.NamedPatterns) == 0 {
+		return nil, fmt.Errorf("'pattern_attribute' can only be used with 'named_patterns'")
+	}
+
+	configs := c.NamedPatterns
+	if len(configs) == 0 {
+		sources := c.Patterns
+		if len(sources) == 0 {
+			if c.Regex == "" {
+				return nil, fmt.Errorf("missing required field 'regex'")
+			}
+			sources = []string{c.Regex}
+		}
+		for _, source := range sources {
+			configs = append(configs, NamedPatternConfig{Regex: source})
+		}
+	}
+
+	names := map[string]bool{}
+	patterns := make([]pattern, 0, len(configs))
+	for i, config := range configs {
+		if len(c.NamedPatterns) > 0 {
+			if config.Name == "" {
+				return nil, fmt.Errorf("missing required field 'name' of named pattern %d", i)
+			}
+			if config.Regex == "" {
+				return nil, fmt.Errorf("missing required field 'regex' of named pattern %d", i)
+			}
+			if names[config.Name] {
+				return nil, fmt.Errorf("pattern name '%s' is used more than once", config.Name)
+			}
+			names[config.Name] = true
+		}
+
+		p, err := compilePattern(config.Name, config.Regex)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+
+	switch c.OnNoMatch {
+	case "":
+		c.OnNoMatch = ErrorOnNoMatch
+	case ErrorOnNoMatch, SendOnNoMatch, DropOnNoMatch:
+	default:
+		return nil, fmt.Errorf("invalid value '%s' for 'on_no_match', must be one of %s, %s or %s",
+			c.OnNoMatch, ErrorOnNoMatch, SendOnNoMatch, DropOnNoMatch)
+	}
+
+	regexParser := &RegexParser{
+		ParserOperator:   parserOperator,
+		patterns:         patterns,
+		performanceMode:  c.PerformanceMode,
+		patternAttribute: c.PatternAttribute,
+		onNoMatch:        c.OnNoMatch,
+	}
+
+	if c.PerformanceMode && len(patterns) > 1 && !allPrefixed(patterns) {
+		regexParser.gate, err = combinePatterns(patterns)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	r, err := regexp.Compile(c.Regex)
+	return []operator.Operator{regexParser}, nil
+}
+
+// pattern is a compiled regex of a regex parser.
+type pattern struct {
+	name   string
+	regexp *regexp.Regexp
+
+	// prefix is a literal which values must begin with to match, if the regex is anchored to the start of the value
+	prefix string
+}
+
+// compilePattern will compile a regex, which must contain named capture groups.
+func compilePattern(name, source string) (pattern, error) {
+	r, err := regexp.Compile(source)
 	if err != nil {
-		return nil, fmt.Errorf("compiling regex: %s", err)
+		return pattern{}, fmt.Errorf("compiling regex: %s", err)
 	}
 
 	namedCaptureGroups := 0
@@ -66,54 +174,186 @@ func (c RegexParserConfig) Build(context operator.BuildContext) ([]operator.Oper
 		}
 	}
 	if namedCaptureGroups == 0 {
-		return nil, errors.NewError(
+		return pattern{}, errors.NewError(
 			"no named capture groups in regex pattern",
 			"use named capture groups like '^(?P<my_key>.*)$' to specify the key name for the parsed field",
 		)
 	}
 
-	regexParser := &RegexParser{
-		ParserOperator: parserOperator,
-		regexp:         r,
+	return pattern{name: name, regexp: r, prefix: anchoredPrefix(source)}, nil
+}
+
+// anchoredPrefix will return the literal which a value must begin with to match a regex. Only
+// regexes which are anchored to the start of the value, such as '^GET (?P<path>.*)', have a prefix.
+func anchoredPrefix(source string) string {
+	re, err := syntax.Parse(source, syntax.Perl)
+	if err != nil || re.Op != syntax.OpConcat || len(re.Sub) < 2 || re.Sub[0].Op != syntax.OpBeginText {
+		return ""
 	}
 
-	return []operator.Operator{regexParser}, nil
+	var prefix strings.Builder
+	for _, sub := range re.Sub[1:] {
+		if sub.Op != syntax.OpLiteral || sub.Flags&syntax.FoldCase != 0 {
+			break
+		}
+		prefix.WriteString(string(sub.Rune))
+	}
+	return prefix.String()
+}
+
+// allPrefixed returns true if every pattern has an anchored prefix.
+func allPrefixed(patterns []pattern) bool {
+	for _, p := range patterns {
+		if p.prefix == "" {
+			return false
+		}
+	}
+	return true
 }
 
+// combinePatterns will combine patterns into a single regex which matches a value if any of them
+// do. Capture groups are removed from the combined regex, so that it may be matched without
+// tracking submatches, which is much cheaper than matching each pattern in turn.
+func combinePatterns(patterns []pattern) (*regexp.Regexp, error) {
+	alternatives := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := syntax.Parse(p.regexp.String(), syntax.Perl)
+		if err != nil {
+			return nil, fmt.Errorf("combining patterns: %s", err)
+		}
+		alternatives = append(alternatives, "(?:"+removeCaptures(re).String()+")")
+	}
+
+	combined, err := regexp.Compile(strings.Join(alternatives, "|"))
+	if err != nil {
+		return nil, fmt.Errorf("combining patterns: %s", err)
+	}
+	return combined, nil
+}
+
+// removeCaptures will replace the capture groups of a parsed regex with their contents.
+func removeCaptures(re *syntax.Regexp) *syntax.Regexp {
+	for i, sub := range re.Sub {
+		re.Sub[i] = removeCaptures(sub)
+	}
+	if re.Op == syntax.OpCapture {
+		return re.Sub[0]
+	}
+	return re
+}
+
+// errNoMatch is returned when a value matches none of the patterns of a regex parser.
+var errNoMatch = fmt.Errorf("regex pattern does not match")
+
 // RegexParser is an operator that parses regex in an entry.
 type RegexParser struct {
 	helper.ParserOperator
-	regexp *regexp.Regexp
+	patterns         []pattern
+	performanceMode  bool
+	patternAttribute string
+	onNoMatch        string
+
+	// gate matches a value if any of the patterns do, and is used in performance mode
+	// to reject values which match none of them without trying each pattern in turn
+	gate *regexp.Regexp
 }
 
 // Process will parse an entry for regex.
 func (r *RegexParser) Process(ctx context.Context, entry *entry.Entry) error {
-	return r.ParserOperator.ProcessWith(ctx, entry, r.parse)
+	if r.patternAttribute == "" && r.onNoMatch == ErrorOnNoMatch {
+		return r.ParserOperator.ProcessWith(ctx, entry, r.parse)
+	}
+
+	skip, err := r.Skip(ctx, entry)
+	if err != nil {
+		return r.HandleEntryError(ctx, entry, err)
+	}
+	if skip {
+		r.Write(ctx, entry)
+		return nil
+	}
+
+	// The value is matched before parsing, so that a value which matches none
+	// of the patterns can be handled without it being reported as an error
+	parse := r.parse
+	var matched *pattern
+	if value, ok := entry.Get(r.ParseFrom); ok {
+		p, values, err := r.match(value)
+		switch {
+		case err == errNoMatch && r.onNoMatch == SendOnNoMatch:
+			r.Write(ctx, entry)
+			return nil
+		case err == errNoMatch && r.onNoMatch == DropOnNoMatch:
+			return nil
+		}
+		matched = p
+		parse = func(interface{}) (interface{}, error) {
+			if err != nil {
+				return nil, err
+			}
+			return values, nil
+		}
+	}
+
+	if err := r.ParseWith(ctx, entry, parse); err != nil {
+		return err
+	}
+	if r.patternAttribute != "" && matched != nil {
+		entry.AddAttribute(r.patternAttribute, matched.name)
+	}
+	r.Write(ctx, entry)
+	return nil
 }
 
-// parse will parse a value using the supplied regex.
+// parse will parse a value using the supplied regex. When there are multiple
+// patterns, the values of the first pattern which matches are used.
 func (r *RegexParser) parse(value interface{}) (interface{}, error) {
-	var matches []string
-	switch m := value.(type) {
+	_, values, err := r.match(value)
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// match will return the first pattern which matches a value, and the values of its named capture groups.
+func (r *RegexParser) match(value interface{}) (*pattern, map[string]interface{}, error) {
+	var m string
+	switch v := value.(type) {
 	case string:
-		matches = r.regexp.FindStringSubmatch(m)
-		if matches == nil {
-			return nil, fmt.Errorf("regex pattern does not match")
-		}
+		m = v
 	default:
-		return nil, fmt.Errorf("type '%T' cannot be parsed as regex", value)
+		return nil, nil, fmt.Errorf("type '%T' cannot be parsed as regex", value)
 	}
 
-	parsedValues := map[string]interface{}{}
-	for i, subexp := range r.regexp.SubexpNames() {
-		if i == 0 {
-			// Skip whole match
+	if r.gate != nil && !r.gate.MatchString(m) {
+		return nil, nil, errNoMatch
+	}
+
+	for i := range r.patterns {
+		p := &r.patterns[i]
+		if r.performanceMode && !strings.HasPrefix(m, p.prefix) {
 			continue
 		}
-		if subexp != "" {
-			parsedValues[subexp] = matches[i]
+		if matches := p.regexp.FindStringSubmatchIndex(m); matches != nil {
+			return p, parsedValues(m, p.regexp.SubexpNames(), matches), nil
 		}
 	}
+	return nil, nil, errNoMatch
+}
 
-	return parsedValues, nil
+// parsedValues will return the values of the named capture groups of a match.
+// A group which did not participate in the match has an empty value.
+func parsedValues(m string, names []string, matches []int) map[string]interface{} {
+	values := map[string]interface{}{}
+	for i, name := range names {
+		if name == "" {
+			continue
+		}
+		if matches[2*i] < 0 {
+			values[name] = ""
+			continue
+		}
+		values[name] = m[matches[2*i]:matches[2*i+1]]
+	}
+	return values
 }
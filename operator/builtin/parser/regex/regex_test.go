@@ -16,7 +16,9 @@ package regex
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
@@ -187,3 +189,317 @@ parse_to: $.to`
 		require.Equal(t, expect, &actual)
 	})
 }
+
+func TestRegexParserPatterns(t *testing.T) {
+	patterns := []string{
+		`^GET (?P<path>\S+) (?P<status>\d+)$`,
+		`^POST (?P<path>\S+) (?P<status>\d+) (?P<size>\d+)$`,
+		`(?P<message>error: .*)$`,
+		`(?i)(?P<level>warn)(?:ing)?: (?P<message>.*)$`,
+	}
+
+	cases := []struct {
+		name      string
+		input     string
+		expected  map[string]interface{}
+		expectErr bool
+	}{
+		{
+			"First",
+			"GET /index.html 200",
+			map[string]interface{}{"path": "/index.html", "status": "200"},
+			false,
+		},
+		{
+			"Second",
+			"POST /api 201 512",
+			map[string]interface{}{"path": "/api", "status": "201", "size": "512"},
+			false,
+		},
+		{
+			"Unanchored",
+			"request failed with error: timeout",
+			map[string]interface{}{"message": "error: timeout"},
+			false,
+		},
+		{
+			"CaseInsensitive",
+			"disk usage WARNING: 91%",
+			map[string]interface{}{"level": "WARN", "message": "91%"},
+			false,
+		},
+		{
+			"NoMatch",
+			"PUT /api 200",
+			nil,
+			true,
+		},
+	}
+
+	for _, performanceMode := range []bool{false, true} {
+		for _, tc := range cases {
+			t.Run(fmt.Sprintf("%s/performance_mode=%t", tc.name, performanceMode), func(t *testing.T) {
+				cfg := NewRegexParserConfig("test")
+				cfg.Patterns = patterns
+				cfg.PerformanceMode = performanceMode
+				ops, err := cfg.Build(testutil.NewBuildContext(t))
+				require.NoError(t, err)
+				parser := ops[0].(*RegexParser)
+				require.Equal(t, performanceMode, parser.gate != nil)
+
+				parsed, err := parser.parse(tc.input)
+				if tc.expectErr {
+					require.Error(t, err)
+					require.Contains(t, err.Error(), "regex pattern does not match")
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, parsed)
+			})
+		}
+	}
+}
+
+func TestRegexParserPerformanceModePrefix(t *testing.T) {
+	cfg := NewRegexParserConfig("test")
+	cfg.Patterns = []string{`^GET (?P<path>\S+)$`, `^POST (?P<path>\S+)$`}
+	cfg.PerformanceMode = true
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*RegexParser)
+	require.Nil(t, parser.gate)
+
+	_, err = parser.parse("DELETE /api")
+	require.Error(t, err)
+
+	parsed, err := parser.parse("POST /api")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"path": "/api"}, parsed)
+}
+
+func TestRegexParserPatternsBuildFailure(t *testing.T) {
+	t.Run("RegexAndPatterns", func(t *testing.T) {
+		cfg := NewRegexParserConfig("test")
+		cfg.Regex = "^(?P<message>.*)$"
+		cfg.Patterns = []string{"^(?P<message>.*)$"}
+		_, err := cfg.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "only one of 'regex' or 'patterns' can be set")
+	})
+
+	t.Run("PatternWithoutNamedGroups", func(t *testing.T) {
+		cfg := NewRegexParserConfig("test")
+		cfg.Patterns = []string{"^(?P<message>.*)$", "^(.*)$"}
+		_, err := cfg.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no named capture groups")
+	})
+
+	t.Run("InvalidPattern", func(t *testing.T) {
+		cfg := NewRegexParserConfig("test")
+		cfg.Patterns = []string{"^(?P<message>.*$"}
+		_, err := cfg.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "compiling regex")
+	})
+}
+
+func TestAnchoredPrefix(t *testing.T) {
+	cases := []struct {
+		regex    string
+		expected string
+	}{
+		{`^GET (?P<path>\S+)`, "GET "},
+		{`^(?P<path>\S+)`, ""},
+		{`GET (?P<path>\S+)`, ""},
+		{`(?i)^get (?P<path>\S+)`, ""},
+		{`(?m)^GET (?P<path>\S+)`, ""},
+		{`^\[(?P<time>[^\]]+)\]`, "["},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.regex, func(t *testing.T) {
+			require.Equal(t, tc.expected, anchoredPrefix(tc.regex))
+		})
+	}
+}
+
+func TestRegexParserNamedPatterns(t *testing.T) {
+	patterns := []NamedPatternConfig{
+		{Name: "access", Regex: `^(?P<method>GET|POST) (?P<path>\S+)$`},
+		{Name: "error", Regex: `^ERROR (?P<message>.*)$`},
+		{Name: "kv", Regex: `(?P<key>\w+)=(?P<value>\w+)`},
+	}
+
+	cases := []struct {
+		name           string
+		onNoMatch      string
+		input          string
+		expectEntry    bool
+		expectedBody   interface{}
+		expectedFormat string
+	}{
+		{"Access", "", "GET /index.html", true, map[string]interface{}{"method": "GET", "path": "/index.html"}, "access"},
+		{"Error", "", "ERROR disk full", true, map[string]interface{}{"message": "disk full"}, "error"},
+		{"KeyValue", "", "user=alice", true, map[string]interface{}{"key": "user", "value": "alice"}, "kv"},
+		{"NoMatchError", ErrorOnNoMatch, "PUT /index.html", true, "PUT /index.html", ""},
+		{"NoMatchSend", SendOnNoMatch, "PUT /index.html", true, "PUT /index.html", ""},
+		{"NoMatchDrop", DropOnNoMatch, "PUT /index.html", false, nil, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRegexParserConfig("test")
+			cfg.OutputIDs = []string{"fake"}
+			cfg.NamedPatterns = patterns
+			cfg.PatternAttribute = "log.format"
+			cfg.OnNoMatch = tc.onNoMatch
+
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			fake := testutil.NewFakeOutput(t)
+			require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+			e := entry.New()
+			e.Body = tc.input
+			require.NoError(t, op.Process(context.Background(), e))
+
+			if !tc.expectEntry {
+				fake.ExpectNoEntry(t, 100*time.Millisecond)
+				return
+			}
+
+			select {
+			case received := <-fake.Received:
+				require.Equal(t, tc.expectedBody, received.Body)
+				if tc.expectedFormat == "" {
+					require.NotContains(t, received.Attributes, "log.format")
+				} else {
+					require.Equal(t, tc.expectedFormat, received.Attributes["log.format"])
+				}
+			case <-time.After(time.Second):
+				require.FailNow(t, "Timed out waiting for entry")
+			}
+		})
+	}
+}
+
+func TestRegexParserNamedPatternsBuildFailure(t *testing.T) {
+	cases := []struct {
+		name      string
+		configure func(*RegexParserConfig)
+		expected  string
+	}{
+		{
+			"DuplicateName",
+			func(cfg *RegexParserConfig) {
+				cfg.NamedPatterns = []NamedPatternConfig{
+					{Name: "a", Regex: "^(?P<x>a)$"},
+					{Name: "a", Regex: "^(?P<x>b)$"},
+				}
+			},
+			"pattern name 'a' is used more than once",
+		},
+		{
+			"AttributeWithoutNamedPatterns",
+			func(cfg *RegexParserConfig) {
+				cfg.Patterns = []string{"^(?P<x>a)$", "^(?P<x>b)$"}
+				cfg.PatternAttribute = "log.format"
+			},
+			"'pattern_attribute' can only be used with 'named_patterns'",
+		},
+		{
+			"PatternsAndNamedPatterns",
+			func(cfg *RegexParserConfig) {
+				cfg.Patterns = []string{"^(?P<x>a)$"}
+				cfg.NamedPatterns = []NamedPatternConfig{{Name: "b", Regex: "^(?P<x>b)$"}}
+			},
+			"only one of 'regex', 'patterns' or 'named_patterns' can be set",
+		},
+		{
+			"MissingName",
+			func(cfg *RegexParserConfig) {
+				cfg.NamedPatterns = []NamedPatternConfig{{Name: "a", Regex: "^(?P<x>a)$"}, {Regex: "^(?P<x>b)$"}}
+			},
+			"missing required field 'name' of named pattern 1",
+		},
+		{
+			"MissingRegex",
+			func(cfg *RegexParserConfig) {
+				cfg.NamedPatterns = []NamedPatternConfig{{Name: "a"}}
+			},
+			"missing required field 'regex' of named pattern 0",
+		},
+		{
+			"InvalidOnNoMatch",
+			func(cfg *RegexParserConfig) {
+				cfg.Regex = "^(?P<x>a)$"
+				cfg.OnNoMatch = "ignore"
+			},
+			"invalid value 'ignore' for 'on_no_match'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRegexParserConfig("test")
+			tc.configure(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func benchmarkPatterns() []string {
+	return []string{
+		`^GET (?P<path>\S+) HTTP/(?P<version>\S+) (?P<status>\d+) (?P<duration>\d+)ms$`,
+		`^POST (?P<path>\S+) HTTP/(?P<version>\S+) (?P<status>\d+) (?P<duration>\d+)ms$`,
+		`^PUT (?P<path>\S+) HTTP/(?P<version>\S+) (?P<status>\d+) (?P<duration>\d+)ms$`,
+		`^DELETE (?P<path>\S+) HTTP/(?P<version>\S+) (?P<status>\d+) (?P<duration>\d+)ms$`,
+	}
+}
+
+func benchmarkUnanchoredPatterns() []string {
+	return []string{
+		`user=(?P<user>\w+) action=login status=(?P<status>\w+)`,
+		`user=(?P<user>\w+) action=logout duration=(?P<duration>\d+)`,
+		`user=(?P<user>\w+) action=upload size=(?P<size>\d+)`,
+		`user=(?P<user>\w+) action=delete file=(?P<file>\S+)`,
+	}
+}
+
+func BenchmarkRegexParserPatterns(b *testing.B) {
+	cases := []struct {
+		name     string
+		patterns []string
+		input    string
+	}{
+		{"anchored/last", benchmarkPatterns(), "DELETE /api/v1/users/123 HTTP/1.1 204 12ms"},
+		{"anchored/no_match", benchmarkPatterns(), "PATCH /api/v1/users/123 HTTP/1.1 200 15ms"},
+		{"unanchored/last", benchmarkUnanchoredPatterns(), "2021-06-01T12:00:00Z host=web1 user=alice action=delete file=/tmp/report.csv"},
+		{"unanchored/no_match", benchmarkUnanchoredPatterns(), "2021-06-01T12:00:00Z host=web1 user=alice action=rename file=/tmp/report.csv"},
+	}
+
+	for _, performanceMode := range []bool{false, true} {
+		for _, tc := range cases {
+			input := tc.input
+			b.Run(fmt.Sprintf("%s/performance_mode=%t", tc.name, performanceMode), func(b *testing.B) {
+				cfg := NewRegexParserConfig("bench")
+				cfg.Patterns = tc.patterns
+				cfg.PerformanceMode = performanceMode
+				ops, err := cfg.Build(testutil.NewBuildContext(b))
+				require.NoError(b, err)
+				parser := ops[0].(*RegexParser)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					_, _ = parser.parse(input)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+)
+
+// wellKnownAttributes are the attribute names of the parameters of the SD-IDs defined by RFC 5424.
+var wellKnownAttributes = map[string]map[string]string{
+	"timeQuality": {
+		"tzKnown":      "syslog.time_quality.tz_known",
+		"isSynced":     "syslog.time_quality.is_synced",
+		"syncAccuracy": "syslog.time_quality.sync_accuracy",
+	},
+	"origin": {
+		"ip":           "host.ip",
+		"enterpriseId": "syslog.origin.enterprise_id",
+		"software":     "service.name",
+		"swVersion":    "service.version",
+	},
+	"meta": {
+		"sequenceId": "syslog.meta.sequence_id",
+		"sysUpTime":  "syslog.meta.sys_up_time",
+		"language":   "syslog.meta.language",
+	},
+}
+
+var structuredDataField = entry.NewBodyField("structured_data")
+
+// StructuredDataConfig is the configuration of how the structured data of an RFC 5424 message is mapped.
+type StructuredDataConfig struct {
+	Flatten      []string `mapstructure:"flatten,omitempty"        json:"flatten,omitempty"        yaml:"flatten,omitempty"`
+	Drop         []string `mapstructure:"drop,omitempty"           json:"drop,omitempty"           yaml:"drop,omitempty"`
+	MapWellKnown bool     `mapstructure:"map_well_known,omitempty" json:"map_well_known,omitempty" yaml:"map_well_known,omitempty"`
+}
+
+// build will build a structured data mapper. If the config does not change the structured data, nil is returned.
+func (c StructuredDataConfig) build() (*structuredDataMapper, error) {
+	if len(c.Flatten) == 0 && len(c.Drop) == 0 && !c.MapWellKnown {
+		return nil, nil
+	}
+
+	m := &structuredDataMapper{
+		flatten:      make(map[string]bool, len(c.Flatten)),
+		drop:         make(map[string]bool, len(c.Drop)),
+		mapWellKnown: c.MapWellKnown,
+	}
+	for _, id := range c.Drop {
+		m.drop[id] = true
+	}
+	for _, id := range c.Flatten {
+		if m.drop[id] {
+			return nil, fmt.Errorf("SD-ID '%s' cannot be both flattened and dropped", id)
+		}
+		m.flatten[id] = true
+	}
+	return m, nil
+}
+
+// structuredDataMapper moves the elements of structured data to attributes, or removes them.
+type structuredDataMapper struct {
+	flatten      map[string]bool
+	drop         map[string]bool
+	mapWellKnown bool
+}
+
+// apply will map the structured data of an entry. Dropped elements are removed first. Then the
+// parameters of well-known elements are written to their attributes, and the parameters of
+// flattened elements are written to attributes named '<SD-ID>.<parameter>'. The structured data
+// field is removed if no elements remain.
+func (m *structuredDataMapper) apply(e *entry.Entry) {
+	value, ok := e.Get(structuredDataField)
+	if !ok {
+		return
+	}
+	structuredData, ok := value.(map[string]map[string]string)
+	if !ok {
+		return
+	}
+
+	for id, params := range structuredData {
+		if m.drop[id] {
+			delete(structuredData, id)
+			continue
+		}
+
+		if names, ok := wellKnownAttributes[id]; ok && m.mapWellKnown {
+			for param, value := range params {
+				if name, ok := names[param]; ok {
+					e.AddAttribute(name, value)
+					delete(params, param)
+				}
+			}
+		}
+
+		if m.flatten[id] {
+			for param, value := range params {
+				e.AddAttribute(id+"."+param, value)
+			}
+			params = nil
+		}
+
+		if len(params) == 0 {
+			delete(structuredData, id)
+		}
+	}
+
+	if len(structuredData) == 0 {
+		e.Delete(structuredDataField)
+	}
+}
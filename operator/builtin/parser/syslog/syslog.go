@@ -17,6 +17,7 @@ package syslog
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	sl "github.com/observiq/go-syslog/v3"
@@ -31,6 +32,15 @@ import (
 const RFC3164 = "rfc3164"
 const RFC5424 = "rfc5424"
 
+// These are the modes of a syslog parser.
+const (
+	StrictMode   = "strict"
+	TolerantMode = "tolerant"
+)
+
+// MissingAttribute is the attribute to which the components missing from a message are written in tolerant mode.
+const MissingAttribute = "syslog.missing"
+
 func init() {
 	operator.Register("syslog_parser", func() operator.Builder { return NewSyslogParserConfig("") })
 }
@@ -48,11 +58,15 @@ type SyslogParserConfig struct {
 
 	Protocol string `mapstructure:"protocol,omitempty" json:"protocol,omitempty" yaml:"protocol,omitempty"`
 	Location string `mapstructure:"location,omitempty" json:"location,omitempty" yaml:"location,omitempty"`
+	Mode     string `mapstructure:"mode,omitempty"     json:"mode,omitempty"     yaml:"mode,omitempty"`
+
+	StructuredData StructuredDataConfig `mapstructure:"structured_data,omitempty" json:"structured_data,omitempty" yaml:"structured_data,omitempty"`
 }
 
 // Build will build a JSON parser operator.
 func (c SyslogParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
-	if c.ParserConfig.TimeParser == nil {
+	defaultTimeParser := c.ParserConfig.TimeParser == nil
+	if defaultTimeParser {
 		parseFromField := entry.NewBodyField("timestamp")
 		c.ParserConfig.TimeParser = &helper.TimeParser{
 			ParseFrom:  &parseFromField,
@@ -78,10 +92,31 @@ func (c SyslogParserConfig) Build(context operator.BuildContext) ([]operator.Ope
 		return nil, err
 	}
 
+	switch c.Mode {
+	case "":
+		c.Mode = StrictMode
+	case StrictMode, TolerantMode:
+	default:
+		return nil, fmt.Errorf("invalid mode '%s', must be one of %s or %s", c.Mode, StrictMode, TolerantMode)
+	}
+
+	structuredData, err := c.StructuredData.build()
+	if err != nil {
+		return nil, err
+	}
+
 	syslogParser := &SyslogParser{
 		ParserOperator: parserOperator,
 		protocol:       c.Protocol,
 		location:       location,
+		mode:           c.Mode,
+		structuredData: structuredData,
+	}
+
+	// In tolerant mode, the default timestamp parser is only used when a timestamp is recognized
+	if c.Mode == TolerantMode && defaultTimeParser {
+		syslogParser.timeParser = syslogParser.TimeParser
+		syslogParser.TimeParser = nil
 	}
 
 	return []operator.Operator{syslogParser}, nil
@@ -103,11 +138,88 @@ type SyslogParser struct {
 	helper.ParserOperator
 	protocol string
 	location *time.Location
+	mode     string
+
+	// structuredData maps the structured data of RFC 5424 messages, if configured
+	structuredData *structuredDataMapper
+
+	// timeParser parses the timestamp of a message in tolerant mode, if it has one
+	timeParser *helper.TimeParser
 }
 
 // Process will parse an entry field as syslog.
 func (s *SyslogParser) Process(ctx context.Context, entry *entry.Entry) error {
-	return s.ParserOperator.ProcessWithCallback(ctx, entry, s.parse, promoteSeverity)
+	if s.mode == TolerantMode {
+		return s.processTolerant(ctx, entry)
+	}
+	return s.ParserOperator.ProcessWithCallback(ctx, entry, s.parse, s.promote)
+}
+
+// promote will map the structured data of an entry, and promote its severity.
+func (s *SyslogParser) promote(e *entry.Entry) error {
+	if s.structuredData != nil {
+		s.structuredData.apply(e)
+	}
+	return promoteSeverity(e)
+}
+
+// processTolerant will parse an entry field as syslog, extracting whatever components of the message
+// can be recognized. The components which are missing are written to the MissingAttribute attribute.
+func (s *SyslogParser) processTolerant(ctx context.Context, e *entry.Entry) error {
+	var missing []string
+	parse := func(value interface{}) (interface{}, error) {
+		parsed, err := s.parseTolerant(value)
+		if err != nil {
+			return nil, err
+		}
+		missing = missingComponents(parsed)
+		return parsed, nil
+	}
+
+	return s.ParserOperator.ProcessWithCallback(ctx, e, parse, func(e *entry.Entry) error {
+		if len(missing) > 0 {
+			e.AddAttribute(MissingAttribute, strings.Join(missing, ","))
+		}
+		if s.structuredData != nil {
+			s.structuredData.apply(e)
+		}
+		if s.timeParser != nil {
+			if _, ok := e.Get(s.timeParser.ParseFrom); ok {
+				if err := s.timeParser.Parse(e); err != nil {
+					return err
+				}
+			}
+		}
+		if _, ok := e.Get(severityField); ok {
+			return promoteSeverity(e)
+		}
+		return nil
+	})
+}
+
+// parseTolerant will parse a value as syslog, falling back to extracting the components
+// which can be recognized if the value is not valid for the protocol.
+func (s *SyslogParser) parseTolerant(value interface{}) (map[string]interface{}, error) {
+	bytes, err := toBytes(value)
+	if err != nil {
+		return nil, err
+	}
+
+	machine, err := buildMachine(s.protocol, s.location)
+	if err != nil {
+		return nil, err
+	}
+
+	if slog, err := machine.Parse(bytes); err == nil {
+		switch message := slog.(type) {
+		case *rfc3164.SyslogMessage:
+			return s.parseRFC3164(message)
+		case *rfc5424.SyslogMessage:
+			return s.parseRFC5424(message)
+		}
+	}
+
+	return parseTolerant(string(bytes), s.location), nil
 }
 
 // parse will parse a value as syslog.
@@ -69,14 +69,26 @@ func TestSyslogParser(t *testing.T) {
 func TestSyslogParserConfig(t *testing.T) {
 	expect := NewSyslogParserConfig("test")
 	expect.Protocol = RFC3164
+	expect.Mode = TolerantMode
+	expect.StructuredData = StructuredDataConfig{
+		Flatten:      []string{"exampleSDID@32473"},
+		Drop:         []string{"meta"},
+		MapWellKnown: true,
+	}
 	expect.ParseFrom = entry.NewBodyField("from")
 	expect.ParseTo = entry.NewBodyField("to")
 
 	t.Run("mapstructure", func(t *testing.T) {
 		input := map[string]interface{}{
-			"id":         "test",
-			"type":       "syslog_parser",
-			"protocol":   RFC3164,
+			"id":       "test",
+			"type":     "syslog_parser",
+			"protocol": RFC3164,
+			"mode":     TolerantMode,
+			"structured_data": map[string]interface{}{
+				"flatten":        []interface{}{"exampleSDID@32473"},
+				"drop":           []interface{}{"meta"},
+				"map_well_known": true,
+			},
 			"parse_from": "$.from",
 			"parse_to":   "$.to",
 			"on_error":   "send",
@@ -93,6 +105,11 @@ type: syslog_parser
 id: test
 on_error: "send"
 protocol: rfc3164
+mode: tolerant
+structured_data:
+  flatten: [exampleSDID@32473]
+  drop: [meta]
+  map_well_known: true
 parse_from: $.from
 parse_to: $.to`
 		var actual SyslogParserConfig
@@ -101,3 +118,256 @@ parse_to: $.to`
 		require.Equal(t, expect, &actual)
 	})
 }
+
+func TestSyslogParserTolerant(t *testing.T) {
+	cases := []struct {
+		name              string
+		input             string
+		expectedBody      map[string]interface{}
+		expectedTimestamp string
+		expectedSeverity  entry.Severity
+		expectedMissing   string
+	}{
+		{
+			"Valid",
+			"<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8",
+			map[string]interface{}{
+				"priority": 34,
+				"facility": 4,
+				"hostname": "mymachine",
+				"appname":  "su",
+				"message":  "'su root' failed for lonvick on /dev/pts/8",
+			},
+			"Oct 11 22:14:15",
+			entry.Critical,
+			"",
+		},
+		{
+			"MissingPriority",
+			"Oct 11 22:14:15 mymachine su: 'su root' failed",
+			map[string]interface{}{
+				"hostname": "mymachine",
+				"appname":  "su",
+				"message":  "'su root' failed",
+			},
+			"Oct 11 22:14:15",
+			entry.Default,
+			"priority",
+		},
+		{
+			"RFC3339TimestampWithoutHostname",
+			"<13>2021-06-01T12:00:00Z sshd[123]: Accepted publickey for alice",
+			map[string]interface{}{
+				"priority": 13,
+				"facility": 1,
+				"appname":  "sshd",
+				"proc_id":  "123",
+				"message":  "Accepted publickey for alice",
+			},
+			"Jun  1 12:00:00",
+			entry.Notice,
+			"hostname",
+		},
+		{
+			"MissingTimestamp",
+			"<11>router kernel: eth0 link down",
+			map[string]interface{}{
+				"priority": 11,
+				"facility": 1,
+				"hostname": "router",
+				"appname":  "kernel",
+				"message":  "eth0 link down",
+			},
+			"",
+			entry.Error,
+			"timestamp",
+		},
+		{
+			"Unstructured",
+			"something happened",
+			map[string]interface{}{
+				"message": "something happened",
+			},
+			"",
+			entry.Default,
+			"priority,timestamp,hostname,appname",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewSyslogParserConfig("test_operator_id")
+			cfg.OutputIDs = []string{"fake"}
+			cfg.Protocol = RFC3164
+			cfg.Mode = TolerantMode
+
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			fake := testutil.NewFakeOutput(t)
+			require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+			newEntry := entry.New()
+			originalTimestamp := newEntry.Timestamp
+			newEntry.Body = tc.input
+			require.NoError(t, op.Process(context.Background(), newEntry))
+
+			select {
+			case e := <-fake.Received:
+				require.Equal(t, tc.expectedBody, e.Body)
+				require.Equal(t, tc.expectedSeverity, e.Severity)
+				if tc.expectedTimestamp == "" {
+					require.Equal(t, originalTimestamp, e.Timestamp)
+				} else {
+					require.Equal(t, tc.expectedTimestamp, e.Timestamp.Format(time.Stamp))
+				}
+				if tc.expectedMissing == "" {
+					require.NotContains(t, e.Attributes, MissingAttribute)
+				} else {
+					require.Equal(t, tc.expectedMissing, e.Attributes[MissingAttribute])
+				}
+			case <-time.After(time.Second):
+				require.FailNow(t, "Timed out waiting for entry to be processed")
+			}
+		})
+	}
+}
+
+func TestSyslogParserInvalidMode(t *testing.T) {
+	cfg := NewSyslogParserConfig("test_operator_id")
+	cfg.Protocol = RFC3164
+	cfg.Mode = "lenient"
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid mode 'lenient'")
+}
+
+func TestSyslogParserStructuredData(t *testing.T) {
+	input := `<86>1 2015-08-05T21:58:59.693Z 192.168.2.132 SecureAuth0 23108 ID52020 ` +
+		`[exampleSDID@32473 iut="3" eventSource="Application"]` +
+		`[origin ip="192.0.2.1" software="sshd" swVersion="8.4" x-custom="a"]` +
+		`[timeQuality tzKnown="1" isSynced="0"]` +
+		`[meta sequenceId="7"] message`
+
+	cases := []struct {
+		name               string
+		config             StructuredDataConfig
+		expectedData       interface{}
+		expectedAttributes map[string]string
+	}{
+		{
+			"Default",
+			StructuredDataConfig{},
+			map[string]map[string]string{
+				"exampleSDID@32473": {"iut": "3", "eventSource": "Application"},
+				"origin":            {"ip": "192.0.2.1", "software": "sshd", "swVersion": "8.4", "x-custom": "a"},
+				"timeQuality":       {"tzKnown": "1", "isSynced": "0"},
+				"meta":              {"sequenceId": "7"},
+			},
+			nil,
+		},
+		{
+			"Flatten",
+			StructuredDataConfig{Flatten: []string{"exampleSDID@32473"}},
+			map[string]map[string]string{
+				"origin":      {"ip": "192.0.2.1", "software": "sshd", "swVersion": "8.4", "x-custom": "a"},
+				"timeQuality": {"tzKnown": "1", "isSynced": "0"},
+				"meta":        {"sequenceId": "7"},
+			},
+			map[string]string{
+				"exampleSDID@32473.iut":         "3",
+				"exampleSDID@32473.eventSource": "Application",
+			},
+		},
+		{
+			"Drop",
+			StructuredDataConfig{Drop: []string{"meta", "timeQuality"}},
+			map[string]map[string]string{
+				"exampleSDID@32473": {"iut": "3", "eventSource": "Application"},
+				"origin":            {"ip": "192.0.2.1", "software": "sshd", "swVersion": "8.4", "x-custom": "a"},
+			},
+			nil,
+		},
+		{
+			"MapWellKnown",
+			StructuredDataConfig{MapWellKnown: true},
+			map[string]map[string]string{
+				"exampleSDID@32473": {"iut": "3", "eventSource": "Application"},
+				"origin":            {"x-custom": "a"},
+			},
+			map[string]string{
+				"host.ip":                       "192.0.2.1",
+				"service.name":                  "sshd",
+				"service.version":               "8.4",
+				"syslog.time_quality.tz_known":  "1",
+				"syslog.time_quality.is_synced": "0",
+				"syslog.meta.sequence_id":       "7",
+			},
+		},
+		{
+			"All",
+			StructuredDataConfig{
+				Flatten:      []string{"exampleSDID@32473", "origin"},
+				Drop:         []string{"meta", "timeQuality"},
+				MapWellKnown: true,
+			},
+			nil,
+			map[string]string{
+				"exampleSDID@32473.iut":         "3",
+				"exampleSDID@32473.eventSource": "Application",
+				"host.ip":                       "192.0.2.1",
+				"service.name":                  "sshd",
+				"service.version":               "8.4",
+				"origin.x-custom":               "a",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewSyslogParserConfig("test_operator_id")
+			cfg.OutputIDs = []string{"fake"}
+			cfg.Protocol = RFC5424
+			cfg.StructuredData = tc.config
+
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			fake := testutil.NewFakeOutput(t)
+			require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+			newEntry := entry.New()
+			newEntry.Body = input
+			require.NoError(t, op.Process(context.Background(), newEntry))
+
+			select {
+			case e := <-fake.Received:
+				data, ok := e.Body.(map[string]interface{})["structured_data"]
+				if tc.expectedData == nil {
+					require.False(t, ok)
+				} else {
+					require.Equal(t, tc.expectedData, data)
+				}
+				if tc.expectedAttributes == nil {
+					require.Empty(t, e.Attributes)
+				} else {
+					require.Equal(t, tc.expectedAttributes, e.Attributes)
+				}
+			case <-time.After(time.Second):
+				require.FailNow(t, "Timed out waiting for entry to be processed")
+			}
+		})
+	}
+}
+
+func TestSyslogParserStructuredDataFlattenAndDrop(t *testing.T) {
+	cfg := NewSyslogParserConfig("test_operator_id")
+	cfg.Protocol = RFC5424
+	cfg.StructuredData.Flatten = []string{"meta"}
+	cfg.StructuredData.Drop = []string{"meta"}
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "SD-ID 'meta' cannot be both flattened and dropped")
+}
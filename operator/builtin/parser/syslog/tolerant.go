@@ -0,0 +1,145 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tolerantTimestampLayouts are the layouts of the timestamps recognized in tolerant mode, in the order they are tried.
+var tolerantTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"Jan _2 2006 15:04:05",
+	"Jan _2 15:04:05",
+}
+
+// tagPattern matches the tag of a message, which is an app name optionally followed by a process id, such as 'sshd[123]:'
+var tagPattern = regexp.MustCompile(`^([^\s\[\]:]+)(?:\[([^\s\[\]]*)\]:?|:)(?:\s+|$)`)
+
+// tolerantComponents are the components which are reported as missing when they cannot be recognized in tolerant mode.
+var tolerantComponents = []string{"priority", "timestamp", "hostname", "appname"}
+
+// parseTolerant will extract whatever components of a syslog message can be recognized. The
+// priority, timestamp, hostname and tag are each optional, and the remainder is the message.
+// A hostname is only recognized when it is followed by a tag, so that the first word of a
+// message is not mistaken for a hostname.
+func parseTolerant(value string, location *time.Location) map[string]interface{} {
+	parsed := map[string]interface{}{}
+	rest := value
+
+	if priority, n, ok := parsePriority(rest); ok {
+		parsed["priority"] = priority
+		parsed["facility"] = priority / 8
+		parsed["severity"] = priority % 8
+		rest = rest[n:]
+	}
+	rest = strings.TrimLeft(rest, " ")
+
+	if timestamp, n, ok := parseTimestamp(rest, location); ok {
+		parsed["timestamp"] = timestamp
+		rest = strings.TrimLeft(rest[n:], " ")
+	}
+
+	if m := tagPattern.FindStringSubmatchIndex(rest); m != nil {
+		rest = setTag(parsed, rest, m)
+	} else if i := strings.IndexByte(rest, ' '); i > 0 {
+		hostname, remainder := rest[:i], strings.TrimLeft(rest[i:], " ")
+		if m := tagPattern.FindStringSubmatchIndex(remainder); m != nil {
+			parsed["hostname"] = hostname
+			rest = setTag(parsed, remainder, m)
+		}
+	}
+
+	if rest != "" {
+		parsed["message"] = rest
+	}
+	return parsed
+}
+
+// setTag will set the app name and process id of a matched tag, and return the remainder of the value.
+func setTag(parsed map[string]interface{}, value string, m []int) string {
+	parsed["appname"] = value[m[2]:m[3]]
+	if m[4] >= 0 && m[5] > m[4] {
+		parsed["proc_id"] = value[m[4]:m[5]]
+	}
+	return value[m[1]:]
+}
+
+// parsePriority will parse a priority in the form '<PRI>', returning its length.
+func parsePriority(value string) (int, int, bool) {
+	if !strings.HasPrefix(value, "<") {
+		return 0, 0, false
+	}
+	end := strings.IndexByte(value, '>')
+	if end < 2 || end > 4 {
+		return 0, 0, false
+	}
+	priority, err := strconv.Atoi(value[1:end])
+	if err != nil || priority < 0 || priority > 191 {
+		return 0, 0, false
+	}
+	return priority, end + 1, true
+}
+
+// parseTimestamp will parse a timestamp at the start of a value, returning its length.
+func parseTimestamp(value string, location *time.Location) (time.Time, int, bool) {
+	for _, layout := range tolerantTimestampLayouts {
+		prefix, n, ok := leadingFields(value, strings.Count(layout, " ")+1)
+		if !ok {
+			continue
+		}
+		if timestamp, err := time.ParseInLocation(layout, prefix, location); err == nil {
+			return timestamp, n, true
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+// leadingFields will return the first count space separated fields of a value, joined by single
+// spaces, and the length of the value which they span.
+func leadingFields(value string, count int) (string, int, bool) {
+	fields := make([]string, 0, count)
+	i := 0
+	for len(fields) < count {
+		for i < len(value) && value[i] == ' ' {
+			i++
+		}
+		start := i
+		for i < len(value) && value[i] != ' ' {
+			i++
+		}
+		if start == i {
+			return "", 0, false
+		}
+		fields = append(fields, value[start:i])
+	}
+	return strings.Join(fields, " "), i, true
+}
+
+// missingComponents will return the components which are missing from a parsed message.
+func missingComponents(parsed map[string]interface{}) []string {
+	var missing []string
+	for _, component := range tolerantComponents {
+		if _, ok := parsed[component]; !ok {
+			missing = append(missing, component)
+		}
+	}
+	return missing
+}
@@ -156,3 +156,31 @@ func TestTraceParserParse(t *testing.T) {
 		})
 	}
 }
+
+func TestTraceParserTraceparent(t *testing.T) {
+	traceParserConfig := NewTraceParserConfig("")
+	traceParserConfig.Format = "traceparent"
+	ops, err := traceParserConfig.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*TraceParserOperator)
+
+	e := entry.New()
+	e.Body = map[string]interface{}{
+		"traceparent": "00-480140f3d770a5ae32f0a22b6a812cff-92c3792d54ba94f3-01",
+	}
+	require.NoError(t, op.Parse(e))
+	require.Equal(t, map[string]interface{}{}, e.Body)
+
+	traceId, _ := hex.DecodeString("480140f3d770a5ae32f0a22b6a812cff")
+	require.Equal(t, traceId, e.TraceId)
+	spanId, _ := hex.DecodeString("92c3792d54ba94f3")
+	require.Equal(t, spanId, e.SpanId)
+	require.Equal(t, []byte{0x01}, e.TraceFlags)
+}
+
+func TestTraceParserInvalidFormat(t *testing.T) {
+	traceParserConfig := NewTraceParserConfig("")
+	traceParserConfig.Format = "jaeger"
+	_, err := traceParserConfig.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+}
@@ -20,6 +20,8 @@ import (
 	"net/url"
 	"strings"
 
+	"golang.org/x/net/idna"
+
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
@@ -39,8 +41,19 @@ func NewURIParserConfig(operatorID string) *URIParserConfig {
 // URIParserConfig is the configuration of a uri parser operator.
 type URIParserConfig struct {
 	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	Mode            string `mapstructure:"mode,omitempty"             json:"mode,omitempty"             yaml:"mode,omitempty"`
+	DecodeIDN       bool   `mapstructure:"decode_idn,omitempty"       json:"decode_idn,omitempty"       yaml:"decode_idn,omitempty"`
+	IncludePassword bool   `mapstructure:"include_password,omitempty" json:"include_password,omitempty" yaml:"include_password,omitempty"`
 }
 
+const (
+	// URIMode parses values as an absolute uri, a relative uri, or a query string starting with '?'
+	URIMode = "uri"
+	// QueryMode parses values as a query string, with or without a leading '?'
+	QueryMode = "query"
+)
+
 // Build will build a uri parser operator.
 func (c URIParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
 	parserOperator, err := c.ParserConfig.Build(context)
@@ -48,8 +61,19 @@ func (c URIParserConfig) Build(context operator.BuildContext) ([]operator.Operat
 		return nil, err
 	}
 
+	switch c.Mode {
+	case "":
+		c.Mode = URIMode
+	case URIMode, QueryMode:
+	default:
+		return nil, fmt.Errorf("invalid mode '%s', must be '%s' or '%s'", c.Mode, URIMode, QueryMode)
+	}
+
 	uriParser := &URIParser{
-		ParserOperator: parserOperator,
+		ParserOperator:  parserOperator,
+		queryOnly:       c.Mode == QueryMode,
+		decodeIDN:       c.DecodeIDN,
+		includePassword: c.IncludePassword,
 	}
 
 	return []operator.Operator{uriParser}, nil
@@ -58,6 +82,9 @@ func (c URIParserConfig) Build(context operator.BuildContext) ([]operator.Operat
 // URIParser is an operator that parses a uri.
 type URIParser struct {
 	helper.ParserOperator
+	queryOnly       bool
+	decodeIDN       bool
+	includePassword bool
 }
 
 // Process will parse an entry.
@@ -69,30 +96,58 @@ func (u *URIParser) Process(ctx context.Context, entry *entry.Entry) error {
 func (u *URIParser) parse(value interface{}) (interface{}, error) {
 	switch m := value.(type) {
 	case string:
-		return parseURI(m)
+		return u.parseURI(m)
 	default:
 		return nil, fmt.Errorf("type '%T' cannot be parsed as URI", value)
 	}
 }
 
 // parseURI takes an absolute or relative uri and returns the parsed values.
-func parseURI(value string) (map[string]interface{}, error) {
+func (u *URIParser) parseURI(value string) (map[string]interface{}, error) {
 	m := make(map[string]interface{})
 
-	if strings.HasPrefix(value, "?") {
+	if u.queryOnly || strings.HasPrefix(value, "?") {
 		// remove the query string '?' prefix before parsing
-		v, err := url.ParseQuery(value[1:])
+		v, err := url.ParseQuery(strings.TrimPrefix(value, "?"))
 		if err != nil {
 			return nil, err
 		}
 		return queryToMap(v, m), nil
 	}
 
+	// ParseRequestURI does not expect a fragment, so it is removed before parsing
+	var fragment string
+	if i := strings.IndexByte(value, '#'); i >= 0 {
+		f, err := url.PathUnescape(value[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		value, fragment = value[:i], f
+	}
+
 	x, err := url.ParseRequestURI(value)
 	if err != nil {
 		return nil, err
 	}
-	return urlToMap(x, m), nil
+	x.Fragment = fragment
+
+	m = urlToMap(x, m)
+
+	if u.includePassword {
+		if password, ok := x.User.Password(); ok && password != "" {
+			m["password"] = password
+		}
+	}
+
+	if host, ok := m["host"].(string); ok && u.decodeIDN {
+		decoded, err := idna.ToUnicode(host)
+		if err != nil {
+			return nil, fmt.Errorf("decode host '%s': %s", host, err)
+		}
+		m["host"] = decoded
+	}
+
+	return m, nil
 }
 
 // urlToMap converts a url.URL to a map, excludes any values that are not set.
@@ -122,6 +177,11 @@ func urlToMap(p *url.URL, m map[string]interface{}) map[string]interface{} {
 		m["path"] = path
 	}
 
+	fragment := p.Fragment
+	if fragment != "" {
+		m["fragment"] = fragment
+	}
+
 	return queryToMap(p.Query(), m)
 }
 
@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package useragent
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCache is a cache of parsed user agents, which evicts the least recently used
+// user agent when it is full.
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List
+}
+
+// cacheItem is an item of the cache.
+type cacheItem struct {
+	key   string
+	value userAgent
+}
+
+// newLRUCache creates a cache of a number of user agents.
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:  size,
+		items: make(map[string]*list.Element, size),
+		order: list.New(),
+	}
+}
+
+// get will return a cached user agent, and mark it as the most recently used.
+func (c *lruCache) get(key string) (userAgent, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return userAgent{}, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheItem).value, true
+}
+
+// add will cache a user agent, evicting the least recently used user agent if the cache is full.
+func (c *lruCache) add(key string, value userAgent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		element.Value.(*cacheItem).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheItem).key)
+	}
+	c.items[key] = c.order.PushFront(&cacheItem{key: key, value: value})
+}
+
+// len will return the number of cached user agents.
+func (c *lruCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,49 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package useragent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCache(t *testing.T) {
+	cache := newLRUCache(2)
+	cache.add("a", userAgent{browserFamily: "A"})
+	cache.add("b", userAgent{browserFamily: "B"})
+
+	// Getting "a" makes "b" the least recently used
+	ua, ok := cache.get("a")
+	require.True(t, ok)
+	require.Equal(t, "A", ua.browserFamily)
+
+	cache.add("c", userAgent{browserFamily: "C"})
+	require.Equal(t, 2, cache.len())
+
+	_, ok = cache.get("b")
+	require.False(t, ok)
+	_, ok = cache.get("a")
+	require.True(t, ok)
+	_, ok = cache.get("c")
+	require.True(t, ok)
+
+	// Adding an existing key replaces its value without evicting
+	cache.add("c", userAgent{browserFamily: "D"})
+	require.Equal(t, 2, cache.len())
+	ua, ok = cache.get("c")
+	require.True(t, ok)
+	require.Equal(t, "D", ua.browserFamily)
+}
@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package useragent
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestUserAgentParserConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "parse_from_to",
+			Expect: func() *UserAgentParserConfig {
+				cfg := defaultCfg()
+				cfg.ParseFrom = entry.NewBodyField("user_agent")
+				cfg.ParseTo = entry.NewBodyField("user_agent_parsed")
+				return cfg
+			}(),
+		},
+		{
+			Name: "cache_size",
+			Expect: func() *UserAgentParserConfig {
+				cfg := defaultCfg()
+				cfg.CacheSize = 10000
+				return cfg
+			}(),
+		},
+		{
+			Name: "rules",
+			Expect: func() *UserAgentParserConfig {
+				cfg := defaultCfg()
+				cfg.BrowserRules = []Rule{
+					{Regex: `(MyApp)/(\d+(?:\.\d+)*)`},
+				}
+				cfg.OSRules = []Rule{
+					{Regex: `MyOS (\w+)`, Family: "My OS", Version: "release-$1"},
+				}
+				cfg.DeviceRules = []Rule{
+					{Regex: `MyApp/.*\((\w+)\)`, Class: "kiosk"},
+				}
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *UserAgentParserConfig {
+	return NewUserAgentParserConfig("ua_parser")
+}
@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package useragent
+
+// The built-in rules are evaluated in order, after any rules of the configuration,
+// and the first rule which matches is used. More specific rules must therefore come
+// before more general ones, such as Edge and Chrome Mobile before Chrome.
+
+// versionPattern matches a version, such as 91.0.4472.124 or 14_6
+const versionPattern = `(\d+(?:[._]\d+)*)`
+
+// builtinBrowserRules identify the browser, or other client, of a user agent.
+var builtinBrowserRules = []Rule{
+	// Crawlers and HTTP libraries
+	{Regex: `(Googlebot|bingbot|Baiduspider|YandexBot|DuckDuckBot|Applebot|AhrefsBot|SemrushBot|Twitterbot|facebookexternalhit)/` + versionPattern},
+	{Regex: `(curl|Wget|python-requests|Go-http-client|okhttp|PostmanRuntime|Apache-HttpClient|axios)/` + versionPattern},
+
+	// Browsers based on Chromium, which also identify as Chrome
+	{Regex: `Edg(?:e|A|iOS)?/` + versionPattern, Family: "Edge"},
+	{Regex: `OPR/` + versionPattern, Family: "Opera"},
+	{Regex: `SamsungBrowser/` + versionPattern, Family: "Samsung Internet"},
+	{Regex: `YaBrowser/` + versionPattern, Family: "Yandex Browser"},
+	{Regex: `Vivaldi/` + versionPattern, Family: "Vivaldi"},
+	{Regex: `UCBrowser/` + versionPattern, Family: "UC Browser"},
+	{Regex: `HeadlessChrome/` + versionPattern, Family: "HeadlessChrome"},
+	{Regex: `Chromium/` + versionPattern, Family: "Chromium"},
+	{Regex: `CriOS/` + versionPattern, Family: "Chrome Mobile iOS"},
+	{Regex: `; wv\).*Chrome/` + versionPattern, Family: "Chrome Mobile WebView"},
+	{Regex: `Chrome/` + versionPattern + ` Mobile`, Family: "Chrome Mobile"},
+	{Regex: `Chrome/` + versionPattern, Family: "Chrome"},
+
+	// Firefox
+	{Regex: `FxiOS/` + versionPattern, Family: "Firefox iOS"},
+	{Regex: `(?:Mobile|Tablet).*Firefox/` + versionPattern, Family: "Firefox Mobile"},
+	{Regex: `Firefox/` + versionPattern, Family: "Firefox"},
+
+	// Internet Explorer
+	{Regex: `MSIE ` + versionPattern, Family: "IE"},
+	{Regex: `Trident/.*rv:` + versionPattern, Family: "IE"},
+
+	// Browsers based on WebKit, which also identify as Safari
+	{Regex: `Android.*Version/` + versionPattern + `.*Safari`, Family: "Android"},
+	{Regex: `Version/` + versionPattern + `.*Mobile/\S+ Safari`, Family: "Mobile Safari"},
+	{Regex: `Version/` + versionPattern + `.*Safari`, Family: "Safari"},
+
+	{Regex: `Opera/.*Version/` + versionPattern, Family: "Opera"},
+}
+
+// builtinOSRules identify the operating system of a user agent.
+var builtinOSRules = []Rule{
+	{Regex: `Windows Phone(?: OS)? ` + versionPattern, Family: "Windows Phone"},
+	{Regex: `Windows NT 10\.0`, Family: "Windows", Version: "10"},
+	{Regex: `Windows NT 6\.3`, Family: "Windows", Version: "8.1"},
+	{Regex: `Windows NT 6\.2`, Family: "Windows", Version: "8"},
+	{Regex: `Windows NT 6\.1`, Family: "Windows", Version: "7"},
+	{Regex: `Windows NT 6\.0`, Family: "Windows", Version: "Vista"},
+	{Regex: `Windows NT 5\.[12]`, Family: "Windows", Version: "XP"},
+	{Regex: `Windows`, Family: "Windows"},
+
+	// iOS devices also identify as Mac OS X
+	{Regex: `(?:iPhone|iPad|iPod).*? OS ` + versionPattern, Family: "iOS"},
+	{Regex: `iPhone|iPad|iPod`, Family: "iOS"},
+	{Regex: `Mac OS X ` + versionPattern, Family: "Mac OS X"},
+	{Regex: `Macintosh`, Family: "Mac OS X"},
+
+	// Android also identifies as Linux
+	{Regex: `Android ` + versionPattern, Family: "Android"},
+	{Regex: `Android`, Family: "Android"},
+	{Regex: `CrOS \S+ ` + versionPattern, Family: "Chrome OS"},
+	{Regex: `(Ubuntu|Fedora|Debian)`},
+	{Regex: `(FreeBSD|OpenBSD|NetBSD)`},
+	{Regex: `Linux`, Family: "Linux"},
+}
+
+// builtinDeviceRules identify the class, and sometimes the model, of the device of a user agent.
+var builtinDeviceRules = []Rule{
+	{Regex: `(?i)bot\b|crawler|spider|slurp|facebookexternalhit|curl/|wget/|python-requests|go-http-client|okhttp|postmanruntime|apache-httpclient|axios/`, Class: ClassBot},
+	{Regex: `(?i)smart-?tv|hbbtv|appletv|googletv|android tv|roku|crkey|bravia`, Class: ClassTV},
+	{Regex: `PlayStation|Xbox|Nintendo`, Class: ClassConsole},
+	{Regex: `(iPad)`, Class: ClassTablet},
+	{Regex: `(iPhone|iPod)`, Class: ClassMobile},
+
+	// Android phones identify as Mobile, and tablets do not
+	{Regex: `Android [\d.]+; (?:[a-zA-Z]{2}[-_][a-zA-Z]{2}; )?([^;)]+?)(?: Build/[^;)]+)?(?:; wv)?\).*Mobile`, Class: ClassMobile},
+	{Regex: `Android.*Mobile`, Class: ClassMobile},
+	{Regex: `Android [\d.]+; (?:[a-zA-Z]{2}[-_][a-zA-Z]{2}; )?([^;)]+?)(?: Build/[^;)]+)?(?:; wv)?\)`, Class: ClassTablet},
+	{Regex: `Android|Tablet|Kindle|Silk/|PlayBook`, Class: ClassTablet},
+
+	{Regex: `Windows Phone|IEMobile|BlackBerry|Opera Mini|Mobile`, Class: ClassMobile},
+	{Regex: `Windows NT|Macintosh|X11|CrOS|Linux`, Class: ClassDesktop},
+}
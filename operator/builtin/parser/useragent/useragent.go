@@ -0,0 +1,305 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package useragent
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// DefaultCacheSize is the number of parsed user agents which are cached when no size is configured
+	DefaultCacheSize = 1000
+
+	// OtherFamily is the family of a browser or operating system which is not identified by any rule
+	OtherFamily = "Other"
+)
+
+// Device classes
+const (
+	ClassDesktop = "desktop"
+	ClassMobile  = "mobile"
+	ClassTablet  = "tablet"
+	ClassTV      = "tv"
+	ClassConsole = "console"
+	ClassBot     = "bot"
+	ClassOther   = "other"
+)
+
+// The built-in rules are compiled once, and shared by all user agent parsers
+var (
+	compiledBrowserRules = mustCompileRules(builtinBrowserRules, false)
+	compiledOSRules      = mustCompileRules(builtinOSRules, false)
+	compiledDeviceRules  = mustCompileRules(builtinDeviceRules, true)
+)
+
+func init() {
+	operator.Register("ua_parser", func() operator.Builder { return NewUserAgentParserConfig("") })
+}
+
+// NewUserAgentParserConfig creates a new user agent parser config with default values
+func NewUserAgentParserConfig(operatorID string) *UserAgentParserConfig {
+	return &UserAgentParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "ua_parser"),
+		CacheSize:    DefaultCacheSize,
+	}
+}
+
+// UserAgentParserConfig is the configuration of a user agent parser operator.
+type UserAgentParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	BrowserRules []Rule `mapstructure:"browser_rules,omitempty" json:"browser_rules,omitempty" yaml:"browser_rules,omitempty"`
+	OSRules      []Rule `mapstructure:"os_rules,omitempty"      json:"os_rules,omitempty"      yaml:"os_rules,omitempty"`
+	DeviceRules  []Rule `mapstructure:"device_rules,omitempty"  json:"device_rules,omitempty"  yaml:"device_rules,omitempty"`
+	CacheSize    int    `mapstructure:"cache_size,omitempty"    json:"cache_size,omitempty"    yaml:"cache_size,omitempty"`
+}
+
+// Rule identifies the browser, operating system or device of user agents which match a regular expression.
+type Rule struct {
+	// Regex is the regular expression which user agents are matched against
+	Regex string `mapstructure:"regex"             json:"regex"             yaml:"regex"`
+
+	// Family is the name of the browser, operating system or device, and may refer to capture groups
+	// of the regex, such as $1. When empty, the first capture group is used.
+	Family string `mapstructure:"family,omitempty"  json:"family,omitempty"  yaml:"family,omitempty"`
+
+	// Version is the version of the browser or operating system, and may refer to capture groups
+	// of the regex. When empty, the capture group following the family is used.
+	Version string `mapstructure:"version,omitempty" json:"version,omitempty" yaml:"version,omitempty"`
+
+	// Class is the class of a device
+	Class string `mapstructure:"class,omitempty"   json:"class,omitempty"   yaml:"class,omitempty"`
+}
+
+// Build will build a user agent parser operator.
+func (c UserAgentParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.CacheSize <= 0 {
+		return nil, fmt.Errorf("invalid value for parameter 'cache_size', must be greater than 0")
+	}
+
+	browserRules, err := compileRules("browser_rules", c.BrowserRules, compiledBrowserRules)
+	if err != nil {
+		return nil, err
+	}
+
+	osRules, err := compileRules("os_rules", c.OSRules, compiledOSRules)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceRules, err := compileRules("device_rules", c.DeviceRules, compiledDeviceRules)
+	if err != nil {
+		return nil, err
+	}
+
+	userAgentParser := &UserAgentParser{
+		ParserOperator: parserOperator,
+		browserRules:   browserRules,
+		osRules:        osRules,
+		deviceRules:    deviceRules,
+		cache:          newLRUCache(c.CacheSize),
+	}
+
+	return []operator.Operator{userAgentParser}, nil
+}
+
+// UserAgentParser is an operator that parses user agents.
+type UserAgentParser struct {
+	helper.ParserOperator
+	browserRules []*compiledRule
+	osRules      []*compiledRule
+	deviceRules  []*compiledRule
+	cache        *lruCache
+}
+
+// Process will parse an entry field as a user agent.
+func (p *UserAgentParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ParserOperator.ProcessWith(ctx, entry, p.parse)
+}
+
+// parse will parse a value as a user agent.
+func (p *UserAgentParser) parse(value interface{}) (interface{}, error) {
+	var raw string
+	switch m := value.(type) {
+	case string:
+		raw = m
+	case []byte:
+		raw = string(m)
+	default:
+		return nil, fmt.Errorf("type %T cannot be parsed as a user agent", value)
+	}
+
+	if ua, ok := p.cache.get(raw); ok {
+		return ua.toMap(), nil
+	}
+
+	ua := p.parseUserAgent(raw)
+	p.cache.add(raw, ua)
+	return ua.toMap(), nil
+}
+
+// userAgent is a parsed user agent.
+type userAgent struct {
+	browserFamily  string
+	browserVersion string
+	osFamily       string
+	osVersion      string
+	deviceClass    string
+	deviceFamily   string
+}
+
+// parseUserAgent will parse a user agent with the first rule of each kind which matches it.
+func (p *UserAgentParser) parseUserAgent(raw string) userAgent {
+	ua := userAgent{
+		browserFamily: OtherFamily,
+		osFamily:      OtherFamily,
+		deviceClass:   ClassOther,
+	}
+
+	if rule, matches := firstMatch(p.browserRules, raw); rule != nil {
+		ua.browserFamily, ua.browserVersion = rule.familyAndVersion(raw, matches)
+	}
+	if rule, matches := firstMatch(p.osRules, raw); rule != nil {
+		ua.osFamily, ua.osVersion = rule.familyAndVersion(raw, matches)
+	}
+	if rule, matches := firstMatch(p.deviceRules, raw); rule != nil {
+		ua.deviceClass = rule.class
+		ua.deviceFamily, _ = rule.familyAndVersion(raw, matches)
+	}
+	return ua
+}
+
+// toMap will return the fields of a parsed user agent. Fields which were not identified are omitted.
+func (ua userAgent) toMap() map[string]interface{} {
+	browser := map[string]interface{}{"family": ua.browserFamily}
+	if ua.browserVersion != "" {
+		browser["version"] = ua.browserVersion
+	}
+
+	os := map[string]interface{}{"family": ua.osFamily}
+	if ua.osVersion != "" {
+		os["version"] = ua.osVersion
+	}
+
+	device := map[string]interface{}{"class": ua.deviceClass}
+	if ua.deviceFamily != "" {
+		device["family"] = ua.deviceFamily
+	}
+
+	return map[string]interface{}{
+		"browser": browser,
+		"os":      os,
+		"device":  device,
+	}
+}
+
+// compiledRule is a rule with a compiled regular expression.
+type compiledRule struct {
+	regexp  *regexp.Regexp
+	family  string
+	version string
+	class   string
+}
+
+// compileRules will compile the rules of the configuration, followed by the compiled built-in rules.
+func compileRules(name string, configured []Rule, builtin []*compiledRule) ([]*compiledRule, error) {
+	compiled := make([]*compiledRule, 0, len(configured)+len(builtin))
+	for i, rule := range configured {
+		c, err := rule.compile(name == "device_rules")
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s[%d]: %s", name, i, err)
+		}
+		compiled = append(compiled, c)
+	}
+	return append(compiled, builtin...), nil
+}
+
+// mustCompileRules will compile built-in rules, panicking if any are invalid.
+func mustCompileRules(rules []Rule, isDevice bool) []*compiledRule {
+	compiled := make([]*compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		c, err := rule.compile(isDevice)
+		if err != nil {
+			panic(fmt.Sprintf("invalid built-in rule '%s': %s", rule.Regex, err))
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled
+}
+
+// compile will compile a rule. A device rule must have a class, and other rules must identify a family.
+func (r Rule) compile(isDevice bool) (*compiledRule, error) {
+	if r.Regex == "" {
+		return nil, fmt.Errorf("missing required field 'regex'")
+	}
+
+	re, err := regexp.Compile(r.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("compiling regex: %s", err)
+	}
+
+	c := &compiledRule{
+		regexp:  re,
+		family:  r.Family,
+		version: r.Version,
+		class:   r.Class,
+	}
+
+	versionGroup := 1
+	if c.family == "" && re.NumSubexp() > 0 {
+		c.family = "$1"
+		versionGroup = 2
+	}
+	if c.version == "" && re.NumSubexp() >= versionGroup {
+		c.version = fmt.Sprintf("${%d}", versionGroup)
+	}
+
+	switch {
+	case isDevice && c.class == "":
+		return nil, fmt.Errorf("missing required field 'class'")
+	case !isDevice && c.family == "":
+		return nil, fmt.Errorf("missing required field 'family', or a capture group")
+	}
+	return c, nil
+}
+
+// familyAndVersion will expand the family and version of a rule from its match of a user agent.
+func (c *compiledRule) familyAndVersion(raw string, matches []int) (string, string) {
+	family := strings.TrimSpace(string(c.regexp.ExpandString(nil, c.family, raw, matches)))
+	version := strings.TrimSpace(string(c.regexp.ExpandString(nil, c.version, raw, matches)))
+	return family, strings.ReplaceAll(version, "_", ".")
+}
+
+// firstMatch will return the first rule which matches a user agent, and the indexes of its match.
+func firstMatch(rules []*compiledRule, raw string) (*compiledRule, []int) {
+	for _, rule := range rules {
+		if matches := rule.regexp.FindStringSubmatchIndex(raw); matches != nil {
+			return rule, matches
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,299 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package useragent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T, modify func(*UserAgentParserConfig)) (*UserAgentParser, *testutil.FakeOutput) {
+	cfg := NewUserAgentParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	if modify != nil {
+		modify(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*UserAgentParser)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fakeOutput}))
+	return parser, fakeOutput
+}
+
+// expectedUserAgent builds the expected result of parsing a user agent.
+func expectedUserAgent(browserFamily, browserVersion, osFamily, osVersion, deviceClass, deviceFamily string) map[string]interface{} {
+	return userAgent{
+		browserFamily:  browserFamily,
+		browserVersion: browserVersion,
+		osFamily:       osFamily,
+		osVersion:      osVersion,
+		deviceClass:    deviceClass,
+		deviceFamily:   deviceFamily,
+	}.toMap()
+}
+
+func TestUserAgentParserBuildFailure(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*UserAgentParserConfig)
+		expectErr string
+	}{
+		{
+			"InvalidCacheSize",
+			func(cfg *UserAgentParserConfig) {
+				cfg.CacheSize = 0
+			},
+			"invalid value for parameter 'cache_size'",
+		},
+		{
+			"MissingRegex",
+			func(cfg *UserAgentParserConfig) {
+				cfg.BrowserRules = []Rule{{Family: "Custom"}}
+			},
+			"invalid browser_rules[0]: missing required field 'regex'",
+		},
+		{
+			"InvalidRegex",
+			func(cfg *UserAgentParserConfig) {
+				cfg.OSRules = []Rule{{Regex: `Custom/(\d+`}}
+			},
+			"invalid os_rules[0]: compiling regex",
+		},
+		{
+			"MissingFamily",
+			func(cfg *UserAgentParserConfig) {
+				cfg.BrowserRules = []Rule{{Regex: `Custom`}}
+			},
+			"missing required field 'family', or a capture group",
+		},
+		{
+			"MissingClass",
+			func(cfg *UserAgentParserConfig) {
+				cfg.DeviceRules = []Rule{{Regex: `Custom`}}
+			},
+			"invalid device_rules[0]: missing required field 'class'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewUserAgentParserConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectErr)
+		})
+	}
+}
+
+func TestUserAgentParserParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected map[string]interface{}
+	}{
+		{
+			"ChromeWindows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36",
+			expectedUserAgent("Chrome", "91.0.4472.124", "Windows", "10", ClassDesktop, ""),
+		},
+		{
+			"EdgeWindows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36 Edg/91.0.864.59",
+			expectedUserAgent("Edge", "91.0.864.59", "Windows", "10", ClassDesktop, ""),
+		},
+		{
+			"FirefoxLinux",
+			"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:89.0) Gecko/20100101 Firefox/89.0",
+			expectedUserAgent("Firefox", "89.0", "Ubuntu", "", ClassDesktop, ""),
+		},
+		{
+			"SafariMac",
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Safari/605.1.15",
+			expectedUserAgent("Safari", "14.1.1", "Mac OS X", "10.15.7", ClassDesktop, ""),
+		},
+		{
+			"MobileSafariIPhone",
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 14_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Mobile/15E148 Safari/604.1",
+			expectedUserAgent("Mobile Safari", "14.1.1", "iOS", "14.6", ClassMobile, "iPhone"),
+		},
+		{
+			"ChromeIPad",
+			"Mozilla/5.0 (iPad; CPU OS 14_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/91.0.4472.80 Mobile/15E148 Safari/604.1",
+			expectedUserAgent("Chrome Mobile iOS", "91.0.4472.80", "iOS", "14.6", ClassTablet, "iPad"),
+		},
+		{
+			"ChromeMobileAndroid",
+			"Mozilla/5.0 (Linux; Android 11; Pixel 5) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.120 Mobile Safari/537.36",
+			expectedUserAgent("Chrome Mobile", "91.0.4472.120", "Android", "11", ClassMobile, "Pixel 5"),
+		},
+		{
+			"SamsungInternetTablet",
+			"Mozilla/5.0 (Linux; Android 10; SM-T510) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/14.2 Chrome/87.0.4280.141 Safari/537.36",
+			expectedUserAgent("Samsung Internet", "14.2", "Android", "10", ClassTablet, "SM-T510"),
+		},
+		{
+			"AndroidWebViewWithBuild",
+			"Mozilla/5.0 (Linux; Android 9; SM-G960F Build/PPR1.180610.011; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/74.0.3729.157 Mobile Safari/537.36",
+			expectedUserAgent("Chrome Mobile WebView", "74.0.3729.157", "Android", "9", ClassMobile, "SM-G960F"),
+		},
+		{
+			"FirefoxMobileAndroid",
+			"Mozilla/5.0 (Android 11; Mobile; rv:68.0) Gecko/68.0 Firefox/89.0",
+			expectedUserAgent("Firefox Mobile", "89.0", "Android", "11", ClassMobile, ""),
+		},
+		{
+			"InternetExplorer11",
+			"Mozilla/5.0 (Windows NT 6.1; WOW64; Trident/7.0; rv:11.0) like Gecko",
+			expectedUserAgent("IE", "11.0", "Windows", "7", ClassDesktop, ""),
+		},
+		{
+			"ChromeOS",
+			"Mozilla/5.0 (X11; CrOS x86_64 13904.55.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.114 Safari/537.36",
+			expectedUserAgent("Chrome", "91.0.4472.114", "Chrome OS", "13904.55.0", ClassDesktop, ""),
+		},
+		{
+			"Googlebot",
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			expectedUserAgent("Googlebot", "2.1", OtherFamily, "", ClassBot, ""),
+		},
+		{
+			"Curl",
+			"curl/7.68.0",
+			expectedUserAgent("curl", "7.68.0", OtherFamily, "", ClassBot, ""),
+		},
+		{
+			"PlayStation",
+			"Mozilla/5.0 (PlayStation 4 8.52) AppleWebKit/605.1.15 (KHTML, like Gecko)",
+			expectedUserAgent(OtherFamily, "", OtherFamily, "", ClassConsole, ""),
+		},
+		{
+			"SmartTV",
+			"Mozilla/5.0 (SMART-TV; Linux; Tizen 5.0) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/2.2 Chrome/63.0.3239.84 TV Safari/537.36",
+			expectedUserAgent("Samsung Internet", "2.2", "Linux", "", ClassTV, ""),
+		},
+		{
+			"Unknown",
+			"my-client",
+			expectedUserAgent(OtherFamily, "", OtherFamily, "", ClassOther, ""),
+		},
+		{
+			"Empty",
+			"",
+			expectedUserAgent(OtherFamily, "", OtherFamily, "", ClassOther, ""),
+		},
+	}
+
+	parser, _ := newTestParser(t, nil)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestUserAgentParserCustomRules(t *testing.T) {
+	parser, _ := newTestParser(t, func(cfg *UserAgentParserConfig) {
+		cfg.BrowserRules = []Rule{
+			{Regex: `(MyApp)/(\d+)\.(\d+)`, Version: "$2.$3"},
+			{Regex: `Chrome/(\d+)`, Family: "Chrome (major)"},
+		}
+		cfg.OSRules = []Rule{
+			{Regex: `MyOS (\w+)`, Family: "My OS", Version: "release-$1"},
+		}
+		cfg.DeviceRules = []Rule{
+			{Regex: `MyApp/.*\((\w+)\)`, Class: "kiosk"},
+		}
+	})
+
+	result, err := parser.parse("MyApp/2.7 MyOS jammy (Terminal1)")
+	require.NoError(t, err)
+	require.Equal(t, expectedUserAgent("MyApp", "2.7", "My OS", "release-jammy", "kiosk", "Terminal1"), result)
+
+	// Rules of the configuration are evaluated before the built-in rules
+	result, err = parser.parse("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+	require.NoError(t, err)
+	require.Equal(t, expectedUserAgent("Chrome (major)", "91", "Windows", "10", ClassDesktop, ""), result)
+}
+
+func TestUserAgentParserParseErrors(t *testing.T) {
+	parser, _ := newTestParser(t, nil)
+	_, err := parser.parse(1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "type int cannot be parsed as a user agent")
+}
+
+func TestUserAgentParserCache(t *testing.T) {
+	parser, _ := newTestParser(t, func(cfg *UserAgentParserConfig) {
+		cfg.CacheSize = 2
+	})
+
+	input := []byte("curl/7.68.0")
+	first, err := parser.parse(input)
+	require.NoError(t, err)
+	require.Equal(t, 1, parser.cache.len())
+
+	// A cached user agent is returned as a new map, which may be modified
+	first.(map[string]interface{})["browser"].(map[string]interface{})["family"] = "modified"
+	second, err := parser.parse(input)
+	require.NoError(t, err)
+	require.Equal(t, expectedUserAgent("curl", "7.68.0", OtherFamily, "", ClassBot, ""), second)
+	require.Equal(t, 1, parser.cache.len())
+
+	_, err = parser.parse("Wget/1.20.3")
+	require.NoError(t, err)
+	_, err = parser.parse("python-requests/2.25.1")
+	require.NoError(t, err)
+	require.Equal(t, 2, parser.cache.len())
+}
+
+func TestUserAgentParserProcess(t *testing.T) {
+	parser, fakeOutput := newTestParser(t, func(cfg *UserAgentParserConfig) {
+		cfg.ParseFrom = entry.NewBodyField("user_agent")
+		cfg.ParseTo = entry.NewBodyField("user_agent_parsed")
+		preserve := entry.NewBodyField("user_agent")
+		cfg.PreserveTo = &preserve
+	})
+
+	e := entry.New()
+	e.Body = map[string]interface{}{
+		"user_agent": "curl/7.68.0",
+		"path":       "/index.html",
+	}
+	require.NoError(t, parser.Process(context.Background(), e))
+
+	select {
+	case processed := <-fakeOutput.Received:
+		require.Equal(t, map[string]interface{}{
+			"user_agent":        "curl/7.68.0",
+			"user_agent_parsed": expectedUserAgent("curl", "7.68.0", OtherFamily, "", ClassBot, ""),
+			"path":              "/index.html",
+		}, processed.Body)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry to be processed")
+	}
+}
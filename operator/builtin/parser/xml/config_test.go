@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestXMLParserConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "parse_from_simple",
+			Expect: func() *XMLParserConfig {
+				cfg := defaultCfg()
+				cfg.ParseFrom = entry.NewBodyField("from")
+				return cfg
+			}(),
+		},
+		{
+			Name: "parse_to_simple",
+			Expect: func() *XMLParserConfig {
+				cfg := defaultCfg()
+				cfg.ParseTo = entry.NewBodyField("log")
+				return cfg
+			}(),
+		},
+		{
+			Name: "names",
+			Expect: func() *XMLParserConfig {
+				cfg := defaultCfg()
+				cfg.AttributePrefix = "_"
+				cfg.TextKey = "value"
+				cfg.TrimSpace = false
+				return cfg
+			}(),
+		},
+		{
+			Name: "repeated_elements",
+			Expect: func() *XMLParserConfig {
+				cfg := defaultCfg()
+				cfg.RepeatedElements = RepeatedElementsLast
+				cfg.ArrayElements = []string{"Data", "Item"}
+				return cfg
+			}(),
+		},
+		{
+			Name: "namespaces",
+			Expect: func() *XMLParserConfig {
+				cfg := defaultCfg()
+				cfg.Namespaces = NamespacesPrefix
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *XMLParserConfig {
+	return NewXMLParserConfig("xml_parser")
+}
@@ -0,0 +1,290 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// RepeatedElementsArray collects repeated elements into an array
+	RepeatedElementsArray = "array"
+
+	// RepeatedElementsFirst keeps the first of repeated elements
+	RepeatedElementsFirst = "first"
+
+	// RepeatedElementsLast keeps the last of repeated elements
+	RepeatedElementsLast = "last"
+
+	// NamespacesStrip names elements and attributes by their local name
+	NamespacesStrip = "strip"
+
+	// NamespacesPrefix names elements and attributes by their prefix and local name, as written in the document
+	NamespacesPrefix = "prefix"
+
+	// NamespacesURI names elements and attributes by their namespace URI and local name, as {uri}name
+	NamespacesURI = "uri"
+)
+
+func init() {
+	operator.Register("xml_parser", func() operator.Builder { return NewXMLParserConfig("") })
+}
+
+// NewXMLParserConfig creates a new XML parser config with default values
+func NewXMLParserConfig(operatorID string) *XMLParserConfig {
+	return &XMLParserConfig{
+		ParserConfig:     helper.NewParserConfig(operatorID, "xml_parser"),
+		AttributePrefix:  "@",
+		TextKey:          "#text",
+		TrimSpace:        true,
+		RepeatedElements: RepeatedElementsArray,
+		Namespaces:       NamespacesStrip,
+	}
+}
+
+// XMLParserConfig is the configuration of an XML parser operator.
+type XMLParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	AttributePrefix  string   `mapstructure:"attribute_prefix"         json:"attribute_prefix"         yaml:"attribute_prefix"`
+	TextKey          string   `mapstructure:"text_key"                 json:"text_key"                 yaml:"text_key"`
+	TrimSpace        bool     `mapstructure:"trim_space"               json:"trim_space"               yaml:"trim_space"`
+	RepeatedElements string   `mapstructure:"repeated_elements"        json:"repeated_elements"        yaml:"repeated_elements"`
+	ArrayElements    []string `mapstructure:"array_elements,omitempty" json:"array_elements,omitempty" yaml:"array_elements,omitempty"`
+	Namespaces       string   `mapstructure:"namespaces"               json:"namespaces"               yaml:"namespaces"`
+}
+
+// Build will build an XML parser operator.
+func (c XMLParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.TextKey == "" {
+		return nil, fmt.Errorf("missing required parameter 'text_key'")
+	}
+
+	switch c.RepeatedElements {
+	case RepeatedElementsArray, RepeatedElementsFirst, RepeatedElementsLast:
+	default:
+		return nil, fmt.Errorf("invalid repeated_elements '%s'", c.RepeatedElements)
+	}
+
+	switch c.Namespaces {
+	case NamespacesStrip, NamespacesPrefix, NamespacesURI:
+	default:
+		return nil, fmt.Errorf("invalid namespaces '%s'", c.Namespaces)
+	}
+
+	arrayElements := make(map[string]bool, len(c.ArrayElements))
+	for _, name := range c.ArrayElements {
+		arrayElements[name] = true
+	}
+
+	xmlParser := &XMLParser{
+		ParserOperator:   parserOperator,
+		attributePrefix:  c.AttributePrefix,
+		textKey:          c.TextKey,
+		trimSpace:        c.TrimSpace,
+		repeatedElements: c.RepeatedElements,
+		arrayElements:    arrayElements,
+		namespaces:       c.Namespaces,
+	}
+
+	return []operator.Operator{xmlParser}, nil
+}
+
+// XMLParser is an operator that parses XML.
+type XMLParser struct {
+	helper.ParserOperator
+	attributePrefix  string
+	textKey          string
+	trimSpace        bool
+	repeatedElements string
+	arrayElements    map[string]bool
+	namespaces       string
+}
+
+// Process will parse an entry for XML.
+func (p *XMLParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ParserOperator.ProcessWith(ctx, entry, p.parse)
+}
+
+// element is an element which is being parsed.
+type element struct {
+	name     xml.Name
+	fields   map[string]interface{}
+	text     strings.Builder
+	children bool
+}
+
+// parse will parse a value as XML. The result is a map containing the root element,
+// keyed by its name.
+func (p *XMLParser) parse(value interface{}) (interface{}, error) {
+	var raw []byte
+	switch m := value.(type) {
+	case string:
+		raw = []byte(m)
+	case []byte:
+		raw = m
+	default:
+		return nil, fmt.Errorf("type %T cannot be parsed as XML", value)
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	nextToken := decoder.Token
+	if p.namespaces == NamespacesPrefix {
+		// Raw tokens keep the prefixes of names, but end elements must be matched here
+		nextToken = decoder.RawToken
+	}
+
+	var root map[string]interface{}
+	var stack []*element
+	for {
+		token, err := nextToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(stack) == 0 && root != nil {
+				return nil, fmt.Errorf("unexpected element <%s> after the root element", t.Name.Local)
+			}
+			if len(stack) > 0 {
+				stack[len(stack)-1].children = true
+			}
+
+			el := &element{name: t.Name, fields: map[string]interface{}{}}
+			for _, attr := range t.Attr {
+				if name, ok := p.attributeName(attr.Name); ok {
+					el.fields[p.attributePrefix+name] = attr.Value
+				}
+			}
+			stack = append(stack, el)
+
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("unexpected end element </%s>", t.Name.Local)
+			}
+			el := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if el.name != t.Name {
+				return nil, fmt.Errorf("element <%s> closed by </%s>", p.name(el.name), p.name(t.Name))
+			}
+
+			name := p.name(el.name)
+			value := p.value(el)
+			if len(stack) == 0 {
+				root = map[string]interface{}{name: value}
+				continue
+			}
+			p.addChild(stack[len(stack)-1].fields, name, value)
+
+		case xml.CharData:
+			if len(stack) == 0 {
+				if len(bytes.TrimSpace(t)) > 0 {
+					return nil, fmt.Errorf("unexpected character data outside of the root element")
+				}
+				continue
+			}
+			stack[len(stack)-1].text.Write(t)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("element <%s> is not closed", p.name(stack[len(stack)-1].name))
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+	return root, nil
+}
+
+// value will return the value of a parsed element. An element with only character data
+// is its text, and any other element is a map of its attributes, children and text.
+func (p *XMLParser) value(el *element) interface{} {
+	text := el.text.String()
+	if p.trimSpace || el.children {
+		text = strings.TrimSpace(text)
+	}
+
+	if len(el.fields) == 0 && !el.children {
+		return text
+	}
+	if text != "" {
+		el.fields[p.textKey] = text
+	}
+	return el.fields
+}
+
+// addChild will add the value of a child element to the fields of its parent.
+func (p *XMLParser) addChild(fields map[string]interface{}, name string, value interface{}) {
+	existing, ok := fields[name]
+	switch {
+	case p.arrayElements[name]:
+		values, _ := existing.([]interface{})
+		fields[name] = append(values, value)
+	case !ok:
+		fields[name] = value
+	case p.repeatedElements == RepeatedElementsLast:
+		fields[name] = value
+	case p.repeatedElements == RepeatedElementsArray:
+		if values, ok := existing.([]interface{}); ok {
+			fields[name] = append(values, value)
+		} else {
+			fields[name] = []interface{}{existing, value}
+		}
+	}
+}
+
+// name will return the name of an element or attribute, according to the namespaces mode.
+func (p *XMLParser) name(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+
+	switch p.namespaces {
+	case NamespacesPrefix:
+		return name.Space + ":" + name.Local
+	case NamespacesURI:
+		return "{" + name.Space + "}" + name.Local
+	default:
+		return name.Local
+	}
+}
+
+// attributeName will return the name of an attribute, and false if the attribute is a
+// namespace declaration which is only kept when names include prefixes.
+func (p *XMLParser) attributeName(name xml.Name) (string, bool) {
+	isDeclaration := name.Space == "xmlns" || (name.Space == "" && name.Local == "xmlns")
+	if isDeclaration && p.namespaces != NamespacesPrefix {
+		return "", false
+	}
+	return p.name(name), true
+}
@@ -0,0 +1,382 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T, modify func(*XMLParserConfig)) (*XMLParser, *testutil.FakeOutput) {
+	cfg := NewXMLParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	if modify != nil {
+		modify(cfg)
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*XMLParser)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fakeOutput}))
+	return parser, fakeOutput
+}
+
+func TestXMLParserBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(*XMLParserConfig)
+		expectErr bool
+	}{
+		{
+			"Default",
+			func(cfg *XMLParserConfig) {},
+			false,
+		},
+		{
+			"EmptyAttributePrefix",
+			func(cfg *XMLParserConfig) {
+				cfg.AttributePrefix = ""
+			},
+			false,
+		},
+		{
+			"MissingTextKey",
+			func(cfg *XMLParserConfig) {
+				cfg.TextKey = ""
+			},
+			true,
+		},
+		{
+			"InvalidRepeatedElements",
+			func(cfg *XMLParserConfig) {
+				cfg.RepeatedElements = "merge"
+			},
+			true,
+		},
+		{
+			"InvalidNamespaces",
+			func(cfg *XMLParserConfig) {
+				cfg.Namespaces = "keep"
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewXMLParserConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestXMLParserParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*XMLParserConfig)
+		input    interface{}
+		expected map[string]interface{}
+	}{
+		{
+			"TextElement",
+			nil,
+			"<message>hello</message>",
+			map[string]interface{}{
+				"message": "hello",
+			},
+		},
+		{
+			"Bytes",
+			nil,
+			[]byte("<message>hello</message>"),
+			map[string]interface{}{
+				"message": "hello",
+			},
+		},
+		{
+			"EmptyElement",
+			nil,
+			"<event><empty/></event>",
+			map[string]interface{}{
+				"event": map[string]interface{}{
+					"empty": "",
+				},
+			},
+		},
+		{
+			"Nested",
+			nil,
+			`<?xml version="1.0" encoding="UTF-8"?>
+<!-- exported -->
+<log>
+  <level>ERROR</level>
+  <logger>com.example.App</logger>
+  <thread>main</thread>
+</log>
+`,
+			map[string]interface{}{
+				"log": map[string]interface{}{
+					"level":  "ERROR",
+					"logger": "com.example.App",
+					"thread": "main",
+				},
+			},
+		},
+		{
+			"Attributes",
+			nil,
+			`<record level="INFO" seq="1"><message>started</message></record>`,
+			map[string]interface{}{
+				"record": map[string]interface{}{
+					"@level":  "INFO",
+					"@seq":    "1",
+					"message": "started",
+				},
+			},
+		},
+		{
+			"AttributesAndText",
+			nil,
+			`<Data Name="TargetUserName">  admin </Data>`,
+			map[string]interface{}{
+				"Data": map[string]interface{}{
+					"@Name": "TargetUserName",
+					"#text": "admin",
+				},
+			},
+		},
+		{
+			"MixedContent",
+			nil,
+			`<p>one <b>two</b> three</p>`,
+			map[string]interface{}{
+				"p": map[string]interface{}{
+					"b":     "two",
+					"#text": "one  three",
+				},
+			},
+		},
+		{
+			"CDATAAndEntities",
+			nil,
+			`<msg><![CDATA[a < b]]> &amp; c</msg>`,
+			map[string]interface{}{
+				"msg": "a < b & c",
+			},
+		},
+		{
+			"NoTrimSpace",
+			func(cfg *XMLParserConfig) {
+				cfg.TrimSpace = false
+			},
+			`<msg>  padded  </msg>`,
+			map[string]interface{}{
+				"msg": "  padded  ",
+			},
+		},
+		{
+			"CustomNames",
+			func(cfg *XMLParserConfig) {
+				cfg.AttributePrefix = ""
+				cfg.TextKey = "value"
+			},
+			`<Data Name="SubjectUserName">SYSTEM</Data>`,
+			map[string]interface{}{
+				"Data": map[string]interface{}{
+					"Name":  "SubjectUserName",
+					"value": "SYSTEM",
+				},
+			},
+		},
+		{
+			"RepeatedElementsArray",
+			nil,
+			`<e><tag>a</tag><host>h</host><tag>b</tag><tag>c</tag></e>`,
+			map[string]interface{}{
+				"e": map[string]interface{}{
+					"tag":  []interface{}{"a", "b", "c"},
+					"host": "h",
+				},
+			},
+		},
+		{
+			"RepeatedElementsFirst",
+			func(cfg *XMLParserConfig) {
+				cfg.RepeatedElements = RepeatedElementsFirst
+			},
+			`<e><tag>a</tag><tag>b</tag></e>`,
+			map[string]interface{}{
+				"e": map[string]interface{}{
+					"tag": "a",
+				},
+			},
+		},
+		{
+			"RepeatedElementsLast",
+			func(cfg *XMLParserConfig) {
+				cfg.RepeatedElements = RepeatedElementsLast
+			},
+			`<e><tag>a</tag><tag>b</tag></e>`,
+			map[string]interface{}{
+				"e": map[string]interface{}{
+					"tag": "b",
+				},
+			},
+		},
+		{
+			"ArrayElements",
+			func(cfg *XMLParserConfig) {
+				cfg.RepeatedElements = RepeatedElementsLast
+				cfg.ArrayElements = []string{"Data"}
+			},
+			`<EventData><Data>one</Data></EventData>`,
+			map[string]interface{}{
+				"EventData": map[string]interface{}{
+					"Data": []interface{}{"one"},
+				},
+			},
+		},
+		{
+			"NamespacesStrip",
+			nil,
+			`<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event" xmlns:x="urn:x"><System x:id="1"><EventID>4624</EventID></System></Event>`,
+			map[string]interface{}{
+				"Event": map[string]interface{}{
+					"System": map[string]interface{}{
+						"@id":     "1",
+						"EventID": "4624",
+					},
+				},
+			},
+		},
+		{
+			"NamespacesPrefix",
+			func(cfg *XMLParserConfig) {
+				cfg.Namespaces = NamespacesPrefix
+			},
+			`<soap:Envelope xmlns:soap="urn:soap"><soap:Body>ok</soap:Body></soap:Envelope>`,
+			map[string]interface{}{
+				"soap:Envelope": map[string]interface{}{
+					"@xmlns:soap": "urn:soap",
+					"soap:Body":   "ok",
+				},
+			},
+		},
+		{
+			"NamespacesURI",
+			func(cfg *XMLParserConfig) {
+				cfg.Namespaces = NamespacesURI
+			},
+			`<soap:Envelope xmlns:soap="urn:soap"><soap:Body>ok</soap:Body><plain>p</plain></soap:Envelope>`,
+			map[string]interface{}{
+				"{urn:soap}Envelope": map[string]interface{}{
+					"{urn:soap}Body": "ok",
+					"plain":          "p",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, tc.modify)
+			actual, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestXMLParserParseErrors(t *testing.T) {
+	cases := []struct {
+		name       string
+		namespaces string
+		input      interface{}
+		expected   string
+	}{
+		{"InvalidType", NamespacesStrip, []int{}, "type []int cannot be parsed as XML"},
+		{"Empty", NamespacesStrip, "", "no root element"},
+		{"NotXML", NamespacesStrip, "plain text", "unexpected character data outside of the root element"},
+		{"Unclosed", NamespacesStrip, "<a><b>text</b>", "unexpected EOF"},
+		{"Mismatched", NamespacesStrip, "<a><b>text</a></b>", "element <b> closed by </a>"},
+		{"MultipleRoots", NamespacesStrip, "<a/><b/>", "unexpected element <b> after the root element"},
+		{"PrefixUnclosed", NamespacesPrefix, "<a><b>text</b>", "element <a> is not closed"},
+		{"PrefixMismatched", NamespacesPrefix, "<x:a><x:b>text</x:a></x:b>", "element <x:b> closed by </x:a>"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, func(cfg *XMLParserConfig) {
+				cfg.Namespaces = tc.namespaces
+			})
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestXMLParserProcess(t *testing.T) {
+	parser, fakeOutput := newTestParser(t, func(cfg *XMLParserConfig) {
+		cfg.ParseFrom = entry.NewBodyField("message")
+
+		timeField := entry.NewBodyField("log", "@timestamp")
+		timeParser := helper.NewTimeParser()
+		timeParser.ParseFrom = &timeField
+		timeParser.LayoutType = helper.GotimeKey
+		timeParser.Layout = time.RFC3339
+		cfg.TimeParser = &timeParser
+
+		severityField := entry.NewBodyField("log", "level")
+		severityParser := helper.NewSeverityParserConfig()
+		severityParser.ParseFrom = &severityField
+		cfg.SeverityParserConfig = &severityParser
+	})
+
+	e := entry.New()
+	e.Body = map[string]interface{}{
+		"message": `<log timestamp="2021-06-01T12:00:00Z"><level>warn</level><message>disk almost full</message></log>`,
+	}
+	require.NoError(t, parser.Process(context.Background(), e))
+
+	select {
+	case out := <-fakeOutput.Received:
+		require.Equal(t, map[string]interface{}{
+			"log": map[string]interface{}{
+				"message": "disk almost full",
+			},
+		}, out.Body)
+		require.Equal(t, time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC), out.Timestamp.UTC())
+		require.Equal(t, entry.Warning, out.Severity)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// countKey is the key of the summary body which contains the number of entries
+	countKey = "count"
+
+	// sumKey is the key of the summary body which contains the sums of numeric fields
+	sumKey = "sum"
+
+	// averageKey is the key of the summary body which contains the averages of numeric fields
+	averageKey = "average"
+)
+
+func init() {
+	operator.Register("aggregate", func() operator.Builder { return NewAggregateOperatorConfig("") })
+}
+
+// NewAggregateOperatorConfig creates a new aggregate operator config with default values
+func NewAggregateOperatorConfig(operatorID string) *AggregateOperatorConfig {
+	return &AggregateOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "aggregate"),
+		Window:            helper.NewDuration(time.Minute),
+		MaxGroups:         10000,
+	}
+}
+
+// AggregateOperatorConfig is the configuration of an aggregate operator
+type AggregateOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+	Window                   helper.Duration `mapstructure:"window"     json:"window"             yaml:"window"`
+	GroupBy                  []entry.Field   `mapstructure:"group_by"   json:"group_by,omitempty" yaml:"group_by,omitempty"`
+	Sum                      []entry.Field   `mapstructure:"sum"        json:"sum,omitempty"      yaml:"sum,omitempty"`
+	Average                  []entry.Field   `mapstructure:"average"    json:"average,omitempty"  yaml:"average,omitempty"`
+	MaxGroups                int             `mapstructure:"max_groups" json:"max_groups"         yaml:"max_groups"`
+}
+
+// Build will build an aggregate operator from the supplied configuration
+func (c AggregateOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformer, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Window.Raw() <= 0 {
+		return nil, fmt.Errorf("window must be greater than 0")
+	}
+
+	if c.MaxGroups <= 0 {
+		return nil, fmt.Errorf("max_groups must be greater than 0")
+	}
+
+	for _, field := range c.GroupBy {
+		body, ok := field.FieldInterface.(entry.BodyField)
+		if !ok {
+			continue
+		}
+		if len(body.Keys) == 0 {
+			return nil, fmt.Errorf("group_by cannot contain the entire body")
+		}
+		switch body.Keys[0] {
+		case countKey, sumKey, averageKey:
+			return nil, fmt.Errorf("group_by field %s conflicts with the '%s' key of summary entries", field, body.Keys[0])
+		}
+	}
+
+	sums, err := numericFields("sum", c.Sum)
+	if err != nil {
+		return nil, err
+	}
+
+	averages, err := numericFields("average", c.Average)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregate := &AggregateOperator{
+		TransformerOperator: transformer,
+		window:              c.Window.Raw(),
+		groupBy:             c.GroupBy,
+		sums:                sums,
+		averages:            averages,
+		maxGroups:           c.MaxGroups,
+		groups:              map[string]*group{},
+		now:                 time.Now,
+	}
+
+	return []operator.Operator{aggregate}, nil
+}
+
+// numericField is a body field whose numeric values are aggregated
+type numericField struct {
+	field entry.Field
+	name  string
+}
+
+// numericFields will validate that fields are body fields, and name them by their keys.
+func numericFields(param string, fields []entry.Field) ([]numericField, error) {
+	numeric := make([]numericField, 0, len(fields))
+	for _, field := range fields {
+		body, ok := field.FieldInterface.(entry.BodyField)
+		if !ok || len(body.Keys) == 0 {
+			return nil, fmt.Errorf("%s field %s must be a field of the body", param, field)
+		}
+		numeric = append(numeric, numericField{field: field, name: strings.Join(body.Keys, ".")})
+	}
+	return numeric, nil
+}
+
+// AggregateOperator is an operator that replaces entries with periodic summaries of each group of entries
+type AggregateOperator struct {
+	helper.TransformerOperator
+	window    time.Duration
+	groupBy   []entry.Field
+	sums      []numericField
+	averages  []numericField
+	maxGroups int
+	now       func() time.Time
+
+	sync.Mutex
+	groups  map[string]*group
+	dropped int
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// group is the aggregate of the entries of a group within the current window
+type group struct {
+	values   []interface{}
+	exists   []bool
+	count    int
+	sums     []float64
+	averages []average
+}
+
+// average is the running total of the values of a numeric field
+type average struct {
+	total float64
+	count int
+}
+
+// Start will start writing summaries at the end of each window
+func (a *AggregateOperator) Start(_ operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.goFlush(ctx)
+	return nil
+}
+
+// Stop will stop writing summaries, and write the summaries of the current window
+func (a *AggregateOperator) Stop() error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	a.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	a.flush(ctx)
+	return nil
+}
+
+// goFlush will write summaries at the end of each window
+func (a *AggregateOperator) goFlush(ctx context.Context) {
+	a.wg.Add(1)
+
+	go func() {
+		defer a.wg.Done()
+
+		ticker := time.NewTicker(a.window)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.flush(ctx)
+			}
+		}
+	}()
+}
+
+// Process will add an entry to the aggregate of its group
+func (a *AggregateOperator) Process(ctx context.Context, e *entry.Entry) error {
+	skip, err := a.Skip(ctx, e)
+	if err != nil {
+		return a.HandleEntryError(ctx, e, err)
+	}
+	if skip {
+		a.Write(ctx, e)
+		return nil
+	}
+
+	values := make([]interface{}, len(a.groupBy))
+	exists := make([]bool, len(a.groupBy))
+	var key strings.Builder
+	for i, field := range a.groupBy {
+		values[i], exists[i] = e.Get(field)
+		if exists[i] {
+			_, _ = fmt.Fprintf(&key, "%v", values[i])
+		} else {
+			// Distinguish a missing field from an empty value
+			key.WriteByte(1)
+		}
+		// Separate values so that adjacent values cannot produce the same key
+		key.WriteByte(0)
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	g, ok := a.groups[key.String()]
+	if !ok {
+		if len(a.groups) >= a.maxGroups {
+			a.dropped++
+			return nil
+		}
+		g = &group{
+			values:   values,
+			exists:   exists,
+			sums:     make([]float64, len(a.sums)),
+			averages: make([]average, len(a.averages)),
+		}
+		a.groups[key.String()] = g
+	}
+
+	g.count++
+	for i, f := range a.sums {
+		if n, ok := helper.NumericValue(e, f.field); ok {
+			g.sums[i] += n
+		}
+	}
+	for i, f := range a.averages {
+		if n, ok := helper.NumericValue(e, f.field); ok {
+			g.averages[i].total += n
+			g.averages[i].count++
+		}
+	}
+	return nil
+}
+
+// flush will write a summary of each group, and start a new window
+func (a *AggregateOperator) flush(ctx context.Context) {
+	a.Lock()
+	groups := a.groups
+	dropped := a.dropped
+	a.groups = make(map[string]*group, len(groups))
+	a.dropped = 0
+	a.Unlock()
+
+	if dropped > 0 {
+		a.Warnf("Dropped %d entries of groups beyond max_groups", dropped)
+	}
+
+	timestamp := a.now()
+	for _, g := range groups {
+		summary, err := a.summarize(g, timestamp)
+		if err != nil {
+			a.Errorf("Failed to create summary entry: %s", err)
+			continue
+		}
+		a.Write(ctx, summary)
+	}
+}
+
+// summarize will create the summary entry of a group.
+func (a *AggregateOperator) summarize(g *group, timestamp time.Time) (*entry.Entry, error) {
+	body := map[string]interface{}{
+		countKey: g.count,
+	}
+
+	if len(a.sums) > 0 {
+		sums := make(map[string]interface{}, len(a.sums))
+		for i, f := range a.sums {
+			sums[f.name] = g.sums[i]
+		}
+		body[sumKey] = sums
+	}
+
+	if len(a.averages) > 0 {
+		averages := make(map[string]interface{}, len(a.averages))
+		for i, f := range a.averages {
+			if g.averages[i].count > 0 {
+				averages[f.name] = g.averages[i].total / float64(g.averages[i].count)
+			}
+		}
+		body[averageKey] = averages
+	}
+
+	summary := entry.New()
+	summary.Timestamp = timestamp
+	summary.Body = body
+	for i, field := range a.groupBy {
+		if !g.exists[i] {
+			continue
+		}
+		value := g.values[i]
+		if _, ok := field.FieldInterface.(entry.BodyField); !ok {
+			// Attributes and resource values are strings
+			value = fmt.Sprintf("%v", value)
+		}
+		if err := summary.Set(field, value); err != nil {
+			return nil, err
+		}
+	}
+	return summary, nil
+}
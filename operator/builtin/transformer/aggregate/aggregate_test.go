@@ -0,0 +1,272 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestOperator(t *testing.T, cfg *AggregateOperatorConfig) (*AggregateOperator, *testutil.FakeOutput) {
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*AggregateOperator)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fakeOutput}))
+	return op, fakeOutput
+}
+
+func newEntry(body interface{}, attributes map[string]string) *entry.Entry {
+	e := entry.New()
+	e.Body = body
+	e.Attributes = attributes
+	return e
+}
+
+// receiveAll will receive a number of entries, keyed by a function of each entry
+func receiveAll(t *testing.T, fake *testutil.FakeOutput, n int, key func(*entry.Entry) string) map[string]*entry.Entry {
+	received := make(map[string]*entry.Entry, n)
+	for i := 0; i < n; i++ {
+		select {
+		case e := <-fake.Received:
+			received[key(e)] = e
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for summary entry")
+		}
+	}
+	fake.ExpectNoEntry(t, 0)
+	return received
+}
+
+func TestAggregateOperatorBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*AggregateOperatorConfig)
+		expectedErr string
+	}{
+		{
+			"Default",
+			func(cfg *AggregateOperatorConfig) {},
+			"",
+		},
+		{
+			"ZeroWindow",
+			func(cfg *AggregateOperatorConfig) { cfg.Window = helper.NewDuration(0) },
+			"window must be greater than 0",
+		},
+		{
+			"ZeroMaxGroups",
+			func(cfg *AggregateOperatorConfig) { cfg.MaxGroups = 0 },
+			"max_groups must be greater than 0",
+		},
+		{
+			"GroupByBody",
+			func(cfg *AggregateOperatorConfig) { cfg.GroupBy = []entry.Field{entry.NewBodyField()} },
+			"group_by cannot contain the entire body",
+		},
+		{
+			"GroupByConflict",
+			func(cfg *AggregateOperatorConfig) { cfg.GroupBy = []entry.Field{entry.NewBodyField("count")} },
+			"conflicts with the 'count' key",
+		},
+		{
+			"SumAttribute",
+			func(cfg *AggregateOperatorConfig) { cfg.Sum = []entry.Field{entry.NewAttributeField("bytes")} },
+			"sum field $attributes.bytes must be a field of the body",
+		},
+		{
+			"AverageBody",
+			func(cfg *AggregateOperatorConfig) { cfg.Average = []entry.Field{entry.NewBodyField()} },
+			"average field $body must be a field of the body",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewAggregateOperatorConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestAggregateOperatorCount(t *testing.T) {
+	op, fake := newTestOperator(t, NewAggregateOperatorConfig("test"))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, op.Process(ctx, newEntry("request", nil)))
+	}
+	fake.ExpectNoEntry(t, 0)
+
+	end := time.Date(2021, 6, 1, 12, 1, 0, 0, time.UTC)
+	op.now = func() time.Time { return end }
+	op.flush(ctx)
+
+	select {
+	case e := <-fake.Received:
+		require.Equal(t, map[string]interface{}{"count": 3}, e.Body)
+		require.Equal(t, end, e.Timestamp)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for summary entry")
+	}
+
+	// Each window starts with no groups
+	op.flush(ctx)
+	fake.ExpectNoEntry(t, 0)
+}
+
+func TestAggregateOperatorGroups(t *testing.T) {
+	cfg := NewAggregateOperatorConfig("test")
+	cfg.GroupBy = []entry.Field{entry.NewBodyField("service"), entry.NewAttributeField("host")}
+	cfg.Sum = []entry.Field{entry.NewBodyField("bytes")}
+	cfg.Average = []entry.Field{entry.NewBodyField("timing", "duration")}
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+
+	entries := []*entry.Entry{
+		newEntry(map[string]interface{}{"service": "api", "bytes": 100, "timing": map[string]interface{}{"duration": 0.5}}, map[string]string{"host": "a"}),
+		newEntry(map[string]interface{}{"service": "api", "bytes": "50", "timing": map[string]interface{}{"duration": 1.5}}, map[string]string{"host": "a"}),
+		newEntry(map[string]interface{}{"service": "api", "bytes": "unknown"}, map[string]string{"host": "a"}),
+		newEntry(map[string]interface{}{"service": "api", "bytes": 10.5}, map[string]string{"host": "b"}),
+		newEntry(map[string]interface{}{"bytes": 1}, nil),
+	}
+	for _, e := range entries {
+		require.NoError(t, op.Process(ctx, e))
+	}
+	fake.ExpectNoEntry(t, 0)
+
+	op.flush(ctx)
+	received := receiveAll(t, fake, 3, func(e *entry.Entry) string {
+		return e.Attributes["host"]
+	})
+
+	require.Equal(t, map[string]interface{}{
+		"service": "api",
+		"count":   3,
+		"sum":     map[string]interface{}{"bytes": 150.0},
+		"average": map[string]interface{}{"timing.duration": 1.0},
+	}, received["a"].Body)
+	require.Equal(t, map[string]string{"host": "a"}, received["a"].Attributes)
+
+	require.Equal(t, map[string]interface{}{
+		"service": "api",
+		"count":   1,
+		"sum":     map[string]interface{}{"bytes": 10.5},
+		"average": map[string]interface{}{},
+	}, received["b"].Body)
+
+	// Fields which are missing are not set on the summary
+	require.Equal(t, map[string]interface{}{
+		"count":   1,
+		"sum":     map[string]interface{}{"bytes": 1.0},
+		"average": map[string]interface{}{},
+	}, received[""].Body)
+	require.Nil(t, received[""].Attributes)
+}
+
+func TestAggregateOperatorMissingAndEmpty(t *testing.T) {
+	cfg := NewAggregateOperatorConfig("test")
+	cfg.GroupBy = []entry.Field{entry.NewBodyField("service")}
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+
+	require.NoError(t, op.Process(ctx, newEntry(map[string]interface{}{"service": ""}, nil)))
+	require.NoError(t, op.Process(ctx, newEntry(map[string]interface{}{}, nil)))
+
+	op.flush(ctx)
+	received := receiveAll(t, fake, 2, func(e *entry.Entry) string {
+		_, ok := e.Body.(map[string]interface{})["service"]
+		if ok {
+			return "empty"
+		}
+		return "missing"
+	})
+	require.Len(t, received, 2)
+}
+
+func TestAggregateOperatorMaxGroups(t *testing.T) {
+	cfg := NewAggregateOperatorConfig("test")
+	cfg.GroupBy = []entry.Field{entry.NewBodyField("service")}
+	cfg.MaxGroups = 1
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+
+	require.NoError(t, op.Process(ctx, newEntry(map[string]interface{}{"service": "api"}, nil)))
+	require.NoError(t, op.Process(ctx, newEntry(map[string]interface{}{"service": "web"}, nil)))
+	require.NoError(t, op.Process(ctx, newEntry(map[string]interface{}{"service": "api"}, nil)))
+
+	op.flush(ctx)
+	fake.ExpectBody(t, map[string]interface{}{"service": "api", "count": 2})
+	fake.ExpectNoEntry(t, 0)
+}
+
+func TestAggregateOperatorIf(t *testing.T) {
+	cfg := NewAggregateOperatorConfig("test")
+	cfg.IfExpr = `$body.level == "debug"`
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+
+	// Entries which do not match the if expression are written unchanged
+	info := map[string]interface{}{"level": "info"}
+	require.NoError(t, op.Process(ctx, newEntry(info, nil)))
+	fake.ExpectBody(t, info)
+
+	require.NoError(t, op.Process(ctx, newEntry(map[string]interface{}{"level": "debug"}, nil)))
+	fake.ExpectNoEntry(t, 0)
+}
+
+func TestAggregateOperatorWindow(t *testing.T) {
+	cfg := NewAggregateOperatorConfig("test")
+	cfg.Window = helper.NewDuration(50 * time.Millisecond)
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	defer func() {
+		require.NoError(t, op.Stop())
+	}()
+
+	require.NoError(t, op.Process(ctx, newEntry("request", nil)))
+	require.NoError(t, op.Process(ctx, newEntry("request", nil)))
+	fake.ExpectBody(t, map[string]interface{}{"count": 2})
+}
+
+func TestAggregateOperatorStop(t *testing.T) {
+	op, fake := newTestOperator(t, NewAggregateOperatorConfig("test"))
+	ctx := context.Background()
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+
+	require.NoError(t, op.Process(ctx, newEntry("request", nil)))
+
+	// The current window is summarized when the operator stops
+	require.NoError(t, op.Stop())
+	fake.ExpectBody(t, map[string]interface{}{"count": 1})
+}
@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestAggregateOperatorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "window",
+			Expect: func() *AggregateOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Window = helper.NewDuration(30 * time.Second)
+				return cfg
+			}(),
+		},
+		{
+			Name: "fields",
+			Expect: func() *AggregateOperatorConfig {
+				cfg := defaultCfg()
+				cfg.GroupBy = []entry.Field{entry.NewBodyField("service"), entry.NewAttributeField("host")}
+				cfg.Sum = []entry.Field{entry.NewBodyField("bytes")}
+				cfg.Average = []entry.Field{entry.NewBodyField("duration")}
+				return cfg
+			}(),
+		},
+		{
+			Name: "max_groups",
+			Expect: func() *AggregateOperatorConfig {
+				cfg := defaultCfg()
+				cfg.MaxGroups = 100
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *AggregateOperatorConfig {
+	return NewAggregateOperatorConfig("aggregate")
+}
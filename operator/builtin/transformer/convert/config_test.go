@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestConvertOperatorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "fields",
+			Expect: func() *ConvertOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Fields = []FieldConfig{
+					{Field: entry.NewBodyField("status"), Type: IntType},
+					{Field: entry.NewAttributeField("client_ip"), Type: IPType},
+				}
+				return cfg
+			}(),
+		},
+		{
+			Name: "best_effort",
+			Expect: func() *ConvertOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Mode = BestEffortMode
+				cfg.Fields = []FieldConfig{
+					{Field: entry.NewBodyField("duration"), Type: FloatType},
+				}
+				return cfg
+			}(),
+		},
+		{
+			Name: "auto_numeric",
+			Expect: func() *ConvertOperatorConfig {
+				cfg := defaultCfg()
+				cfg.AutoNumeric = []entry.BodyField{
+					{Keys: []string{"metrics"}},
+					{Keys: []string{"request", "headers"}},
+				}
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *ConvertOperatorConfig {
+	return NewConvertOperatorConfig("convert")
+}
@@ -0,0 +1,188 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// Modes of handling values which cannot be converted
+const (
+	// StrictMode fails the conversion of an entry if any field cannot be converted
+	StrictMode = "strict"
+
+	// BestEffortMode leaves fields which cannot be converted as they are
+	BestEffortMode = "best_effort"
+)
+
+func init() {
+	operator.Register("convert", func() operator.Builder { return NewConvertOperatorConfig("") })
+}
+
+// NewConvertOperatorConfig creates a new convert operator config with default values
+func NewConvertOperatorConfig(operatorID string) *ConvertOperatorConfig {
+	return &ConvertOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "convert"),
+		Mode:              StrictMode,
+	}
+}
+
+// ConvertOperatorConfig is the configuration of a convert operator
+type ConvertOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+	Fields                   []FieldConfig     `mapstructure:"fields"       json:"fields,omitempty"       yaml:"fields,omitempty"`
+	Mode                     string            `mapstructure:"mode"         json:"mode"                   yaml:"mode"`
+	AutoNumeric              []entry.BodyField `mapstructure:"auto_numeric" json:"auto_numeric,omitempty" yaml:"auto_numeric,omitempty"`
+}
+
+// FieldConfig is the configuration of a field to convert
+type FieldConfig struct {
+	Field entry.Field `mapstructure:"field" json:"field" yaml:"field"`
+	Type  string      `mapstructure:"type"  json:"type"  yaml:"type"`
+}
+
+// Build will build a convert operator from the supplied configuration
+func (c ConvertOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Fields) == 0 && len(c.AutoNumeric) == 0 {
+		return nil, fmt.Errorf("at least one of 'fields' or 'auto_numeric' is required")
+	}
+
+	if c.Mode != StrictMode && c.Mode != BestEffortMode {
+		return nil, fmt.Errorf("invalid mode '%s', must be '%s' or '%s'", c.Mode, StrictMode, BestEffortMode)
+	}
+
+	conversions := make([]conversion, 0, len(c.Fields))
+	for i, f := range c.Fields {
+		if f.Field.FieldInterface == nil {
+			return nil, fmt.Errorf("missing field of fields[%d]", i)
+		}
+		convert, ok := converters[f.Type]
+		if !ok {
+			return nil, fmt.Errorf("invalid type '%s' of field %s, must be one of %s, %s, %s, %s or %s",
+				f.Type, f.Field, IntType, FloatType, BoolType, StringType, IPType)
+		}
+		// Attributes and resource values are strings, so only body fields can hold other types
+		if _, ok := f.Field.FieldInterface.(entry.BodyField); !ok && f.Type != StringType && f.Type != IPType {
+			return nil, fmt.Errorf("field %s must be a body field to be converted to type '%s'", f.Field, f.Type)
+		}
+		conversions = append(conversions, conversion{field: f.Field, convert: convert})
+	}
+
+	convertOperator := &ConvertOperator{
+		TransformerOperator: transformerOperator,
+		conversions:         conversions,
+		strict:              c.Mode == StrictMode,
+		autoNumeric:         c.AutoNumeric,
+	}
+
+	return []operator.Operator{convertOperator}, nil
+}
+
+// ConvertOperator is an operator that converts the types of fields
+type ConvertOperator struct {
+	helper.TransformerOperator
+	conversions []conversion
+	strict      bool
+	autoNumeric []entry.BodyField
+}
+
+// conversion converts the value of a field to a type
+type conversion struct {
+	field   entry.Field
+	convert func(interface{}) (interface{}, error)
+}
+
+// Process will process an entry with a convert transformation.
+func (p *ConvertOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ProcessWith(ctx, entry, p.Transform)
+}
+
+// Transform will convert the fields of an entry, and then the numeric strings under the auto_numeric fields.
+// Fields which do not exist are skipped. In strict mode, if any field cannot be converted, the entry is not modified.
+func (p *ConvertOperator) Transform(e *entry.Entry) error {
+	converted := make([]interface{}, len(p.conversions))
+	var errs []string
+	for i, c := range p.conversions {
+		value, ok := e.Get(c.field)
+		if !ok {
+			continue
+		}
+		v, err := c.convert(value)
+		if err != nil {
+			if p.strict {
+				return fmt.Errorf("convert field %s: %s", c.field, err)
+			}
+			errs = append(errs, fmt.Sprintf("%s: %s", c.field, err))
+			continue
+		}
+		converted[i] = v
+	}
+
+	for i, c := range p.conversions {
+		if converted[i] == nil {
+			continue
+		}
+		if err := e.Set(c.field, converted[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range p.autoNumeric {
+		if value, ok := e.Get(field); ok {
+			if err := e.Set(field, convertNumeric(value)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		p.Debugw("Failed to convert fields", "errors", errs)
+	}
+	return nil
+}
+
+// convertNumeric will convert a string which looks like a number, and the numeric strings
+// of the maps and arrays nested in a value, to numbers.
+func convertNumeric(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if n, ok := parseNumeric(v); ok {
+			return n
+		}
+		return v
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = convertNumeric(child)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertNumeric(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestConvertOperatorBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*ConvertOperatorConfig)
+		expectedErr string
+	}{
+		{
+			"Fields",
+			func(cfg *ConvertOperatorConfig) {
+				cfg.Fields = []FieldConfig{{Field: entry.NewBodyField("status"), Type: IntType}}
+			},
+			"",
+		},
+		{
+			"AutoNumeric",
+			func(cfg *ConvertOperatorConfig) {
+				cfg.AutoNumeric = []entry.BodyField{{Keys: []string{"metrics"}}}
+			},
+			"",
+		},
+		{
+			"AttributeIP",
+			func(cfg *ConvertOperatorConfig) {
+				cfg.Fields = []FieldConfig{{Field: entry.NewAttributeField("client"), Type: IPType}}
+			},
+			"",
+		},
+		{
+			"NothingToConvert",
+			func(cfg *ConvertOperatorConfig) {},
+			"at least one of 'fields' or 'auto_numeric' is required",
+		},
+		{
+			"InvalidMode",
+			func(cfg *ConvertOperatorConfig) {
+				cfg.Mode = "lenient"
+				cfg.Fields = []FieldConfig{{Field: entry.NewBodyField("status"), Type: IntType}}
+			},
+			"invalid mode 'lenient'",
+		},
+		{
+			"MissingField",
+			func(cfg *ConvertOperatorConfig) {
+				cfg.Fields = []FieldConfig{{Type: IntType}}
+			},
+			"missing field of fields[0]",
+		},
+		{
+			"InvalidType",
+			func(cfg *ConvertOperatorConfig) {
+				cfg.Fields = []FieldConfig{{Field: entry.NewBodyField("status"), Type: "number"}}
+			},
+			"invalid type 'number' of field status",
+		},
+		{
+			"AttributeInt",
+			func(cfg *ConvertOperatorConfig) {
+				cfg.Fields = []FieldConfig{{Field: entry.NewAttributeField("status"), Type: IntType}}
+			},
+			"must be a body field to be converted to type 'int'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewConvertOperatorConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestConvertOperatorTransform(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*ConvertOperatorConfig)
+		input       interface{}
+		expected    interface{}
+		expectedErr string
+	}{
+		{
+			"Fields",
+			func(cfg *ConvertOperatorConfig) {
+				cfg.Fields = []FieldConfig{
+					{Field: entry.NewBodyField("status"), Type: IntType},
+					{Field: entry.NewBodyField("duration"), Type: FloatType},
+					{Field: entry.NewBodyField("cached"), Type: BoolType},
+					{Field: entry.NewBodyField("id"), Type: StringType},
+					{Field: entry.NewBodyField("missing"), Type: IntType},
+				}
+			},
+			map[string]interface{}{"status": "200", "duration": "1.5", "cached": "yes", "id": 12},
+			map[string]interface{}{"status": int64(200), "duration": 1.5, "cached": true, "id": "12"},
+			"",
+		},
+		{
+			"StrictFailure",
+			func(cfg *ConvertOperatorConfig) {
+				cfg.Fields = []FieldConfig{
+					{Field: entry.NewBodyField("status"), Type: IntType},
+					{Field: entry.NewBodyField("duration"), Type: FloatType},
+				}
+			},
+			map[string]interface{}{"status": "200", "duration": "fast"},
+			map[string]interface{}{"status": "200", "duration": "fast"},
+			"convert field duration: invalid float 'fast'",
+		},
+		{
+			"BestEffortFailure",
+			func(cfg *ConvertOperatorConfig) {
+				cfg.Mode = BestEffortMode
+				cfg.Fields = []FieldConfig{
+					{Field: entry.NewBodyField("status"), Type: IntType},
+					{Field: entry.NewBodyField("duration"), Type: FloatType},
+				}
+			},
+			map[string]interface{}{"status": "200", "duration": "fast"},
+			map[string]interface{}{"status": int64(200), "duration": "fast"},
+			"",
+		},
+		{
+			"AutoNumeric",
+			func(cfg *ConvertOperatorConfig) {
+				cfg.AutoNumeric = []entry.BodyField{{Keys: []string{"metrics"}}}
+			},
+			map[string]interface{}{
+				"metrics": map[string]interface{}{
+					"count":   "10",
+					"ratio":   "0.75",
+					"zip":     "02134",
+					"name":    "web",
+					"samples": []interface{}{"1", "2.5", "n/a"},
+					"nested":  map[string]interface{}{"size": "-3"},
+				},
+				"port": "8080",
+			},
+			map[string]interface{}{
+				"metrics": map[string]interface{}{
+					"count":   int64(10),
+					"ratio":   0.75,
+					"zip":     "02134",
+					"name":    "web",
+					"samples": []interface{}{int64(1), 2.5, "n/a"},
+					"nested":  map[string]interface{}{"size": int64(-3)},
+				},
+				"port": "8080",
+			},
+			"",
+		},
+		{
+			"AutoNumericBody",
+			func(cfg *ConvertOperatorConfig) {
+				cfg.AutoNumeric = []entry.BodyField{{Keys: []string{}}}
+			},
+			"42",
+			int64(42),
+			"",
+		},
+		{
+			"FieldsBeforeAutoNumeric",
+			func(cfg *ConvertOperatorConfig) {
+				cfg.Fields = []FieldConfig{{Field: entry.NewBodyField("code"), Type: StringType}}
+				cfg.AutoNumeric = []entry.BodyField{{Keys: []string{}}}
+			},
+			map[string]interface{}{"code": 7, "count": "3"},
+			map[string]interface{}{"code": int64(7), "count": int64(3)},
+			"",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewConvertOperatorConfig("test")
+			tc.modify(cfg)
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0].(*ConvertOperator)
+
+			e := entry.New()
+			e.Body = tc.input
+			err = op.Transform(e)
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expected, e.Body)
+		})
+	}
+}
+
+func TestConvertOperatorAttributes(t *testing.T) {
+	cfg := NewConvertOperatorConfig("test")
+	cfg.Fields = []FieldConfig{
+		{Field: entry.NewAttributeField("client"), Type: IPType},
+		{Field: entry.NewResourceField("host"), Type: IPType},
+	}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*ConvertOperator)
+
+	e := entry.New()
+	e.Attributes = map[string]string{"client": "2001:DB8::0001"}
+	e.Resource = map[string]string{"host": "not-an-ip"}
+	err = op.Transform(e)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid IP address 'not-an-ip'")
+	require.Equal(t, "2001:DB8::0001", e.Attributes["client"])
+
+	e.Resource["host"] = "10.0.0.1"
+	require.NoError(t, op.Transform(e))
+	require.Equal(t, "2001:db8::1", e.Attributes["client"])
+	require.Equal(t, "10.0.0.1", e.Resource["host"])
+}
+
+func TestConvertOperatorProcess(t *testing.T) {
+	cfg := NewConvertOperatorConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Fields = []FieldConfig{{Field: entry.NewBodyField("status"), Type: IntType}}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*ConvertOperator)
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = map[string]interface{}{"status": "404"}
+	require.NoError(t, op.Process(context.Background(), e))
+	fake.ExpectBody(t, map[string]interface{}{"status": int64(404)})
+}
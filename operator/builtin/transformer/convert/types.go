@@ -0,0 +1,223 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// Types which values can be converted to
+const (
+	IntType    = "int"
+	FloatType  = "float"
+	BoolType   = "bool"
+	StringType = "string"
+	IPType     = "ip"
+)
+
+// converters are the conversion functions of each type.
+var converters = map[string]func(interface{}) (interface{}, error){
+	IntType:    toInt,
+	FloatType:  toFloat,
+	BoolType:   toBool,
+	StringType: toString,
+	IPType:     toIP,
+}
+
+// toInt will convert a value to an int64. Floats and strings of floats are converted
+// only if they have no fractional part.
+func toInt(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		s := strings.TrimSpace(v)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int '%s'", v)
+		}
+		return floatToInt(f)
+	case []byte:
+		return toInt(string(v))
+	case bool:
+		return nil, fmt.Errorf("type 'bool' cannot be converted to an int")
+	default:
+		if i, ok := asInt(value); ok {
+			return i, nil
+		}
+		if f, ok := helper.ToFloat64(value); ok {
+			return floatToInt(f)
+		}
+		return nil, fmt.Errorf("type '%T' cannot be converted to an int", value)
+	}
+}
+
+// floatToInt will convert a float without a fractional part to an int64.
+func floatToInt(f float64) (interface{}, error) {
+	if f != math.Trunc(f) || math.IsInf(f, 0) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return nil, fmt.Errorf("%v cannot be converted to an int", f)
+	}
+	return int64(f), nil
+}
+
+// toFloat will convert a value to a float64.
+func toFloat(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float '%s'", v)
+		}
+		return f, nil
+	case []byte:
+		return toFloat(string(v))
+	default:
+		if f, ok := helper.ToFloat64(value); ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("type '%T' cannot be converted to a float", value)
+	}
+}
+
+// toBool will convert a value to a bool. Strings such as "true", "yes", "on" and "1" are true,
+// and strings such as "false", "no", "off" and "0" are false. The numbers 1 and 0 are also accepted.
+func toBool(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "t", "yes", "y", "on", "1":
+			return true, nil
+		case "false", "f", "no", "n", "off", "0":
+			return false, nil
+		default:
+			return nil, fmt.Errorf("invalid bool '%s'", v)
+		}
+	case []byte:
+		return toBool(string(v))
+	default:
+		if f, ok := helper.ToFloat64(value); ok && (f == 0 || f == 1) {
+			return f == 1, nil
+		}
+		return nil, fmt.Errorf("value '%v' cannot be converted to a bool", value)
+	}
+}
+
+// toString will convert a scalar value to a string.
+func toString(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	default:
+		if i, ok := asInt(value); ok {
+			return strconv.FormatInt(i, 10), nil
+		}
+		return nil, fmt.Errorf("type '%T' cannot be converted to a string", value)
+	}
+}
+
+// toIP will convert an IP address to its canonical string form.
+func toIP(value interface{}) (interface{}, error) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case net.IP:
+		return v.String(), nil
+	default:
+		return nil, fmt.Errorf("type '%T' cannot be converted to an IP address", value)
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(s))
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address '%s'", s)
+	}
+	return ip.String(), nil
+}
+
+// asInt will return the value of an integer of any type, except uint64 values which overflow an int64.
+func asInt(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), uint64(v) <= math.MaxInt64
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	default:
+		return 0, false
+	}
+}
+
+var (
+	// intPattern matches integers without leading zeros
+	intPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+
+	// floatPattern matches decimal numbers without leading zeros, with an optional exponent
+	floatPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+)
+
+// parseNumeric will convert a string which looks like a number to an int64 or float64.
+// Strings with leading zeros, such as "007", are identifiers rather than numbers, and are not converted.
+func parseNumeric(s string) (interface{}, bool) {
+	if intPattern.MatchString(s) {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, true
+		}
+	}
+	if floatPattern.MatchString(s) {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
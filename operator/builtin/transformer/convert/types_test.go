@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverters(t *testing.T) {
+	cases := []struct {
+		name        string
+		typ         string
+		input       interface{}
+		expected    interface{}
+		expectedErr string
+	}{
+		{"IntFromString", IntType, " 42 ", int64(42), ""},
+		{"IntFromIntegralFloatString", IntType, "42.0", int64(42), ""},
+		{"IntFromFractionalString", IntType, "42.5", nil, "cannot be converted to an int"},
+		{"IntFromInvalidString", IntType, "forty-two", nil, "invalid int 'forty-two'"},
+		{"IntFromInt32", IntType, int32(7), int64(7), ""},
+		{"IntFromFloat", IntType, float64(3), int64(3), ""},
+		{"IntFromUintOverflow", IntType, uint64(1 << 63), nil, "cannot be converted to an int"},
+		{"IntFromBool", IntType, true, nil, "type 'bool' cannot be converted to an int"},
+		{"IntFromBytes", IntType, []byte("12"), int64(12), ""},
+		{"FloatFromString", FloatType, "1.5e3", 1500.0, ""},
+		{"FloatFromInt", FloatType, 3, 3.0, ""},
+		{"FloatFromInvalidString", FloatType, "fast", nil, "invalid float 'fast'"},
+		{"FloatFromMap", FloatType, map[string]interface{}{}, nil, "cannot be converted to a float"},
+		{"BoolFromYes", BoolType, "Yes", true, ""},
+		{"BoolFromOff", BoolType, "off", false, ""},
+		{"BoolFromOne", BoolType, 1, true, ""},
+		{"BoolFromTwo", BoolType, 2, nil, "cannot be converted to a bool"},
+		{"BoolFromInvalidString", BoolType, "maybe", nil, "invalid bool 'maybe'"},
+		{"StringFromInt", StringType, int64(-12), "-12", ""},
+		{"StringFromFloat", StringType, 0.25, "0.25", ""},
+		{"StringFromBool", StringType, false, "false", ""},
+		{"StringFromUint64", StringType, uint64(1 << 63), "9223372036854775808", ""},
+		{"StringFromMap", StringType, map[string]interface{}{}, nil, "cannot be converted to a string"},
+		{"IPv4", IPType, " 10.0.0.1 ", "10.0.0.1", ""},
+		{"IPv6", IPType, "2001:DB8:0:0:0:0:0:1", "2001:db8::1", ""},
+		{"IPFromNetIP", IPType, net.IPv4(192, 168, 0, 1), "192.168.0.1", ""},
+		{"InvalidIP", IPType, "10.0.0", nil, "invalid IP address '10.0.0'"},
+		{"IPFromInt", IPType, 10, nil, "cannot be converted to an IP address"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := converters[tc.typ](tc.input)
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestParseNumeric(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected interface{}
+		ok       bool
+	}{
+		{"0", int64(0), true},
+		{"-17", int64(-17), true},
+		{"3.14", 3.14, true},
+		{"-0.5", -0.5, true},
+		{"1e3", 1000.0, true},
+		{"92233720368547758070", 9.223372036854776e19, true},
+		{"007", nil, false},
+		{"1.", nil, false},
+		{".5", nil, false},
+		{"+1", nil, false},
+		{" 1", nil, false},
+		{"0x1F", nil, false},
+		{"NaN", nil, false},
+		{"Inf", nil, false},
+		{"", nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			value, ok := parseNumeric(tc.input)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.expected, value)
+		})
+	}
+}
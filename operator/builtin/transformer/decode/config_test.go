@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decode
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestDecodeOperatorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "hex",
+			Expect: func() *DecodeOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Encoding = HexEncoding
+				cfg.ParseFrom = entry.NewBodyField("payload")
+				return cfg
+			}(),
+		},
+		{
+			Name: "gzip",
+			Expect: func() *DecodeOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Compression = GzipCompression
+				cfg.MaxSize = 1024 * 1024
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *DecodeOperatorConfig {
+	return NewDecodeOperatorConfig("decode")
+}
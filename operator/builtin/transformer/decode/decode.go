@@ -0,0 +1,181 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// Encodings of the decoded field
+const (
+	Base64Encoding    = "base64"
+	Base64URLEncoding = "base64url"
+	HexEncoding       = "hex"
+)
+
+// Compressions of the decoded field
+const (
+	NoCompression   = ""
+	GzipCompression = "gzip"
+	AutoCompression = "auto"
+)
+
+// DefaultMaxSize is the maximum size of a decompressed value when no size is configured
+const DefaultMaxSize = 10 * 1024 * 1024
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+func init() {
+	operator.Register("decode", func() operator.Builder { return NewDecodeOperatorConfig("") })
+}
+
+// NewDecodeOperatorConfig creates a new decode operator config with default values
+func NewDecodeOperatorConfig(operatorID string) *DecodeOperatorConfig {
+	return &DecodeOperatorConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "decode"),
+		Encoding:     Base64Encoding,
+		MaxSize:      DefaultMaxSize,
+	}
+}
+
+// DecodeOperatorConfig is the configuration of a decode operator
+type DecodeOperatorConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	Encoding    string          `mapstructure:"encoding,omitempty"    json:"encoding,omitempty"    yaml:"encoding,omitempty"`
+	Compression string          `mapstructure:"compression,omitempty" json:"compression,omitempty" yaml:"compression,omitempty"`
+	MaxSize     helper.ByteSize `mapstructure:"max_size,omitempty"    json:"max_size,omitempty"    yaml:"max_size,omitempty"`
+}
+
+// Build will build a decode operator from the supplied configuration
+func (c DecodeOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Encoding {
+	case Base64Encoding, Base64URLEncoding, HexEncoding:
+	default:
+		return nil, fmt.Errorf("invalid encoding '%s', must be one of '%s', '%s' or '%s'",
+			c.Encoding, Base64Encoding, Base64URLEncoding, HexEncoding)
+	}
+
+	switch c.Compression {
+	case NoCompression, GzipCompression, AutoCompression:
+	default:
+		return nil, fmt.Errorf("invalid compression '%s', must be '%s' or '%s'", c.Compression, GzipCompression, AutoCompression)
+	}
+
+	if c.MaxSize <= 0 {
+		return nil, fmt.Errorf("invalid value for parameter 'max_size', must be greater than 0")
+	}
+
+	decodeOperator := &DecodeOperator{
+		ParserOperator: parserOperator,
+		encoding:       c.Encoding,
+		compression:    c.Compression,
+		maxSize:        int64(c.MaxSize),
+	}
+
+	return []operator.Operator{decodeOperator}, nil
+}
+
+// DecodeOperator is an operator that decodes a base64 or hex encoded field
+type DecodeOperator struct {
+	helper.ParserOperator
+	encoding    string
+	compression string
+	maxSize     int64
+}
+
+// Process will decode an entry's field
+func (d *DecodeOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return d.ParserOperator.ProcessWith(ctx, entry, d.decode)
+}
+
+// decode will decode a value, and decompress it if configured. The result is a string
+// if it is valid UTF-8, and bytes otherwise.
+func (d *DecodeOperator) decode(value interface{}) (interface{}, error) {
+	var encoded string
+	switch v := value.(type) {
+	case string:
+		encoded = v
+	case []byte:
+		encoded = string(v)
+	default:
+		return nil, fmt.Errorf("type %T cannot be decoded", value)
+	}
+
+	decoded, err := d.decodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %s", d.encoding, err)
+	}
+
+	if d.compression == GzipCompression || (d.compression == AutoCompression && bytes.HasPrefix(decoded, gzipMagic)) {
+		decoded, err = d.gunzip(decoded)
+		if err != nil {
+			return nil, fmt.Errorf("decompress gzip: %s", err)
+		}
+	}
+
+	if utf8.Valid(decoded) {
+		return string(decoded), nil
+	}
+	return decoded, nil
+}
+
+// decodeString will decode a string with the configured encoding. Base64 may be padded or unpadded.
+func (d *DecodeOperator) decodeString(encoded string) ([]byte, error) {
+	switch d.encoding {
+	case HexEncoding:
+		return hex.DecodeString(encoded)
+	case Base64URLEncoding:
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	default:
+		return base64.RawStdEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	}
+}
+
+// gunzip will decompress gzip data, up to the maximum size.
+func (d *DecodeOperator) gunzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	decompressed, err := ioutil.ReadAll(io.LimitReader(reader, d.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(decompressed)) > d.maxSize {
+		return nil, fmt.Errorf("decompressed size exceeds max_size of %d bytes", d.maxSize)
+	}
+	return decompressed, nil
+}
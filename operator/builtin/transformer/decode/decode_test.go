@@ -0,0 +1,254 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func newTestOperator(t *testing.T, modify func(*DecodeOperatorConfig)) *DecodeOperator {
+	cfg := NewDecodeOperatorConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	modify(cfg)
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	return ops[0].(*DecodeOperator)
+}
+
+func TestDecodeBuildFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*DecodeOperatorConfig)
+		expected string
+	}{
+		{
+			"InvalidEncoding",
+			func(cfg *DecodeOperatorConfig) {
+				cfg.Encoding = "base32"
+			},
+			"invalid encoding 'base32'",
+		},
+		{
+			"InvalidCompression",
+			func(cfg *DecodeOperatorConfig) {
+				cfg.Compression = "zstd"
+			},
+			"invalid compression 'zstd'",
+		},
+		{
+			"InvalidMaxSize",
+			func(cfg *DecodeOperatorConfig) {
+				cfg.MaxSize = 0
+			},
+			"invalid value for parameter 'max_size'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewDecodeOperatorConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	message := []byte(`{"message":"hello world"}`)
+	binary := []byte{0x08, 0x96, 0x01, 0xff}
+
+	cases := []struct {
+		name      string
+		modify    func(*DecodeOperatorConfig)
+		input     interface{}
+		expected  interface{}
+		expectErr string
+	}{
+		{
+			"Base64",
+			func(cfg *DecodeOperatorConfig) {},
+			base64.StdEncoding.EncodeToString(message),
+			string(message),
+			"",
+		},
+		{
+			"Base64Unpadded",
+			func(cfg *DecodeOperatorConfig) {},
+			base64.RawStdEncoding.EncodeToString(message),
+			string(message),
+			"",
+		},
+		{
+			"Base64Bytes",
+			func(cfg *DecodeOperatorConfig) {},
+			[]byte(base64.StdEncoding.EncodeToString(message)),
+			string(message),
+			"",
+		},
+		{
+			"Base64URL",
+			func(cfg *DecodeOperatorConfig) {
+				cfg.Encoding = Base64URLEncoding
+			},
+			base64.URLEncoding.EncodeToString([]byte("??>>")),
+			"??>>",
+			"",
+		},
+		{
+			"Hex",
+			func(cfg *DecodeOperatorConfig) {
+				cfg.Encoding = HexEncoding
+			},
+			hex.EncodeToString(message),
+			string(message),
+			"",
+		},
+		{
+			"Binary",
+			func(cfg *DecodeOperatorConfig) {},
+			base64.StdEncoding.EncodeToString(binary),
+			binary,
+			"",
+		},
+		{
+			"Gzip",
+			func(cfg *DecodeOperatorConfig) {
+				cfg.Compression = GzipCompression
+			},
+			base64.StdEncoding.EncodeToString(gzipBytes(t, message)),
+			string(message),
+			"",
+		},
+		{
+			"AutoGzip",
+			func(cfg *DecodeOperatorConfig) {
+				cfg.Compression = AutoCompression
+			},
+			base64.StdEncoding.EncodeToString(gzipBytes(t, message)),
+			string(message),
+			"",
+		},
+		{
+			"AutoNotCompressed",
+			func(cfg *DecodeOperatorConfig) {
+				cfg.Compression = AutoCompression
+			},
+			base64.StdEncoding.EncodeToString(message),
+			string(message),
+			"",
+		},
+		{
+			"GzipNotCompressed",
+			func(cfg *DecodeOperatorConfig) {
+				cfg.Compression = GzipCompression
+			},
+			base64.StdEncoding.EncodeToString(message),
+			nil,
+			"decompress gzip",
+		},
+		{
+			"GzipMaxSize",
+			func(cfg *DecodeOperatorConfig) {
+				cfg.Compression = GzipCompression
+				cfg.MaxSize = 10
+			},
+			base64.StdEncoding.EncodeToString(gzipBytes(t, message)),
+			nil,
+			"decompressed size exceeds max_size of 10 bytes",
+		},
+		{
+			"InvalidBase64",
+			func(cfg *DecodeOperatorConfig) {},
+			"not base64!",
+			nil,
+			"decode base64",
+		},
+		{
+			"InvalidHex",
+			func(cfg *DecodeOperatorConfig) {
+				cfg.Encoding = HexEncoding
+			},
+			"xyz",
+			nil,
+			"decode hex",
+		},
+		{
+			"InvalidType",
+			func(cfg *DecodeOperatorConfig) {},
+			1,
+			nil,
+			"type int cannot be decoded",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			op := newTestOperator(t, tc.modify)
+			decoded, err := op.decode(tc.input)
+			if tc.expectErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, decoded)
+		})
+	}
+}
+
+func TestDecodeProcess(t *testing.T) {
+	op := newTestOperator(t, func(cfg *DecodeOperatorConfig) {
+		cfg.ParseFrom = entry.NewBodyField("data")
+		cfg.ParseTo = entry.NewBodyField("message")
+	})
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = map[string]interface{}{
+		"data":   base64.StdEncoding.EncodeToString([]byte("hello world")),
+		"source": "kinesis",
+	}
+	require.NoError(t, op.Process(context.Background(), e))
+
+	fake.ExpectBody(t, map[string]interface{}{
+		"message": "hello world",
+		"source":  "kinesis",
+	})
+}
@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestDedupOperatorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "window",
+			Expect: func() *DedupOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Window = helper.NewDuration(30 * time.Second)
+				return cfg
+			}(),
+		},
+		{
+			Name: "fields",
+			Expect: func() *DedupOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Fields = []entry.Field{entry.NewBodyField("message"), entry.NewAttributeField("host")}
+				return cfg
+			}(),
+		},
+		{
+			Name: "max_keys",
+			Expect: func() *DedupOperatorConfig {
+				cfg := defaultCfg()
+				cfg.MaxKeys = 100
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *DedupOperatorConfig {
+	return NewDedupOperatorConfig("dedup")
+}
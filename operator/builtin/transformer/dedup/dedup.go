@@ -0,0 +1,230 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// RepeatCountAttribute is the attribute of a summary entry which contains the number of suppressed repeats
+	RepeatCountAttribute = "dedup.repeat_count"
+
+	// minCheckInterval is the minimum interval at which windows are checked for expiry
+	minCheckInterval = 10 * time.Millisecond
+)
+
+func init() {
+	operator.Register("dedup", func() operator.Builder { return NewDedupOperatorConfig("") })
+}
+
+// NewDedupOperatorConfig creates a new dedup operator config with default values
+func NewDedupOperatorConfig(operatorID string) *DedupOperatorConfig {
+	return &DedupOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "dedup"),
+		Window:            helper.NewDuration(time.Minute),
+		MaxKeys:           10000,
+	}
+}
+
+// DedupOperatorConfig is the configuration of a dedup operator
+type DedupOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+	Window                   helper.Duration `mapstructure:"window"   json:"window"           yaml:"window"`
+	Fields                   []entry.Field   `mapstructure:"fields"   json:"fields,omitempty" yaml:"fields,omitempty"`
+	MaxKeys                  int             `mapstructure:"max_keys" json:"max_keys"         yaml:"max_keys"`
+}
+
+// Build will build a dedup operator from the supplied configuration
+func (c DedupOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformer, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Window.Raw() <= 0 {
+		return nil, fmt.Errorf("window must be greater than 0")
+	}
+
+	if c.MaxKeys <= 0 {
+		return nil, fmt.Errorf("max_keys must be greater than 0")
+	}
+
+	dedup := &DedupOperator{
+		TransformerOperator: transformer,
+		window:              c.Window.Raw(),
+		fields:              c.Fields,
+		maxKeys:             c.MaxKeys,
+		windows:             map[string]*window{},
+		now:                 time.Now,
+	}
+
+	return []operator.Operator{dedup}, nil
+}
+
+// DedupOperator is an operator that suppresses repeated entries until their key has not been seen for a window
+type DedupOperator struct {
+	helper.TransformerOperator
+	window  time.Duration
+	fields  []entry.Field
+	maxKeys int
+	now     func() time.Time
+
+	sync.Mutex
+	windows map[string]*window
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// window tracks the repeats of a key, and expires once the key has not been seen for the window
+type window struct {
+	expires time.Time
+	repeats int
+	last    *entry.Entry
+}
+
+// Start will start closing expired windows
+func (d *DedupOperator) Start(_ operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.goCloseWindows(ctx)
+	return nil
+}
+
+// Stop will stop closing expired windows, and write the summaries of all open windows
+func (d *DedupOperator) Stop() error {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	d.closeWindows(ctx, true)
+	return nil
+}
+
+// goCloseWindows will periodically close the windows which have expired
+func (d *DedupOperator) goCloseWindows(ctx context.Context) {
+	d.wg.Add(1)
+
+	go func() {
+		defer d.wg.Done()
+
+		interval := d.window / 10
+		if interval < minCheckInterval {
+			interval = minCheckInterval
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				d.closeWindows(ctx, false)
+			}
+		}
+	}()
+}
+
+// Process will write the first occurrence of each key, and suppress repeats until the key
+// has not been seen for the window. Each repeat extends the window of its key.
+func (d *DedupOperator) Process(ctx context.Context, e *entry.Entry) error {
+	skip, err := d.Skip(ctx, e)
+	if err != nil {
+		return d.HandleEntryError(ctx, e, err)
+	}
+	if skip {
+		d.Write(ctx, e)
+		return nil
+	}
+
+	key := d.key(e)
+
+	d.Lock()
+	if w, ok := d.windows[key]; ok {
+		w.expires = d.now().Add(d.window)
+		w.repeats++
+		w.last = e
+		d.Unlock()
+		return nil
+	}
+	// Keys beyond the limit are not tracked, so that memory is bounded
+	if len(d.windows) < d.maxKeys {
+		d.windows[key] = &window{expires: d.now().Add(d.window)}
+	}
+	d.Unlock()
+
+	d.Write(ctx, e)
+	return nil
+}
+
+// key will return the values of the fields of an entry, or its body if no fields are configured.
+// The whole values are kept rather than a hash of them, so that distinct keys are never merged.
+func (d *DedupOperator) key(e *entry.Entry) string {
+	if len(d.fields) == 0 {
+		return fmt.Sprintf("%v", e.Body)
+	}
+
+	var b strings.Builder
+	for _, field := range d.fields {
+		value, _ := e.Get(field)
+		// Separate values so that adjacent values cannot produce the same key
+		_, _ = fmt.Fprintf(&b, "%v\x00", value)
+	}
+	return b.String()
+}
+
+// closeWindows will remove expired windows, or all windows if all is true, and write
+// a summary entry for each of them which had repeats.
+func (d *DedupOperator) closeWindows(ctx context.Context, all bool) {
+	now := d.now()
+
+	d.Lock()
+	var summaries []*entry.Entry
+	for key, w := range d.windows {
+		if !all && now.Before(w.expires) {
+			continue
+		}
+		delete(d.windows, key)
+		if w.repeats > 0 {
+			summaries = append(summaries, summary(w))
+		}
+	}
+	d.Unlock()
+
+	for _, s := range summaries {
+		d.Write(ctx, s)
+	}
+}
+
+// summary will create the summary entry of a window, which is the last repeat with the number of repeats.
+func summary(w *window) *entry.Entry {
+	s := w.last
+	s.AddAttribute(RepeatCountAttribute, strconv.Itoa(w.repeats))
+	return s
+}
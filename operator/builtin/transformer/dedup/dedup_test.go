@@ -0,0 +1,251 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestOperator(t *testing.T, cfg *DedupOperatorConfig) (*DedupOperator, *testutil.FakeOutput) {
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*DedupOperator)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fakeOutput}))
+	return op, fakeOutput
+}
+
+// fakeClock returns a now function, and a function which advances it
+func fakeClock() (func() time.Time, func(time.Duration)) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	return func() time.Time { return now }, func(d time.Duration) { now = now.Add(d) }
+}
+
+func newEntry(body interface{}, attributes map[string]string) *entry.Entry {
+	e := entry.New()
+	e.Body = body
+	e.Attributes = attributes
+	return e
+}
+
+func TestDedupOperatorBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*DedupOperatorConfig)
+		expectedErr string
+	}{
+		{
+			"Default",
+			func(cfg *DedupOperatorConfig) {},
+			"",
+		},
+		{
+			"ZeroWindow",
+			func(cfg *DedupOperatorConfig) { cfg.Window = helper.NewDuration(0) },
+			"window must be greater than 0",
+		},
+		{
+			"ZeroMaxKeys",
+			func(cfg *DedupOperatorConfig) { cfg.MaxKeys = 0 },
+			"max_keys must be greater than 0",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewDedupOperatorConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestDedupOperatorBody(t *testing.T) {
+	op, fake := newTestOperator(t, NewDedupOperatorConfig("test"))
+	now, advance := fakeClock()
+	op.now = now
+	ctx := context.Background()
+
+	require.NoError(t, op.Process(ctx, newEntry("connection refused", nil)))
+	fake.ExpectBody(t, "connection refused")
+
+	require.NoError(t, op.Process(ctx, newEntry("connection refused", nil)))
+	require.NoError(t, op.Process(ctx, newEntry("connection refused", nil)))
+	fake.ExpectNoEntry(t, 0)
+
+	// A different body is not a repeat
+	require.NoError(t, op.Process(ctx, newEntry("connection reset", nil)))
+	fake.ExpectBody(t, "connection reset")
+
+	// Windows are not closed before they expire
+	advance(59 * time.Second)
+	op.closeWindows(ctx, false)
+	fake.ExpectNoEntry(t, 0)
+
+	// The window with repeats writes a summary, and the window without repeats is closed silently
+	advance(time.Second)
+	op.closeWindows(ctx, false)
+	select {
+	case e := <-fake.Received:
+		require.Equal(t, "connection refused", e.Body)
+		require.Equal(t, map[string]string{RepeatCountAttribute: "2"}, e.Attributes)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for summary entry")
+	}
+	fake.ExpectNoEntry(t, 0)
+
+	// After the window closes, the next occurrence is written again
+	require.NoError(t, op.Process(ctx, newEntry("connection refused", nil)))
+	fake.ExpectBody(t, "connection refused")
+}
+
+func TestDedupOperatorSlidingWindow(t *testing.T) {
+	op, fake := newTestOperator(t, NewDedupOperatorConfig("test"))
+	now, advance := fakeClock()
+	op.now = now
+	ctx := context.Background()
+
+	require.NoError(t, op.Process(ctx, newEntry("connection refused", nil)))
+	fake.ExpectBody(t, "connection refused")
+
+	// Each repeat extends the window, so the key stays suppressed while it keeps repeating
+	for i := 0; i < 3; i++ {
+		advance(45 * time.Second)
+		require.NoError(t, op.Process(ctx, newEntry("connection refused", nil)))
+		op.closeWindows(ctx, false)
+		fake.ExpectNoEntry(t, 0)
+	}
+
+	// The window closes once the key has not been seen for the whole window
+	advance(time.Minute)
+	op.closeWindows(ctx, false)
+	select {
+	case e := <-fake.Received:
+		require.Equal(t, "connection refused", e.Body)
+		require.Equal(t, "3", e.Attributes[RepeatCountAttribute])
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for summary entry")
+	}
+}
+
+func TestDedupOperatorFields(t *testing.T) {
+	cfg := NewDedupOperatorConfig("test")
+	cfg.Fields = []entry.Field{entry.NewBodyField("message"), entry.NewAttributeField("host")}
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+
+	first := map[string]interface{}{"message": "disk full", "time": "12:00:00"}
+	require.NoError(t, op.Process(ctx, newEntry(first, map[string]string{"host": "a"})))
+	fake.ExpectBody(t, first)
+
+	// Fields which are not part of the key may differ
+	repeat := map[string]interface{}{"message": "disk full", "time": "12:00:01"}
+	require.NoError(t, op.Process(ctx, newEntry(repeat, map[string]string{"host": "a"})))
+	fake.ExpectNoEntry(t, 0)
+
+	other := map[string]interface{}{"message": "disk full", "time": "12:00:02"}
+	require.NoError(t, op.Process(ctx, newEntry(other, map[string]string{"host": "b"})))
+	fake.ExpectBody(t, other)
+}
+
+func TestDedupOperatorMaxKeys(t *testing.T) {
+	cfg := NewDedupOperatorConfig("test")
+	cfg.MaxKeys = 1
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+
+	require.NoError(t, op.Process(ctx, newEntry("first", nil)))
+	fake.ExpectBody(t, "first")
+
+	// Keys beyond the limit are not tracked, so repeats are not suppressed
+	require.NoError(t, op.Process(ctx, newEntry("second", nil)))
+	fake.ExpectBody(t, "second")
+	require.NoError(t, op.Process(ctx, newEntry("second", nil)))
+	fake.ExpectBody(t, "second")
+}
+
+func TestDedupOperatorIf(t *testing.T) {
+	cfg := NewDedupOperatorConfig("test")
+	cfg.IfExpr = `$body.level == "error"`
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+
+	info := map[string]interface{}{"level": "info"}
+	require.NoError(t, op.Process(ctx, newEntry(info, nil)))
+	fake.ExpectBody(t, info)
+	require.NoError(t, op.Process(ctx, newEntry(info, nil)))
+	fake.ExpectBody(t, info)
+}
+
+func TestDedupOperatorStop(t *testing.T) {
+	op, fake := newTestOperator(t, NewDedupOperatorConfig("test"))
+	ctx := context.Background()
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+
+	require.NoError(t, op.Process(ctx, newEntry("timeout", nil)))
+	fake.ExpectBody(t, "timeout")
+	require.NoError(t, op.Process(ctx, newEntry("timeout", nil)))
+	fake.ExpectNoEntry(t, 0)
+
+	// Open windows are summarized when the operator stops
+	require.NoError(t, op.Stop())
+	select {
+	case e := <-fake.Received:
+		require.Equal(t, "timeout", e.Body)
+		require.Equal(t, "1", e.Attributes[RepeatCountAttribute])
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for summary entry")
+	}
+}
+
+func TestDedupOperatorWindowExpiry(t *testing.T) {
+	cfg := NewDedupOperatorConfig("test")
+	cfg.Window = helper.NewDuration(50 * time.Millisecond)
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	defer func() {
+		require.NoError(t, op.Stop())
+	}()
+
+	require.NoError(t, op.Process(ctx, newEntry("timeout", nil)))
+	fake.ExpectBody(t, "timeout")
+	require.NoError(t, op.Process(ctx, newEntry("timeout", nil)))
+
+	select {
+	case e := <-fake.Received:
+		require.Equal(t, "timeout", e.Body)
+		require.Equal(t, "1", e.Attributes[RepeatCountAttribute])
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for summary entry")
+	}
+}
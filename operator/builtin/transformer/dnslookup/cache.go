@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnslookup
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// ttlCache is a cache of resolved hostnames, which expires each hostname after its time to live,
+// and evicts the least recently used hostname when it is full.
+type ttlCache struct {
+	mu    sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List
+}
+
+// cacheItem is an item of the cache. An empty hostname records a failed lookup.
+type cacheItem struct {
+	key      string
+	hostname string
+	expires  time.Time
+}
+
+// newTTLCache creates a cache of a number of hostnames.
+func newTTLCache(size int) *ttlCache {
+	return &ttlCache{
+		size:  size,
+		items: make(map[string]*list.Element, size),
+		order: list.New(),
+	}
+}
+
+// get will return a cached hostname which has not expired, and mark it as the most recently used.
+func (c *ttlCache) get(key string, now time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+
+	item := element.Value.(*cacheItem)
+	if !now.Before(item.expires) {
+		c.order.Remove(element)
+		delete(c.items, key)
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return item.hostname, true
+}
+
+// add will cache a hostname until it expires, evicting the least recently used hostname if the cache is full.
+func (c *ttlCache) add(key, hostname string, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		item := element.Value.(*cacheItem)
+		item.hostname = hostname
+		item.expires = expires
+		c.order.MoveToFront(element)
+		return
+	}
+
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheItem).key)
+	}
+	c.items[key] = c.order.PushFront(&cacheItem{key: key, hostname: hostname, expires: expires})
+}
+
+// len will return the number of cached hostnames, including those which have expired but not been removed.
+func (c *ttlCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
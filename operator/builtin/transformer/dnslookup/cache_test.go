@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnslookup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTTLCache(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := newTTLCache(2)
+	cache.add("a", "a.example.com", now.Add(time.Minute))
+	cache.add("b", "", now.Add(time.Minute))
+
+	// Getting "a" makes "b" the least recently used
+	hostname, ok := cache.get("a", now)
+	require.True(t, ok)
+	require.Equal(t, "a.example.com", hostname)
+
+	cache.add("c", "c.example.com", now.Add(time.Second))
+	require.Equal(t, 2, cache.len())
+
+	_, ok = cache.get("b", now)
+	require.False(t, ok)
+	_, ok = cache.get("a", now)
+	require.True(t, ok)
+
+	// Expired hostnames are removed
+	_, ok = cache.get("c", now.Add(time.Second))
+	require.False(t, ok)
+	require.Equal(t, 1, cache.len())
+
+	// Adding an existing key replaces its hostname and expiry
+	cache.add("a", "", now.Add(time.Hour))
+	hostname, ok = cache.get("a", now.Add(time.Minute))
+	require.True(t, ok)
+	require.Equal(t, "", hostname)
+}
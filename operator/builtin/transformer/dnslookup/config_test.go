@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnslookup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestDNSLookupOperatorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "fields",
+			Expect: func() *DNSLookupOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Fields = []FieldConfig{
+					{From: entry.NewBodyField("src_ip"), To: entry.NewAttributeField("src_host")},
+					{From: entry.NewBodyField("dst_ip"), To: entry.NewAttributeField("dst_host")},
+				}
+				return cfg
+			}(),
+		},
+		{
+			Name: "cache",
+			Expect: func() *DNSLookupOperatorConfig {
+				cfg := defaultCfg()
+				cfg.CacheSize = 500
+				cfg.CacheTTL = helper.NewDuration(10 * time.Minute)
+				cfg.NegativeCacheTTL = helper.NewDuration(30 * time.Second)
+				return cfg
+			}(),
+		},
+		{
+			Name: "timeout",
+			Expect: func() *DNSLookupOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Timeout = helper.NewDuration(250 * time.Millisecond)
+				cfg.MaxConcurrentLookups = 2
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *DNSLookupOperatorConfig {
+	return NewDNSLookupOperatorConfig("dns_lookup")
+}
@@ -0,0 +1,239 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnslookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("dns_lookup", func() operator.Builder { return NewDNSLookupOperatorConfig("") })
+}
+
+// NewDNSLookupOperatorConfig creates a new dns lookup operator config with default values
+func NewDNSLookupOperatorConfig(operatorID string) *DNSLookupOperatorConfig {
+	return &DNSLookupOperatorConfig{
+		TransformerConfig:    helper.NewTransformerConfig(operatorID, "dns_lookup"),
+		Timeout:              helper.NewDuration(time.Second),
+		CacheSize:            10000,
+		CacheTTL:             helper.NewDuration(time.Hour),
+		NegativeCacheTTL:     helper.NewDuration(5 * time.Minute),
+		MaxConcurrentLookups: 10,
+	}
+}
+
+// DNSLookupOperatorConfig is the configuration of a dns lookup operator
+type DNSLookupOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+
+	Fields               []FieldConfig   `mapstructure:"fields"                 json:"fields"                 yaml:"fields"`
+	Timeout              helper.Duration `mapstructure:"timeout"                json:"timeout"                yaml:"timeout"`
+	CacheSize            int             `mapstructure:"cache_size"             json:"cache_size"             yaml:"cache_size"`
+	CacheTTL             helper.Duration `mapstructure:"cache_ttl"              json:"cache_ttl"              yaml:"cache_ttl"`
+	NegativeCacheTTL     helper.Duration `mapstructure:"negative_cache_ttl"     json:"negative_cache_ttl"     yaml:"negative_cache_ttl"`
+	MaxConcurrentLookups int             `mapstructure:"max_concurrent_lookups" json:"max_concurrent_lookups" yaml:"max_concurrent_lookups"`
+}
+
+// FieldConfig is the configuration of a field containing an IP address, and the field its hostname is written to
+type FieldConfig struct {
+	From entry.Field `mapstructure:"from" json:"from" yaml:"from"`
+	To   entry.Field `mapstructure:"to"   json:"to"   yaml:"to"`
+}
+
+// Build will build a dns lookup operator from the supplied configuration
+func (c DNSLookupOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Fields) == 0 {
+		return nil, fmt.Errorf("missing required parameter 'fields'")
+	}
+	for i, field := range c.Fields {
+		if field.From.FieldInterface == nil {
+			return nil, fmt.Errorf("fields[%d]: missing required parameter 'from'", i)
+		}
+		if field.To.FieldInterface == nil {
+			return nil, fmt.Errorf("fields[%d]: missing required parameter 'to'", i)
+		}
+	}
+
+	if c.Timeout.Raw() <= 0 {
+		return nil, fmt.Errorf("timeout must be greater than 0")
+	}
+	if c.CacheSize <= 0 {
+		return nil, fmt.Errorf("cache_size must be greater than 0")
+	}
+	if c.CacheTTL.Raw() <= 0 {
+		return nil, fmt.Errorf("cache_ttl must be greater than 0")
+	}
+	if c.NegativeCacheTTL.Raw() < 0 {
+		return nil, fmt.Errorf("negative_cache_ttl must not be negative")
+	}
+	if c.MaxConcurrentLookups <= 0 {
+		return nil, fmt.Errorf("max_concurrent_lookups must be greater than 0")
+	}
+
+	dnsLookupOperator := &DNSLookupOperator{
+		TransformerOperator: transformerOperator,
+		fields:              c.Fields,
+		timeout:             c.Timeout.Raw(),
+		cacheTTL:            c.CacheTTL.Raw(),
+		negativeCacheTTL:    c.NegativeCacheTTL.Raw(),
+		cache:               newTTLCache(c.CacheSize),
+		lookups:             make(chan struct{}, c.MaxConcurrentLookups),
+		lookupAddr:          net.DefaultResolver.LookupAddr,
+		now:                 time.Now,
+	}
+
+	return []operator.Operator{dnsLookupOperator}, nil
+}
+
+// DNSLookupOperator is an operator that resolves the hostnames of IP addresses with reverse DNS
+type DNSLookupOperator struct {
+	helper.TransformerOperator
+	fields           []FieldConfig
+	timeout          time.Duration
+	cacheTTL         time.Duration
+	negativeCacheTTL time.Duration
+	cache            *ttlCache
+
+	// lookups limits the number of concurrent lookups, with one slot per lookup in progress
+	lookups    chan struct{}
+	lookupAddr func(ctx context.Context, addr string) ([]string, error)
+	now        func() time.Time
+}
+
+// Process will resolve the hostnames of the IP addresses of an entry.
+func (d *DNSLookupOperator) Process(ctx context.Context, e *entry.Entry) error {
+	skip, err := d.Skip(ctx, e)
+	if err != nil {
+		return d.HandleEntryError(ctx, e, err)
+	}
+	if skip {
+		d.Write(ctx, e)
+		return nil
+	}
+
+	if err := d.Transform(ctx, e); err != nil {
+		return d.HandleEntryError(ctx, e, err)
+	}
+	d.Write(ctx, e)
+	return nil
+}
+
+// Transform will write the hostname of the IP address of each field to its target field.
+// Fields which are missing, or whose address can not be resolved, are skipped.
+func (d *DNSLookupOperator) Transform(ctx context.Context, e *entry.Entry) error {
+	// All addresses are validated before any hostname is written, so that an entry is not partially modified
+	addrs := make([]string, len(d.fields))
+	for i, field := range d.fields {
+		value, ok := e.Get(field.From)
+		if !ok {
+			continue
+		}
+		addr, err := parseIP(value)
+		if err != nil {
+			return fmt.Errorf("field %s: %s", field.From, err)
+		}
+		addrs[i] = addr
+	}
+
+	for i, field := range d.fields {
+		if addrs[i] == "" {
+			continue
+		}
+		hostname := d.resolve(ctx, addrs[i])
+		if hostname == "" {
+			continue
+		}
+		if err := e.Set(field.To, hostname); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve will return the hostname of an IP address, or an empty string if it can not be resolved.
+func (d *DNSLookupOperator) resolve(ctx context.Context, addr string) string {
+	if hostname, ok := d.cache.get(addr, d.now()); ok {
+		return hostname
+	}
+
+	// The timeout includes the time spent waiting for a lookup slot
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	select {
+	case d.lookups <- struct{}{}:
+	case <-ctx.Done():
+		// The resolver was not queried, so the failure is not cached
+		d.Debugw("Timed out waiting to look up address", "address", addr)
+		return ""
+	}
+	names, err := d.lookupAddr(ctx, addr)
+	<-d.lookups
+
+	if err != nil || len(names) == 0 {
+		d.Debugw("Failed to look up address", "address", addr, zap.Error(err))
+		// Only addresses which the resolver reports as unknown are cached, since
+		// other failures, such as timeouts, may not happen on the next lookup
+		if d.negativeCacheTTL > 0 && (err == nil || isNotFound(err)) {
+			d.cache.add(addr, "", d.now().Add(d.negativeCacheTTL))
+		}
+		return ""
+	}
+
+	hostname := strings.TrimSuffix(names[0], ".")
+	d.cache.add(addr, hostname, d.now().Add(d.cacheTTL))
+	return hostname
+}
+
+// isNotFound will return true if a lookup failed because the address has no hostname.
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// parseIP will return the canonical form of an IP address value.
+func parseIP(value interface{}) (string, error) {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return "", fmt.Errorf("type %T cannot be parsed as an IP address", value)
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(raw))
+	if ip == nil {
+		return "", fmt.Errorf("invalid IP address '%s'", raw)
+	}
+	return ip.String(), nil
+}
@@ -0,0 +1,385 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnslookup
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// fakeResolver resolves the addresses of a table, and counts its lookups
+type fakeResolver struct {
+	hosts   map[string][]string
+	lookups int32
+}
+
+func (r *fakeResolver) lookupAddr(_ context.Context, addr string) ([]string, error) {
+	atomic.AddInt32(&r.lookups, 1)
+	if names, ok := r.hosts[addr]; ok {
+		return names, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: addr, IsNotFound: true}
+}
+
+func newFakeResolver() *fakeResolver {
+	return &fakeResolver{
+		hosts: map[string][]string{
+			"10.0.0.1":    {"web-1.example.com."},
+			"10.0.0.2":    {"db-1.example.com.", "db.example.com."},
+			"2001:db8::1": {"v6.example.com."},
+		},
+	}
+}
+
+func ipFields() []FieldConfig {
+	return []FieldConfig{
+		{From: entry.NewBodyField("src_ip"), To: entry.NewAttributeField("src_host")},
+		{From: entry.NewBodyField("dst_ip"), To: entry.NewAttributeField("dst_host")},
+	}
+}
+
+func buildOperator(t *testing.T, cfg *DNSLookupOperatorConfig) (*DNSLookupOperator, *testutil.FakeOutput) {
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*DNSLookupOperator)
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+	return op, fake
+}
+
+func TestDNSLookupOperatorBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*DNSLookupOperatorConfig)
+		expectedErr string
+	}{
+		{
+			"Default",
+			func(cfg *DNSLookupOperatorConfig) {},
+			"",
+		},
+		{
+			"NoFields",
+			func(cfg *DNSLookupOperatorConfig) { cfg.Fields = nil },
+			"missing required parameter 'fields'",
+		},
+		{
+			"MissingFrom",
+			func(cfg *DNSLookupOperatorConfig) {
+				cfg.Fields = []FieldConfig{{To: entry.NewAttributeField("host")}}
+			},
+			"fields[0]: missing required parameter 'from'",
+		},
+		{
+			"MissingTo",
+			func(cfg *DNSLookupOperatorConfig) {
+				cfg.Fields = []FieldConfig{{From: entry.NewBodyField("ip")}}
+			},
+			"fields[0]: missing required parameter 'to'",
+		},
+		{
+			"ZeroTimeout",
+			func(cfg *DNSLookupOperatorConfig) { cfg.Timeout = helper.NewDuration(0) },
+			"timeout must be greater than 0",
+		},
+		{
+			"ZeroCacheSize",
+			func(cfg *DNSLookupOperatorConfig) { cfg.CacheSize = 0 },
+			"cache_size must be greater than 0",
+		},
+		{
+			"ZeroCacheTTL",
+			func(cfg *DNSLookupOperatorConfig) { cfg.CacheTTL = helper.NewDuration(0) },
+			"cache_ttl must be greater than 0",
+		},
+		{
+			"ZeroNegativeCacheTTL",
+			func(cfg *DNSLookupOperatorConfig) { cfg.NegativeCacheTTL = helper.NewDuration(0) },
+			"",
+		},
+		{
+			"NegativeNegativeCacheTTL",
+			func(cfg *DNSLookupOperatorConfig) { cfg.NegativeCacheTTL = helper.NewDuration(-time.Second) },
+			"negative_cache_ttl must not be negative",
+		},
+		{
+			"ZeroMaxConcurrentLookups",
+			func(cfg *DNSLookupOperatorConfig) { cfg.MaxConcurrentLookups = 0 },
+			"max_concurrent_lookups must be greater than 0",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewDNSLookupOperatorConfig("test")
+			cfg.Fields = ipFields()
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestDNSLookupOperatorTransform(t *testing.T) {
+	cases := []struct {
+		name        string
+		body        map[string]interface{}
+		expected    map[string]string
+		expectedErr string
+	}{
+		{
+			"BothFields",
+			map[string]interface{}{"src_ip": "10.0.0.1", "dst_ip": "10.0.0.2"},
+			map[string]string{"src_host": "web-1.example.com", "dst_host": "db-1.example.com"},
+			"",
+		},
+		{
+			"IPv6",
+			map[string]interface{}{"src_ip": "2001:0db8::0001"},
+			map[string]string{"src_host": "v6.example.com"},
+			"",
+		},
+		{
+			"Bytes",
+			map[string]interface{}{"src_ip": []byte("10.0.0.1")},
+			map[string]string{"src_host": "web-1.example.com"},
+			"",
+		},
+		{
+			"MissingField",
+			map[string]interface{}{"dst_ip": "10.0.0.2"},
+			map[string]string{"dst_host": "db-1.example.com"},
+			"",
+		},
+		{
+			"Unresolved",
+			map[string]interface{}{"src_ip": "10.0.0.9", "dst_ip": "10.0.0.2"},
+			map[string]string{"dst_host": "db-1.example.com"},
+			"",
+		},
+		{
+			"InvalidAddress",
+			map[string]interface{}{"src_ip": "10.0.0.1", "dst_ip": "not-an-ip"},
+			nil,
+			"invalid IP address 'not-an-ip'",
+		},
+		{
+			"InvalidType",
+			map[string]interface{}{"src_ip": 10},
+			nil,
+			"type int cannot be parsed as an IP address",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewDNSLookupOperatorConfig("test")
+			cfg.Fields = ipFields()
+			op, _ := buildOperator(t, cfg)
+			op.lookupAddr = newFakeResolver().lookupAddr
+
+			e := entry.New()
+			e.Body = tc.body
+			err := op.Transform(context.Background(), e)
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedErr)
+				require.Nil(t, e.Attributes)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, e.Attributes)
+		})
+	}
+}
+
+func TestDNSLookupOperatorCache(t *testing.T) {
+	cfg := NewDNSLookupOperatorConfig("test")
+	cfg.Fields = ipFields()
+	cfg.CacheTTL = helper.NewDuration(time.Hour)
+	cfg.NegativeCacheTTL = helper.NewDuration(time.Minute)
+	op, _ := buildOperator(t, cfg)
+
+	resolver := newFakeResolver()
+	op.lookupAddr = resolver.lookupAddr
+	now := time.Unix(1000, 0)
+	op.now = func() time.Time { return now }
+
+	lookup := func(addr string) string {
+		e := entry.New()
+		e.Body = map[string]interface{}{"src_ip": addr}
+		require.NoError(t, op.Transform(context.Background(), e))
+		return e.Attributes["src_host"]
+	}
+
+	require.Equal(t, "web-1.example.com", lookup("10.0.0.1"))
+	require.Equal(t, "web-1.example.com", lookup("10.0.0.1"))
+	require.Equal(t, "", lookup("10.0.0.9"))
+	require.Equal(t, "", lookup("10.0.0.9"))
+	require.Equal(t, int32(2), atomic.LoadInt32(&resolver.lookups))
+
+	// Failed lookups expire before resolved hostnames
+	now = now.Add(time.Minute)
+	resolver.hosts["10.0.0.9"] = []string{"new.example.com."}
+	require.Equal(t, "web-1.example.com", lookup("10.0.0.1"))
+	require.Equal(t, "new.example.com", lookup("10.0.0.9"))
+	require.Equal(t, int32(3), atomic.LoadInt32(&resolver.lookups))
+
+	now = now.Add(time.Hour)
+	require.Equal(t, "web-1.example.com", lookup("10.0.0.1"))
+	require.Equal(t, int32(4), atomic.LoadInt32(&resolver.lookups))
+}
+
+func TestDNSLookupOperatorNoNegativeCache(t *testing.T) {
+	cfg := NewDNSLookupOperatorConfig("test")
+	cfg.Fields = ipFields()
+	cfg.NegativeCacheTTL = helper.NewDuration(0)
+	op, _ := buildOperator(t, cfg)
+
+	resolver := newFakeResolver()
+	op.lookupAddr = resolver.lookupAddr
+
+	for i := 0; i < 3; i++ {
+		e := entry.New()
+		e.Body = map[string]interface{}{"src_ip": "10.0.0.9"}
+		require.NoError(t, op.Transform(context.Background(), e))
+		require.Nil(t, e.Attributes)
+	}
+	require.Equal(t, int32(3), atomic.LoadInt32(&resolver.lookups))
+}
+
+func TestDNSLookupOperatorTimeout(t *testing.T) {
+	cfg := NewDNSLookupOperatorConfig("test")
+	cfg.Fields = ipFields()
+	cfg.Timeout = helper.NewDuration(20 * time.Millisecond)
+	op, _ := buildOperator(t, cfg)
+
+	op.lookupAddr = func(ctx context.Context, addr string) ([]string, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	e := entry.New()
+	e.Body = map[string]interface{}{"src_ip": "10.0.0.1"}
+	require.NoError(t, op.Transform(context.Background(), e))
+	require.Nil(t, e.Attributes)
+
+	// The timed out lookup is not cached, so the address is looked up again
+	_, ok := op.cache.get("10.0.0.1", time.Now())
+	require.False(t, ok)
+}
+
+func TestDNSLookupOperatorTemporaryFailure(t *testing.T) {
+	cfg := NewDNSLookupOperatorConfig("test")
+	cfg.Fields = ipFields()
+	op, _ := buildOperator(t, cfg)
+
+	var lookups int32
+	op.lookupAddr = func(_ context.Context, addr string) ([]string, error) {
+		atomic.AddInt32(&lookups, 1)
+		return nil, &net.DNSError{Err: "server misbehaving", Name: addr, IsTemporary: true}
+	}
+
+	// Failures other than an unknown address are not cached
+	for i := 0; i < 3; i++ {
+		e := entry.New()
+		e.Body = map[string]interface{}{"src_ip": "10.0.0.1"}
+		require.NoError(t, op.Transform(context.Background(), e))
+		require.Nil(t, e.Attributes)
+	}
+	require.Equal(t, int32(3), atomic.LoadInt32(&lookups))
+}
+
+func TestDNSLookupOperatorMaxConcurrentLookups(t *testing.T) {
+	cfg := NewDNSLookupOperatorConfig("test")
+	cfg.Fields = ipFields()
+	cfg.MaxConcurrentLookups = 2
+	cfg.Timeout = helper.NewDuration(time.Minute)
+	op, _ := buildOperator(t, cfg)
+
+	var active, maxActive int32
+	release := make(chan struct{})
+	op.lookupAddr = func(_ context.Context, addr string) ([]string, error) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&active, -1)
+		return []string{addr + ".example.com."}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 6; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			e := entry.New()
+			e.Body = map[string]interface{}{"src_ip": fmt.Sprintf("10.0.0.%d", i)}
+			require.NoError(t, op.Transform(context.Background(), e))
+			require.Equal(t, fmt.Sprintf("10.0.0.%d.example.com", i), e.Attributes["src_host"])
+		}(i)
+	}
+
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&active) == 2 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.Equal(t, int32(2), atomic.LoadInt32(&maxActive))
+}
+
+func TestDNSLookupOperatorProcess(t *testing.T) {
+	cfg := NewDNSLookupOperatorConfig("test")
+	cfg.Fields = ipFields()
+	cfg.IfExpr = `$body.src_ip != "10.0.0.2"`
+	op, fake := buildOperator(t, cfg)
+	op.lookupAddr = newFakeResolver().lookupAddr
+
+	e := entry.New()
+	e.Body = map[string]interface{}{"src_ip": "10.0.0.1"}
+	require.NoError(t, op.Process(context.Background(), e))
+	fake.ExpectEntry(t, e)
+	require.Equal(t, map[string]string{"src_host": "web-1.example.com"}, e.Attributes)
+
+	skipped := entry.New()
+	skipped.Body = map[string]interface{}{"src_ip": "10.0.0.2"}
+	require.NoError(t, op.Process(context.Background(), skipped))
+	fake.ExpectBody(t, map[string]interface{}{"src_ip": "10.0.0.2"})
+
+	invalid := entry.New()
+	invalid.Body = map[string]interface{}{"src_ip": "invalid"}
+	require.NoError(t, op.Process(context.Background(), invalid))
+	fake.ExpectBody(t, map[string]interface{}{"src_ip": "invalid"})
+}
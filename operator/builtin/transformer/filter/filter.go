@@ -81,6 +81,15 @@ type FilterOperator struct {
 
 // Process will drop incoming entries that match the filter expression
 func (f *FilterOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	skip, err := f.Skip(ctx, entry)
+	if err != nil {
+		return f.HandleEntryError(ctx, entry, err)
+	}
+	if skip {
+		f.Write(ctx, entry)
+		return nil
+	}
+
 	env := helper.GetExprEnv(entry)
 	defer helper.PutExprEnv(env)
 
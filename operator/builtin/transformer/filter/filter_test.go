@@ -170,3 +170,34 @@ func TestFilterDropRatio(t *testing.T) {
 
 	require.Equal(t, 10, processedEntries)
 }
+
+func TestFilterIf(t *testing.T) {
+	cfg := NewFilterOperatorConfig("test")
+	cfg.Expression = `$.message == "test_message"`
+	cfg.IfExpr = `$attributes.env == "dev"`
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	processedEntries := 0
+	mockOutput := testutil.NewMockOperator("output")
+	mockOutput.On("Process", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		processedEntries++
+	})
+
+	filterOperator, ok := ops[0].(*FilterOperator)
+	require.True(t, ok)
+	filterOperator.OutputOperators = []operator.Operator{mockOutput}
+
+	for _, env := range []string{"dev", "prod"} {
+		testEntry := &entry.Entry{
+			Attributes: map[string]string{"env": env},
+			Body: map[string]interface{}{
+				"message": "test_message",
+			},
+		}
+		require.NoError(t, filterOperator.Process(context.Background(), testEntry))
+	}
+
+	// Only the entry which matches the if condition is filtered
+	require.Equal(t, 1, processedEntries)
+}
@@ -69,6 +69,21 @@ func TestGoldenConfig(t *testing.T) {
 			}(),
 			false,
 		},
+		{
+			"flatten_options",
+			func() *FlattenOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.BodyField{
+					Keys: []string{"nested"},
+				}
+				cfg.MaxDepth = 3
+				cfg.Separator = "_"
+				cfg.FlattenArrays = true
+				cfg.Exclude = []string{"request.headers"}
+				return cfg
+			}(),
+			false,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
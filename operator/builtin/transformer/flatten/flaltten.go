@@ -17,6 +17,7 @@ package flatten
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
@@ -33,6 +34,8 @@ func init() {
 func NewFlattenOperatorConfig(operatorID string) *FlattenOperatorConfig {
 	return &FlattenOperatorConfig{
 		TransformerConfig: helper.NewTransformerConfig(operatorID, "flatten"),
+		MaxDepth:          1,
+		Separator:         ".",
 	}
 }
 
@@ -40,6 +43,18 @@ func NewFlattenOperatorConfig(operatorID string) *FlattenOperatorConfig {
 type FlattenOperatorConfig struct {
 	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
 	Field                    entry.BodyField `mapstructure:"field" json:"field" yaml:"field"`
+
+	// MaxDepth is the number of levels which are flattened, or 0 to flatten all levels
+	MaxDepth int `mapstructure:"max_depth" json:"max_depth" yaml:"max_depth"`
+
+	// Separator joins the keys of levels below the first
+	Separator string `mapstructure:"separator" json:"separator" yaml:"separator"`
+
+	// FlattenArrays flattens arrays into keys of their indexes
+	FlattenArrays bool `mapstructure:"flatten_arrays" json:"flatten_arrays,omitempty" yaml:"flatten_arrays,omitempty"`
+
+	// Exclude are the paths of subtrees, relative to the field, which are not flattened
+	Exclude []string `mapstructure:"exclude" json:"exclude,omitempty" yaml:"exclude,omitempty"`
 }
 
 // Build will build a Flatten operator from the supplied configuration
@@ -53,9 +68,29 @@ func (c FlattenOperatorConfig) Build(context operator.BuildContext) ([]operator.
 		return nil, fmt.Errorf("flatten: field cannot be a resource or attribute")
 	}
 
+	if c.MaxDepth < 0 {
+		return nil, fmt.Errorf("flatten: max_depth must not be negative")
+	}
+
+	if c.Separator == "" {
+		return nil, fmt.Errorf("flatten: separator must not be empty")
+	}
+
+	exclude := make(map[string]bool, len(c.Exclude))
+	for _, path := range c.Exclude {
+		if path == "" {
+			return nil, fmt.Errorf("flatten: exclude paths must not be empty")
+		}
+		exclude[path] = true
+	}
+
 	flattenOp := &FlattenOperator{
 		TransformerOperator: transformerOperator,
 		Field:               c.Field,
+		MaxDepth:            c.MaxDepth,
+		Separator:           c.Separator,
+		FlattenArrays:       c.FlattenArrays,
+		exclude:             exclude,
 	}
 
 	return []operator.Operator{flattenOp}, nil
@@ -64,7 +99,11 @@ func (c FlattenOperatorConfig) Build(context operator.BuildContext) ([]operator.
 // FlattenOperator flattens an object in the body field
 type FlattenOperator struct {
 	helper.TransformerOperator
-	Field entry.BodyField
+	Field         entry.BodyField
+	MaxDepth      int
+	Separator     string
+	FlattenArrays bool
+	exclude       map[string]bool
 }
 
 // Process will process an entry with a flatten transformation.
@@ -81,7 +120,7 @@ func (p *FlattenOperator) Transform(entry *entry.Entry) error {
 		return fmt.Errorf("apply flatten: field %s does not exist on body", p.Field)
 	}
 
-	valMap, ok := val.(map[string]interface{})
+	children, ok := p.children(val)
 	if !ok {
 		// The field we were asked to flatten was not a map, so put it back
 		err := entry.Set(p.Field, val)
@@ -91,7 +130,12 @@ func (p *FlattenOperator) Transform(entry *entry.Entry) error {
 		return fmt.Errorf("apply flatten: field %s is not a map", p.Field)
 	}
 
-	for k, v := range valMap {
+	flattened := map[string]interface{}{}
+	for k, v := range children {
+		p.flatten(flattened, k, k, v, 1)
+	}
+
+	for k, v := range flattened {
 		err := entry.Set(parent.Child(k), v)
 		if err != nil {
 			return err
@@ -99,3 +143,41 @@ func (p *FlattenOperator) Transform(entry *entry.Entry) error {
 	}
 	return nil
 }
+
+// flatten will add a value to the flattened values under a key, after flattening its children
+// if it is above the maximum depth and not excluded. The path of the value is relative to the field.
+func (p *FlattenOperator) flatten(flattened map[string]interface{}, key, path string, value interface{}, depth int) {
+	if (p.MaxDepth != 0 && depth >= p.MaxDepth) || p.exclude[path] {
+		flattened[key] = value
+		return
+	}
+
+	children, ok := p.children(value)
+	if !ok || len(children) == 0 {
+		flattened[key] = value
+		return
+	}
+
+	for k, v := range children {
+		p.flatten(flattened, key+p.Separator+k, path+"."+k, v, depth+1)
+	}
+}
+
+// children will return the children of a map, or of an array if arrays are flattened, by their keys.
+func (p *FlattenOperator) children(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case []interface{}:
+		if !p.FlattenArrays {
+			return nil, false
+		}
+		children := make(map[string]interface{}, len(v))
+		for i, item := range v {
+			children[strconv.Itoa(i)] = item
+		}
+		return children, true
+	default:
+		return nil, false
+	}
+}
@@ -261,6 +261,241 @@ func TestBuildAndProcess(t *testing.T) {
 			newTestEntry,
 			nil,
 		},
+		{
+			"flatten_max_depth",
+			false,
+			func() *FlattenOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.BodyField{
+					Keys: []string{"nested"},
+				}
+				cfg.MaxDepth = 2
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Body = map[string]interface{}{
+					"key": "val",
+					"nested": map[string]interface{}{
+						"request": map[string]interface{}{
+							"method": "GET",
+							"headers": map[string]interface{}{
+								"host": "example.com",
+							},
+						},
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Body = map[string]interface{}{
+					"key":            "val",
+					"request.method": "GET",
+					"request.headers": map[string]interface{}{
+						"host": "example.com",
+					},
+				}
+				return e
+			},
+		},
+		{
+			"flatten_all_levels_separator",
+			false,
+			func() *FlattenOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.BodyField{
+					Keys: []string{"nested"},
+				}
+				cfg.MaxDepth = 0
+				cfg.Separator = "_"
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Body = map[string]interface{}{
+					"key": "val",
+					"nested": map[string]interface{}{
+						"request": map[string]interface{}{
+							"method": "GET",
+							"headers": map[string]interface{}{
+								"host": "example.com",
+							},
+							"empty": map[string]interface{}{},
+						},
+						"tags": []interface{}{"a", "b"},
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Body = map[string]interface{}{
+					"key":                  "val",
+					"request_method":       "GET",
+					"request_headers_host": "example.com",
+					"request_empty":        map[string]interface{}{},
+					"tags":                 []interface{}{"a", "b"},
+				}
+				return e
+			},
+		},
+		{
+			"flatten_arrays",
+			false,
+			func() *FlattenOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.BodyField{
+					Keys: []string{"nested"},
+				}
+				cfg.MaxDepth = 0
+				cfg.FlattenArrays = true
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Body = map[string]interface{}{
+					"key": "val",
+					"nested": map[string]interface{}{
+						"tags": []interface{}{
+							"a",
+							map[string]interface{}{"name": "b"},
+						},
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Body = map[string]interface{}{
+					"key":         "val",
+					"tags.0":      "a",
+					"tags.1.name": "b",
+				}
+				return e
+			},
+		},
+		{
+			"flatten_array_field",
+			false,
+			func() *FlattenOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.BodyField{
+					Keys: []string{"nested"},
+				}
+				cfg.FlattenArrays = true
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Body = map[string]interface{}{
+					"key":    "val",
+					"nested": []interface{}{"a", "b"},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Body = map[string]interface{}{
+					"key": "val",
+					"0":   "a",
+					"1":   "b",
+				}
+				return e
+			},
+		},
+		{
+			"flatten_exclude",
+			false,
+			func() *FlattenOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.BodyField{
+					Keys: []string{"nested"},
+				}
+				cfg.MaxDepth = 0
+				cfg.Exclude = []string{"request.headers", "labels"}
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Body = map[string]interface{}{
+					"key": "val",
+					"nested": map[string]interface{}{
+						"request": map[string]interface{}{
+							"method": "GET",
+							"headers": map[string]interface{}{
+								"host": "example.com",
+							},
+						},
+						"labels": map[string]interface{}{
+							"team": "payments",
+						},
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Body = map[string]interface{}{
+					"key":            "val",
+					"request.method": "GET",
+					"request.headers": map[string]interface{}{
+						"host": "example.com",
+					},
+					"labels": map[string]interface{}{
+						"team": "payments",
+					},
+				}
+				return e
+			},
+		},
+		{
+			"flatten_array_not_enabled",
+			true,
+			func() *FlattenOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.BodyField{
+					Keys: []string{"nested"},
+				}
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Body = map[string]interface{}{
+					"nested": []interface{}{"a", "b"},
+				}
+				return e
+			},
+			nil,
+		},
+		{
+			"flatten_negative_max_depth",
+			true,
+			func() *FlattenOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.BodyField{
+					Keys: []string{"nested"},
+				}
+				cfg.MaxDepth = -1
+				return cfg
+			}(),
+			newTestEntry,
+			nil,
+		},
+		{
+			"flatten_empty_separator",
+			true,
+			func() *FlattenOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.BodyField{
+					Keys: []string{"nested"},
+				}
+				cfg.Separator = ""
+				return cfg
+			}(),
+			newTestEntry,
+			nil,
+		},
 	}
 
 	for _, tc := range cases {
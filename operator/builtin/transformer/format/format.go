@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("format", func() operator.Builder { return NewFormatOperatorConfig("") })
+}
+
+// NewFormatOperatorConfig creates a new format operator config with default values
+func NewFormatOperatorConfig(operatorID string) *FormatOperatorConfig {
+	return &FormatOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "format"),
+	}
+}
+
+// FormatOperatorConfig is the configuration of a format operator
+type FormatOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+	Field                    entry.Field `mapstructure:"field"    json:"field"    yaml:"field"`
+	Template                 string      `mapstructure:"template" json:"template" yaml:"template"`
+}
+
+// Build will build a format operator from the supplied configuration
+func (c FormatOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Field.FieldInterface == nil {
+		return nil, fmt.Errorf("missing required parameter 'field'")
+	}
+
+	if c.Template == "" {
+		return nil, fmt.Errorf("missing required parameter 'template'")
+	}
+
+	t, err := compileTemplate(c.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %s", err)
+	}
+
+	formatOperator := &FormatOperator{
+		TransformerOperator: transformerOperator,
+		field:               c.Field,
+		template:            t,
+	}
+
+	return []operator.Operator{formatOperator}, nil
+}
+
+// FormatOperator is an operator that writes a template rendered with the values of an entry to a field
+type FormatOperator struct {
+	helper.TransformerOperator
+	field    entry.Field
+	template template
+}
+
+// Process will process an entry with a format transformation.
+func (f *FormatOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return f.ProcessWith(ctx, entry, f.Transform)
+}
+
+// Transform will render the template with the values of an entry, and write it to the field
+func (f *FormatOperator) Transform(e *entry.Entry) error {
+	value, err := f.template.render(e)
+	if err != nil {
+		return err
+	}
+	return e.Set(f.field, value)
+}
@@ -0,0 +1,228 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestFormatOperatorBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		field       entry.Field
+		template    string
+		expectedErr string
+	}{
+		{
+			"Valid",
+			entry.NewBodyField("summary"),
+			"{service}-{env}",
+			"",
+		},
+		{
+			"MissingField",
+			entry.Field{},
+			"{service}",
+			"missing required parameter 'field'",
+		},
+		{
+			"MissingTemplate",
+			entry.NewBodyField("summary"),
+			"",
+			"missing required parameter 'template'",
+		},
+		{
+			"UnclosedPlaceholder",
+			entry.NewBodyField("summary"),
+			"{service-{env}",
+			"invalid template: unclosed placeholder at position 0",
+		},
+		{
+			"UnclosedPlaceholderAtEnd",
+			entry.NewBodyField("summary"),
+			"{service}-{env",
+			"invalid template: unclosed placeholder at position 10",
+		},
+		{
+			"UnexpectedClose",
+			entry.NewBodyField("summary"),
+			"service}",
+			"invalid template: unexpected '}' at position 7",
+		},
+		{
+			"EmptyPlaceholder",
+			entry.NewBodyField("summary"),
+			"{ }",
+			"invalid template: placeholder at position 0: empty placeholder",
+		},
+		{
+			"EmptyTimestampLayout",
+			entry.NewBodyField("summary"),
+			"{$timestamp:}",
+			"invalid template: placeholder at position 0: empty timestamp layout",
+		},
+		{
+			"NestedAttribute",
+			entry.NewBodyField("summary"),
+			"{$attributes.a.b}",
+			"attributes cannot be nested",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewFormatOperatorConfig("test")
+			cfg.Field = tc.field
+			cfg.Template = tc.template
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestFormatOperatorTransform(t *testing.T) {
+	timestamp := time.Date(2021, 6, 1, 12, 30, 15, 0, time.UTC)
+
+	newEntry := func() *entry.Entry {
+		e := entry.New()
+		e.Timestamp = timestamp
+		e.Body = map[string]interface{}{
+			"service": "checkout",
+			"status":  503,
+			"latency": 1.5,
+			"tags":    []interface{}{"a", "b"},
+			"request": map[string]interface{}{"method": "GET", "path": "/cart"},
+		}
+		e.Attributes = map[string]string{"env": "prod"}
+		e.Resource = map[string]string{"region": "us-east-1"}
+		return e
+	}
+
+	cases := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			"Body",
+			"{service}",
+			"checkout",
+		},
+		{
+			"AttributesAndResource",
+			"{service}-{$attributes.env}.{$resource.region}",
+			"checkout-prod.us-east-1",
+		},
+		{
+			"NestedBody",
+			"{$body.request.method} {request.path}",
+			"GET /cart",
+		},
+		{
+			"NonString",
+			"status={status} latency={latency}",
+			"status=503 latency=1.5",
+		},
+		{
+			"Map",
+			"{request}",
+			`{"method":"GET","path":"/cart"}`,
+		},
+		{
+			"Slice",
+			"{tags}",
+			`["a","b"]`,
+		},
+		{
+			"Missing",
+			"[{missing}]",
+			"[]",
+		},
+		{
+			"Timestamp",
+			"{$timestamp}",
+			"2021-06-01T12:30:15Z",
+		},
+		{
+			"TimestampLayout",
+			"{$timestamp:2006-01-02 15:04}",
+			"2021-06-01 12:30",
+		},
+		{
+			"Escaped",
+			"{{{service}}}",
+			"{checkout}",
+		},
+		{
+			"Literal",
+			"no placeholders",
+			"no placeholders",
+		},
+		{
+			"Whitespace",
+			"{ service }",
+			"checkout",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewFormatOperatorConfig("test")
+			cfg.Field = entry.NewAttributeField("summary")
+			cfg.Template = tc.template
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0].(*FormatOperator)
+
+			e := newEntry()
+			require.NoError(t, op.Transform(e))
+			require.Equal(t, tc.expected, e.Attributes["summary"])
+		})
+	}
+}
+
+func TestFormatOperatorProcess(t *testing.T) {
+	cfg := NewFormatOperatorConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Field = entry.NewBodyField("summary")
+	cfg.Template = "{service}-{$attributes.env}"
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*FormatOperator)
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = map[string]interface{}{"service": "checkout"}
+	e.Attributes = map[string]string{"env": "prod"}
+	require.NoError(t, op.Process(context.Background(), e))
+	fake.ExpectBody(t, map[string]interface{}{
+		"service": "checkout",
+		"summary": "checkout-prod",
+	})
+}
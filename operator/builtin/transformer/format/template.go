@@ -0,0 +1,165 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+)
+
+const (
+	// TimestampPlaceholder is the placeholder which is replaced by the timestamp of an entry
+	TimestampPlaceholder = "$timestamp"
+
+	// DefaultTimestampLayout is the layout of a timestamp placeholder which does not specify one
+	DefaultTimestampLayout = time.RFC3339Nano
+)
+
+// template is a compiled template, which is a sequence of literals and placeholders
+type template []segment
+
+// segment is a literal, or a placeholder which is replaced by a value of an entry
+type segment struct {
+	literal string
+
+	placeholder bool
+	field       entry.Field
+	timestamp   bool
+	layout      string
+}
+
+// compileTemplate will compile a template. Placeholders are enclosed in braces,
+// and braces are escaped by doubling them.
+func compileTemplate(s string) (template, error) {
+	var t template
+	var literal strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			if i+1 < len(s) && s[i+1] == '{' {
+				literal.WriteByte('{')
+				i++
+				continue
+			}
+
+			end := strings.IndexByte(s[i+1:], '}')
+			if end == -1 || strings.IndexByte(s[i+1:i+1+end], '{') != -1 {
+				return nil, fmt.Errorf("unclosed placeholder at position %d", i)
+			}
+			p, err := compilePlaceholder(s[i+1 : i+1+end])
+			if err != nil {
+				return nil, fmt.Errorf("placeholder at position %d: %s", i, err)
+			}
+
+			if literal.Len() > 0 {
+				t = append(t, segment{literal: literal.String()})
+				literal.Reset()
+			}
+			t = append(t, p)
+			i += end + 1
+		case '}':
+			if i+1 < len(s) && s[i+1] == '}' {
+				literal.WriteByte('}')
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("unexpected '}' at position %d", i)
+		default:
+			literal.WriteByte(s[i])
+		}
+	}
+
+	if literal.Len() > 0 {
+		t = append(t, segment{literal: literal.String()})
+	}
+	return t, nil
+}
+
+// compilePlaceholder will compile the contents of a placeholder, which is a field,
+// or the timestamp with an optional layout.
+func compilePlaceholder(s string) (segment, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return segment{}, fmt.Errorf("empty placeholder")
+	}
+
+	if s == TimestampPlaceholder || strings.HasPrefix(s, TimestampPlaceholder+":") {
+		layout := DefaultTimestampLayout
+		if s != TimestampPlaceholder {
+			layout = s[len(TimestampPlaceholder)+1:]
+			if layout == "" {
+				return segment{}, fmt.Errorf("empty timestamp layout")
+			}
+		}
+		return segment{placeholder: true, timestamp: true, layout: layout}, nil
+	}
+
+	field, err := entry.NewField(s)
+	if err != nil {
+		return segment{}, err
+	}
+	return segment{placeholder: true, field: field}, nil
+}
+
+// render will render a template with the values of an entry. Missing fields are rendered as empty strings.
+func (t template) render(e *entry.Entry) (string, error) {
+	var b strings.Builder
+	for _, s := range t {
+		switch {
+		case !s.placeholder:
+			b.WriteString(s.literal)
+		case s.timestamp:
+			b.WriteString(e.Timestamp.Format(s.layout))
+		default:
+			value, ok := e.Get(s.field)
+			if !ok {
+				continue
+			}
+			str, err := formatValue(value)
+			if err != nil {
+				return "", fmt.Errorf("field %s: %s", s.field, err)
+			}
+			b.WriteString(str)
+		}
+	}
+	return b.String(), nil
+}
+
+// formatValue will format a value of an entry as a string. Maps and slices are formatted as JSON.
+func formatValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case time.Time:
+		return v.Format(DefaultTimestampLayout), nil
+	case map[string]interface{}, map[string]string, []interface{}, []string:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hash
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestHashOperatorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "sha256",
+			Expect: func() *HashOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Fields = []entry.Field{entry.NewBodyField("user", "id"), entry.NewAttributeField("user")}
+				cfg.Key = "pepper"
+				return cfg
+			}(),
+		},
+		{
+			Name: "hmac_env",
+			Expect: func() *HashOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Fields = []entry.Field{entry.NewBodyField("user", "id")}
+				cfg.Algorithm = HMACSHA256Algorithm
+				cfg.KeyEnv = "HASH_KEY"
+				return cfg
+			}(),
+		},
+		{
+			Name: "hmac_file",
+			Expect: func() *HashOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Fields = []entry.Field{entry.NewBodyField("user", "id")}
+				cfg.Algorithm = HMACSHA256Algorithm
+				cfg.KeyFile = "/etc/collector/hash.key"
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *HashOperatorConfig {
+	return NewHashOperatorConfig("hash")
+}
@@ -0,0 +1,192 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hash
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// SHA256Algorithm hashes values with SHA-256, prefixed by the key as a salt, if any
+	SHA256Algorithm = "sha256"
+
+	// HMACSHA256Algorithm hashes values with HMAC-SHA256, which requires a key
+	HMACSHA256Algorithm = "hmac_sha256"
+)
+
+func init() {
+	operator.Register("hash", func() operator.Builder { return NewHashOperatorConfig("") })
+}
+
+// NewHashOperatorConfig creates a new hash operator config with default values
+func NewHashOperatorConfig(operatorID string) *HashOperatorConfig {
+	return &HashOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "hash"),
+		Algorithm:         SHA256Algorithm,
+	}
+}
+
+// HashOperatorConfig is the configuration of a hash operator
+type HashOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+	Fields                   []entry.Field `mapstructure:"fields"    json:"fields"             yaml:"fields"`
+	Algorithm                string        `mapstructure:"algorithm" json:"algorithm"          yaml:"algorithm"`
+	Key                      string        `mapstructure:"key"       json:"key,omitempty"      yaml:"key,omitempty"`
+	KeyEnv                   string        `mapstructure:"key_env"   json:"key_env,omitempty"  yaml:"key_env,omitempty"`
+	KeyFile                  string        `mapstructure:"key_file"  json:"key_file,omitempty" yaml:"key_file,omitempty"`
+}
+
+// Build will build a hash operator from the supplied configuration
+func (c HashOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformer, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Fields) == 0 {
+		return nil, fmt.Errorf("missing required field 'fields'")
+	}
+
+	key, err := c.loadKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var digest func([]byte) string
+	switch c.Algorithm {
+	case SHA256Algorithm:
+		digest = func(value []byte) string {
+			h := sha256.New()
+			_, _ = h.Write(key)
+			_, _ = h.Write(value)
+			return hex.EncodeToString(h.Sum(nil))
+		}
+	case HMACSHA256Algorithm:
+		if len(key) == 0 {
+			return nil, fmt.Errorf("algorithm '%s' requires a key", HMACSHA256Algorithm)
+		}
+		digest = func(value []byte) string {
+			h := hmac.New(sha256.New, key)
+			_, _ = h.Write(value)
+			return hex.EncodeToString(h.Sum(nil))
+		}
+	default:
+		return nil, fmt.Errorf("invalid algorithm '%s', must be '%s' or '%s'", c.Algorithm, SHA256Algorithm, HMACSHA256Algorithm)
+	}
+
+	hashOperator := &HashOperator{
+		TransformerOperator: transformer,
+		fields:              c.Fields,
+		digest:              digest,
+	}
+
+	return []operator.Operator{hashOperator}, nil
+}
+
+// loadKey will load the key from the configuration, an environment variable, or a file.
+// At most one source may be configured.
+func (c HashOperatorConfig) loadKey() ([]byte, error) {
+	sources := 0
+	for _, s := range []string{c.Key, c.KeyEnv, c.KeyFile} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return nil, fmt.Errorf("only one of 'key', 'key_env' and 'key_file' can be set")
+	}
+
+	switch {
+	case c.KeyEnv != "":
+		key, ok := os.LookupEnv(c.KeyEnv)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("environment variable '%s' of key_env is not set", c.KeyEnv)
+		}
+		return []byte(key), nil
+	case c.KeyFile != "":
+		data, err := ioutil.ReadFile(c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read key_file: %s", err)
+		}
+		// A trailing newline is not considered part of the key
+		key := strings.TrimRight(string(data), "\r\n")
+		if key == "" {
+			return nil, fmt.Errorf("key_file %s is empty", c.KeyFile)
+		}
+		return []byte(key), nil
+	default:
+		return []byte(c.Key), nil
+	}
+}
+
+// HashOperator is an operator that replaces the values of fields with their digests
+type HashOperator struct {
+	helper.TransformerOperator
+	fields []entry.Field
+	digest func([]byte) string
+}
+
+// Process will hash the fields of an entry
+func (h *HashOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return h.ProcessWith(ctx, entry, h.Transform)
+}
+
+// Transform will replace the value of each field with its digest. Fields which do not exist are skipped.
+// If any field cannot be hashed, the entry is not modified.
+func (h *HashOperator) Transform(e *entry.Entry) error {
+	digests := make([]string, len(h.fields))
+	found := make([]bool, len(h.fields))
+	for i, field := range h.fields {
+		value, ok := e.Get(field)
+		if !ok {
+			continue
+		}
+
+		var data []byte
+		switch v := value.(type) {
+		case string:
+			data = []byte(v)
+		case []byte:
+			data = v
+		case map[string]interface{}, map[string]string, []interface{}:
+			return fmt.Errorf("field %s of type '%T' cannot be hashed", field, value)
+		default:
+			data = []byte(fmt.Sprintf("%v", v))
+		}
+		digests[i] = h.digest(data)
+		found[i] = true
+	}
+
+	for i, field := range h.fields {
+		if !found[i] {
+			continue
+		}
+		if err := e.Set(field, digests[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
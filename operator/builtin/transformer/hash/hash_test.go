@@ -0,0 +1,228 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hash
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacHex(key, s string) string {
+	h := hmac.New(sha256.New, []byte(key))
+	_, _ = h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func buildOperator(t *testing.T, cfg *HashOperatorConfig) (*HashOperator, error) {
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	if err != nil {
+		return nil, err
+	}
+	return ops[0].(*HashOperator), nil
+}
+
+func TestHashOperatorBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*HashOperatorConfig)
+		expectedErr string
+	}{
+		{
+			"Valid",
+			func(cfg *HashOperatorConfig) {},
+			"",
+		},
+		{
+			"MissingFields",
+			func(cfg *HashOperatorConfig) { cfg.Fields = nil },
+			"missing required field 'fields'",
+		},
+		{
+			"InvalidAlgorithm",
+			func(cfg *HashOperatorConfig) { cfg.Algorithm = "md5" },
+			"invalid algorithm 'md5'",
+		},
+		{
+			"HMACWithoutKey",
+			func(cfg *HashOperatorConfig) { cfg.Algorithm = HMACSHA256Algorithm },
+			"algorithm 'hmac_sha256' requires a key",
+		},
+		{
+			"MultipleKeySources",
+			func(cfg *HashOperatorConfig) {
+				cfg.Key = "secret"
+				cfg.KeyEnv = "HASH_KEY"
+			},
+			"only one of 'key', 'key_env' and 'key_file' can be set",
+		},
+		{
+			"KeyEnvNotSet",
+			func(cfg *HashOperatorConfig) { cfg.KeyEnv = "TEST_HASH_OPERATOR_UNSET" },
+			"environment variable 'TEST_HASH_OPERATOR_UNSET' of key_env is not set",
+		},
+		{
+			"KeyFileMissing",
+			func(cfg *HashOperatorConfig) { cfg.KeyFile = "/does/not/exist" },
+			"read key_file",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewHashOperatorConfig("test")
+			cfg.Fields = []entry.Field{entry.NewBodyField("user")}
+			tc.modify(cfg)
+			_, err := buildOperator(t, cfg)
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestHashOperatorKeySources(t *testing.T) {
+	os.Setenv("TEST_HASH_OPERATOR_KEY", "env-secret")
+	defer os.Unsetenv("TEST_HASH_OPERATOR_KEY")
+
+	keyFile := filepath.Join(t.TempDir(), "hash.key")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte("file-secret\n"), 0600))
+
+	cases := []struct {
+		name     string
+		modify   func(*HashOperatorConfig)
+		expected string
+	}{
+		{
+			"SHA256",
+			func(cfg *HashOperatorConfig) {},
+			sha256Hex("user-1"),
+		},
+		{
+			"SHA256Salted",
+			func(cfg *HashOperatorConfig) { cfg.Key = "pepper" },
+			sha256Hex("pepperuser-1"),
+		},
+		{
+			"HMACKey",
+			func(cfg *HashOperatorConfig) {
+				cfg.Algorithm = HMACSHA256Algorithm
+				cfg.Key = "secret"
+			},
+			hmacHex("secret", "user-1"),
+		},
+		{
+			"HMACKeyEnv",
+			func(cfg *HashOperatorConfig) {
+				cfg.Algorithm = HMACSHA256Algorithm
+				cfg.KeyEnv = "TEST_HASH_OPERATOR_KEY"
+			},
+			hmacHex("env-secret", "user-1"),
+		},
+		{
+			"HMACKeyFile",
+			func(cfg *HashOperatorConfig) {
+				cfg.Algorithm = HMACSHA256Algorithm
+				cfg.KeyFile = keyFile
+			},
+			hmacHex("file-secret", "user-1"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewHashOperatorConfig("test")
+			cfg.Fields = []entry.Field{entry.NewBodyField()}
+			tc.modify(cfg)
+			op, err := buildOperator(t, cfg)
+			require.NoError(t, err)
+
+			e := entry.New()
+			e.Body = "user-1"
+			require.NoError(t, op.Transform(e))
+			require.Equal(t, tc.expected, e.Body)
+		})
+	}
+}
+
+func TestHashOperatorFields(t *testing.T) {
+	cfg := NewHashOperatorConfig("test")
+	cfg.Fields = []entry.Field{
+		entry.NewBodyField("user", "id"),
+		entry.NewBodyField("account"),
+		entry.NewAttributeField("user"),
+		entry.NewBodyField("missing"),
+	}
+	op, err := buildOperator(t, cfg)
+	require.NoError(t, err)
+
+	e := entry.New()
+	e.Body = map[string]interface{}{
+		"user":    map[string]interface{}{"id": "user-1", "name": "bob"},
+		"account": 42,
+	}
+	e.Attributes = map[string]string{"user": "user-1"}
+	require.NoError(t, op.Transform(e))
+
+	// The same value has the same digest wherever it is, so hashed values remain joinable
+	require.Equal(t, map[string]interface{}{
+		"user":    map[string]interface{}{"id": sha256Hex("user-1"), "name": "bob"},
+		"account": sha256Hex("42"),
+	}, e.Body)
+	require.Equal(t, map[string]string{"user": sha256Hex("user-1")}, e.Attributes)
+}
+
+func TestHashOperatorInvalidType(t *testing.T) {
+	cfg := NewHashOperatorConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Fields = []entry.Field{entry.NewBodyField("id"), entry.NewBodyField("user")}
+	op, err := buildOperator(t, cfg)
+	require.NoError(t, err)
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	body := map[string]interface{}{
+		"id":   "user-1",
+		"user": map[string]interface{}{"name": "bob"},
+	}
+	e := entry.New()
+	e.Body = body
+	require.NoError(t, op.Process(context.Background(), e))
+
+	// The entry is sent unmodified when any field cannot be hashed
+	fake.ExpectBody(t, map[string]interface{}{
+		"id":   "user-1",
+		"user": map[string]interface{}{"name": "bob"},
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lookup
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestLookupOperatorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "file",
+			Expect: func() *LookupOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Key = entry.NewAttributeField("host")
+				cfg.File = "/etc/collector/hosts.csv"
+				cfg.KeyColumn = "hostname"
+				cfg.Columns = []string{"team"}
+				return cfg
+			}(),
+		},
+		{
+			Name: "table",
+			Expect: func() *LookupOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Key = entry.NewBodyField("service")
+				cfg.Table = map[string]map[string]interface{}{
+					"checkout": {"team": "payments", "tier": 1},
+				}
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *LookupOperatorConfig {
+	return NewLookupOperatorConfig("lookup")
+}
@@ -0,0 +1,253 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lookup
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// reloadDebounce is the time to wait after the file changes before reloading it,
+// so that a file which is written in several steps is reloaded once
+const reloadDebounce = 100 * time.Millisecond
+
+func init() {
+	operator.Register("lookup", func() operator.Builder { return NewLookupOperatorConfig("") })
+}
+
+// NewLookupOperatorConfig creates a new lookup operator config with default values
+func NewLookupOperatorConfig(operatorID string) *LookupOperatorConfig {
+	return &LookupOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "lookup"),
+	}
+}
+
+// LookupOperatorConfig is the configuration of a lookup operator
+type LookupOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+
+	Key       entry.Field                       `mapstructure:"key"        json:"key"                  yaml:"key"`
+	File      string                            `mapstructure:"file"       json:"file,omitempty"       yaml:"file,omitempty"`
+	Format    string                            `mapstructure:"format"     json:"format,omitempty"     yaml:"format,omitempty"`
+	KeyColumn string                            `mapstructure:"key_column" json:"key_column,omitempty" yaml:"key_column,omitempty"`
+	Table     map[string]map[string]interface{} `mapstructure:"table"      json:"table,omitempty"      yaml:"table,omitempty"`
+	Columns   []string                          `mapstructure:"columns"    json:"columns,omitempty"    yaml:"columns,omitempty"`
+}
+
+// Build will build a lookup operator from the supplied configuration
+func (c LookupOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Key.FieldInterface == nil {
+		return nil, fmt.Errorf("missing required parameter 'key'")
+	}
+
+	lookupOperator := &LookupOperator{
+		TransformerOperator: transformerOperator,
+		key:                 c.Key,
+		columns:             c.Columns,
+	}
+
+	switch {
+	case c.File != "" && c.Table != nil:
+		return nil, fmt.Errorf("only one of 'file' and 'table' can be set")
+	case c.File != "":
+		lookupOperator.path = filepath.Clean(c.File)
+		lookupOperator.format, err = fileFormat(lookupOperator.path, c.Format)
+		if err != nil {
+			return nil, err
+		}
+		if c.KeyColumn != "" && lookupOperator.format != CSVFormat {
+			return nil, fmt.Errorf("key_column can only be used with format '%s'", CSVFormat)
+		}
+		lookupOperator.keyColumn = c.KeyColumn
+		lookupOperator.table, err = loadTable(lookupOperator.path, lookupOperator.format, c.KeyColumn)
+		if err != nil {
+			return nil, err
+		}
+	case c.Table != nil:
+		if c.Format != "" || c.KeyColumn != "" {
+			return nil, fmt.Errorf("format and key_column can only be used with 'file'")
+		}
+		lookupOperator.table, err = newTable(c.Table)
+		if err != nil {
+			return nil, fmt.Errorf("invalid table: %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("one of 'file' or 'table' is required")
+	}
+
+	return []operator.Operator{lookupOperator}, nil
+}
+
+// LookupOperator is an operator that adds the columns of the row matching a key to the attributes of an entry
+type LookupOperator struct {
+	helper.TransformerOperator
+	key       entry.Field
+	columns   []string
+	path      string
+	format    string
+	keyColumn string
+
+	mu    sync.RWMutex
+	table table
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Start will start watching the file for changes, if the table was loaded from a file.
+func (l *LookupOperator) Start(_ operator.Persister) error {
+	if l.path == "" {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create lookup file watcher: %s", err)
+	}
+
+	// The directory is watched, so that a file which is replaced continues to be watched
+	dir := filepath.Dir(l.path)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch lookup file directory %s: %s", dir, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.wg.Add(1)
+	go l.watch(ctx, watcher)
+	return nil
+}
+
+// Stop will stop watching the file for changes.
+func (l *LookupOperator) Stop() error {
+	if l.cancel != nil {
+		l.cancel()
+	}
+	l.wg.Wait()
+	return nil
+}
+
+// watch will reload the table when its file is written, created or replaced.
+func (l *LookupOperator) watch(ctx context.Context, watcher *fsnotify.Watcher) {
+	defer l.wg.Done()
+	defer watcher.Close()
+
+	timer := time.NewTimer(reloadDebounce)
+	if !timer.Stop() {
+		<-timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			if filepath.Clean(event.Name) == l.path {
+				timer.Reset(reloadDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			l.Warnw("Lookup file watcher error", zap.Error(err))
+		case <-timer.C:
+			l.reload()
+		}
+	}
+}
+
+// reload will replace the table with the current contents of its file.
+// The previous table continues to be used if the file can not be read.
+func (l *LookupOperator) reload() {
+	t, err := loadTable(l.path, l.format, l.keyColumn)
+	if err != nil {
+		l.Warnw("Failed to reload lookup file. Continuing to use the previous table", "path", l.path, zap.Error(err))
+		return
+	}
+
+	l.mu.Lock()
+	l.table = t
+	l.mu.Unlock()
+
+	l.Infow("Reloaded lookup file", "path", l.path, "rows", len(t))
+}
+
+// Process will enrich an entry with the columns of the row matching its key.
+func (l *LookupOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return l.ProcessWith(ctx, entry, l.Transform)
+}
+
+// Transform will add the columns of the row matching the key of an entry to its attributes.
+// Entries without the key, or whose key does not match a row, are not modified.
+func (l *LookupOperator) Transform(e *entry.Entry) error {
+	value, ok := e.Get(l.key)
+	if !ok {
+		return nil
+	}
+
+	var key string
+	switch v := value.(type) {
+	case string:
+		key = v
+	case []byte:
+		key = string(v)
+	default:
+		key = fmt.Sprintf("%v", v)
+	}
+
+	l.mu.RLock()
+	row, ok := l.table[key]
+	l.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	if len(l.columns) == 0 {
+		for name, value := range row {
+			e.AddAttribute(name, value)
+		}
+		return nil
+	}
+
+	for _, name := range l.columns {
+		if value, ok := row[name]; ok {
+			e.AddAttribute(name, value)
+		}
+	}
+	return nil
+}
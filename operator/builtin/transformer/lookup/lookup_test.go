@@ -0,0 +1,365 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lookup
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+const hostsCSV = `datacenter,hostname,team
+us-east-1,web-1,storefront
+eu-west-1,db-1,platform
+`
+
+const servicesJSON = `{
+  "checkout": {"team": "payments", "tier": 1, "critical": true},
+  "search": {"team": "discovery"}
+}`
+
+func writeFile(t *testing.T, dir, name, contents string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func buildOperator(t *testing.T, cfg *LookupOperatorConfig) (*LookupOperator, error) {
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	if err != nil {
+		return nil, err
+	}
+	op := ops[0].(*LookupOperator)
+	require.NoError(t, op.SetOutputs([]operator.Operator{testutil.NewFakeOutput(t)}))
+	return op, nil
+}
+
+func TestLookupOperatorBuild(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := writeFile(t, dir, "hosts.csv", hostsCSV)
+	jsonPath := writeFile(t, dir, "services.json", servicesJSON)
+	txtPath := writeFile(t, dir, "hosts.txt", hostsCSV)
+	invalidPath := writeFile(t, dir, "invalid.json", `{"checkout": "payments"}`)
+
+	cases := []struct {
+		name        string
+		modify      func(*LookupOperatorConfig)
+		expectedErr string
+	}{
+		{
+			"CSV",
+			func(cfg *LookupOperatorConfig) { cfg.File = csvPath },
+			"",
+		},
+		{
+			"JSON",
+			func(cfg *LookupOperatorConfig) { cfg.File = jsonPath },
+			"",
+		},
+		{
+			"ExplicitFormat",
+			func(cfg *LookupOperatorConfig) {
+				cfg.File = txtPath
+				cfg.Format = CSVFormat
+			},
+			"",
+		},
+		{
+			"Table",
+			func(cfg *LookupOperatorConfig) {
+				cfg.Table = map[string]map[string]interface{}{"checkout": {"team": "payments"}}
+			},
+			"",
+		},
+		{
+			"MissingKey",
+			func(cfg *LookupOperatorConfig) {
+				cfg.Key = entry.Field{}
+				cfg.File = csvPath
+			},
+			"missing required parameter 'key'",
+		},
+		{
+			"MissingSource",
+			func(cfg *LookupOperatorConfig) {},
+			"one of 'file' or 'table' is required",
+		},
+		{
+			"FileAndTable",
+			func(cfg *LookupOperatorConfig) {
+				cfg.File = csvPath
+				cfg.Table = map[string]map[string]interface{}{}
+			},
+			"only one of 'file' and 'table' can be set",
+		},
+		{
+			"UnknownFormat",
+			func(cfg *LookupOperatorConfig) { cfg.File = txtPath },
+			"unable to determine the format of file",
+		},
+		{
+			"KeyColumnJSON",
+			func(cfg *LookupOperatorConfig) {
+				cfg.File = jsonPath
+				cfg.KeyColumn = "service"
+			},
+			"key_column can only be used with format 'csv'",
+		},
+		{
+			"KeyColumnMissing",
+			func(cfg *LookupOperatorConfig) {
+				cfg.File = csvPath
+				cfg.KeyColumn = "host"
+			},
+			"key column 'host' is not in the header row",
+		},
+		{
+			"FormatWithTable",
+			func(cfg *LookupOperatorConfig) {
+				cfg.Table = map[string]map[string]interface{}{}
+				cfg.Format = CSVFormat
+			},
+			"format and key_column can only be used with 'file'",
+		},
+		{
+			"FileMissing",
+			func(cfg *LookupOperatorConfig) { cfg.File = filepath.Join(dir, "missing.csv") },
+			"read lookup file",
+		},
+		{
+			"InvalidJSON",
+			func(cfg *LookupOperatorConfig) { cfg.File = invalidPath },
+			"parse lookup file",
+		},
+		{
+			"InvalidTableValue",
+			func(cfg *LookupOperatorConfig) {
+				cfg.Table = map[string]map[string]interface{}{"checkout": {"teams": []interface{}{"a"}}}
+			},
+			"column 'teams' of key 'checkout' has unsupported type",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewLookupOperatorConfig("test")
+			cfg.Key = entry.NewAttributeField("host")
+			tc.modify(cfg)
+			_, err := buildOperator(t, cfg)
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestLookupOperatorTransform(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := writeFile(t, dir, "hosts.csv", hostsCSV)
+	jsonPath := writeFile(t, dir, "services.json", servicesJSON)
+
+	cases := []struct {
+		name     string
+		modify   func(*LookupOperatorConfig)
+		input    func() *entry.Entry
+		expected map[string]string
+	}{
+		{
+			"CSVFirstColumn",
+			func(cfg *LookupOperatorConfig) {
+				cfg.Key = entry.NewBodyField("dc")
+				cfg.File = csvPath
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{"dc": "us-east-1"}
+				return e
+			},
+			map[string]string{"hostname": "web-1", "team": "storefront"},
+		},
+		{
+			"CSVKeyColumn",
+			func(cfg *LookupOperatorConfig) {
+				cfg.Key = entry.NewAttributeField("host")
+				cfg.File = csvPath
+				cfg.KeyColumn = "hostname"
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Attributes = map[string]string{"host": "db-1"}
+				return e
+			},
+			map[string]string{"host": "db-1", "datacenter": "eu-west-1", "team": "platform"},
+		},
+		{
+			"JSON",
+			func(cfg *LookupOperatorConfig) {
+				cfg.Key = entry.NewBodyField("service")
+				cfg.File = jsonPath
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{"service": "checkout"}
+				return e
+			},
+			map[string]string{"team": "payments", "tier": "1", "critical": "true"},
+		},
+		{
+			"Columns",
+			func(cfg *LookupOperatorConfig) {
+				cfg.Key = entry.NewBodyField("service")
+				cfg.File = jsonPath
+				cfg.Columns = []string{"team", "owner"}
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{"service": "checkout"}
+				return e
+			},
+			map[string]string{"team": "payments"},
+		},
+		{
+			"Table",
+			func(cfg *LookupOperatorConfig) {
+				cfg.Key = entry.NewBodyField("port")
+				cfg.Table = map[string]map[string]interface{}{"443": {"protocol": "https"}}
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{"port": 443}
+				return e
+			},
+			map[string]string{"protocol": "https"},
+		},
+		{
+			"NoMatch",
+			func(cfg *LookupOperatorConfig) {
+				cfg.Key = entry.NewBodyField("service")
+				cfg.File = jsonPath
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{"service": "unknown"}
+				return e
+			},
+			nil,
+		},
+		{
+			"MissingKey",
+			func(cfg *LookupOperatorConfig) {
+				cfg.Key = entry.NewBodyField("service")
+				cfg.File = jsonPath
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = "no service"
+				return e
+			},
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewLookupOperatorConfig("test")
+			tc.modify(cfg)
+			op, err := buildOperator(t, cfg)
+			require.NoError(t, err)
+
+			e := tc.input()
+			require.NoError(t, op.Transform(e))
+			require.Equal(t, tc.expected, e.Attributes)
+		})
+	}
+}
+
+func TestLookupOperatorProcess(t *testing.T) {
+	cfg := NewLookupOperatorConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Key = entry.NewBodyField("service")
+	cfg.Table = map[string]map[string]interface{}{"checkout": {"team": "payments"}}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*LookupOperator)
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	// A table without a file is not watched
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	defer op.Stop()
+
+	e := entry.New()
+	e.Body = map[string]interface{}{"service": "checkout"}
+	require.NoError(t, op.Process(context.Background(), e))
+
+	select {
+	case processed := <-fake.Received:
+		require.Equal(t, map[string]string{"team": "payments"}, processed.Attributes)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry to be processed")
+	}
+}
+
+func TestLookupOperatorReload(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "services.json", servicesJSON)
+
+	cfg := NewLookupOperatorConfig("test")
+	cfg.Key = entry.NewBodyField("service")
+	cfg.File = path
+	op, err := buildOperator(t, cfg)
+	require.NoError(t, err)
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	defer op.Stop()
+
+	team := func() string {
+		e := entry.New()
+		e.Body = map[string]interface{}{"service": "search"}
+		require.NoError(t, op.Transform(e))
+		return e.Attributes["team"]
+	}
+	require.Equal(t, "discovery", team())
+
+	// An invalid file is ignored, and the previous table is used
+	require.NoError(t, ioutil.WriteFile(path, []byte("not json"), 0600))
+	time.Sleep(2 * reloadDebounce)
+	require.Equal(t, "discovery", team())
+
+	// A file which is written is reloaded
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"search": {"team": "relevance"}}`), 0600))
+	require.Eventually(t, func() bool {
+		return team() == "relevance"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A file which is replaced is reloaded
+	tempPath := writeFile(t, t.TempDir(), "services.json.tmp", `{"search": {"team": "ranking"}}`)
+	require.NoError(t, os.Rename(tempPath, path))
+	require.Eventually(t, func() bool {
+		return team() == "ranking"
+	}, 5*time.Second, 10*time.Millisecond)
+}
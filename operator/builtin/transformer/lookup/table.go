@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lookup
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// CSVFormat is a table with a header row, whose rows are keyed by one of its columns
+	CSVFormat = "csv"
+
+	// JSONFormat is a table which is an object of keys to objects of columns
+	JSONFormat = "json"
+)
+
+// table is a mapping of keys to the columns of their rows.
+type table map[string]map[string]string
+
+// fileFormat will return the format of a file, inferring it from the extension if it is not configured.
+func fileFormat(path, format string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	switch format {
+	case CSVFormat, JSONFormat:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unable to determine the format of file %s, set format to '%s' or '%s'", path, CSVFormat, JSONFormat)
+	}
+}
+
+// loadTable will load a table from a file.
+func loadTable(path, format, keyColumn string) (table, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read lookup file: %s", err)
+	}
+
+	var t table
+	switch format {
+	case CSVFormat:
+		t, err = parseCSV(data, keyColumn)
+	default:
+		t, err = parseJSON(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse lookup file %s: %s", path, err)
+	}
+	return t, nil
+}
+
+// parseCSV will parse a table from CSV with a header row. Rows are keyed by the key column,
+// or the first column if none is configured, which is not included in their columns.
+func parseCSV(data []byte, keyColumn string) (table, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header row")
+	}
+
+	header := records[0]
+	keyIndex := 0
+	if keyColumn != "" {
+		keyIndex = -1
+		for i, name := range header {
+			if name == keyColumn {
+				keyIndex = i
+				break
+			}
+		}
+		if keyIndex < 0 {
+			return nil, fmt.Errorf("key column '%s' is not in the header row", keyColumn)
+		}
+	}
+
+	t := make(table, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header)-1)
+		for i, value := range record {
+			if i != keyIndex {
+				row[header[i]] = value
+			}
+		}
+		t[record[keyIndex]] = row
+	}
+	return t, nil
+}
+
+// parseJSON will parse a table from a JSON object of keys to objects of columns.
+// Values which are not strings are converted to strings.
+func parseJSON(data []byte) (table, error) {
+	var raw map[string]map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return newTable(raw)
+}
+
+// newTable will create a table from a map of keys to maps of columns.
+func newTable(raw map[string]map[string]interface{}) (table, error) {
+	t := make(table, len(raw))
+	for key, columns := range raw {
+		row := make(map[string]string, len(columns))
+		for name, value := range columns {
+			switch v := value.(type) {
+			case string:
+				row[name] = v
+			case float64:
+				row[name] = strconv.FormatFloat(v, 'f', -1, 64)
+			case int:
+				row[name] = strconv.Itoa(v)
+			case bool:
+				row[name] = strconv.FormatBool(v)
+			case nil:
+			default:
+				return nil, fmt.Errorf("column '%s' of key '%s' has unsupported type %T", name, key, value)
+			}
+		}
+		t[key] = row
+	}
+	return t, nil
+}
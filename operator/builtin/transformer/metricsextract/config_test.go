@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsextract
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestMetricsExtractConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "metrics",
+			Expect: func() *MetricsExtractConfig {
+				cfg := defaultCfg()
+				cfg.Interval = helper.NewDuration(30 * time.Second)
+				cfg.PassThrough = false
+				cfg.MaxSeries = 100
+				duration := entry.NewBodyField("duration")
+				cfg.Metrics = []MetricConfig{
+					{
+						Name: "http.requests",
+						Type: CounterType,
+						Labels: map[string]entry.Field{
+							"method": entry.NewAttributeField("method"),
+							"status": entry.NewBodyField("status"),
+						},
+					},
+					{
+						Name:    "http.request.duration",
+						Type:    HistogramType,
+						Field:   &duration,
+						Buckets: []float64{0.1, 0.5, 1},
+					},
+				}
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *MetricsExtractConfig {
+	return NewMetricsExtractConfig("metrics_extract")
+}
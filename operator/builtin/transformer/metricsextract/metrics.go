@@ -0,0 +1,161 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsextract
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+)
+
+const (
+	// CounterType is a metric which counts entries, or sums the values of a field
+	CounterType = "counter"
+
+	// HistogramType is a metric which records the distribution of the values of a field
+	HistogramType = "histogram"
+)
+
+// defaultBuckets are the upper bounds of the buckets of a histogram, if none are configured
+var defaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// MetricConfig is the configuration of a metric.
+type MetricConfig struct {
+	Name    string                 `mapstructure:"name"    json:"name"              yaml:"name"`
+	Type    string                 `mapstructure:"type"    json:"type"              yaml:"type"`
+	Field   *entry.Field           `mapstructure:"field"   json:"field,omitempty"   yaml:"field,omitempty"`
+	Buckets []float64              `mapstructure:"buckets" json:"buckets,omitempty" yaml:"buckets,omitempty"`
+	Labels  map[string]entry.Field `mapstructure:"labels"  json:"labels,omitempty"  yaml:"labels,omitempty"`
+}
+
+// metric is a configured metric.
+type metric struct {
+	name    string
+	typ     string
+	field   *entry.Field
+	buckets []float64
+	labels  []label
+}
+
+// label is a label of a metric, whose value is read from a field.
+type label struct {
+	name  string
+	field entry.Field
+}
+
+// build will validate the configuration of a metric.
+func (c MetricConfig) build() (*metric, error) {
+	if c.Name == "" {
+		return nil, fmt.Errorf("missing required field 'name'")
+	}
+
+	m := &metric{
+		name:  c.Name,
+		typ:   c.Type,
+		field: c.Field,
+	}
+
+	switch c.Type {
+	case CounterType:
+		if len(c.Buckets) > 0 {
+			return nil, fmt.Errorf("buckets of metric '%s' can only be used with type '%s'", c.Name, HistogramType)
+		}
+	case HistogramType:
+		if c.Field == nil {
+			return nil, fmt.Errorf("metric '%s' of type '%s' requires a field", c.Name, HistogramType)
+		}
+		m.buckets = c.Buckets
+		if len(m.buckets) == 0 {
+			m.buckets = defaultBuckets
+		}
+		for i := 1; i < len(m.buckets); i++ {
+			if m.buckets[i] <= m.buckets[i-1] {
+				return nil, fmt.Errorf("buckets of metric '%s' must be in increasing order", c.Name)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid type '%s' of metric '%s', must be '%s' or '%s'", c.Type, c.Name, CounterType, HistogramType)
+	}
+
+	for name, field := range c.Labels {
+		m.labels = append(m.labels, label{name: name, field: field})
+	}
+	sort.Slice(m.labels, func(i, j int) bool { return m.labels[i].name < m.labels[j].name })
+	return m, nil
+}
+
+// labelValues will return the values of the labels of an entry. Labels whose field does not exist are empty.
+func (m *metric) labelValues(e *entry.Entry) []string {
+	values := make([]string, len(m.labels))
+	for i, l := range m.labels {
+		if value, ok := e.Get(l.field); ok {
+			values[i] = fmt.Sprintf("%v", value)
+		}
+	}
+	return values
+}
+
+// series is the value of a metric with a set of label values, since it was last emitted.
+type series struct {
+	metric      *metric
+	labelValues []string
+	count       int
+	sum         float64
+	bucketCount []int
+}
+
+// record will record an entry. The value is ignored by a counter without a field.
+func (s *series) record(value float64) {
+	s.count++
+	s.sum += value
+	for i, bound := range s.metric.buckets {
+		if value <= bound {
+			s.bucketCount[i]++
+		}
+	}
+}
+
+// toEntry will create the entry of a series.
+func (s *series) toEntry() *entry.Entry {
+	body := map[string]interface{}{
+		"name": s.metric.name,
+		"type": s.metric.typ,
+	}
+
+	switch {
+	case s.metric.typ == HistogramType:
+		buckets := make([]interface{}, len(s.metric.buckets))
+		for i, bound := range s.metric.buckets {
+			buckets[i] = map[string]interface{}{"le": bound, "count": s.bucketCount[i]}
+		}
+		body["count"] = s.count
+		body["sum"] = s.sum
+		body["buckets"] = buckets
+	case s.metric.field != nil:
+		body["value"] = s.sum
+	default:
+		body["value"] = s.count
+	}
+
+	e := entry.New()
+	e.Body = body
+	for i, l := range s.metric.labels {
+		if s.labelValues[i] != "" {
+			e.AddAttribute(l.name, s.labelValues[i])
+		}
+	}
+	return e
+}
@@ -0,0 +1,225 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsextract
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("metrics_extract", func() operator.Builder { return NewMetricsExtractConfig("") })
+}
+
+// NewMetricsExtractConfig creates a new metrics extract config with default values
+func NewMetricsExtractConfig(operatorID string) *MetricsExtractConfig {
+	return &MetricsExtractConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "metrics_extract"),
+		Interval:          helper.NewDuration(time.Minute),
+		PassThrough:       true,
+		MaxSeries:         10000,
+	}
+}
+
+// MetricsExtractConfig is the configuration of a metrics extract operator
+type MetricsExtractConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+	Interval                 helper.Duration `mapstructure:"interval"     json:"interval"     yaml:"interval"`
+	Metrics                  []MetricConfig  `mapstructure:"metrics"      json:"metrics"      yaml:"metrics"`
+	PassThrough              bool            `mapstructure:"pass_through" json:"pass_through" yaml:"pass_through"`
+	MaxSeries                int             `mapstructure:"max_series"   json:"max_series"   yaml:"max_series"`
+}
+
+// Build will build a metrics extract operator from the supplied configuration
+func (c MetricsExtractConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformer, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Interval.Raw() <= 0 {
+		return nil, fmt.Errorf("interval must be greater than 0")
+	}
+
+	if c.MaxSeries <= 0 {
+		return nil, fmt.Errorf("max_series must be greater than 0")
+	}
+
+	if len(c.Metrics) == 0 {
+		return nil, fmt.Errorf("missing required field 'metrics'")
+	}
+
+	metrics := make([]*metric, 0, len(c.Metrics))
+	names := make(map[string]bool, len(c.Metrics))
+	for i, mc := range c.Metrics {
+		m, err := mc.build()
+		if err != nil {
+			return nil, fmt.Errorf("metrics[%d]: %s", i, err)
+		}
+		if names[m.name] {
+			return nil, fmt.Errorf("metric '%s' is defined more than once", m.name)
+		}
+		names[m.name] = true
+		metrics = append(metrics, m)
+	}
+
+	metricsExtract := &MetricsExtractOperator{
+		TransformerOperator: transformer,
+		interval:            c.Interval.Raw(),
+		metrics:             metrics,
+		passThrough:         c.PassThrough,
+		maxSeries:           c.MaxSeries,
+		series:              map[string]*series{},
+		now:                 time.Now,
+	}
+
+	return []operator.Operator{metricsExtract}, nil
+}
+
+// MetricsExtractOperator is an operator that records metrics from entries, and periodically writes them as entries
+type MetricsExtractOperator struct {
+	helper.TransformerOperator
+	interval    time.Duration
+	metrics     []*metric
+	passThrough bool
+	maxSeries   int
+	now         func() time.Time
+
+	sync.Mutex
+	series  map[string]*series
+	dropped int
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Start will start writing metrics on each interval
+func (m *MetricsExtractOperator) Start(_ operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.goFlush(ctx)
+	return nil
+}
+
+// Stop will stop writing metrics, and write the metrics of the current interval
+func (m *MetricsExtractOperator) Stop() error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	m.flush(ctx)
+	return nil
+}
+
+// goFlush will write metrics on each interval
+func (m *MetricsExtractOperator) goFlush(ctx context.Context) {
+	m.wg.Add(1)
+
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.flush(ctx)
+			}
+		}
+	}()
+}
+
+// Process will record the metrics of an entry, and write the entry if pass_through is enabled
+func (m *MetricsExtractOperator) Process(ctx context.Context, e *entry.Entry) error {
+	skip, err := m.Skip(ctx, e)
+	if err != nil {
+		return m.HandleEntryError(ctx, e, err)
+	}
+	if !skip {
+		m.record(e)
+	}
+
+	if skip || m.passThrough {
+		m.Write(ctx, e)
+	}
+	return nil
+}
+
+// record will record the metrics of an entry.
+func (m *MetricsExtractOperator) record(e *entry.Entry) {
+	m.Lock()
+	defer m.Unlock()
+
+	for i, metric := range m.metrics {
+		var value float64
+		if metric.field != nil {
+			var ok bool
+			if value, ok = helper.NumericValue(e, *metric.field); !ok {
+				continue
+			}
+		}
+
+		labelValues := metric.labelValues(e)
+		key := fmt.Sprintf("%d\x00%s", i, strings.Join(labelValues, "\x00"))
+		s, ok := m.series[key]
+		if !ok {
+			if len(m.series) >= m.maxSeries {
+				m.dropped++
+				continue
+			}
+			s = &series{
+				metric:      metric,
+				labelValues: labelValues,
+				bucketCount: make([]int, len(metric.buckets)),
+			}
+			m.series[key] = s
+		}
+		s.record(value)
+	}
+}
+
+// flush will write an entry for each series, and start a new interval
+func (m *MetricsExtractOperator) flush(ctx context.Context) {
+	m.Lock()
+	pending := m.series
+	dropped := m.dropped
+	m.series = make(map[string]*series, len(pending))
+	m.dropped = 0
+	m.Unlock()
+
+	if dropped > 0 {
+		m.Warnf("Dropped %d values of series beyond max_series", dropped)
+	}
+
+	timestamp := m.now()
+	for _, s := range pending {
+		e := s.toEntry()
+		e.Timestamp = timestamp
+		m.Write(ctx, e)
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsextract
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestOperator(t *testing.T, cfg *MetricsExtractConfig) (*MetricsExtractOperator, *testutil.FakeOutput) {
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*MetricsExtractOperator)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fakeOutput}))
+	return op, fakeOutput
+}
+
+func newEntry(body interface{}, attributes map[string]string) *entry.Entry {
+	e := entry.New()
+	e.Body = body
+	e.Attributes = attributes
+	return e
+}
+
+func fieldPtr(field entry.Field) *entry.Field {
+	return &field
+}
+
+// receiveMetrics will receive a number of metric entries, keyed by their name and attributes
+func receiveMetrics(t *testing.T, fake *testutil.FakeOutput, n int) map[string]*entry.Entry {
+	received := make(map[string]*entry.Entry, n)
+	for i := 0; i < n; i++ {
+		select {
+		case e := <-fake.Received:
+			key := e.Body.(map[string]interface{})["name"].(string)
+			for _, name := range []string{"method", "status"} {
+				if v, ok := e.Attributes[name]; ok {
+					key += " " + name + "=" + v
+				}
+			}
+			received[key] = e
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for metric entry")
+		}
+	}
+	fake.ExpectNoEntry(t, 0)
+	return received
+}
+
+func TestMetricsExtractBuild(t *testing.T) {
+	duration := entry.NewBodyField("duration")
+	cases := []struct {
+		name        string
+		metrics     []MetricConfig
+		modify      func(*MetricsExtractConfig)
+		expectedErr string
+	}{
+		{
+			"Valid",
+			[]MetricConfig{{Name: "requests", Type: CounterType}},
+			nil,
+			"",
+		},
+		{
+			"MissingMetrics",
+			nil,
+			nil,
+			"missing required field 'metrics'",
+		},
+		{
+			"MissingName",
+			[]MetricConfig{{Type: CounterType}},
+			nil,
+			"metrics[0]: missing required field 'name'",
+		},
+		{
+			"InvalidType",
+			[]MetricConfig{{Name: "requests", Type: "gauge"}},
+			nil,
+			"invalid type 'gauge' of metric 'requests'",
+		},
+		{
+			"HistogramWithoutField",
+			[]MetricConfig{{Name: "duration", Type: HistogramType}},
+			nil,
+			"metric 'duration' of type 'histogram' requires a field",
+		},
+		{
+			"CounterWithBuckets",
+			[]MetricConfig{{Name: "requests", Type: CounterType, Buckets: []float64{1}}},
+			nil,
+			"buckets of metric 'requests' can only be used with type 'histogram'",
+		},
+		{
+			"UnorderedBuckets",
+			[]MetricConfig{{Name: "duration", Type: HistogramType, Field: &duration, Buckets: []float64{1, 0.5}}},
+			nil,
+			"buckets of metric 'duration' must be in increasing order",
+		},
+		{
+			"DuplicateName",
+			[]MetricConfig{{Name: "requests", Type: CounterType}, {Name: "requests", Type: CounterType}},
+			nil,
+			"metric 'requests' is defined more than once",
+		},
+		{
+			"ZeroInterval",
+			[]MetricConfig{{Name: "requests", Type: CounterType}},
+			func(cfg *MetricsExtractConfig) { cfg.Interval = helper.NewDuration(0) },
+			"interval must be greater than 0",
+		},
+		{
+			"ZeroMaxSeries",
+			[]MetricConfig{{Name: "requests", Type: CounterType}},
+			func(cfg *MetricsExtractConfig) { cfg.MaxSeries = 0 },
+			"max_series must be greater than 0",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewMetricsExtractConfig("test")
+			cfg.Metrics = tc.metrics
+			if tc.modify != nil {
+				tc.modify(cfg)
+			}
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestMetricsExtractCounter(t *testing.T) {
+	cfg := NewMetricsExtractConfig("test")
+	cfg.PassThrough = false
+	cfg.Metrics = []MetricConfig{
+		{
+			Name: "http.requests",
+			Type: CounterType,
+			Labels: map[string]entry.Field{
+				"method": entry.NewAttributeField("method"),
+				"status": entry.NewBodyField("status"),
+			},
+		},
+		{
+			Name:  "http.response.bytes",
+			Type:  CounterType,
+			Field: fieldPtr(entry.NewBodyField("bytes")),
+		},
+	}
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+
+	entries := []*entry.Entry{
+		newEntry(map[string]interface{}{"status": 200, "bytes": 100}, map[string]string{"method": "GET"}),
+		newEntry(map[string]interface{}{"status": 200, "bytes": "50"}, map[string]string{"method": "GET"}),
+		newEntry(map[string]interface{}{"status": 500}, map[string]string{"method": "POST"}),
+	}
+	for _, e := range entries {
+		require.NoError(t, op.Process(ctx, e))
+	}
+	fake.ExpectNoEntry(t, 0)
+
+	end := time.Date(2021, 6, 1, 12, 1, 0, 0, time.UTC)
+	op.now = func() time.Time { return end }
+	op.flush(ctx)
+
+	received := receiveMetrics(t, fake, 3)
+	get := received["http.requests method=GET status=200"]
+	require.Equal(t, map[string]interface{}{"name": "http.requests", "type": "counter", "value": 2}, get.Body)
+	require.Equal(t, map[string]string{"method": "GET", "status": "200"}, get.Attributes)
+	require.Equal(t, end, get.Timestamp)
+
+	post := received["http.requests method=POST status=500"]
+	require.Equal(t, map[string]interface{}{"name": "http.requests", "type": "counter", "value": 1}, post.Body)
+
+	// Entries without the field of a metric are not recorded by it
+	bytes := received["http.response.bytes"]
+	require.Equal(t, map[string]interface{}{"name": "http.response.bytes", "type": "counter", "value": 150.0}, bytes.Body)
+	require.Nil(t, bytes.Attributes)
+
+	// Values are reset after each interval
+	op.flush(ctx)
+	fake.ExpectNoEntry(t, 0)
+}
+
+func TestMetricsExtractHistogram(t *testing.T) {
+	cfg := NewMetricsExtractConfig("test")
+	cfg.PassThrough = false
+	cfg.Metrics = []MetricConfig{
+		{
+			Name:    "http.request.duration",
+			Type:    HistogramType,
+			Field:   fieldPtr(entry.NewBodyField("duration")),
+			Buckets: []float64{0.1, 0.5, 1},
+		},
+	}
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+
+	for _, d := range []interface{}{0.0625, 0.125, 0.25, 2.0, "0.75", "slow"} {
+		require.NoError(t, op.Process(ctx, newEntry(map[string]interface{}{"duration": d}, nil)))
+	}
+
+	op.flush(ctx)
+	fake.ExpectBody(t, map[string]interface{}{
+		"name":  "http.request.duration",
+		"type":  "histogram",
+		"count": 5,
+		"sum":   3.1875,
+		"buckets": []interface{}{
+			map[string]interface{}{"le": 0.1, "count": 1},
+			map[string]interface{}{"le": 0.5, "count": 3},
+			map[string]interface{}{"le": 1.0, "count": 4},
+		},
+	})
+}
+
+func TestMetricsExtractDefaultBuckets(t *testing.T) {
+	cfg := NewMetricsExtractConfig("test")
+	cfg.Metrics = []MetricConfig{
+		{Name: "duration", Type: HistogramType, Field: fieldPtr(entry.NewBodyField("duration"))},
+	}
+	op, _ := newTestOperator(t, cfg)
+	require.Equal(t, defaultBuckets, op.metrics[0].buckets)
+}
+
+func TestMetricsExtractPassThrough(t *testing.T) {
+	cfg := NewMetricsExtractConfig("test")
+	cfg.IfExpr = `$body.path == "/api"`
+	cfg.Metrics = []MetricConfig{{Name: "requests", Type: CounterType}}
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+
+	api := map[string]interface{}{"path": "/api"}
+	require.NoError(t, op.Process(ctx, newEntry(api, nil)))
+	fake.ExpectBody(t, api)
+
+	// Entries which do not match the if expression are written, and not recorded
+	health := map[string]interface{}{"path": "/health"}
+	require.NoError(t, op.Process(ctx, newEntry(health, nil)))
+	fake.ExpectBody(t, health)
+
+	op.flush(ctx)
+	fake.ExpectBody(t, map[string]interface{}{"name": "requests", "type": "counter", "value": 1})
+}
+
+func TestMetricsExtractMaxSeries(t *testing.T) {
+	cfg := NewMetricsExtractConfig("test")
+	cfg.PassThrough = false
+	cfg.MaxSeries = 1
+	cfg.Metrics = []MetricConfig{
+		{Name: "requests", Type: CounterType, Labels: map[string]entry.Field{"method": entry.NewAttributeField("method")}},
+	}
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+
+	require.NoError(t, op.Process(ctx, newEntry("a", map[string]string{"method": "GET"})))
+	require.NoError(t, op.Process(ctx, newEntry("b", map[string]string{"method": "POST"})))
+	require.NoError(t, op.Process(ctx, newEntry("c", map[string]string{"method": "GET"})))
+
+	op.flush(ctx)
+	fake.ExpectBody(t, map[string]interface{}{"name": "requests", "type": "counter", "value": 2})
+	fake.ExpectNoEntry(t, 0)
+}
+
+func TestMetricsExtractInterval(t *testing.T) {
+	cfg := NewMetricsExtractConfig("test")
+	cfg.Interval = helper.NewDuration(50 * time.Millisecond)
+	cfg.PassThrough = false
+	cfg.Metrics = []MetricConfig{{Name: "requests", Type: CounterType}}
+	op, fake := newTestOperator(t, cfg)
+	ctx := context.Background()
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	defer func() {
+		require.NoError(t, op.Stop())
+	}()
+
+	require.NoError(t, op.Process(ctx, newEntry("a", nil)))
+	fake.ExpectBody(t, map[string]interface{}{"name": "requests", "type": "counter", "value": 1})
+}
+
+func TestMetricsExtractStop(t *testing.T) {
+	cfg := NewMetricsExtractConfig("test")
+	cfg.PassThrough = false
+	cfg.Metrics = []MetricConfig{{Name: "requests", Type: CounterType}}
+	op, fake := newTestOperator(t, cfg)
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+
+	require.NoError(t, op.Process(context.Background(), newEntry("a", nil)))
+
+	// The metrics of the current interval are written when the operator stops
+	require.NoError(t, op.Stop())
+	fake.ExpectBody(t, map[string]interface{}{"name": "requests", "type": "counter", "value": 1})
+}
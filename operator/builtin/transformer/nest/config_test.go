@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nest
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestNestOperatorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "keys",
+			Expect: func() *NestOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Keys = []string{"method", "status"}
+				cfg.To = &entry.BodyField{Keys: []string{"http"}}
+				return cfg
+			}(),
+		},
+		{
+			Name: "prefix",
+			Expect: func() *NestOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.BodyField{Keys: []string{"request"}}
+				cfg.Prefix = "http_"
+				cfg.To = &entry.BodyField{Keys: []string{"request", "http"}}
+				return cfg
+			}(),
+		},
+		{
+			Name: "separator",
+			Expect: func() *NestOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Separator = "_"
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *NestOperatorConfig {
+	return NewNestOperatorConfig("nest")
+}
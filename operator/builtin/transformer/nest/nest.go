@@ -0,0 +1,222 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("nest", func() operator.Builder { return NewNestOperatorConfig("") })
+}
+
+// NewNestOperatorConfig creates a new nest operator config with default values
+func NewNestOperatorConfig(operatorID string) *NestOperatorConfig {
+	return &NestOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "nest"),
+		Field:             entry.BodyField{Keys: []string{}},
+	}
+}
+
+// NestOperatorConfig is the configuration of a nest operator
+type NestOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+	Field                    entry.BodyField  `mapstructure:"field"     json:"field"               yaml:"field"`
+	To                       *entry.BodyField `mapstructure:"to"        json:"to,omitempty"        yaml:"to,omitempty"`
+	Keys                     []string         `mapstructure:"keys"      json:"keys,omitempty"      yaml:"keys,omitempty"`
+	Prefix                   string           `mapstructure:"prefix"    json:"prefix,omitempty"    yaml:"prefix,omitempty"`
+	Separator                string           `mapstructure:"separator" json:"separator,omitempty" yaml:"separator,omitempty"`
+}
+
+// Build will build a nest operator from the supplied configuration
+func (c NestOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(c.Field.String(), "$attributes") || strings.Contains(c.Field.String(), "$resource") {
+		return nil, fmt.Errorf("nest: field cannot be a resource or attribute")
+	}
+
+	modes := 0
+	for _, set := range []bool{len(c.Keys) > 0, c.Prefix != "", c.Separator != ""} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
+		return nil, fmt.Errorf("nest: exactly one of keys, prefix or separator must be set")
+	}
+
+	nestOperator := &NestOperator{
+		TransformerOperator: transformerOperator,
+		Field:               c.Field,
+		Keys:                c.Keys,
+		Prefix:              c.Prefix,
+		Separator:           c.Separator,
+	}
+
+	if c.Separator != "" {
+		if c.To != nil {
+			return nil, fmt.Errorf("nest: to cannot be used with separator")
+		}
+		return []operator.Operator{nestOperator}, nil
+	}
+
+	if c.To == nil {
+		return nil, fmt.Errorf("nest: to is required with keys or prefix")
+	}
+	if strings.Contains(c.To.String(), "$attributes") || strings.Contains(c.To.String(), "$resource") {
+		return nil, fmt.Errorf("nest: to cannot be a resource or attribute")
+	}
+	nestOperator.To = *c.To
+
+	return []operator.Operator{nestOperator}, nil
+}
+
+// NestOperator is an operator that moves the keys of a map into nested maps
+type NestOperator struct {
+	helper.TransformerOperator
+	Field     entry.BodyField
+	To        entry.BodyField
+	Keys      []string
+	Prefix    string
+	Separator string
+}
+
+// Process will process an entry with a nest transformation.
+func (p *NestOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ProcessWith(ctx, entry, p.Transform)
+}
+
+// Transform will apply the nest operation to an entry
+func (p *NestOperator) Transform(e *entry.Entry) error {
+	val, ok := e.Get(p.Field)
+	if !ok {
+		return fmt.Errorf("apply nest: field %s does not exist on body", p.Field)
+	}
+
+	valMap, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("apply nest: field %s is not a map", p.Field)
+	}
+
+	if p.Separator != "" {
+		p.group(valMap)
+		return nil
+	}
+
+	// moved are the keys which are nested, by their names in the nested map
+	moved := map[string]string{}
+	for key := range valMap {
+		switch {
+		case len(p.Keys) > 0:
+			if contains(p.Keys, key) {
+				moved[key] = key
+			}
+		case strings.HasPrefix(key, p.Prefix) && len(key) > len(p.Prefix):
+			moved[key] = strings.TrimPrefix(key, p.Prefix)
+		}
+	}
+	if len(moved) == 0 {
+		return nil
+	}
+
+	// The target is checked before any key is moved, so that the entry is not partially modified
+	if existing, ok := e.Get(p.To); ok {
+		if _, ok := existing.(map[string]interface{}); !ok {
+			return fmt.Errorf("apply nest: field %s is not a map", p.To)
+		}
+	}
+
+	nested := make(map[string]interface{}, len(moved))
+	for key, name := range moved {
+		nested[name] = valMap[key]
+		delete(valMap, key)
+	}
+
+	// Setting a map merges it into an existing map
+	return e.Set(p.To, nested)
+}
+
+// group will nest each key of a map which contains the separator, by splitting it into the keys of nested maps.
+// Keys which would replace a value that is not a map are left as they are.
+func (p *NestOperator) group(m map[string]interface{}) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		if strings.Contains(key, p.Separator) {
+			keys = append(keys, key)
+		}
+	}
+	// Keys are grouped in order, so that conflicts are resolved consistently
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		parts := strings.Split(key, p.Separator)
+		if contains(parts, "") || !canNest(m, parts) {
+			continue
+		}
+
+		value := m[key]
+		delete(m, key)
+
+		current := m
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := current[part].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				current[part] = child
+			}
+			current = child
+		}
+		current[parts[len(parts)-1]] = value
+	}
+}
+
+// canNest will return whether a value can be nested at the keys of a path without replacing another value.
+func canNest(m map[string]interface{}, path []string) bool {
+	current := m
+	for _, part := range path[:len(path)-1] {
+		value, ok := current[part]
+		if !ok {
+			return true
+		}
+		child, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		current = child
+	}
+	_, exists := current[path[len(path)-1]]
+	return !exists
+}
+
+// contains will return whether a slice contains a string.
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,296 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func bodyField(keys ...string) *entry.BodyField {
+	return &entry.BodyField{Keys: keys}
+}
+
+func TestNestOperatorBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*NestOperatorConfig)
+		expectedErr string
+	}{
+		{
+			"Keys",
+			func(cfg *NestOperatorConfig) {
+				cfg.Keys = []string{"method"}
+				cfg.To = bodyField("http")
+			},
+			"",
+		},
+		{
+			"Prefix",
+			func(cfg *NestOperatorConfig) {
+				cfg.Prefix = "http_"
+				cfg.To = bodyField("http")
+			},
+			"",
+		},
+		{
+			"Separator",
+			func(cfg *NestOperatorConfig) { cfg.Separator = "_" },
+			"",
+		},
+		{
+			"NoMode",
+			func(cfg *NestOperatorConfig) {},
+			"exactly one of keys, prefix or separator must be set",
+		},
+		{
+			"TwoModes",
+			func(cfg *NestOperatorConfig) {
+				cfg.Prefix = "http_"
+				cfg.Separator = "_"
+			},
+			"exactly one of keys, prefix or separator must be set",
+		},
+		{
+			"MissingTo",
+			func(cfg *NestOperatorConfig) { cfg.Prefix = "http_" },
+			"to is required with keys or prefix",
+		},
+		{
+			"SeparatorWithTo",
+			func(cfg *NestOperatorConfig) {
+				cfg.Separator = "_"
+				cfg.To = bodyField("http")
+			},
+			"to cannot be used with separator",
+		},
+		{
+			"AttributesField",
+			func(cfg *NestOperatorConfig) {
+				cfg.Field = entry.BodyField{Keys: []string{"$attributes", "a"}}
+				cfg.Separator = "_"
+			},
+			"field cannot be a resource or attribute",
+		},
+		{
+			"ResourceTo",
+			func(cfg *NestOperatorConfig) {
+				cfg.Prefix = "http_"
+				cfg.To = bodyField("$resource", "http")
+			},
+			"to cannot be a resource or attribute",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewNestOperatorConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestNestOperatorTransform(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*NestOperatorConfig)
+		input       interface{}
+		expected    interface{}
+		expectedErr string
+	}{
+		{
+			"Keys",
+			func(cfg *NestOperatorConfig) {
+				cfg.Keys = []string{"method", "status", "missing"}
+				cfg.To = bodyField("http")
+			},
+			map[string]interface{}{"method": "GET", "status": 200, "message": "ok"},
+			map[string]interface{}{
+				"http":    map[string]interface{}{"method": "GET", "status": 200},
+				"message": "ok",
+			},
+			"",
+		},
+		{
+			"Prefix",
+			func(cfg *NestOperatorConfig) {
+				cfg.Prefix = "http_"
+				cfg.To = bodyField("http")
+			},
+			map[string]interface{}{"http_method": "GET", "http_status": 200, "http_": "empty", "message": "ok"},
+			map[string]interface{}{
+				"http":    map[string]interface{}{"method": "GET", "status": 200},
+				"http_":   "empty",
+				"message": "ok",
+			},
+			"",
+		},
+		{
+			"PrefixMergesIntoExisting",
+			func(cfg *NestOperatorConfig) {
+				cfg.Prefix = "http_"
+				cfg.To = bodyField("http")
+			},
+			map[string]interface{}{"http_method": "GET", "http": map[string]interface{}{"version": "1.1"}},
+			map[string]interface{}{
+				"http": map[string]interface{}{"method": "GET", "version": "1.1"},
+			},
+			"",
+		},
+		{
+			"NestedField",
+			func(cfg *NestOperatorConfig) {
+				cfg.Field = entry.BodyField{Keys: []string{"request"}}
+				cfg.Prefix = "http_"
+				cfg.To = bodyField("request", "http")
+			},
+			map[string]interface{}{"request": map[string]interface{}{"http_method": "GET"}},
+			map[string]interface{}{
+				"request": map[string]interface{}{"http": map[string]interface{}{"method": "GET"}},
+			},
+			"",
+		},
+		{
+			"NoMatches",
+			func(cfg *NestOperatorConfig) {
+				cfg.Prefix = "http_"
+				cfg.To = bodyField("http")
+			},
+			map[string]interface{}{"message": "ok"},
+			map[string]interface{}{"message": "ok"},
+			"",
+		},
+		{
+			"TargetNotMap",
+			func(cfg *NestOperatorConfig) {
+				cfg.Prefix = "http_"
+				cfg.To = bodyField("http")
+			},
+			map[string]interface{}{"http_method": "GET", "http": "1.1"},
+			map[string]interface{}{"http_method": "GET", "http": "1.1"},
+			"field http is not a map",
+		},
+		{
+			"Separator",
+			func(cfg *NestOperatorConfig) { cfg.Separator = "_" },
+			map[string]interface{}{
+				"http_method":          "GET",
+				"http_request_size":    10,
+				"http_response_status": 200,
+				"message":              "ok",
+			},
+			map[string]interface{}{
+				"http": map[string]interface{}{
+					"method":   "GET",
+					"request":  map[string]interface{}{"size": 10},
+					"response": map[string]interface{}{"status": 200},
+				},
+				"message": "ok",
+			},
+			"",
+		},
+		{
+			"SeparatorConflicts",
+			func(cfg *NestOperatorConfig) { cfg.Separator = "." },
+			map[string]interface{}{
+				"host":         "web-1",
+				"host.name":    "web-1",
+				"http":         map[string]interface{}{"version": "1.1"},
+				"http.method":  "GET",
+				"http.version": "2",
+				".hidden":      true,
+				"trailing.":    true,
+			},
+			map[string]interface{}{
+				"host":         "web-1",
+				"host.name":    "web-1",
+				"http":         map[string]interface{}{"version": "1.1", "method": "GET"},
+				"http.version": "2",
+				".hidden":      true,
+				"trailing.":    true,
+			},
+			"",
+		},
+		{
+			"NotMap",
+			func(cfg *NestOperatorConfig) { cfg.Separator = "_" },
+			"http_method",
+			"http_method",
+			"field $body is not a map",
+		},
+		{
+			"MissingField",
+			func(cfg *NestOperatorConfig) {
+				cfg.Field = entry.BodyField{Keys: []string{"request"}}
+				cfg.Separator = "_"
+			},
+			map[string]interface{}{"http_method": "GET"},
+			map[string]interface{}{"http_method": "GET"},
+			"field request does not exist on body",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewNestOperatorConfig("test")
+			tc.modify(cfg)
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0].(*NestOperator)
+
+			e := entry.New()
+			e.Body = tc.input
+			err = op.Transform(e)
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expected, e.Body)
+		})
+	}
+}
+
+func TestNestOperatorProcess(t *testing.T) {
+	cfg := NewNestOperatorConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Separator = "_"
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*NestOperator)
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = map[string]interface{}{"http_method": "GET"}
+	require.NoError(t, op.Process(context.Background(), e))
+	fake.ExpectBody(t, map[string]interface{}{
+		"http": map[string]interface{}{"method": "GET"},
+	})
+}
@@ -0,0 +1,184 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+)
+
+// Results of reserving a token for an entry
+const (
+	// reservedNow means the entry may be written now
+	reservedNow = iota
+
+	// reservedDelayed means the entry was added to the delayed entries of its key
+	reservedDelayed
+
+	// reservedNone means no token was reserved, and the entry must be dropped
+	reservedNone
+)
+
+// buckets are the token buckets of the keys of a rate limit, which evict the least recently used key when full.
+// A bucket which is in debt is not evicted, since its key would otherwise be given a new full bucket.
+type buckets struct {
+	mu    sync.Mutex
+	rate  float64
+	burst float64
+	size  int
+	items map[string]*list.Element
+	order *list.List
+}
+
+// bucket is the token bucket of a key. Its tokens are negative while entries are waiting for them.
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+
+	// delayed are the entries which are waiting for tokens, in the order their tokens were reserved
+	delayed []delayedEntry
+}
+
+// delayedEntry is an entry which may be written once its token is available at a time
+type delayedEntry struct {
+	entry *entry.Entry
+	at    time.Time
+}
+
+// newBuckets creates the token buckets of a number of keys, which are refilled at a rate per second up to a burst.
+func newBuckets(rate float64, burst, size int) *buckets {
+	return &buckets{
+		rate:  rate,
+		burst: float64(burst),
+		size:  size,
+		items: make(map[string]*list.Element, size),
+		order: list.New(),
+	}
+}
+
+// reserve will take a token from the bucket of a key for an entry. If the token is not available yet, the entry
+// is added to the delayed entries of the key, and the bucket is returned if the entry is the first of them, so
+// that the caller can start writing them. A token is not taken if the entry would wait longer than maxWait, or
+// if the key has no bucket and none can be evicted for it.
+func (b *buckets) reserve(key string, e *entry.Entry, now time.Time, maxWait time.Duration) (int, *bucket) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bk, ok := b.get(key, now)
+	if !ok {
+		return reservedNone, nil
+	}
+	bk.tokens = b.tokensAt(bk, now)
+	bk.last = now
+
+	var wait time.Duration
+	if bk.tokens < 1 {
+		wait = time.Duration((1 - bk.tokens) / b.rate * float64(time.Second))
+	}
+	if wait > maxWait {
+		return reservedNone, nil
+	}
+	bk.tokens--
+
+	// Entries are delayed behind those already waiting, so that the entries of a key stay in order
+	if wait <= 0 && len(bk.delayed) == 0 {
+		return reservedNow, nil
+	}
+	bk.delayed = append(bk.delayed, delayedEntry{entry: e, at: now.Add(wait)})
+	if len(bk.delayed) == 1 {
+		return reservedDelayed, bk
+	}
+	return reservedDelayed, nil
+}
+
+// next will return the first delayed entry of a bucket
+func (b *buckets) next(bk *bucket) (delayedEntry, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(bk.delayed) == 0 {
+		return delayedEntry{}, false
+	}
+	return bk.delayed[0], true
+}
+
+// pop will remove the first delayed entry of a bucket once it is written, and return the next one
+func (b *buckets) pop(bk *bucket) (delayedEntry, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	bk.delayed[0] = delayedEntry{}
+	bk.delayed = bk.delayed[1:]
+	if len(bk.delayed) == 0 {
+		bk.delayed = nil
+		return delayedEntry{}, false
+	}
+	return bk.delayed[0], true
+}
+
+// get will return the bucket of a key, and mark it as the most recently used. A new bucket is full, and evicts
+// the least recently used bucket which is not in debt if there are too many. If every bucket is in debt, there
+// is no bucket for the key.
+func (b *buckets) get(key string, now time.Time) (*bucket, bool) {
+	if element, ok := b.items[key]; ok {
+		b.order.MoveToFront(element)
+		return element.Value.(*bucket), true
+	}
+
+	if b.order.Len() >= b.size {
+		evicted := false
+		for element := b.order.Back(); element != nil; element = element.Prev() {
+			if bk := element.Value.(*bucket); !b.inDebt(bk, now) {
+				b.order.Remove(element)
+				delete(b.items, bk.key)
+				evicted = true
+				break
+			}
+		}
+		if !evicted {
+			return nil, false
+		}
+	}
+
+	bk := &bucket{key: key, tokens: b.burst, last: now}
+	b.items[key] = b.order.PushFront(bk)
+	return bk, true
+}
+
+// tokensAt will return the tokens of a bucket after it is refilled up to a time
+func (b *buckets) tokensAt(bk *bucket, now time.Time) float64 {
+	tokens := bk.tokens
+	if elapsed := now.Sub(bk.last); elapsed > 0 {
+		tokens += elapsed.Seconds() * b.rate
+		if tokens > b.burst {
+			tokens = b.burst
+		}
+	}
+	return tokens
+}
+
+// inDebt will return true if entries are waiting for the tokens of a bucket
+func (b *buckets) inDebt(bk *bucket, now time.Time) bool {
+	return len(bk.delayed) > 0 || b.tokensAt(bk, now) < 0
+}
+
+// len will return the number of keys which have a bucket.
+func (b *buckets) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.order.Len()
+}
@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+)
+
+func TestBucketsReserve(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	b := newBuckets(10, 2, 10)
+
+	// A new bucket is full
+	for i := 0; i < 2; i++ {
+		result, _ := b.reserve("a", entry.New(), start, 0)
+		require.Equal(t, reservedNow, result)
+	}
+
+	// An empty bucket is not reserved beyond the max wait
+	result, _ := b.reserve("a", entry.New(), start, 0)
+	require.Equal(t, reservedNone, result)
+
+	// Reservations wait for tokens in turn, and the bucket is returned for the first of them
+	result, bk := b.reserve("a", entry.New(), start, time.Second)
+	require.Equal(t, reservedDelayed, result)
+	require.NotNil(t, bk)
+	result, second := b.reserve("a", entry.New(), start, time.Second)
+	require.Equal(t, reservedDelayed, result)
+	require.Nil(t, second)
+	require.Len(t, bk.delayed, 2)
+	require.Equal(t, start.Add(100*time.Millisecond), bk.delayed[0].at)
+	require.Equal(t, start.Add(200*time.Millisecond), bk.delayed[1].at)
+
+	// An entry waits behind those which are delayed, even once its token is available
+	result, _ = b.reserve("a", entry.New(), start.Add(300*time.Millisecond), time.Second)
+	require.Equal(t, reservedDelayed, result)
+	require.Len(t, bk.delayed, 3)
+
+	// The delayed entries are written in order
+	for i := 0; i < 2; i++ {
+		_, ok := b.pop(bk)
+		require.True(t, ok)
+	}
+	_, ok := b.pop(bk)
+	require.False(t, ok)
+
+	// The bucket is not refilled beyond the burst
+	for i := 0; i < 2; i++ {
+		result, _ = b.reserve("a", entry.New(), start.Add(time.Hour), 0)
+		require.Equal(t, reservedNow, result)
+	}
+	result, _ = b.reserve("a", entry.New(), start.Add(time.Hour), 0)
+	require.Equal(t, reservedNone, result)
+
+	// Other keys have their own buckets
+	result, _ = b.reserve("b", entry.New(), start.Add(time.Hour), 0)
+	require.Equal(t, reservedNow, result)
+}
+
+func TestBucketsEviction(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	b := newBuckets(1, 1, 2)
+
+	result, _ := b.reserve("a", entry.New(), now, 0)
+	require.Equal(t, reservedNow, result)
+	result, _ = b.reserve("b", entry.New(), now, 0)
+	require.Equal(t, reservedNow, result)
+
+	// "b" is the least recently used key after "a" is used again
+	result, _ = b.reserve("a", entry.New(), now, 0)
+	require.Equal(t, reservedNone, result)
+	result, _ = b.reserve("c", entry.New(), now, 0)
+	require.Equal(t, reservedNow, result)
+	require.Equal(t, 2, b.len())
+
+	// "a" is still limited, and "b" was evicted, so it has a new full bucket
+	result, _ = b.reserve("a", entry.New(), now, 0)
+	require.Equal(t, reservedNone, result)
+	result, _ = b.reserve("b", entry.New(), now, 0)
+	require.Equal(t, reservedNow, result)
+}
+
+func TestBucketsEvictionInDebt(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	b := newBuckets(1, 1, 2)
+
+	// "a" is in debt while an entry waits for its token
+	for _, expected := range []int{reservedNow, reservedDelayed} {
+		result, _ := b.reserve("a", entry.New(), now, time.Minute)
+		require.Equal(t, expected, result)
+	}
+	result, _ := b.reserve("b", entry.New(), now, 0)
+	require.Equal(t, reservedNow, result)
+	result, _ = b.reserve("b", entry.New(), now, 0)
+	require.Equal(t, reservedNone, result)
+
+	// "b" is evicted rather than "a", although "a" is the least recently used key
+	result, _ = b.reserve("c", entry.New(), now, 0)
+	require.Equal(t, reservedNow, result)
+	result, _ = b.reserve("a", entry.New(), now, 0)
+	require.Equal(t, reservedNone, result)
+
+	// When every key is in debt, there is no bucket for a new key
+	result, _ = b.reserve("c", entry.New(), now, time.Minute)
+	require.Equal(t, reservedDelayed, result)
+	result, _ = b.reserve("d", entry.New(), now, time.Minute)
+	require.Equal(t, reservedNone, result)
+	require.Equal(t, 2, b.len())
+}
@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestRateLimitOperatorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "rate",
+			Expect: func() *RateLimitOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 100
+				cfg.Burst = 20
+				return cfg
+			}(),
+		},
+		{
+			Name: "key",
+			Expect: func() *RateLimitOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 10
+				cfg.Key = `$resource["k8s.pod.name"]`
+				cfg.MaxKeys = 500
+				return cfg
+			}(),
+		},
+		{
+			Name: "drop",
+			Expect: func() *RateLimitOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 10
+				cfg.Action = DropAction
+				return cfg
+			}(),
+		},
+		{
+			Name: "max_delay",
+			Expect: func() *RateLimitOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 10
+				cfg.MaxDelay = helper.NewDuration(30 * time.Second)
+				return cfg
+			}(),
+		},
+		{
+			Name: "overrides",
+			Expect: func() *RateLimitOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 10
+				cfg.Key = `$resource["k8s.pod.name"]`
+				cfg.Overrides = map[string]OverrideConfig{
+					"audit": {MaxDelay: helper.NewDuration(time.Minute)},
+					"debug": {Action: DropAction},
+				}
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *RateLimitOperatorConfig {
+	return NewRateLimitOperatorConfig("rate_limit")
+}
@@ -0,0 +1,272 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// Actions taken on entries which exceed the rate limit
+const (
+	// DelayAction holds entries until the rate limit allows them, up to the max delay
+	DelayAction = "delay"
+
+	// DropAction drops entries immediately
+	DropAction = "drop"
+)
+
+func init() {
+	operator.Register("rate_limit", func() operator.Builder { return NewRateLimitOperatorConfig("") })
+}
+
+// NewRateLimitOperatorConfig creates a new rate limit operator config with default values
+func NewRateLimitOperatorConfig(operatorID string) *RateLimitOperatorConfig {
+	return &RateLimitOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "rate_limit"),
+		MaxKeys:           10000,
+		Action:            DelayAction,
+		MaxDelay:          helper.NewDuration(10 * time.Second),
+	}
+}
+
+// RateLimitOperatorConfig is the configuration of a rate limit operator
+type RateLimitOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+	Rate                     float64                   `mapstructure:"rate"      json:"rate"                yaml:"rate"`
+	Burst                    int                       `mapstructure:"burst"     json:"burst"               yaml:"burst"`
+	Key                      string                    `mapstructure:"key"       json:"key,omitempty"       yaml:"key,omitempty"`
+	MaxKeys                  int                       `mapstructure:"max_keys"  json:"max_keys"            yaml:"max_keys"`
+	Action                   string                    `mapstructure:"action"    json:"action"              yaml:"action"`
+	MaxDelay                 helper.Duration           `mapstructure:"max_delay" json:"max_delay"           yaml:"max_delay"`
+	Overrides                map[string]OverrideConfig `mapstructure:"overrides" json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+// OverrideConfig is the action taken on the entries of a key which exceed the rate, in place of the default action.
+// Fields which are not set are those of the operator.
+type OverrideConfig struct {
+	Action   string          `mapstructure:"action"    json:"action,omitempty"    yaml:"action,omitempty"`
+	MaxDelay helper.Duration `mapstructure:"max_delay" json:"max_delay,omitempty" yaml:"max_delay,omitempty"`
+}
+
+// Build will build a rate limit operator from the supplied configuration
+func (c RateLimitOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformer, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Rate <= 0 {
+		return nil, fmt.Errorf("rate must be greater than 0")
+	}
+
+	if c.Burst < 0 {
+		return nil, fmt.Errorf("burst must not be negative")
+	}
+
+	if c.MaxKeys <= 0 {
+		return nil, fmt.Errorf("max_keys must be greater than 0")
+	}
+
+	maxDelay, err := maxDelayOf(c.Action, c.MaxDelay)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Overrides) > 0 && c.Key == "" {
+		return nil, fmt.Errorf("overrides can only be used with a key")
+	}
+
+	overrides := make(map[string]time.Duration, len(c.Overrides))
+	for value, override := range c.Overrides {
+		action := override.Action
+		if action == "" {
+			action = c.Action
+		}
+		delay := override.MaxDelay
+		if delay.Raw() == 0 {
+			delay = c.MaxDelay
+		}
+		overrides[value], err = maxDelayOf(action, delay)
+		if err != nil {
+			return nil, fmt.Errorf("override for key '%s': %s", value, err)
+		}
+	}
+
+	var key *vm.Program
+	if c.Key != "" {
+		key, err = expr.Compile(c.Key, expr.AllowUndefinedVariables())
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile key expression '%s': %w", c.Key, err)
+		}
+	}
+
+	// A burst of 0 allows as many entries at once as the rate allows per second
+	burst := c.Burst
+	if burst == 0 {
+		burst = int(math.Ceil(c.Rate))
+	}
+
+	rateLimit := &RateLimitOperator{
+		TransformerOperator: transformer,
+		key:                 key,
+		buckets:             newBuckets(c.Rate, burst, c.MaxKeys),
+		maxDelay:            maxDelay,
+		overrides:           overrides,
+		now:                 time.Now,
+		done:                make(chan struct{}),
+	}
+
+	return []operator.Operator{rateLimit}, nil
+}
+
+// maxDelayOf will return the longest an entry may be delayed with an action. Entries are never delayed when they are dropped.
+func maxDelayOf(action string, maxDelay helper.Duration) (time.Duration, error) {
+	switch action {
+	case DelayAction:
+		if maxDelay.Raw() <= 0 {
+			return 0, fmt.Errorf("max_delay must be greater than 0")
+		}
+		return maxDelay.Raw(), nil
+	case DropAction:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("invalid action '%s', must be '%s' or '%s'", action, DelayAction, DropAction)
+	}
+}
+
+// RateLimitOperator is an operator that limits the rate of entries, independently for each key
+type RateLimitOperator struct {
+	helper.TransformerOperator
+	key       *vm.Program
+	buckets   *buckets
+	maxDelay  time.Duration
+	overrides map[string]time.Duration
+	now       func() time.Time
+
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	dropped uint64
+}
+
+// Start will delay the entries which exceed the rate limit again, if the operator was stopped
+func (p *RateLimitOperator) Start(_ operator.Persister) error {
+	p.done = make(chan struct{})
+	p.stopOnce = sync.Once{}
+	return nil
+}
+
+// Stop will write the entries which are delayed immediately
+func (p *RateLimitOperator) Stop() error {
+	p.stopOnce.Do(func() { close(p.done) })
+	p.wg.Wait()
+	return nil
+}
+
+// Process will write an entry if the rate limit of its key allows it. Otherwise the entry is
+// delayed until the rate limit allows it, or dropped if it would be delayed for longer than the max delay.
+func (p *RateLimitOperator) Process(ctx context.Context, e *entry.Entry) error {
+	skip, err := p.Skip(ctx, e)
+	if err != nil {
+		return p.HandleEntryError(ctx, e, err)
+	}
+	if skip {
+		p.Write(ctx, e)
+		return nil
+	}
+
+	key, err := p.keyOf(e)
+	if err != nil {
+		return p.HandleEntryError(ctx, e, err)
+	}
+
+	maxDelay, ok := p.overrides[key]
+	if !ok {
+		maxDelay = p.maxDelay
+	}
+
+	result, bk := p.buckets.reserve(key, e, p.now(), maxDelay)
+	switch result {
+	case reservedNow:
+		p.Write(ctx, e)
+		return nil
+	case reservedNone:
+		atomic.AddUint64(&p.dropped, 1)
+		p.Debugw("Dropped entry which exceeds the rate limit", "key", key)
+		return nil
+	}
+
+	// The delayed entries of each key are written in order by a goroutine of their own,
+	// so that one key cannot hold up the entries of other keys
+	if bk != nil {
+		p.wg.Add(1)
+		go p.writeDelayed(bk)
+	}
+	return nil
+}
+
+// Dropped returns the number of entries dropped because they exceeded the rate limit
+func (p *RateLimitOperator) Dropped() uint64 {
+	return atomic.LoadUint64(&p.dropped)
+}
+
+// writeDelayed will write the delayed entries of a bucket as their tokens become available, until it has none
+func (p *RateLimitOperator) writeDelayed(bk *bucket) {
+	defer p.wg.Done()
+	for d, ok := p.buckets.next(bk); ok; d, ok = p.buckets.pop(bk) {
+		if wait := d.at.Sub(p.now()); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-p.done:
+				timer.Stop()
+			}
+		}
+		p.Write(context.Background(), d.entry)
+	}
+}
+
+// keyOf will return the rate limit key of an entry. Entries share a single key if no key expression is configured,
+// and entries for which the key expression is undefined share the empty key.
+func (p *RateLimitOperator) keyOf(e *entry.Entry) (string, error) {
+	if p.key == nil {
+		return "", nil
+	}
+
+	env := helper.GetExprEnv(e)
+	defer helper.PutExprEnv(env)
+
+	value, err := vm.Run(p.key, env)
+	if err != nil {
+		return "", fmt.Errorf("running key expression: %s", err)
+	}
+	if value == nil {
+		return "", nil
+	}
+	return fmt.Sprintf("%v", value), nil
+}
@@ -0,0 +1,342 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestRateLimitOperatorBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*RateLimitOperatorConfig)
+		expectedErr string
+	}{
+		{
+			"Default",
+			func(cfg *RateLimitOperatorConfig) { cfg.Rate = 10 },
+			"",
+		},
+		{
+			"Key",
+			func(cfg *RateLimitOperatorConfig) {
+				cfg.Rate = 10
+				cfg.Key = `$attributes.pod`
+			},
+			"",
+		},
+		{
+			"MissingRate",
+			func(cfg *RateLimitOperatorConfig) {},
+			"rate must be greater than 0",
+		},
+		{
+			"NegativeBurst",
+			func(cfg *RateLimitOperatorConfig) {
+				cfg.Rate = 10
+				cfg.Burst = -1
+			},
+			"burst must not be negative",
+		},
+		{
+			"ZeroMaxKeys",
+			func(cfg *RateLimitOperatorConfig) {
+				cfg.Rate = 10
+				cfg.MaxKeys = 0
+			},
+			"max_keys must be greater than 0",
+		},
+		{
+			"InvalidAction",
+			func(cfg *RateLimitOperatorConfig) {
+				cfg.Rate = 10
+				cfg.Action = "block"
+			},
+			"invalid action 'block'",
+		},
+		{
+			"ZeroMaxDelay",
+			func(cfg *RateLimitOperatorConfig) {
+				cfg.Rate = 10
+				cfg.MaxDelay = helper.NewDuration(0)
+			},
+			"max_delay must be greater than 0",
+		},
+		{
+			"ZeroMaxDelayDrop",
+			func(cfg *RateLimitOperatorConfig) {
+				cfg.Rate = 10
+				cfg.Action = DropAction
+				cfg.MaxDelay = helper.NewDuration(0)
+			},
+			"",
+		},
+		{
+			"Overrides",
+			func(cfg *RateLimitOperatorConfig) {
+				cfg.Rate = 10
+				cfg.Key = `$attributes.pod`
+				cfg.Overrides = map[string]OverrideConfig{"debug": {Action: DropAction}}
+			},
+			"",
+		},
+		{
+			"OverridesWithoutKey",
+			func(cfg *RateLimitOperatorConfig) {
+				cfg.Rate = 10
+				cfg.Overrides = map[string]OverrideConfig{"debug": {Action: DropAction}}
+			},
+			"overrides can only be used with a key",
+		},
+		{
+			"InvalidOverrideAction",
+			func(cfg *RateLimitOperatorConfig) {
+				cfg.Rate = 10
+				cfg.Key = `$attributes.pod`
+				cfg.Overrides = map[string]OverrideConfig{"debug": {Action: "block"}}
+			},
+			"override for key 'debug': invalid action 'block'",
+		},
+		{
+			"OverrideDelayWithoutMaxDelay",
+			func(cfg *RateLimitOperatorConfig) {
+				cfg.Rate = 10
+				cfg.Key = `$attributes.pod`
+				cfg.Action = DropAction
+				cfg.MaxDelay = helper.NewDuration(0)
+				cfg.Overrides = map[string]OverrideConfig{"audit": {Action: DelayAction}}
+			},
+			"override for key 'audit': max_delay must be greater than 0",
+		},
+		{
+			"InvalidKey",
+			func(cfg *RateLimitOperatorConfig) {
+				cfg.Rate = 10
+				cfg.Key = `$attributes.pod ==`
+			},
+			"failed to compile key expression",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRateLimitOperatorConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func newTestOperator(t *testing.T, cfg *RateLimitOperatorConfig) (*RateLimitOperator, *testutil.FakeOutput) {
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*RateLimitOperator)
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+	return op, fake
+}
+
+func entryWithPod(pod string) *entry.Entry {
+	e := entry.New()
+	if pod != "" {
+		e.AddAttribute("pod", pod)
+	}
+	e.Body = pod
+	return e
+}
+
+func TestRateLimitOperatorDrop(t *testing.T) {
+	cfg := NewRateLimitOperatorConfig("test")
+	cfg.Rate = 1
+	cfg.Burst = 2
+	cfg.Key = `$attributes.pod`
+	cfg.Action = DropAction
+	op, fake := newTestOperator(t, cfg)
+
+	now := time.Unix(1600000000, 0)
+	op.now = func() time.Time { return now }
+
+	// The noisy pod is limited to its burst, and does not affect the quiet pod
+	for i := 0; i < 5; i++ {
+		require.NoError(t, op.Process(context.Background(), entryWithPod("noisy")))
+	}
+	require.NoError(t, op.Process(context.Background(), entryWithPod("quiet")))
+	fake.ExpectBody(t, "noisy")
+	fake.ExpectBody(t, "noisy")
+	fake.ExpectBody(t, "quiet")
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+	require.Equal(t, uint64(3), op.Dropped())
+
+	// Entries without the key share the empty key
+	for i := 0; i < 3; i++ {
+		require.NoError(t, op.Process(context.Background(), entryWithPod("")))
+	}
+	fake.ExpectBody(t, "")
+	fake.ExpectBody(t, "")
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+	require.Equal(t, uint64(4), op.Dropped())
+
+	// The noisy pod is allowed another entry after its bucket is refilled
+	now = now.Add(time.Second)
+	require.NoError(t, op.Process(context.Background(), entryWithPod("noisy")))
+	require.NoError(t, op.Process(context.Background(), entryWithPod("noisy")))
+	fake.ExpectBody(t, "noisy")
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+	require.Equal(t, uint64(5), op.Dropped())
+}
+
+func TestRateLimitOperatorDelay(t *testing.T) {
+	cfg := NewRateLimitOperatorConfig("test")
+	cfg.Rate = 20
+	cfg.Burst = 1
+	cfg.Key = `$attributes.pod`
+	cfg.MaxDelay = helper.NewDuration(75 * time.Millisecond)
+	op, fake := newTestOperator(t, cfg)
+	defer func() { require.NoError(t, op.Stop()) }()
+
+	now := time.Unix(1600000000, 0)
+	op.now = func() time.Time { return now }
+
+	// The noisy pod's entries are delayed by 50ms each, up to the max delay, without holding up the quiet pod
+	for i := 0; i < 3; i++ {
+		require.NoError(t, op.Process(context.Background(), entryWithPod("noisy")))
+	}
+	require.NoError(t, op.Process(context.Background(), entryWithPod("quiet")))
+	fake.ExpectBody(t, "noisy")
+	fake.ExpectBody(t, "quiet")
+
+	select {
+	case e := <-fake.Received:
+		require.FailNow(t, "Received delayed entry early", e)
+	case <-time.After(20 * time.Millisecond):
+	}
+	fake.ExpectBody(t, "noisy")
+
+	// The third entry would have been delayed by 100ms, so it was dropped
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+	require.Equal(t, uint64(1), op.Dropped())
+}
+
+func TestRateLimitOperatorDelayOrder(t *testing.T) {
+	cfg := NewRateLimitOperatorConfig("test")
+	cfg.Rate = 200
+	cfg.Burst = 1
+	op, fake := newTestOperator(t, cfg)
+	defer func() { require.NoError(t, op.Stop()) }()
+
+	// Delayed entries are written in the order they were processed
+	for i := 0; i < 10; i++ {
+		e := entry.New()
+		e.Body = i
+		require.NoError(t, op.Process(context.Background(), e))
+	}
+	for i := 0; i < 10; i++ {
+		fake.ExpectBody(t, i)
+	}
+}
+
+func TestRateLimitOperatorOverrides(t *testing.T) {
+	cfg := NewRateLimitOperatorConfig("test")
+	cfg.Rate = 20
+	cfg.Burst = 1
+	cfg.Key = `$attributes.pod`
+	cfg.Action = DropAction
+	cfg.MaxDelay = helper.NewDuration(time.Second)
+	cfg.Overrides = map[string]OverrideConfig{"audit": {Action: DelayAction}}
+	op, fake := newTestOperator(t, cfg)
+	defer func() { require.NoError(t, op.Stop()) }()
+
+	now := time.Unix(1600000000, 0)
+	op.now = func() time.Time { return now }
+
+	// The entries of the audit pod are delayed, while those of other pods are dropped
+	for i := 0; i < 2; i++ {
+		require.NoError(t, op.Process(context.Background(), entryWithPod("audit")))
+		require.NoError(t, op.Process(context.Background(), entryWithPod("noisy")))
+	}
+	fake.ExpectBody(t, "audit")
+	fake.ExpectBody(t, "noisy")
+	fake.ExpectBody(t, "audit")
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+}
+
+func TestRateLimitOperatorStopWritesDelayed(t *testing.T) {
+	cfg := NewRateLimitOperatorConfig("test")
+	cfg.Rate = 0.1
+	cfg.Burst = 1
+	op, fake := newTestOperator(t, cfg)
+
+	require.NoError(t, op.Process(context.Background(), entryWithPod("a")))
+	require.NoError(t, op.Process(context.Background(), entryWithPod("b")))
+	fake.ExpectBody(t, "a")
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+
+	require.NoError(t, op.Stop())
+	fake.ExpectBody(t, "b")
+}
+
+func TestRateLimitOperatorRestart(t *testing.T) {
+	cfg := NewRateLimitOperatorConfig("test")
+	cfg.Rate = 0.1
+	cfg.Burst = 1
+	op, fake := newTestOperator(t, cfg)
+
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	require.NoError(t, op.Stop())
+
+	// Entries are delayed again once the operator is restarted
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	require.NoError(t, op.Process(context.Background(), entryWithPod("a")))
+	require.NoError(t, op.Process(context.Background(), entryWithPod("b")))
+	fake.ExpectBody(t, "a")
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+
+	require.NoError(t, op.Stop())
+	fake.ExpectBody(t, "b")
+}
+
+func TestRateLimitOperatorIf(t *testing.T) {
+	cfg := NewRateLimitOperatorConfig("test")
+	cfg.Rate = 1
+	cfg.Burst = 1
+	cfg.Action = DropAction
+	cfg.IfExpr = `$attributes.pod == "noisy"`
+	op, fake := newTestOperator(t, cfg)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, op.Process(context.Background(), entryWithPod("noisy")))
+		require.NoError(t, op.Process(context.Background(), entryWithPod("quiet")))
+	}
+	fake.ExpectBody(t, "noisy")
+	fake.ExpectBody(t, "quiet")
+	fake.ExpectBody(t, "quiet")
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+}
@@ -139,6 +139,16 @@ func (r *RecombineOperator) Stop() error {
 }
 
 func (r *RecombineOperator) Process(ctx context.Context, e *entry.Entry) error {
+	// Entries which do not match the "if" condition are passed through without being combined
+	skip, err := r.Skip(ctx, e)
+	if err != nil {
+		return r.HandleEntryError(ctx, e, err)
+	}
+	if skip {
+		r.Write(ctx, e)
+		return nil
+	}
+
 	// Lock the recombine operator because process can't run concurrently
 	r.Lock()
 	defer r.Unlock()
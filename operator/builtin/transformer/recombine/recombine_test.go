@@ -125,6 +125,26 @@ func TestRecombineOperator(t *testing.T) {
 				entryWithBody(t2, "test1\ntest2"),
 			},
 		},
+		{
+			"IfSkipsEntries",
+			func() *RecombineOperatorConfig {
+				cfg := NewRecombineOperatorConfig("")
+				cfg.CombineField = entry.NewBodyField()
+				cfg.IsLastEntry = "$body == 'test2'"
+				cfg.IfExpr = "$body != 'other'"
+				cfg.OutputIDs = []string{"fake"}
+				return cfg
+			}(),
+			[]*entry.Entry{
+				entryWithBody(t1, "test1"),
+				entryWithBody(t1, "other"),
+				entryWithBody(t2, "test2"),
+			},
+			[]*entry.Entry{
+				entryWithBody(t1, "other"),
+				entryWithBody(t1, "test1\ntest2"),
+			},
+		},
 	}
 
 	for _, tc := range cases {
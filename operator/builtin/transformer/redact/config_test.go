@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestRedactOperatorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "all",
+			Expect: func() *RedactOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Patterns = []string{EmailPattern, CreditCardPattern}
+				cfg.CustomPatterns = []CustomPatternConfig{{Name: "api_key", Regex: "key-[a-z0-9]{8}"}}
+				cfg.Strategy = HashStrategy
+				cfg.Include = []scopeField{
+					{Field: entry.NewBodyField("message")},
+					{allAttributes: true},
+				}
+				cfg.Exclude = []scopeField{
+					{Field: entry.NewAttributeField("user")},
+					{allResource: true},
+				}
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *RedactOperatorConfig {
+	return NewRedactOperatorConfig("redact")
+}
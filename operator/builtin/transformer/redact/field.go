@@ -0,0 +1,65 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact
+
+import (
+	"encoding/json"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+)
+
+// scopeField is a field which may also be `$attributes` or `$resource`,
+// referring to all of the attributes or resource values.
+type scopeField struct {
+	entry.Field
+	allAttributes bool
+	allResource   bool
+}
+
+// UnmarshalJSON will unmarshal a field from JSON
+func (f *scopeField) UnmarshalJSON(raw []byte) error {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return err
+	}
+	return f.unmarshalString(s)
+}
+
+// UnmarshalYAML will unmarshal a field from YAML
+func (f *scopeField) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return f.unmarshalString(s)
+}
+
+func (f *scopeField) unmarshalString(s string) error {
+	switch s {
+	case entry.AttributesPrefix:
+		*f = scopeField{allAttributes: true}
+		return nil
+	case entry.ResourcePrefix:
+		*f = scopeField{allResource: true}
+		return nil
+	}
+
+	field, err := entry.NewField(s)
+	if err != nil {
+		return err
+	}
+	*f = scopeField{Field: field}
+	return nil
+}
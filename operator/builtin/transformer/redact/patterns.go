@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	// EmailPattern matches email addresses
+	EmailPattern = "email"
+
+	// CreditCardPattern matches credit card numbers which pass the Luhn check
+	CreditCardPattern = "credit_card"
+
+	// SSNPattern matches US social security numbers
+	SSNPattern = "ssn"
+)
+
+// rule is a compiled pattern, with an optional check of each match.
+type rule struct {
+	name   string
+	regex  *regexp.Regexp
+	verify func(string) bool
+}
+
+// builtinRules are the built-in patterns
+var builtinRules = map[string]rule{
+	EmailPattern: {
+		name:  EmailPattern,
+		regex: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+	},
+	CreditCardPattern: {
+		name:   CreditCardPattern,
+		regex:  regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		verify: luhnValid,
+	},
+	SSNPattern: {
+		name:  SSNPattern,
+		regex: regexp.MustCompile(`\b(?:00[1-9]|0[1-9]\d|[1-578]\d{2}|6[0-57-9]\d|66[0-57-9])-(?:0[1-9]|[1-9]\d)-(?:000[1-9]|00[1-9]\d|0[1-9]\d{2}|[1-9]\d{3})\b`),
+	},
+}
+
+// CustomPatternConfig is the configuration of a user-defined pattern
+type CustomPatternConfig struct {
+	Name  string `mapstructure:"name"  json:"name"  yaml:"name"`
+	Regex string `mapstructure:"regex" json:"regex" yaml:"regex"`
+}
+
+// build will compile a user-defined pattern
+func (c CustomPatternConfig) build() (rule, error) {
+	if c.Name == "" {
+		return rule{}, fmt.Errorf("missing required field 'name'")
+	}
+	if c.Regex == "" {
+		return rule{}, fmt.Errorf("missing required field 'regex' of pattern '%s'", c.Name)
+	}
+	r, err := regexp.Compile(c.Regex)
+	if err != nil {
+		return rule{}, fmt.Errorf("compiling regex of pattern '%s': %s", c.Name, err)
+	}
+	return rule{name: c.Name, regex: r}, nil
+}
+
+// luhnValid will return true if the digits of a number pass the Luhn check.
+// Separators between the digits are ignored.
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
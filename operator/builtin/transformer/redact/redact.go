@@ -0,0 +1,262 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// MaskStrategy replaces each character of a match with an asterisk
+	MaskStrategy = "mask"
+
+	// HashStrategy replaces a match with the hex encoded SHA-256 hash of it
+	HashStrategy = "hash"
+
+	// RemoveStrategy removes a match
+	RemoveStrategy = "remove"
+)
+
+func init() {
+	operator.Register("redact", func() operator.Builder { return NewRedactOperatorConfig("") })
+}
+
+// NewRedactOperatorConfig creates a new redact operator config with default values
+func NewRedactOperatorConfig(operatorID string) *RedactOperatorConfig {
+	return &RedactOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "redact"),
+		Strategy:          MaskStrategy,
+	}
+}
+
+// RedactOperatorConfig is the configuration of a redact operator
+type RedactOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+	Patterns                 []string              `mapstructure:"patterns"        json:"patterns,omitempty"        yaml:"patterns,omitempty"`
+	CustomPatterns           []CustomPatternConfig `mapstructure:"custom_patterns" json:"custom_patterns,omitempty" yaml:"custom_patterns,omitempty"`
+	Strategy                 string                `mapstructure:"strategy"        json:"strategy"                  yaml:"strategy"`
+	Include                  []scopeField          `mapstructure:"include"         json:"include,omitempty"         yaml:"include,omitempty"`
+	Exclude                  []scopeField          `mapstructure:"exclude"         json:"exclude,omitempty"         yaml:"exclude,omitempty"`
+}
+
+// Build will build a redact operator from the supplied configuration
+func (c RedactOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformer, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Patterns) == 0 && len(c.CustomPatterns) == 0 {
+		return nil, fmt.Errorf("at least one of 'patterns' or 'custom_patterns' is required")
+	}
+
+	rules := make([]rule, 0, len(c.Patterns)+len(c.CustomPatterns))
+	for _, name := range c.Patterns {
+		r, ok := builtinRules[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown pattern '%s', must be one of %s, %s or %s", name, EmailPattern, CreditCardPattern, SSNPattern)
+		}
+		rules = append(rules, r)
+	}
+	for i, pc := range c.CustomPatterns {
+		r, err := pc.build()
+		if err != nil {
+			return nil, fmt.Errorf("custom_patterns[%d]: %s", i, err)
+		}
+		rules = append(rules, r)
+	}
+
+	var replace func(string) string
+	switch c.Strategy {
+	case MaskStrategy:
+		replace = mask
+	case HashStrategy:
+		replace = hash
+	case RemoveStrategy:
+		replace = func(string) string { return "" }
+	default:
+		return nil, fmt.Errorf("invalid strategy '%s', must be one of %s, %s or %s", c.Strategy, MaskStrategy, HashStrategy, RemoveStrategy)
+	}
+
+	redactOperator := &RedactOperator{
+		TransformerOperator: transformer,
+		rules:               rules,
+		replace:             replace,
+		include:             c.Include,
+		exclude:             c.Exclude,
+	}
+
+	return []operator.Operator{redactOperator}, nil
+}
+
+// RedactOperator is an operator that masks sensitive values within an entry
+type RedactOperator struct {
+	helper.TransformerOperator
+	rules   []rule
+	replace func(string) string
+	include []scopeField
+	exclude []scopeField
+}
+
+// Process will redact an entry
+func (r *RedactOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return r.ProcessWith(ctx, entry, r.Transform)
+}
+
+// Transform will redact the included fields of an entry, or its body, attributes and resource if none are included.
+// Excluded fields are not redacted.
+func (r *RedactOperator) Transform(e *entry.Entry) error {
+	excluded := make([]interface{}, len(r.exclude))
+	found := make([]bool, len(r.exclude))
+	for i, f := range r.exclude {
+		excluded[i], found[i] = f.get(e)
+	}
+
+	if len(r.include) == 0 {
+		e.Body = r.redactValue(e.Body)
+		e.Attributes = r.redactMap(e.Attributes)
+		e.Resource = r.redactMap(e.Resource)
+	}
+	for _, f := range r.include {
+		switch {
+		case f.allAttributes:
+			e.Attributes = r.redactMap(e.Attributes)
+		case f.allResource:
+			e.Resource = r.redactMap(e.Resource)
+		default:
+			value, ok := e.Get(f.Field)
+			if !ok {
+				continue
+			}
+			if err := e.Set(f.Field, r.redactValue(value)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, f := range r.exclude {
+		if !found[i] {
+			continue
+		}
+		if err := f.set(e, excluded[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// get will return the value of a field, which is a map for `$attributes` and `$resource`
+func (f scopeField) get(e *entry.Entry) (interface{}, bool) {
+	switch {
+	case f.allAttributes:
+		return e.Attributes, true
+	case f.allResource:
+		return e.Resource, true
+	default:
+		return e.Get(f.Field)
+	}
+}
+
+// set will set the value of a field, which is a map for `$attributes` and `$resource`
+func (f scopeField) set(e *entry.Entry, value interface{}) error {
+	switch {
+	case f.allAttributes:
+		e.Attributes = value.(map[string]string)
+		return nil
+	case f.allResource:
+		e.Resource = value.(map[string]string)
+		return nil
+	default:
+		return e.Set(f.Field, value)
+	}
+}
+
+// redactValue will return a copy of a value, with its strings redacted.
+// Maps and slices are copied, so that excluded values are not modified.
+func (r *RedactOperator) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.redactString(v)
+	case []byte:
+		return r.redactString(string(v))
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			redacted[k] = r.redactValue(val)
+		}
+		return redacted
+	case map[string]string:
+		return r.redactMap(v)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, val := range v {
+			redacted[i] = r.redactValue(val)
+		}
+		return redacted
+	case []string:
+		redacted := make([]string, len(v))
+		for i, val := range v {
+			redacted[i] = r.redactString(val)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// redactMap will return a copy of a map of strings, with its values redacted.
+func (r *RedactOperator) redactMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	redacted := make(map[string]string, len(m))
+	for k, v := range m {
+		redacted[k] = r.redactString(v)
+	}
+	return redacted
+}
+
+// redactString will replace the matches of each rule within a string.
+func (r *RedactOperator) redactString(s string) string {
+	for _, rule := range r.rules {
+		verify := rule.verify
+		s = rule.regex.ReplaceAllStringFunc(s, func(match string) string {
+			if verify != nil && !verify(match) {
+				return match
+			}
+			return r.replace(match)
+		})
+	}
+	return s
+}
+
+// mask will replace each character of a match with an asterisk.
+func mask(match string) string {
+	return strings.Repeat("*", len([]rune(match)))
+}
+
+// hash will replace a match with its hex encoded SHA-256 hash.
+func hash(match string) string {
+	sum := sha256.Sum256([]byte(match))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,297 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestOperator(t *testing.T, cfg *RedactOperatorConfig) *RedactOperator {
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*RedactOperator)
+	require.NoError(t, op.SetOutputs([]operator.Operator{testutil.NewFakeOutput(t)}))
+	return op
+}
+
+func TestRedactOperatorBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*RedactOperatorConfig)
+		expectedErr string
+	}{
+		{
+			"Valid",
+			func(cfg *RedactOperatorConfig) { cfg.Patterns = []string{EmailPattern} },
+			"",
+		},
+		{
+			"NoPatterns",
+			func(cfg *RedactOperatorConfig) {},
+			"at least one of 'patterns' or 'custom_patterns' is required",
+		},
+		{
+			"UnknownPattern",
+			func(cfg *RedactOperatorConfig) { cfg.Patterns = []string{"phone"} },
+			"unknown pattern 'phone'",
+		},
+		{
+			"CustomMissingName",
+			func(cfg *RedactOperatorConfig) { cfg.CustomPatterns = []CustomPatternConfig{{Regex: "a"}} },
+			"custom_patterns[0]: missing required field 'name'",
+		},
+		{
+			"CustomMissingRegex",
+			func(cfg *RedactOperatorConfig) { cfg.CustomPatterns = []CustomPatternConfig{{Name: "a"}} },
+			"missing required field 'regex' of pattern 'a'",
+		},
+		{
+			"CustomInvalidRegex",
+			func(cfg *RedactOperatorConfig) { cfg.CustomPatterns = []CustomPatternConfig{{Name: "a", Regex: "("}} },
+			"compiling regex of pattern 'a'",
+		},
+		{
+			"InvalidStrategy",
+			func(cfg *RedactOperatorConfig) {
+				cfg.Patterns = []string{EmailPattern}
+				cfg.Strategy = "encrypt"
+			},
+			"invalid strategy 'encrypt'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRedactOperatorConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestRedactPatterns(t *testing.T) {
+	cases := []struct {
+		name     string
+		pattern  string
+		input    string
+		expected string
+	}{
+		{"Email", EmailPattern, "login by jane.doe+test@mail.example.com failed", "login by ****************************** failed"},
+		{"EmailNotAddress", EmailPattern, "user@localhost", "user@localhost"},
+		{"CreditCard", CreditCardPattern, "card 4111111111111111 charged", "card **************** charged"},
+		{"CreditCardSeparated", CreditCardPattern, "card 4111-1111-1111-1111", "card *******************"},
+		{"CreditCardSpaces", CreditCardPattern, "card 5500 0000 0000 0004", "card *******************"},
+		{"CreditCardFailsLuhn", CreditCardPattern, "order 4111111111111112", "order 4111111111111112"},
+		{"CreditCardTooShort", CreditCardPattern, "id 411111111111", "id 411111111111"},
+		{"SSN", SSNPattern, "ssn=123-45-6789", "ssn=***********"},
+		{"SSNInvalidArea", SSNPattern, "ssn=000-45-6789", "ssn=000-45-6789"},
+		{"SSNInvalidArea666", SSNPattern, "ssn=666-45-6789", "ssn=666-45-6789"},
+		{"SSNInvalidArea9", SSNPattern, "ssn=900-45-6789", "ssn=900-45-6789"},
+		{"SSNDate", SSNPattern, "on 2021-06-01", "on 2021-06-01"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRedactOperatorConfig("test")
+			cfg.Patterns = []string{tc.pattern}
+			op := newTestOperator(t, cfg)
+			require.Equal(t, tc.expected, op.redactString(tc.input))
+		})
+	}
+}
+
+func TestRedactStrategies(t *testing.T) {
+	cases := []struct {
+		strategy string
+		expected string
+	}{
+		{MaskStrategy, "ssn ***********"},
+		{HashStrategy, "ssn 01a54629efb952287e554eb23ef69c52097a75aecc0e3a93ca0855ab6d7a31a0"},
+		{RemoveStrategy, "ssn "},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.strategy, func(t *testing.T) {
+			cfg := NewRedactOperatorConfig("test")
+			cfg.Patterns = []string{SSNPattern}
+			cfg.Strategy = tc.strategy
+			op := newTestOperator(t, cfg)
+			require.Equal(t, tc.expected, op.redactString("ssn 123-45-6789"))
+		})
+	}
+}
+
+func TestRedactCustomPattern(t *testing.T) {
+	cfg := NewRedactOperatorConfig("test")
+	cfg.CustomPatterns = []CustomPatternConfig{{Name: "api_key", Regex: `key-[a-z0-9]{8}`}}
+	op := newTestOperator(t, cfg)
+	require.Equal(t, "auth with ************", op.redactString("auth with key-ab12cd34"))
+}
+
+func TestRedactTransform(t *testing.T) {
+	newEntry := func() *entry.Entry {
+		e := entry.New()
+		e.Body = map[string]interface{}{
+			"message": "mail bob@example.com",
+			"user": map[string]interface{}{
+				"email": "bob@example.com",
+				"ids":   []interface{}{"bob@example.com", 42},
+			},
+		}
+		e.Attributes = map[string]string{"from": "alice@example.com", "to": "carol@example.com"}
+		e.Resource = map[string]string{"owner": "ops@example.com"}
+		return e
+	}
+
+	cases := []struct {
+		name       string
+		include    []scopeField
+		exclude    []scopeField
+		body       map[string]interface{}
+		attributes map[string]string
+		resource   map[string]string
+	}{
+		{
+			"All",
+			nil,
+			nil,
+			map[string]interface{}{
+				"message": "mail ***************",
+				"user": map[string]interface{}{
+					"email": "***************",
+					"ids":   []interface{}{"***************", 42},
+				},
+			},
+			map[string]string{"from": "*****************", "to": "*****************"},
+			map[string]string{"owner": "***************"},
+		},
+		{
+			"IncludeField",
+			[]scopeField{{Field: entry.NewBodyField("user")}},
+			nil,
+			map[string]interface{}{
+				"message": "mail bob@example.com",
+				"user": map[string]interface{}{
+					"email": "***************",
+					"ids":   []interface{}{"***************", 42},
+				},
+			},
+			map[string]string{"from": "alice@example.com", "to": "carol@example.com"},
+			map[string]string{"owner": "ops@example.com"},
+		},
+		{
+			"IncludeAttributes",
+			[]scopeField{{allAttributes: true}},
+			nil,
+			map[string]interface{}{
+				"message": "mail bob@example.com",
+				"user": map[string]interface{}{
+					"email": "bob@example.com",
+					"ids":   []interface{}{"bob@example.com", 42},
+				},
+			},
+			map[string]string{"from": "*****************", "to": "*****************"},
+			map[string]string{"owner": "ops@example.com"},
+		},
+		{
+			"Exclude",
+			nil,
+			[]scopeField{{Field: entry.NewBodyField("user")}, {Field: entry.NewAttributeField("from")}, {allResource: true}},
+			map[string]interface{}{
+				"message": "mail ***************",
+				"user": map[string]interface{}{
+					"email": "bob@example.com",
+					"ids":   []interface{}{"bob@example.com", 42},
+				},
+			},
+			map[string]string{"from": "alice@example.com", "to": "*****************"},
+			map[string]string{"owner": "ops@example.com"},
+		},
+		{
+			"IncludeAndExclude",
+			[]scopeField{{Field: entry.NewBodyField("user")}},
+			[]scopeField{{Field: entry.NewBodyField("user", "ids")}},
+			map[string]interface{}{
+				"message": "mail bob@example.com",
+				"user": map[string]interface{}{
+					"email": "***************",
+					"ids":   []interface{}{"bob@example.com", 42},
+				},
+			},
+			map[string]string{"from": "alice@example.com", "to": "carol@example.com"},
+			map[string]string{"owner": "ops@example.com"},
+		},
+		{
+			"IncludeMissing",
+			[]scopeField{{Field: entry.NewBodyField("missing")}},
+			[]scopeField{{Field: entry.NewBodyField("other")}},
+			map[string]interface{}{
+				"message": "mail bob@example.com",
+				"user": map[string]interface{}{
+					"email": "bob@example.com",
+					"ids":   []interface{}{"bob@example.com", 42},
+				},
+			},
+			map[string]string{"from": "alice@example.com", "to": "carol@example.com"},
+			map[string]string{"owner": "ops@example.com"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRedactOperatorConfig("test")
+			cfg.Patterns = []string{EmailPattern}
+			cfg.Include = tc.include
+			cfg.Exclude = tc.exclude
+			op := newTestOperator(t, cfg)
+
+			e := newEntry()
+			require.NoError(t, op.Transform(e))
+			require.Equal(t, tc.body, e.Body)
+			require.Equal(t, tc.attributes, e.Attributes)
+			require.Equal(t, tc.resource, e.Resource)
+		})
+	}
+}
+
+func TestRedactProcess(t *testing.T) {
+	cfg := NewRedactOperatorConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Patterns = []string{CreditCardPattern}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*RedactOperator)
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = "paid with 4111111111111111"
+	require.NoError(t, op.Process(context.Background(), e))
+	fake.ExpectBody(t, "paid with ****************")
+}
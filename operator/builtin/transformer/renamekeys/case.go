@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renamekeys
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Case transforms
+const (
+	SnakeCase  = "snake"
+	CamelCase  = "camel"
+	PascalCase = "pascal"
+	KebabCase  = "kebab"
+	LowerCase  = "lower"
+	UpperCase  = "upper"
+)
+
+// caseTransforms are the functions which transform the case of a key
+var caseTransforms = map[string]func(string) string{
+	SnakeCase: func(s string) string {
+		return strings.Join(mapWords(s, strings.ToLower), "_")
+	},
+	CamelCase: func(s string) string {
+		words := mapWords(s, title)
+		if len(words) > 0 {
+			words[0] = strings.ToLower(words[0])
+		}
+		return strings.Join(words, "")
+	},
+	PascalCase: func(s string) string {
+		return strings.Join(mapWords(s, title), "")
+	},
+	KebabCase: func(s string) string {
+		return strings.Join(mapWords(s, strings.ToLower), "-")
+	},
+	LowerCase: strings.ToLower,
+	UpperCase: strings.ToUpper,
+}
+
+// mapWords will split a key into words, and apply a function to each word.
+func mapWords(s string, f func(string) string) []string {
+	words := splitWords(s)
+	for i, word := range words {
+		words[i] = f(word)
+	}
+	return words
+}
+
+// title will capitalize the first letter of a word, and lower the rest.
+func title(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// splitWords will split a key into words at underscores, hyphens and spaces, and at changes of case,
+// so that "HTTPStatusCode", "http_status_code" and "http-status-code" have the same words.
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := 0
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '_' || r == '-' || r == ' ' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+
+		prev := runes[i-1]
+		nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		// A word starts at an upper case letter which follows a lower case letter or digit,
+		// or which is the last letter of an acronym followed by a lower case letter
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renamekeys
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitWords(t *testing.T) {
+	cases := map[string][]string{
+		"http_status_code": {"http", "status", "code"},
+		"http-status-code": {"http", "status", "code"},
+		"httpStatusCode":   {"http", "Status", "Code"},
+		"HTTPStatusCode":   {"HTTP", "Status", "Code"},
+		"userID":           {"user", "ID"},
+		"ipv4Address":      {"ipv4", "Address"},
+		"__leading":        {"leading"},
+		"request.method":   {"request.method"},
+		"single":           {"single"},
+		"":                 nil,
+	}
+
+	for input, expected := range cases {
+		t.Run(input, func(t *testing.T) {
+			require.Equal(t, expected, splitWords(input))
+		})
+	}
+}
+
+func TestCaseTransforms(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected map[string]string
+	}{
+		{
+			"http_status_code",
+			map[string]string{
+				SnakeCase:  "http_status_code",
+				CamelCase:  "httpStatusCode",
+				PascalCase: "HttpStatusCode",
+				KebabCase:  "http-status-code",
+				LowerCase:  "http_status_code",
+				UpperCase:  "HTTP_STATUS_CODE",
+			},
+		},
+		{
+			"HTTPStatusCode",
+			map[string]string{
+				SnakeCase:  "http_status_code",
+				CamelCase:  "httpStatusCode",
+				PascalCase: "HttpStatusCode",
+				KebabCase:  "http-status-code",
+				LowerCase:  "httpstatuscode",
+				UpperCase:  "HTTPSTATUSCODE",
+			},
+		},
+		{
+			"__",
+			map[string]string{
+				SnakeCase:  "",
+				CamelCase:  "",
+				PascalCase: "",
+				KebabCase:  "",
+				LowerCase:  "__",
+				UpperCase:  "__",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		for name, expected := range tc.expected {
+			t.Run(tc.input+"/"+name, func(t *testing.T) {
+				require.Equal(t, expected, caseTransforms[name](tc.input))
+			})
+		}
+	}
+}
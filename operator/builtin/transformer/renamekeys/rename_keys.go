@@ -0,0 +1,211 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renamekeys
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("rename_keys", func() operator.Builder { return NewRenameKeysOperatorConfig("") })
+}
+
+// NewRenameKeysOperatorConfig creates a new rename keys operator config with default values
+func NewRenameKeysOperatorConfig(operatorID string) *RenameKeysOperatorConfig {
+	return &RenameKeysOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "rename_keys"),
+		Field:             entry.BodyField{Keys: []string{}},
+		Recursive:         true,
+	}
+}
+
+// RenameKeysOperatorConfig is the configuration of a rename keys operator
+type RenameKeysOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+	Pattern                  string          `mapstructure:"pattern"     json:"pattern,omitempty"     yaml:"pattern,omitempty"`
+	Replacement              string          `mapstructure:"replacement" json:"replacement,omitempty" yaml:"replacement,omitempty"`
+	Case                     string          `mapstructure:"case"        json:"case,omitempty"        yaml:"case,omitempty"`
+	Field                    entry.BodyField `mapstructure:"field"       json:"field"                 yaml:"field"`
+	Recursive                bool            `mapstructure:"recursive"   json:"recursive"             yaml:"recursive"`
+	Attributes               bool            `mapstructure:"attributes"  json:"attributes,omitempty"  yaml:"attributes,omitempty"`
+	Resource                 bool            `mapstructure:"resource"    json:"resource,omitempty"    yaml:"resource,omitempty"`
+}
+
+// Build will build a rename keys operator from the supplied configuration
+func (c RenameKeysOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(c.Field.String(), "$attributes") || strings.Contains(c.Field.String(), "$resource") {
+		return nil, fmt.Errorf("rename_keys: field cannot be a resource or attribute. Use 'attributes' or 'resource' instead")
+	}
+
+	if c.Pattern == "" && c.Case == "" {
+		return nil, fmt.Errorf("rename_keys: at least one of pattern or case is required")
+	}
+
+	renameKeysOperator := &RenameKeysOperator{
+		TransformerOperator: transformerOperator,
+		field:               c.Field,
+		recursive:           c.Recursive,
+		attributes:          c.Attributes,
+		resource:            c.Resource,
+		replacement:         c.Replacement,
+	}
+
+	if c.Pattern != "" {
+		renameKeysOperator.pattern, err = regexp.Compile(c.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rename_keys: compiling pattern: %s", err)
+		}
+	} else if c.Replacement != "" {
+		return nil, fmt.Errorf("rename_keys: replacement can only be used with pattern")
+	}
+
+	if c.Case != "" {
+		transform, ok := caseTransforms[c.Case]
+		if !ok {
+			return nil, fmt.Errorf("rename_keys: invalid case '%s'", c.Case)
+		}
+		renameKeysOperator.transformCase = transform
+	}
+
+	return []operator.Operator{renameKeysOperator}, nil
+}
+
+// RenameKeysOperator is an operator that renames keys with a regular expression or a case transform
+type RenameKeysOperator struct {
+	helper.TransformerOperator
+	field         entry.BodyField
+	recursive     bool
+	attributes    bool
+	resource      bool
+	pattern       *regexp.Regexp
+	replacement   string
+	transformCase func(string) string
+}
+
+// Process will process an entry with a rename keys transformation.
+func (p *RenameKeysOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ProcessWith(ctx, entry, p.Transform)
+}
+
+// Transform will rename the keys of the field, and of the attributes and resource if configured.
+// A field which is missing or is not a map is ignored.
+func (p *RenameKeysOperator) Transform(e *entry.Entry) error {
+	if value, ok := e.Get(p.field); ok {
+		p.renameValue(value)
+	}
+
+	if p.attributes {
+		renameStringKeys(e.Attributes, p.rename)
+	}
+
+	if p.resource {
+		renameStringKeys(e.Resource, p.rename)
+	}
+	return nil
+}
+
+// renameValue will rename the keys of a map, and of the maps nested in it if recursive.
+func (p *RenameKeysOperator) renameValue(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if p.recursive {
+			for _, child := range v {
+				p.renameValue(child)
+			}
+		}
+		renameKeys(v, p.rename)
+	case []interface{}:
+		if p.recursive {
+			for _, item := range v {
+				p.renameValue(item)
+			}
+		}
+	}
+}
+
+// rename will return the new name of a key.
+func (p *RenameKeysOperator) rename(key string) string {
+	if p.pattern != nil {
+		key = p.pattern.ReplaceAllString(key, p.replacement)
+	}
+	if p.transformCase != nil {
+		key = p.transformCase(key)
+	}
+	return key
+}
+
+// renameKeys will rename the keys of a map.
+func renameKeys(m map[string]interface{}, rename func(string) string) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	for _, r := range renames(keys, rename) {
+		m[r.to] = m[r.from]
+		delete(m, r.from)
+	}
+}
+
+// renameStringKeys will rename the keys of a map of strings, such as the attributes or resource of an entry.
+func renameStringKeys(m map[string]string, rename func(string) string) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	for _, r := range renames(keys, rename) {
+		m[r.to] = m[r.from]
+		delete(m, r.from)
+	}
+}
+
+// keyRename is the renaming of a key
+type keyRename struct {
+	from, to string
+}
+
+// renames will return the renames of a set of keys. A key is not renamed if its new name is empty,
+// is an existing key, or is the new name of a key which precedes it in order, so that no value is replaced.
+// As new names are never existing keys, the renames may be applied in any order.
+func renames(keys []string, rename func(string) string) []keyRename {
+	sort.Strings(keys)
+	taken := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		taken[key] = true
+	}
+
+	var result []keyRename
+	for _, key := range keys {
+		to := rename(key)
+		if to == key || to == "" || taken[to] {
+			continue
+		}
+		taken[to] = true
+		result = append(result, keyRename{from: key, to: to})
+	}
+	return result
+}
@@ -0,0 +1,300 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renamekeys
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestRenameKeysOperatorBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*RenameKeysOperatorConfig)
+		expectedErr string
+	}{
+		{
+			"Pattern",
+			func(cfg *RenameKeysOperatorConfig) { cfg.Pattern = "^a" },
+			"",
+		},
+		{
+			"Case",
+			func(cfg *RenameKeysOperatorConfig) { cfg.Case = SnakeCase },
+			"",
+		},
+		{
+			"Neither",
+			func(cfg *RenameKeysOperatorConfig) {},
+			"at least one of pattern or case is required",
+		},
+		{
+			"InvalidPattern",
+			func(cfg *RenameKeysOperatorConfig) { cfg.Pattern = "(" },
+			"compiling pattern",
+		},
+		{
+			"ReplacementWithoutPattern",
+			func(cfg *RenameKeysOperatorConfig) {
+				cfg.Case = SnakeCase
+				cfg.Replacement = "b"
+			},
+			"replacement can only be used with pattern",
+		},
+		{
+			"InvalidCase",
+			func(cfg *RenameKeysOperatorConfig) { cfg.Case = "title" },
+			"invalid case 'title'",
+		},
+		{
+			"AttributesField",
+			func(cfg *RenameKeysOperatorConfig) {
+				cfg.Case = SnakeCase
+				cfg.Field = entry.BodyField{Keys: []string{"$attributes", "a"}}
+			},
+			"field cannot be a resource or attribute",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRenameKeysOperatorConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestRenameKeysOperatorTransform(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*RenameKeysOperatorConfig)
+		input    func() *entry.Entry
+		expected func() *entry.Entry
+	}{
+		{
+			"PatternRecursive",
+			func(cfg *RenameKeysOperatorConfig) {
+				cfg.Pattern = `^http\.(.*)$`
+				cfg.Replacement = "http_$1"
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{
+					"http.method": "GET",
+					"request": map[string]interface{}{
+						"http.status": 200,
+						"spans": []interface{}{
+							map[string]interface{}{"http.url": "/cart"},
+						},
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{
+					"http_method": "GET",
+					"request": map[string]interface{}{
+						"http_status": 200,
+						"spans": []interface{}{
+							map[string]interface{}{"http_url": "/cart"},
+						},
+					},
+				}
+				return e
+			},
+		},
+		{
+			"NotRecursive",
+			func(cfg *RenameKeysOperatorConfig) {
+				cfg.Case = SnakeCase
+				cfg.Recursive = false
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{
+					"userName": "a",
+					"httpRequest": map[string]interface{}{
+						"statusCode": 200,
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{
+					"user_name": "a",
+					"http_request": map[string]interface{}{
+						"statusCode": 200,
+					},
+				}
+				return e
+			},
+		},
+		{
+			"NestedField",
+			func(cfg *RenameKeysOperatorConfig) {
+				cfg.Case = CamelCase
+				cfg.Field = entry.BodyField{Keys: []string{"request"}}
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{
+					"user_name": "a",
+					"request": map[string]interface{}{
+						"status_code": 200,
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{
+					"user_name": "a",
+					"request": map[string]interface{}{
+						"statusCode": 200,
+					},
+				}
+				return e
+			},
+		},
+		{
+			"PatternThenCase",
+			func(cfg *RenameKeysOperatorConfig) {
+				cfg.Pattern = `^x_`
+				cfg.Case = PascalCase
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{"x_request_id": "1"}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{"RequestId": "1"}
+				return e
+			},
+		},
+		{
+			"Conflicts",
+			func(cfg *RenameKeysOperatorConfig) { cfg.Case = SnakeCase },
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{
+					"user_id":   "existing",
+					"userId":    "conflicts with an existing key",
+					"UserID":    "conflicts with an existing key",
+					"hostName":  "first",
+					"host-name": "second",
+					"__":        "empty",
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{
+					"user_id":   "existing",
+					"userId":    "conflicts with an existing key",
+					"UserID":    "conflicts with an existing key",
+					"host_name": "second",
+					"hostName":  "first",
+					"__":        "empty",
+				}
+				return e
+			},
+		},
+		{
+			"AttributesAndResource",
+			func(cfg *RenameKeysOperatorConfig) {
+				cfg.Case = KebabCase
+				cfg.Attributes = true
+				cfg.Resource = true
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = "message"
+				e.Attributes = map[string]string{"log_level": "info"}
+				e.Resource = map[string]string{"hostName": "web-1"}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = "message"
+				e.Attributes = map[string]string{"log-level": "info"}
+				e.Resource = map[string]string{"host-name": "web-1"}
+				return e
+			},
+		},
+		{
+			"AttributesNotConfigured",
+			func(cfg *RenameKeysOperatorConfig) { cfg.Case = KebabCase },
+			func() *entry.Entry {
+				e := entry.New()
+				e.Attributes = map[string]string{"log_level": "info"}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Attributes = map[string]string{"log_level": "info"}
+				return e
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRenameKeysOperatorConfig("test")
+			tc.modify(cfg)
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0].(*RenameKeysOperator)
+
+			e := tc.input()
+			expected := tc.expected()
+			expected.Timestamp = e.Timestamp
+			require.NoError(t, op.Transform(e))
+			require.Equal(t, expected, e)
+		})
+	}
+}
+
+func TestRenameKeysOperatorProcess(t *testing.T) {
+	cfg := NewRenameKeysOperatorConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Case = SnakeCase
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*RenameKeysOperator)
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = map[string]interface{}{"statusCode": 200}
+	require.NoError(t, op.Process(context.Background(), e))
+	fake.ExpectBody(t, map[string]interface{}{"status_code": 200})
+}
@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestSamplingOperatorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "rate",
+			Expect: func() *SamplingOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 0.05
+				return cfg
+			}(),
+		},
+		{
+			Name: "hash_field",
+			Expect: func() *SamplingOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 0.1
+				field := entry.NewBodyField("trace_id")
+				cfg.HashField = &field
+				return cfg
+			}(),
+		},
+		{
+			Name: "hash_on",
+			Expect: func() *SamplingOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 0.1
+				cfg.HashOn = "trace_id"
+				return cfg
+			}(),
+		},
+		{
+			Name: "bypass",
+			Expect: func() *SamplingOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 0.05
+				cfg.Bypass = []string{`$body.level == "error"`, `$attributes.keep == "true"`}
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *SamplingOperatorConfig {
+	return NewSamplingOperatorConfig("sampling")
+}
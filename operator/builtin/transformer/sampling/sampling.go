@@ -0,0 +1,197 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// HashOnTraceID is the hash_on value which hashes the trace ID of an entry
+const HashOnTraceID = "trace_id"
+
+func init() {
+	operator.Register("sampling", func() operator.Builder { return NewSamplingOperatorConfig("") })
+}
+
+// NewSamplingOperatorConfig creates a sampling operator config with default values
+func NewSamplingOperatorConfig(operatorID string) *SamplingOperatorConfig {
+	return &SamplingOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "sampling"),
+		Rate:              1,
+	}
+}
+
+// SamplingOperatorConfig is the configuration of a sampling operator
+type SamplingOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+	Rate                     float64      `mapstructure:"rate"       json:"rate"                 yaml:"rate"`
+	HashField                *entry.Field `mapstructure:"hash_field" json:"hash_field,omitempty" yaml:"hash_field,omitempty"`
+	HashOn                   string       `mapstructure:"hash_on"    json:"hash_on,omitempty"    yaml:"hash_on,omitempty"`
+	Bypass                   []string     `mapstructure:"bypass"     json:"bypass,omitempty"     yaml:"bypass,omitempty"`
+}
+
+// Build will build a sampling operator from the supplied configuration
+func (c SamplingOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformer, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Rate < 0.0 || c.Rate > 1.0 {
+		return nil, fmt.Errorf("rate must be a number between 0 and 1")
+	}
+
+	switch c.HashOn {
+	case "":
+	case HashOnTraceID:
+		if c.HashField != nil {
+			return nil, fmt.Errorf("hash_on cannot be used with hash_field")
+		}
+	default:
+		return nil, fmt.Errorf("invalid hash_on '%s', must be '%s'", c.HashOn, HashOnTraceID)
+	}
+
+	bypass := make([]*vm.Program, 0, len(c.Bypass))
+	for _, e := range c.Bypass {
+		compiled, err := expr.Compile(e, expr.AsBool(), expr.AllowUndefinedVariables())
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile bypass expression '%s': %w", e, err)
+		}
+		bypass = append(bypass, compiled)
+	}
+
+	samplingOperator := &SamplingOperator{
+		TransformerOperator: transformer,
+		rate:                c.Rate,
+		threshold:           hashThreshold(c.Rate),
+		hashField:           c.HashField,
+		hashTraceID:         c.HashOn == HashOnTraceID,
+		bypass:              bypass,
+		random:              rand.Float64,
+	}
+
+	return []operator.Operator{samplingOperator}, nil
+}
+
+// hashThreshold will return the value below which the hash of an entry is kept at a rate.
+func hashThreshold(rate float64) uint64 {
+	if rate >= 1 {
+		return math.MaxUint64
+	}
+	return uint64(rate * math.MaxUint64)
+}
+
+// SamplingOperator is an operator that keeps a proportion of entries
+type SamplingOperator struct {
+	helper.TransformerOperator
+	rate        float64
+	threshold   uint64
+	hashField   *entry.Field
+	hashTraceID bool
+	bypass      []*vm.Program
+	random      func() float64
+}
+
+// Process will write an entry if it matches a bypass expression or it is sampled, and drop it otherwise
+func (s *SamplingOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	skip, err := s.Skip(ctx, entry)
+	if err != nil {
+		return s.HandleEntryError(ctx, entry, err)
+	}
+
+	if skip || s.bypassed(entry) || s.sampled(entry) {
+		s.Write(ctx, entry)
+	}
+	return nil
+}
+
+// bypassed will return true if an entry matches any bypass expression.
+func (s *SamplingOperator) bypassed(entry *entry.Entry) bool {
+	if len(s.bypass) == 0 {
+		return false
+	}
+
+	env := helper.GetExprEnv(entry)
+	defer helper.PutExprEnv(env)
+
+	for _, program := range s.bypass {
+		matches, err := vm.Run(program, env)
+		if err != nil {
+			s.Errorw("Running bypass expression returned an error", zap.Error(err))
+			continue
+		}
+		if matches.(bool) {
+			return true
+		}
+	}
+	return false
+}
+
+// sampled will return true if an entry should be kept. When hash_field is configured and present,
+// or hash_on is trace_id and the entry has a trace ID, the decision is derived from the hash of
+// its value, so that entries with the same value are either all kept or all dropped. Otherwise,
+// the decision is random.
+func (s *SamplingOperator) sampled(entry *entry.Entry) bool {
+	if s.hashTraceID && len(entry.TraceId) > 0 {
+		return s.sampledHash(entry.TraceId)
+	}
+	if s.hashField != nil {
+		if value, ok := entry.Get(s.hashField); ok {
+			return s.sampledHash(value)
+		}
+	}
+	return s.random() < s.rate
+}
+
+// sampledHash will return true if the hash of a value is within the sampling rate.
+func (s *SamplingOperator) sampledHash(value interface{}) bool {
+	if s.threshold == math.MaxUint64 {
+		return true
+	}
+
+	h := fnv.New64a()
+	switch v := value.(type) {
+	case string:
+		_, _ = h.Write([]byte(v))
+	case []byte:
+		_, _ = h.Write(v)
+	default:
+		_, _ = fmt.Fprint(h, v)
+	}
+	return mix(h.Sum64()) < s.threshold
+}
+
+// mix will spread the bits of a hash, as the high bits of an FNV hash of similar values,
+// such as sequential identifiers, are not evenly distributed.
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -0,0 +1,276 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestOperator(t *testing.T, cfg *SamplingOperatorConfig) (*SamplingOperator, *testutil.FakeOutput) {
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*SamplingOperator)
+
+	fakeOutput := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fakeOutput}))
+	return op, fakeOutput
+}
+
+func TestSamplingOperatorBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*SamplingOperatorConfig)
+		expectedErr string
+	}{
+		{
+			"Default",
+			func(cfg *SamplingOperatorConfig) {},
+			"",
+		},
+		{
+			"NegativeRate",
+			func(cfg *SamplingOperatorConfig) { cfg.Rate = -0.1 },
+			"rate must be a number between 0 and 1",
+		},
+		{
+			"RateAboveOne",
+			func(cfg *SamplingOperatorConfig) { cfg.Rate = 1.5 },
+			"rate must be a number between 0 and 1",
+		},
+		{
+			"HashOnTraceID",
+			func(cfg *SamplingOperatorConfig) { cfg.HashOn = "trace_id" },
+			"",
+		},
+		{
+			"InvalidHashOn",
+			func(cfg *SamplingOperatorConfig) { cfg.HashOn = "span_id" },
+			"invalid hash_on 'span_id'",
+		},
+		{
+			"HashOnWithHashField",
+			func(cfg *SamplingOperatorConfig) {
+				field := entry.NewBodyField("trace_id")
+				cfg.HashField = &field
+				cfg.HashOn = "trace_id"
+			},
+			"hash_on cannot be used with hash_field",
+		},
+		{
+			"InvalidBypass",
+			func(cfg *SamplingOperatorConfig) { cfg.Bypass = []string{"severity >="} },
+			"failed to compile bypass expression",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewSamplingOperatorConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestSamplingOperatorRandom(t *testing.T) {
+	cases := []struct {
+		name   string
+		rate   float64
+		random float64
+		kept   bool
+	}{
+		{"RateZero", 0, 0, false},
+		{"RateOne", 1, 0.999, true},
+		{"BelowRate", 0.05, 0.04, true},
+		{"AboveRate", 0.05, 0.06, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewSamplingOperatorConfig("test")
+			cfg.Rate = tc.rate
+			op, fake := newTestOperator(t, cfg)
+			op.random = func() float64 { return tc.random }
+
+			require.NoError(t, op.Process(context.Background(), entry.New()))
+			if tc.kept {
+				fake.ExpectBody(t, nil)
+			} else {
+				fake.ExpectNoEntry(t, 0)
+			}
+		})
+	}
+}
+
+func TestSamplingOperatorBypass(t *testing.T) {
+	cfg := NewSamplingOperatorConfig("test")
+	cfg.Rate = 0
+	cfg.Bypass = []string{`$body.level == "error"`, `$attributes.keep == "true"`}
+	op, fake := newTestOperator(t, cfg)
+
+	errorEntry := entry.New()
+	errorEntry.Body = map[string]interface{}{"level": "error"}
+	require.NoError(t, op.Process(context.Background(), errorEntry))
+	fake.ExpectBody(t, map[string]interface{}{"level": "error"})
+
+	keepEntry := entry.New()
+	keepEntry.Attributes = map[string]string{"keep": "true"}
+	keepEntry.Body = "keep"
+	require.NoError(t, op.Process(context.Background(), keepEntry))
+	fake.ExpectBody(t, "keep")
+
+	debugEntry := entry.New()
+	debugEntry.Body = map[string]interface{}{"level": "debug"}
+	require.NoError(t, op.Process(context.Background(), debugEntry))
+	fake.ExpectNoEntry(t, 0)
+}
+
+func TestSamplingOperatorIf(t *testing.T) {
+	cfg := NewSamplingOperatorConfig("test")
+	cfg.Rate = 0
+	cfg.IfExpr = `$body.level == "debug"`
+	op, fake := newTestOperator(t, cfg)
+
+	// Entries which do not match the if expression are not sampled
+	infoEntry := entry.New()
+	infoEntry.Body = map[string]interface{}{"level": "info"}
+	require.NoError(t, op.Process(context.Background(), infoEntry))
+	fake.ExpectBody(t, map[string]interface{}{"level": "info"})
+
+	debugEntry := entry.New()
+	debugEntry.Body = map[string]interface{}{"level": "debug"}
+	require.NoError(t, op.Process(context.Background(), debugEntry))
+	fake.ExpectNoEntry(t, 0)
+}
+
+func TestSamplingOperatorHash(t *testing.T) {
+	cfg := NewSamplingOperatorConfig("test")
+	cfg.Rate = 0.25
+	field := entry.NewBodyField("trace_id")
+	cfg.HashField = &field
+	op, _ := newTestOperator(t, cfg)
+	op.random = func() float64 {
+		require.FailNow(t, "Random sampling used for an entry with a hash field")
+		return 0
+	}
+
+	kept := 0
+	const traces = 10000
+	for i := 0; i < traces; i++ {
+		traceID := fmt.Sprintf("%032x", i)
+		first := entry.New()
+		first.Body = map[string]interface{}{"trace_id": traceID, "message": "first"}
+		second := entry.New()
+		second.Body = map[string]interface{}{"trace_id": traceID, "message": "second"}
+
+		// Entries of the same trace are either all kept or all dropped
+		require.Equal(t, op.sampled(first), op.sampled(second))
+		if op.sampled(first) {
+			kept++
+		}
+	}
+	require.InDelta(t, traces*0.25, kept, traces*0.02)
+}
+
+func TestSamplingOperatorHashOnTraceID(t *testing.T) {
+	cfg := NewSamplingOperatorConfig("test")
+	cfg.Rate = 0.25
+	cfg.HashOn = HashOnTraceID
+	op, fake := newTestOperator(t, cfg)
+	op.random = func() float64 {
+		require.FailNow(t, "Random sampling used for an entry with a trace ID")
+		return 0
+	}
+
+	kept := 0
+	const traces = 10000
+	for i := 0; i < traces; i++ {
+		traceID := make([]byte, 16)
+		binary.BigEndian.PutUint64(traceID[8:], uint64(i))
+		first := entry.New()
+		first.TraceId = traceID
+		first.Body = "first"
+		second := entry.New()
+		second.TraceId = traceID
+		second.Body = "second"
+
+		// Entries of the same trace are either all kept or all dropped
+		require.NoError(t, op.Process(context.Background(), first))
+		require.NoError(t, op.Process(context.Background(), second))
+		select {
+		case e := <-fake.Received:
+			require.Equal(t, "first", e.Body)
+			fake.ExpectBody(t, "second")
+			kept++
+		default:
+			fake.ExpectNoEntry(t, 0)
+		}
+	}
+	require.InDelta(t, traces*0.25, kept, traces*0.02)
+
+	// Entries without a trace ID are sampled randomly
+	op.random = func() float64 { return 0.1 }
+	require.NoError(t, op.Process(context.Background(), entry.New()))
+	require.Len(t, fake.Received, 1)
+}
+
+func TestSamplingOperatorHashMissingField(t *testing.T) {
+	cfg := NewSamplingOperatorConfig("test")
+	cfg.Rate = 0.5
+	field := entry.NewBodyField("trace_id")
+	cfg.HashField = &field
+	op, fake := newTestOperator(t, cfg)
+	op.random = func() float64 { return 0.1 }
+
+	// Entries without the hash field are sampled randomly
+	e := entry.New()
+	e.Body = map[string]interface{}{"message": "no trace"}
+	require.NoError(t, op.Process(context.Background(), e))
+	fake.ExpectBody(t, map[string]interface{}{"message": "no trace"})
+}
+
+func TestSamplingOperatorHashRates(t *testing.T) {
+	cfg := NewSamplingOperatorConfig("test")
+	field := entry.NewBodyField("trace_id")
+	cfg.HashField = &field
+
+	cfg.Rate = 1
+	all, _ := newTestOperator(t, cfg)
+	cfg.Rate = 0
+	none, _ := newTestOperator(t, cfg)
+
+	for i := 0; i < 100; i++ {
+		e := entry.New()
+		e.Body = map[string]interface{}{"trace_id": i}
+		require.True(t, all.sampled(e))
+		require.False(t, none.sampled(e))
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemavalidate
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestSchemaValidateOperatorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "schema",
+			Expect: func() *SchemaValidateOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewBodyField("event")
+				cfg.Schema = map[string]interface{}{
+					"type":     "object",
+					"required": []interface{}{"message"},
+				}
+				return cfg
+			}(),
+		},
+		{
+			Name: "schema_file",
+			Expect: func() *SchemaValidateOperatorConfig {
+				cfg := defaultCfg()
+				cfg.SchemaFile = "/etc/collector/event.schema.json"
+				cfg.InvalidOutput = helper.OutputIDs{"dead_letter"}
+				cfg.ErrorsAttribute = "validation_errors"
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *SchemaValidateOperatorConfig {
+	return NewSchemaValidateOperatorConfig("schema_validate")
+}
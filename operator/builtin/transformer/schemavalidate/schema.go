@@ -0,0 +1,557 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemavalidate
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// schema is a compiled JSON Schema.
+type schema struct {
+	// always is set for the boolean schemas true and false, which accept or reject every value
+	always *bool
+
+	types []string
+	enum  []interface{}
+
+	hasConst bool
+	constVal interface{}
+
+	properties           map[string]*schema
+	required             []string
+	additionalProperties *schema
+	minProperties        *int
+	maxProperties        *int
+
+	items    *schema
+	minItems *int
+	maxItems *int
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+
+	allOf []*schema
+	anyOf []*schema
+	oneOf []*schema
+	not   *schema
+}
+
+// validTypes are the types of the JSON Schema type keyword.
+var validTypes = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"object":  true,
+	"array":   true,
+	"number":  true,
+	"integer": true,
+	"string":  true,
+}
+
+// unsupportedKeywords are keywords which are not supported, and are rejected rather than ignored,
+// so that a schema is not silently validated less strictly than intended.
+var unsupportedKeywords = []string{
+	"$ref", "patternProperties", "dependencies", "dependentRequired", "dependentSchemas",
+	"propertyNames", "if", "then", "else", "contains", "uniqueItems", "multipleOf",
+	"additionalItems", "unevaluatedProperties", "unevaluatedItems",
+}
+
+// compileSchema will compile a JSON Schema from its decoded representation.
+func compileSchema(raw interface{}) (*schema, error) {
+	return compileAt(normalize(raw), "#")
+}
+
+// compileAt will compile a schema at a location of its root schema.
+func compileAt(raw interface{}, location string) (*schema, error) {
+	if b, ok := raw.(bool); ok {
+		return &schema{always: &b}, nil
+	}
+
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: schema must be an object or boolean", location)
+	}
+
+	for _, keyword := range unsupportedKeywords {
+		if _, ok := m[keyword]; ok {
+			return nil, fmt.Errorf("%s: keyword '%s' is not supported", location, keyword)
+		}
+	}
+
+	s := &schema{}
+	var err error
+
+	if t, ok := m["type"]; ok {
+		if s.types, err = stringOrStrings(t); err != nil {
+			return nil, fmt.Errorf("%s/type: %s", location, err)
+		}
+		for _, typ := range s.types {
+			if !validTypes[typ] {
+				return nil, fmt.Errorf("%s/type: invalid type '%s'", location, typ)
+			}
+		}
+	}
+
+	if e, ok := m["enum"]; ok {
+		if s.enum, ok = e.([]interface{}); !ok {
+			return nil, fmt.Errorf("%s/enum: must be an array", location)
+		}
+	}
+
+	if c, ok := m["const"]; ok {
+		s.hasConst = true
+		s.constVal = c
+	}
+
+	if p, ok := m["properties"]; ok {
+		props, ok := p.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s/properties: must be an object", location)
+		}
+		s.properties = make(map[string]*schema, len(props))
+		for name, prop := range props {
+			if s.properties[name], err = compileAt(prop, location+"/properties/"+name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if r, ok := m["required"]; ok {
+		if s.required, err = stringList(r); err != nil {
+			return nil, fmt.Errorf("%s/required: %s", location, err)
+		}
+	}
+
+	if a, ok := m["additionalProperties"]; ok {
+		if s.additionalProperties, err = compileAt(a, location+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+
+	if i, ok := m["items"]; ok {
+		if s.items, err = compileAt(i, location+"/items"); err != nil {
+			return nil, err
+		}
+	}
+
+	for keyword, dst := range map[string]**int{
+		"minProperties": &s.minProperties,
+		"maxProperties": &s.maxProperties,
+		"minItems":      &s.minItems,
+		"maxItems":      &s.maxItems,
+		"minLength":     &s.minLength,
+		"maxLength":     &s.maxLength,
+	} {
+		if v, ok := m[keyword]; ok {
+			n, ok := helper.ToFloat64(v)
+			if !ok || n < 0 || n != math.Trunc(n) {
+				return nil, fmt.Errorf("%s/%s: must be a non-negative integer", location, keyword)
+			}
+			i := int(n)
+			*dst = &i
+		}
+	}
+
+	for keyword, dst := range map[string]**float64{
+		"minimum":          &s.minimum,
+		"maximum":          &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum,
+		"exclusiveMaximum": &s.exclusiveMaximum,
+	} {
+		if v, ok := m[keyword]; ok {
+			n, ok := helper.ToFloat64(v)
+			if !ok {
+				return nil, fmt.Errorf("%s/%s: must be a number", location, keyword)
+			}
+			*dst = &n
+		}
+	}
+
+	if p, ok := m["pattern"]; ok {
+		str, ok := p.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s/pattern: must be a string", location)
+		}
+		if s.pattern, err = regexp.Compile(str); err != nil {
+			return nil, fmt.Errorf("%s/pattern: %s", location, err)
+		}
+	}
+
+	for keyword, dst := range map[string]*[]*schema{
+		"allOf": &s.allOf,
+		"anyOf": &s.anyOf,
+		"oneOf": &s.oneOf,
+	} {
+		v, ok := m[keyword]
+		if !ok {
+			continue
+		}
+		list, ok := v.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, fmt.Errorf("%s/%s: must be a non-empty array", location, keyword)
+		}
+		for i, sub := range list {
+			compiled, err := compileAt(sub, fmt.Sprintf("%s/%s/%d", location, keyword, i))
+			if err != nil {
+				return nil, err
+			}
+			*dst = append(*dst, compiled)
+		}
+	}
+
+	if n, ok := m["not"]; ok {
+		if s.not, err = compileAt(n, location+"/not"); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// validate will return the errors of a normalized value which does not match the schema.
+// Each error is prefixed with the path of the invalid value.
+func (s *schema) validate(value interface{}, path string) []string {
+	if s.always != nil {
+		if *s.always {
+			return nil
+		}
+		return []string{path + ": no value is allowed"}
+	}
+
+	if len(s.types) > 0 && !s.matchesType(value) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(s.types, " or "), typeOf(value))}
+	}
+
+	var errs []string
+
+	if s.enum != nil {
+		found := false
+		for _, allowed := range s.enum {
+			if equal(value, allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s: value is not one of the allowed values", path))
+		}
+	}
+
+	if s.hasConst && !equal(value, s.constVal) {
+		errs = append(errs, fmt.Sprintf("%s: value does not equal the constant", path))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		errs = append(errs, s.validateObject(v, path)...)
+	case []interface{}:
+		errs = append(errs, s.validateArray(v, path)...)
+	case string:
+		errs = append(errs, s.validateString(v, path)...)
+	default:
+		if n, ok := helper.ToFloat64(v); ok {
+			errs = append(errs, s.validateNumber(n, path)...)
+		}
+	}
+
+	for _, sub := range s.allOf {
+		errs = append(errs, sub.validate(value, path)...)
+	}
+
+	if len(s.anyOf) > 0 {
+		matched := false
+		for _, sub := range s.anyOf {
+			if len(sub.validate(value, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			errs = append(errs, fmt.Sprintf("%s: value does not match any schema of anyOf", path))
+		}
+	}
+
+	if len(s.oneOf) > 0 {
+		matches := 0
+		for _, sub := range s.oneOf {
+			if len(sub.validate(value, path)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			errs = append(errs, fmt.Sprintf("%s: value matches %d schemas of oneOf, expected 1", path, matches))
+		}
+	}
+
+	if s.not != nil && len(s.not.validate(value, path)) == 0 {
+		errs = append(errs, fmt.Sprintf("%s: value must not match the schema of not", path))
+	}
+
+	return errs
+}
+
+// validateObject will validate the properties of an object.
+func (s *schema) validateObject(m map[string]interface{}, path string) []string {
+	var errs []string
+
+	for _, name := range s.required {
+		if _, ok := m[name]; !ok {
+			errs = append(errs, fmt.Sprintf("%s: missing required property '%s'", path, name))
+		}
+	}
+
+	if s.minProperties != nil && len(m) < *s.minProperties {
+		errs = append(errs, fmt.Sprintf("%s: expected at least %d properties, got %d", path, *s.minProperties, len(m)))
+	}
+	if s.maxProperties != nil && len(m) > *s.maxProperties {
+		errs = append(errs, fmt.Sprintf("%s: expected at most %d properties, got %d", path, *s.maxProperties, len(m)))
+	}
+
+	// Properties are validated in order, so that errors are reported consistently
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if prop, ok := s.properties[name]; ok {
+			errs = append(errs, prop.validate(m[name], path+"."+name)...)
+			continue
+		}
+		if s.additionalProperties != nil {
+			if s.additionalProperties.always != nil && !*s.additionalProperties.always {
+				errs = append(errs, fmt.Sprintf("%s: property '%s' is not allowed", path, name))
+				continue
+			}
+			errs = append(errs, s.additionalProperties.validate(m[name], path+"."+name)...)
+		}
+	}
+
+	return errs
+}
+
+// validateArray will validate the items of an array.
+func (s *schema) validateArray(list []interface{}, path string) []string {
+	var errs []string
+
+	if s.minItems != nil && len(list) < *s.minItems {
+		errs = append(errs, fmt.Sprintf("%s: expected at least %d items, got %d", path, *s.minItems, len(list)))
+	}
+	if s.maxItems != nil && len(list) > *s.maxItems {
+		errs = append(errs, fmt.Sprintf("%s: expected at most %d items, got %d", path, *s.maxItems, len(list)))
+	}
+
+	if s.items != nil {
+		for i, item := range list {
+			errs = append(errs, s.items.validate(item, path+"["+strconv.Itoa(i)+"]")...)
+		}
+	}
+
+	return errs
+}
+
+// validateString will validate the length and pattern of a string.
+func (s *schema) validateString(str string, path string) []string {
+	var errs []string
+
+	length := utf8.RuneCountInString(str)
+	if s.minLength != nil && length < *s.minLength {
+		errs = append(errs, fmt.Sprintf("%s: expected at least %d characters, got %d", path, *s.minLength, length))
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		errs = append(errs, fmt.Sprintf("%s: expected at most %d characters, got %d", path, *s.maxLength, length))
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		errs = append(errs, fmt.Sprintf("%s: value does not match pattern '%s'", path, s.pattern))
+	}
+
+	return errs
+}
+
+// validateNumber will validate the range of a number.
+func (s *schema) validateNumber(n float64, path string) []string {
+	var errs []string
+
+	if s.minimum != nil && n < *s.minimum {
+		errs = append(errs, fmt.Sprintf("%s: %v is less than the minimum of %v", path, n, *s.minimum))
+	}
+	if s.maximum != nil && n > *s.maximum {
+		errs = append(errs, fmt.Sprintf("%s: %v is greater than the maximum of %v", path, n, *s.maximum))
+	}
+	if s.exclusiveMinimum != nil && n <= *s.exclusiveMinimum {
+		errs = append(errs, fmt.Sprintf("%s: %v is not greater than the exclusive minimum of %v", path, n, *s.exclusiveMinimum))
+	}
+	if s.exclusiveMaximum != nil && n >= *s.exclusiveMaximum {
+		errs = append(errs, fmt.Sprintf("%s: %v is not less than the exclusive maximum of %v", path, n, *s.exclusiveMaximum))
+	}
+
+	return errs
+}
+
+// matchesType will return whether a value matches one of the types of the schema.
+func (s *schema) matchesType(value interface{}) bool {
+	actual := typeOf(value)
+	for _, typ := range s.types {
+		if typ == actual {
+			return true
+		}
+		if typ == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf will return the JSON Schema type of a value. Numbers without a fractional part are integers.
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		n, ok := helper.ToFloat64(v)
+		if !ok {
+			return fmt.Sprintf("%T", v)
+		}
+		if n == math.Trunc(n) && !math.IsInf(n, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+}
+
+// equal will return whether two normalized values are equal. Numbers are equal if their values are equal, regardless of type.
+func equal(a, b interface{}) bool {
+	if x, ok := helper.ToFloat64(a); ok {
+		y, ok := helper.ToFloat64(b)
+		return ok && x == y
+	}
+
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, ok := y[key]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// normalize will convert the maps and slices of a value to the types produced by decoding JSON,
+// so that values decoded from YAML, and values of entries, can be validated in the same way.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprintf("%v", key)] = normalize(value)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = normalize(value)
+		}
+		return m
+	case map[string]string:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = value
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, value := range v {
+			list[i] = normalize(value)
+		}
+		return list
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, value := range v {
+			list[i] = value
+		}
+		return list
+	case []byte:
+		return string(v)
+	default:
+		return value
+	}
+}
+
+// stringOrStrings will convert a string, or an array of strings, to a slice of strings.
+func stringOrStrings(value interface{}) ([]string, error) {
+	if s, ok := value.(string); ok {
+		return []string{s}, nil
+	}
+	return stringList(value)
+}
+
+// stringList will convert an array of strings to a slice of strings.
+func stringList(value interface{}) ([]string, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be an array of strings")
+	}
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be an array of strings")
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
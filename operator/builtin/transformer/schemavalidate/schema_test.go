@@ -0,0 +1,225 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemavalidate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecode(t *testing.T, s string) interface{} {
+	var raw interface{}
+	require.NoError(t, json.Unmarshal([]byte(s), &raw))
+	return raw
+}
+
+func TestCompileSchemaErrors(t *testing.T) {
+	cases := []struct {
+		name        string
+		schema      string
+		expectedErr string
+	}{
+		{"NotObject", `"string"`, "#: schema must be an object or boolean"},
+		{"InvalidType", `{"type": "text"}`, "#/type: invalid type 'text'"},
+		{"TypeNotString", `{"type": 1}`, "#/type: must be an array of strings"},
+		{"EnumNotArray", `{"enum": "a"}`, "#/enum: must be an array"},
+		{"RequiredNotStrings", `{"required": [1]}`, "#/required: must be an array of strings"},
+		{"NestedProperty", `{"properties": {"a": {"type": "text"}}}`, "#/properties/a/type: invalid type 'text'"},
+		{"NegativeLength", `{"minLength": -1}`, "#/minLength: must be a non-negative integer"},
+		{"FractionalItems", `{"maxItems": 1.5}`, "#/maxItems: must be a non-negative integer"},
+		{"MinimumNotNumber", `{"minimum": "1"}`, "#/minimum: must be a number"},
+		{"InvalidPattern", `{"pattern": "("}`, "#/pattern: error parsing regexp"},
+		{"EmptyAnyOf", `{"anyOf": []}`, "#/anyOf: must be a non-empty array"},
+		{"InvalidOneOf", `{"oneOf": [{"type": "text"}]}`, "#/oneOf/0/type: invalid type 'text'"},
+		{"Ref", `{"properties": {"a": {"$ref": "#/definitions/a"}}}`, "#/properties/a: keyword '$ref' is not supported"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := compileSchema(mustDecode(t, tc.schema))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	eventSchema := `{
+		"type": "object",
+		"required": ["message", "status"],
+		"properties": {
+			"message": {"type": "string", "minLength": 1, "maxLength": 10},
+			"status": {"type": "integer", "minimum": 100, "exclusiveMaximum": 600},
+			"level": {"enum": ["info", "warn", "error"]},
+			"tags": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}, "maxItems": 2},
+			"latency": {"type": ["number", "null"]}
+		},
+		"additionalProperties": false
+	}`
+
+	cases := []struct {
+		name     string
+		schema   string
+		value    interface{}
+		expected []string
+	}{
+		{
+			"Valid",
+			eventSchema,
+			map[string]interface{}{"message": "ok", "status": 200, "level": "info", "tags": []interface{}{"a"}, "latency": 1.5},
+			nil,
+		},
+		{
+			"ValidNativeTypes",
+			eventSchema,
+			map[string]interface{}{"message": []byte("ok"), "status": int64(200), "tags": []string{"a", "b"}, "latency": nil},
+			nil,
+		},
+		{
+			"WrongRootType",
+			eventSchema,
+			"message",
+			[]string{"$: expected object, got string"},
+		},
+		{
+			"MissingRequired",
+			eventSchema,
+			map[string]interface{}{"message": "ok"},
+			[]string{"$: missing required property 'status'"},
+		},
+		{
+			"AdditionalProperty",
+			eventSchema,
+			map[string]interface{}{"message": "ok", "status": 200, "extra": true},
+			[]string{"$: property 'extra' is not allowed"},
+		},
+		{
+			"PropertyErrors",
+			eventSchema,
+			map[string]interface{}{
+				"message": "a very long message",
+				"status":  600,
+				"level":   "debug",
+				"tags":    []interface{}{"a", "B", "c"},
+				"latency": "fast",
+			},
+			[]string{
+				"$.latency: expected number or null, got string",
+				"$.level: value is not one of the allowed values",
+				"$.message: expected at most 10 characters, got 19",
+				"$.status: 600 is not less than the exclusive maximum of 600",
+				"$.tags: expected at most 2 items, got 3",
+				"$.tags[1]: value does not match pattern '^[a-z]+$'",
+			},
+		},
+		{
+			"IntegerType",
+			eventSchema,
+			map[string]interface{}{"message": "ok", "status": 200.5},
+			[]string{"$.status: expected integer, got number"},
+		},
+		{
+			"Minimum",
+			eventSchema,
+			map[string]interface{}{"message": "", "status": 99},
+			[]string{
+				"$.message: expected at least 1 characters, got 0",
+				"$.status: 99 is less than the minimum of 100",
+			},
+		},
+		{
+			"AdditionalPropertiesSchema",
+			`{"additionalProperties": {"type": "string"}}`,
+			map[string]interface{}{"a": "b", "c": 1},
+			[]string{"$.c: expected string, got integer"},
+		},
+		{
+			"PropertyCount",
+			`{"minProperties": 2, "maxProperties": 3}`,
+			map[string]interface{}{"a": 1},
+			[]string{"$: expected at least 2 properties, got 1"},
+		},
+		{
+			"Const",
+			`{"const": {"a": [1, "b"]}}`,
+			map[string]interface{}{"a": []interface{}{int64(1), "b"}},
+			nil,
+		},
+		{
+			"ConstMismatch",
+			`{"const": 1}`,
+			"1",
+			[]string{"$: value does not equal the constant"},
+		},
+		{
+			"AllOf",
+			`{"allOf": [{"type": "string"}, {"maxLength": 2}]}`,
+			"abc",
+			[]string{"$: expected at most 2 characters, got 3"},
+		},
+		{
+			"AnyOf",
+			`{"anyOf": [{"type": "string"}, {"type": "boolean"}]}`,
+			1,
+			[]string{"$: value does not match any schema of anyOf"},
+		},
+		{
+			"OneOf",
+			`{"oneOf": [{"type": "number"}, {"type": "integer"}]}`,
+			1,
+			[]string{"$: value matches 2 schemas of oneOf, expected 1"},
+		},
+		{
+			"Not",
+			`{"not": {"type": "null"}}`,
+			nil,
+			[]string{"$: value must not match the schema of not"},
+		},
+		{
+			"FalseSchema",
+			`{"properties": {"a": false}}`,
+			map[string]interface{}{"a": 1},
+			[]string{"$.a: no value is allowed"},
+		},
+		{
+			"MapOfStrings",
+			`{"properties": {"host": {"type": "string"}}, "required": ["host"]}`,
+			map[string]string{"host": "a"},
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := compileSchema(mustDecode(t, tc.schema))
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, s.validate(normalize(tc.value), "$"))
+		})
+	}
+}
+
+func TestCompileSchemaYAML(t *testing.T) {
+	// Schemas decoded from YAML contain maps with interface keys
+	s, err := compileSchema(map[string]interface{}{
+		"type": "object",
+		"properties": map[interface{}]interface{}{
+			"status": map[interface{}]interface{}{"type": "integer"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"$.status: expected integer, got string"}, s.validate(normalize(map[string]interface{}{"status": "200"}), "$"))
+}
@@ -0,0 +1,212 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemavalidate
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// DefaultErrorsAttribute is the attribute which contains the validation errors of an invalid entry
+	DefaultErrorsAttribute = "schema.errors"
+
+	// maxReportedErrors is the maximum number of validation errors recorded on an entry
+	maxReportedErrors = 10
+)
+
+func init() {
+	operator.Register("schema_validate", func() operator.Builder { return NewSchemaValidateOperatorConfig("") })
+}
+
+// NewSchemaValidateOperatorConfig creates a new schema validate operator config with default values
+func NewSchemaValidateOperatorConfig(operatorID string) *SchemaValidateOperatorConfig {
+	return &SchemaValidateOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "schema_validate"),
+		Field:             entry.NewBodyField(),
+		ErrorsAttribute:   DefaultErrorsAttribute,
+	}
+}
+
+// SchemaValidateOperatorConfig is the configuration of a schema validate operator
+type SchemaValidateOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+
+	Field           entry.Field            `mapstructure:"field"            json:"field"                    yaml:"field"`
+	Schema          map[string]interface{} `mapstructure:"schema"           json:"schema,omitempty"         yaml:"schema,omitempty"`
+	SchemaFile      string                 `mapstructure:"schema_file"      json:"schema_file,omitempty"    yaml:"schema_file,omitempty"`
+	InvalidOutput   helper.OutputIDs       `mapstructure:"invalid_output"   json:"invalid_output,omitempty" yaml:"invalid_output,omitempty"`
+	ErrorsAttribute string                 `mapstructure:"errors_attribute" json:"errors_attribute"         yaml:"errors_attribute"`
+}
+
+// Build will build a schema validate operator from the supplied configuration
+func (c SchemaValidateOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw interface{}
+	switch {
+	case c.Schema != nil && c.SchemaFile != "":
+		return nil, fmt.Errorf("only one of 'schema' and 'schema_file' can be set")
+	case c.Schema != nil:
+		raw = c.Schema
+	case c.SchemaFile != "":
+		if raw, err = readSchemaFile(c.SchemaFile); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("one of 'schema' or 'schema_file' is required")
+	}
+
+	s, err := compileSchema(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %s", err)
+	}
+
+	if c.ErrorsAttribute == "" {
+		return nil, fmt.Errorf("errors_attribute must not be empty")
+	}
+
+	schemaValidateOperator := &SchemaValidateOperator{
+		TransformerOperator: transformerOperator,
+		field:               c.Field,
+		schema:              s,
+		errorsAttribute:     c.ErrorsAttribute,
+		invalidOutputIDs:    c.InvalidOutput.WithNamespace(context),
+	}
+
+	return []operator.Operator{schemaValidateOperator}, nil
+}
+
+// readSchemaFile will read a schema from a JSON or YAML file.
+func readSchemaFile(path string) (interface{}, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %s", err)
+	}
+
+	// YAML is a superset of JSON, so both formats are decoded in the same way
+	var raw interface{}
+	if err := yaml.Unmarshal(contents, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse schema file: %s", err)
+	}
+	return raw, nil
+}
+
+// SchemaValidateOperator is an operator that validates a field of entries against a JSON Schema
+type SchemaValidateOperator struct {
+	helper.TransformerOperator
+	field           entry.Field
+	schema          *schema
+	errorsAttribute string
+
+	invalidOutputIDs helper.OutputIDs
+	invalidOutputs   []operator.Operator
+}
+
+// Process will validate an entry, and write it to the invalid outputs if it does not match the schema.
+// Invalid entries are written to the outputs of the operator if no invalid outputs are configured.
+func (s *SchemaValidateOperator) Process(ctx context.Context, e *entry.Entry) error {
+	skip, err := s.Skip(ctx, e)
+	if err != nil {
+		return s.HandleEntryError(ctx, e, err)
+	}
+	if skip {
+		s.Write(ctx, e)
+		return nil
+	}
+
+	errs := s.Validate(e)
+	if len(errs) == 0 {
+		s.Write(ctx, e)
+		return nil
+	}
+
+	if len(errs) > maxReportedErrors {
+		errs = append(errs[:maxReportedErrors], fmt.Sprintf("and %d more errors", len(errs)-maxReportedErrors))
+	}
+	e.AddAttribute(s.errorsAttribute, strings.Join(errs, "; "))
+
+	if len(s.invalidOutputs) == 0 {
+		s.Write(ctx, e)
+		return nil
+	}
+	for i, output := range s.invalidOutputs {
+		if i == len(s.invalidOutputs)-1 {
+			_ = output.Process(ctx, e)
+			return nil
+		}
+		_ = output.Process(ctx, e.Copy())
+	}
+	return nil
+}
+
+// Validate will return the errors of an entry whose field does not match the schema.
+func (s *SchemaValidateOperator) Validate(e *entry.Entry) []string {
+	value, ok := e.Get(s.field)
+	if !ok {
+		return []string{fmt.Sprintf("%s: field is missing", s.field)}
+	}
+	return s.schema.validate(normalize(value), "$")
+}
+
+// Outputs will return the outputs and invalid outputs of the operator.
+func (s *SchemaValidateOperator) Outputs() []operator.Operator {
+	outputs := make([]operator.Operator, 0, len(s.OutputOperators)+len(s.invalidOutputs))
+	outputs = append(outputs, s.OutputOperators...)
+	return append(outputs, s.invalidOutputs...)
+}
+
+// SetOutputs will set the outputs and invalid outputs of the operator.
+func (s *SchemaValidateOperator) SetOutputs(operators []operator.Operator) error {
+	if err := s.TransformerOperator.SetOutputs(operators); err != nil {
+		return err
+	}
+
+	invalidOutputs := make([]operator.Operator, 0, len(s.invalidOutputIDs))
+	for _, operatorID := range s.invalidOutputIDs {
+		output, err := findOperator(operators, operatorID)
+		if err != nil {
+			return err
+		}
+		invalidOutputs = append(invalidOutputs, output)
+	}
+	s.invalidOutputs = invalidOutputs
+	return nil
+}
+
+// findOperator will find an operator that can process entries from a collection.
+func findOperator(operators []operator.Operator, operatorID string) (operator.Operator, error) {
+	for _, op := range operators {
+		if op.ID() != operatorID {
+			continue
+		}
+		if !op.CanProcess() {
+			return nil, fmt.Errorf("operator '%s' can not process entries", operatorID)
+		}
+		return op, nil
+	}
+	return nil, fmt.Errorf("operator '%s' does not exist", operatorID)
+}
@@ -0,0 +1,259 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemavalidate
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func eventSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"message"},
+		"properties": map[string]interface{}{
+			"message": map[string]interface{}{"type": "string"},
+			"status":  map[string]interface{}{"type": "integer"},
+		},
+	}
+}
+
+func writeFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func TestSchemaValidateOperatorBuild(t *testing.T) {
+	jsonPath := writeFile(t, "event.schema.json", `{"type": "object", "required": ["message"]}`)
+	yamlPath := writeFile(t, "event.schema.yaml", "type: object\nproperties:\n  status:\n    type: integer\n")
+	invalidPath := writeFile(t, "invalid.json", `{"type": `)
+
+	cases := []struct {
+		name        string
+		modify      func(*SchemaValidateOperatorConfig)
+		expectedErr string
+	}{
+		{
+			"Inline",
+			func(cfg *SchemaValidateOperatorConfig) { cfg.Schema = eventSchema() },
+			"",
+		},
+		{
+			"JSONFile",
+			func(cfg *SchemaValidateOperatorConfig) { cfg.SchemaFile = jsonPath },
+			"",
+		},
+		{
+			"YAMLFile",
+			func(cfg *SchemaValidateOperatorConfig) { cfg.SchemaFile = yamlPath },
+			"",
+		},
+		{
+			"NoSchema",
+			func(cfg *SchemaValidateOperatorConfig) {},
+			"one of 'schema' or 'schema_file' is required",
+		},
+		{
+			"BothSchemas",
+			func(cfg *SchemaValidateOperatorConfig) {
+				cfg.Schema = eventSchema()
+				cfg.SchemaFile = jsonPath
+			},
+			"only one of 'schema' and 'schema_file' can be set",
+		},
+		{
+			"MissingFile",
+			func(cfg *SchemaValidateOperatorConfig) { cfg.SchemaFile = filepath.Join(t.TempDir(), "missing.json") },
+			"failed to read schema file",
+		},
+		{
+			"InvalidFile",
+			func(cfg *SchemaValidateOperatorConfig) { cfg.SchemaFile = invalidPath },
+			"failed to parse schema file",
+		},
+		{
+			"InvalidSchema",
+			func(cfg *SchemaValidateOperatorConfig) { cfg.Schema = map[string]interface{}{"type": "text"} },
+			"invalid schema: #/type: invalid type 'text'",
+		},
+		{
+			"EmptyErrorsAttribute",
+			func(cfg *SchemaValidateOperatorConfig) {
+				cfg.Schema = eventSchema()
+				cfg.ErrorsAttribute = ""
+			},
+			"errors_attribute must not be empty",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewSchemaValidateOperatorConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestSchemaValidateOperatorValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		field    entry.Field
+		body     interface{}
+		expected []string
+	}{
+		{
+			"Valid",
+			entry.NewBodyField(),
+			map[string]interface{}{"message": "ok", "status": 200},
+			nil,
+		},
+		{
+			"Invalid",
+			entry.NewBodyField(),
+			map[string]interface{}{"status": "200"},
+			[]string{"$: missing required property 'message'", "$.status: expected integer, got string"},
+		},
+		{
+			"NestedField",
+			entry.NewBodyField("event"),
+			map[string]interface{}{"event": map[string]interface{}{"message": "ok"}},
+			nil,
+		},
+		{
+			"MissingField",
+			entry.NewBodyField("event"),
+			map[string]interface{}{"message": "ok"},
+			[]string{"event: field is missing"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewSchemaValidateOperatorConfig("test")
+			cfg.Field = tc.field
+			cfg.Schema = eventSchema()
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0].(*SchemaValidateOperator)
+
+			e := entry.New()
+			e.Body = tc.body
+			require.Equal(t, tc.expected, op.Validate(e))
+		})
+	}
+}
+
+func TestSchemaValidateOperatorInvalidOutput(t *testing.T) {
+	cfg := NewSchemaValidateOperatorConfig("test")
+	cfg.Schema = eventSchema()
+	cfg.OutputIDs = []string{"valid"}
+	cfg.InvalidOutput = helper.OutputIDs{"invalid"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*SchemaValidateOperator)
+
+	var validEntries, invalidEntries []*entry.Entry
+	valid := testutil.NewMockOperator("$.valid")
+	valid.On("Process", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		validEntries = append(validEntries, args[1].(*entry.Entry))
+	})
+	invalid := testutil.NewMockOperator("$.invalid")
+	invalid.On("Process", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		invalidEntries = append(invalidEntries, args[1].(*entry.Entry))
+	})
+	require.NoError(t, op.SetOutputs([]operator.Operator{valid, invalid}))
+	require.Equal(t, []operator.Operator{valid, invalid}, op.Outputs())
+
+	ok := entry.New()
+	ok.Body = map[string]interface{}{"message": "ok"}
+	require.NoError(t, op.Process(context.Background(), ok))
+
+	bad := entry.New()
+	bad.Body = map[string]interface{}{"status": 200}
+	require.NoError(t, op.Process(context.Background(), bad))
+
+	require.Len(t, validEntries, 1)
+	require.Nil(t, validEntries[0].Attributes)
+	require.Len(t, invalidEntries, 1)
+	require.Equal(t, map[string]string{
+		DefaultErrorsAttribute: "$: missing required property 'message'",
+	}, invalidEntries[0].Attributes)
+}
+
+func TestSchemaValidateOperatorMissingInvalidOutput(t *testing.T) {
+	cfg := NewSchemaValidateOperatorConfig("test")
+	cfg.Schema = eventSchema()
+	cfg.OutputIDs = []string{"valid"}
+	cfg.InvalidOutput = helper.OutputIDs{"invalid"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	err = ops[0].SetOutputs([]operator.Operator{testutil.NewMockOperator("$.valid")})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "operator '$.invalid' does not exist")
+}
+
+func TestSchemaValidateOperatorTag(t *testing.T) {
+	cfg := NewSchemaValidateOperatorConfig("test")
+	cfg.Schema = map[string]interface{}{
+		"additionalProperties": map[string]interface{}{"type": "integer"},
+	}
+	cfg.ErrorsAttribute = "validation_errors"
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*SchemaValidateOperator)
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	// Invalid entries are written to the output with their errors, which are limited in number
+	body := map[string]interface{}{}
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"} {
+		body[key] = "x"
+	}
+	e := entry.New()
+	e.Body = body
+	require.NoError(t, op.Process(context.Background(), e))
+
+	select {
+	case received := <-fake.Received:
+		errs := strings.Split(received.Attributes["validation_errors"], "; ")
+		require.Len(t, errs, maxReportedErrors+1)
+		require.Equal(t, "$.a: expected integer, got string", errs[0])
+		require.Equal(t, "and 2 more errors", errs[maxReportedErrors])
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splitarray
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+// test unmarshalling of values into config struct
+func TestGoldenConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "field",
+			Expect: func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewBodyField("records")
+				return cfg
+			}(),
+		},
+		{
+			Name: "index_attribute",
+			Expect: func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.IndexAttribute = "record_index"
+				return cfg
+			}(),
+		},
+		{
+			Name: "no_index_attribute",
+			Expect: func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.IndexAttribute = ""
+				return cfg
+			}(),
+		},
+		{
+			Name: "mode",
+			Expect: func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewBodyField("events")
+				cfg.Mode = MergeMode
+				return cfg
+			}(),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *SplitArrayOperatorConfig {
+	return NewSplitArrayOperatorConfig("split_array")
+}
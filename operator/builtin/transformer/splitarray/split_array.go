@@ -0,0 +1,218 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splitarray
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/errors"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// DefaultIndexAttribute is the attribute to which the index of an element is written when none is configured
+const DefaultIndexAttribute = "array_index"
+
+// Modes of writing an element to its entry
+const (
+	// InPlaceMode writes the element in place of the array
+	InPlaceMode = "in_place"
+
+	// ReplaceMode writes the element as the body of the entry
+	ReplaceMode = "replace"
+
+	// MergeMode merges the keys of a map element into the body of the entry
+	MergeMode = "merge"
+)
+
+func init() {
+	operator.Register("split_array", func() operator.Builder { return NewSplitArrayOperatorConfig("") })
+}
+
+// NewSplitArrayOperatorConfig creates a new split array operator config with default values
+func NewSplitArrayOperatorConfig(operatorID string) *SplitArrayOperatorConfig {
+	return &SplitArrayOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "split_array"),
+		Field:             entry.NewBodyField(),
+		IndexAttribute:    DefaultIndexAttribute,
+		Mode:              InPlaceMode,
+	}
+}
+
+// SplitArrayOperatorConfig is the configuration of a split array operator
+type SplitArrayOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+
+	Field          entry.Field `mapstructure:"field"           json:"field"           yaml:"field"`
+	IndexAttribute string      `mapstructure:"index_attribute" json:"index_attribute" yaml:"index_attribute"`
+	Mode           string      `mapstructure:"mode"            json:"mode"            yaml:"mode"`
+}
+
+// Build will build a split array operator from the supplied configuration
+func (c SplitArrayOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Field == entry.NewNilField() {
+		return nil, fmt.Errorf("split_array: missing field")
+	}
+
+	switch c.Mode {
+	case InPlaceMode, ReplaceMode, MergeMode:
+	default:
+		return nil, fmt.Errorf("split_array: invalid mode '%s', must be '%s', '%s' or '%s'", c.Mode, InPlaceMode, ReplaceMode, MergeMode)
+	}
+
+	splitArrayOp := &SplitArrayOperator{
+		TransformerOperator: transformerOperator,
+		Field:               c.Field,
+		IndexAttribute:      c.IndexAttribute,
+		Mode:                c.Mode,
+		json:                jsoniter.ConfigFastest,
+	}
+
+	return []operator.Operator{splitArrayOp}, nil
+}
+
+// SplitArrayOperator splits an entry whose field contains an array into an entry for each element
+type SplitArrayOperator struct {
+	helper.TransformerOperator
+	Field          entry.Field
+	IndexAttribute string
+	Mode           string
+	json           jsoniter.API
+}
+
+// Process will split an entry into an entry for each element of its array, and write them to the outputs.
+// An entry with an empty array is dropped.
+func (p *SplitArrayOperator) Process(ctx context.Context, e *entry.Entry) error {
+	skip, err := p.Skip(ctx, e)
+	if err != nil {
+		return p.HandleEntryError(ctx, e, err)
+	}
+	if skip {
+		p.Write(ctx, e)
+		return nil
+	}
+
+	elements, err := p.elements(e)
+	if err != nil {
+		return p.HandleEntryError(ctx, e, err)
+	}
+
+	if p.Mode == MergeMode {
+		if err := p.validateMerge(e, elements); err != nil {
+			return p.HandleEntryError(ctx, e, err)
+		}
+	}
+
+	// The array is removed before the entry is copied for each element, so that it is not copied repeatedly
+	_, _ = e.Delete(p.Field)
+	if p.Mode == ReplaceMode {
+		e.Body = nil
+	}
+	for i, element := range elements {
+		split := e.Copy()
+		if err := p.setElement(split, element); err != nil {
+			return p.HandleEntryError(ctx, e, errors.Wrap(err, "set element"))
+		}
+		if p.IndexAttribute != "" {
+			split.AddAttribute(p.IndexAttribute, strconv.Itoa(i))
+		}
+		p.Write(ctx, split)
+	}
+	return nil
+}
+
+// setElement will write an element to an entry according to the mode.
+func (p *SplitArrayOperator) setElement(e *entry.Entry, element interface{}) error {
+	switch p.Mode {
+	case ReplaceMode:
+		e.Body = element
+		return nil
+	case MergeMode:
+		body, ok := e.Body.(map[string]interface{})
+		if !ok {
+			body = make(map[string]interface{})
+		}
+		for key, value := range element.(map[string]interface{}) {
+			body[key] = value
+		}
+		e.Body = body
+		return nil
+	default:
+		return e.Set(p.Field, element)
+	}
+}
+
+// validateMerge will check that the elements of an array can be merged into the body of an entry,
+// so that no entries are written if any element cannot be merged.
+func (p *SplitArrayOperator) validateMerge(e *entry.Entry, elements []interface{}) error {
+	// A body field is either in a map or is the whole body, which is removed before merging
+	if _, ok := p.Field.FieldInterface.(entry.BodyField); !ok {
+		switch e.Body.(type) {
+		case map[string]interface{}, nil:
+		default:
+			return fmt.Errorf("split_array: body of type %T cannot be merged", e.Body)
+		}
+	}
+	for i, element := range elements {
+		if _, ok := element.(map[string]interface{}); !ok {
+			return fmt.Errorf("split_array: element %d of type %T cannot be merged", i, element)
+		}
+	}
+	return nil
+}
+
+// elements will return the elements of the array of an entry. A string is parsed as a JSON array.
+func (p *SplitArrayOperator) elements(e *entry.Entry) ([]interface{}, error) {
+	value, ok := e.Get(p.Field)
+	if !ok {
+		return nil, fmt.Errorf("split_array: field does not exist in this entry: %s", p.Field.String())
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		return v, nil
+	case []map[string]interface{}:
+		elements := make([]interface{}, 0, len(v))
+		for _, element := range v {
+			elements = append(elements, element)
+		}
+		return elements, nil
+	case string:
+		return p.parseArray([]byte(v))
+	case []byte:
+		return p.parseArray(v)
+	default:
+		return nil, fmt.Errorf("split_array: type %T cannot be split", value)
+	}
+}
+
+// parseArray will parse a JSON array.
+func (p *SplitArrayOperator) parseArray(data []byte) ([]interface{}, error) {
+	var elements []interface{}
+	if err := p.json.Unmarshal(data, &elements); err != nil {
+		return nil, fmt.Errorf("split_array: parse JSON array: %s", err)
+	}
+	return elements, nil
+}
@@ -0,0 +1,326 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splitarray
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+type testCase struct {
+	name      string
+	expectErr bool
+	op        *SplitArrayOperatorConfig
+	input     func() *entry.Entry
+	output    []func() *entry.Entry
+}
+
+// Test building and processing a SplitArrayOperatorConfig
+func TestBuildAndProcess(t *testing.T) {
+	newTestEntry := func() *entry.Entry {
+		e := entry.New()
+		e.Timestamp = time.Unix(1586632809, 0)
+		e.AddAttribute("source", "webhook")
+		e.AddResourceKey("host", "collector")
+		return e
+	}
+
+	withBody := func(body interface{}, index string) func() *entry.Entry {
+		return func() *entry.Entry {
+			e := newTestEntry()
+			e.Body = body
+			if index != "" {
+				e.AddAttribute(DefaultIndexAttribute, index)
+			}
+			return e
+		}
+	}
+
+	cases := []testCase{
+		{
+			"body_array",
+			false,
+			defaultCfg(),
+			withBody([]interface{}{
+				map[string]interface{}{"id": "a"},
+				map[string]interface{}{"id": "b"},
+			}, ""),
+			[]func() *entry.Entry{
+				withBody(map[string]interface{}{"id": "a"}, "0"),
+				withBody(map[string]interface{}{"id": "b"}, "1"),
+			},
+		},
+		{
+			"body_json_string",
+			false,
+			defaultCfg(),
+			withBody(`[{"id":"a"}, "b", 3]`, ""),
+			[]func() *entry.Entry{
+				withBody(map[string]interface{}{"id": "a"}, "0"),
+				withBody("b", "1"),
+				withBody(float64(3), "2"),
+			},
+		},
+		{
+			"body_json_bytes",
+			false,
+			defaultCfg(),
+			withBody([]byte(`[{"id":"a"}]`), ""),
+			[]func() *entry.Entry{
+				withBody(map[string]interface{}{"id": "a"}, "0"),
+			},
+		},
+		{
+			"nested_field",
+			false,
+			func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewBodyField("records")
+				return cfg
+			}(),
+			withBody(map[string]interface{}{
+				"batch":   "1",
+				"records": []interface{}{"x", "y"},
+			}, ""),
+			[]func() *entry.Entry{
+				withBody(map[string]interface{}{"batch": "1", "records": "x"}, "0"),
+				withBody(map[string]interface{}{"batch": "1", "records": "y"}, "1"),
+			},
+		},
+		{
+			"custom_index_attribute",
+			false,
+			func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.IndexAttribute = "record_index"
+				return cfg
+			}(),
+			withBody([]interface{}{"x"}, ""),
+			[]func() *entry.Entry{
+				func() *entry.Entry {
+					e := withBody("x", "")()
+					e.AddAttribute("record_index", "0")
+					return e
+				},
+			},
+		},
+		{
+			"no_index_attribute",
+			false,
+			func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.IndexAttribute = ""
+				return cfg
+			}(),
+			withBody([]interface{}{"x"}, ""),
+			[]func() *entry.Entry{
+				withBody("x", ""),
+			},
+		},
+		{
+			"replace",
+			false,
+			func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewBodyField("events")
+				cfg.Mode = ReplaceMode
+				return cfg
+			}(),
+			withBody(map[string]interface{}{
+				"batch":  "1",
+				"events": []interface{}{map[string]interface{}{"id": "a"}, "b"},
+			}, ""),
+			[]func() *entry.Entry{
+				withBody(map[string]interface{}{"id": "a"}, "0"),
+				withBody("b", "1"),
+			},
+		},
+		{
+			"merge",
+			false,
+			func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewBodyField("events")
+				cfg.Mode = MergeMode
+				return cfg
+			}(),
+			withBody(map[string]interface{}{
+				"batch": "1",
+				"id":    "parent",
+				"events": []interface{}{
+					map[string]interface{}{"id": "a"},
+					map[string]interface{}{"level": "warn"},
+				},
+			}, ""),
+			[]func() *entry.Entry{
+				withBody(map[string]interface{}{"batch": "1", "id": "a"}, "0"),
+				withBody(map[string]interface{}{"batch": "1", "id": "parent", "level": "warn"}, "1"),
+			},
+		},
+		{
+			"merge_body_array",
+			false,
+			func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Mode = MergeMode
+				return cfg
+			}(),
+			withBody([]interface{}{map[string]interface{}{"id": "a"}}, ""),
+			[]func() *entry.Entry{
+				withBody(map[string]interface{}{"id": "a"}, "0"),
+			},
+		},
+		{
+			"merge_attribute",
+			false,
+			func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewAttributeField("events")
+				cfg.Mode = MergeMode
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := withBody(map[string]interface{}{"batch": "1"}, "")()
+				e.AddAttribute("events", `[{"id":"a"}]`)
+				return e
+			},
+			[]func() *entry.Entry{
+				withBody(map[string]interface{}{"batch": "1", "id": "a"}, "0"),
+			},
+		},
+		{
+			"merge_element_not_map",
+			true,
+			func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewBodyField("events")
+				cfg.Mode = MergeMode
+				return cfg
+			}(),
+			withBody(map[string]interface{}{
+				"events": []interface{}{map[string]interface{}{"id": "a"}, "b"},
+			}, ""),
+			nil,
+		},
+		{
+			"merge_body_not_map",
+			true,
+			func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewAttributeField("events")
+				cfg.Mode = MergeMode
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := withBody("message", "")()
+				e.AddAttribute("events", `[{"id":"a"}]`)
+				return e
+			},
+			nil,
+		},
+		{
+			"empty_array",
+			false,
+			defaultCfg(),
+			withBody([]interface{}{}, ""),
+			nil,
+		},
+		{
+			"missing_field",
+			true,
+			func() *SplitArrayOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewBodyField("records")
+				return cfg
+			}(),
+			withBody(map[string]interface{}{}, ""),
+			nil,
+		},
+		{
+			"invalid_json",
+			true,
+			defaultCfg(),
+			withBody(`{"id":"a"}`, ""),
+			nil,
+		},
+		{
+			"invalid_type",
+			true,
+			defaultCfg(),
+			withBody(map[string]interface{}{"id": "a"}, ""),
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run("BuildAndProcess/"+tc.name, func(t *testing.T) {
+			cfg := tc.op
+			cfg.OutputIDs = []string{"fake"}
+			cfg.OnError = "drop"
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			splitArray := op.(*SplitArrayOperator)
+			fake := testutil.NewFakeOutput(t)
+			require.NoError(t, splitArray.SetOutputs([]operator.Operator{fake}))
+			val := tc.input()
+			err = splitArray.Process(context.Background(), val)
+			if tc.expectErr {
+				require.Error(t, err)
+				fake.ExpectNoEntry(t, 100*time.Millisecond)
+				return
+			}
+			require.NoError(t, err)
+			for _, output := range tc.output {
+				// Each element is written to a copy of the entry
+				fake.ExpectEntry(t, output().Copy())
+			}
+			fake.ExpectNoEntry(t, 100*time.Millisecond)
+		})
+	}
+}
+
+func TestSplitArrayIf(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.IfExpr = `$body matches "^\\["`
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	splitArray := ops[0].(*SplitArrayOperator)
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, splitArray.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = "not an array"
+	require.NoError(t, splitArray.Process(context.Background(), e))
+	fake.ExpectBody(t, "not an array")
+}
+
+func TestSplitArrayInvalidMode(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.Mode = "explode"
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid mode 'explode'")
+}
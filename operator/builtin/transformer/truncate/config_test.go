@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package truncate
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestTruncateOperatorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "fields",
+			Expect: func() *TruncateOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Fields = []entry.Field{entry.NewBodyField("stack_trace"), entry.NewAttributeField("url")}
+				cfg.MaxLength = 1024
+				return cfg
+			}(),
+		},
+		{
+			Name: "runes",
+			Expect: func() *TruncateOperatorConfig {
+				cfg := defaultCfg()
+				cfg.MaxLength = 100
+				cfg.Unit = RunesUnit
+				cfg.Suffix = " [truncated]"
+				cfg.LengthAttribute = "original_length"
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *TruncateOperatorConfig {
+	return NewTruncateOperatorConfig("truncate")
+}
@@ -0,0 +1,218 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package truncate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// Units in which the length of a value is measured
+const (
+	BytesUnit = "bytes"
+	RunesUnit = "runes"
+)
+
+// DefaultLengthAttribute is the prefix of the attributes which record the original lengths of truncated fields
+const DefaultLengthAttribute = "truncate.original_length"
+
+func init() {
+	operator.Register("truncate", func() operator.Builder { return NewTruncateOperatorConfig("") })
+}
+
+// NewTruncateOperatorConfig creates a new truncate operator config with default values
+func NewTruncateOperatorConfig(operatorID string) *TruncateOperatorConfig {
+	return &TruncateOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "truncate"),
+		Unit:              BytesUnit,
+		Suffix:            "...",
+		LengthAttribute:   DefaultLengthAttribute,
+	}
+}
+
+// TruncateOperatorConfig is the configuration of a truncate operator
+type TruncateOperatorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+	Fields                   []entry.Field `mapstructure:"fields"           json:"fields,omitempty" yaml:"fields,omitempty"`
+	MaxLength                int           `mapstructure:"max_length"       json:"max_length"       yaml:"max_length"`
+	Unit                     string        `mapstructure:"unit"             json:"unit"             yaml:"unit"`
+	Suffix                   string        `mapstructure:"suffix"           json:"suffix"           yaml:"suffix"`
+	LengthAttribute          string        `mapstructure:"length_attribute" json:"length_attribute" yaml:"length_attribute"`
+}
+
+// Build will build a truncate operator from the supplied configuration
+func (c TruncateOperatorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.MaxLength <= 0 {
+		return nil, fmt.Errorf("max_length must be greater than 0")
+	}
+
+	var length func(string) int
+	switch c.Unit {
+	case BytesUnit:
+		length = func(s string) int { return len(s) }
+	case RunesUnit:
+		length = utf8.RuneCountInString
+	default:
+		return nil, fmt.Errorf("invalid unit '%s', must be '%s' or '%s'", c.Unit, BytesUnit, RunesUnit)
+	}
+
+	if length(c.Suffix) >= c.MaxLength {
+		return nil, fmt.Errorf("suffix must be shorter than max_length")
+	}
+
+	truncateOperator := &TruncateOperator{
+		TransformerOperator: transformerOperator,
+		fields:              c.Fields,
+		maxLength:           c.MaxLength,
+		runes:               c.Unit == RunesUnit,
+		length:              length,
+		suffix:              c.Suffix,
+		lengthAttribute:     c.LengthAttribute,
+	}
+
+	return []operator.Operator{truncateOperator}, nil
+}
+
+// TruncateOperator is an operator that truncates string values which are longer than a maximum length
+type TruncateOperator struct {
+	helper.TransformerOperator
+	fields          []entry.Field
+	maxLength       int
+	runes           bool
+	length          func(string) int
+	suffix          string
+	lengthAttribute string
+}
+
+// Process will process an entry with a truncate transformation.
+func (t *TruncateOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return t.ProcessWith(ctx, entry, t.Transform)
+}
+
+// Transform will truncate the configured fields of an entry, or all of its string values if no fields are configured.
+// The original length of each truncated field is recorded in an attribute.
+func (t *TruncateOperator) Transform(e *entry.Entry) error {
+	// Lengths are recorded after all fields are truncated, so that the recorded lengths are not truncated
+	lengths := map[string]int{}
+
+	if len(t.fields) == 0 {
+		t.truncateAll(e, lengths)
+	}
+
+	for _, field := range t.fields {
+		value, ok := e.Get(field)
+		if !ok {
+			continue
+		}
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		truncated, ok := t.truncate(s)
+		if !ok {
+			continue
+		}
+		if err := e.Set(field, truncated); err != nil {
+			return err
+		}
+		lengths[field.String()] = t.length(s)
+	}
+
+	if t.lengthAttribute == "" {
+		return nil
+	}
+	for name, length := range lengths {
+		e.AddAttribute(t.lengthAttribute+"."+name, strconv.Itoa(length))
+	}
+	return nil
+}
+
+// truncateAll will truncate all string values of the body, attributes and resource of an entry.
+func (t *TruncateOperator) truncateAll(e *entry.Entry, lengths map[string]int) {
+	switch body := e.Body.(type) {
+	case string:
+		if truncated, ok := t.truncate(body); ok {
+			e.Body = truncated
+			lengths[entry.NewBodyField().String()] = t.length(body)
+		}
+	case map[string]interface{}:
+		t.truncateMap(body, nil, lengths)
+	}
+
+	for key, value := range e.Attributes {
+		if truncated, ok := t.truncate(value); ok {
+			e.Attributes[key] = truncated
+			lengths[entry.NewAttributeField(key).String()] = t.length(value)
+		}
+	}
+
+	for key, value := range e.Resource {
+		if truncated, ok := t.truncate(value); ok {
+			e.Resource[key] = truncated
+			lengths[entry.NewResourceField(key).String()] = t.length(value)
+		}
+	}
+}
+
+// truncateMap will truncate the string values of a map of the body, and of the maps nested in it.
+func (t *TruncateOperator) truncateMap(m map[string]interface{}, parent []string, lengths map[string]int) {
+	for key, value := range m {
+		keys := append(append([]string{}, parent...), key)
+		switch v := value.(type) {
+		case string:
+			if truncated, ok := t.truncate(v); ok {
+				m[key] = truncated
+				lengths[entry.NewBodyField(keys...).String()] = t.length(v)
+			}
+		case map[string]interface{}:
+			t.truncateMap(v, keys, lengths)
+		}
+	}
+}
+
+// truncate will return a value truncated to the maximum length, including the suffix,
+// and whether the value was longer than the maximum length.
+func (t *TruncateOperator) truncate(s string) (string, bool) {
+	if t.length(s) <= t.maxLength {
+		return s, false
+	}
+
+	keep := t.maxLength - t.length(t.suffix)
+	if t.runes {
+		end := 0
+		for i := 0; i < keep; i++ {
+			_, size := utf8.DecodeRuneInString(s[end:])
+			end += size
+		}
+		return s[:end] + t.suffix, true
+	}
+
+	// A multi-byte character is not split, so that the truncated value is valid UTF-8
+	for keep > 0 && !utf8.RuneStart(s[keep]) {
+		keep--
+	}
+	return s[:keep] + t.suffix, true
+}
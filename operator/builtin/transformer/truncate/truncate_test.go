@@ -0,0 +1,255 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package truncate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestTruncateOperatorBuild(t *testing.T) {
+	cases := []struct {
+		name        string
+		modify      func(*TruncateOperatorConfig)
+		expectedErr string
+	}{
+		{
+			"Default",
+			func(cfg *TruncateOperatorConfig) {},
+			"",
+		},
+		{
+			"ZeroMaxLength",
+			func(cfg *TruncateOperatorConfig) { cfg.MaxLength = 0 },
+			"max_length must be greater than 0",
+		},
+		{
+			"InvalidUnit",
+			func(cfg *TruncateOperatorConfig) { cfg.Unit = "words" },
+			"invalid unit 'words', must be 'bytes' or 'runes'",
+		},
+		{
+			"LongSuffix",
+			func(cfg *TruncateOperatorConfig) { cfg.Suffix = "..........." },
+			"suffix must be shorter than max_length",
+		},
+		{
+			"SuffixRunes",
+			func(cfg *TruncateOperatorConfig) {
+				cfg.Unit = RunesUnit
+				cfg.Suffix = "………"
+			},
+			"",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewTruncateOperatorConfig("test")
+			cfg.MaxLength = 10
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestTruncateOperatorTransform(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*TruncateOperatorConfig)
+		input    func() *entry.Entry
+		expected func() *entry.Entry
+	}{
+		{
+			"Fields",
+			func(cfg *TruncateOperatorConfig) {
+				cfg.Fields = []entry.Field{entry.NewBodyField("stack"), entry.NewAttributeField("url")}
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{"stack": "0123456789abcdef", "other": "0123456789abcdef"}
+				e.Attributes = map[string]string{"url": "/a/very/long/path"}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{"stack": "0123456...", "other": "0123456789abcdef"}
+				e.Attributes = map[string]string{
+					"url":                            "/a/very...",
+					"truncate.original_length.stack": "16",
+					"truncate.original_length.$attributes.url": "17",
+				}
+				return e
+			},
+		},
+		{
+			"ShortAndMissing",
+			func(cfg *TruncateOperatorConfig) {
+				cfg.Fields = []entry.Field{entry.NewBodyField("short"), entry.NewBodyField("missing"), entry.NewBodyField("number")}
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{"short": "0123456789", "number": 12345678901234}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{"short": "0123456789", "number": 12345678901234}
+				return e
+			},
+		},
+		{
+			"AllStrings",
+			func(cfg *TruncateOperatorConfig) {},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{
+					"message": "0123456789abcdef",
+					"request": map[string]interface{}{"body": "0123456789abcdef", "size": 16},
+					"short":   "short",
+				}
+				e.Attributes = map[string]string{"url": "/a/very/long/path"}
+				e.Resource = map[string]string{"host": "a-very-long-hostname"}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = map[string]interface{}{
+					"message": "0123456...",
+					"request": map[string]interface{}{"body": "0123456...", "size": 16},
+					"short":   "short",
+				}
+				e.Attributes = map[string]string{
+					"url":                                      "/a/very...",
+					"truncate.original_length.message":         "16",
+					"truncate.original_length.request.body":    "16",
+					"truncate.original_length.$attributes.url": "17",
+					"truncate.original_length.$resource.host":  "20",
+				}
+				e.Resource = map[string]string{"host": "a-very-..."}
+				return e
+			},
+		},
+		{
+			"StringBody",
+			func(cfg *TruncateOperatorConfig) {},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = "0123456789abcdef"
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = "0123456..."
+				e.Attributes = map[string]string{"truncate.original_length.$body": "16"}
+				return e
+			},
+		},
+		{
+			"BytesMultiByte",
+			func(cfg *TruncateOperatorConfig) { cfg.Suffix = "" },
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = "aéééééé"
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = "aéééé"
+				e.Attributes = map[string]string{"truncate.original_length.$body": "13"}
+				return e
+			},
+		},
+		{
+			"Runes",
+			func(cfg *TruncateOperatorConfig) {
+				cfg.Unit = RunesUnit
+				cfg.Suffix = "…"
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = "ééééééééééé"
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = "ééééééééé…"
+				e.Attributes = map[string]string{"truncate.original_length.$body": "11"}
+				return e
+			},
+		},
+		{
+			"NoLengthAttribute",
+			func(cfg *TruncateOperatorConfig) { cfg.LengthAttribute = "" },
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = "0123456789abcdef"
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Body = "0123456..."
+				return e
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewTruncateOperatorConfig("test")
+			cfg.MaxLength = 10
+			tc.modify(cfg)
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0].(*TruncateOperator)
+
+			e := tc.input()
+			expected := tc.expected()
+			expected.Timestamp = e.Timestamp
+			require.NoError(t, op.Transform(e))
+			require.Equal(t, expected, e)
+		})
+	}
+}
+
+func TestTruncateOperatorProcess(t *testing.T) {
+	cfg := NewTruncateOperatorConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.MaxLength = 5
+	cfg.Suffix = ""
+	cfg.LengthAttribute = ""
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*TruncateOperator)
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = "0123456789"
+	require.NoError(t, op.Process(context.Background(), e))
+	fake.ExpectBody(t, "01234")
+}
@@ -0,0 +1,71 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// AWSConfig is the configuration of the region and credentials
+// used by operators which call AWS APIs
+type AWSConfig struct {
+	Region     string `mapstructure:"region,omitempty"      json:"region,omitempty"      yaml:"region,omitempty"`
+	Profile    string `mapstructure:"profile,omitempty"     json:"profile,omitempty"     yaml:"profile,omitempty"`
+	RoleARN    string `mapstructure:"role_arn,omitempty"    json:"role_arn,omitempty"    yaml:"role_arn,omitempty"`
+	ExternalID string `mapstructure:"external_id,omitempty" json:"external_id,omitempty" yaml:"external_id,omitempty"`
+}
+
+// Validate checks that the configuration is consistent
+func (c AWSConfig) Validate() error {
+	if c.ExternalID != "" && c.RoleARN == "" {
+		return fmt.Errorf("`external_id` can only be used with `role_arn`")
+	}
+	return nil
+}
+
+// NewSession creates an AWS session. Credentials are found with the default
+// credential chain, and are used to assume the role, if one is configured.
+func (c AWSConfig) NewSession() (*session.Session, error) {
+	awsConfig := aws.Config{}
+	if c.Region != "" {
+		awsConfig.Region = aws.String(c.Region)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            awsConfig,
+		Profile:           c.Profile,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if aws.StringValue(sess.Config.Region) == "" {
+		return nil, fmt.Errorf("missing region, which must be configured with 'region' or the AWS_REGION environment variable")
+	}
+
+	if c.RoleARN != "" {
+		creds := stscreds.NewCredentials(sess, c.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+			if c.ExternalID != "" {
+				p.ExternalID = aws.String(c.ExternalID)
+			}
+		})
+		return sess.Copy(&aws.Config{Credentials: creds}), nil
+	}
+	return sess, nil
+}
@@ -0,0 +1,38 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSConfigValidate(t *testing.T) {
+	require.NoError(t, AWSConfig{}.Validate())
+	require.NoError(t, AWSConfig{RoleARN: "arn:aws:iam::123456789012:role/logs", ExternalID: "id"}.Validate())
+	require.Error(t, AWSConfig{ExternalID: "id"}.Validate())
+}
+
+func TestAWSConfigNewSession(t *testing.T) {
+	sess, err := AWSConfig{Region: "eu-west-1"}.NewSession()
+	require.NoError(t, err)
+	require.Equal(t, "eu-west-1", aws.StringValue(sess.Config.Region))
+
+	sess, err = AWSConfig{Region: "eu-west-1", RoleARN: "arn:aws:iam::123456789012:role/logs"}.NewSession()
+	require.NoError(t, err)
+	require.NotNil(t, sess.Config.Credentials)
+}
@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"bufio"
+	"time"
+)
+
+// Flusher forces buffered data to be returned as a token when no new
+// data has been read for the force flush period. A Flusher holds state
+// for a single stream, so it must not be shared between streams.
+type Flusher struct {
+	period        time.Duration
+	pendingLength int
+	pendingSince  time.Time
+}
+
+// NewFlusher creates a new Flusher which flushes after the given period
+func NewFlusher(period time.Duration) *Flusher {
+	return &Flusher{period: period}
+}
+
+// SplitFunc wraps a bufio.SplitFunc, returning all of the remaining data as a token
+// when the split func has not returned a token at EOF for the length of the period
+func (f *Flusher) SplitFunc(splitFunc bufio.SplitFunc) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		advance, token, err = splitFunc(data, atEOF)
+		if err != nil || token != nil || !atEOF || len(data) == 0 {
+			if token != nil {
+				f.pendingLength = 0
+			}
+			return
+		}
+
+		// Start the period over whenever more data has been read
+		if len(data) != f.pendingLength {
+			f.pendingLength = len(data)
+			f.pendingSince = time.Now()
+			return
+		}
+
+		if time.Since(f.pendingSince) < f.period {
+			return
+		}
+
+		f.pendingLength = 0
+		return len(data), data, nil
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestFlusher(t *testing.T) {
+	splitFunc, err := NewNewlineSplitFunc(unicode.UTF8, false)
+	require.NoError(t, err)
+	flusher := NewFlusher(50 * time.Millisecond)
+	flushFunc := flusher.SplitFunc(splitFunc)
+
+	// Complete tokens are returned as usual
+	advance, token, err := flushFunc([]byte("log1\nlog2"), true)
+	require.NoError(t, err)
+	require.Equal(t, 5, advance)
+	require.Equal(t, []byte("log1"), token)
+
+	// The partial token is held until the period has passed
+	advance, token, err = flushFunc([]byte("log2"), true)
+	require.NoError(t, err)
+	require.Equal(t, 0, advance)
+	require.Nil(t, token)
+
+	// More data restarts the period
+	time.Sleep(60 * time.Millisecond)
+	advance, token, err = flushFunc([]byte("log2 more"), true)
+	require.NoError(t, err)
+	require.Equal(t, 0, advance)
+	require.Nil(t, token)
+
+	time.Sleep(60 * time.Millisecond)
+	advance, token, err = flushFunc([]byte("log2 more"), true)
+	require.NoError(t, err)
+	require.Equal(t, 9, advance)
+	require.Equal(t, []byte("log2 more"), token)
+}
+
+func TestFlusherNotAtEOF(t *testing.T) {
+	splitFunc, err := NewNewlineSplitFunc(unicode.UTF8, false)
+	require.NoError(t, err)
+	flushFunc := NewFlusher(0).SplitFunc(splitFunc)
+
+	// Data is only flushed once all available data has been read
+	advance, token, err := flushFunc([]byte("log"), false)
+	require.NoError(t, err)
+	require.Equal(t, 0, advance)
+	require.Nil(t, token)
+}
@@ -0,0 +1,227 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+)
+
+// Framings of messages in a stream
+const (
+	FramingNewline       = "newline"
+	FramingOctetCounting = "octet_counting"
+	FramingAuto          = "auto"
+
+	// maxFrameLengthDigits is the most digits accepted in the
+	// length of an octet counted frame
+	maxFrameLengthDigits = 9
+)
+
+// NewFramingSplitFuncBuilder returns a func which creates the split func of a
+// framing for each connection, so that the framing of each connection is
+// detected separately. Messages which are delimited by newlines are split with
+// the split func built from the multiline config. If the framing is empty,
+// messages are delimited by newlines.
+func NewFramingSplitFuncBuilder(framing string, multiline MultilineConfig, splitFunc bufio.SplitFunc) (func() bufio.SplitFunc, error) {
+	switch framing {
+	case "", FramingNewline:
+		return func() bufio.SplitFunc { return splitFunc }, nil
+	case FramingOctetCounting:
+		if multiline.LineStartPattern != "" || multiline.LineEndPattern != "" {
+			return nil, fmt.Errorf("`multiline` can not be used with the `octet_counting` framing")
+		}
+		return NewOctetCountingSplitFunc, nil
+	case FramingAuto:
+		return func() bufio.SplitFunc { return NewAutoFramingSplitFunc(splitFunc) }, nil
+	default:
+		return nil, fmt.Errorf("invalid framing '%s'", framing)
+	}
+}
+
+// NewOctetCountingSplitFunc returns a split func for the octet counted framing of
+// RFC 6587, in which each message is prefixed with its length in bytes and a space.
+// Newlines between frames are skipped.
+func NewOctetCountingSplitFunc() bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		start := skipNewlines(data)
+		if start == len(data) {
+			return start, nil, nil
+		}
+
+		length, headerLen, ok := frameHeader(data[start:])
+		switch {
+		case ok:
+		case headerLen > 0 && !atEOF:
+			// The header may be incomplete
+			return start, nil, nil
+		default:
+			return 0, nil, fmt.Errorf("invalid octet counted frame")
+		}
+
+		end := start + headerLen + length
+		if end > len(data) {
+			if atEOF {
+				return 0, nil, fmt.Errorf("incomplete octet counted frame")
+			}
+			return start, nil, nil
+		}
+		return end, data[start+headerLen : end], nil
+	}
+}
+
+// NewAutoFramingSplitFunc returns a split func which detects the framing of a
+// stream from its first message. If it begins with a length and a space, the
+// stream is split as octet counted frames, and otherwise it is split with the
+// fallback. The split func holds the framing which was detected, so a new one
+// must be created for each stream.
+func NewAutoFramingSplitFunc(fallback bufio.SplitFunc) bufio.SplitFunc {
+	var detected bufio.SplitFunc
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if detected == nil {
+			start := skipNewlines(data)
+			if start == len(data) && !atEOF {
+				return 0, nil, nil
+			}
+
+			_, headerLen, ok := frameHeader(data[start:])
+			switch {
+			case ok:
+				detected = NewOctetCountingSplitFunc()
+			case headerLen > 0 && !atEOF:
+				// The header may be incomplete
+				return 0, nil, nil
+			default:
+				detected = fallback
+			}
+		}
+		return detected(data, atEOF)
+	}
+}
+
+// skipNewlines returns the index of the first byte of data which is not a newline
+func skipNewlines(data []byte) int {
+	start := 0
+	for start < len(data) && (data[start] == '\n' || data[start] == '\r') {
+		start++
+	}
+	return start
+}
+
+// frameHeader parses the length and space which begin an octet counted frame.
+// If the data is not a complete header, ok is false, and headerLen is the length
+// of the part of a header found, or zero if the data can not begin a header.
+func frameHeader(data []byte) (length, headerLen int, ok bool) {
+	if len(data) == 0 || data[0] < '1' || data[0] > '9' {
+		return 0, 0, false
+	}
+
+	i := 1
+	for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+		if i == maxFrameLengthDigits {
+			return 0, 0, false
+		}
+		i++
+	}
+	if i == len(data) {
+		return 0, i, false
+	}
+	if data[i] != ' ' {
+		return 0, 0, false
+	}
+
+	length, err := strconv.Atoi(string(data[:i]))
+	if err != nil {
+		return 0, 0, false
+	}
+	return length, i + 1, true
+}
+
+// NewDelimiterSplitFunc returns a split func for messages which are terminated
+// by a delimiter byte, such as the null bytes written by `find -print0`. Data
+// after the last delimiter is returned as a message at EOF.
+func NewDelimiterSplitFunc(delimiter byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if i := bytes.IndexByte(data, delimiter); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// NewVarintSplitFunc returns a split func for messages which are prefixed
+// with their length in bytes, encoded as an unsigned varint.
+func NewVarintSplitFunc(maxLength int) bufio.SplitFunc {
+	return newLengthPrefixSplitFunc(maxLength, func(data []byte) (uint64, int, error) {
+		length, n := binary.Uvarint(data)
+		switch {
+		case n > 0:
+			return length, n, nil
+		case n < 0 || len(data) >= binary.MaxVarintLen64:
+			return 0, 0, fmt.Errorf("invalid varint length prefix")
+		default:
+			return 0, 0, nil
+		}
+	})
+}
+
+// NewUint32SplitFunc returns a split func for messages which are prefixed
+// with their length in bytes, encoded as a 4-byte big-endian integer.
+func NewUint32SplitFunc(maxLength int) bufio.SplitFunc {
+	return newLengthPrefixSplitFunc(maxLength, func(data []byte) (uint64, int, error) {
+		if len(data) < 4 {
+			return 0, 0, nil
+		}
+		return uint64(binary.BigEndian.Uint32(data)), 4, nil
+	})
+}
+
+// newLengthPrefixSplitFunc returns a split func for messages which are prefixed with
+// their length. readLength returns the length and the size of the prefix, or a size
+// of zero if the prefix is incomplete. Messages longer than maxLength are an error.
+func newLengthPrefixSplitFunc(maxLength int, readLength func([]byte) (uint64, int, error)) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if len(data) == 0 {
+			return 0, nil, nil
+		}
+
+		length, prefixLen, err := readLength(data)
+		switch {
+		case err != nil:
+			return 0, nil, err
+		case prefixLen == 0 && atEOF:
+			return 0, nil, fmt.Errorf("incomplete length prefix")
+		case prefixLen == 0:
+			return 0, nil, nil
+		case length > uint64(maxLength):
+			return 0, nil, fmt.Errorf("message of %d bytes is longer than the maximum of %d bytes", length, maxLength)
+		}
+
+		end := prefixLen + int(length)
+		if end > len(data) {
+			if atEOF {
+				return 0, nil, fmt.Errorf("incomplete length prefixed message")
+			}
+			return 0, nil, nil
+		}
+		return end, data[prefixLen:end], nil
+	}
+}
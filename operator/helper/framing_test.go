@@ -0,0 +1,299 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// chunkedReader returns its data a few bytes at a time,
+// so that frames are split across reads
+type chunkedReader struct {
+	data []byte
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	if len(p) > 3 {
+		p = p[:3]
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestOctetCountingSplitFunc(t *testing.T) {
+	newlineSplitFunc, err := NewNewlineSplitFunc(unicode.UTF8, true)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name      string
+		splitFunc bufio.SplitFunc
+		input     string
+		expected  []string
+		expectErr bool
+	}{
+		{
+			"Single",
+			NewOctetCountingSplitFunc(),
+			"11 hello world",
+			[]string{"hello world"},
+			false,
+		},
+		{
+			"Multiple",
+			NewOctetCountingSplitFunc(),
+			"5 first6 second5 third",
+			[]string{"first", "second", "third"},
+			false,
+		},
+		{
+			"EmbeddedNewlines",
+			NewOctetCountingSplitFunc(),
+			"12 first\nsecond10 third\r\nend",
+			[]string{"first\nsecond", "third\r\nend"},
+			false,
+		},
+		{
+			"NewlinesBetweenFrames",
+			NewOctetCountingSplitFunc(),
+			"5 first\n6 second\r\n",
+			[]string{"first", "second"},
+			false,
+		},
+		{
+			"NotOctetCounted",
+			NewOctetCountingSplitFunc(),
+			"<34>1 message\n",
+			nil,
+			true,
+		},
+		{
+			"LeadingZero",
+			NewOctetCountingSplitFunc(),
+			"05 first",
+			nil,
+			true,
+		},
+		{
+			"TooManyDigits",
+			NewOctetCountingSplitFunc(),
+			"1234567890 first",
+			nil,
+			true,
+		},
+		{
+			"Incomplete",
+			NewOctetCountingSplitFunc(),
+			"12 first",
+			[]string{},
+			true,
+		},
+		{
+			"AutoOctetCounted",
+			NewAutoFramingSplitFunc(newlineSplitFunc),
+			"5 first6 second",
+			[]string{"first", "second"},
+			false,
+		},
+		{
+			"AutoNewline",
+			NewAutoFramingSplitFunc(newlineSplitFunc),
+			"<34>1 first\n<34>1 second\n",
+			[]string{"<34>1 first", "<34>1 second"},
+			false,
+		},
+		{
+			"AutoNewlineBeginningWithLength",
+			NewAutoFramingSplitFunc(newlineSplitFunc),
+			"<34>1 first\n12 monkeys\n",
+			[]string{"<34>1 first", "12 monkeys"},
+			false,
+		},
+		{
+			"AutoNewlinesBeforeFrame",
+			NewAutoFramingSplitFunc(newlineSplitFunc),
+			"\n\r\n5 first6 second",
+			[]string{"first", "second"},
+			false,
+		},
+		{
+			"AutoOctetCountedThenNewline",
+			NewAutoFramingSplitFunc(newlineSplitFunc),
+			"5 first\nsecond\n",
+			[]string{"first"},
+			true,
+		},
+		{
+			"AutoNewlineFlushedAtEOF",
+			NewAutoFramingSplitFunc(newlineSplitFunc),
+			"first\n123",
+			[]string{"first", "123"},
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			scanner := bufio.NewScanner(&chunkedReader{data: []byte(tc.input)})
+			scanner.Split(tc.splitFunc)
+
+			tokens := []string{}
+			for scanner.Scan() {
+				tokens = append(tokens, scanner.Text())
+			}
+
+			if tc.expectErr {
+				require.Error(t, scanner.Err())
+				if tc.expected != nil {
+					require.Equal(t, tc.expected, tokens)
+				}
+				return
+			}
+			require.NoError(t, scanner.Err())
+			require.Equal(t, tc.expected, tokens)
+		})
+	}
+}
+
+func scanTokens(t *testing.T, splitFunc bufio.SplitFunc, input []byte) ([]string, error) {
+	scanner := bufio.NewScanner(&chunkedReader{data: input})
+	scanner.Split(splitFunc)
+
+	tokens := []string{}
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	return tokens, scanner.Err()
+}
+
+func TestDelimiterSplitFunc(t *testing.T) {
+	cases := []struct {
+		name      string
+		delimiter byte
+		input     string
+		expected  []string
+	}{
+		{"Null", 0, "first\x00second\x00", []string{"first", "second"}},
+		{"NullWithNewlines", 0, "first\nline\x00second\x00", []string{"first\nline", "second"}},
+		{"FlushedAtEOF", 0, "first\x00second", []string{"first", "second"}},
+		{"Empty", 0, "\x00\x00", []string{"", ""}},
+		{"RecordSeparator", 0x1e, "first\x1esecond\x1e", []string{"first", "second"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, err := scanTokens(t, NewDelimiterSplitFunc(tc.delimiter), []byte(tc.input))
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, tokens)
+		})
+	}
+}
+
+func varintFrame(message string) []byte {
+	frame := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(frame, uint64(len(message)))
+	return append(frame[:n], message...)
+}
+
+func uint32Frame(message string) []byte {
+	frame := make([]byte, 4)
+	binary.BigEndian.PutUint32(frame, uint32(len(message)))
+	return append(frame, message...)
+}
+
+func TestLengthPrefixSplitFuncs(t *testing.T) {
+	long := strings.Repeat("a", 300)
+
+	cases := []struct {
+		name      string
+		splitFunc bufio.SplitFunc
+		input     []byte
+		expected  []string
+		expectErr bool
+	}{
+		{
+			"Varint",
+			NewVarintSplitFunc(1024),
+			bytes.Join([][]byte{varintFrame("first"), varintFrame(long), varintFrame("")}, nil),
+			[]string{"first", long, ""},
+			false,
+		},
+		{
+			"VarintTooLong",
+			NewVarintSplitFunc(100),
+			bytes.Join([][]byte{varintFrame("first"), varintFrame(long)}, nil),
+			[]string{"first"},
+			true,
+		},
+		{
+			"VarintInvalid",
+			NewVarintSplitFunc(1024),
+			bytes.Repeat([]byte{0xff}, 11),
+			[]string{},
+			true,
+		},
+		{
+			"VarintIncomplete",
+			NewVarintSplitFunc(1024),
+			varintFrame("first")[:3],
+			[]string{},
+			true,
+		},
+		{
+			"Uint32",
+			NewUint32SplitFunc(1024),
+			bytes.Join([][]byte{uint32Frame("first"), uint32Frame("binary\x00\n"), uint32Frame(long)}, nil),
+			[]string{"first", "binary\x00\n", long},
+			false,
+		},
+		{
+			"Uint32TooLong",
+			NewUint32SplitFunc(100),
+			uint32Frame(long),
+			[]string{},
+			true,
+		},
+		{
+			"Uint32IncompletePrefix",
+			NewUint32SplitFunc(1024),
+			[]byte{0, 0},
+			[]string{},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, err := scanTokens(t, tc.splitFunc, tc.input)
+			if tc.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expected, tokens)
+		})
+	}
+}
@@ -35,8 +35,11 @@ func NewMultilineConfig() MultilineConfig {
 
 // MultilineConfig is the configuration of a multiline helper
 type MultilineConfig struct {
-	LineStartPattern string `mapstructure:"line_start_pattern"  json:"line_start_pattern" yaml:"line_start_pattern"`
-	LineEndPattern   string `mapstructure:"line_end_pattern"    json:"line_end_pattern"   yaml:"line_end_pattern"`
+	LineStartPattern string   `mapstructure:"line_start_pattern"  json:"line_start_pattern" yaml:"line_start_pattern"`
+	LineEndPattern   string   `mapstructure:"line_end_pattern"    json:"line_end_pattern"   yaml:"line_end_pattern"`
+	MaxLines         int      `mapstructure:"max_lines,omitempty"          json:"max_lines,omitempty"          yaml:"max_lines,omitempty"`
+	MaxBytes         ByteSize `mapstructure:"max_bytes,omitempty"          json:"max_bytes,omitempty"          yaml:"max_bytes,omitempty"`
+	ForceFlushPeriod Duration `mapstructure:"force_flush_period,omitempty" json:"force_flush_period,omitempty" yaml:"force_flush_period,omitempty"`
 }
 
 // Build will build a Multiline operator.
@@ -46,12 +49,47 @@ func (c MultilineConfig) Build(context operator.BuildContext, encoding encoding.
 
 // getSplitFunc returns split function for bufio.Scanner basing on configured pattern
 func (c MultilineConfig) getSplitFunc(encoding encoding.Encoding, flushAtEOF bool) (bufio.SplitFunc, error) {
+	switch {
+	case c.MaxLines < 0:
+		return nil, fmt.Errorf("`max_lines` must not be negative")
+	case c.MaxBytes < 0:
+		return nil, fmt.Errorf("`max_bytes` must not be negative")
+	case c.ForceFlushPeriod.Raw() < 0:
+		return nil, fmt.Errorf("`force_flush_period` must not be negative")
+	}
+
+	splitFunc, err := c.getPatternSplitFunc(encoding, flushAtEOF)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.MaxLines == 0 && c.MaxBytes == 0 {
+		return splitFunc, nil
+	}
+
+	newline, err := encodedNewline(encoding)
+	if err != nil {
+		return nil, err
+	}
+	return NewLimitedSplitFunc(splitFunc, newline, c.MaxLines, int(c.MaxBytes)), nil
+}
+
+// getPatternSplitFunc returns the split function for the configured patterns
+func (c MultilineConfig) getPatternSplitFunc(encoding encoding.Encoding, flushAtEOF bool) (bufio.SplitFunc, error) {
 	endPattern := c.LineEndPattern
 	startPattern := c.LineStartPattern
 
 	switch {
 	case endPattern != "" && startPattern != "":
-		return nil, fmt.Errorf("only one of line_start_pattern or line_end_pattern can be set")
+		startRe, err := regexp.Compile("(?m)" + c.LineStartPattern)
+		if err != nil {
+			return nil, fmt.Errorf("compile line start regex: %s", err)
+		}
+		endRe, err := regexp.Compile("(?m)" + c.LineEndPattern)
+		if err != nil {
+			return nil, fmt.Errorf("compile line end regex: %s", err)
+		}
+		return NewLineStartEndSplitFunc(startRe, endRe, flushAtEOF), nil
 	case endPattern == "" && startPattern == "":
 		return NewNewlineSplitFunc(encoding, flushAtEOF)
 	case endPattern != "":
@@ -143,6 +181,116 @@ func NewLineEndSplitFunc(re *regexp.Regexp, flushAtEOF bool) bufio.SplitFunc {
 	}
 }
 
+// NewLineStartEndSplitFunc creates a bufio.SplitFunc that splits an incoming stream into
+// tokens that start with a match to the start regex and end with a match to the end regex.
+// If the start regex matches again before the end regex, the token ends at the second start.
+func NewLineStartEndSplitFunc(startRe, endRe *regexp.Regexp, flushAtEOF bool) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		firstLoc := startRe.FindIndex(data)
+		if firstLoc == nil {
+			// Flush if no more data is expected
+			if len(data) != 0 && atEOF && flushAtEOF {
+				return len(data), data, nil
+			}
+			return 0, nil, nil // read more data and try again.
+		}
+
+		if firstLoc[0] != 0 {
+			// the beginning of the file does not match the start pattern, so return a token up to the first match so we don't lose data
+			return firstLoc[0], data[:firstLoc[0]], nil
+		}
+
+		var nextStart int
+		if firstLoc[1] < len(data) {
+			if loc := startRe.FindIndex(data[firstLoc[1]+1:]); loc != nil {
+				nextStart = loc[0] + firstLoc[1] + 1
+			}
+		}
+
+		endLoc := endRe.FindIndex(data[firstLoc[1]:])
+		if endLoc != nil && (nextStart == 0 || endLoc[1]+firstLoc[1] <= nextStart) {
+			end := endLoc[1] + firstLoc[1]
+
+			// If the match goes up to the end of the current buffer, do another
+			// read until we can capture the entire match
+			if end == len(data) && !atEOF {
+				return 0, nil, nil
+			}
+
+			// The line ending after the end match is not part of the next token
+			advance = end
+			if bytes.HasPrefix(data[end:], []byte("\r\n")) {
+				advance += 2
+			} else if bytes.HasPrefix(data[end:], []byte("\n")) {
+				advance++
+			}
+			return advance, data[:end], nil
+		}
+
+		if nextStart != 0 {
+			// the entry was not terminated by the end pattern, so end it where the next entry starts
+			return nextStart, data[:nextStart], nil
+		}
+
+		// Flush if no more data is expected
+		if atEOF && flushAtEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil // read more data and try again
+	}
+}
+
+// NewLimitedSplitFunc wraps a bufio.SplitFunc so that tokens contain at most maxLines
+// lines and maxBytes bytes. When the buffered data exceeds either limit before a
+// complete token is found, the beginning of the data is returned as a token.
+// A limit of zero is unlimited.
+func NewLimitedSplitFunc(splitFunc bufio.SplitFunc, newline []byte, maxLines, maxBytes int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		advance, token, err = splitFunc(data, atEOF)
+		if err != nil {
+			return
+		}
+
+		if token != nil {
+			if n := limitedLength(token, newline, maxLines, maxBytes); n < len(token) {
+				return n, token[:n], nil
+			}
+			return
+		}
+
+		if n := limitedLength(data, newline, maxLines, maxBytes); n < len(data) {
+			return n, data[:n], nil
+		}
+		return
+	}
+}
+
+// limitedLength returns the length of the beginning of data which is within the limits
+func limitedLength(data, newline []byte, maxLines, maxBytes int) int {
+	length := len(data)
+	if maxLines > 0 {
+		end := 0
+		for i := 0; i < maxLines; i++ {
+			j := bytes.Index(data[end:], newline)
+			if j < 0 {
+				end = len(data)
+				break
+			}
+			end += j + len(newline)
+		}
+		length = end
+	}
+
+	if maxBytes > 0 && length > maxBytes {
+		// Prefer to split on a line boundary
+		length = maxBytes
+		if i := bytes.LastIndex(data[:maxBytes], newline); i > 0 {
+			length = i + len(newline)
+		}
+	}
+	return length
+}
+
 // NewNewlineSplitFunc splits log lines by newline, just as bufio.ScanLines, but
 // never returning an token using EOF as a terminator
 func NewNewlineSplitFunc(encoding encoding.Encoding, flushAtEOF bool) (bufio.SplitFunc, error) {
@@ -254,6 +254,171 @@ func TestLineEndSplitFunc(t *testing.T) {
 	}
 }
 
+func TestLineStartEndSplitFunc(t *testing.T) {
+	testCases := []struct {
+		tokenizerTestCase
+		EndPattern string
+	}{
+		{
+			tokenizerTestCase{
+				Name:    "OneLog",
+				Pattern: `^START`,
+				Raw:     []byte("START log\nmore\nEND\n"),
+				ExpectedTokenized: []string{
+					"START log\nmore\nEND",
+				},
+			},
+			`^END$`,
+		},
+		{
+			tokenizerTestCase{
+				Name:    "TwoLogsWithoutNewlineBetween",
+				Pattern: `^START`,
+				Raw:     []byte("START log1\nEND\nSTART log2\nEND\n"),
+				ExpectedTokenized: []string{
+					"START log1\nEND",
+					"START log2\nEND",
+				},
+			},
+			`^END$`,
+		},
+		{
+			tokenizerTestCase{
+				Name:    "PrecedingNonMatches",
+				Pattern: `^START`,
+				Raw:     []byte("noise\nSTART log\nEND\n"),
+				ExpectedTokenized: []string{
+					"noise\n",
+					"START log\nEND",
+				},
+			},
+			`^END$`,
+		},
+		{
+			tokenizerTestCase{
+				Name:    "StartBeforeEnd",
+				Pattern: `^START`,
+				Raw:     []byte("START log1\nSTART log2\nEND\n"),
+				ExpectedTokenized: []string{
+					"START log1\n",
+					"START log2\nEND",
+				},
+			},
+			`^END$`,
+		},
+		{
+			tokenizerTestCase{
+				Name:    "EndOnStartLine",
+				Pattern: `^START`,
+				Raw:     []byte("START log1 END\nSTART log2\nEND\n"),
+				ExpectedTokenized: []string{
+					"START log1 END",
+					"START log2\nEND",
+				},
+			},
+			`END$`,
+		},
+		{
+			tokenizerTestCase{
+				Name:              "NoEnd",
+				Pattern:           `^START`,
+				Raw:               []byte("START log\nmore\n"),
+				ExpectedTokenized: []string{},
+			},
+			`^END$`,
+		},
+	}
+
+	for _, tc := range testCases {
+		cfg := &MultilineConfig{
+			LineStartPattern: tc.Pattern,
+			LineEndPattern:   tc.EndPattern,
+		}
+		splitFunc, err := cfg.getSplitFunc(unicode.UTF8, false)
+		require.NoError(t, err)
+		t.Run(tc.Name, tc.RunFunc(splitFunc))
+	}
+}
+
+func TestLimitedSplitFunc(t *testing.T) {
+	testCases := []struct {
+		tokenizerTestCase
+		MaxLines int
+		MaxBytes ByteSize
+	}{
+		{
+			tokenizerTestCase{
+				Name:    "MaxLines",
+				Pattern: `^START`,
+				Raw:     []byte("START 1\na\nb\nc\nSTART 2\na\nSTART 3"),
+				ExpectedTokenized: []string{
+					"START 1\na\n",
+					"b\nc\n",
+					"START 2\na\n",
+				},
+			},
+			2,
+			0,
+		},
+		{
+			tokenizerTestCase{
+				Name:    "MaxLinesWithoutNextStart",
+				Pattern: `^START`,
+				Raw:     []byte("START 1\na\nb\nc"),
+				ExpectedTokenized: []string{
+					"START 1\na\n",
+				},
+			},
+			2,
+			0,
+		},
+		{
+			tokenizerTestCase{
+				Name:    "MaxBytesOnLineBoundary",
+				Pattern: `^START`,
+				Raw:     []byte("START 1\naaaa\nbbbb\nSTART 2\n"),
+				ExpectedTokenized: []string{
+					"START 1\naaaa\n",
+					"bbbb\n",
+				},
+			},
+			0,
+			16,
+		},
+		{
+			tokenizerTestCase{
+				Name:    "MaxBytesWithinLine",
+				Pattern: `^START`,
+				Raw:     []byte("START 1 aaaaaaaa\nSTART 2\n"),
+				ExpectedTokenized: []string{
+					"START 1 ",
+					"aaaaaaaa",
+					"\n",
+				},
+			},
+			0,
+			8,
+		},
+	}
+
+	for _, tc := range testCases {
+		cfg := &MultilineConfig{
+			LineStartPattern: tc.Pattern,
+			MaxLines:         tc.MaxLines,
+			MaxBytes:         tc.MaxBytes,
+		}
+		splitFunc, err := cfg.getSplitFunc(unicode.UTF8, false)
+		require.NoError(t, err)
+		t.Run(tc.Name, tc.RunFunc(splitFunc))
+	}
+
+	t.Run("NegativeMaxLines", func(t *testing.T) {
+		cfg := &MultilineConfig{MaxLines: -1}
+		_, err := cfg.getSplitFunc(unicode.UTF8, false)
+		require.Error(t, err)
+	})
+}
+
 func TestNewlineSplitFunc(t *testing.T) {
 	testCases := []tokenizerTestCase{
 		{
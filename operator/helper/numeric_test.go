@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+)
+
+func TestToFloat64(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    interface{}
+		expected float64
+		ok       bool
+	}{
+		{"Int", 1, 1, true},
+		{"Int8", int8(-8), -8, true},
+		{"Int64", int64(64), 64, true},
+		{"Uint16", uint16(16), 16, true},
+		{"Uint64", uint64(64), 64, true},
+		{"Float32", float32(1.5), 1.5, true},
+		{"Float64", 2.5, 2.5, true},
+		{"String", "1", 0, false},
+		{"Bool", true, 0, false},
+		{"Nil", nil, 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			n, ok := ToFloat64(tc.value)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.expected, n)
+		})
+	}
+}
+
+func TestNumericValue(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    interface{}
+		expected float64
+		ok       bool
+	}{
+		{"Int", 10, 10, true},
+		{"Float", 1.5, 1.5, true},
+		{"String", " 2.5 ", 2.5, true},
+		{"NonNumericString", "abc", 0, false},
+		{"Map", map[string]interface{}{}, 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := entry.New()
+			e.Body = tc.value
+			n, ok := NumericValue(e, entry.NewBodyField())
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.expected, n)
+		})
+	}
+
+	t.Run("Missing", func(t *testing.T) {
+		_, ok := NumericValue(entry.New(), entry.NewBodyField("missing"))
+		require.False(t, ok)
+	})
+}
@@ -16,6 +16,7 @@ package helper
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -28,11 +29,30 @@ type SeverityParser struct {
 	ParseFrom  entry.Field
 	PreserveTo *entry.Field
 	Mapping    severityMap
+
+	ranges        []severityRange
+	regex         *regexp.Regexp
+	regexGroup    int
+	normalizeText bool
+}
+
+// severityRange is an inclusive range of numeric values which are parsed as a severity.
+type severityRange struct {
+	min      int64
+	max      int64
+	severity entry.Severity
 }
 
 // Parse will parse severity from a field and attach it to the entry
 func (p *SeverityParser) Parse(ent *entry.Entry) error {
-	value, ok := ent.Delete(p.ParseFrom)
+	var value interface{}
+	var ok bool
+	if p.regex != nil {
+		// The severity is extracted from the field, so the field is kept
+		value, ok = ent.Get(p.ParseFrom)
+	} else {
+		value, ok = ent.Delete(p.ParseFrom)
+	}
 	if !ok {
 		return errors.NewError(
 			"log entry does not have the expected parse_from field",
@@ -41,13 +61,25 @@ func (p *SeverityParser) Parse(ent *entry.Entry) error {
 		)
 	}
 
-	severity, sevText, err := p.Mapping.find(value)
+	toParse := value
+	if p.regex != nil {
+		extracted, err := p.extract(value)
+		if err != nil {
+			return errors.Wrap(err, "parse")
+		}
+		toParse = extracted
+	}
+
+	severity, sevText, err := p.find(toParse)
 	if err != nil {
 		return errors.Wrap(err, "parse")
 	}
 
 	ent.Severity = severity
 	ent.SeverityText = sevText
+	if p.normalizeText {
+		ent.SeverityText = severity.String()
+	}
 
 	if p.PreserveTo != nil {
 		if err := ent.Set(p.PreserveTo, value); err != nil {
@@ -58,6 +90,44 @@ func (p *SeverityParser) Parse(ent *entry.Entry) error {
 	return nil
 }
 
+// extract will extract the severity from a value with the capture group of the regex.
+func (p *SeverityParser) extract(value interface{}) (string, error) {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return "", fmt.Errorf("type %T cannot be matched with a regex", value)
+	}
+
+	matches := p.regex.FindStringSubmatchIndex(raw)
+	if matches == nil || matches[2*p.regexGroup] < 0 {
+		return "", fmt.Errorf("regex pattern does not match")
+	}
+	return raw[matches[2*p.regexGroup]:matches[2*p.regexGroup+1]], nil
+}
+
+// find will find the severity of a value in the mapping, and then in the ranges if it is numeric.
+func (p *SeverityParser) find(value interface{}) (entry.Severity, string, error) {
+	severity, sevText, found, err := p.Mapping.lookup(value)
+	if err != nil || found || len(p.ranges) == 0 {
+		return severity, sevText, err
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(sevText), 10, 64)
+	if err != nil {
+		return severity, sevText, nil
+	}
+	for _, r := range p.ranges {
+		if n >= r.min && n <= r.max {
+			return r.severity, sevText, nil
+		}
+	}
+	return severity, sevText, nil
+}
+
 type severityMap map[string]entry.Severity
 
 // accepts various stringifyable input types and returns
@@ -65,33 +135,31 @@ type severityMap map[string]entry.Severity
 //   2) string version of input value
 //   3) error if invalid input type
 func (m severityMap) find(value interface{}) (entry.Severity, string, error) {
+	severity, sevText, _, err := m.lookup(value)
+	return severity, sevText, err
+}
+
+// lookup is the same as find, but also returns whether the value was found.
+func (m severityMap) lookup(value interface{}) (entry.Severity, string, bool, error) {
+	var strV string
 	switch v := value.(type) {
 	case int:
-		strV := strconv.Itoa(v)
-		if severity, ok := m[strV]; ok {
-			return severity, strV, nil
-		}
-		return entry.Default, strV, nil
+		strV = strconv.Itoa(v)
 	case float64:
 		if v != float64(int(v)) {
-			return entry.Default, "", fmt.Errorf("type %T cannot be a severity unless it is a whole number", v)
-		}
-		strV := strconv.Itoa(int(v))
-		if severity, ok := m[strV]; ok {
-			return severity, strV, nil
+			return entry.Default, "", false, fmt.Errorf("type %T cannot be a severity unless it is a whole number", v)
 		}
-		return entry.Default, strV, nil
+		strV = strconv.Itoa(int(v))
 	case string:
-		if severity, ok := m[strings.ToLower(v)]; ok {
-			return severity, v, nil
-		}
-		return entry.Default, v, nil
+		strV = v
 	case []byte:
-		if severity, ok := m[strings.ToLower(string(v))]; ok {
-			return severity, string(v), nil
-		}
-		return entry.Default, string(v), nil
+		strV = string(v)
 	default:
-		return entry.Default, "", fmt.Errorf("type %T cannot be a severity", v)
+		return entry.Default, "", false, fmt.Errorf("type %T cannot be a severity", v)
+	}
+
+	if severity, ok := m[strings.ToLower(strV)]; ok {
+		return severity, strV, true, nil
 	}
+	return entry.Default, strV, false, nil
 }
@@ -16,6 +16,8 @@ package helper
 
 import (
 	"fmt"
+	"math"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -112,11 +114,15 @@ type SeverityParserConfig struct {
 	PreserveTo *entry.Field                `mapstructure:"preserve_to,omitempty" json:"preserve_to,omitempty" yaml:"preserve_to,omitempty"`
 	Preset     string                      `mapstructure:"preset,omitempty"      json:"preset,omitempty"      yaml:"preset,omitempty"`
 	Mapping    map[interface{}]interface{} `mapstructure:"mapping,omitempty"     json:"mapping,omitempty"     yaml:"mapping,omitempty"`
+
+	Regex         string `mapstructure:"regex,omitempty"          json:"regex,omitempty"          yaml:"regex,omitempty"`
+	NormalizeText bool   `mapstructure:"normalize_text,omitempty" json:"normalize_text,omitempty" yaml:"normalize_text,omitempty"`
 }
 
 // Build builds a SeverityParser from a SeverityParserConfig
 func (c *SeverityParserConfig) Build(context operator.BuildContext) (SeverityParser, error) {
 	operatorMapping := getBuiltinMapping(c.Preset)
+	var operatorRanges []severityRange
 
 	for severity, unknown := range c.Mapping {
 		sev, err := validateSeverity(severity)
@@ -124,17 +130,18 @@ func (c *SeverityParserConfig) Build(context operator.BuildContext) (SeverityPar
 			return SeverityParser{}, err
 		}
 
-		switch u := unknown.(type) {
-		case []interface{}: // check before interface{}
-			for _, value := range u {
-				v, err := parseableValues(value)
-				if err != nil {
-					return SeverityParser{}, err
-				}
-				operatorMapping.add(sev, v...)
+		values := []interface{}{unknown}
+		if list, ok := unknown.([]interface{}); ok {
+			values = list
+		}
+
+		for _, value := range values {
+			if min, max, ok := parseableRange(value); ok {
+				operatorRanges = append(operatorRanges, severityRange{min: min, max: max, severity: sev})
+				continue
 			}
-		case interface{}:
-			v, err := parseableValues(u)
+
+			v, err := parseableValues(value)
 			if err != nil {
 				return SeverityParser{}, err
 			}
@@ -147,9 +154,27 @@ func (c *SeverityParserConfig) Build(context operator.BuildContext) (SeverityPar
 	}
 
 	p := SeverityParser{
-		ParseFrom:  *c.ParseFrom,
-		PreserveTo: c.PreserveTo,
-		Mapping:    operatorMapping,
+		ParseFrom:     *c.ParseFrom,
+		PreserveTo:    c.PreserveTo,
+		Mapping:       operatorMapping,
+		ranges:        operatorRanges,
+		normalizeText: c.NormalizeText,
+	}
+
+	if c.Regex != "" {
+		r, err := regexp.Compile(c.Regex)
+		if err != nil {
+			return SeverityParser{}, fmt.Errorf("compiling regex: %s", err)
+		}
+		if r.NumSubexp() == 0 {
+			return SeverityParser{}, fmt.Errorf("regex must contain a capture group")
+		}
+
+		p.regex = r
+		p.regexGroup = 1
+		if i := r.SubexpIndex("severity"); i > 0 {
+			p.regexGroup = i
+		}
 	}
 
 	return p, nil
@@ -183,37 +208,68 @@ func validateSeverity(severity interface{}) (entry.Severity, error) {
 	return entry.Severity(intSev), nil
 }
 
-func isRange(value interface{}) (int, int, bool) {
-	rawMap, ok := value.(map[interface{}]interface{})
-	if !ok {
+// parseableRange returns the bounds of a range of numeric values, which is either
+// a map of "min" and "max", or one of the special HTTP status code ranges.
+func parseableRange(value interface{}) (int64, int64, bool) {
+	switch v := value.(type) {
+	case string:
+		switch v {
+		case HTTP2xx:
+			return 200, 299, true
+		case HTTP3xx:
+			return 300, 399, true
+		case HTTP4xx:
+			return 400, 499, true
+		case HTTP5xx:
+			return 500, 599, true
+		}
 		return 0, 0, false
-	}
-
-	min, minOK := rawMap["min"]
-	max, maxOK := rawMap["max"]
-	if !minOK || !maxOK {
+	case map[interface{}]interface{}:
+		return parseRangeBounds(v["min"], v["max"])
+	case map[string]interface{}:
+		return parseRangeBounds(v["min"], v["max"])
+	default:
 		return 0, 0, false
 	}
+}
 
-	minInt, minOK := min.(int)
-	maxInt, maxOK := max.(int)
+func parseRangeBounds(min, max interface{}) (int64, int64, bool) {
+	minInt, minOK := wholeNumber(min)
+	maxInt, maxOK := wholeNumber(max)
 	if !minOK || !maxOK {
 		return 0, 0, false
 	}
 
+	if minInt > maxInt {
+		minInt, maxInt = maxInt, minInt
+	}
 	return minInt, maxInt, true
 }
 
-func expandRange(min, max int) []string {
-	if min > max {
-		min, max = max, min
-	}
-
-	rangeOfStrings := []string{}
-	for i := min; i <= max; i++ {
-		rangeOfStrings = append(rangeOfStrings, strconv.Itoa(i))
+// wholeNumber returns the value of an integer, or of a float which is a whole number.
+func wholeNumber(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	default:
+		return 0, false
 	}
-	return rangeOfStrings
 }
 
 func parseableValues(value interface{}) ([]string, error) {
@@ -221,25 +277,10 @@ func parseableValues(value interface{}) ([]string, error) {
 	case int:
 		return []string{strconv.Itoa(v)}, nil // store as string because we will compare as string
 	case string:
-		switch v {
-		case HTTP2xx:
-			return expandRange(200, 299), nil
-		case HTTP3xx:
-			return expandRange(300, 399), nil
-		case HTTP4xx:
-			return expandRange(400, 499), nil
-		case HTTP5xx:
-			return expandRange(500, 599), nil
-		default:
-			return []string{strings.ToLower(v)}, nil
-		}
+		return []string{strings.ToLower(v)}, nil
 	case []byte:
 		return []string{strings.ToLower(string(v))}, nil
 	default:
-		min, max, ok := isRange(v)
-		if ok {
-			return expandRange(min, max), nil
-		}
 		return nil, fmt.Errorf("type %T cannot be parsed as a severity", v)
 	}
 }
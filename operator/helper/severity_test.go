@@ -29,13 +29,16 @@ import (
 )
 
 type severityTestCase struct {
-	name       string
-	sample     interface{}
-	mappingSet string
-	mapping    map[interface{}]interface{}
-	buildErr   bool
-	parseErr   bool
-	expected   entry.Severity
+	name          string
+	sample        interface{}
+	mappingSet    string
+	mapping       map[interface{}]interface{}
+	regex         string
+	normalizeText bool
+	buildErr      bool
+	parseErr      bool
+	expected      entry.Severity
+	expectedText  string
 }
 
 func otlpSevCases() []severityTestCase {
@@ -233,6 +236,135 @@ func TestSeverityParser(t *testing.T) {
 			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"min": 125, "max": 120}},
 			expected: entry.Error,
 		},
+		{
+			name:     "large-range",
+			sample:   1500000,
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"min": 1000000, "max": 1999999}},
+			expected: entry.Error,
+		},
+		{
+			name:     "range-string-sample",
+			sample:   "123",
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"min": 120, "max": 125}},
+			expected: entry.Error,
+		},
+		{
+			name:     "range-float-sample",
+			sample:   float64(123),
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"min": 120, "max": 125}},
+			expected: entry.Error,
+		},
+		{
+			name:     "range-float-bounds",
+			sample:   123,
+			mapping:  map[interface{}]interface{}{"error": map[string]interface{}{"min": float64(120), "max": float64(125)}},
+			expected: entry.Error,
+		},
+		{
+			name:     "range-fractional-bounds",
+			sample:   123,
+			mapping:  map[interface{}]interface{}{"error": map[string]interface{}{"min": 120.5, "max": float64(125)}},
+			buildErr: true,
+		},
+		{
+			name:     "range-non-numeric-sample",
+			sample:   "12a",
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"min": 0, "max": 1000}},
+			expected: entry.Default,
+		},
+		{
+			name:   "value-before-range",
+			sample: 404,
+			mapping: map[interface{}]interface{}{
+				"error":   "4xx",
+				"warning": 404,
+			},
+			expected: entry.Warning,
+		},
+		{
+			name:   "overlapping-ranges-same-severity",
+			sample: 250,
+			mapping: map[interface{}]interface{}{
+				"info": []interface{}{
+					map[interface{}]interface{}{"min": 200, "max": 299},
+					map[interface{}]interface{}{"min": 250, "max": 260},
+				},
+			},
+			expected: entry.Info,
+		},
+		{
+			name:         "regex-named-group",
+			sample:       "2021-01-01 host [WARN] disk almost full",
+			regex:        `\[(?P<severity>\w+)\]`,
+			expected:     entry.Warning,
+			expectedText: "WARN",
+		},
+		{
+			name:         "regex-first-group",
+			sample:       "GET /index.html 503 12ms",
+			regex:        `^\S+ \S+ (\d{3})`,
+			mapping:      map[interface{}]interface{}{"info": "2xx", "error": "5xx"},
+			expected:     entry.Error,
+			expectedText: "503",
+		},
+		{
+			name:     "regex-no-match",
+			sample:   "no severity here",
+			regex:    `\[(\w+)\]`,
+			parseErr: true,
+		},
+		{
+			name:     "regex-optional-group",
+			sample:   "level=",
+			regex:    `level=(\w+)?`,
+			parseErr: true,
+		},
+		{
+			name:     "regex-non-string",
+			sample:   123,
+			regex:    `(\d+)`,
+			parseErr: true,
+		},
+		{
+			name:     "regex-without-group",
+			regex:    `\w+`,
+			buildErr: true,
+		},
+		{
+			name:     "regex-invalid",
+			regex:    `(\w+`,
+			buildErr: true,
+		},
+		{
+			name:          "normalize-text",
+			sample:        "WARN",
+			normalizeText: true,
+			expected:      entry.Warning,
+			expectedText:  "warning",
+		},
+		{
+			name:          "normalize-text-custom-level",
+			sample:        "medium",
+			mapping:       map[interface{}]interface{}{36: "medium"},
+			normalizeText: true,
+			expected:      entry.Severity(36),
+			expectedText:  "36",
+		},
+		{
+			name:          "normalize-text-regex",
+			sample:        "GET /index.html 503 12ms",
+			regex:         `^\S+ \S+ (\d{3})`,
+			mapping:       map[interface{}]interface{}{"error": "5xx"},
+			normalizeText: true,
+			expected:      entry.Error,
+			expectedText:  "error",
+		},
+		{
+			name:         "raw-text",
+			sample:       "WARN",
+			expected:     entry.Warning,
+			expectedText: "WARN",
+		},
 		{
 			name:     "Http2xx-hit",
 			sample:   201,
@@ -378,9 +510,11 @@ func (tc severityTestCase) run(parseFrom entry.Field) func(*testing.T) {
 		buildContext := testutil.NewBuildContext(t)
 
 		cfg := &SeverityParserConfig{
-			ParseFrom: &parseFrom,
-			Preset:    tc.mappingSet,
-			Mapping:   tc.mapping,
+			ParseFrom:     &parseFrom,
+			Preset:        tc.mappingSet,
+			Mapping:       tc.mapping,
+			Regex:         tc.regex,
+			NormalizeText: tc.normalizeText,
 		}
 
 		severityParser, err := cfg.Build(buildContext)
@@ -400,6 +534,16 @@ func (tc severityTestCase) run(parseFrom entry.Field) func(*testing.T) {
 		require.NoError(t, err)
 
 		require.Equal(t, tc.expected, ent.Severity)
+		if tc.expectedText != "" {
+			require.Equal(t, tc.expectedText, ent.SeverityText)
+		}
+
+		if tc.regex != "" {
+			// A field from which the severity is extracted is kept
+			value, ok := ent.Get(parseFrom)
+			require.True(t, ok)
+			require.Equal(t, tc.sample, value)
+		}
 	}
 }
 
@@ -459,6 +603,16 @@ func TestGoldenSeverityParserConfig(t *testing.T) {
 				return cfg
 			}(),
 		},
+		{
+			"regex",
+			false,
+			func() *SeverityParserConfig {
+				cfg := defaultSeverityCfg()
+				cfg.Regex = `\[(?P<severity>\w+)\]`
+				cfg.NormalizeText = true
+				return cfg
+			}(),
+		},
 	}
 
 	for _, tc := range cases {
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	strptime "github.com/observiq/ctimefmt"
@@ -53,13 +54,23 @@ type TimeParser struct {
 	LayoutType string       `mapstructure:"layout_type,omitempty" json:"layout_type,omitempty" yaml:"layout_type,omitempty"`
 	PreserveTo *entry.Field `mapstructure:"preserve_to,omitempty" json:"preserve_to,omitempty" yaml:"preserve_to,omitempty"`
 	Location   string       `mapstructure:"location,omitempty"    json:"location,omitempty"    yaml:"location,omitempty"`
+	Layouts    []TimeLayout `mapstructure:"layouts,omitempty"     json:"layouts,omitempty"     yaml:"layouts,omitempty"`
 
 	location *time.Location
+	layouts  []*TimeParser
+	hits     []uint64
+}
+
+// TimeLayout is one of an ordered list of layouts, which are tried in turn until one succeeds.
+type TimeLayout struct {
+	Layout     string `mapstructure:"layout,omitempty"      json:"layout,omitempty"      yaml:"layout,omitempty"`
+	LayoutType string `mapstructure:"layout_type,omitempty" json:"layout_type,omitempty" yaml:"layout_type,omitempty"`
+	Location   string `mapstructure:"location,omitempty"    json:"location,omitempty"    yaml:"location,omitempty"`
 }
 
 // IsZero returns true if the TimeParser is not a valid config
 func (t *TimeParser) IsZero() bool {
-	return t.Layout == ""
+	return t.Layout == "" && len(t.Layouts) == 0
 }
 
 // Validate validates a TimeParser, and reconfigures it if necessary
@@ -68,6 +79,10 @@ func (t *TimeParser) Validate(context operator.BuildContext) error {
 		return fmt.Errorf("missing required parameter 'parse_from'")
 	}
 
+	if len(t.Layouts) > 0 {
+		return t.validateLayouts(context)
+	}
+
 	if t.Layout == "" && t.LayoutType != "native" {
 		return errors.NewError("missing required configuration parameter `layout`", "")
 	}
@@ -110,6 +125,38 @@ func (t *TimeParser) Validate(context operator.BuildContext) error {
 	return nil
 }
 
+// validateLayouts validates each of the layouts of a TimeParser. A layout without
+// a layout_type uses strptime, and a layout without a location uses the location of the TimeParser.
+func (t *TimeParser) validateLayouts(context operator.BuildContext) error {
+	if t.Layout != "" {
+		return errors.NewError(
+			"only one of `layout` or `layouts` can be configured",
+			"move `layout` into the list of `layouts`",
+		)
+	}
+
+	t.layouts = make([]*TimeParser, 0, len(t.Layouts))
+	for i, l := range t.Layouts {
+		layout := &TimeParser{
+			ParseFrom:  t.ParseFrom,
+			Layout:     l.Layout,
+			LayoutType: l.LayoutType,
+			Location:   l.Location,
+		}
+		if layout.Location == "" {
+			layout.Location = t.Location
+		}
+
+		if err := layout.Validate(context); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("layouts[%d]", i))
+		}
+		t.layouts = append(t.layouts, layout)
+	}
+
+	t.hits = make([]uint64, len(t.layouts))
+	return nil
+}
+
 func (t *TimeParser) setLocation() error {
 	if t.Location != "" {
 		// If "location" is specified, it must be in the local timezone database
@@ -142,36 +189,66 @@ func (t *TimeParser) Parse(entry *entry.Entry) error {
 		)
 	}
 
+	var timeValue time.Time
+	var err error
+	if len(t.layouts) > 0 {
+		timeValue, err = t.parseLayouts(value)
+	} else {
+		timeValue, err = t.parseValue(value)
+	}
+	if err != nil {
+		return err
+	}
+	entry.Timestamp = setTimestampYear(timeValue)
+
+	if t.PreserveTo != nil {
+		if err := entry.Set(t.PreserveTo, value); err != nil {
+			return errors.Wrap(err, "set preserve_to")
+		}
+	}
+
+	return nil
+}
+
+// parseValue will parse a value with the layout of the TimeParser.
+func (t *TimeParser) parseValue(value interface{}) (time.Time, error) {
 	switch t.LayoutType {
 	case NativeKey:
 		timeValue, ok := value.(time.Time)
 		if !ok {
-			return fmt.Errorf("native time.Time field required, but found %v of type %T", value, value)
+			return time.Time{}, fmt.Errorf("native time.Time field required, but found %v of type %T", value, value)
 		}
-		entry.Timestamp = setTimestampYear(timeValue)
+		return timeValue, nil
 	case GotimeKey:
-		timeValue, err := t.parseGotime(value)
-		if err != nil {
-			return err
-		}
-		entry.Timestamp = setTimestampYear(timeValue)
+		return t.parseGotime(value)
 	case EpochKey:
-		timeValue, err := t.parseEpochTime(value)
-		if err != nil {
-			return err
-		}
-		entry.Timestamp = setTimestampYear(timeValue)
+		return t.parseEpochTime(value)
 	default:
-		return fmt.Errorf("unsupported layout type: %s", t.LayoutType)
+		return time.Time{}, fmt.Errorf("unsupported layout type: %s", t.LayoutType)
 	}
+}
 
-	if t.PreserveTo != nil {
-		if err := entry.Set(t.PreserveTo, value); err != nil {
-			return errors.Wrap(err, "set preserve_to")
+// parseLayouts will parse a value with the first of the layouts which succeeds.
+func (t *TimeParser) parseLayouts(value interface{}) (time.Time, error) {
+	errs := make([]string, 0, len(t.layouts))
+	for i, layout := range t.layouts {
+		timeValue, err := layout.parseValue(value)
+		if err == nil {
+			atomic.AddUint64(&t.hits[i], 1)
+			return timeValue, nil
 		}
+		errs = append(errs, fmt.Sprintf("layouts[%d]: %s", i, err))
 	}
+	return time.Time{}, fmt.Errorf("value did not match any layout: %s", strings.Join(errs, "; "))
+}
 
-	return nil
+// LayoutHits returns the number of values which have been parsed by each of the layouts, in order.
+func (t *TimeParser) LayoutHits() []uint64 {
+	hits := make([]uint64, len(t.hits))
+	for i := range t.hits {
+		hits[i] = atomic.LoadUint64(&t.hits[i])
+	}
+	return hits
 }
 
 func (t *TimeParser) parseGotime(value interface{}) (time.Time, error) {
@@ -78,6 +78,7 @@ func Test_setTimestampYear(t *testing.T) {
 func TestIsZero(t *testing.T) {
 	require.True(t, (&TimeParser{}).IsZero())
 	require.False(t, (&TimeParser{Layout: "strptime"}).IsZero())
+	require.False(t, (&TimeParser{Layouts: []TimeLayout{{Layout: "%Y"}}}).IsZero())
 }
 
 func TestTimeParser(t *testing.T) {
@@ -573,6 +574,103 @@ func TestTimeErrors(t *testing.T) {
 	}
 }
 
+func TestTimeParserLayouts(t *testing.T) {
+	field := entry.NewBodyField("time")
+	timeParser := &TimeParser{
+		ParseFrom: &field,
+		Location:  "UTC",
+		Layouts: []TimeLayout{
+			{LayoutType: GotimeKey, Layout: time.RFC3339},
+			{LayoutType: EpochKey, Layout: "ms"},
+			{Layout: "%d/%b/%Y:%H:%M:%S"},
+			{Layout: "%d/%b/%Y:%H:%M:%S", Location: "MST"},
+		},
+	}
+	require.NoError(t, timeParser.Validate(testutil.NewBuildContext(t)))
+
+	cases := []struct {
+		name     string
+		sample   interface{}
+		expected time.Time
+	}{
+		{"rfc3339", "2020-06-01T10:20:30Z", time.Date(2020, time.June, 1, 10, 20, 30, 0, time.UTC)},
+		{"epoch-string", "1591006830000", time.Unix(1591006830, 0)},
+		{"epoch-int", 1591006830123, time.Unix(1591006830, 123e6)},
+		{"strptime", "01/Jun/2020:10:20:30", time.Date(2020, time.June, 1, 10, 20, 30, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := makeTestEntry(field, tc.sample)
+			require.NoError(t, timeParser.Parse(e))
+			require.True(t, tc.expected.Equal(e.Timestamp), "expected %s, got %s", tc.expected, e.Timestamp)
+		})
+	}
+
+	// The last layout is never reached, because the previous layout matches the same values
+	require.Equal(t, []uint64{1, 2, 1, 0}, timeParser.LayoutHits())
+
+	e := makeTestEntry(field, "yesterday")
+	err := timeParser.Parse(e)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "value did not match any layout")
+	require.Contains(t, err.Error(), "layouts[1]: invalid value 'yesterday' for layout 'ms'")
+	require.Equal(t, []uint64{1, 2, 1, 0}, timeParser.LayoutHits())
+}
+
+func TestTimeParserLayoutsErrors(t *testing.T) {
+	field := entry.NewBodyField()
+
+	cases := []struct {
+		name     string
+		parser   *TimeParser
+		expected string
+	}{
+		{
+			"layout-and-layouts",
+			&TimeParser{
+				ParseFrom: &field,
+				Layout:    "%Y",
+				Layouts:   []TimeLayout{{Layout: "%m"}},
+			},
+			"only one of `layout` or `layouts` can be configured",
+		},
+		{
+			"invalid-layout",
+			&TimeParser{
+				ParseFrom: &field,
+				Layouts:   []TimeLayout{{Layout: "%Y"}, {LayoutType: EpochKey, Layout: "years"}},
+			},
+			"layouts[1]",
+		},
+		{
+			"missing-layout",
+			&TimeParser{
+				ParseFrom: &field,
+				Layouts:   []TimeLayout{{LayoutType: GotimeKey}},
+			},
+			"layouts[0]",
+		},
+		{
+			"invalid-location",
+			&TimeParser{
+				ParseFrom: &field,
+				Location:  "fake",
+				Layouts:   []TimeLayout{{Layout: "%Y"}},
+			},
+			"layouts[0]",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.parser.Validate(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
 func runTimeParseTest(timeParser *TimeParser, ent *entry.Entry, buildErr bool, parseErr bool, expected time.Time) func(*testing.T) {
 	return runLossyTimeParseTest(timeParser, ent, buildErr, parseErr, expected, time.Duration(0))
 }
@@ -674,6 +772,19 @@ func TestGoldenTimeParserConfig(t *testing.T) {
 				return cfg
 			}(),
 		},
+		{
+			"layouts",
+			false,
+			func() *TimeParser {
+				cfg := defaultTimeCfg()
+				cfg.Layouts = []TimeLayout{
+					{LayoutType: "gotime", Layout: "2006-01-02T15:04:05Z07:00"},
+					{LayoutType: "epoch", Layout: "ms"},
+					{Layout: "%d/%b/%Y:%H:%M:%S", Location: "America/Shiprock"},
+				}
+				return cfg
+			}(),
+		},
 	}
 
 	for _, tc := range cases {
@@ -15,14 +15,30 @@
 package helper
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/errors"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator"
 )
 
+const (
+	// TraceFieldsFormat parses the trace id, span id and trace flags from separate fields
+	TraceFieldsFormat = "fields"
+
+	// TraceparentFormat parses a W3C traceparent header from a single field
+	TraceparentFormat = "traceparent"
+
+	// B3Format parses a B3 single header from a single field
+	B3Format = "b3"
+
+	// B3MultiFormat parses the X-B3-TraceId, X-B3-SpanId and X-B3-Sampled headers from separate fields
+	B3MultiFormat = "b3_multi"
+)
+
 // NewTraceParser creates a new trace parser with default values
 func NewTraceParser() TraceParser {
 	traceId := entry.NewBodyField("trace_id")
@@ -46,6 +62,10 @@ type TraceParser struct {
 	TraceId    *TraceIdConfig    `mapstructure:"trace_id,omitempty"    json:"trace_id,omitempty"    yaml:"trace_id,omitempty"`
 	SpanId     *SpanIdConfig     `mapstructure:"span_id,omitempty"     json:"span_id,omitempty"     yaml:"span_id,omitempty"`
 	TraceFlags *TraceFlagsConfig `mapstructure:"trace_flags,omitempty" json:"trace_flags,omitempty" yaml:"trace_flags,omitempty"`
+
+	Format     string       `mapstructure:"format,omitempty"      json:"format,omitempty"      yaml:"format,omitempty"`
+	ParseFrom  *entry.Field `mapstructure:"parse_from,omitempty"  json:"parse_from,omitempty"  yaml:"parse_from,omitempty"`
+	PreserveTo *entry.Field `mapstructure:"preserve_to,omitempty" json:"preserve_to,omitempty" yaml:"preserve_to,omitempty"`
 }
 
 type TraceIdConfig struct {
@@ -86,6 +106,20 @@ func (t *TraceParser) Validate(context operator.BuildContext) error {
 		field := entry.NewBodyField("trace_flags")
 		t.TraceFlags.ParseFrom = &field
 	}
+
+	switch t.Format {
+	case "":
+		t.Format = TraceFieldsFormat
+	case TraceFieldsFormat, B3MultiFormat:
+	case TraceparentFormat, B3Format:
+		if t.ParseFrom == nil {
+			field := entry.NewBodyField(t.Format)
+			t.ParseFrom = &field
+		}
+	default:
+		return fmt.Errorf("invalid trace format '%s', must be one of '%s', '%s', '%s' or '%s'",
+			t.Format, TraceFieldsFormat, TraceparentFormat, B3Format, B3MultiFormat)
+	}
 	return nil
 }
 
@@ -110,6 +144,13 @@ func parseHexField(entry *entry.Entry, field *entry.Field, to *entry.Field) ([]b
 
 // Parse will parse a trace (trace_id, span_id and flags) from a field and attach it to the entry
 func (t *TraceParser) Parse(entry *entry.Entry) error {
+	switch t.Format {
+	case TraceparentFormat, B3Format:
+		return t.parseHeader(entry)
+	case B3MultiFormat:
+		return t.parseB3Multi(entry)
+	}
+
 	var errTraceId, errSpanId, errTraceFlags error
 	entry.TraceId, errTraceId = parseHexField(entry, t.TraceId.ParseFrom, t.TraceId.PreserveTo)
 	entry.SpanId, errSpanId = parseHexField(entry, t.SpanId.ParseFrom, t.SpanId.PreserveTo)
@@ -129,3 +170,184 @@ func (t *TraceParser) Parse(entry *entry.Entry) error {
 	}
 	return nil
 }
+
+// parseHeader will parse a trace from a single field, which contains a W3C traceparent or B3 header
+func (t *TraceParser) parseHeader(entry *entry.Entry) error {
+	value, ok := entry.Delete(t.ParseFrom)
+	if !ok {
+		return nil
+	}
+
+	header, ok := value.(string)
+	if !ok {
+		return errors.NewError(fmt.Sprintf("type %T cannot be parsed as a %s header", value, t.Format), "")
+	}
+
+	var traceID, spanID, traceFlags []byte
+	var err error
+	if t.Format == TraceparentFormat {
+		traceID, spanID, traceFlags, err = parseTraceparent(strings.TrimSpace(header))
+	} else {
+		traceID, spanID, traceFlags, err = parseB3(strings.TrimSpace(header))
+	}
+	if err != nil {
+		return errors.NewError("Error decoding traces for logs", "", t.Format, err.Error())
+	}
+
+	entry.TraceId, entry.SpanId, entry.TraceFlags = traceID, spanID, traceFlags
+	if t.PreserveTo != nil {
+		return entry.Set(t.PreserveTo, value)
+	}
+	return nil
+}
+
+// parseTraceparent will parse a W3C traceparent header, of the form version-trace_id-parent_id-trace_flags
+func parseTraceparent(header string) ([]byte, []byte, []byte, error) {
+	parts := strings.Split(header, "-")
+	if len(parts) < 4 {
+		return nil, nil, nil, fmt.Errorf("expected 4 parts, found %d", len(parts))
+	}
+
+	version, err := decodeHex("version", parts[0], 1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	switch {
+	case version[0] == 0xff:
+		return nil, nil, nil, fmt.Errorf("invalid version ff")
+	case version[0] == 0 && len(parts) != 4:
+		return nil, nil, nil, fmt.Errorf("expected 4 parts for version 00, found %d", len(parts))
+	}
+
+	traceID, err := decodeID("trace_id", parts[1], 16)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	spanID, err := decodeID("parent_id", parts[2], 8)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	traceFlags, err := decodeHex("trace_flags", parts[3], 1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return traceID, spanID, traceFlags, nil
+}
+
+// parseB3 will parse a B3 single header, of the form trace_id-span_id-sampling_state-parent_span_id,
+// where the sampling state and parent span id are optional. The header may also be only a sampling state.
+func parseB3(header string) ([]byte, []byte, []byte, error) {
+	parts := strings.Split(header, "-")
+	if len(parts) == 1 {
+		traceFlags, err := parseSamplingState(parts[0])
+		return nil, nil, traceFlags, err
+	}
+	if len(parts) > 4 {
+		return nil, nil, nil, fmt.Errorf("expected at most 4 parts, found %d", len(parts))
+	}
+
+	traceID, err := decodeB3TraceID(parts[0])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	spanID, err := decodeID("span_id", parts[1], 8)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var traceFlags []byte
+	if len(parts) > 2 {
+		traceFlags, err = parseSamplingState(parts[2])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if len(parts) > 3 {
+		if _, err := decodeID("parent_span_id", parts[3], 8); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return traceID, spanID, traceFlags, nil
+}
+
+// parseB3Multi will parse a trace from the fields of the X-B3-TraceId, X-B3-SpanId and X-B3-Sampled headers
+func (t *TraceParser) parseB3Multi(ent *entry.Entry) error {
+	err := errors.NewError("Error decoding traces for logs", "")
+	failed := false
+
+	parseField := func(field, preserveTo *entry.Field, key string, parse func(string) ([]byte, error)) []byte {
+		value, ok := ent.Delete(field)
+		if !ok {
+			return nil
+		}
+		if preserveTo != nil {
+			if setErr := ent.Set(preserveTo, value); setErr != nil {
+				_ = err.WithDetails(key, setErr.Error())
+				failed = true
+			}
+		}
+
+		data, parseErr := parse(strings.TrimSpace(fmt.Sprintf("%v", value)))
+		if parseErr != nil {
+			_ = err.WithDetails(key, parseErr.Error())
+			failed = true
+		}
+		return data
+	}
+
+	ent.TraceId = parseField(t.TraceId.ParseFrom, t.TraceId.PreserveTo, "trace_id", decodeB3TraceID)
+	ent.SpanId = parseField(t.SpanId.ParseFrom, t.SpanId.PreserveTo, "span_id", func(s string) ([]byte, error) {
+		return decodeID("span_id", s, 8)
+	})
+	ent.TraceFlags = parseField(t.TraceFlags.ParseFrom, t.TraceFlags.PreserveTo, "trace_flags", parseSamplingState)
+	if failed {
+		return err
+	}
+	return nil
+}
+
+// decodeB3TraceID will decode a B3 trace id, which is either 64 or 128 bits. A 64 bit
+// trace id is padded with zeros to 128 bits.
+func decodeB3TraceID(value string) ([]byte, error) {
+	if len(value) == 16 {
+		value = strings.Repeat("0", 16) + value
+	}
+	return decodeID("trace_id", value, 16)
+}
+
+// parseSamplingState will parse a B3 sampling state as trace flags. Accepted and
+// debug traces are sampled, and denied traces are not.
+func parseSamplingState(value string) ([]byte, error) {
+	switch strings.ToLower(value) {
+	case "1", "d", "true":
+		return []byte{0x01}, nil
+	case "0", "false":
+		return []byte{0x00}, nil
+	default:
+		return nil, fmt.Errorf("invalid sampling state '%s'", value)
+	}
+}
+
+// decodeID will decode an id, which must not be all zeros.
+func decodeID(name, value string, size int) ([]byte, error) {
+	data, err := decodeHex(name, value, size)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(data, make([]byte, size)) {
+		return nil, fmt.Errorf("invalid %s '%s': must not be all zeros", name, value)
+	}
+	return data, nil
+}
+
+// decodeHex will decode a hex string of a number of bytes.
+func decodeHex(name, value string, size int) ([]byte, error) {
+	if len(value) != 2*size {
+		return nil, fmt.Errorf("invalid %s '%s': expected %d hex characters, found %d", name, value, 2*size, len(value))
+	}
+	data, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s '%s': %s", name, value, err)
+	}
+	return data, nil
+}
@@ -97,3 +97,241 @@ func TestPreserveFields(t *testing.T) {
 	value, _ = hex.DecodeString("01")
 	require.Equal(t, value, entry.TraceFlags)
 }
+
+func TestValidateFormat(t *testing.T) {
+	parser := TraceParser{}
+	require.NoError(t, parser.Validate(testutil.NewBuildContext(t)))
+	require.Equal(t, TraceFieldsFormat, parser.Format)
+	require.Nil(t, parser.ParseFrom)
+
+	parser = TraceParser{Format: TraceparentFormat}
+	require.NoError(t, parser.Validate(testutil.NewBuildContext(t)))
+	traceparent := entry.NewBodyField("traceparent")
+	require.Equal(t, &traceparent, parser.ParseFrom)
+
+	parser = TraceParser{Format: B3Format}
+	require.NoError(t, parser.Validate(testutil.NewBuildContext(t)))
+	b3 := entry.NewBodyField("b3")
+	require.Equal(t, &b3, parser.ParseFrom)
+
+	parser = TraceParser{Format: "jaeger"}
+	err := parser.Validate(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid trace format 'jaeger'")
+}
+
+func TestTraceParserFormats(t *testing.T) {
+	decode := func(s string) []byte {
+		data, _ := hex.DecodeString(s)
+		return data
+	}
+
+	cases := []struct {
+		name         string
+		format       string
+		body         map[string]interface{}
+		expectErr    string
+		expectedBody map[string]interface{}
+		traceID      []byte
+		spanID       []byte
+		traceFlags   []byte
+	}{
+		{
+			name:         "TraceparentSampled",
+			format:       TraceparentFormat,
+			body:         map[string]interface{}{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "msg": "hi"},
+			expectedBody: map[string]interface{}{"msg": "hi"},
+			traceID:      decode("4bf92f3577b34da6a3ce929d0e0e4736"),
+			spanID:       decode("00f067aa0ba902b7"),
+			traceFlags:   decode("01"),
+		},
+		{
+			name:         "TraceparentFutureVersion",
+			format:       TraceparentFormat,
+			body:         map[string]interface{}{"traceparent": "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"},
+			expectedBody: map[string]interface{}{},
+			traceID:      decode("4bf92f3577b34da6a3ce929d0e0e4736"),
+			spanID:       decode("00f067aa0ba902b7"),
+			traceFlags:   decode("00"),
+		},
+		{
+			name:         "TraceparentMissing",
+			format:       TraceparentFormat,
+			body:         map[string]interface{}{"msg": "hi"},
+			expectedBody: map[string]interface{}{"msg": "hi"},
+		},
+		{
+			name:      "TraceparentShortTraceID",
+			format:    TraceparentFormat,
+			body:      map[string]interface{}{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01"},
+			expectErr: "expected 32 hex characters, found 30",
+		},
+		{
+			name:      "TraceparentZeroTraceID",
+			format:    TraceparentFormat,
+			body:      map[string]interface{}{"traceparent": "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+			expectErr: "must not be all zeros",
+		},
+		{
+			name:      "TraceparentZeroParentID",
+			format:    TraceparentFormat,
+			body:      map[string]interface{}{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+			expectErr: "must not be all zeros",
+		},
+		{
+			name:      "TraceparentInvalidHex",
+			format:    TraceparentFormat,
+			body:      map[string]interface{}{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bz-01"},
+			expectErr: "invalid parent_id",
+		},
+		{
+			name:      "TraceparentInvalidVersion",
+			format:    TraceparentFormat,
+			body:      map[string]interface{}{"traceparent": "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			expectErr: "invalid version ff",
+		},
+		{
+			name:      "TraceparentExtraParts",
+			format:    TraceparentFormat,
+			body:      map[string]interface{}{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"},
+			expectErr: "expected 4 parts for version 00, found 5",
+		},
+		{
+			name:      "TraceparentTooFewParts",
+			format:    TraceparentFormat,
+			body:      map[string]interface{}{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736"},
+			expectErr: "expected 4 parts, found 2",
+		},
+		{
+			name:      "TraceparentNotString",
+			format:    TraceparentFormat,
+			body:      map[string]interface{}{"traceparent": 1},
+			expectErr: "type int cannot be parsed as a traceparent header",
+		},
+		{
+			name:         "B3Full",
+			format:       B3Format,
+			body:         map[string]interface{}{"b3": "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3ac9a4f6e3b90"},
+			expectedBody: map[string]interface{}{},
+			traceID:      decode("80f198ee56343ba864fe8b2a57d3eff7"),
+			spanID:       decode("e457b5a2e4d86bd1"),
+			traceFlags:   decode("01"),
+		},
+		{
+			name:         "B3ShortTraceID",
+			format:       B3Format,
+			body:         map[string]interface{}{"b3": "64fe8b2a57d3eff7-e457b5a2e4d86bd1-0"},
+			expectedBody: map[string]interface{}{},
+			traceID:      decode("000000000000000064fe8b2a57d3eff7"),
+			spanID:       decode("e457b5a2e4d86bd1"),
+			traceFlags:   decode("00"),
+		},
+		{
+			name:         "B3WithoutSamplingState",
+			format:       B3Format,
+			body:         map[string]interface{}{"b3": "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1"},
+			expectedBody: map[string]interface{}{},
+			traceID:      decode("80f198ee56343ba864fe8b2a57d3eff7"),
+			spanID:       decode("e457b5a2e4d86bd1"),
+		},
+		{
+			name:         "B3Debug",
+			format:       B3Format,
+			body:         map[string]interface{}{"b3": "d"},
+			expectedBody: map[string]interface{}{},
+			traceFlags:   decode("01"),
+		},
+		{
+			name:      "B3InvalidTraceIDLength",
+			format:    B3Format,
+			body:      map[string]interface{}{"b3": "80f198ee56343ba864fe-e457b5a2e4d86bd1-1"},
+			expectErr: "invalid trace_id",
+		},
+		{
+			name:      "B3InvalidSamplingState",
+			format:    B3Format,
+			body:      map[string]interface{}{"b3": "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-x"},
+			expectErr: "invalid sampling state 'x'",
+		},
+		{
+			name:      "B3InvalidParentSpanID",
+			format:    B3Format,
+			body:      map[string]interface{}{"b3": "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3"},
+			expectErr: "invalid parent_span_id",
+		},
+		{
+			name:   "B3Multi",
+			format: B3MultiFormat,
+			body: map[string]interface{}{
+				"trace_id":    "64fe8b2a57d3eff7",
+				"span_id":     "e457b5a2e4d86bd1",
+				"trace_flags": "true",
+			},
+			expectedBody: map[string]interface{}{},
+			traceID:      decode("000000000000000064fe8b2a57d3eff7"),
+			spanID:       decode("e457b5a2e4d86bd1"),
+			traceFlags:   decode("01"),
+		},
+		{
+			name:   "B3MultiNotSampled",
+			format: B3MultiFormat,
+			body: map[string]interface{}{
+				"trace_id":    "80f198ee56343ba864fe8b2a57d3eff7",
+				"span_id":     "e457b5a2e4d86bd1",
+				"trace_flags": 0,
+			},
+			expectedBody: map[string]interface{}{},
+			traceID:      decode("80f198ee56343ba864fe8b2a57d3eff7"),
+			spanID:       decode("e457b5a2e4d86bd1"),
+			traceFlags:   decode("00"),
+		},
+		{
+			name:   "B3MultiInvalidSpanID",
+			format: B3MultiFormat,
+			body: map[string]interface{}{
+				"trace_id": "80f198ee56343ba864fe8b2a57d3eff7",
+				"span_id":  "e457b5a2e4d86b",
+			},
+			expectErr: "expected 16 hex characters, found 14",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := TraceParser{Format: tc.format}
+			require.NoError(t, parser.Validate(testutil.NewBuildContext(t)))
+
+			e := entry.New()
+			e.Body = tc.body
+			err := parser.Parse(e)
+			if tc.expectErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedBody, e.Body)
+			require.Equal(t, tc.traceID, e.TraceId)
+			require.Equal(t, tc.spanID, e.SpanId)
+			require.Equal(t, tc.traceFlags, e.TraceFlags)
+		})
+	}
+}
+
+func TestTraceParserPreserveHeader(t *testing.T) {
+	preserveTo := entry.NewBodyField("original")
+	parser := TraceParser{
+		Format:     TraceparentFormat,
+		PreserveTo: &preserveTo,
+	}
+	require.NoError(t, parser.Validate(testutil.NewBuildContext(t)))
+
+	e := entry.New()
+	e.Body = map[string]interface{}{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	require.NoError(t, parser.Parse(e))
+	require.Equal(t, map[string]interface{}{
+		"original": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}, e.Body)
+}
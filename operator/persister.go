@@ -38,6 +38,15 @@ func NewScopedPersister(s string, p Persister) Persister {
 	}
 }
 
+// UnscopedPersister returns the persister wrapped by a scoped persister,
+// or the persister itself if it is not scoped
+func UnscopedPersister(p Persister) Persister {
+	if scoped, ok := p.(*scopedPersister); ok {
+		return scoped.Persister
+	}
+	return p
+}
+
 func (p scopedPersister) Get(ctx context.Context, key string) ([]byte, error) {
 	return p.Persister.Get(ctx, fmt.Sprintf("%s.%s", p.scope, key))
 }